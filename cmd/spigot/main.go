@@ -1,31 +1,71 @@
 package main
 
 import (
+	"context"
 	"flag"
-	"math/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/elastic/go-ucfg"
 	"github.com/elastic/go-ucfg/yaml"
+	"github.com/leehinman/spigot/pkg/daemon"
+	"github.com/leehinman/spigot/pkg/entities"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/profile"
+	"github.com/leehinman/spigot/pkg/random"
 	"github.com/leehinman/spigot/pkg/runner"
+	"github.com/leehinman/spigot/pkg/service"
+	"github.com/leehinman/spigot/pkg/telemetry"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 )
 
 type Config struct {
-	Runners []*ucfg.Config `config:"runners" validate:"required"`
+	Plugins   []string          `config:"plugins"`
+	Seed      *int64            `config:"seed"`
+	Labels    map[string]string `config:"labels"`
+	Telemetry *ucfg.Config      `config:"telemetry"`
+	Entities  *ucfg.Config      `config:"entities"`
+	Runners   []*ucfg.Config    `config:"runners" validate:"required"`
 }
 
+// lintSamples is the number of events "spigot validate" generates to
+// find template fields that are never set.
+const lintSamples = 100
+
+// estimateSamples is the number of events "spigot estimate" generates
+// per runner to measure the average size of an event.
+const estimateSamples = 1000
+
 type Result struct {
 	Done  bool
 	Error error
 }
 
-func execute_runner(cfg *ucfg.Config, results chan Result) {
+func execute_runner(ctx context.Context, name string, cfg *ucfg.Config, progress time.Duration, results chan Result) {
 	r, err := runner.New(cfg)
 	if err != nil {
 		results <- Result{Error: err}
 		return
 	}
-	err = r.Execute()
+	issues, err := r.Lint()
+	if err != nil {
+		results <- Result{Error: err}
+		return
+	}
+	for _, issue := range issues {
+		fmt.Fprintln(os.Stderr, issue)
+	}
+	stop := func() {}
+	if progress > 0 && r.Finite() {
+		stop = report_progress(ctx, &r, name, progress)
+	}
+	err = r.ExecuteContext(ctx)
+	stop()
 	if err != nil {
 		results <- Result{Error: err}
 		return
@@ -34,14 +74,93 @@ func execute_runner(cfg *ucfg.Config, results chan Result) {
 	return
 }
 
+// report_progress writes the progress of r to stderr every interval,
+// until the returned function is called, which writes it a last time.
+func report_progress(ctx context.Context, r *runner.Runner, name string, interval time.Duration) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		r.ReportProgress(ctx, os.Stderr, name, interval)
+		close(done)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
 func main() {
 	var cfgFile string
 	var randomize bool
+	var listen string
+	var workers int
+	var otlp bool
+	var quiet bool
+	var progress time.Duration
+	var only string
+	var metrics string
+
+	// "spigot init <profile>" writes the configuration of a bundled
+	// profile, and lists the profiles without one.
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		if err := init_config(os.Stdout, os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// "spigot validate" checks the configuration and the templates of
+	// the generators, without running them.  "spigot estimate" reports
+	// the volume the runners write in a day.
+	validate := len(os.Args) > 1 && os.Args[1] == "validate"
+	estimate := len(os.Args) > 1 && os.Args[1] == "estimate"
+	if validate || estimate {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
 
 	flag.StringVar(&cfgFile, "c", "./spigot.yml", "path to configuration file")
 	flag.BoolVar(&randomize, "r", false, "seed random number generator with current time")
+	flag.StringVar(&listen, "listen", "", "run as a service, serving the scenario API on this address")
+	flag.IntVar(&workers, "workers", 1, "number of scenarios the service runs in parallel")
+	flag.BoolVar(&otlp, "telemetry", false, "in service mode, export traces and metrics with the OTEL_EXPORTER_OTLP_* environment variables")
+	flag.BoolVar(&quiet, "q", false, "do not report the progress of finite runs")
+	flag.DurationVar(&progress, "progress", 10*time.Second, "interval of the progress reports of finite runs")
+	flag.StringVar(&metrics, "metrics", "", "serve Prometheus metrics on /metrics of this address")
+	flag.StringVar(&only, "only", "", "comma separated names of the runners to run, default all")
 	flag.Parse()
 
+	if listen != "" {
+		if workers < 1 {
+			panic(fmt.Errorf("'%d' is not a valid value for 'workers' expected a value greater than 0", workers))
+		}
+		if randomize {
+			random.Seed(time.Now().UnixNano())
+		}
+		prom := start_metrics(metrics)
+		switch {
+		case otlp:
+			if _, err := telemetry.Start(context.Background(), telemetry.DefaultConfig(), nil, readers(prom)...); err != nil {
+				panic(err)
+			}
+		case prom != nil:
+			if _, err := telemetry.StartMetrics(nil, prom.Reader()); err != nil {
+				panic(err)
+			}
+		}
+		if err := daemon.Run("spigot", func(ctx context.Context) error {
+			s := service.New(workers, 64)
+			defer s.Close()
+			if prom != nil {
+				go serve_metrics(ctx, metrics, prom)
+			}
+			return serve(ctx, listen, s)
+		}); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	c := Config{}
 	cfg, err := yaml.NewConfigWithFile(cfgFile, ucfg.PathSep("."))
 	if err != nil {
@@ -52,23 +171,293 @@ func main() {
 		panic(err)
 	}
 
+	for _, p := range c.Plugins {
+		if err := generator.LoadPlugin(p); err != nil {
+			panic(err)
+		}
+	}
+
 	if randomize {
-		rand.Seed(time.Now().UnixNano())
+		random.Seed(time.Now().UnixNano())
+	}
+	if c.Seed != nil {
+		if err := runner.SetSeed(c.Runners, *c.Seed); err != nil {
+			panic(err)
+		}
+	}
+	if c.Entities != nil {
+		if err := build_entities(c.Entities, c.Seed); err != nil {
+			panic(err)
+		}
+	}
+	names, err := runner.Names(c.Runners)
+	if err != nil {
+		panic(err)
+	}
+	// The names are the spigot.runner attribute of the metrics.
+	for i, cfg := range c.Runners {
+		if err := cfg.SetString("name", -1, names[i]); err != nil {
+			panic(err)
+		}
+	}
+	if only != "" {
+		names, c.Runners, err = select_runners(names, c.Runners, strings.Split(only, ","))
+		if err != nil {
+			panic(err)
+		}
+	}
+	if validate {
+		if !validate_runners(names, c.Runners) {
+			os.Exit(1)
+		}
+		return
+	}
+	if estimate {
+		if err := estimate_runners(os.Stdout, names, c.Runners); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	prom := start_metrics(metrics)
+	if c.Telemetry != nil {
+		tc := telemetry.DefaultConfig()
+		if err := c.Telemetry.Unpack(&tc); err != nil {
+			panic(err)
+		}
+		shutdown, err := telemetry.Start(context.Background(), tc, c.Labels, readers(prom)...)
+		if err != nil {
+			panic(err)
+		}
+		defer func() {
+			_ = shutdown(context.Background())
+		}()
+	} else if prom != nil {
+		if _, err := telemetry.StartMetrics(c.Labels, prom.Reader()); err != nil {
+			panic(err)
+		}
 	}
 
+	if err := daemon.Run("spigot", func(ctx context.Context) error {
+		if quiet {
+			progress = 0
+		}
+		if prom != nil {
+			go serve_metrics(ctx, metrics, prom)
+		}
+		return execute_runners(ctx, names, c.Runners, progress)
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// init_config writes the configuration of the profile of args to the
+// file of its -c, which it does not overwrite without -f.  Without a
+// profile it writes a table of the profiles to w.
+func init_config(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	cfgFile := fs.String("c", "./spigot.yml", "path of the configuration file to write")
+	force := fs.Bool("f", false, "overwrite the configuration file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "PROFILE\tDESCRIPTION")
+		for _, p := range profile.List() {
+			fmt.Fprintf(tw, "%s\t%s\n", p.Name, p.Description)
+		}
+		return tw.Flush()
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("init expected one profile, got '%s'", strings.Join(fs.Args(), " "))
+	}
+	b, err := profile.Config(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if *force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(*cfgFile, flags, 0o644)
+	if os.IsExist(err) {
+		return fmt.Errorf("'%s' already exists, use -f to overwrite it", *cfgFile)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "wrote the %s profile to %s, run it with: spigot -c %s\n", fs.Arg(0), *cfgFile, *cfgFile)
+	return nil
+}
+
+// build_entities builds the population of the entities config cfg,
+// with the top level seed when it has no seed of its own, and shares
+// it with the generators.
+func build_entities(cfg *ucfg.Config, seed *int64) error {
+	if seed != nil {
+		ok, err := cfg.Has("seed", -1)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if err := cfg.SetInt("seed", -1, *seed); err != nil {
+				return err
+			}
+		}
+	}
+	p, err := entities.Build(cfg)
+	if err != nil {
+		return err
+	}
+	entities.Set(p)
+	return nil
+}
+
+// select_runners returns the names and configs of the runners named
+// in only, in the order of the configuration file.
+func select_runners(names []string, cfgs []*ucfg.Config, only []string) ([]string, []*ucfg.Config, error) {
+	want := make(map[string]bool, len(only))
+	for _, n := range only {
+		want[strings.TrimSpace(n)] = true
+	}
+	var selNames []string
+	var selCfgs []*ucfg.Config
+	for i, n := range names {
+		if want[n] {
+			selNames = append(selNames, n)
+			selCfgs = append(selCfgs, cfgs[i])
+			delete(want, n)
+		}
+	}
+	for n := range want {
+		return nil, nil, fmt.Errorf("no runner named '%s'", n)
+	}
+	return selNames, selCfgs, nil
+}
+
+// validate_runners prints the problems in the runner configs and the
+// issues in the templates of their generators.  It returns false if a
+// config is not valid or a template has an error.
+func validate_runners(names []string, cfgs []*ucfg.Config) bool {
+	ok := true
+	for i, cfg := range cfgs {
+		issues, err := runner.Validate(cfg, lintSamples)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", names[i], err)
+			ok = false
+			continue
+		}
+		for _, issue := range issues {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", names[i], issue)
+			if !issue.Warning {
+				ok = false
+			}
+		}
+	}
+	return ok
+}
+
+// estimate_runners writes a table of the projected events and GB per
+// day of each runner, and of all runners, to w.
+func estimate_runners(w io.Writer, names []string, cfgs []*ucfg.Config) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "RUNNER\tGENERATOR\tINTERVAL\tRECORDS\tAVG BYTES\tEVENTS/DAY\tGB/DAY\t")
+	var events, bytes float64
+	for i, cfg := range cfgs {
+		p, err := runner.Estimate(cfg, estimateSamples)
+		if err != nil {
+			return fmt.Errorf("%s: %w", names[i], err)
+		}
+		interval := "once"
+		if p.Interval > 0 {
+			interval = p.Interval.String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%.1f\t%.0f\t%.3f\t\n", strings.TrimPrefix(names[i], "runner "), p.Generator, interval, p.Records, p.AverageBytes, p.EventsPerDay, p.BytesPerDay/1e9)
+		events += p.EventsPerDay
+		bytes += p.BytesPerDay
+	}
+	fmt.Fprintf(tw, "TOTAL\t\t\t\t\t%.0f\t%.3f\t\n", events, bytes/1e9)
+	return tw.Flush()
+}
+
+// execute_runners runs the runners until they are done or ctx is
+// canceled.  All runners are canceled when one of them fails.  The
+// runners that stop by themselves report their progress every
+// progress, unless it is 0.
+func execute_runners(ctx context.Context, names []string, cfgs []*ucfg.Config, progress time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	resultCh := make(chan Result)
 
-	for _, rCfg := range c.Runners {
-		rCfg := rCfg
+	for i, rCfg := range cfgs {
+		name, rCfg := names[i], rCfg
 		go func() {
-			execute_runner(rCfg, resultCh)
+			execute_runner(ctx, name, rCfg, progress, resultCh)
 		}()
 	}
 
-	for i := 0; i < len(c.Runners); i++ {
+	var err error
+	for i := 0; i < len(cfgs); i++ {
 		r := <-resultCh
-		if !r.Done {
-			panic(r.Error)
+		if !r.Done && err == nil {
+			err = r.Error
+			cancel()
+		}
+	}
+	return err
+}
+
+// start_metrics returns the Prometheus handler of the metrics served
+// on addr, or nil without an address.
+func start_metrics(addr string) *telemetry.Prometheus {
+	if addr == "" {
+		return nil
+	}
+	return telemetry.NewPrometheus()
+}
+
+// readers returns the reader of prom, if there is one.
+func readers(prom *telemetry.Prometheus) []sdkmetric.Reader {
+	if prom == nil {
+		return nil
+	}
+	return []sdkmetric.Reader{prom.Reader()}
+}
+
+// serve_metrics serves prom on /metrics of addr until ctx is canceled.
+// Errors are written to stderr, they do not stop the runners.
+func serve_metrics(ctx context.Context, addr string, prom *telemetry.Prometheus) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", prom)
+	if err := serve(ctx, addr, mux); err != nil && ctx.Err() == nil {
+		fmt.Fprintf(os.Stderr, "metrics: %v\n", err)
+	}
+}
+
+// serve serves h on addr until ctx is canceled.
+func serve(ctx context.Context, addr string, h http.Handler) error {
+	srv := &http.Server{Addr: addr, Handler: h}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		if err := srv.Shutdown(context.Background()); err != nil {
+			return err
 		}
+		return ctx.Err()
 	}
 }
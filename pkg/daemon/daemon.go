@@ -0,0 +1,132 @@
+// Package daemon integrates spigot with the init system of the host,
+// so long running instances can be managed like any other service.
+//
+// Under systemd spigot reports readiness and shutdown with sd_notify
+// and, when the unit has WatchdogSec set, pings the watchdog while it
+// is running.  A unit for a load instance looks like:
+//
+//	[Service]
+//	Type=notify
+//	ExecStart=/usr/local/bin/spigot -c /etc/spigot/spigot.yml
+//	WatchdogSec=30s
+//	Restart=on-failure
+//
+// On Windows spigot runs as a service when it is started by the
+// service control manager, and stops when the service is stopped or
+// the host shuts down.
+//
+// Outside of an init system Run only stops on SIGINT and SIGTERM.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Notifications of the sd_notify protocol.
+const (
+	NotifyReady    = "READY=1"
+	NotifyStopping = "STOPPING=1"
+	NotifyWatchdog = "WATCHDOG=1"
+)
+
+// Notify sends state to the service manager over the socket in
+// NOTIFY_SOCKET.  If NOTIFY_SOCKET is not set it returns false and
+// does nothing.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// abstract sockets start with @
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns how often the watchdog of the service
+// manager has to be pinged, half of WATCHDOG_USEC.  It returns 0 if
+// the watchdog is not enabled for this process.
+func WatchdogInterval() (time.Duration, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return 0, nil
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("'%s' is not a valid value for 'WATCHDOG_USEC' expected a number greater than 0", usec)
+	}
+	return time.Duration(n) * time.Microsecond / 2, nil
+}
+
+// runNotify runs run with sd_notify readiness, stopping and watchdog
+// notifications.  Readiness is sent once run has been started.
+func runNotify(ctx context.Context, run func(context.Context) error) error {
+	interval, err := WatchdogInterval()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- run(ctx)
+	}()
+
+	if _, err := Notify(NotifyReady); err != nil {
+		cancel()
+		<-done
+		return err
+	}
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case err := <-done:
+			_, _ = Notify(NotifyStopping)
+			return err
+		case <-ctx.Done():
+			_, _ = Notify(NotifyStopping)
+			return stopped(<-done)
+		case <-tick:
+			_, _ = Notify(NotifyWatchdog)
+		}
+	}
+}
+
+// stopped returns err of a run that was asked to stop, which is nil if
+// run returned because its context was canceled.
+func stopped(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Run runs run until it returns or spigot is asked to stop, with
+// SIGINT or SIGTERM, which cancels the context of run.  Under systemd
+// the service manager is notified when spigot is ready and stopping,
+// and the watchdog is pinged.  name is only used on Windows.
+func Run(name string, run func(context.Context) error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return runNotify(ctx, run)
+}
@@ -0,0 +1,100 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func listen(t *testing.T) *net.UnixConn {
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	assert.Nil(t, err)
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", socket)
+	return conn
+}
+
+func receive(t *testing.T, conn *net.UnixConn) string {
+	buf := make([]byte, 256)
+	assert.Nil(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, err := conn.Read(buf)
+	assert.Nil(t, err)
+	return string(buf[:n])
+}
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	sent, err := Notify(NotifyReady)
+	assert.Nil(t, err)
+	assert.False(t, sent)
+
+	conn := listen(t)
+	sent, err = Notify(NotifyReady)
+	assert.Nil(t, err)
+	assert.True(t, sent)
+	assert.Equal(t, NotifyReady, receive(t, conn))
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	tests := map[string]struct {
+		usec     string
+		pid      string
+		expected time.Duration
+		hasErr   bool
+	}{
+		"disabled":  {},
+		"enabled":   {usec: "30000000", expected: 15 * time.Second},
+		"this pid":  {usec: "30000000", pid: strconv.Itoa(os.Getpid()), expected: 15 * time.Second},
+		"other pid": {usec: "30000000", pid: "1"},
+		"invalid":   {usec: "soon", hasErr: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", tc.usec)
+			t.Setenv("WATCHDOG_PID", tc.pid)
+			got, err := WatchdogInterval()
+			if tc.hasErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestRunNotify(t *testing.T) {
+	conn := listen(t)
+	t.Setenv("WATCHDOG_USEC", "20000")
+	t.Setenv("WATCHDOG_PID", "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- runNotify(ctx, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+
+	assert.Equal(t, NotifyReady, receive(t, conn))
+	assert.Equal(t, NotifyWatchdog, receive(t, conn))
+
+	cancel()
+	assert.Nil(t, <-errCh)
+	for {
+		if got := receive(t, conn); got != NotifyWatchdog {
+			assert.Equal(t, NotifyStopping, got)
+			break
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Config is the configuration of a Population.
+type Config struct {
+	Users     int      `config:"users"`
+	Hosts     int      `config:"hosts"`
+	Groups    []string `config:"groups"`
+	Domain    string   `config:"domain"`
+	Networks  []string `config:"networks"`
+	Timezones []string `config:"timezones"`
+	OffHours  float64  `config:"off_hours"`
+	Seed      *int64   `config:"seed"`
+	File      string   `config:"file"`
+}
+
+// DefaultConfig returns the Config of a population of 100 users with a
+// workstation each, in 10.0.0.0/16, working in the timezones of
+// offices in America, Europe and Asia.
+func DefaultConfig() Config {
+	return Config{
+		Users:     100,
+		Hosts:     100,
+		Groups:    []string{"Engineering", "Sales", "Marketing", "Finance", "Human Resources", "Support", "Legal", "IT"},
+		Domain:    "example.com",
+		Networks:  []string{"10.0.0.0/16"},
+		Timezones: []string{"America/New_York", "America/New_York", "America/Chicago", "America/Los_Angeles", "Europe/London", "Europe/Berlin", "Asia/Kolkata", "Asia/Tokyo"},
+		OffHours:  5,
+	}
+}
+
+func (c *Config) Validate() error {
+	if c.OffHours < 0 || c.OffHours > 100 {
+		return fmt.Errorf("'%v' is not a valid value for 'off_hours' expected a percentage from 0 to 100", c.OffHours)
+	}
+	if c.File != "" {
+		return nil
+	}
+	if c.Users < 1 {
+		return fmt.Errorf("'%d' is not a valid value for 'users' expected a value greater than 0", c.Users)
+	}
+	if c.Hosts < 1 {
+		return fmt.Errorf("'%d' is not a valid value for 'hosts' expected a value greater than 0", c.Hosts)
+	}
+	if len(c.Groups) == 0 {
+		return fmt.Errorf("'groups' expected at least one group")
+	}
+	if c.Domain == "" || strings.ContainsAny(c.Domain, " \t\n@") {
+		return fmt.Errorf("'%s' is not a valid value for 'domain' expected a domain name", c.Domain)
+	}
+	if len(c.Networks) == 0 {
+		return fmt.Errorf("'networks' expected at least one network")
+	}
+	if _, err := random.NewPool("networks", c.Networks); err != nil {
+		return err
+	}
+	if len(c.Timezones) == 0 {
+		return fmt.Errorf("'timezones' expected at least one timezone")
+	}
+	for _, tz := range c.Timezones {
+		if _, err := time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("'%s' is not a valid value for 'timezones' expected a timezone such as Europe/Berlin", tz)
+		}
+	}
+	return nil
+}
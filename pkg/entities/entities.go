@@ -0,0 +1,307 @@
+// Package entities builds a population of users and hosts that the
+// generators share, so the same user appears, from the same host and
+// address, in the firewall, authentication and web logs of a run, as
+// entity analytics expect.
+//
+// The population is configured with the top level "entities" of the
+// config file.  It is built from a seed, so every run has the same
+// population, or loaded from a JSON file.
+//
+//	entities:
+//	  users: 500
+//	  hosts: 400
+//	  groups: [Engineering, Sales, Finance]
+//	  domain: "corp.example.com"
+//	  networks: ["10.10.0.0/16", "10.20.0.0/16"]
+//	  seed: 7
+//
+// "users" and "hosts" are the size of the population, by default 100
+// of each.  Every user has a login, such as "jane.doe", an email
+// address at "domain", a group of "groups" and a workstation.  Users
+// share the hosts when there are fewer hosts than users.  The hosts
+// have a name, an address of "networks", by default 10.0.0.0/16, and
+// a MAC address.  Without a "seed" the top level seed of the config
+// file is used, and without either the population is random.
+//
+// Every user also works from a home timezone of "timezones", from a
+// start between 7 and 10 in the morning for 8 or 9 hours, Monday to
+// Friday.  The generators draw the users who are at work at the time
+// of an event, see ActiveUser, and off hours a user is "off_hours"
+// percent as likely to act, by default 5, so that detections of
+// activity at unusual times have a baseline to learn.
+//
+// "file" is a JSON file of the population instead, with the users and
+// hosts in the fields of User and Host.  A user without a "timezone"
+// works in UTC, and a user without "start" and "end" hours is always
+// at work:
+//
+//	{"users": [{"name": "jane.doe", "display_name": "Jane Doe", "email": "jane.doe@example.com", "group": "Sales", "host": "DESKTOP-4F7Q2ZK", "timezone": "Europe/Berlin", "start": 8, "end": 17}],
+//	 "hosts": [{"name": "DESKTOP-4F7Q2ZK", "ip": "10.0.4.17", "mac": "00:14:22:9a:01:f3", "os": "windows"}]}
+//
+// A generator draws from the population with "entities: true" in its
+// config, see New.
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	// The timezones of the users do not depend on the timezone
+	// database of the system.
+	_ "time/tzdata"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+var (
+	firstNames = [...]string{"Aaron", "Beatriz", "Chloe", "Dmitri", "Elena", "Felix", "Gabriela", "Hassan", "Ingrid", "Jamal", "Keiko", "Liam", "Maya", "Nikolai", "Olivia", "Pedro", "Quinn", "Rosa", "Samuel", "Tara", "Umar", "Vera", "Wei", "Ximena", "Yusuf", "Zoe"}
+	lastNames  = [...]string{"Adams", "Bakker", "Costa", "Dubois", "Eriksson", "Fernandez", "Gupta", "Hoffmann", "Ivanov", "Johnson", "Kowalski", "Lee", "Murphy", "Nakamura", "Okafor", "Petrov", "Quinn", "Rossi", "Schmidt", "Tanaka", "Usman", "Vargas", "Williams", "Xu", "Yilmaz", "Zhang"}
+	pcVendors  = [...]string{"dell", "hp", "intel"}
+)
+
+// User is a user of a population.  Host is the name of their
+// workstation.  They work from Start to End, the hours of the day in
+// their Timezone, on weekdays.  End is before Start for a night shift.
+type User struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Email       string `json:"email"`
+	Group       string `json:"group"`
+	Host        string `json:"host"`
+	Timezone    string `json:"timezone"`
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+
+	loc *time.Location
+}
+
+// Host is a host of a population.  OS is windows, macos or linux.
+type Host struct {
+	Name string `json:"name"`
+	IP   net.IP `json:"ip"`
+	MAC  string `json:"mac"`
+	OS   string `json:"os"`
+}
+
+// Population is the users and hosts the generators share.
+type Population struct {
+	Users []User `json:"users"`
+	Hosts []Host `json:"hosts"`
+
+	hosts    map[string]int
+	offHours float64
+}
+
+var (
+	mu     sync.Mutex
+	shared *Population
+)
+
+// Set makes p the population the generators draw from.
+func Set(p *Population) {
+	mu.Lock()
+	defer mu.Unlock()
+	shared = p
+}
+
+// Shared returns the population of Set, or nil when there is none.
+func Shared() *Population {
+	mu.Lock()
+	defer mu.Unlock()
+	return shared
+}
+
+type generatorConfig struct {
+	Entities bool `config:"entities"`
+}
+
+// New returns the shared population when the generator config cfg
+// has "entities: true", or nil when it does not.  It is an error to
+// ask for the population when none is configured.
+func New(cfg *ucfg.Config) (*Population, error) {
+	c := generatorConfig{}
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	if !c.Entities {
+		return nil, nil
+	}
+	p := Shared()
+	if p == nil {
+		return nil, fmt.Errorf("'entities' requires the top level 'entities' of the config file")
+	}
+	return p, nil
+}
+
+// Build returns the population of the "entities" config cfg.
+func Build(cfg *ucfg.Config) (*Population, error) {
+	c := DefaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	if c.File != "" {
+		p, err := Load(c.File)
+		if err != nil {
+			return nil, err
+		}
+		p.offHours = c.OffHours
+		return p, nil
+	}
+	// Validate checked the networks.
+	networks, _ := random.NewPool("networks", c.Networks)
+	r := random.NewRand(c.Seed)
+
+	p := &Population{offHours: c.OffHours}
+	names, ips := map[string]bool{}, map[string]bool{}
+	for i := 0; i < c.Hosts; i++ {
+		var h Host
+		for h.Name == "" || names[h.Name] {
+			h.Name, h.OS = random.WorkstationName(r), "windows"
+			switch n := r.IntN(10); {
+			case n == 0:
+				h.Name, h.OS = random.ServerName(r), "linux"
+			case n < 3:
+				h.OS = "macos"
+			}
+		}
+		names[h.Name] = true
+		// The addresses are unique, unless the networks are too
+		// small for the hosts.
+		for try := 0; try == 0 || (ips[h.IP.String()] && try < 10); try++ {
+			h.IP = networks.IP(r, 0)
+		}
+		ips[h.IP.String()] = true
+		vendor := pcVendors[r.IntN(len(pcVendors))]
+		if h.OS == "macos" {
+			vendor = "apple"
+		}
+		mac, _ := random.VendorMAC(r, vendor)
+		h.MAC = mac.String()
+		p.Hosts = append(p.Hosts, h)
+	}
+	logins := map[string]bool{}
+	for i := 0; i < c.Users; i++ {
+		first := firstNames[r.IntN(len(firstNames))]
+		last := lastNames[r.IntN(len(lastNames))]
+		login := strings.ToLower(first + "." + last)
+		if logins[login] {
+			login += fmt.Sprint(i)
+		}
+		logins[login] = true
+		start := 7 + r.IntN(4)
+		p.Users = append(p.Users, User{
+			Name:        login,
+			DisplayName: first + " " + last,
+			Email:       login + "@" + c.Domain,
+			Group:       c.Groups[r.IntN(len(c.Groups))],
+			Host:        p.Hosts[i%len(p.Hosts)].Name,
+			Timezone:    c.Timezones[r.IntN(len(c.Timezones))],
+			Start:       start,
+			End:         start + 8 + r.IntN(2),
+		})
+	}
+	// Validate checked the timezones.
+	_ = p.index()
+	return p, nil
+}
+
+// Load returns the population of the JSON file path.
+func Load(path string) (*Population, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &Population{offHours: DefaultConfig().OffHours}
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, fmt.Errorf("entities file %s: %w", path, err)
+	}
+	if len(p.Users) == 0 || len(p.Hosts) == 0 {
+		return nil, fmt.Errorf("entities file %s expected at least one user and one host", path)
+	}
+	if err := p.index(); err != nil {
+		return nil, fmt.Errorf("entities file %s: %w", path, err)
+	}
+	for _, u := range p.Users {
+		if _, ok := p.hosts[u.Host]; !ok {
+			return nil, fmt.Errorf("entities file %s: user %s has host '%s' that is not in the hosts", path, u.Name, u.Host)
+		}
+		if u.Start < 0 || u.Start > 23 || u.End < 0 || u.End > 24 {
+			return nil, fmt.Errorf("entities file %s: user %s has hours '%d-%d' expected hours from 0 to 24", path, u.Name, u.Start, u.End)
+		}
+	}
+	return p, nil
+}
+
+func (p *Population) index() error {
+	p.hosts = make(map[string]int, len(p.Hosts))
+	for i, h := range p.Hosts {
+		p.hosts[h.Name] = i
+	}
+	for i := range p.Users {
+		u := &p.Users[i]
+		loc, err := time.LoadLocation(u.Timezone)
+		if err != nil {
+			return fmt.Errorf("user %s has timezone '%s' that is not a valid timezone", u.Name, u.Timezone)
+		}
+		u.loc = loc
+	}
+	return nil
+}
+
+// User returns a random user of the population.
+func (p *Population) User(r *rand.Rand) User {
+	return p.Users[r.IntN(len(p.Users))]
+}
+
+// ActiveUser returns a random user of the population who is at work
+// at t, or off hours with the "off_hours" chance.  When no one is at
+// work it returns any user after a number of draws.
+func (p *Population) ActiveUser(r *rand.Rand, t time.Time) User {
+	var u User
+	for try := 0; try < 100; try++ {
+		if u = p.User(r); p.Acts(r, u, t) {
+			break
+		}
+	}
+	return u
+}
+
+// Acts reports whether u acts at t: always at work, and with the
+// "off_hours" chance off hours.
+func (p *Population) Acts(r *rand.Rand, u User, t time.Time) bool {
+	return u.Working(t) || r.Float64()*100 < p.offHours
+}
+
+// Working reports whether t is in the working hours of u.
+func (u User) Working(t time.Time) bool {
+	if u.Start == u.End {
+		return true
+	}
+	if u.loc != nil {
+		t = t.In(u.loc)
+	}
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	h := t.Hour()
+	if u.Start < u.End {
+		return h >= u.Start && h < u.End
+	}
+	return h >= u.Start || h < u.End
+}
+
+// Host returns a random host of the population.
+func (p *Population) Host(r *rand.Rand) Host {
+	return p.Hosts[r.IntN(len(p.Hosts))]
+}
+
+// HostOf returns the workstation of u.
+func (p *Population) HostOf(u User) Host {
+	return p.Hosts[p.hosts[u.Host]]
+}
@@ -0,0 +1,180 @@
+package entities
+
+import (
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Defaults": {
+			c: map[string]interface{}{},
+		},
+		"Valid Options": {
+			c: map[string]interface{}{"users": 20, "hosts": 5, "groups": []interface{}{"Ops"}, "domain": "corp.example.com", "networks": []interface{}{"192.168.1.0/24"}, "seed": 3},
+		},
+		"Invalid Users": {
+			c:           map[string]interface{}{"users": 0},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'users' expected a value greater than 0 accessing config",
+		},
+		"Invalid Hosts": {
+			c:           map[string]interface{}{"hosts": -1},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'hosts' expected a value greater than 0 accessing config",
+		},
+		"Invalid Domain": {
+			c:           map[string]interface{}{"domain": "corp example"},
+			hasError:    true,
+			errorString: "'corp example' is not a valid value for 'domain' expected a domain name accessing config",
+		},
+		"Invalid Network": {
+			c:           map[string]interface{}{"networks": []interface{}{"10.0.0.0"}},
+			hasError:    true,
+			errorString: "'10.0.0.0' is not a valid value for 'networks' expected a CIDR such as 10.0.0.0/8 accessing config",
+		},
+		"Invalid Timezone": {
+			c:           map[string]interface{}{"timezones": []interface{}{"Mars/Olympus_Mons"}},
+			hasError:    true,
+			errorString: "'Mars/Olympus_Mons' is not a valid value for 'timezones' expected a timezone such as Europe/Berlin accessing config",
+		},
+		"Invalid Off Hours": {
+			c:           map[string]interface{}{"off_hours": 101},
+			hasError:    true,
+			errorString: "'101' is not a valid value for 'off_hours' expected a percentage from 0 to 100 accessing config",
+		},
+		"Missing File": {
+			c:           map[string]interface{}{"file": "testdata/missing.json"},
+			hasError:    true,
+			errorString: "open testdata/missing.json: no such file or directory",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			_, err = Build(c)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	c := ucfg.MustNewFrom(map[string]interface{}{"users": 300, "hosts": 120, "domain": "corp.example.com", "networks": []interface{}{"10.20.0.0/16"}, "seed": 7})
+	p, err := Build(c)
+	assert.NoError(t, err)
+	assert.Len(t, p.Users, 300)
+	assert.Len(t, p.Hosts, 120)
+
+	names, ips := map[string]bool{}, map[string]bool{}
+	for _, h := range p.Hosts {
+		assert.False(t, names[h.Name], h.Name)
+		assert.False(t, ips[h.IP.String()], h.IP)
+		names[h.Name], ips[h.IP.String()] = true, true
+		assert.True(t, strings.HasPrefix(h.IP.String(), "10.20."), h.IP)
+		assert.Contains(t, []string{"windows", "macos", "linux"}, h.OS)
+	}
+	logins := map[string]bool{}
+	for _, u := range p.Users {
+		assert.False(t, logins[u.Name], u.Name)
+		logins[u.Name] = true
+		assert.Equal(t, u.Name+"@corp.example.com", u.Email)
+		assert.Equal(t, u.Host, p.HostOf(u).Name)
+		assert.Contains(t, DefaultConfig().Timezones, u.Timezone)
+		assert.True(t, u.Start >= 7 && u.Start <= 10, u.Start)
+		assert.True(t, u.End-u.Start == 8 || u.End-u.Start == 9, u.End)
+	}
+
+	again, err := Build(c)
+	assert.NoError(t, err)
+	assert.Equal(t, p.Users, again.Users)
+	assert.Equal(t, p.Hosts, again.Hosts)
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "population.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{
+  "users": [{"name": "jane.doe", "display_name": "Jane Doe", "email": "jane.doe@example.com", "group": "Sales", "host": "DESKTOP-4F7Q2ZK"}],
+  "hosts": [{"name": "DESKTOP-4F7Q2ZK", "ip": "10.0.4.17", "mac": "00:14:22:9a:01:f3", "os": "windows"}]
+}`), 0o600))
+	p, err := Build(ucfg.MustNewFrom(map[string]interface{}{"file": path}))
+	assert.NoError(t, err)
+	assert.Equal(t, "jane.doe", p.Users[0].Name)
+	assert.Equal(t, "10.0.4.17", p.HostOf(p.Users[0]).IP.String())
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"users": [{"name": "jane.doe", "host": "LAPTOP-1"}], "hosts": [{"name": "DESKTOP-4F7Q2ZK"}]}`), 0o600))
+	_, err = Load(path)
+	assert.EqualError(t, err, "entities file "+path+": user jane.doe has host 'LAPTOP-1' that is not in the hosts")
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"users": [{"name": "jane.doe", "host": "LAPTOP-1", "timezone": "Mars/Olympus_Mons"}], "hosts": [{"name": "LAPTOP-1"}]}`), 0o600))
+	_, err = Load(path)
+	assert.EqualError(t, err, "entities file "+path+": user jane.doe has timezone 'Mars/Olympus_Mons' that is not a valid timezone")
+}
+
+func TestWorkingHours(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "population.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{
+  "users": [{"name": "jane.doe", "host": "LAPTOP-1", "timezone": "Europe/Berlin", "start": 8, "end": 17},
+            {"name": "night.owl", "host": "LAPTOP-1", "timezone": "America/Chicago", "start": 22, "end": 6},
+            {"name": "always.on", "host": "LAPTOP-1"}],
+  "hosts": [{"name": "LAPTOP-1"}]
+}`), 0o600))
+	p, err := Build(ucfg.MustNewFrom(map[string]interface{}{"file": path, "off_hours": 0}))
+	assert.NoError(t, err)
+	jane, owl, always := p.Users[0], p.Users[1], p.Users[2]
+
+	// Tuesday 2024-10-15, Berlin is UTC+2 and Chicago UTC-5.
+	tuesday := func(hour int) time.Time { return time.Date(2024, 10, 15, hour, 0, 0, 0, time.UTC) }
+	assert.True(t, jane.Working(tuesday(6)))
+	assert.True(t, jane.Working(tuesday(14)))
+	assert.False(t, jane.Working(tuesday(15)))
+	assert.False(t, jane.Working(tuesday(5)))
+	assert.True(t, owl.Working(tuesday(4)))
+	assert.False(t, owl.Working(tuesday(12)))
+	assert.False(t, jane.Working(time.Date(2024, 10, 19, 10, 0, 0, 0, time.UTC)))
+	assert.True(t, always.Working(time.Date(2024, 10, 19, 3, 0, 0, 0, time.UTC)))
+
+	r := rand.New(rand.NewChaCha8([32]byte{1}))
+	for i := 0; i < 100; i++ {
+		assert.NotEqual(t, jane.Name, p.ActiveUser(r, tuesday(4)).Name)
+		assert.NotEqual(t, owl.Name, p.ActiveUser(r, tuesday(12)).Name)
+	}
+}
+
+func TestNew(t *testing.T) {
+	defer Set(nil)
+
+	p, err := New(ucfg.MustNewFrom(map[string]interface{}{"type": "test"}))
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+
+	_, err = New(ucfg.MustNewFrom(map[string]interface{}{"type": "test", "entities": true}))
+	assert.EqualError(t, err, "'entities' requires the top level 'entities' of the config file")
+
+	want, err := Build(ucfg.MustNewFrom(map[string]interface{}{"seed": 1}))
+	assert.NoError(t, err)
+	Set(want)
+	p, err = New(ucfg.MustNewFrom(map[string]interface{}{"type": "test", "entities": true}))
+	assert.NoError(t, err)
+	assert.Same(t, want, p)
+}
@@ -0,0 +1,135 @@
+// Package expr evaluates Starlark expressions for computed fields,
+// for example a severity derived from an action:
+//
+//	"high" if action == "deny" else "low"
+//
+// Expressions see the variables they are evaluated with.  Values of
+// Go types other than strings, booleans, integers and floats are
+// passed to the expression as their string form.
+package expr
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// Expr is a parsed expression.
+type Expr struct {
+	src  string
+	expr syntax.Expr
+}
+
+// cache holds the parsed expressions used by Eval.
+var cache sync.Map
+
+// Compile parses src as a Starlark expression.
+func Compile(src string) (*Expr, error) {
+	e, err := syntax.ParseExpr("expr", src, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &Expr{src: src, expr: e}, nil
+}
+
+// Eval evaluates the expression with vars as the variables.
+func (e *Expr) Eval(vars map[string]interface{}) (interface{}, error) {
+	env := make(starlark.StringDict, len(vars))
+	for k, v := range vars {
+		env[k] = toValue(v)
+	}
+
+	thread := &starlark.Thread{Name: "expr"}
+	v, err := starlark.EvalExpr(thread, e.expr, env)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating '%s': %w", e.src, err)
+	}
+
+	return fromValue(v), nil
+}
+
+// Eval compiles and evaluates src with vars as the variables.  The
+// compiled expression is cached, so Eval can be called for every log
+// message.
+func Eval(src string, vars map[string]interface{}) (interface{}, error) {
+	if e, ok := cache.Load(src); ok {
+		return e.(*Expr).Eval(vars)
+	}
+
+	e, err := Compile(src)
+	if err != nil {
+		return nil, err
+	}
+	cache.Store(src, e)
+
+	return e.Eval(vars)
+}
+
+// TemplateEval is the "eval" template function.  The first argument
+// is the expression followed by pairs of variable name and value:
+//
+//	{{eval "\"high\" if a == \"deny\" else \"low\"" "a" .Action}}
+func TemplateEval(src string, pairs ...interface{}) (interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("eval of '%s' expects pairs of name and value", src)
+	}
+
+	vars := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		name, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("eval of '%s' expects a string name, got %v", src, pairs[i])
+		}
+		vars[name] = pairs[i+1]
+	}
+
+	return Eval(src, vars)
+}
+
+func toValue(v interface{}) starlark.Value {
+	switch v := v.(type) {
+	case nil:
+		return starlark.None
+	case string:
+		return starlark.String(v)
+	case bool:
+		return starlark.Bool(v)
+	case int:
+		return starlark.MakeInt(v)
+	case int32:
+		return starlark.MakeInt64(int64(v))
+	case int64:
+		return starlark.MakeInt64(v)
+	case uint32:
+		return starlark.MakeUint64(uint64(v))
+	case uint64:
+		return starlark.MakeUint64(v)
+	case float32:
+		return starlark.Float(v)
+	case float64:
+		return starlark.Float(v)
+	}
+	return starlark.String(fmt.Sprint(v))
+}
+
+func fromValue(v starlark.Value) interface{} {
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return nil
+	case starlark.String:
+		return string(v)
+	case starlark.Bool:
+		return bool(v)
+	case starlark.Int:
+		if i, ok := v.Int64(); ok && i >= math.MinInt && i <= math.MaxInt {
+			return int(i)
+		}
+		return v.String()
+	case starlark.Float:
+		return float64(v)
+	}
+	return v.String()
+}
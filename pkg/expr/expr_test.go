@@ -0,0 +1,73 @@
+package expr
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEval(t *testing.T) {
+	tests := map[string]struct {
+		src      string
+		vars     map[string]interface{}
+		expected interface{}
+		hasError bool
+	}{
+		"Conditional": {
+			src:      `"high" if action == "deny" else "low"`,
+			vars:     map[string]interface{}{"action": "deny"},
+			expected: "high",
+		},
+		"Arithmetic": {
+			src:      "duration * 125000",
+			vars:     map[string]interface{}{"duration": 4},
+			expected: 500000,
+		},
+		"Float": {
+			src:      "bytes / duration",
+			vars:     map[string]interface{}{"bytes": 10, "duration": 4},
+			expected: 2.5,
+		},
+		"Stringer": {
+			src:      `ip.startswith("10.")`,
+			vars:     map[string]interface{}{"ip": net.IPv4(10, 1, 2, 3)},
+			expected: true,
+		},
+		"None": {
+			src:      "None",
+			expected: nil,
+		},
+		"Undefined Variable": {
+			src:      "missing + 1",
+			hasError: true,
+		},
+		"Syntax Error": {
+			src:      `"high" if`,
+			hasError: true,
+		},
+	}
+	for name, tc := range tests {
+		got, err := Eval(tc.src, tc.vars)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			continue
+		}
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, got, name)
+	}
+}
+
+func TestTemplateEval(t *testing.T) {
+	tmpl, err := template.New("t").Funcs(template.FuncMap{"eval": TemplateEval}).Parse(`severity={{eval "5 if a == 'deny' else 1" "a" .Action}}`)
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	assert.Nil(t, tmpl.Execute(&buf, struct{ Action string }{Action: "deny"}))
+	assert.Equal(t, "severity=5", buf.String())
+
+	_, err = TemplateEval("a", "a")
+	assert.EqualError(t, err, "eval of 'a' expects pairs of name and value")
+}
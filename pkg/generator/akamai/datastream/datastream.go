@@ -0,0 +1,294 @@
+// Package datastream generates Akamai DataStream 2 log records.
+//
+// Records are JSON objects with the DataStream 2 field set, as
+// delivered to a destination with the JSON log format.  As in the
+// real stream all values are strings.  The edge server IP is in
+// edgeIP, and the path the request took through the edge, parent and
+// origin servers is in breadcrumbs.
+//
+// Configuration:
+//
+//   - generator:
+//     type: akamai:datastream
+package datastream
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "akamai:datastream"
+
+var (
+	hosts = [...]struct {
+		host string
+		cp   string
+	}{
+		{host: "www.example.com", cp: "123456"},
+		{host: "static.example.com", cp: "123457"},
+		{host: "api.example.com", cp: "234567"},
+		{host: "media.example.net", cp: "345678"},
+	}
+	paths = [...]struct {
+		path        string
+		contentType string
+	}{
+		{path: "/", contentType: "text/html"},
+		{path: "/index.html", contentType: "text/html"},
+		{path: "/assets/app.js", contentType: "application/javascript"},
+		{path: "/assets/site.css", contentType: "text/css"},
+		{path: "/images/hero.jpg", contentType: "image/jpeg"},
+		{path: "/api/v1/products", contentType: "application/json"},
+		{path: "/api/v1/cart", contentType: "application/json"},
+		{path: "/video/intro/segment-0042.ts", contentType: "video/MP2T"},
+	}
+	methods       = [...]string{"GET", "GET", "GET", "GET", "HEAD", "POST", "PUT", "OPTIONS"}
+	statuses      = [...]int{200, 200, 200, 200, 200, 206, 301, 304, 304, 403, 404, 500, 503}
+	tlsVersions   = [...]string{"TLSv1.2", "TLSv1.3", "TLSv1.3"}
+	protos        = [...]string{"HTTP/1.1", "HTTP/2", "HTTP/2", "HTTP/3"}
+	cacheStatuses = [...]string{"0", "1", "1", "1", "2", "3"}
+	countries     = [...]struct {
+		country string
+		state   string
+		city    string
+	}{
+		{country: "US", state: "CA", city: "SANJOSE"},
+		{country: "US", state: "NY", city: "NEWYORK"},
+		{country: "GB", state: "", city: "LONDON"},
+		{country: "DE", state: "HE", city: "FRANKFURT"},
+		{country: "JP", state: "13", city: "TOKYO"},
+		{country: "BR", state: "SP", city: "SAOPAULO"},
+	}
+	billingRegions = [...]string{"1", "2", "3", "4", "7"}
+	languages      = [...]string{"en-US,en;q=0.9", "de-DE,de;q=0.8", "ja-JP", "pt-BR,pt;q=0.9", "-"}
+	referers       = [...]string{"-", "https://www.example.com/", "https://www.google.com/", "https://t.co/"}
+	errorCodes     = [...]string{"ERR_ACCESS_DENIED|fwd_acl", "ERR_CONNECT_FAIL|errno=111", "ERR_READ_TIMEOUT|origin"}
+)
+
+// Record holds the fields of a DataStream 2 log record.
+type Record struct {
+	Version            string `json:"version"`
+	CP                 string `json:"cp"`
+	ReqID              string `json:"reqId"`
+	ReqTimeSec         string `json:"reqTimeSec"`
+	Bytes              string `json:"bytes"`
+	CliIP              string `json:"cliIP"`
+	StatusCode         string `json:"statusCode"`
+	Proto              string `json:"proto"`
+	ReqHost            string `json:"reqHost"`
+	ReqMethod          string `json:"reqMethod"`
+	ReqPath            string `json:"reqPath"`
+	ReqPort            string `json:"reqPort"`
+	RspContentLen      string `json:"rspContentLen"`
+	RspContentType     string `json:"rspContentType"`
+	UA                 string `json:"UA"`
+	TLSOverheadTimeMs  string `json:"tlsOverheadTimeMSec"`
+	TLSVersion         string `json:"tlsVersion"`
+	ObjSize            string `json:"objSize"`
+	UncompressedSize   string `json:"uncompressedSize"`
+	OverheadBytes      string `json:"overheadBytes"`
+	TotalBytes         string `json:"totalBytes"`
+	QueryStr           string `json:"queryStr"`
+	Breadcrumbs        string `json:"breadcrumbs"`
+	AccLang            string `json:"accLang"`
+	Cookie             string `json:"cookie"`
+	Range              string `json:"range"`
+	Referer            string `json:"referer"`
+	XForwardedFor      string `json:"xForwardedFor"`
+	MaxAgeSec          string `json:"maxAgeSec"`
+	ReqEndTimeMSec     string `json:"reqEndTimeMSec"`
+	ErrorCode          string `json:"errorCode"`
+	TurnAroundTimeMSec string `json:"turnAroundTimeMSec"`
+	TransferTimeMSec   string `json:"transferTimeMSec"`
+	DNSLookupTimeMSec  string `json:"dnsLookupTimeMSec"`
+	LastByte           string `json:"lastByte"`
+	EdgeIP             string `json:"edgeIP"`
+	Country            string `json:"country"`
+	State              string `json:"state"`
+	City               string `json:"city"`
+	ServerCountry      string `json:"serverCountry"`
+	BillingRegion      string `json:"billingRegion"`
+	CacheStatus        string `json:"cacheStatus"`
+	Cacheable          string `json:"cacheable"`
+	StreamID           string `json:"streamId"`
+}
+
+// Generator provides an Akamai DataStream 2 record generator.
+type Generator struct {
+	Data Record
+
+	rand       *rand.Rand
+	pins       *generator.Pins
+	streamID   string
+	staticTime *time.Time
+	clock      *generator.Clock
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the factory for Akamai DataStream 2 objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	g := Generator{
+		rand:     r,
+		clock:    clock,
+		streamID: strconv.Itoa(r.IntN(90000) + 10000),
+	}
+
+	g.pins, err = generator.NewPins(cfg, r, &g.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+// Next produces the next Akamai DataStream 2 record.
+func (g *Generator) Next() ([]byte, error) {
+	g.randomize()
+
+	data, err := json.Marshal(&g.Data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal %s data: %w", Name, err)
+	}
+
+	return data, nil
+}
+
+func (g *Generator) randomize() {
+	now := g.getTime()
+	h := hosts[g.rand.IntN(len(hosts))]
+	p := paths[g.rand.IntN(len(paths))]
+	loc := countries[g.rand.IntN(len(countries))]
+	status := statuses[g.rand.IntN(len(statuses))]
+	cacheStatus := cacheStatuses[g.rand.IntN(len(cacheStatuses))]
+	edge := random.IPv4(g.rand).String()
+
+	objSize := g.rand.IntN(512*1024) + 128
+	if status == 304 || status >= 400 {
+		objSize = 0
+	}
+	overhead := g.rand.IntN(800) + 200
+	turnAround := g.rand.IntN(300) + 1
+	transfer := g.rand.IntN(1000)
+
+	g.Data = Record{
+		Version:            "1",
+		CP:                 h.cp,
+		ReqID:              fmt.Sprintf("%x", g.rand.Uint32()),
+		ReqTimeSec:         fmt.Sprintf("%d.%03d", now.Unix(), now.Nanosecond()/int(time.Millisecond)),
+		Bytes:              strconv.Itoa(objSize),
+		CliIP:              random.IPv4(g.rand).String(),
+		StatusCode:         strconv.Itoa(status),
+		Proto:              protos[g.rand.IntN(len(protos))],
+		ReqHost:            h.host,
+		ReqMethod:          methods[g.rand.IntN(len(methods))],
+		ReqPath:            strings.TrimPrefix(p.path, "/"),
+		ReqPort:            "443",
+		RspContentLen:      strconv.Itoa(objSize),
+		RspContentType:     p.contentType,
+		UA:                 url.QueryEscape(random.UserAgent(g.rand)),
+		TLSOverheadTimeMs:  strconv.Itoa(g.rand.IntN(50)),
+		TLSVersion:         tlsVersions[g.rand.IntN(len(tlsVersions))],
+		ObjSize:            strconv.Itoa(objSize),
+		UncompressedSize:   strconv.Itoa(objSize * (g.rand.IntN(3) + 1)),
+		OverheadBytes:      strconv.Itoa(overhead),
+		TotalBytes:         strconv.Itoa(objSize + overhead),
+		QueryStr:           g.query(),
+		AccLang:            languages[g.rand.IntN(len(languages))],
+		Cookie:             "-",
+		Range:              "-",
+		Referer:            url.QueryEscape(referers[g.rand.IntN(len(referers))]),
+		XForwardedFor:      "-",
+		MaxAgeSec:          strconv.Itoa([...]int{0, 60, 300, 3600, 86400}[g.rand.IntN(5)]),
+		ReqEndTimeMSec:     strconv.Itoa(g.rand.IntN(20)),
+		ErrorCode:          "-",
+		TurnAroundTimeMSec: strconv.Itoa(turnAround),
+		TransferTimeMSec:   strconv.Itoa(transfer),
+		DNSLookupTimeMSec:  "-",
+		LastByte:           "1",
+		EdgeIP:             edge,
+		Country:            loc.country,
+		State:              loc.state,
+		City:               loc.city,
+		ServerCountry:      loc.country,
+		BillingRegion:      billingRegions[g.rand.IntN(len(billingRegions))],
+		CacheStatus:        cacheStatus,
+		Cacheable:          "1",
+		StreamID:           g.streamID,
+	}
+	if g.Data.ReqPath == "" {
+		g.Data.ReqPath = "-"
+	}
+	if status >= 500 {
+		g.Data.ErrorCode = errorCodes[g.rand.IntN(len(errorCodes))]
+	}
+	if strings.HasPrefix(p.contentType, "application/json") || g.Data.ReqMethod != "GET" {
+		g.Data.Cacheable = "0"
+		cacheStatus = "0"
+		g.Data.CacheStatus = cacheStatus
+	}
+	if cacheStatus == "0" {
+		g.Data.DNSLookupTimeMSec = strconv.Itoa(g.rand.IntN(30))
+	}
+
+	g.Data.Breadcrumbs = breadcrumbs(g.rand, edge, cacheStatus, turnAround)
+
+	g.pins.Apply(&g.Data)
+}
+
+// breadcrumbs returns the URL encoded breadcrumbs for a request.  Each
+// breadcrumb lists a server that handled the request: c=g is the edge
+// (ghost) server, c=p a parent server and c=o the origin.  Cache hits
+// on the edge only have the edge breadcrumb.
+func breadcrumbs(r *rand.Rand, edge, cacheStatus string, latency int) string {
+	crumbs := []string{fmt.Sprintf("[a=%s,c=g,k=0,l=%d]", edge, r.IntN(latency)+1)}
+	switch cacheStatus {
+	case "0":
+		crumbs = append(crumbs, fmt.Sprintf("[a=%s,c=o,k=0,l=%d]", random.IPv4(r), latency))
+	case "2", "3":
+		crumbs = append(crumbs, fmt.Sprintf("[a=%s,c=p,k=0,l=%d]", random.IPv4(r), latency))
+	}
+	return "//BC/" + url.PathEscape(strings.Join(crumbs, ","))
+}
+
+func (g *Generator) getTime() time.Time {
+	if g.staticTime != nil {
+		return *g.staticTime
+	}
+
+	return g.clock.Now()
+}
+
+// query returns the query string of a request, "-" for half of them.
+func (g *Generator) query() string {
+	if g.rand.IntN(2) == 0 {
+		return "-"
+	}
+	return random.Query(g.rand)
+}
@@ -0,0 +1,51 @@
+package datastream
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	random.Seed(1)
+	g := newGenerator(t)
+	got, err := g.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"version":"1","cp":"345678","reqId":"276fb8ca","reqTimeSec":"97445.123","bytes":"0","cliIP":"241.213.67.2","statusCode":"403","proto":"HTTP/2","reqHost":"media.example.net","reqMethod":"GET","reqPath":"images/hero.jpg","reqPort":"443","rspContentLen":"0","rspContentType":"image/jpeg","UA":"Mozilla%2F5.0+%28iPhone%3B+CPU+iPhone+OS+15_4+like+Mac+OS+X%29+AppleWebKit%2F605.1.15+%28KHTML%2C+like+Gecko%29+CriOS%2F99.0.4844.59+Mobile%2F15E148+Safari%2F604.1","tlsOverheadTimeMSec":"41","tlsVersion":"TLSv1.3","objSize":"0","uncompressedSize":"0","overheadBytes":"402","totalBytes":"402","queryStr":"-","breadcrumbs":"//BC/%5Ba=148.3.128.236%2Cc=g%2Ck=0%2Cl=8%5D%2C%5Ba=110.254.97.57%2Cc=o%2Ck=0%2Cl=186%5D","accLang":"de-DE,de;q=0.8","cookie":"-","range":"-","referer":"https%3A%2F%2Ft.co%2F","xForwardedFor":"-","maxAgeSec":"60","reqEndTimeMSec":"7","errorCode":"-","turnAroundTimeMSec":"186","transferTimeMSec":"910","dnsLookupTimeMSec":"16","lastByte":"1","edgeIP":"148.3.128.236","country":"BR","state":"SP","city":"SAOPAULO","serverCountry":"BR","billingRegion":"1","cacheStatus":"0","cacheable":"1","streamId":"88945"}`, string(got))
+}
+
+func TestBreadcrumbs(t *testing.T) {
+	random.Seed(1)
+	g := newGenerator(t)
+	for i := 0; i < 1000; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+
+		var r Record
+		assert.Nil(t, json.Unmarshal(got, &r))
+		crumbs, err := url.PathUnescape(strings.TrimPrefix(r.Breadcrumbs, "//BC/"))
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(crumbs, "[a="+r.EdgeIP+",c=g,"), crumbs)
+		if r.CacheStatus == "0" {
+			assert.Contains(t, crumbs, ",c=o,")
+		}
+	}
+}
+
+func newGenerator(t *testing.T) *Generator {
+	testTime, err := time.Parse(time.RFC3339Nano, "1970-01-02T03:04:05.123456Z")
+	assert.Nil(t, err)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	d := g.(*Generator)
+	d.staticTime = &testTime
+	return d
+}
@@ -0,0 +1,390 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+)
+
+// Types of the anomalies of the "anomalies" option.
+const (
+	AnomalyBruteForce       = "brute_force"
+	AnomalyExfiltration     = "exfiltration"
+	AnomalyImpossibleTravel = "impossible_travel"
+)
+
+var anomalyTypes = []string{AnomalyBruteForce, AnomalyExfiltration, AnomalyImpossibleTravel}
+
+type anomaliesConfig struct {
+	Anomalies []*ucfg.Config `config:"anomalies"`
+}
+
+type anomalyTypeConfig struct {
+	Type string `config:"type" validate:"required"`
+}
+
+// AnomalyConfig is the configuration of an anomaly of the "anomalies"
+// option.  Generators give the default fields of the anomalies they
+// support to NewAnomalies, so a type and a rate is all most configs
+// need.
+type AnomalyConfig struct {
+	Type   string                 `config:"type" validate:"required"`
+	Label  string                 `config:"label"`
+	Rate   float64                `config:"rate"`
+	Start  string                 `config:"start"`
+	End    string                 `config:"end"`
+	Events int                    `config:"events"`
+	Match  map[string]interface{} `config:"match"`
+	Hold   []string               `config:"hold"`
+	Set    map[string]interface{} `config:"set"`
+	Scale  []string               `config:"scale"`
+	Factor float64                `config:"factor"`
+}
+
+// defaultAnomalyConfig returns the config of an anomaly of type t
+// without fields: one an hour, of 20 events for a brute force and of
+// one event otherwise, and 1000 times the bytes of an exfiltration.
+func defaultAnomalyConfig(t string) AnomalyConfig {
+	c := AnomalyConfig{Type: t, Rate: 1, Events: 1, Factor: 1}
+	switch t {
+	case AnomalyBruteForce:
+		c.Events = 20
+	case AnomalyExfiltration:
+		c.Factor = 1000
+	}
+	return c
+}
+
+func (c *AnomalyConfig) Validate() error {
+	valid := false
+	for _, t := range anomalyTypes {
+		valid = valid || c.Type == t
+	}
+	if !valid {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, strings.Join(anomalyTypes, ", "))
+	}
+	if c.Rate <= 0 {
+		return fmt.Errorf("'%v' is not a valid value for 'rate' expected a value greater than 0", c.Rate)
+	}
+	for option, v := range map[string]string{"start": c.Start, "end": c.End} {
+		if _, err := time.Parse(time.RFC3339Nano, v); v != "" && err != nil {
+			return fmt.Errorf("'%s' is not a valid value for '%s' expected an RFC 3339 time", v, option)
+		}
+	}
+	if c.Events < 1 {
+		return fmt.Errorf("'%d' is not a valid value for 'events' expected a value greater than 0", c.Events)
+	}
+	if c.Factor <= 0 {
+		return fmt.Errorf("'%v' is not a valid value for 'factor' expected a value greater than 0", c.Factor)
+	}
+	return nil
+}
+
+// Anomalies are the anomalies a generator injects in its events with
+// the "anomalies" option, for detection rules to find in otherwise
+// normal traffic.  An anomaly is an episode of one or more events, at
+// "rate" episodes per hour of the event timestamps, between the
+// optional RFC 3339 "start" and "end":
+//
+//	generator:
+//	  type: okta:systemlog
+//	  anomalies:
+//	    - type: brute_force
+//	      rate: 2
+//	      events: 50
+//	    - type: impossible_travel
+//	      start: "2024-03-01T09:00:00Z"
+//	      end: "2024-03-01T17:00:00Z"
+//
+// The types are brute_force, a burst of failed logins, exfiltration,
+// a spike of the bytes sent, and impossible_travel, a user who logs
+// in from far away right after logging in from home.  The anomalies
+// act on the exported fields of the generator, named as for Pins, and
+// the generator has defaults for the types it supports.  The events
+// of an episode are the events that "match" the fields and values, by
+// default every event.  Of these, the "hold" fields keep the values of
+// the last event before the episode, such as the user, the "set"
+// fields are set to a value, or to one of a list of values that is
+// picked for the episode, with the same place in every list, and the
+// "scale" fields are multiplied by "factor":
+//
+//	generator:
+//	  type: fortinet:firewall
+//	  anomalies:
+//	    - type: exfiltration
+//	      hold: [SrcIp, User]
+//	      scale: [SentBytes]
+//	      factor: 5000
+//	      set:
+//	        DstIp: [203.0.113.7, 198.51.100.40]
+//	        DstPort: [443, 8443]
+//
+// The "label" of an anomaly, by default its type, is the label of the
+// events of its episodes, see Label.  Only one episode is in progress
+// at a time.
+type Anomalies struct {
+	rand      *rand.Rand
+	anomalies []*anomaly
+	active    *anomaly
+	label     string
+}
+
+type anomaly struct {
+	label      string
+	rate       float64
+	start, end time.Time
+	events     int
+	factor     float64
+	match      []pin
+	hold       []pin
+	set        []pin
+	scale      []pin
+
+	next time.Time
+	left int
+	pick int
+	held []reflect.Value
+}
+
+// NewAnomalies returns the Anomalies of the "anomalies" option in the
+// ucfg.Config for the generator v, a pointer to a struct.  defaults
+// are the fields of the anomalies the generator supports by type, the
+// options of an anomaly override them.  An error is returned for names
+// that are not exported fields of v and for values that can not be
+// converted to the type of their field.
+func NewAnomalies(cfg *ucfg.Config, r *rand.Rand, v interface{}, defaults map[string]AnomalyConfig) (*Anomalies, error) {
+	c := anomaliesConfig{}
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	a := &Anomalies{rand: r}
+	t := reflect.TypeOf(v).Elem()
+	for _, sub := range c.Anomalies {
+		tc := anomalyTypeConfig{}
+		if err := sub.Unpack(&tc); err != nil {
+			return nil, err
+		}
+		ac := defaultAnomalyConfig(tc.Type)
+		if err := sub.Unpack(&ac); err != nil {
+			return nil, err
+		}
+		// The fields of the options replace the defaults, instead of
+		// being merged with the maps of the defaults.
+		d := defaults[tc.Type]
+		if !sub.HasField("match") {
+			ac.Match = d.Match
+		}
+		if !sub.HasField("hold") {
+			ac.Hold = d.Hold
+		}
+		if !sub.HasField("set") {
+			ac.Set = d.Set
+		}
+		if !sub.HasField("scale") {
+			ac.Scale = d.Scale
+		}
+		an, err := newAnomaly(t, ac)
+		if err != nil {
+			return nil, err
+		}
+		a.anomalies = append(a.anomalies, an)
+	}
+	return a, nil
+}
+
+func newAnomaly(t reflect.Type, c AnomalyConfig) (*anomaly, error) {
+	if len(c.Hold)+len(c.Set)+len(c.Scale) == 0 {
+		return nil, fmt.Errorf("'%s' anomaly expected fields to 'hold', 'set' or 'scale'", c.Type)
+	}
+	an := &anomaly{label: c.Label, rate: c.Rate, events: c.Events, factor: c.Factor}
+	if an.label == "" {
+		an.label = c.Type
+	}
+	// Validate checked the times.
+	an.start, _ = time.Parse(time.RFC3339Nano, c.Start)
+	an.end, _ = time.Parse(time.RFC3339Nano, c.End)
+
+	var err error
+	if an.match, err = anomalyValues(t, "match", c.Match); err != nil {
+		return nil, err
+	}
+	if an.set, err = anomalyValues(t, "set", c.Set); err != nil {
+		return nil, err
+	}
+	for _, name := range c.Hold {
+		index, _, err := field(t, "hold", name)
+		if err != nil {
+			return nil, err
+		}
+		an.hold = append(an.hold, pin{name: name, index: index})
+	}
+	for _, name := range c.Scale {
+		index, ft, err := field(t, "scale", name)
+		if err != nil {
+			return nil, err
+		}
+		switch ft.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+		default:
+			return nil, fmt.Errorf("'%s' is not a valid value for 'scale' expected a field of a number", name)
+		}
+		an.scale = append(an.scale, pin{name: name, index: index})
+	}
+	return an, nil
+}
+
+// anomalyValues returns the fields of t with their values of the
+// option m, as for the "pin" option.
+func anomalyValues(t reflect.Type, option string, m map[string]interface{}) ([]pin, error) {
+	fields := make(map[string]interface{})
+	flatten("", m, fields)
+	var pins []pin
+	for _, name := range sorted(fields) {
+		index, ft, err := field(t, option, name)
+		if err != nil {
+			return nil, err
+		}
+		values, ok := fields[name].([]interface{})
+		if !ok {
+			values = []interface{}{fields[name]}
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("'%s.%s' has no values", option, name)
+		}
+		pn := pin{name: name, index: index}
+		for _, value := range values {
+			rv, err := convert(value, ft)
+			if err != nil {
+				return nil, fmt.Errorf("'%v' is not a valid value for '%s.%s' expected %s", value, option, name, ft)
+			}
+			pn.values = append(pn.values, rv)
+		}
+		pins = append(pins, pn)
+	}
+	return pins, nil
+}
+
+// Apply injects the anomalies in v, the pointer to the struct the
+// Anomalies were created for, as the event at now.  Generators call
+// it once for every event, after the pins.
+func (a *Anomalies) Apply(v interface{}, now time.Time) {
+	if a == nil || len(a.anomalies) == 0 {
+		return
+	}
+	a.label = ""
+	s := reflect.ValueOf(v).Elem()
+	if a.active == nil {
+		for _, an := range a.anomalies {
+			if an.due(a.rand, now) {
+				an.left, an.pick = an.events, a.rand.Int()
+				a.active = an
+				break
+			}
+		}
+	}
+	an := a.active
+	if an == nil || !an.matches(s) {
+		// The hold fields are of the last normal event before an
+		// episode.
+		for _, other := range a.anomalies {
+			if other != an && other.matches(s) {
+				other.keep(s)
+			}
+		}
+		return
+	}
+	an.inject(s)
+	a.label = an.label
+	if an.left--; an.left == 0 {
+		a.active = nil
+	}
+}
+
+// Label returns the label of the anomaly of the last event of Apply,
+// or "" for a normal event.
+func (a *Anomalies) Label() string {
+	if a == nil {
+		return ""
+	}
+	return a.label
+}
+
+// due reports whether an episode of an starts at now, in the window
+// of the anomaly.  The episodes are a Poisson process of the rate.
+func (an *anomaly) due(r *rand.Rand, now time.Time) bool {
+	if an.next.IsZero() {
+		an.next = now.Add(an.wait(r))
+	}
+	if now.Before(an.next) {
+		return false
+	}
+	an.next = now.Add(an.wait(r))
+	return (an.start.IsZero() || !now.Before(an.start)) && (an.end.IsZero() || !now.After(an.end))
+}
+
+func (an *anomaly) wait(r *rand.Rand) time.Duration {
+	return time.Duration(r.ExpFloat64() / an.rate * float64(time.Hour))
+}
+
+// matches reports whether s has the values of the match fields.
+func (an *anomaly) matches(s reflect.Value) bool {
+	for _, m := range an.match {
+		f, err := s.FieldByIndexErr(m.index)
+		if err != nil {
+			return false
+		}
+		found := false
+		for _, v := range m.values {
+			found = found || reflect.DeepEqual(f.Interface(), v.Interface())
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// keep keeps the hold fields of s for the next episode.
+func (an *anomaly) keep(s reflect.Value) {
+	an.held = an.held[:0]
+	for _, h := range an.hold {
+		f, err := s.FieldByIndexErr(h.index)
+		if err != nil {
+			an.held = nil
+			return
+		}
+		an.held = append(an.held, reflect.ValueOf(f.Interface()))
+	}
+}
+
+// inject sets the fields of s for the anomaly.
+func (an *anomaly) inject(s reflect.Value) {
+	if len(an.held) == len(an.hold) {
+		for i, h := range an.hold {
+			set(s, h.index, an.held[i])
+		}
+	}
+	for _, pn := range an.set {
+		set(s, pn.index, pn.values[an.pick%len(pn.values)])
+	}
+	for _, sc := range an.scale {
+		f, err := s.FieldByIndexErr(sc.index)
+		if err != nil {
+			continue
+		}
+		switch f.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f.SetInt(int64(float64(f.Int()) * an.factor))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f.SetUint(uint64(float64(f.Uint()) * an.factor))
+		default:
+			f.SetFloat(f.Float() * an.factor)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+type anomalyGenerator struct {
+	Kind    string
+	User    string
+	SrcIP   string
+	Country string
+	Bytes   int
+	Result  string
+	Reason  *string
+}
+
+func anomalies(t *testing.T, c map[string]interface{}, defaults map[string]AnomalyConfig) (*Anomalies, error) {
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": "test", "anomalies": []interface{}{c}})
+	assert.Nil(t, err)
+	return NewAnomalies(cfg, rand.New(rand.NewChaCha8([32]byte{1})), &anomalyGenerator{}, defaults)
+}
+
+func TestAnomalies(t *testing.T) {
+	a, err := anomalies(t, map[string]interface{}{
+		"type":   AnomalyBruteForce,
+		"label":  "password-spray",
+		"rate":   60,
+		"events": 5,
+		"match":  map[string]interface{}{"Kind": "login"},
+		"hold":   []interface{}{"User"},
+		"set": map[string]interface{}{
+			"SrcIP":   []interface{}{"203.0.113.7", "198.51.100.40"},
+			"Country": []interface{}{"Russia", "China"},
+			"Result":  "FAILURE",
+			"Reason":  "INVALID_CREDENTIALS",
+		},
+	}, nil)
+	assert.Nil(t, err)
+
+	r := rand.New(rand.NewChaCha8([32]byte{2}))
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	var user string
+	episodes, events := 0, 0
+	for i := 0; i < 10000; i++ {
+		g := &anomalyGenerator{Kind: "login", User: []string{"alice", "bob", "carol"}[r.IntN(3)], SrcIP: "10.0.0.1", Country: "Netherlands", Result: "SUCCESS"}
+		if r.IntN(2) == 0 {
+			g.Kind = "sso"
+		}
+		a.Apply(g, now)
+		now = now.Add(time.Second)
+		if a.Label() == "" {
+			if g.Kind == "login" {
+				user = g.User
+			}
+			continue
+		}
+		assert.Equal(t, "password-spray", a.Label())
+		assert.Equal(t, "login", g.Kind)
+		assert.Equal(t, user, g.User)
+		assert.Equal(t, "FAILURE", g.Result)
+		assert.Equal(t, "INVALID_CREDENTIALS", *g.Reason)
+		switch g.SrcIP {
+		case "203.0.113.7":
+			assert.Equal(t, "Russia", g.Country)
+		case "198.51.100.40":
+			assert.Equal(t, "China", g.Country)
+		default:
+			t.Errorf("unexpected SrcIP %s", g.SrcIP)
+		}
+		if events%5 == 0 {
+			episodes++
+		}
+		events++
+	}
+	// About 60 episodes an hour, in the 2 hours and 47 minutes.
+	assert.Equal(t, 0, events%5)
+	assert.InDelta(t, 167, episodes, 40)
+}
+
+func TestAnomaliesWindow(t *testing.T) {
+	a, err := anomalies(t, map[string]interface{}{
+		"type":  AnomalyExfiltration,
+		"rate":  120,
+		"start": "2024-03-01T10:00:00Z",
+		"end":   "2024-03-01T11:00:00Z",
+	}, map[string]AnomalyConfig{
+		AnomalyExfiltration: {Hold: []string{"SrcIP"}, Scale: []string{"Bytes"}},
+	})
+	assert.Nil(t, err)
+
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 3*3600; i++ {
+		g := &anomalyGenerator{SrcIP: "10.0.0.1", Bytes: 2000}
+		a.Apply(g, now)
+		if a.Label() != "" {
+			assert.Equal(t, AnomalyExfiltration, a.Label())
+			assert.Equal(t, 2000000, g.Bytes)
+			assert.False(t, now.Before(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)), now)
+			assert.False(t, now.After(time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)), now)
+		}
+		now = now.Add(time.Second)
+	}
+}
+
+func TestAnomaliesErrors(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		errorString string
+	}{
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "ddos", "set": map[string]interface{}{"Result": "FAILURE"}},
+			errorString: "'ddos' is not a valid value for 'type' expected 'brute_force, exfiltration, impossible_travel' accessing 'anomalies.0'",
+		},
+		"Invalid Rate": {
+			c:           map[string]interface{}{"type": AnomalyBruteForce, "rate": 0, "hold": []interface{}{"User"}},
+			errorString: "'0' is not a valid value for 'rate' expected a value greater than 0 accessing 'anomalies.0'",
+		},
+		"Invalid Start": {
+			c:           map[string]interface{}{"type": AnomalyBruteForce, "start": "monday", "hold": []interface{}{"User"}},
+			errorString: "'monday' is not a valid value for 'start' expected an RFC 3339 time accessing 'anomalies.0'",
+		},
+		"No Fields": {
+			c:           map[string]interface{}{"type": AnomalyImpossibleTravel},
+			errorString: "'impossible_travel' anomaly expected fields to 'hold', 'set' or 'scale'",
+		},
+		"Unknown Field": {
+			c:           map[string]interface{}{"type": AnomalyBruteForce, "hold": []interface{}{"Nope"}},
+			errorString: "'Nope' is not a valid value for 'hold' expected a field of generator.anomalyGenerator",
+		},
+		"Scale Not A Number": {
+			c:           map[string]interface{}{"type": AnomalyExfiltration, "scale": []interface{}{"User"}},
+			errorString: "'User' is not a valid value for 'scale' expected a field of a number",
+		},
+		"Invalid Set Value": {
+			c:           map[string]interface{}{"type": AnomalyExfiltration, "set": map[string]interface{}{"Bytes": "lots"}},
+			errorString: "'lots' is not a valid value for 'set.Bytes' expected int",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			_, err := anomalies(t, tc.c, nil)
+			assert.EqualError(t, err, tc.errorString)
+		})
+	}
+}
+
+func TestAnomaliesNone(t *testing.T) {
+	a, err := NewAnomalies(ucfg.MustNewFrom(map[string]interface{}{"type": "test"}), rand.New(rand.NewChaCha8([32]byte{1})), &anomalyGenerator{}, nil)
+	assert.Nil(t, err)
+	g := &anomalyGenerator{Bytes: 10}
+	a.Apply(g, time.Now())
+	assert.Equal(t, 10, g.Bytes)
+	assert.Equal(t, "", a.Label())
+
+	var none *Anomalies
+	none.Apply(g, time.Now())
+	assert.Equal(t, "", none.Label())
+}
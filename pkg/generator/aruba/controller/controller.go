@@ -0,0 +1,313 @@
+// Package controller generates Aruba (HPE) wireless controller log messages.
+//
+// Messages cover station association and deauthentication, user
+// authentication and Adaptive Radio Management (ARM) events.  Each
+// station is tracked across messages, so a station associates,
+// authenticates, roams between access points and eventually leaves,
+// with the same MAC address, BSSID and ESSID used throughout the
+// sequence.
+//
+// Configuration:
+//
+//   - generator:
+//     type: aruba:controller
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "aruba:controller"
+
+const (
+	numStations = 32
+	armOneIn    = 10
+)
+
+var (
+	header            = "<{{.MessageID}}> <{{.Level}}> |AP {{.APName}}@{{.APAddr}} {{.Process}}|  "
+	assocRequest      = header + "Assoc request @ {{.Timestamp.Format \"15:04:05.000000\"}}: {{.StationMAC}} (SN {{.Sequence}}): AP {{.APAddr}}-{{.BSSID}}-{{.APName}}"
+	assocSuccess      = header + "Assoc success @ {{.Timestamp.Format \"15:04:05.000000\"}}: {{.StationMAC}}: AP {{.APAddr}}-{{.BSSID}}-{{.APName}}"
+	authSuccess       = header + "Auth success: {{.StationMAC}}: AP {{.APAddr}}-{{.BSSID}}-{{.APName}}"
+	userAuthSuccess   = header + "User Authentication Successful: username={{.Username}} MAC={{.StationMAC}} IP={{.StationAddr}} role={{.Role}} VLAN={{.VLAN}} AP={{.APName}} SSID={{.ESSID}} AAA profile={{.ESSID}}-aaa auth method={{.AuthMethod}} auth server={{.AuthServer}}"
+	userAuthFailure   = header + "User Authentication failed. username={{.Username}} MAC={{.StationMAC}} IP={{.StationAddr}} auth method={{.AuthMethod}} auth server={{.AuthServer}}"
+	deauthToStation   = header + "Deauth to sta: {{.StationMAC}}: {{.Reason}} AP {{.APAddr}}-{{.BSSID}}-{{.APName}}"
+	deauthFromStation = header + "Deauth from sta: {{.StationMAC}}: AP {{.APAddr}}-{{.BSSID}}-{{.APName}} Reason {{.Reason}}"
+	armChannel        = header + "ARM Channel change: AP-name={{.APName}}, radio={{.Radio}}, channel {{.OldChannel}} -> {{.Channel}}, reason={{.Reason}}"
+	armPower          = header + "ARM Power change: AP-name={{.APName}}, radio={{.Radio}}, power {{.OldPower}} -> {{.Power}} dBm, reason={{.Reason}}"
+
+	messages = map[string]struct {
+		id      int
+		level   string
+		process string
+		text    string
+	}{
+		"assocRequest":      {id: 501095, level: "NOTI", process: "stm", text: assocRequest},
+		"assocSuccess":      {id: 501100, level: "NOTI", process: "stm", text: assocSuccess},
+		"authSuccess":       {id: 501093, level: "NOTI", process: "stm", text: authSuccess},
+		"userAuthSuccess":   {id: 522008, level: "NOTI", process: "authmgr", text: userAuthSuccess},
+		"userAuthFailure":   {id: 522275, level: "WARN", process: "authmgr", text: userAuthFailure},
+		"deauthToStation":   {id: 501080, level: "NOTI", process: "stm", text: deauthToStation},
+		"deauthFromStation": {id: 501106, level: "NOTI", process: "stm", text: deauthFromStation},
+		"armChannel":        {id: 404003, level: "WARN", process: "sapd", text: armChannel},
+		"armPower":          {id: 404004, level: "WARN", process: "sapd", text: armPower},
+	}
+
+	aps = [...]string{
+		"AP-Lobby",
+		"AP-Floor1-East",
+		"AP-Floor1-West",
+		"AP-Floor2-East",
+		"AP-Floor2-West",
+		"AP-Warehouse",
+		"AP-Cafeteria",
+		"AP-Boardroom",
+	}
+	essids = [...]struct {
+		name       string
+		role       string
+		vlan       int
+		authMethod string
+	}{
+		{name: "Corp", role: "employee", vlan: 20, authMethod: "802.1x"},
+		{name: "Corp-Voice", role: "voice", vlan: 30, authMethod: "802.1x"},
+		{name: "Guest", role: "guest", vlan: 100, authMethod: "Web"},
+		{name: "IoT", role: "iot", vlan: 40, authMethod: "MAC"},
+	}
+	authServers     = [...]string{"ClearPass", "ClearPass-2", "Internal"}
+	usernames       = [...]string{"jdoe", "asmith", "bwilson", "cjohnson", "dlee", "emartin", "fgarcia", "guest-4821"}
+	deauthToReasons = [...]string{"Ageout", "Sapcp Ageout"}
+	deauthReasons   = [...]string{"Unspecified Failure", "Disassociated because sending STA is leaving BSS", "Inactivity"}
+	armReasons      = [...]string{"Interference", "Noise", "Radar detected", "Coverage hole", "Error rate"}
+	channels        = [...][]int{{1, 6, 11}, {36, 40, 44, 48, 149, 153, 157, 161}}
+)
+
+// ap is an access point with one BSSID per ESSID.
+type ap struct {
+	name   string
+	addr   net.IP
+	bssids []string
+}
+
+// station is a wireless client, it is associated when ap is not nil.
+type station struct {
+	mac      string
+	addr     net.IP
+	username string
+	essid    int
+	ap       *ap
+}
+
+// Controller holds the random fields for an Aruba controller log message.
+type Controller struct {
+	APAddr      net.IP
+	APName      string
+	AuthMethod  string
+	AuthServer  string
+	BSSID       string
+	Channel     int
+	ESSID       string
+	Level       string
+	MessageID   int
+	OldChannel  int
+	OldPower    int
+	Power       int
+	Process     string
+	Radio       int
+	Reason      string
+	Role        string
+	Sequence    int
+	StationAddr net.IP
+	StationMAC  string
+	Timestamp   time.Time
+	Username    string
+	VLAN        int
+
+	msg       string
+	rand      *rand.Rand
+	clock     *generator.Clock
+	aps       []*ap
+	stations  []*station
+	pending   []*Controller
+	templates map[string]*template.Template
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Controller objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Controller{
+		rand:      r,
+		clock:     clock,
+		templates: make(map[string]*template.Template),
+	}
+	for k, v := range messages {
+		t, err := template.New(k).Funcs(generator.FunctionMap).Parse(v.text)
+		if err != nil {
+			return nil, err
+		}
+		a.templates[k] = t
+	}
+
+	for i, name := range aps {
+		base := randomMAC(r)
+		p := &ap{name: name, addr: net.IPv4(10, 1, 1, byte(20+i))}
+		for j := range essids {
+			p.bssids = append(p.bssids, fmt.Sprintf("%s%x", base[:len(base)-1], j))
+		}
+		a.aps = append(a.aps, p)
+	}
+	for i := 0; i < numStations; i++ {
+		a.stations = append(a.stations, &station{
+			mac:      randomMAC(r),
+			username: usernames[r.IntN(len(usernames))],
+			essid:    r.IntN(len(essids)),
+		})
+	}
+
+	return a, nil
+}
+
+// Next produces the next Aruba controller log message.
+//
+// Example:
+//
+// <501093> <NOTI> |AP AP-Lobby@10.1.1.20 stm|  Auth success: 6c:f3:7f:12:34:56: AP 10.1.1.20-6c:f3:7f:aa:bb:c0-AP-Lobby
+func (a *Controller) Next() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if len(a.pending) == 0 {
+		if a.rand.IntN(armOneIn) == 0 {
+			a.arm()
+		} else {
+			a.sequence()
+		}
+	}
+
+	e := a.pending[0]
+	a.pending = a.pending[1:]
+
+	err := a.templates[e.msg].Execute(&buf, e)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), err
+}
+
+// sequence queues the messages for the next step in the life of a
+// randomly chosen station.  A station that is not associated joins an
+// access point and authenticates, an associated station either roams
+// to another access point or leaves.
+func (a *Controller) sequence() {
+	s := a.stations[a.rand.IntN(len(a.stations))]
+
+	if s.ap == nil {
+		s.ap = a.aps[a.rand.IntN(len(a.aps))]
+		s.addr = net.IPv4(10, byte(essids[s.essid].vlan), byte(a.rand.IntN(256)), byte(a.rand.IntN(254)+1))
+		a.queue("assocRequest", s, s.ap, "")
+		a.queue("assocSuccess", s, s.ap, "")
+		a.queue("authSuccess", s, s.ap, "")
+		if a.rand.IntN(10) == 0 {
+			a.queue("userAuthFailure", s, s.ap, "")
+			a.queue("deauthToStation", s, s.ap, "Denied: Auth Failure")
+			s.ap = nil
+			return
+		}
+		a.queue("userAuthSuccess", s, s.ap, "")
+		return
+	}
+
+	if a.rand.IntN(3) == 0 {
+		if a.rand.IntN(2) == 0 {
+			a.queue("deauthFromStation", s, s.ap, deauthReasons[a.rand.IntN(len(deauthReasons))])
+		} else {
+			a.queue("deauthToStation", s, s.ap, deauthToReasons[a.rand.IntN(len(deauthToReasons))])
+		}
+		s.ap = nil
+		return
+	}
+
+	old := s.ap
+	for s.ap == old {
+		s.ap = a.aps[a.rand.IntN(len(a.aps))]
+	}
+	a.queue("assocRequest", s, s.ap, "")
+	a.queue("assocSuccess", s, s.ap, "")
+	a.queue("authSuccess", s, s.ap, "")
+	a.queue("deauthToStation", s, old, "Moved out from")
+}
+
+// queue adds a message about station s on access point p to the
+// pending messages.
+func (a *Controller) queue(msg string, s *station, p *ap, reason string) {
+	e := a.newEvent(msg, p)
+	e.BSSID = p.bssids[s.essid]
+	e.ESSID = essids[s.essid].name
+	e.Role = essids[s.essid].role
+	e.VLAN = essids[s.essid].vlan
+	e.AuthMethod = essids[s.essid].authMethod
+	e.AuthServer = authServers[a.rand.IntN(len(authServers))]
+	e.StationMAC = s.mac
+	e.StationAddr = s.addr
+	e.Username = s.username
+	e.Reason = reason
+	e.Sequence = a.rand.IntN(4096)
+	a.pending = append(a.pending, e)
+}
+
+// arm queues an Adaptive Radio Management event for a random access point.
+func (a *Controller) arm() {
+	radio := a.rand.IntN(2)
+	msg := [...]string{"armChannel", "armPower"}[a.rand.IntN(2)]
+	e := a.newEvent(msg, a.aps[a.rand.IntN(len(a.aps))])
+	e.Radio = radio
+	e.OldChannel = channels[radio][a.rand.IntN(len(channels[radio]))]
+	e.Channel = channels[radio][a.rand.IntN(len(channels[radio]))]
+	e.OldPower = a.rand.IntN(16) + 3
+	e.Power = a.rand.IntN(16) + 3
+	e.Reason = armReasons[a.rand.IntN(len(armReasons))]
+	a.pending = append(a.pending, e)
+}
+
+func (a *Controller) newEvent(msg string, p *ap) *Controller {
+	m := messages[msg]
+	return &Controller{
+		APAddr:    p.addr,
+		APName:    p.name,
+		Level:     m.level,
+		MessageID: m.id,
+		Process:   m.process,
+		Timestamp: a.clock.Now(),
+		msg:       msg,
+	}
+}
+
+func randomMAC(r *rand.Rand) string {
+	return fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x", r.IntN(256), r.IntN(256), r.IntN(256), r.IntN(256), r.IntN(256), r.IntN(256))
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		expected string
+	}{
+		"assocRequest":      {expected: `<501095> <NOTI> |AP AP-Lobby@10.1.1.20 stm|  Assoc request @ 03:04:05.123456: 17:aa:c1:01:03:94 (SN 3229): AP 10.1.1.20-0c:8f:3c:9b:2a:a1-AP-Lobby`},
+		"assocSuccess":      {expected: `<501100> <NOTI> |AP AP-Lobby@10.1.1.20 stm|  Assoc success @ 03:04:05.123456: 17:aa:c1:01:03:94: AP 10.1.1.20-0c:8f:3c:9b:2a:a1-AP-Lobby`},
+		"authSuccess":       {expected: `<501093> <NOTI> |AP AP-Lobby@10.1.1.20 stm|  Auth success: 17:aa:c1:01:03:94: AP 10.1.1.20-0c:8f:3c:9b:2a:a1-AP-Lobby`},
+		"userAuthSuccess":   {expected: `<522008> <NOTI> |AP AP-Lobby@10.1.1.20 authmgr|  User Authentication Successful: username=bwilson MAC=17:aa:c1:01:03:94 IP=10.30.1.2 role=voice VLAN=30 AP=AP-Lobby SSID=Corp-Voice AAA profile=Corp-Voice-aaa auth method=802.1x auth server=Internal`},
+		"userAuthFailure":   {expected: `<522275> <WARN> |AP AP-Lobby@10.1.1.20 authmgr|  User Authentication failed. username=bwilson MAC=17:aa:c1:01:03:94 IP=10.30.1.2 auth method=802.1x auth server=Internal`},
+		"deauthToStation":   {expected: `<501080> <NOTI> |AP AP-Lobby@10.1.1.20 stm|  Deauth to sta: 17:aa:c1:01:03:94: Ageout AP 10.1.1.20-0c:8f:3c:9b:2a:a1-AP-Lobby`},
+		"deauthFromStation": {expected: `<501106> <NOTI> |AP AP-Lobby@10.1.1.20 stm|  Deauth from sta: 17:aa:c1:01:03:94: AP 10.1.1.20-0c:8f:3c:9b:2a:a1-AP-Lobby Reason Ageout`},
+		"armChannel":        {expected: `<404003> <WARN> |AP AP-Floor2-East@10.1.1.23 sapd|  ARM Channel change: AP-name=AP-Floor2-East, radio=1, channel 161 -> 44, reason=Coverage hole`},
+		"armPower":          {expected: `<404004> <WARN> |AP AP-Floor2-East@10.1.1.23 sapd|  ARM Power change: AP-name=AP-Floor2-East, radio=1, power 17 -> 7 dBm, reason=Coverage hole`},
+	}
+	testTime, err := time.Parse(time.RFC3339Nano, "1970-01-02T03:04:05.123456Z")
+	assert.Nil(t, err)
+	for name, tc := range tests {
+		random.Seed(1)
+		a := newController(t)
+		s := a.stations[0]
+		s.ap = a.aps[0]
+		s.addr = net.IPv4(10, byte(essids[s.essid].vlan), 1, 2)
+		if name == "armChannel" || name == "armPower" {
+			a.arm()
+			a.pending[0].msg = name
+		} else {
+			a.queue(name, s, s.ap, "Ageout")
+		}
+		a.pending[0].Level = messages[name].level
+		a.pending[0].MessageID = messages[name].id
+		a.pending[0].Process = messages[name].process
+		a.pending[0].Timestamp = testTime
+		got, err := a.Next()
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, string(got), name)
+	}
+}
+
+func TestRoaming(t *testing.T) {
+	random.Seed(1)
+	a := newController(t)
+
+	re := regexp.MustCompile(`\|  (Assoc success|Deauth to sta|Deauth from sta).*: ([0-9a-f:]{17}): (.*)AP [0-9.]+-([0-9a-f:]{17})-(\S+)`)
+	associated := make(map[string]string)
+	roams := 0
+	for i := 0; i < 5000; i++ {
+		got, err := a.Next()
+		assert.Nil(t, err)
+		m := re.FindStringSubmatch(string(got))
+		if m == nil {
+			continue
+		}
+		mac, reason, bssid := m[2], m[3], m[4]
+		switch m[1] {
+		case "Assoc success":
+			associated[mac] = bssid
+		default:
+			assert.NotEmpty(t, associated[mac], "%s deauthenticated without association: %s", mac, got)
+			if reason == "Moved out from " {
+				roams++
+				assert.NotEqual(t, associated[mac], bssid, "%s roamed to the same BSSID", mac)
+				continue
+			}
+			delete(associated, mac)
+		}
+	}
+	assert.NotZero(t, roams)
+}
+
+func newController(t *testing.T) *Controller {
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	return g.(*Controller)
+}
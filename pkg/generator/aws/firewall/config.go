@@ -3,16 +3,20 @@ package firewall
 import (
 	"fmt"
 	"strings"
+
+	"github.com/leehinman/spigot/pkg/random"
 )
 
 type config struct {
-	Type      string `config:"type" validate:"required"`
-	EventType string `config:"event_type"`
+	Type       string                  `config:"type" validate:"required"`
+	EventType  string                  `config:"event_type"`
+	TopTalkers random.TopTalkersConfig `config:"top_talkers"`
 }
 
 func defaultConfig() config {
 	return config{
-		Type: Name,
+		Type:       Name,
+		TopTalkers: random.DefaultTopTalkersConfig(),
 	}
 }
 
@@ -28,6 +28,16 @@ func TestConfigs(t *testing.T) {
 			hasError:    false,
 			errorString: "",
 		},
+		"Valid Type with Top Talkers": {
+			c:           map[string]interface{}{"type": Name, "event_type": "netflow", "top_talkers": map[string]interface{}{"pairs": 3}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Top Talkers Share": {
+			c:           map[string]interface{}{"type": Name, "top_talkers": map[string]interface{}{"pairs": 3, "share": 1}},
+			hasError:    true,
+			errorString: "'1' is not a valid value for 'share' expected a value from 0.5 to below 1 accessing 'top_talkers'",
+		},
 		"Invalid Type": {
 			c:           map[string]interface{}{"type": "Bob"},
 			hasError:    true,
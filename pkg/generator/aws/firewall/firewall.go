@@ -6,15 +6,21 @@
 //		 event_type: Specify the type of event to generate, or leave blank for random.
 //		             Valid values are: alert, netflow.
 //
+//		 top_talkers: Optional, for netflow events.  When pairs is set that many
+//		              host pairs account for share (default 0.8) of the bytes
+//		              of every window (default 1m).
+//
 //	  - generator:
 //	      type: aws:firewall
 //		     event_type: netflow
+//		     top_talkers:
+//		       pairs: 5
 package firewall
 
 import (
 	"encoding/json"
 	"fmt"
-	"math/rand"
+	"math/rand/v2"
 	"net"
 
 	"strconv"
@@ -123,6 +129,10 @@ type Generator struct {
 	Data Firewall
 
 	eventType string
+	rand      *rand.Rand
+	clock     *generator.Clock
+	pins      *generator.Pins
+	talkers   *random.TopTalkers
 }
 
 func init() {
@@ -136,8 +146,25 @@ func New(cfg *ucfg.Config) (generator.Generator, error) {
 		return nil, err
 	}
 
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	g := Generator{
 		eventType: c.EventType,
+		rand:      r,
+		clock:     clock,
+		talkers:   random.NewTopTalkers(r, c.TopTalkers),
+	}
+
+	g.pins, err = generator.NewPins(cfg, r, &g)
+	if err != nil {
+		return nil, err
 	}
 
 	return &g, nil
@@ -158,25 +185,25 @@ func (g *Generator) Next() ([]byte, error) {
 }
 
 func (g *Generator) randomize() {
-	now := time.Now()
+	now := g.clock.Now()
 	g.Data = Firewall{
-		FirewallName:     fmt.Sprintf("Firewall-%d", rand.Intn(100)),
-		AvailabilityZone: random.AWSAvailabilityZone(),
+		FirewallName:     fmt.Sprintf("Firewall-%d", g.rand.IntN(100)),
+		AvailabilityZone: random.AWSAvailabilityZone(g.rand),
 		EventTimestamp:   strconv.Itoa(int(now.Unix())),
-		//EventTimestamp: random.Randomtime(),
+		//EventTimestamp: random.Randomtime(g.rand),
 		Event: EventData{
 			Timestamp: now.Format(timestampFmt),
-			FlowID:    rand.Int(),
-			SrcIP:     random.IPv4(),
-			SrcPort:   random.Port(),
-			DstIP:     random.IPv4(),
-			DstPort:   random.Port(),
-			Proto:     protocols[rand.Intn(len(protocols))],
+			FlowID:    g.rand.Int(),
+			SrcIP:     random.IPv4(g.rand),
+			SrcPort:   random.Port(g.rand),
+			DstIP:     random.IPv4(g.rand),
+			DstPort:   random.Port(g.rand),
+			Proto:     protocols[g.rand.IntN(len(protocols))],
 		},
 	}
 
 	if g.eventType == "" {
-		g.Data.Event.EventType = eventTypes[rand.Intn(len(eventTypes))]
+		g.Data.Event.EventType = eventTypes[g.rand.IntN(len(eventTypes))]
 	} else {
 		g.Data.Event.EventType = g.eventType
 	}
@@ -187,17 +214,19 @@ func (g *Generator) randomize() {
 	case EventTypeNetflow:
 		g.randomizeNetflow(now)
 	}
+
+	g.pins.Apply(g)
 }
 
 func (g *Generator) randomizeAlert() {
-	signature := rand.Intn(1024)
+	signature := g.rand.IntN(1024)
 	g.Data.Event.Alert = &AlertData{
-		Action:      alertActions[rand.Intn(len(alertActions))],
+		Action:      alertActions[g.rand.IntN(len(alertActions))],
 		SignatureID: signature,
-		Rev:         rand.Intn(1024),
+		Rev:         g.rand.IntN(1024),
 		Signature:   fmt.Sprintf("Signature-%d", signature),
-		Category:    fmt.Sprintf("Category-%d", rand.Intn(100)),
-		Severity:    rand.Intn(6),
+		Category:    fmt.Sprintf("Category-%d", g.rand.IntN(100)),
+		Severity:    g.rand.IntN(6),
 	}
 
 	if g.Data.Event.Proto == ProtocolTCP {
@@ -206,23 +235,30 @@ func (g *Generator) randomizeAlert() {
 }
 
 func (g *Generator) randomizeNetflow(now time.Time) {
-	ttl := rand.Intn(256)
-	start := now.Add(-time.Duration(rand.Intn(60)) * time.Minute)
+	ttl := g.rand.IntN(256)
+	start := now.Add(-time.Duration(g.rand.IntN(60)) * time.Minute)
 	g.Data.Event.Netflow = &NetflowData{
-		Pkts:   rand.Intn(100),
+		Pkts:   g.rand.IntN(100),
 		Start:  start.Format(timestampFmt),
 		End:    now.Format(timestampFmt),
 		Age:    int(now.Sub(start).Seconds()),
 		MinTTL: ttl,
 		MaxTTL: ttl,
 	}
-	g.Data.Event.Netflow.Bytes = g.Data.Event.Netflow.Pkts*rand.Intn(1024) + 1
+	g.Data.Event.Netflow.Bytes = g.Data.Event.Netflow.Pkts*g.rand.IntN(1024) + 1
+
+	if g.talkers != nil {
+		var scale int
+		g.Data.Event.SrcIP, g.Data.Event.DstIP, scale = g.talkers.Pair(now, g.Data.Event.Netflow.Bytes)
+		g.Data.Event.Netflow.Pkts *= scale
+		g.Data.Event.Netflow.Bytes *= scale
+	}
 }
 
 func (g *Generator) randomizeTCP() {
-	g.Data.Event.AppProto = tcpAppProtos[rand.Intn(len(tcpAppProtos))]
+	g.Data.Event.AppProto = tcpAppProtos[g.rand.IntN(len(tcpAppProtos))]
 
-	flags := rand.Intn(64)
+	flags := g.rand.IntN(64)
 	g.Data.Event.TCP = &TCPData{
 		TCPFlags: fmt.Sprintf("%02d", flags),
 		Fin:      flags&(1<<0) != 0,
@@ -240,11 +276,11 @@ func (g *Generator) randomizeTCP() {
 
 func (g *Generator) randomizeHTTP() {
 	g.Data.Event.HTTP = &HTTPData{
-		Hostname:      fmt.Sprintf("HTTPHost-%d", rand.Intn(100)),
-		URL:           fmt.Sprintf("/random-%d.html", rand.Intn(100)),
-		HTTPUserAgent: random.UserAgent(),
-		HTTPMethod:    random.HTTPMethod(),
-		Protocol:      random.HTTPVersion(),
-		Length:        rand.Intn(1024),
+		Hostname:      fmt.Sprintf("HTTPHost-%d", g.rand.IntN(100)),
+		URL:           fmt.Sprintf("/random-%d.html", g.rand.IntN(100)),
+		HTTPUserAgent: random.UserAgent(g.rand),
+		HTTPMethod:    random.HTTPMethod(g.rand),
+		Protocol:      random.HTTPVersion(g.rand),
+		Length:        g.rand.IntN(1024),
 	}
 }
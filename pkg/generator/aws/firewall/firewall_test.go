@@ -2,11 +2,12 @@ package firewall
 
 import (
 	"encoding/json"
-	"math/rand"
 	"net"
+	"sort"
 	"testing"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,22 +22,22 @@ func TestNext(t *testing.T) {
 				"event_type": "netflow",
 			},
 			want: Firewall{
-				FirewallName:     "Firewall-81",
-				AvailabilityZone: "eu-west-1c",
+				FirewallName:     "Firewall-87",
+				AvailabilityZone: "ap-southeast-1c",
 				Event: EventData{
-					FlowID:    6129484611666145821,
+					FlowID:    9046953972447816508,
 					EventType: "netflow",
-					SrcIP:     net.ParseIP("118.9.14.112"),
-					SrcPort:   34177,
-					DstIP:     net.ParseIP("12.163.211.175"),
-					DstPort:   52025,
+					SrcIP:     net.ParseIP("91.92.37.225"),
+					SrcPort:   15146,
+					DstIP:     net.ParseIP("244.146.90.1"),
+					DstPort:   13208,
 					Proto:     "UDP",
 					Netflow: &NetflowData{
-						Pkts:   94,
-						Bytes:  64579,
-						Age:    0,
-						MinTTL: 72,
-						MaxTTL: 72,
+						Pkts:   91,
+						Bytes:  27483,
+						Age:    2220,
+						MinTTL: 131,
+						MaxTTL: 131,
 					},
 				},
 			},
@@ -46,22 +47,22 @@ func TestNext(t *testing.T) {
 				"event_type": "alert",
 			},
 			want: Firewall{
-				FirewallName:     "Firewall-81",
-				AvailabilityZone: "eu-west-1c",
+				FirewallName:     "Firewall-87",
+				AvailabilityZone: "ap-southeast-1c",
 				Event: EventData{
-					FlowID:    6129484611666145821,
+					FlowID:    9046953972447816508,
 					EventType: "alert",
-					SrcIP:     net.ParseIP("118.9.14.112"),
-					SrcPort:   34177,
-					DstIP:     net.ParseIP("12.163.211.175"),
-					DstPort:   52025,
+					SrcIP:     net.ParseIP("91.92.37.225"),
+					SrcPort:   15146,
+					DstIP:     net.ParseIP("244.146.90.1"),
+					DstPort:   13208,
 					Proto:     "UDP",
 					Alert: &AlertData{
-						Action:      "allowed",
-						SignatureID: 840,
-						Rev:         198,
-						Signature:   "Signature-840",
-						Category:    "Category-11",
+						Action:      "blocked",
+						SignatureID: 899,
+						Rev:         794,
+						Signature:   "Signature-899",
+						Category:    "Category-15",
 						Severity:    0,
 					},
 				},
@@ -72,7 +73,7 @@ func TestNext(t *testing.T) {
 	for name, tc := range tests {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
-			rand.Seed(1)
+			random.Seed(1)
 			var got Firewall
 			g, err := New(ucfg.MustNewFrom(tc.config))
 			if err != nil {
@@ -98,10 +99,40 @@ func TestNext(t *testing.T) {
 	}
 }
 
+func TestTopTalkers(t *testing.T) {
+	random.Seed(1)
+	g, err := New(ucfg.MustNewFrom(map[string]interface{}{"type": Name, "event_type": "netflow", "top_talkers": map[string]interface{}{"pairs": 5, "share": 0.9}}))
+	assert.NoError(t, err)
+
+	bytes := make(map[string]int)
+	total := 0
+	for i := 0; i < 10000; i++ {
+		data, err := g.Next()
+		assert.NoError(t, err)
+		var got Firewall
+		assert.NoError(t, json.Unmarshal(data, &got))
+		bytes[got.Event.SrcIP.String()+" "+got.Event.DstIP.String()] += got.Event.Netflow.Bytes
+		total += got.Event.Netflow.Bytes
+	}
+
+	// The top pairs by bytes should be the top talkers.
+	var counts []int
+	for _, v := range bytes {
+		counts = append(counts, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+	top := 0
+	for _, v := range counts[:5] {
+		top += v
+	}
+	share := float64(top) / float64(total)
+	assert.True(t, share > 0.85 && share < 0.95, "share %f", share)
+}
+
 func BenchmarkGenerator_Next(b *testing.B) {
 	b.ReportAllocs()
 
-	rand.Seed(1)
+	random.Seed(1)
 	g, err := New(ucfg.New())
 	if err != nil {
 		b.Fatal(err)
@@ -1,14 +1,27 @@
 package vpcflow
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/leehinman/spigot/pkg/random"
+)
 
 type config struct {
-	Type string `config:"type" validate:"required"`
+	Type       string                  `config:"type" validate:"required"`
+	Version    int                     `config:"version"`
+	Fields     []string                `config:"fields"`
+	NoData     float64                 `config:"nodata"`
+	SkipData   float64                 `config:"skipdata"`
+	TopTalkers random.TopTalkersConfig `config:"top_talkers"`
 }
 
 func defaultConfig() config {
 	return config{
-		Type: Name,
+		Type:       Name,
+		Version:    2,
+		NoData:     0.05,
+		SkipData:   0.01,
+		TopTalkers: random.DefaultTopTalkersConfig(),
 	}
 }
 
@@ -16,5 +29,23 @@ func (c *config) Validate() error {
 	if c.Type != Name {
 		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
 	}
+	if c.Version < 2 || c.Version > 5 {
+		return fmt.Errorf("'%d' is not a valid value for 'version' expected 2, 3, 4 or 5", c.Version)
+	}
+	for _, name := range c.Fields {
+		f, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("'%s' is not a valid value for 'fields'", name)
+		}
+		if f.version > c.Version {
+			return fmt.Errorf("'%s' is not a valid value for 'fields' expected a field of version %d or earlier", name, c.Version)
+		}
+	}
+	if c.NoData < 0 || c.NoData > 1 {
+		return fmt.Errorf("'%g' is not a valid value for 'nodata' expected a value from 0 up to 1", c.NoData)
+	}
+	if c.SkipData < 0 || c.NoData+c.SkipData > 1 {
+		return fmt.Errorf("'%g' is not a valid value for 'skipdata' expected a value from 0 up to 1 minus nodata", c.SkipData)
+	}
 	return nil
 }
@@ -18,6 +18,51 @@ func TestConfigs(t *testing.T) {
 			hasError:    false,
 			errorString: "",
 		},
+		"Valid Type with Top Talkers": {
+			c:           map[string]interface{}{"type": Name, "top_talkers": map[string]interface{}{"pairs": 5, "share": 0.9}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Top Talkers Pairs": {
+			c:           map[string]interface{}{"type": Name, "top_talkers": map[string]interface{}{"pairs": -1}},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'pairs' expected a value of 0 or more accessing 'top_talkers'",
+		},
+		"Invalid Top Talkers Share": {
+			c:           map[string]interface{}{"type": Name, "top_talkers": map[string]interface{}{"pairs": 5, "share": 0.25}},
+			hasError:    true,
+			errorString: "'0.25' is not a valid value for 'share' expected a value from 0.5 to below 1 accessing 'top_talkers'",
+		},
+		"Valid Version 5 with Fields": {
+			c:           map[string]interface{}{"type": Name, "version": 5, "fields": []string{"version", "srcaddr", "traffic-path"}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Version": {
+			c:           map[string]interface{}{"type": Name, "version": 6},
+			hasError:    true,
+			errorString: "'6' is not a valid value for 'version' expected 2, 3, 4 or 5 accessing config",
+		},
+		"Invalid Field": {
+			c:           map[string]interface{}{"type": Name, "fields": []string{"version", "bob"}},
+			hasError:    true,
+			errorString: "'bob' is not a valid value for 'fields' accessing config",
+		},
+		"Field of Later Version": {
+			c:           map[string]interface{}{"type": Name, "fields": []string{"version", "flow-direction"}},
+			hasError:    true,
+			errorString: "'flow-direction' is not a valid value for 'fields' expected a field of version 2 or earlier accessing config",
+		},
+		"Invalid NoData": {
+			c:           map[string]interface{}{"type": Name, "nodata": 1.5},
+			hasError:    true,
+			errorString: "'1.5' is not a valid value for 'nodata' expected a value from 0 up to 1 accessing config",
+		},
+		"Invalid SkipData": {
+			c:           map[string]interface{}{"type": Name, "nodata": 0.5, "skipdata": 0.6},
+			hasError:    true,
+			errorString: "'0.6' is not a valid value for 'skipdata' expected a value from 0 up to 1 minus nodata accessing config",
+		},
 		"Invalid Type": {
 			c:           map[string]interface{}{"type": "Bob"},
 			hasError:    true,
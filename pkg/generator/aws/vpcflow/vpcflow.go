@@ -1,17 +1,37 @@
-// Package vpcflow generates version 2 AWS vpcflow log messages
+// Package vpcflow generates AWS vpcflow log messages
 //
-// For the configuration file there are no options so only the following is needed:
+// Records are space delimited, with the fields of the default format
+// of the version, or the fields of a custom format.  NODATA and
+// SKIPDATA records have "-" for the fields of the traffic.
 //
-//   - generator:
-//     type: "aws:vpcflow"
+// Configuration:
+//
+//	version:     (optional) The version of the records, 2 to 5.  Default 2.
+//	fields:      (optional) The fields of a custom format, in order, for
+//	             example [version, interface-id, srcaddr, dstaddr, action].
+//	             The fields must be of the version or an earlier one.
+//	             Default the fields of the default format of the version.
+//	nodata:      (optional) The share of NODATA records.  Default 0.05.
+//	skipdata:    (optional) The share of SKIPDATA records.  Default 0.01.
+//	top_talkers: (optional) When top_talkers.pairs is set that many host
+//	             pairs account for top_talkers.share (default 0.8) of
+//	             the bytes of every top_talkers.window (default 1m).
+//
+//	- generator:
+//	    type: "aws:vpcflow"
+//	    version: 5
+//	    top_talkers:
+//	      pairs: 5
+//	      share: 0.8
 package vpcflow
 
 import (
 	"bytes"
-	"math/rand"
+	"fmt"
+	"math/rand/v2"
 	"net"
+	"strings"
 	"text/template"
-	"time"
 
 	"github.com/elastic/go-ucfg"
 	"github.com/leehinman/spigot/pkg/generator"
@@ -22,30 +42,114 @@ import (
 const Name = "aws:vpcflow"
 
 var (
-	actions         = [...]string{"ACCEPT", "REJECT"}
-	statuses        = [...]string{"OK", "SKIPDATA", "NODATA"}
-	vpcFlowTemplate = "2 {{.Id}} eni-{{.Eni}} {{.SrcAddr}} {{.DstAddr}} {{.SrcPort}} {{.DstPort}} {{.Protocol}} {{.Packets}} {{.Bytes}} {{.Start}} {{.End}} {{.Action}} {{.LogStatus}}"
+	actions     = [...]string{"ACCEPT", "REJECT"}
+	statuses    = [...]string{"OK", "SKIPDATA", "NODATA"}
+	tcpFlags    = [...]int{1, 2, 3, 4, 18, 19}
+	awsServices = [...]string{"-", "-", "-", "AMAZON", "S3", "EC2", "DYNAMODB", "ROUTE53"}
+	directions  = [...]string{"ingress", "egress"}
+
+	// fields are the fields of the records, by name, with the version
+	// that added them.  Fields with traffic are "-" in the records
+	// without data.
+	fields = map[string]struct {
+		version int
+		traffic bool
+		value   string
+	}{
+		"version":             {version: 2, value: "{{.Version}}"},
+		"account-id":          {version: 2, value: "{{.Id}}"},
+		"interface-id":        {version: 2, value: "eni-{{.Eni}}"},
+		"srcaddr":             {version: 2, traffic: true, value: "{{.SrcAddr}}"},
+		"dstaddr":             {version: 2, traffic: true, value: "{{.DstAddr}}"},
+		"srcport":             {version: 2, traffic: true, value: "{{.SrcPort}}"},
+		"dstport":             {version: 2, traffic: true, value: "{{.DstPort}}"},
+		"protocol":            {version: 2, traffic: true, value: "{{.Protocol}}"},
+		"packets":             {version: 2, traffic: true, value: "{{.Packets}}"},
+		"bytes":               {version: 2, traffic: true, value: "{{.Bytes}}"},
+		"start":               {version: 2, value: "{{.Start}}"},
+		"end":                 {version: 2, value: "{{.End}}"},
+		"action":              {version: 2, traffic: true, value: "{{.Action}}"},
+		"log-status":          {version: 2, value: "{{.LogStatus}}"},
+		"vpc-id":              {version: 3, value: "{{.VpcId}}"},
+		"subnet-id":           {version: 3, value: "{{.SubnetId}}"},
+		"instance-id":         {version: 3, value: "{{.InstanceId}}"},
+		"tcp-flags":           {version: 3, traffic: true, value: "{{.TcpFlags}}"},
+		"type":                {version: 3, traffic: true, value: "IPv4"},
+		"pkt-srcaddr":         {version: 3, traffic: true, value: "{{.PktSrcAddr}}"},
+		"pkt-dstaddr":         {version: 3, traffic: true, value: "{{.PktDstAddr}}"},
+		"region":              {version: 4, value: "{{.Region}}"},
+		"az-id":               {version: 4, value: "{{.AzId}}"},
+		"sublocation-type":    {version: 4, value: "-"},
+		"sublocation-id":      {version: 4, value: "-"},
+		"pkt-src-aws-service": {version: 5, traffic: true, value: "{{.PktSrcAwsService}}"},
+		"pkt-dst-aws-service": {version: 5, traffic: true, value: "{{.PktDstAwsService}}"},
+		"flow-direction":      {version: 5, traffic: true, value: "{{.FlowDirection}}"},
+		"traffic-path":        {version: 5, traffic: true, value: "{{.TrafficPath}}"},
+	}
+
+	// defaultFields are the fields of the default format of each
+	// version.
+	defaultFields = map[int][]string{
+		2: {"version", "account-id", "interface-id", "srcaddr", "dstaddr", "srcport", "dstport", "protocol", "packets", "bytes", "start", "end", "action", "log-status"},
+		3: {"version", "account-id", "interface-id", "srcaddr", "dstaddr", "srcport", "dstport", "protocol", "packets", "bytes", "start", "end", "action", "log-status", "vpc-id", "subnet-id", "instance-id", "tcp-flags", "type", "pkt-srcaddr", "pkt-dstaddr"},
+		4: {"version", "account-id", "interface-id", "srcaddr", "dstaddr", "srcport", "dstport", "protocol", "packets", "bytes", "start", "end", "action", "log-status", "vpc-id", "subnet-id", "instance-id", "tcp-flags", "type", "pkt-srcaddr", "pkt-dstaddr", "region", "az-id", "sublocation-type", "sublocation-id"},
+		5: {"version", "account-id", "interface-id", "srcaddr", "dstaddr", "srcport", "dstport", "protocol", "packets", "bytes", "start", "end", "action", "log-status", "vpc-id", "subnet-id", "instance-id", "tcp-flags", "type", "pkt-srcaddr", "pkt-dstaddr", "region", "az-id", "sublocation-type", "sublocation-id", "pkt-src-aws-service", "pkt-dst-aws-service", "flow-direction", "traffic-path"},
+	}
+
+	vpcFlowTemplate = format(defaultFields[2])
 )
 
+// format returns the template of a record with names, which must be
+// names of fields.
+func format(names []string) string {
+	values := make([]string, len(names))
+	for i, name := range names {
+		f := fields[name]
+		if f.traffic {
+			values[i] = "{{if .HasData}}" + f.value + "{{else}}-{{end}}"
+		} else {
+			values[i] = f.value
+		}
+	}
+	return strings.Join(values, " ")
+}
+
 // Vpcflow holds the random fields for a vpcflow record.
 type Vpcflow struct {
-	//	version     string
-	//	accountId   string
-	//	interfaceId string
-	Id        int
-	Eni       int
-	SrcAddr   net.IP
-	DstAddr   net.IP
-	SrcPort   int
-	DstPort   int
-	Protocol  int
-	Packets   int
-	Bytes     int
-	Start     int64
-	End       int64
-	Action    string
-	LogStatus string
-	template  *template.Template
+	Version          int
+	Id               int
+	Eni              int
+	SrcAddr          net.IP
+	DstAddr          net.IP
+	SrcPort          int
+	DstPort          int
+	Protocol         int
+	Packets          int
+	Bytes            int
+	Start            int64
+	End              int64
+	Action           string
+	LogStatus        string
+	HasData          bool
+	VpcId            string
+	SubnetId         string
+	InstanceId       string
+	TcpFlags         int
+	PktSrcAddr       net.IP
+	PktDstAddr       net.IP
+	Region           string
+	AzId             string
+	PktSrcAwsService string
+	PktDstAwsService string
+	FlowDirection    string
+	TrafficPath      string
+	template         *template.Template
+	rand             *rand.Rand
+	clock            *generator.Clock
+	pins             *generator.Pins
+	talkers          *random.TopTalkers
+	noData           float64
+	skipData         float64
 }
 
 func init() {
@@ -59,9 +163,34 @@ func New(cfg *ucfg.Config) (generator.Generator, error) {
 		return nil, err
 	}
 
-	v := &Vpcflow{}
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	t, err := template.New("vpcflow").Funcs(generator.FunctionMap).Parse(vpcFlowTemplate)
+	v := &Vpcflow{
+		Version:  c.Version,
+		rand:     r,
+		clock:    clock,
+		talkers:  random.NewTopTalkers(r, c.TopTalkers),
+		noData:   c.NoData,
+		skipData: c.SkipData,
+	}
+
+	v.pins, err = generator.NewPins(cfg, r, v)
+	if err != nil {
+		return nil, err
+	}
+
+	names := c.Fields
+	if len(names) == 0 {
+		names = defaultFields[c.Version]
+	}
+	t, err := template.New("vpcflow").Funcs(generator.FunctionMap).Parse(format(names))
 	if err != nil {
 		return nil, err
 	}
@@ -77,6 +206,7 @@ func New(cfg *ucfg.Config) (generator.Generator, error) {
 // Example:
 //
 // 2 123456789010 eni-1235b8ca123456789 172.31.16.139 172.31.16.21 20641 22 6 20 4249 1418530010 1418530070 ACCEPT OK
+// 2 123456789010 eni-1235b8ca123456789 - - - - - - - 1418530010 1418530070 - NODATA
 func (v *Vpcflow) Next() ([]byte, error) {
 	var buf bytes.Buffer
 
@@ -92,21 +222,75 @@ func (v *Vpcflow) Next() ([]byte, error) {
 }
 
 func (v *Vpcflow) randomize() {
-	v.Id = rand.Intn(1048576)
-	v.Eni = rand.Intn(1048576223) * rand.Intn(1048576223)
-	v.SrcAddr = random.IPv4()
-	v.DstAddr = random.IPv4()
-	v.SrcPort = random.Port()
-	v.DstPort = random.Port()
-	v.Protocol = rand.Intn(256)
-	v.Packets = rand.Intn(1048576)
+	v.Id = v.rand.IntN(1048576)
+	v.Eni = v.rand.IntN(1048576223) * v.rand.IntN(1048576223)
+	v.SrcAddr = random.IPv4(v.rand)
+	v.DstAddr = random.IPv4(v.rand)
+	v.SrcPort = random.Port(v.rand)
+	v.DstPort = random.Port(v.rand)
+	v.Protocol = v.rand.IntN(256)
+	v.Packets = v.rand.IntN(1048576)
+	now := v.clock.Now()
+	if v.talkers != nil {
+		var scale int
+		v.SrcAddr, v.DstAddr, scale = v.talkers.Pair(now, v.Packets*1500)
+		v.Packets *= scale
+	}
 	v.Bytes = v.Packets * 1500
-	v.End = time.Now().Unix()
-	v.Start = v.End - int64(rand.Intn(60))
-	v.Action = actions[rand.Intn(2)]
-	if v.Packets == 0 {
+	v.End = now.Unix()
+	v.Start = v.End - int64(v.rand.IntN(60))
+	v.Action = actions[v.rand.IntN(2)]
+	switch f := v.rand.Float64(); {
+	case v.Packets == 0 || f < v.noData:
 		v.LogStatus = statuses[2]
-	} else {
-		v.LogStatus = statuses[rand.Intn(2)]
+	case f < v.noData+v.skipData:
+		v.LogStatus = statuses[1]
+	default:
+		v.LogStatus = statuses[0]
+	}
+	v.HasData = v.LogStatus == statuses[0]
+
+	v.VpcId = fmt.Sprintf("vpc-%08x", v.rand.Uint32())
+	v.SubnetId = fmt.Sprintf("subnet-%08x", v.rand.Uint32())
+	v.InstanceId = fmt.Sprintf("i-%017x", v.rand.Int64()>>5)
+	v.TcpFlags = 0
+	if v.Protocol == 6 {
+		v.TcpFlags = tcpFlags[v.rand.IntN(len(tcpFlags))]
+	}
+	v.PktSrcAddr = v.SrcAddr
+	v.PktDstAddr = v.DstAddr
+	v.Region = random.AWSRegion(v.rand)
+	v.AzId = azId(v.Region, v.rand.IntN(3)+1)
+	v.PktSrcAwsService = awsServices[v.rand.IntN(len(awsServices))]
+	v.PktDstAwsService = awsServices[v.rand.IntN(len(awsServices))]
+	v.FlowDirection = directions[v.rand.IntN(len(directions))]
+	v.TrafficPath = "-"
+	if v.FlowDirection == "egress" {
+		v.TrafficPath = fmt.Sprint(v.rand.IntN(8) + 1)
+	}
+
+	v.pins.Apply(v)
+}
+
+// azId returns the id of the n-th availability zone of region, for
+// example "use1-az1" for "us-east-1".
+func azId(region string, n int) string {
+	var b strings.Builder
+	for _, part := range strings.Split(region, "-") {
+		switch part {
+		case "north", "south", "east", "west", "central":
+			b.WriteByte(part[0])
+		case "northeast", "northwest", "southeast", "southwest":
+			b.WriteByte(part[0])
+			b.WriteByte(part[5])
+		default:
+			b.WriteString(part)
+		}
 	}
+	return fmt.Sprintf("%s-az%d", b.String(), n)
+}
+
+// Templates returns the templates of the generator, for generator.Lint.
+func (v *Vpcflow) Templates() (interface{}, []*template.Template) {
+	return v, []*template.Template{v.template}
 }
@@ -1,11 +1,15 @@
 package vpcflow
 
 import (
-	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"text/template"
 
+	"github.com/elastic/go-ucfg"
 	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,13 +20,13 @@ func TestNext(t *testing.T) {
 	}{
 		"vpcflow v2": {
 			template: vpcFlowTemplate,
-			expected: "2 123456789010 eni-1235b8ca123456789 66.4.203.154 30.52.197.240 19911 1211 129 643462 965193000 2 42 ACCEPT OK",
+			expected: "2 24076 eni-507734326139160960 91.92.37.225 189.255.226.189 55714 13208 191 48003 72004500 2 42 ACCEPT OK",
 		},
 	}
 
 	for name, tc := range tests {
-		rand.Seed(1)
-		v := &Vpcflow{}
+		random.Seed(1)
+		v := &Vpcflow{Version: 2, rand: random.NewRand(nil)}
 		tmpl, err := template.New(name).Funcs(generator.FunctionMap).Parse(tc.template)
 		assert.Nil(t, err, name)
 		v.template = tmpl
@@ -34,3 +38,87 @@ func TestNext(t *testing.T) {
 		assert.Equal(t, []byte(tc.expected), got, name)
 	}
 }
+
+func TestTopTalkers(t *testing.T) {
+	random.Seed(1)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "top_talkers": map[string]interface{}{"pairs": 3}})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+
+	bytes := make(map[string]int)
+	total := 0
+	for i := 0; i < 10000; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		f := strings.Fields(string(got))
+		if f[13] != "OK" {
+			continue
+		}
+		n, err := strconv.Atoi(f[9])
+		assert.Nil(t, err)
+		bytes[f[3]+" "+f[4]] += n
+		total += n
+	}
+
+	// The top pairs by bytes should be the top talkers.
+	var counts []int
+	for _, v := range bytes {
+		counts = append(counts, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+	top := 0
+	for _, v := range counts[:3] {
+		top += v
+	}
+	share := float64(top) / float64(total)
+	assert.True(t, share > 0.7 && share < 0.9, "share %f", share)
+}
+
+func TestFields(t *testing.T) {
+	tests := map[string]struct {
+		config map[string]interface{}
+		fields int
+		nodata []string
+	}{
+		"version 2": {
+			config: map[string]interface{}{"type": Name},
+			fields: 14,
+			nodata: []string{"2", "-", "-", "-", "-", "-", "-", "-", "-", "NODATA"},
+		},
+		"version 5": {
+			config: map[string]interface{}{"type": Name, "version": 5},
+			fields: 29,
+			nodata: []string{"5", "-", "-", "-", "-", "-", "-", "-", "-", "NODATA", "-", "-", "-", "-", "-", "-", "-", "-", "-", "-"},
+		},
+		"custom": {
+			config: map[string]interface{}{"type": Name, "version": 5, "fields": []string{"version", "srcaddr", "flow-direction", "log-status"}},
+			fields: 4,
+			nodata: []string{"5", "-", "-", "NODATA"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			g, err := New(ucfg.MustNewFrom(tc.config))
+			assert.Nil(t, err)
+			for i := 0; i < 10; i++ {
+				got, err := g.Next()
+				assert.Nil(t, err)
+				assert.Len(t, strings.Fields(string(got)), tc.fields)
+			}
+
+			v := g.(*Vpcflow)
+			v.LogStatus = "NODATA"
+			v.HasData = false
+			got, err := v.Next()
+			assert.Nil(t, err)
+			var values []string
+			for _, s := range strings.Fields(string(got)) {
+				if s == "-" || s == "NODATA" || s == "5" || s == "2" {
+					values = append(values, s)
+				}
+			}
+			assert.Equal(t, tc.nodata, values)
+		})
+	}
+}
@@ -0,0 +1,542 @@
+// Package activitylogs generates Azure Activity Log records, in the
+// JSON envelope diagnostic settings stream them to an Event Hub.
+//
+// Every event is an Event Hub message, an object with a "records"
+// array of batch_size records.  The records are of the Administrative,
+// Security, Policy, ServiceHealth, Alert and Autoscale categories, with
+// the properties of their category.  An administrative operation on a
+// resource is a Start record and a Success or Failure record with the
+// same correlationId, and its caller is one of a pool of users of the
+// tenant, with the claims of their token in the identity.  By default
+// every category is as likely, categories picks them with weights
+// instead, the categories without a weight are left out.  With
+// entities the callers are the users of the shared population of
+// package entities, from the address of their host, mostly at work.
+//
+// Configuration:
+//
+//	categories: (map, optional) The weights of the categories.
+//	batch_size: (int, optional) The number of records of an event,
+//	            defaults to 1.
+//	entities:   (bool, optional) Draw the callers from the shared
+//	            population.
+//
+//	- generator:
+//	    type: azure:activitylogs
+//	    batch_size: 20
+//	    categories:
+//	      Administrative: 80
+//	      Policy: 10
+//	      Security: 5
+//	      ServiceHealth: 5
+package activitylogs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/entities"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "azure:activitylogs"
+
+// Categories of the Activity Log.
+const (
+	CategoryAdministrative = "Administrative"
+	CategorySecurity       = "Security"
+	CategoryPolicy         = "Policy"
+	CategoryServiceHealth  = "ServiceHealth"
+	CategoryAlert          = "Alert"
+	CategoryAutoscale      = "Autoscale"
+
+	timeFmt = "2006-01-02T15:04:05.0000000Z"
+)
+
+var (
+	categories = [...]string{CategoryAdministrative, CategorySecurity, CategoryPolicy, CategoryServiceHealth, CategoryAlert, CategoryAutoscale}
+	firstNames = [...]string{"Adele", "Alex", "Diego", "Grady", "Isaiah", "Johanna", "Lee", "Lidia", "Lynne", "Megan", "Miriam", "Nestor", "Patti", "Pradeep"}
+	lastNames  = [...]string{"Vance", "Wilber", "Siciliani", "Archie", "Langer", "Lauer", "Gu", "Holloway", "Robbins", "Bowen", "Graham", "Wilke", "Fernandez", "Gupta"}
+	groups     = [...]string{"rg-prod-web", "rg-prod-data", "rg-shared-network", "rg-dev", "rg-security"}
+	regions    = [...]string{"westeurope", "northeurope", "eastus", "eastus2", "westus2", "uksouth"}
+	// resourceTypes are the resource types of the administrative
+	// operations, with the names of their resources and their
+	// operations.
+	resourceTypes = [...]struct {
+		provider string
+		names    []string
+		actions  []string
+	}{
+		{"Microsoft.Compute/virtualMachines", []string{"vm-web-01", "vm-web-02", "vm-sql-01", "vm-jump-01"}, []string{"write", "delete", "start/action", "deallocate/action", "restart/action"}},
+		{"Microsoft.Storage/storageAccounts", []string{"stprodlogs01", "stproddata02", "stdevscratch"}, []string{"write", "delete", "listKeys/action", "regenerateKey/action"}},
+		{"Microsoft.KeyVault/vaults", []string{"kv-prod-secrets", "kv-dev-secrets"}, []string{"write", "delete", "accessPolicies/write"}},
+		{"Microsoft.Network/networkSecurityGroups", []string{"nsg-web", "nsg-data", "nsg-jump"}, []string{"write", "delete", "securityRules/write", "securityRules/delete"}},
+		{"Microsoft.Authorization/roleAssignments", []string{"3f2a9c1e-8d4b-4f6a-9e2c-1b7d5a0c8e31", "a81c4e2f-5b9d-4a3e-8f1c-6d2b7e9a0c54"}, []string{"write", "delete"}},
+		{"Microsoft.Web/sites", []string{"app-portal-prod", "func-orders-prod"}, []string{"write", "restart/action", "config/write"}},
+	}
+	// failures are the status codes of failed operations.
+	failures = [...]string{"Forbidden", "Conflict", "BadRequest", "NotFound"}
+	roles    = [...]struct {
+		name, id string
+	}{
+		{"Owner", "8e3af657-a8ff-443c-a75c-2fe8c4bcb635"},
+		{"Contributor", "b24988ac-6180-42a0-ab88-20f7382dd24c"},
+		{"Virtual Machine Contributor", "9980e02c-c2be-4d73-94e8-173b1dc7cf3c"},
+		{"Storage Account Contributor", "17d1049b-9a84-46fb-8f53-869881c3d3ab"},
+	}
+	policies = [...]struct {
+		name, definition, effect string
+	}{
+		{"Allowed locations", "e56962a6-4747-49cd-b67b-bf8b01975c4c", "Deny"},
+		{"Storage accounts should restrict network access", "34c877ad-507e-4c82-993e-3452a6e0ad3c", "Audit"},
+		{"Key vaults should have soft delete enabled", "1e66c121-a66a-4b1f-9b83-0fd99bf0fc2d", "Audit"},
+		{"Require a tag on resources", "871b6d14-10aa-478d-b590-94f262ecfa99", "Deny"},
+	}
+	alerts = [...]struct {
+		name, severity, intent, remediation string
+	}{
+		{"Suspicious authentication activity", "Medium", "PreAttack", "Enforce strong passwords and enable multi-factor authentication"},
+		{"Detected suspicious file download", "High", "Execution", "Review the process that downloaded the file and isolate the machine"},
+		{"Possible incoming SQL brute force attempts", "High", "PreAttack", "Block the source addresses and enable just-in-time VM access"},
+		{"Traffic detected from IP addresses recommended for blocking", "Low", "PreAttack", "Restrict access with network security group rules"},
+		{"Suspicious PowerShell script executed", "High", "Execution", "Review the script and the account that ran it"},
+	}
+	services = [...]string{"Virtual Machines", "Storage", "Azure Active Directory", "App Service", "Azure SQL Database", "Azure Monitor"}
+	metrics  = [...]struct {
+		rule, metric, unit, threshold string
+	}{
+		{"cpu-high", "Percentage CPU", "Percent", "80"},
+		{"memory-low", "Available Memory Bytes", "Bytes", "536870912"},
+		{"disk-queue", "OS Disk Queue Depth", "Count", "10"},
+		{"http-5xx", "Http5xx", "Count", "25"},
+	}
+)
+
+// Authorization is the authorization of the operation of a caller.
+type Authorization struct {
+	Scope    string            `json:"scope"`
+	Action   string            `json:"action"`
+	Evidence map[string]string `json:"evidence"`
+}
+
+// Identity is the caller of an operation.
+type Identity struct {
+	Authorization Authorization     `json:"authorization"`
+	Claims        map[string]string `json:"claims"`
+}
+
+// Record is an Activity Log record.
+type Record struct {
+	Time            string                 `json:"time"`
+	ResourceID      string                 `json:"resourceId"`
+	OperationName   string                 `json:"operationName"`
+	Category        string                 `json:"category"`
+	ResultType      string                 `json:"resultType"`
+	ResultSignature string                 `json:"resultSignature"`
+	DurationMs      string                 `json:"durationMs"`
+	CallerIPAddress string                 `json:"callerIpAddress"`
+	CorrelationID   string                 `json:"correlationId"`
+	Identity        *Identity              `json:"identity,omitempty"`
+	Level           string                 `json:"level"`
+	Location        string                 `json:"location"`
+	Properties      map[string]interface{} `json:"properties"`
+}
+
+// caller is a user of the tenant.
+type caller struct {
+	upn, name, objectID string
+	ip                  net.IP
+}
+
+// ActivityLogs holds the state of the Azure Activity Log generator.
+type ActivityLogs struct {
+	Record Record
+
+	rand          *rand.Rand
+	clock         *generator.Clock
+	pins          *generator.Pins
+	entities      *entities.Population
+	weights       []float64
+	total         float64
+	batch         int
+	tenant        string
+	subscriptions []string
+	callers       []caller
+	ids           map[string]string
+	pending       []Record
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for ActivityLogs objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &ActivityLogs{rand: r, clock: clock, batch: c.BatchSize, tenant: random.UUID(r)}
+	a.pins, err = generator.NewPins(cfg, r, &a.Record)
+	if err != nil {
+		return nil, err
+	}
+	a.entities, err = entities.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.entities != nil {
+		// The object ids of the users are the same for every record.
+		a.ids = make(map[string]string, len(a.entities.Users))
+		for _, u := range a.entities.Users {
+			a.ids[u.Name] = random.UUID(r)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		a.subscriptions = append(a.subscriptions, random.UUID(r))
+	}
+	for i := range firstNames {
+		a.callers = append(a.callers, caller{
+			upn:      strings.ToLower(firstNames[i]+"."+lastNames[i]) + "@contoso.onmicrosoft.com",
+			name:     firstNames[i] + " " + lastNames[i],
+			objectID: random.UUID(r),
+			ip:       random.IPv4(r),
+		})
+	}
+	if c.Categories != nil {
+		for _, category := range categories {
+			a.weights = append(a.weights, c.Categories[category])
+			a.total += c.Categories[category]
+		}
+	}
+	return a, nil
+}
+
+// envelope is the body of an Event Hub message of diagnostic settings.
+type envelope struct {
+	Records []Record `json:"records"`
+}
+
+// Next produces the next Event Hub message.
+//
+// Example:
+//
+// {"records":[{"time":"2024-03-04T12:00:00.0000000Z","resourceId":"/SUBSCRIPTIONS/6A1F.../RESOURCEGROUPS/RG-PROD-WEB/PROVIDERS/MICROSOFT.COMPUTE/VIRTUALMACHINES/VM-WEB-01","operationName":"MICROSOFT.COMPUTE/VIRTUALMACHINES/WRITE","category":"Administrative","resultType":"Start",...}]}
+func (a *ActivityLogs) Next() ([]byte, error) {
+	records := make([]Record, a.batch)
+	for i := range records {
+		for len(a.pending) == 0 {
+			a.generate(a.clock.Now())
+		}
+		a.Record = a.pending[0]
+		a.pending = a.pending[1:]
+		a.pins.Apply(&a.Record)
+		records[i] = a.Record
+	}
+	return json.Marshal(envelope{Records: records})
+}
+
+// generate queues the records of an operation of a random category.
+func (a *ActivityLogs) generate(now time.Time) {
+	switch a.category() {
+	case CategoryAdministrative:
+		a.administrative(now)
+	case CategorySecurity:
+		a.security(now)
+	case CategoryPolicy:
+		a.policy(now)
+	case CategoryServiceHealth:
+		a.serviceHealth(now)
+	case CategoryAlert:
+		a.alert(now)
+	case CategoryAutoscale:
+		a.autoscale(now)
+	}
+}
+
+// category returns the category of the next operation, picked with the
+// weights of the categories when they are set.
+func (a *ActivityLogs) category() string {
+	if a.weights == nil {
+		return categories[a.rand.IntN(len(categories))]
+	}
+	n := a.rand.Float64() * a.total
+	for i, w := range a.weights {
+		if n < w {
+			return categories[i]
+		}
+		n -= w
+	}
+	// n can only be left over by rounding, the last category with a
+	// weight is the pick.
+	for i := len(a.weights) - 1; ; i-- {
+		if a.weights[i] > 0 {
+			return categories[i]
+		}
+	}
+}
+
+// caller returns a random caller, a user of the shared population at
+// work at now with entities.
+func (a *ActivityLogs) caller(now time.Time) caller {
+	if a.entities != nil {
+		u := a.entities.ActiveUser(a.rand, now)
+		return caller{upn: u.Email, name: u.DisplayName, objectID: a.ids[u.Name], ip: a.entities.HostOf(u).IP}
+	}
+	return a.callers[a.rand.IntN(len(a.callers))]
+}
+
+// resourceID returns the resource id of a resource, in upper case as
+// in the Activity Log.
+func (a *ActivityLogs) resourceID(sub, group, provider, name string) string {
+	return strings.ToUpper(fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/%s/%s", sub, group, provider, name))
+}
+
+// record returns a record of the category with the fields every
+// category has.
+func (a *ActivityLogs) record(category, resourceID, operation string, now time.Time) Record {
+	return Record{
+		Time:          a.clock.Format(now.UTC(), timeFmt),
+		ResourceID:    resourceID,
+		OperationName: strings.ToUpper(operation),
+		Category:      category,
+		DurationMs:    "0",
+		CorrelationID: random.UUID(a.rand),
+		Level:         "Information",
+		Location:      "global",
+		Properties: map[string]interface{}{
+			"eventCategory": category,
+			"entity":        resourceID,
+			"message":       operation,
+		},
+	}
+}
+
+// identity returns the identity of the caller u for the action on the
+// scope.
+func (a *ActivityLogs) identity(u caller, sub, scope, action string) *Identity {
+	role := roles[a.rand.IntN(len(roles))]
+	return &Identity{
+		Authorization: Authorization{
+			Scope:  scope,
+			Action: action,
+			Evidence: map[string]string{
+				"role":                role.name,
+				"roleAssignmentScope": "/subscriptions/" + sub,
+				"roleAssignmentId":    strings.ReplaceAll(role.id, "-", ""),
+				"roleDefinitionId":    strings.ReplaceAll(role.id, "-", ""),
+				"principalId":         strings.ReplaceAll(u.objectID, "-", ""),
+				"principalType":       "User",
+			},
+		},
+		Claims: map[string]string{
+			"aud":    "https://management.core.windows.net/",
+			"iss":    "https://sts.windows.net/" + a.tenant + "/",
+			"appid":  "c44b4083-3bb0-49c1-b47d-974e53cbdf3c",
+			"ipaddr": u.ip.String(),
+			"name":   u.name,
+			"http://schemas.microsoft.com/identity/claims/objectidentifier": u.objectID,
+			"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/upn":     u.upn,
+			"http://schemas.microsoft.com/identity/claims/tenantid":         a.tenant,
+		},
+	}
+}
+
+// administrative queues the Start and the Success or Failure records
+// of an operation of a caller on a resource.
+func (a *ActivityLogs) administrative(now time.Time) {
+	sub := a.subscriptions[a.rand.IntN(len(a.subscriptions))]
+	group := groups[a.rand.IntN(len(groups))]
+	rt := resourceTypes[a.rand.IntN(len(resourceTypes))]
+	name := rt.names[a.rand.IntN(len(rt.names))]
+	action := rt.provider + "/" + rt.actions[a.rand.IntN(len(rt.actions))]
+	id := a.resourceID(sub, group, rt.provider, name)
+	scope := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/%s/%s", sub, group, rt.provider, name)
+	u := a.caller(now)
+
+	start := a.record(CategoryAdministrative, id, action, now)
+	start.ResultType = "Start"
+	start.ResultSignature = "Started."
+	start.CallerIPAddress = u.ip.String()
+	start.Identity = a.identity(u, sub, scope, action)
+	start.Properties["hierarchy"] = a.tenant + "/" + sub
+
+	duration := time.Duration(a.rand.IntN(30000)+100) * time.Millisecond
+	end := start
+	end.Time = a.clock.Format(now.Add(duration).UTC(), timeFmt)
+	end.DurationMs = strconv.FormatInt(duration.Milliseconds(), 10)
+	end.Properties = map[string]interface{}{
+		"eventCategory":    CategoryAdministrative,
+		"entity":           id,
+		"message":          action,
+		"hierarchy":        a.tenant + "/" + sub,
+		"serviceRequestId": random.UUID(a.rand),
+	}
+	if a.rand.IntN(10) == 0 {
+		status := failures[a.rand.IntN(len(failures))]
+		end.ResultType = "Failure"
+		end.ResultSignature = "Failed." + status
+		end.Level = "Error"
+		end.Properties["statusCode"] = status
+	} else {
+		status := "OK"
+		if strings.HasSuffix(action, "/write") {
+			status = "Created"
+		}
+		end.ResultType = "Success"
+		end.ResultSignature = "Succeeded." + status
+		end.Properties["statusCode"] = status
+	}
+	a.pending = append(a.pending, start, end)
+}
+
+// security queues a Microsoft Defender for Cloud alert on a virtual
+// machine.
+func (a *ActivityLogs) security(now time.Time) {
+	sub := a.subscriptions[a.rand.IntN(len(a.subscriptions))]
+	vm := resourceTypes[0].names[a.rand.IntN(len(resourceTypes[0].names))]
+	alert := alerts[a.rand.IntN(len(alerts))]
+	r := a.record(CategorySecurity, a.resourceID(sub, groups[0], resourceTypes[0].provider, vm), "Microsoft.Security/locations/alerts/activate/action", now)
+	r.ResultType = "Active"
+	r.Level = map[string]string{"High": "Critical", "Medium": "Warning", "Low": "Informational"}[alert.severity]
+	r.Location = regions[a.rand.IntN(len(regions))]
+	r.Properties["eventName"] = alert.name
+	r.Properties["severity"] = alert.severity
+	r.Properties["compromisedEntity"] = strings.ToUpper(vm)
+	r.Properties["attackedResourceType"] = "Virtual Machine"
+	r.Properties["intent"] = alert.intent
+	r.Properties["remediationSteps"] = alert.remediation
+	r.Properties["operationId"] = random.UUID(a.rand)
+	a.pending = append(a.pending, r)
+}
+
+// policy queues the audit or deny of a resource request by a policy.
+func (a *ActivityLogs) policy(now time.Time) {
+	sub := a.subscriptions[a.rand.IntN(len(a.subscriptions))]
+	group := groups[a.rand.IntN(len(groups))]
+	rt := resourceTypes[a.rand.IntN(len(resourceTypes)-2)]
+	name := rt.names[a.rand.IntN(len(rt.names))]
+	p := policies[a.rand.IntN(len(policies))]
+	action := "Microsoft.Authorization/policies/" + strings.ToLower(p.effect) + "/action"
+	u := a.caller(now)
+
+	r := a.record(CategoryPolicy, a.resourceID(sub, group, rt.provider, name), action, now)
+	r.CallerIPAddress = u.ip.String()
+	r.Identity = a.identity(u, sub, fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/%s/%s", sub, group, rt.provider, name), rt.provider+"/write")
+	r.ResultType = "Success"
+	r.Level = "Warning"
+	if p.effect == "Deny" {
+		r.ResultType = "Failure"
+		r.Level = "Error"
+	}
+	details, _ := json.Marshal([]map[string]string{{
+		"policyDefinitionId":     "/providers/Microsoft.Authorization/policyDefinitions/" + p.definition,
+		"policyDefinitionName":   p.definition,
+		"policyDefinitionEffect": p.effect,
+		"policyAssignmentId":     "/subscriptions/" + sub + "/providers/Microsoft.Authorization/policyAssignments/" + strings.ReplaceAll(strings.ToLower(p.name), " ", "-"),
+		"policyAssignmentName":   p.name,
+	}})
+	r.Properties["isComplianceCheck"] = "False"
+	r.Properties["resourceLocation"] = regions[a.rand.IntN(len(regions))]
+	r.Properties["ancestors"] = a.tenant
+	r.Properties["policies"] = string(details)
+	r.Properties["hierarchy"] = ""
+	a.pending = append(a.pending, r)
+}
+
+// serviceHealth queues a service health incident of a subscription.
+func (a *ActivityLogs) serviceHealth(now time.Time) {
+	sub := a.subscriptions[a.rand.IntN(len(a.subscriptions))]
+	service := services[a.rand.IntN(len(services))]
+	region := regions[a.rand.IntN(len(regions))]
+	id := "/SUBSCRIPTIONS/" + strings.ToUpper(sub)
+	r := a.record(CategoryServiceHealth, id, "Microsoft.ServiceHealth/incident/action", now)
+	stage := [...]string{"Active", "Active", "Resolved"}[a.rand.IntN(3)]
+	r.ResultType = stage
+	r.Level = "Warning"
+	if stage == "Resolved" {
+		r.Level = "Informational"
+	}
+	title := fmt.Sprintf("%s - %s - Mitigated", service, region)
+	if stage == "Active" {
+		title = fmt.Sprintf("Degraded performance of %s in %s", service, region)
+	}
+	impacted, _ := json.Marshal([]map[string]interface{}{{
+		"ServiceName":     service,
+		"ImpactedRegions": []map[string]string{{"RegionName": region}},
+	}})
+	r.Properties["title"] = title
+	r.Properties["service"] = service
+	r.Properties["region"] = region
+	r.Properties["communication"] = "Starting at " + now.Add(-30*time.Minute).UTC().Format("15:04 UTC") + " customers using " + service + " in " + region + " may experience degraded performance."
+	r.Properties["incidentType"] = "Incident"
+	r.Properties["trackingId"] = strings.ToUpper(random.Hex(a.rand, 2) + "-" + random.Hex(a.rand, 2)[:3])
+	r.Properties["impactStartTime"] = now.Add(-30 * time.Minute).UTC().Format(time.RFC3339)
+	r.Properties["impactedServices"] = string(impacted)
+	r.Properties["stage"] = stage
+	a.pending = append(a.pending, r)
+}
+
+// alert queues the activation or resolution of a metric alert.
+func (a *ActivityLogs) alert(now time.Time) {
+	sub := a.subscriptions[a.rand.IntN(len(a.subscriptions))]
+	m := metrics[a.rand.IntN(len(metrics))]
+	id := a.resourceID(sub, groups[0], "Microsoft.Insights/metricAlerts", m.rule)
+	action := "Activated"
+	level := "Warning"
+	if a.rand.IntN(2) == 0 {
+		action, level = "Resolved", "Informational"
+	}
+	r := a.record(CategoryAlert, id, "Microsoft.Insights/metricAlerts/"+action+"/action", now)
+	r.ResultType = "Succeeded"
+	r.Level = level
+	r.Properties["RuleUri"] = strings.ToLower(id)
+	r.Properties["RuleName"] = m.rule
+	r.Properties["RuleDescription"] = ""
+	r.Properties["Threshold"] = m.threshold
+	r.Properties["WindowSizeInMinutes"] = "5"
+	r.Properties["Aggregation"] = "Average"
+	r.Properties["Operator"] = "GreaterThan"
+	r.Properties["MetricName"] = m.metric
+	r.Properties["MetricUnit"] = m.unit
+	a.pending = append(a.pending, r)
+}
+
+// autoscale queues a scale up or down of a virtual machine scale set.
+func (a *ActivityLogs) autoscale(now time.Time) {
+	sub := a.subscriptions[a.rand.IntN(len(a.subscriptions))]
+	target := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/vmss-web", sub, groups[0])
+	id := a.resourceID(sub, groups[0], "Microsoft.Insights/autoscaleSettings", "vmss-web-autoscale")
+	from := a.rand.IntN(8) + 2
+	to, action := from+1+a.rand.IntN(3), "Scaleup"
+	if a.rand.IntN(2) == 0 {
+		to, action = from-1, "Scaledown"
+	}
+	r := a.record(CategoryAutoscale, id, "Microsoft.Insights/AutoscaleSettings/"+action+"/Action", now)
+	r.ResultType = "Succeeded"
+	r.Properties["Description"] = fmt.Sprintf("The autoscale engine attempting to scale resource '%s' from %d instances count to %d instances count.", target, from, to)
+	r.Properties["ResourceName"] = target
+	r.Properties["OldInstancesCount"] = strconv.Itoa(from)
+	r.Properties["NewInstancesCount"] = strconv.Itoa(to)
+	r.Properties["LastScaleActionTime"] = now.UTC().Format(time.RFC1123)
+	a.pending = append(a.pending, r)
+}
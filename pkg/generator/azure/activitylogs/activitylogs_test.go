@@ -0,0 +1,86 @@
+package activitylogs
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func next(t *testing.T, c map[string]interface{}, n int) []Record {
+	t.Helper()
+	cfg, err := ucfg.NewFrom(c)
+	assert.Nil(t, err)
+	g, err := New(cfg)
+	assert.Nil(t, err)
+	var records []Record
+	for i := 0; i < n; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		var e struct {
+			Records []Record `json:"records"`
+		}
+		if assert.Nil(t, json.Unmarshal(got, &e), string(got)) {
+			records = append(records, e.Records...)
+		}
+	}
+	return records
+}
+
+func TestNext(t *testing.T) {
+	records := next(t, map[string]interface{}{"type": Name, "seed": 1}, 1000)
+	assert.Len(t, records, 1000)
+
+	seen := map[string]int{}
+	starts := map[string]Record{}
+	for _, r := range records {
+		seen[r.Category]++
+		_, err := time.Parse(timeFmt, r.Time)
+		assert.Nil(t, err)
+		assert.Equal(t, r.Category, r.Properties["eventCategory"])
+		assert.Regexp(t, "^/SUBSCRIPTIONS/[0-9A-F-]{36}", r.ResourceID)
+
+		switch r.Category {
+		case CategoryAdministrative:
+			assert.NotNil(t, r.Identity)
+			if r.ResultType == "Start" {
+				starts[r.CorrelationID] = r
+				continue
+			}
+			start, ok := starts[r.CorrelationID]
+			if !assert.True(t, ok, "end without start") {
+				continue
+			}
+			assert.Contains(t, []string{"Success", "Failure"}, r.ResultType)
+			assert.Equal(t, start.ResourceID, r.ResourceID)
+			assert.Equal(t, start.OperationName, r.OperationName)
+			assert.Equal(t, start.CallerIPAddress, r.CallerIPAddress)
+		case CategoryPolicy:
+			var policies []map[string]string
+			assert.Nil(t, json.Unmarshal([]byte(r.Properties["policies"].(string)), &policies))
+			assert.Len(t, policies, 1)
+		case CategorySecurity:
+			assert.Contains(t, []string{"High", "Medium", "Low"}, r.Properties["severity"])
+		case CategoryAutoscale:
+			assert.NotEqual(t, r.Properties["OldInstancesCount"], r.Properties["NewInstancesCount"])
+		}
+	}
+	assert.Len(t, seen, len(categories))
+}
+
+func TestBatchSize(t *testing.T) {
+	records := next(t, map[string]interface{}{"type": Name, "batch_size": 25}, 4)
+	assert.Len(t, records, 100)
+}
+
+func TestCategories(t *testing.T) {
+	records := next(t, map[string]interface{}{"type": Name, "categories": map[string]interface{}{"Policy": 1, "Alert": 3}}, 1000)
+	seen := map[string]int{}
+	for _, r := range records {
+		seen[r.Category]++
+	}
+	assert.Len(t, seen, 2)
+	assert.InDelta(t, 750, seen[CategoryAlert], 75)
+}
@@ -0,0 +1,54 @@
+package signinlogs
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Type       string             `config:"type" validate:"required"`
+	Categories map[string]float64 `config:"categories"`
+	BatchSize  int                `config:"batch_size"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:      Name,
+		BatchSize: 1,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if c.BatchSize < 1 {
+		return fmt.Errorf("'%d' is not a valid value for 'batch_size' expected a value greater than 0", c.BatchSize)
+	}
+	if c.Categories == nil {
+		return nil
+	}
+	total := 0.0
+	for category, w := range c.Categories {
+		if !validCategory(category) {
+			return fmt.Errorf("'%s' is not a valid value for 'categories' expected '%s'", category, strings.Join(categories[:], ", "))
+		}
+		if w < 0 {
+			return fmt.Errorf("'%v' is not a valid value for 'categories.%s' expected a weight of 0 or more", w, category)
+		}
+		total += w
+	}
+	if total == 0 {
+		return fmt.Errorf("'categories' expected a weight greater than 0")
+	}
+	return nil
+}
+
+func validCategory(category string) bool {
+	for _, c := range categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package signinlogs
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Categories and Batch Size": {
+			c:           map[string]interface{}{"type": Name, "batch_size": 10, "categories": map[string]interface{}{"SignInLogs": 90, "NonInteractiveUserSignInLogs": 10}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'azure:signinlogs' accessing config",
+		},
+		"Invalid Batch Size": {
+			c:           map[string]interface{}{"type": Name, "batch_size": 0},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'batch_size' expected a value greater than 0 accessing config",
+		},
+		"Invalid Category": {
+			c:           map[string]interface{}{"type": Name, "categories": map[string]interface{}{"AuditLogs": 1}},
+			hasError:    true,
+			errorString: "'AuditLogs' is not a valid value for 'categories' expected 'SignInLogs, NonInteractiveUserSignInLogs, ServicePrincipalSignInLogs, ManagedIdentitySignInLogs' accessing config",
+		},
+		"Invalid Weight": {
+			c:           map[string]interface{}{"type": Name, "categories": map[string]interface{}{"SignInLogs": -1}},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'categories.SignInLogs' expected a weight of 0 or more accessing config",
+		},
+		"No Weight": {
+			c:           map[string]interface{}{"type": Name, "categories": map[string]interface{}{"SignInLogs": 0}},
+			hasError:    true,
+			errorString: "'categories' expected a weight greater than 0 accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			_, err = New(c)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
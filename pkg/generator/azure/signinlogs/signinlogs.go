@@ -0,0 +1,549 @@
+// Package signinlogs generates Microsoft Entra ID sign-in log records,
+// in the JSON envelope diagnostic settings stream them to an Event
+// Hub.
+//
+// Every event is an Event Hub message, an object with a "records"
+// array of batch_size records.  The records are of the SignInLogs,
+// NonInteractiveUserSignInLogs, ServicePrincipalSignInLogs and
+// ManagedIdentitySignInLogs categories.  The interactive and
+// non-interactive sign-ins are of a pool of users of the tenant, from
+// the same device and place for a user, to the applications of the
+// tenant, and mostly succeed with a few wrong passwords, MFA prompts
+// and blocks by conditional access.  The service principal and managed
+// identity sign-ins are of the applications and identities of the
+// tenant to the resources they use.  By default every category is as
+// likely, categories picks them with weights instead, the categories
+// without a weight are left out.  With entities the users are the
+// users of the shared population of package entities, from the address
+// of their host, mostly at work.
+//
+// Configuration:
+//
+//	categories: (map, optional) The weights of the categories.
+//	batch_size: (int, optional) The number of records of an event,
+//	            defaults to 1.
+//	entities:   (bool, optional) Draw the users from the shared
+//	            population.
+//
+//	- generator:
+//	    type: azure:signinlogs
+//	    batch_size: 20
+//	    categories:
+//	      SignInLogs: 40
+//	      NonInteractiveUserSignInLogs: 50
+//	      ServicePrincipalSignInLogs: 10
+package signinlogs
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/entities"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "azure:signinlogs"
+
+// Categories of the sign-in logs.
+const (
+	CategorySignIn              = "SignInLogs"
+	CategoryNonInteractive      = "NonInteractiveUserSignInLogs"
+	CategoryServicePrincipal    = "ServicePrincipalSignInLogs"
+	CategoryManagedIdentity     = "ManagedIdentitySignInLogs"
+	timeFmt                     = "2006-01-02T15:04:05.0000000Z"
+	createdFmt                  = "2006-01-02T15:04:05.0000000+00:00"
+	operationName               = "Sign-in activity"
+	operationVersion            = "1.0"
+	conditionalAccessSuccess    = "success"
+	conditionalAccessFailure    = "failure"
+	conditionalAccessNotApplied = "notApplied"
+	authenticationSingleFactor  = "singleFactorAuthentication"
+	authenticationMultiFactor   = "multiFactorAuthentication"
+)
+
+var (
+	categories = [...]string{CategorySignIn, CategoryNonInteractive, CategoryServicePrincipal, CategoryManagedIdentity}
+	firstNames = [...]string{"Adele", "Alex", "Diego", "Grady", "Isaiah", "Johanna", "Lee", "Lidia", "Lynne", "Megan", "Miriam", "Nestor", "Patti", "Pradeep"}
+	lastNames  = [...]string{"Vance", "Wilber", "Siciliani", "Archie", "Langer", "Lauer", "Gu", "Holloway", "Robbins", "Bowen", "Graham", "Wilke", "Fernandez", "Gupta"}
+	// apps are the applications users sign in to, with the resources
+	// they get tokens for.
+	apps = [...]struct {
+		id, name, resourceID, resource string
+	}{
+		{"00000002-0000-0ff1-ce00-000000000000", "Office 365 Exchange Online", "00000002-0000-0ff1-ce00-000000000000", "Office 365 Exchange Online"},
+		{"1fec8e78-bce4-4aaf-ab1b-5451cc387264", "Microsoft Teams", "cc15fd57-2c6c-4117-a88c-83b1d56b4bbe", "Microsoft Teams Services"},
+		{"d3590ed6-52b3-4102-aeff-aad2292ab01c", "Microsoft Office", "00000003-0000-0ff1-ce00-000000000000", "Office 365 SharePoint Online"},
+		{"c44b4083-3bb0-49c1-b47d-974e53cbdf3c", "Azure Portal", "797f4846-ba00-4fd7-ba43-dac1f8f63013", "Windows Azure Service Management API"},
+		{"00000003-0000-0000-c000-000000000000", "Microsoft Graph", "00000003-0000-0000-c000-000000000000", "Microsoft Graph"},
+		{"4765445b-32c6-49b0-83e6-1d93765276ca", "OfficeHome", "4765445b-32c6-49b0-83e6-1d93765276ca", "OfficeHome"},
+	}
+	// servicePrincipals are the applications of the tenant that sign
+	// in with their own credentials.
+	servicePrincipals = [...]string{"backup-automation", "ci-deploy", "crm-sync", "monitoring-exporter", "hr-provisioning"}
+	// managedIdentities are the Azure resources with a managed
+	// identity.
+	managedIdentities = [...]string{"func-orders-prod", "vm-web-01", "app-portal-prod", "aks-prod-agentpool"}
+	resources         = [...]struct {
+		id, name string
+	}{
+		{"00000003-0000-0000-c000-000000000000", "Microsoft Graph"},
+		{"cfa8b339-82a2-471a-a3c9-0fc0be7a4093", "Azure Key Vault"},
+		{"e406a681-f3d4-42a8-90b6-c2b029497af1", "Azure Storage"},
+		{"797f4846-ba00-4fd7-ba43-dac1f8f63013", "Windows Azure Service Management API"},
+	}
+	// results are the results of user sign-ins, from the most common,
+	// with their weights.
+	results = [...]struct {
+		code, reason string
+		weight       int
+	}{
+		{"0", "", 85},
+		{"50126", "Error validating credentials due to invalid username or password.", 5},
+		{"50074", "Strong Authentication is required.", 4},
+		{"53003", "Access has been blocked by Conditional Access policies. The access policy does not allow token issuance.", 2},
+		{"50140", "This error occurred due to 'Keep me signed in' interrupt when the user was signing in.", 2},
+		{"50053", "Account is locked because user tried to sign in too many times with an incorrect user ID or password.", 1},
+		{"700016", "Application with identifier was not found in the directory.", 1},
+	}
+	places = [...]struct {
+		city, state, country string
+		lat, lon             float64
+	}{
+		{"Amsterdam", "Noord-Holland", "NL", 52.37, 4.89},
+		{"Utrecht", "Utrecht", "NL", 52.09, 5.12},
+		{"London", "England", "GB", 51.51, -0.13},
+		{"Seattle", "Washington", "US", 47.61, -122.33},
+		{"New York", "New York", "US", 40.71, -74.01},
+		{"Berlin", "Berlin", "DE", 52.52, 13.40},
+	}
+	devices = [...]struct {
+		os, browser, clientApp string
+	}{
+		{"Windows10", "Edge 122.0.0", "Browser"},
+		{"Windows10", "Chrome 122.0.0", "Browser"},
+		{"MacOs", "Safari 17.3", "Browser"},
+		{"Windows10", "Rich Client 16.0.17328", "Mobile Apps and Desktop clients"},
+		{"Ios 17.3", "Mobile Safari 17.3", "Mobile Apps and Desktop clients"},
+		{"Android 14", "Chrome Mobile 122.0.0", "Mobile Apps and Desktop clients"},
+	}
+)
+
+// Status is the result of a sign-in.
+type Status struct {
+	ErrorCode     int    `json:"errorCode"`
+	FailureReason string `json:"failureReason,omitempty"`
+}
+
+// DeviceDetail is the device of a sign-in.
+type DeviceDetail struct {
+	DeviceID        string `json:"deviceId"`
+	DisplayName     string `json:"displayName,omitempty"`
+	OperatingSystem string `json:"operatingSystem,omitempty"`
+	Browser         string `json:"browser,omitempty"`
+	IsCompliant     bool   `json:"isCompliant"`
+	IsManaged       bool   `json:"isManaged"`
+	TrustType       string `json:"trustType,omitempty"`
+}
+
+// GeoCoordinates are the coordinates of a location.
+type GeoCoordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Location is the place of a sign-in.
+type Location struct {
+	City            string         `json:"city"`
+	State           string         `json:"state"`
+	CountryOrRegion string         `json:"countryOrRegion"`
+	GeoCoordinates  GeoCoordinates `json:"geoCoordinates"`
+}
+
+// AuthenticationDetail is a step of the authentication of a sign-in.
+type AuthenticationDetail struct {
+	AuthenticationStepDateTime    string `json:"authenticationStepDateTime"`
+	AuthenticationMethod          string `json:"authenticationMethod"`
+	Succeeded                     bool   `json:"succeeded"`
+	AuthenticationStepRequirement string `json:"authenticationStepRequirement"`
+}
+
+// Properties are the properties of a sign-in record.
+type Properties struct {
+	ID                        string                 `json:"id"`
+	CreatedDateTime           string                 `json:"createdDateTime"`
+	UserDisplayName           string                 `json:"userDisplayName,omitempty"`
+	UserPrincipalName         string                 `json:"userPrincipalName,omitempty"`
+	UserID                    string                 `json:"userId,omitempty"`
+	ServicePrincipalID        string                 `json:"servicePrincipalId,omitempty"`
+	ServicePrincipalName      string                 `json:"servicePrincipalName,omitempty"`
+	AppID                     string                 `json:"appId"`
+	AppDisplayName            string                 `json:"appDisplayName"`
+	IPAddress                 string                 `json:"ipAddress"`
+	ClientAppUsed             string                 `json:"clientAppUsed,omitempty"`
+	UserAgent                 string                 `json:"userAgent,omitempty"`
+	CorrelationID             string                 `json:"correlationId"`
+	ConditionalAccessStatus   string                 `json:"conditionalAccessStatus"`
+	IsInteractive             bool                   `json:"isInteractive"`
+	AuthenticationRequirement string                 `json:"authenticationRequirement,omitempty"`
+	TokenIssuerType           string                 `json:"tokenIssuerType"`
+	RiskDetail                string                 `json:"riskDetail"`
+	RiskLevelAggregated       string                 `json:"riskLevelAggregated"`
+	RiskLevelDuringSignIn     string                 `json:"riskLevelDuringSignIn"`
+	RiskState                 string                 `json:"riskState"`
+	ResourceDisplayName       string                 `json:"resourceDisplayName"`
+	ResourceID                string                 `json:"resourceId"`
+	Status                    Status                 `json:"status"`
+	DeviceDetail              *DeviceDetail          `json:"deviceDetail,omitempty"`
+	Location                  *Location              `json:"location,omitempty"`
+	AuthenticationDetails     []AuthenticationDetail `json:"authenticationDetails,omitempty"`
+	ManagedIdentityType       string                 `json:"managedIdentityType,omitempty"`
+}
+
+// Record is a sign-in log record.
+type Record struct {
+	Time              string     `json:"time"`
+	ResourceID        string     `json:"resourceId"`
+	OperationName     string     `json:"operationName"`
+	OperationVersion  string     `json:"operationVersion"`
+	Category          string     `json:"category"`
+	TenantID          string     `json:"tenantId"`
+	ResultType        string     `json:"resultType"`
+	ResultSignature   string     `json:"resultSignature"`
+	ResultDescription string     `json:"resultDescription,omitempty"`
+	DurationMs        int        `json:"durationMs"`
+	CallerIPAddress   string     `json:"callerIpAddress"`
+	CorrelationID     string     `json:"correlationId"`
+	Identity          string     `json:"identity"`
+	Level             int        `json:"Level"`
+	Location          string     `json:"location"`
+	Properties        Properties `json:"properties"`
+}
+
+// user is a user of the tenant, who signs in from the same device and
+// place.
+type user struct {
+	upn, name, id string
+	ip            net.IP
+	device        int
+	deviceID      string
+	place         int
+	agent         string
+}
+
+// identity is a service principal or managed identity of the tenant.
+type identity struct {
+	name, id, appID string
+	ip              net.IP
+}
+
+// SignInLogs holds the state of the sign-in log generator.
+type SignInLogs struct {
+	Record Record
+
+	rand       *rand.Rand
+	clock      *generator.Clock
+	pins       *generator.Pins
+	entities   *entities.Population
+	weights    []float64
+	total      float64
+	batch      int
+	tenant     string
+	users      []user
+	ids        map[string]user
+	principals []identity
+	managed    []identity
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for SignInLogs objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SignInLogs{rand: r, clock: clock, batch: c.BatchSize, tenant: random.UUID(r)}
+	s.pins, err = generator.NewPins(cfg, r, &s.Record)
+	if err != nil {
+		return nil, err
+	}
+	s.entities, err = entities.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.entities != nil {
+		// The users of the population keep their ids and devices for
+		// every record.
+		s.ids = make(map[string]user, len(s.entities.Users))
+		for _, u := range s.entities.Users {
+			s.ids[u.Name] = s.user(u.Email, u.DisplayName, s.entities.HostOf(u).IP)
+		}
+	}
+	for i := range firstNames {
+		upn := strings.ToLower(firstNames[i]+"."+lastNames[i]) + "@contoso.onmicrosoft.com"
+		s.users = append(s.users, s.user(upn, firstNames[i]+" "+lastNames[i], random.IPv4(r)))
+	}
+	for _, name := range servicePrincipals {
+		s.principals = append(s.principals, identity{name: name, id: random.UUID(r), appID: random.UUID(r), ip: random.IPv4(r)})
+	}
+	for _, name := range managedIdentities {
+		s.managed = append(s.managed, identity{name: name, id: random.UUID(r), appID: random.UUID(r), ip: random.IPv4(r)})
+	}
+	if c.Categories != nil {
+		for _, category := range categories {
+			s.weights = append(s.weights, c.Categories[category])
+			s.total += c.Categories[category]
+		}
+	}
+	return s, nil
+}
+
+func (s *SignInLogs) user(upn, name string, ip net.IP) user {
+	return user{
+		upn:      upn,
+		name:     name,
+		id:       random.UUID(s.rand),
+		ip:       ip,
+		device:   s.rand.IntN(len(devices)),
+		deviceID: random.UUID(s.rand),
+		place:    s.rand.IntN(len(places)),
+		agent:    random.UserAgent(s.rand),
+	}
+}
+
+// envelope is the body of an Event Hub message of diagnostic settings.
+type envelope struct {
+	Records []Record `json:"records"`
+}
+
+// Next produces the next Event Hub message.
+//
+// Example:
+//
+// {"records":[{"time":"2024-03-04T12:00:00.0000000Z","resourceId":"/tenants/6a1f.../providers/Microsoft.aadiam","operationName":"Sign-in activity","operationVersion":"1.0","category":"SignInLogs","resultType":"0",...,"properties":{"userPrincipalName":"adele.vance@contoso.onmicrosoft.com",...}}]}
+func (s *SignInLogs) Next() ([]byte, error) {
+	records := make([]Record, s.batch)
+	for i := range records {
+		s.generate(s.clock.Now())
+		s.pins.Apply(&s.Record)
+		records[i] = s.Record
+	}
+	return json.Marshal(envelope{Records: records})
+}
+
+// category returns the category of the next sign-in, picked with the
+// weights of the categories when they are set.
+func (s *SignInLogs) category() string {
+	if s.weights == nil {
+		return categories[s.rand.IntN(len(categories))]
+	}
+	n := s.rand.Float64() * s.total
+	for i, w := range s.weights {
+		if n < w {
+			return categories[i]
+		}
+		n -= w
+	}
+	// n can only be left over by rounding, the last category with a
+	// weight is the pick.
+	for i := len(s.weights) - 1; ; i-- {
+		if s.weights[i] > 0 {
+			return categories[i]
+		}
+	}
+}
+
+// pick returns a random user, a user of the shared population at work
+// at now with entities.
+func (s *SignInLogs) pick(now time.Time) user {
+	if s.entities != nil {
+		return s.ids[s.entities.ActiveUser(s.rand, now).Name]
+	}
+	return s.users[s.rand.IntN(len(s.users))]
+}
+
+// generate sets Record to a sign-in of a random category at now.
+func (s *SignInLogs) generate(now time.Time) {
+	category := s.category()
+	correlation := random.UUID(s.rand)
+	s.Record = Record{
+		Time:             s.clock.Format(now.UTC(), timeFmt),
+		ResourceID:       "/tenants/" + s.tenant + "/providers/Microsoft.aadiam",
+		OperationName:    operationName,
+		OperationVersion: operationVersion,
+		Category:         category,
+		TenantID:         s.tenant,
+		ResultSignature:  "None",
+		CorrelationID:    correlation,
+		Level:            4,
+		Properties: Properties{
+			ID:                    random.UUID(s.rand),
+			CreatedDateTime:       now.UTC().Format(createdFmt),
+			CorrelationID:         correlation,
+			TokenIssuerType:       "AzureAD",
+			RiskDetail:            "none",
+			RiskLevelAggregated:   "none",
+			RiskLevelDuringSignIn: "none",
+			RiskState:             "none",
+		},
+	}
+	switch category {
+	case CategorySignIn, CategoryNonInteractive:
+		s.userSignIn(category == CategorySignIn, now)
+	case CategoryServicePrincipal:
+		s.servicePrincipalSignIn(s.principals[s.rand.IntN(len(s.principals))])
+	case CategoryManagedIdentity:
+		s.servicePrincipalSignIn(s.managed[s.rand.IntN(len(s.managed))])
+		s.Record.Properties.ManagedIdentityType = "SystemAssigned"
+	}
+}
+
+// result returns a random result of a user sign-in.
+func (s *SignInLogs) result() (string, string) {
+	n := s.rand.IntN(100)
+	for _, r := range results {
+		if n < r.weight {
+			return r.code, r.reason
+		}
+		n -= r.weight
+	}
+	return results[0].code, results[0].reason
+}
+
+// userSignIn sets Record to an interactive or non-interactive sign-in
+// of a user to an application.
+func (s *SignInLogs) userSignIn(interactive bool, now time.Time) {
+	u := s.pick(now)
+	app := apps[s.rand.IntN(len(apps))]
+	device := devices[u.device]
+	place := places[u.place]
+	code, reason := s.result()
+	if !interactive && code == "50074" {
+		// A non-interactive sign-in can not prompt for MFA.
+		code, reason = "0", ""
+	}
+
+	r := &s.Record
+	r.ResultType = code
+	r.ResultDescription = reason
+	r.CallerIPAddress = u.ip.String()
+	r.Identity = u.name
+	r.Location = place.country
+	if code != "0" {
+		r.Level = 2
+	}
+
+	p := &r.Properties
+	p.UserDisplayName = u.name
+	p.UserPrincipalName = u.upn
+	p.UserID = u.id
+	p.AppID = app.id
+	p.AppDisplayName = app.name
+	p.ResourceID = app.resourceID
+	p.ResourceDisplayName = app.resource
+	p.IPAddress = u.ip.String()
+	p.ClientAppUsed = device.clientApp
+	p.UserAgent = u.agent
+	p.IsInteractive = interactive
+	p.Status = Status{ErrorCode: atoi(code), FailureReason: reason}
+	p.DeviceDetail = &DeviceDetail{
+		DeviceID:        u.deviceID,
+		DisplayName:     strings.ToUpper(strings.SplitN(u.upn, "@", 2)[0]) + "-PC",
+		OperatingSystem: device.os,
+		Browser:         device.browser,
+		IsCompliant:     true,
+		IsManaged:       true,
+		TrustType:       "Azure AD joined",
+	}
+	p.Location = &Location{
+		City:            place.city,
+		State:           place.state,
+		CountryOrRegion: place.country,
+		GeoCoordinates:  GeoCoordinates{Latitude: place.lat, Longitude: place.lon},
+	}
+
+	p.AuthenticationRequirement = authenticationSingleFactor
+	p.ConditionalAccessStatus = conditionalAccessSuccess
+	step := now.UTC().Format(time.RFC3339)
+	switch code {
+	case "0":
+		if interactive {
+			p.AuthenticationDetails = []AuthenticationDetail{{step, "Password", true, "Primary authentication"}}
+			if s.rand.IntN(3) == 0 {
+				p.AuthenticationRequirement = authenticationMultiFactor
+				p.AuthenticationDetails = append(p.AuthenticationDetails, AuthenticationDetail{step, "Mobile app notification", true, "Secondary authentication"})
+			}
+		} else {
+			p.AuthenticationDetails = []AuthenticationDetail{{step, "Previously satisfied", true, "Primary authentication"}}
+		}
+	case "50126", "50053":
+		p.ConditionalAccessStatus = conditionalAccessNotApplied
+		p.AuthenticationDetails = []AuthenticationDetail{{step, "Password", false, "Primary authentication"}}
+		if s.rand.IntN(4) == 0 {
+			// A wrong password is now and then a risky sign-in.
+			p.RiskDetail, p.RiskLevelAggregated, p.RiskLevelDuringSignIn, p.RiskState = "none", "medium", "medium", "atRisk"
+		}
+	case "50074":
+		p.AuthenticationRequirement = authenticationMultiFactor
+		p.AuthenticationDetails = []AuthenticationDetail{{step, "Password", true, "Primary authentication"}}
+	case "53003":
+		p.ConditionalAccessStatus = conditionalAccessFailure
+	default:
+		p.ConditionalAccessStatus = conditionalAccessNotApplied
+	}
+}
+
+// servicePrincipalSignIn sets Record to a sign-in of a service
+// principal or managed identity to a resource.
+func (s *SignInLogs) servicePrincipalSignIn(id identity) {
+	res := resources[s.rand.IntN(len(resources))]
+	code, reason := "0", ""
+	if s.rand.IntN(50) == 0 {
+		code, reason = "7000215", "Invalid client secret is provided."
+	}
+
+	r := &s.Record
+	r.ResultType = code
+	r.ResultDescription = reason
+	r.CallerIPAddress = id.ip.String()
+	r.Identity = id.name
+	if code != "0" {
+		r.Level = 2
+	}
+
+	p := &r.Properties
+	p.ServicePrincipalID = id.id
+	p.ServicePrincipalName = id.name
+	p.AppID = id.appID
+	p.AppDisplayName = id.name
+	p.ResourceID = res.id
+	p.ResourceDisplayName = res.name
+	p.IPAddress = id.ip.String()
+	p.ConditionalAccessStatus = conditionalAccessNotApplied
+	p.Status = Status{ErrorCode: atoi(code), FailureReason: reason}
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
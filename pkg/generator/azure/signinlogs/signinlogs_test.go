@@ -0,0 +1,88 @@
+package signinlogs
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func next(t *testing.T, c map[string]interface{}, n int) []Record {
+	t.Helper()
+	cfg, err := ucfg.NewFrom(c)
+	assert.Nil(t, err)
+	g, err := New(cfg)
+	assert.Nil(t, err)
+	var records []Record
+	for i := 0; i < n; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		var e struct {
+			Records []Record `json:"records"`
+		}
+		if assert.Nil(t, json.Unmarshal(got, &e), string(got)) {
+			records = append(records, e.Records...)
+		}
+	}
+	return records
+}
+
+func TestNext(t *testing.T) {
+	records := next(t, map[string]interface{}{"type": Name, "seed": 1}, 1000)
+	assert.Len(t, records, 1000)
+
+	seen := map[string]int{}
+	devices := map[string]string{}
+	failed := 0
+	for _, r := range records {
+		seen[r.Category]++
+		_, err := time.Parse(timeFmt, r.Time)
+		assert.Nil(t, err)
+		assert.Equal(t, "/tenants/"+r.TenantID+"/providers/Microsoft.aadiam", r.ResourceID)
+		assert.Equal(t, operationName, r.OperationName)
+		assert.Equal(t, r.CorrelationID, r.Properties.CorrelationID)
+		assert.Equal(t, r.CallerIPAddress, r.Properties.IPAddress)
+		assert.Equal(t, r.ResultType == "0", r.Properties.Status.ErrorCode == 0)
+		if r.ResultType != "0" {
+			failed++
+		}
+
+		switch r.Category {
+		case CategorySignIn, CategoryNonInteractive:
+			assert.Equal(t, r.Category == CategorySignIn, r.Properties.IsInteractive)
+			assert.NotEmpty(t, r.Properties.UserPrincipalName)
+			assert.Empty(t, r.Properties.ServicePrincipalID)
+			// A user signs in from the same device.
+			if id, ok := devices[r.Properties.UserID]; ok {
+				assert.Equal(t, id, r.Properties.DeviceDetail.DeviceID)
+			}
+			devices[r.Properties.UserID] = r.Properties.DeviceDetail.DeviceID
+			assert.Equal(t, r.Location, r.Properties.Location.CountryOrRegion)
+		case CategoryServicePrincipal, CategoryManagedIdentity:
+			assert.False(t, r.Properties.IsInteractive)
+			assert.Empty(t, r.Properties.UserPrincipalName)
+			assert.NotEmpty(t, r.Properties.ServicePrincipalID)
+			assert.Equal(t, r.Category == CategoryManagedIdentity, r.Properties.ManagedIdentityType != "")
+		}
+	}
+	assert.Len(t, seen, len(categories))
+	assert.Greater(t, failed, 0)
+	assert.Less(t, failed, 200)
+}
+
+func TestBatchSize(t *testing.T) {
+	records := next(t, map[string]interface{}{"type": Name, "batch_size": 25}, 4)
+	assert.Len(t, records, 100)
+}
+
+func TestCategories(t *testing.T) {
+	records := next(t, map[string]interface{}{"type": Name, "categories": map[string]interface{}{"SignInLogs": 1, "ManagedIdentitySignInLogs": 3}}, 1000)
+	seen := map[string]int{}
+	for _, r := range records {
+		seen[r.Category]++
+	}
+	assert.Len(t, seen, 2)
+	assert.InDelta(t, 750, seen[CategoryManagedIdentity], 75)
+}
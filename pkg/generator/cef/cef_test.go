@@ -1,12 +1,14 @@
 package cef
 
 import (
-	"math/rand"
+	"regexp"
 	"testing"
 	"text/template"
 	"time"
 
+	"github.com/elastic/go-ucfg"
 	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
 )
 
 func TestNext(t *testing.T) {
@@ -14,11 +16,11 @@ func TestNext(t *testing.T) {
 		seed int64
 		want string
 	}{
-		{seed: 1, want: `CEF:0|Check Point|DNS Trace Log|NS11.0|APPFW|APPFW_SIGNATURE_MATCH|6|dvc=81.37.33.15 c6a4=ef1:c33b:0:f8:c93:8200:3:4ad2 C6a4Label=ring deviceNtDomain=boy oldFileHash=96be6fb77970466a5626fe33408cf9e8 destinationTranslatedZoneURI=https://huge.end.com/share/slip flexDate1=1273775460729 requestMethod=PUT`},
-		{seed: 3, want: `CEF:0|Check Point|NetScalar|NS10.0|APPFW|APPFW_STARTURL|7|agentZoneExternalID=00000000-0000-4000-8000-000000000000 dvcpid=15874 slong=63.95873668457833 agentDnsDomain=behave.boy.com flexNumber2Label=preach flexNumber2=92827 rawEvent=Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat. Duis aute irure dolor in reprehenderit in voluptate velit esse cillum dolore eu fugiat nulla pariatur. Excepteur sint occaecat cupidatat non proident, sunt in culpa qui officia deserunt mollit anim id est laborum. requestCookies=00000000-0000-4000-8000-000000000000 duser=eve`},
-		{seed: 4, want: `CEF:0|Check Point|DNS Trace Log|NS11.0|APPFW|APPFW_SAFECOMMERCE_XFORM|5|destinationTranslatedPort=31726 destinationDnsDomain=house.identify.co rt=1273775720347 outcome=failure start=1273773883151 deviceDnsDomain=rely.futuristic.co`},
+		{seed: 1, want: `CEF:0|Check Point|NetScalar|NS10.0|APPFW|APPFW_FIELDCONSISTENCY|2|C6a4Label=pine cs4=cars cs4Label=tiny deviceTranslatedZoneURI=http://radiate.lunchroom.com/average/spoon request=http://class.fix.com/identify/obsequious oldFileType=directory c6a4=535b:d700:c3e9:52fd:db:7f:7507:1713 deviceZoneExternalID=00000000-0000-4000-8000-000000000000 oldFileCreateTime=1273775649926`},
+		{seed: 3, want: `CEF:0|Check Point|NetScalar|NS11.0|APPFW|APPFW_SAFECOMMERCE|6|deviceTranslatedZoneURI=https://great.dramatic.org/march?payment requestContext=http://behave.encouraging.org/stranger?soak end=1273776743353 app=http at=local cn1=772 agentTranslatedZoneExternalID=00000000-0000-4000-8000-000000000000 oldFileHash=f1d3541f9c796f8a6d9ac8915ab6c156`},
+		{seed: 4, want: `CEF:0|Check Point|DNS Trace Log|NS11.0|APPFW|APPFW_SIGNATURE_MATCH|1|cs3Label=bulb cs3=thinkable eventId=80871 DeviceOutboundInterface=eth1 cs6=vagabond flexNumber1Label=seat rawEvent=Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat. Duis aute irure dolor in reprehenderit in voluptate velit esse cillum dolore eu fugiat nulla pariatur. Excepteur sint occaecat cupidatat non proident, sunt in culpa qui officia deserunt mollit anim id est laborum. duid=alice flexNumber1=50937`},
 	}
-	templ, err := template.New("cef").Funcs(generator.FunctionMap).Parse(tmpl)
+	templ, err := generator.NewTemplate("cef", tmpl)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -37,7 +39,7 @@ func TestNext(t *testing.T) {
 	}}
 	c.config.Validate() // Populate the remaining fields with the defaults.
 	for _, test := range tests {
-		rand.Seed(test.seed)
+		c.rand = random.NewRand(&test.seed)
 		c.Max = 10
 		c.randomize()
 		got, err := c.Next()
@@ -50,6 +52,41 @@ func TestNext(t *testing.T) {
 	}
 }
 
+func TestHeader(t *testing.T) {
+	tests := map[string]struct {
+		header string
+		want   *regexp.Regexp
+	}{
+		"none":    {header: "none", want: regexp.MustCompile(`^CEF:0\|`)},
+		"rfc3164": {header: "rfc3164", want: regexp.MustCompile(`^<1[0-4]>[A-Z][a-z]{2} [ 1-3]\d \d{2}:\d{2}:\d{2} \d+\.\d+\.\d+\.\d+ CEF:0\|`)},
+		"rfc5424": {header: "rfc5424", want: regexp.MustCompile(`^<1[0-4]>1 \d{4}-\d{2}-\d{2}T\S+ \d+\.\d+\.\d+\.\d+ CEF - - CEF:0\|`)},
+	}
+	for name, tc := range tests {
+		g, err := New(ucfg.MustNewFrom(map[string]interface{}{
+			"type":     Name,
+			"seed":     1,
+			"header":   tc.header,
+			"vendors":  vendors,
+			"products": products,
+			"versions": versions,
+			"classes":  classes,
+			"names":    names,
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 10; i++ {
+			got, err := g.Next()
+			if err != nil {
+				t.Fatalf("unexpected error for %s: %v", name, err)
+			}
+			if !tc.want.Match(got) {
+				t.Errorf("unexpected header for %s: %s", name, got)
+			}
+		}
+	}
+}
+
 var (
 	vendors = []string{
 		"Check Point",
@@ -69,6 +69,9 @@ func (c *config) Validate() error {
 	if len(c.Interfaces) == 0 {
 		c.Interfaces = interfaces
 	}
+	if len(c.TimeZones) == 0 {
+		c.TimeZones = timeZones
+	}
 	if len(c.Actions) == 0 {
 		c.Actions = actions
 	}
@@ -61,6 +61,18 @@ func TestConfigs(t *testing.T) {
 			hasError:    false,
 			errorString: "",
 		},
+		"Invalid Header": {
+			c: map[string]interface{}{
+				"type": Name, "header": "rfc3339",
+				"vendors":  []string{"foo"},
+				"products": []string{"foo"},
+				"versions": []string{"foo"},
+				"classes":  []string{"foo"},
+				"names":    []string{"foo"},
+			},
+			hasError:    true,
+			errorString: "'rfc3339' is not a valid value for 'header' expected 'none, rfc3164, rfc5424' accessing config",
+		},
 	}
 	for name, tc := range tests {
 		c, err := ucfg.NewFrom(tc.c)
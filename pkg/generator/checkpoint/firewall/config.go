@@ -0,0 +1,47 @@
+package firewall
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Type       string   `config:"type" validate:"required"`
+	Blade      string   `config:"blade"`
+	Gateways   []string `config:"gateways"`
+	Management string   `config:"management"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:       Name,
+		Management: "mgmt.example.com",
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if c.Blade != "" && !contains(blades[:], c.Blade) {
+		return fmt.Errorf("'%s' is not a valid value for 'blade' expected '%s'", c.Blade, strings.Join(blades[:], ", "))
+	}
+	for _, g := range c.Gateways {
+		if g == "" || strings.ContainsAny(g, " \"") {
+			return fmt.Errorf("'%s' is not a valid value for 'gateways' expected a name without spaces or quotes", g)
+		}
+	}
+	if c.Management == "" {
+		return fmt.Errorf("'management' can not be empty")
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package firewall
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Pools": {
+			c:           map[string]interface{}{"type": Name, "blade": "vpn", "gateways": []string{"gw-amsterdam", "gw-utrecht"}, "management": "smc.example.org"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'checkpoint:firewall' accessing config",
+		},
+		"Invalid Blade": {
+			c:           map[string]interface{}{"type": Name, "blade": "ips"},
+			hasError:    true,
+			errorString: "'ips' is not a valid value for 'blade' expected 'firewall, application_control, url_filtering, vpn' accessing config",
+		},
+		"Invalid Gateway": {
+			c:           map[string]interface{}{"type": Name, "gateways": []string{"gw hq"}},
+			hasError:    true,
+			errorString: "'gw hq' is not a valid value for 'gateways' expected a name without spaces or quotes accessing config",
+		},
+		"Empty Management": {
+			c:           map[string]interface{}{"type": Name, "management": ""},
+			hasError:    true,
+			errorString: "'management' can not be empty accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			_, err = New(c)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
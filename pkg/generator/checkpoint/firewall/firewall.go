@@ -0,0 +1,445 @@
+// Package firewall generates Check Point firewall logs as they are
+// sent by the Log Exporter.
+//
+// The logs are in the syslog format of the Log Exporter, an RFC 5424
+// header with the fields of the log as key:"value" pairs separated by
+// semicolons.  The logs of the Firewall, Application Control and URL
+// Filtering blades are the connections they accept, drop or reject,
+// those of the VPN blade the IKE key exchanges of the site to site
+// communities and the connections it encrypts and decrypts.  Every
+// gateway of the gateways pool is the origin of its logs, with a LAN
+// of its own and the SIC name of the management server.
+//
+// Configuration:
+//
+//	blade:      Specify the blade of the logs, or leave blank for random.
+//	            Valid values are: firewall, application_control,
+//	            url_filtering, vpn.
+//	gateways:   The names of the gateways, defaults to gw-hq and
+//	            gw-branch.
+//	management: The name of the management server in the SIC names,
+//	            defaults to mgmt.example.com.
+//
+//	- generator:
+//	    type: checkpoint:firewall
+//	    gateways: [gw-amsterdam, gw-utrecht]
+package firewall
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/url"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "checkpoint:firewall"
+
+// Blades of the logs.
+const (
+	BladeFirewall           = "firewall"
+	BladeApplicationControl = "application_control"
+	BladeURLFiltering       = "url_filtering"
+	BladeVPN                = "vpn"
+)
+
+const (
+	actionAccept = "Accept"
+	actionDrop   = "Drop"
+	actionReject = "Reject"
+)
+
+var (
+	blades          = [...]string{BladeFirewall, BladeApplicationControl, BladeURLFiltering, BladeVPN}
+	defaultGateways = []string{"gw-hq", "gw-branch"}
+	products        = map[string]string{
+		BladeFirewall:           "VPN-1 & FireWall-1",
+		BladeApplicationControl: "Application Control",
+		BladeURLFiltering:       "URL Filtering",
+		BladeVPN:                "VPN-1 & FireWall-1",
+	}
+	header = `{{template "syslog5424_header" (dict "Priority" 134 "Timestamp" .Timestamp "Layout" .Layout "Host" .Gateway "AppName" "CheckPoint" "ProcID" .PID)}}[action:"{{.Action}}"; `
+	origin = `flags:"{{.Flags}}"; ifdir:"{{.IfDir}}"; ifname:"{{.IfName}}"; logid:"0"; loguid:"{{.LogUID}}"; origin:"{{.Origin}}"; originsicname:"{{.OriginSICName}}"; sequencenum:"{{.Sequence}}"; time:"{{.Timestamp.Unix}}"; version:"5"; `
+	conn   = `dst:"{{.DstAddr}}"; inzone:"{{.InZone}}"; outzone:"{{.OutZone}}"; product:"{{.Product}}"; proto:"{{.Proto}}"; s_port:"{{.SrcPort}}"; service:"{{.DstPort}}"; service_id:"{{.Service}}"; src:"{{.SrcAddr}}"; `
+	rule   = `layer_name:"{{.LayerName}}"; layer_uuid:"{{.LayerUUID}}"; match_id:"{{.MatchID}}"; parent_rule:"0"; rule_action:"{{.Action}}"; rule_name:"{{.RuleName}}"; rule_uid:"{{.RuleUID}}"`
+	// templates are the logs of each blade, the logs of the VPN blade
+	// with their action.
+	templates = [...]struct {
+		blade, action, text string
+	}{
+		{BladeFirewall, "", header + origin + conn + rule + `{{if eq .Action "Reject"}}; reject_id:"{{.RejectID}}"{{end}}]`},
+		{BladeApplicationControl, "", header + origin + conn + `app_category:"{{.AppCategory}}"; app_id:"{{.AppID}}"; app_risk:"{{.AppRisk}}"; appi_name:"{{.App}}"; matched_category:"{{.AppCategory}}"; ` + rule + `]`},
+		{BladeURLFiltering, "", header + origin + conn + `app_category:"{{.URLCategory}}"; appi_name:"{{.Host}}"; matched_category:"{{.URLCategory}}"; resource:"{{.Resource}}"; user_agent:"{{.UserAgent}}"; web_client_type:"{{.WebClient}}"; ` + rule + `]`},
+		{BladeVPN, "Key Install", header + origin + `community:"{{.Community}}"; fw_subproduct:"VPN-1"; ike:"{{.IKE}}"; methods:"{{.Methods}}"; peer_gateway:"{{.PeerGateway}}"; product:"{{.Product}}"; scheme:"IKE"; vpn_feature_name:"IKE"]`},
+		{BladeVPN, "Encrypt", header + origin + conn + `community:"{{.Community}}"; fw_subproduct:"VPN-1"; methods:"{{.Methods}}"; peer_gateway:"{{.PeerGateway}}"; scheme:"IKE"; vpn_feature_name:"VPN"]`},
+		{BladeVPN, "Decrypt", header + origin + conn + `community:"{{.Community}}"; fw_subproduct:"VPN-1"; methods:"{{.Methods}}"; peer_gateway:"{{.PeerGateway}}"; scheme:"IKE"; vpn_feature_name:"VPN"]`},
+	}
+	// rules are the rules of the access layers in the order of their
+	// match_id, weighted by how often they match, with the ports of the
+	// services they match.
+	rules = map[string][]accessRule{
+		BladeFirewall: {
+			{"Allow DNS", actionAccept, 20, []int{53}},
+			{"Allow Web", actionAccept, 40, []int{80, 443}},
+			{"Block SMB from Internet", actionDrop, 10, []int{445}},
+			{"Reject Telnet", actionReject, 5, []int{23}},
+			{"Stealth rule", actionDrop, 10, nil},
+			{"Cleanup rule", actionDrop, 15, nil},
+		},
+		BladeApplicationControl: {
+			{"Block High Risk Applications", actionDrop, 1, nil},
+			{"Allow Business Applications", actionAccept, 4, nil},
+		},
+		BladeURLFiltering: {
+			{"Block Malicious Sites", actionDrop, 1, nil},
+			{"Allow Web Browsing", actionAccept, 4, nil},
+		},
+	}
+	services = [...]struct {
+		port, proto int
+		id          string
+	}{
+		{53, 17, "domain-udp"},
+		{443, 6, "https"},
+		{443, 6, "https"},
+		{80, 6, "http"},
+		{22, 6, "ssh"},
+		{445, 6, "microsoft-ds"},
+		{23, 6, "telnet"},
+		{123, 17, "ntp-udp"},
+		{3389, 6, "Remote_Desktop_Protocol"},
+	}
+	applications = [...]struct {
+		name, category string
+		id, risk       int
+	}{
+		{"Facebook", "Social Networking", 60340822, 3},
+		{"YouTube", "Media Streams", 60340313, 2},
+		{"Microsoft Teams", "Instant Messaging", 60971218, 1},
+		{"Dropbox", "File Storage and Sharing", 60338056, 3},
+		{"TeamViewer", "Remote Administration", 60340124, 4},
+		{"BitTorrent", "P2P File Sharing", 60340236, 5},
+		{"Tor", "Anonymizer", 60340351, 5},
+	}
+	categories = [...]struct {
+		name    string
+		blocked bool
+	}{
+		{"Search Engines / Portals", false},
+		{"Business / Economy", false},
+		{"News / Media", false},
+		{"Computers / Internet", false},
+		{"Gambling", true},
+		{"Phishing", true},
+		{"Botnets", true},
+	}
+	webClients  = [...]string{"Chrome", "Firefox", "Edge", "Safari"}
+	flags       = [...]string{"411908", "7263232", "133376", "2359552"}
+	communities = [...]string{"MyIntranet", "Branches"}
+	ikeMessages = [...]string{
+		"Main Mode completion.",
+		"Quick Mode completion.",
+		"Main Mode Failed to match proposal: Transform: AES-256, SHA256, Pre-shared secret, Group 14 (2048 bit)",
+		"Quick Mode Received notification from peer: No proposal chosen",
+		"Main Mode peer is not responding to request: Timeout",
+	}
+	methods = [...]string{
+		"ESP: AES-256 + SHA256 + PFS (group 14)",
+		"ESP: AES-128 + SHA1",
+		"ESP: AES-GCM-256",
+	}
+)
+
+type accessRule struct {
+	name, action string
+	weight       int
+	ports        []int
+}
+
+type gateway struct {
+	name, sicName string
+	origin, wan   net.IP
+	lan           *net.IPNet
+	layers        map[string]string
+	rules         map[string][]string
+	pid           int
+}
+
+// Firewall holds the random fields for a Check Point log.
+type Firewall struct {
+	Action        string
+	App           string
+	AppCategory   string
+	AppID         int
+	AppRisk       int
+	Community     string
+	DstAddr       net.IP
+	DstPort       int
+	Flags         string
+	Gateway       string
+	Host          string
+	IfDir         string
+	IfName        string
+	IKE           string
+	InZone        string
+	LayerName     string
+	LayerUUID     string
+	Layout        string
+	LogUID        string
+	MatchID       int
+	Methods       string
+	Origin        net.IP
+	OriginSICName string
+	OutZone       string
+	PeerGateway   net.IP
+	PID           int
+	Product       string
+	Proto         int
+	RejectID      string
+	Resource      string
+	RuleName      string
+	RuleUID       string
+	Sequence      int
+	Service       string
+	SrcAddr       net.IP
+	SrcPort       int
+	Timestamp     time.Time
+	URLCategory   string
+	UserAgent     string
+	WebClient     string
+
+	blade     string
+	rand      *rand.Rand
+	clock     *generator.Clock
+	pins      *generator.Pins
+	gateways  []gateway
+	templates map[string][]*template.Template
+	actions   map[*template.Template]string
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Firewall objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Firewall{
+		Layout:    clock.Layout(time.RFC3339),
+		blade:     c.Blade,
+		rand:      r,
+		clock:     clock,
+		templates: make(map[string][]*template.Template),
+		actions:   make(map[*template.Template]string),
+	}
+	f.pins, err = generator.NewPins(cfg, r, f)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Gateways) == 0 {
+		c.Gateways = defaultGateways
+	}
+	// The SIC names of the gateways end in the suffix of the
+	// certificate authority of their management server.
+	suffix := random.Hex(r, 3)
+	for i, name := range c.Gateways {
+		g := gateway{
+			name:    name,
+			sicName: fmt.Sprintf("CN=%s,O=%s.%s", name, c.Management, suffix),
+			origin:  net.IPv4(192, 168, byte(i), 1),
+			wan:     random.IPv4(r),
+			lan:     &net.IPNet{IP: net.IPv4(192, 168, byte(i), 0), Mask: net.CIDRMask(24, 32)},
+			layers:  make(map[string]string),
+			rules:   make(map[string][]string),
+			pid:     1000 + r.IntN(30000),
+		}
+		for _, blade := range blades {
+			rs, ok := rules[blade]
+			if !ok {
+				continue
+			}
+			g.layers[blade] = random.UUID(r)
+			for range rs {
+				g.rules[blade] = append(g.rules[blade], random.UUID(r))
+			}
+		}
+		f.gateways = append(f.gateways, g)
+	}
+
+	for i, v := range templates {
+		t, err := generator.NewTemplate(fmt.Sprintf("%s-%d", v.blade, i), v.text)
+		if err != nil {
+			return nil, err
+		}
+		f.templates[v.blade] = append(f.templates[v.blade], t)
+		f.actions[t] = v.action
+	}
+
+	return f, nil
+}
+
+// Next produces the next Check Point log.
+//
+// Example:
+//
+// <134>1 2024-03-04T12:00:00Z gw-hq CheckPoint 12345 - [action:"Drop"; flags:"411908"; ifdir:"inbound"; ifname:"eth1"; logid:"0"; loguid:"{0x65e5b7c0,0x0,0x100a8c0,0x2a5f3c1}"; origin:"192.168.0.1"; originsicname:"CN=gw-hq,O=mgmt.example.com.3f9a1c"; sequencenum:"7"; time:"1709553600"; version:"5"; dst:"192.168.0.23"; inzone:"External"; outzone:"Internal"; product:"VPN-1 & FireWall-1"; proto:"6"; s_port:"51234"; service:"445"; service_id:"microsoft-ds"; src:"203.0.113.7"; layer_name:"Network"; layer_uuid:"..."; match_id:"3"; parent_rule:"0"; rule_action:"Drop"; rule_name:"Block SMB from Internet"; rule_uid:"..."]
+func (f *Firewall) Next() ([]byte, error) {
+	var buf bytes.Buffer
+
+	blade := f.blade
+	if blade == "" {
+		blade = blades[f.rand.IntN(len(blades))]
+	}
+	templates := f.templates[blade]
+	t := templates[f.rand.IntN(len(templates))]
+	f.randomize(blade, f.actions[t], f.gateways[f.rand.IntN(len(f.gateways))])
+
+	if err := t.Execute(&buf, f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// randomize sets the fields of a log of the blade of the gateway g,
+// with the action of a VPN log or the action of the rule it matches.
+func (f *Firewall) randomize(blade, action string, g gateway) {
+	f.Timestamp = f.clock.Now()
+	f.Sequence++
+	f.Gateway = g.name
+	f.PID = g.pid
+	f.Origin = g.origin
+	f.OriginSICName = g.sicName
+	f.Product = products[blade]
+	f.Flags = flags[f.rand.IntN(len(flags))]
+	f.LogUID = fmt.Sprintf("{0x%x,0x0,0x%x,0x%x}", f.Timestamp.Unix(), binary.LittleEndian.Uint32(g.origin.To4()), f.rand.Uint32())
+
+	s := services[f.rand.IntN(len(services))]
+	f.DstPort, f.Proto, f.Service = s.port, s.proto, s.id
+	f.SrcPort = random.Port(f.rand)
+	lan, wan := random.IPv4FromCIDR(f.rand, g.lan), random.IPv4(f.rand)
+	f.SrcAddr, f.DstAddr = lan, wan
+	f.IfDir, f.IfName, f.InZone, f.OutZone = "outbound", "eth1", "Internal", "External"
+	if f.rand.IntN(3) == 0 {
+		f.SrcAddr, f.DstAddr = wan, lan
+		f.IfDir, f.InZone, f.OutZone = "inbound", "External", "Internal"
+	}
+
+	app := applications[f.rand.IntN(len(applications))]
+	f.App, f.AppCategory, f.AppID, f.AppRisk = app.name, app.category, app.id, app.risk
+	category := categories[f.rand.IntN(len(categories))]
+	f.URLCategory = category.name
+	f.Resource = random.URL(f.rand)
+	if u, err := url.Parse(f.Resource); err == nil {
+		f.Host = u.Host
+	}
+	f.UserAgent = random.UserAgent(f.rand)
+	f.WebClient = webClients[f.rand.IntN(len(webClients))]
+
+	// The rule is the one of the blade that matches, the application
+	// and the category decide on those of Application Control and URL
+	// Filtering.
+	rs, uids := rules[blade], g.rules[blade]
+	f.LayerName, f.LayerUUID = "Network", g.layers[BladeFirewall]
+	i := 0
+	switch blade {
+	case BladeFirewall:
+		i = weighted(f.rand, rs)
+		if ports := rs[i].ports; len(ports) > 0 {
+			f.DstPort = ports[f.rand.IntN(len(ports))]
+			for _, s := range services {
+				if s.port == f.DstPort {
+					f.Proto, f.Service = s.proto, s.id
+				}
+			}
+		}
+	case BladeApplicationControl:
+		f.LayerName, f.LayerUUID = "Application", g.layers[blade]
+		if app.risk < 4 {
+			i = 1
+		}
+	case BladeURLFiltering:
+		f.LayerName, f.LayerUUID = "Application", g.layers[blade]
+		if !category.blocked {
+			i = 1
+		}
+	default:
+		rs, uids = rules[BladeFirewall], g.rules[BladeFirewall]
+		i = 1
+	}
+	f.MatchID = i + 1
+	f.RuleName, f.RuleUID, f.Action = rs[i].name, uids[i], rs[i].action
+	if action != "" {
+		f.Action = action
+	}
+	f.RejectID = fmt.Sprintf("%s-%s", random.Hex(f.rand, 4), random.Hex(f.rand, 2))
+
+	f.Community = communities[f.rand.IntN(len(communities))]
+	f.IKE = ikeMessages[f.rand.IntN(len(ikeMessages))]
+	f.Methods = methods[f.rand.IntN(len(methods))]
+	f.PeerGateway = random.IPv4(f.rand)
+	if len(f.gateways) > 1 {
+		// The peer of a site to site VPN is another gateway, the
+		// encrypted connections are to its LAN.
+		peer := f.gateways[f.rand.IntN(len(f.gateways))]
+		for peer.name == g.name {
+			peer = f.gateways[f.rand.IntN(len(f.gateways))]
+		}
+		f.PeerGateway = peer.wan
+		if blade == BladeVPN {
+			f.DstAddr = random.IPv4FromCIDR(f.rand, peer.lan)
+			if action == "Decrypt" {
+				f.SrcAddr, f.DstAddr = f.DstAddr, lan
+			}
+			f.InZone, f.OutZone = "Internal", "External"
+		}
+	}
+
+	f.pins.Apply(f)
+}
+
+// weighted returns the index of a rule, in proportion to the weights.
+func weighted(r *rand.Rand, rs []accessRule) int {
+	total := 0
+	for _, rule := range rs {
+		total += rule.weight
+	}
+	n := r.IntN(total)
+	for i, rule := range rs {
+		if n < rule.weight {
+			return i
+		}
+		n -= rule.weight
+	}
+	return len(rs) - 1
+}
+
+// Templates returns the templates of the generator, for generator.Lint.
+func (f *Firewall) Templates() (interface{}, []*template.Template) {
+	var templates []*template.Template
+	for _, t := range f.templates {
+		templates = append(templates, t...)
+	}
+	return f, templates
+}
@@ -0,0 +1,65 @@
+package firewall
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFirewall(t *testing.T, c map[string]interface{}) generator.Generator {
+	t.Helper()
+	cfg, err := ucfg.NewFrom(c)
+	assert.Nil(t, err)
+	g, err := New(cfg)
+	assert.Nil(t, err)
+	return g
+}
+
+func TestNext(t *testing.T) {
+	g := newFirewall(t, map[string]interface{}{"type": Name, "seed": 1, "gateways": []string{"gw-amsterdam", "gw-utrecht"}})
+	header := regexp.MustCompile(`^<134>1 \S+ (gw-amsterdam|gw-utrecht) CheckPoint \d+ - \[action:"([^"]+)"; flags:"\d+"; ifdir:"(inbound|outbound)"; ifname:"eth1"; logid:"0"; loguid:"\{0x[0-9a-f]+,0x0,0x[0-9a-f]+,0x[0-9a-f]+\}"; origin:"192\.168\.[01]\.1"; originsicname:"CN=(gw-amsterdam|gw-utrecht),O=mgmt\.example\.com\.[0-9a-f]{6}"; sequencenum:"(\d+)"; time:"\d+"; version:"5"; .*\]$`)
+	product := regexp.MustCompile(`; product:"([^"]+)"`)
+	actions := map[string]int{}
+	for i := 0; i < 500; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		m := header.FindStringSubmatch(string(got))
+		if !assert.NotNil(t, m, string(got)) {
+			continue
+		}
+		assert.Equal(t, m[1], m[4], string(got))
+		actions[m[2]]++
+		if p := product.FindStringSubmatch(string(got)); assert.NotNil(t, p, string(got)) {
+			assert.Contains(t, []string{"VPN-1 & FireWall-1", "Application Control", "URL Filtering"}, p[1])
+		}
+		if m[2] == actionReject {
+			assert.Contains(t, string(got), "reject_id:")
+		}
+		if strings.Contains(string(got), `fw_subproduct:"VPN-1"`) {
+			assert.Contains(t, []string{"Key Install", "Encrypt", "Decrypt"}, m[2], string(got))
+		}
+	}
+	for _, action := range []string{actionAccept, actionDrop, actionReject, "Key Install", "Encrypt", "Decrypt"} {
+		assert.NotZero(t, actions[action], action)
+	}
+}
+
+func TestBlade(t *testing.T) {
+	g := newFirewall(t, map[string]interface{}{"type": Name, "blade": BladeURLFiltering})
+	for i := 0; i < 20; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		assert.Contains(t, string(got), `product:"URL Filtering"`)
+		assert.Regexp(t, `; layer_name:"Application"; .*rule_name:"(Block Malicious Sites|Allow Web Browsing)"`, string(got))
+	}
+}
+
+func TestLint(t *testing.T) {
+	issues, err := generator.Lint(newFirewall(t, map[string]interface{}{"type": Name}), 100)
+	assert.Nil(t, err)
+	assert.Empty(t, issues)
+}
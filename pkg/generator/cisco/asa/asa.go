@@ -1,21 +1,30 @@
 // Package asa implements the generator for Cisco ASA logs.
 //
-// Configuration file supports including timestamps in log messages
+// The message IDs 106023, 302013, 302014, 305011, 113019 and 710003
+// are generated.  Connections that are built with 302013 are torn
+// down later with a 302014 of the same connection ID and addresses.
 //
-//   generator:
-//     type: cisco:asa
-//     include_timestamp: true
+// Configuration file supports including timestamps in log messages,
+// and the syslog priority header, "<PRI>", with the facility, by
+// default 20 (local4), and the severity of the message:
+//
+//	generator:
+//	  type: cisco:asa
+//	  include_timestamp: true
+//	  syslog: true
+//	  facility: 20
 package asa
 
 import (
 	"bytes"
 	"fmt"
-	"math/rand"
+	"math/rand/v2"
 	"net"
-	"strconv"
+	"strings"
 	"text/template"
 	"time"
 
+	"github.com/elastic/elastic-agent-libs/mapstr"
 	"github.com/elastic/go-ucfg"
 	"github.com/leehinman/spigot/pkg/generator"
 	"github.com/leehinman/spigot/pkg/random"
@@ -29,12 +38,29 @@ var (
 	asa302013    = "{{if .IncludeTimestamp}}{{.Timestamp.Format \"Jan 02 2006 03:04:05\"}}: {{end}}%ASA-6-302013: Built {{.Direction}} TCP connection {{.ConnectionId}} for {{.SrcInt}}:{{.SrcAddr}}/{{.SrcPort}} ({{.Map1Addr}}/{{.Map1Port}}) to {{.DstInt}}:{{.DstAddr}}/{{.DstPort}} ({{.Map2Addr}}/{{.Map2Port}})"
 	asa302014    = "{{if .IncludeTimestamp}}{{.Timestamp.Format \"Jan 02 2006 03:04:05\"}}: {{end}}%ASA-6-302014: Teardown TCP connection {{.ConnectionId}} for {{.SrcInt}}:{{.SrcAddr}}/{{.SrcPort}} to {{.DstInt}}:{{.DstAddr}}/{{.DstPort}} duration {{.Duration}} bytes {{.Bytes}} {{.Reason}}"
 	asa305011    = "{{if .IncludeTimestamp}}{{.Timestamp.Format \"Jan 02 2006 03:04:05\"}}: {{end}}%ASA-6-305011: Built {{.TranslationType}} {{.Protocol}} translation from {{.SrcInt}}:{{.SrcAddr}}/{{.SrcPort}} to {{.DstInt}}:{{.DstAddr}}/{{.DstPort}}"
+	asa113019    = "{{if .IncludeTimestamp}}{{.Timestamp.Format \"Jan 02 2006 03:04:05\"}}: {{end}}%ASA-4-113019: Group = {{.Group}}, Username = {{.SrcUser}}, IP = {{.SrcAddr}}, Session disconnected. Session Type: {{.SessionType}}, Duration: {{.Duration}}, Bytes xmt: {{.Bytes}}, Bytes rcv: {{.BytesRcv}}, Reason: {{.VpnReason}}"
+	asa710003    = "{{if .IncludeTimestamp}}{{.Timestamp.Format \"Jan 02 2006 03:04:05\"}}: {{end}}%ASA-3-710003: {{.Protocol}} access denied by ACL from {{.SrcAddr}}/{{.SrcPort}} to {{.DstInt}}:{{.DstAddr}}/{{.DstPort}}"
 	msgTemplates = [...]string{
 		asa106023,
 		asa302013,
 		asa302014,
 		asa305011,
+		asa113019,
+		asa710003,
+	}
+	msgIDs     = [...]string{"106023", "302013", "302014", "305011", "113019", "710003"}
+	severities = map[string]int{
+		"106023": 4,
+		"302013": 6,
+		"302014": 6,
+		"305011": 6,
+		"113019": 4,
+		"710003": 3,
 	}
+	levels           = map[int]string{3: "error", 4: "warning", 6: "informational"}
+	groups           = [...]string{"RemoteAccess", "Contractors", "Engineering", "DfltGrpPolicy"}
+	sessionTypes     = [...]string{"AnyConnect-Parent", "SSL", "IKEv2", "IPsec"}
+	vpnReasons       = [...]string{"User Requested", "Idle Timeout", "Max time exceeded", "Lost Service", "Administrator Reset", "Port Preempted"}
 	directions       = [...]string{"inbound", "outbound"}
 	protocols        = [...]string{"TCP", "UDP"}
 	translationTypes = [...]string{"dynamic", "static"}
@@ -79,10 +105,27 @@ var (
 	}
 )
 
+// maxSessions is the number of connections that are open at most.
+// When it is reached built connections are torn down instead.
+const maxSessions = 4096
+
+// session is a connection that has been built and not yet been torn
+// down.
+type session struct {
+	ConnectionId int
+	SrcInt       string
+	SrcAddr      net.IP
+	SrcPort      int
+	DstInt       string
+	DstAddr      net.IP
+	DstPort      int
+}
+
 type Asa struct {
 	AccessGroup      string
 	AclId            string
 	Bytes            int
+	BytesRcv         int
 	Code             int
 	ConnectionId     int
 	Direction        string
@@ -91,6 +134,7 @@ type Asa struct {
 	DstPort          int
 	DstUser          string
 	Duration         string
+	Group            string
 	IncludeTimestamp bool
 	Map1Addr         net.IP
 	Map1Port         int
@@ -98,6 +142,7 @@ type Asa struct {
 	Map2Port         int
 	Protocol         string
 	Reason           string
+	SessionType      string
 	SrcAddr          net.IP
 	SrcInt           string
 	SrcPort          int
@@ -105,6 +150,13 @@ type Asa struct {
 	Timestamp        time.Time
 	TranslationType  string
 	Type             int
+	VpnReason        string
+	facility         int
+	rand             *rand.Rand
+	clock            *generator.Clock
+	pins             *generator.Pins
+	sessions         []session
+	syslog           bool
 	templates        []*template.Template
 }
 
@@ -119,13 +171,32 @@ func New(cfg *ucfg.Config) (generator.Generator, error) {
 		return nil, err
 	}
 
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	a := &Asa{
 		IncludeTimestamp: c.IncludeTimestamp,
+		facility:         c.Facility,
+		rand:             r,
+		clock:            clock,
+		syslog:           c.Syslog,
+	}
+
+	a.pins, err = generator.NewPins(cfg, r, a)
+	if err != nil {
+		return nil, err
 	}
+
 	a.randomize()
 
 	for i, v := range msgTemplates {
-		t, err := template.New(strconv.Itoa(i)).Funcs(generator.FunctionMap).Parse(v)
+		t, err := template.New(msgIDs[i]).Funcs(generator.FunctionMap).Parse(v)
 		if err != nil {
 			return nil, err
 		}
@@ -137,16 +208,192 @@ func New(cfg *ucfg.Config) (generator.Generator, error) {
 
 // Next produces the next asa log entry
 func (a *Asa) Next() ([]byte, error) {
-	var buf bytes.Buffer
+	_, b, err := a.next()
+	if err != nil {
+		return nil, err
+	}
+
+	a.randomize()
 
-	err := a.templates[rand.Intn(len(a.templates))].Execute(&buf, a)
+	return b, err
+}
+
+// NextECS produces the next asa log entry as an ECS document.
+func (a *Asa) NextECS() (mapstr.M, error) {
+	id, b, err := a.next()
 	if err != nil {
 		return nil, err
 	}
 
+	doc := a.document(id, string(b))
+
 	a.randomize()
 
-	return buf.Bytes(), err
+	return doc, nil
+}
+
+// next executes a random template and returns its message ID and the
+// log entry.
+func (a *Asa) next() (string, []byte, error) {
+	var buf bytes.Buffer
+
+	t := a.templates[a.rand.IntN(len(a.templates))]
+	id := t.Name()
+	if id == "302013" && len(a.sessions) >= maxSessions {
+		if teardown := a.template("302014"); teardown != nil {
+			t, id = teardown, "302014"
+		}
+	}
+	switch id {
+	case "302013":
+		a.build()
+	case "302014":
+		a.teardown()
+	}
+
+	if a.syslog {
+		fmt.Fprintf(&buf, "<%d>", generator.Priority(a.facility, severities[id]))
+	}
+	if err := t.Execute(&buf, a); err != nil {
+		return "", nil, err
+	}
+	return id, buf.Bytes(), nil
+}
+
+// template returns the template of the message ID id, or nil.
+func (a *Asa) template(id string) *template.Template {
+	for _, t := range a.templates {
+		if t.Name() == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// build opens a session for the connection, with a connection ID that
+// is not used by another open session.
+func (a *Asa) build() {
+	if len(a.sessions) >= maxSessions {
+		return
+	}
+	for a.open(a.ConnectionId) {
+		a.ConnectionId = (a.ConnectionId + 1) % 65536
+	}
+	a.sessions = append(a.sessions, session{
+		ConnectionId: a.ConnectionId,
+		SrcInt:       a.SrcInt,
+		SrcAddr:      a.SrcAddr,
+		SrcPort:      a.SrcPort,
+		DstInt:       a.DstInt,
+		DstAddr:      a.DstAddr,
+		DstPort:      a.DstPort,
+	})
+}
+
+// teardown closes a random open session and uses its connection.
+// Without open sessions the connection is one that was built before
+// spigot started.
+func (a *Asa) teardown() {
+	if len(a.sessions) == 0 {
+		return
+	}
+	i := a.rand.IntN(len(a.sessions))
+	s := a.sessions[i]
+	a.sessions[i] = a.sessions[len(a.sessions)-1]
+	a.sessions = a.sessions[:len(a.sessions)-1]
+
+	a.ConnectionId = s.ConnectionId
+	a.SrcInt = s.SrcInt
+	a.SrcAddr = s.SrcAddr
+	a.SrcPort = s.SrcPort
+	a.DstInt = s.DstInt
+	a.DstAddr = s.DstAddr
+	a.DstPort = s.DstPort
+}
+
+func (a *Asa) open(id int) bool {
+	for _, s := range a.sessions {
+		if s.ConnectionId == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Asa) document(id, message string) mapstr.M {
+	fields := map[string]interface{}{
+		"@timestamp":                      a.Timestamp.UTC(),
+		"message":                         message,
+		"event.kind":                      "event",
+		"event.category":                  []string{"network"},
+		"event.code":                      id,
+		"event.severity":                  severities[id],
+		"log.level":                       levels[severities[id]],
+		"network.transport":               strings.ToLower(a.Protocol),
+		"observer.vendor":                 "Cisco",
+		"observer.product":                "ASA",
+		"observer.type":                   "firewall",
+		"observer.ingress.interface.name": a.SrcInt,
+		"observer.egress.interface.name":  a.DstInt,
+		"source.ip":                       a.SrcAddr.String(),
+		"source.port":                     a.SrcPort,
+		"destination.ip":                  a.DstAddr.String(),
+		"destination.port":                a.DstPort,
+	}
+	switch id {
+	case "106023":
+		fields["event.action"] = "deny"
+		fields["event.type"] = []string{"connection", "denied"}
+		fields["event.outcome"] = "failure"
+		fields["rule.name"] = a.AclId
+	case "302013":
+		fields["event.action"] = "connection-built"
+		fields["event.type"] = []string{"connection", "start"}
+		fields["network.transport"] = "tcp"
+		fields["network.direction"] = a.Direction
+		fields["source.nat.ip"] = a.Map1Addr.String()
+		fields["source.nat.port"] = a.Map1Port
+		fields["destination.nat.ip"] = a.Map2Addr.String()
+		fields["destination.nat.port"] = a.Map2Port
+	case "302014":
+		fields["event.action"] = "connection-teardown"
+		fields["event.type"] = []string{"connection", "end"}
+		fields["event.reason"] = a.Reason
+		fields["event.duration"] = duration(a.Duration).Nanoseconds()
+		fields["network.transport"] = "tcp"
+		fields["network.bytes"] = a.Bytes
+	case "305011":
+		fields["event.action"] = "translation-built"
+		fields["event.type"] = []string{"connection", "start"}
+	case "113019":
+		fields["event.action"] = "vpn-session-disconnected"
+		fields["event.category"] = []string{"network", "session"}
+		fields["event.type"] = []string{"end"}
+		fields["event.reason"] = a.VpnReason
+		fields["event.duration"] = duration(a.Duration).Nanoseconds()
+		fields["user.name"] = a.SrcUser
+		fields["group.name"] = a.Group
+		fields["network.bytes"] = a.Bytes + a.BytesRcv
+		fields["network.transport"] = nil
+		fields["source.port"] = nil
+		fields["destination.ip"] = nil
+		fields["destination.port"] = nil
+		fields["observer.ingress.interface.name"] = nil
+		fields["observer.egress.interface.name"] = nil
+	case "710003":
+		fields["event.action"] = "access-denied"
+		fields["event.type"] = []string{"connection", "denied"}
+		fields["event.outcome"] = "failure"
+		fields["observer.ingress.interface.name"] = nil
+	}
+	return generator.ECS(fields)
+}
+
+// duration parses a H:MM:SS duration.
+func duration(s string) time.Duration {
+	var h, m, sec int
+	_, _ = fmt.Sscanf(s, "%d:%d:%d", &h, &m, &sec)
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second
 }
 
 func (a *Asa) randomize() {
@@ -156,22 +403,33 @@ func (a *Asa) randomize() {
 	a.DstUser = "DstUser"
 	a.AccessGroup = "Access-Group"
 	a.AclId = "AclId"
-	a.Protocol = protocols[rand.Intn(len(protocols))]
-	a.TranslationType = translationTypes[rand.Intn(len(translationTypes))]
-	a.ConnectionId = rand.Intn(65536)
-	a.Duration = fmt.Sprintf("%01d:%02d:%02d", rand.Intn(4), rand.Intn(60), rand.Intn(60))
-	a.Bytes = rand.Intn(65536)
-	a.Reason = reasons[rand.Intn(len(reasons))]
-	a.SrcAddr = random.IPv4()
-	a.SrcPort = random.Port()
-	a.DstAddr = random.IPv4()
-	a.DstPort = random.Port()
-	a.Type = rand.Intn(64)
-	a.Code = rand.Intn(64)
-	a.Direction = directions[rand.Intn(len(directions))]
-	a.Map1Addr = random.IPv4()
-	a.Map1Port = random.Port()
-	a.Map2Addr = random.IPv4()
-	a.Map2Port = random.Port()
-	a.Timestamp = time.Now()
+	a.Protocol = protocols[a.rand.IntN(len(protocols))]
+	a.TranslationType = translationTypes[a.rand.IntN(len(translationTypes))]
+	a.ConnectionId = a.rand.IntN(65536)
+	a.Duration = fmt.Sprintf("%01d:%02d:%02d", a.rand.IntN(4), a.rand.IntN(60), a.rand.IntN(60))
+	a.Bytes = a.rand.IntN(65536)
+	a.Reason = reasons[a.rand.IntN(len(reasons))]
+	a.SrcAddr = random.IPv4(a.rand)
+	a.SrcPort = random.Port(a.rand)
+	a.DstAddr = random.IPv4(a.rand)
+	a.DstPort = random.Port(a.rand)
+	a.Type = a.rand.IntN(64)
+	a.Code = a.rand.IntN(64)
+	a.Direction = directions[a.rand.IntN(len(directions))]
+	a.Map1Addr = random.IPv4(a.rand)
+	a.Map1Port = random.Port(a.rand)
+	a.Map2Addr = random.IPv4(a.rand)
+	a.Map2Port = random.Port(a.rand)
+	a.Timestamp = a.clock.Now()
+	a.Group = groups[a.rand.IntN(len(groups))]
+	a.SessionType = sessionTypes[a.rand.IntN(len(sessionTypes))]
+	a.BytesRcv = a.rand.IntN(65536)
+	a.VpnReason = vpnReasons[a.rand.IntN(len(vpnReasons))]
+
+	a.pins.Apply(a)
+}
+
+// Templates returns the templates of the generator, for generator.Lint.
+func (a *Asa) Templates() (interface{}, []*template.Template) {
+	return a, a.templates
 }
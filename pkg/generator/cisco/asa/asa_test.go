@@ -1,11 +1,16 @@
 package asa
 
 import (
-	"math/rand"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
 	"testing"
 	"text/template"
 
+	"github.com/elastic/go-ucfg"
 	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -14,14 +19,16 @@ func TestNext(t *testing.T) {
 		template string
 		expected string
 	}{
-		"106023": {template: asa106023, expected: "%ASA-4-106023: Deny udp src SrcInt:144.254.210.24/18340 dst DstInt:141.249.228.131/23215 type 34 code 49 by access-group \"AclId\" [0x8ed66b60, 0xf8852875]"},
-		"302013": {template: asa302013, expected: "%ASA-6-302013: Built inbound TCP connection 19911 for SrcInt:144.254.210.24/18340 (53.42.9.120/30347) to DstInt:141.249.228.131/23215 (43.185.8.75/16165)"},
-		"302014": {template: asa302014, expected: "%ASA-6-302014: Teardown TCP connection 19911 for SrcInt:144.254.210.24/18340 to DstInt:141.249.228.131/23215 duration 3:01:18 bytes 52025 Xlate Clear"},
-		"305011": {template: asa305011, expected: "%ASA-6-305011: Built static UDP translation from SrcInt:144.254.210.24/18340 to DstInt:141.249.228.131/23215"},
+		"106023": {template: asa106023, expected: "%ASA-4-106023: Deny tcp src SrcInt:108.206.236.64/43831 dst DstInt:72.229.3.233/38190 type 11 code 34 by access-group \"AclId\" [0x8ed66b60, 0xf8852875]"},
+		"302013": {template: asa302013, expected: "%ASA-6-302013: Built inbound TCP connection 43836 for SrcInt:108.206.236.64/43831 (109.13.13.166/7497) to DstInt:72.229.3.233/38190 (180.47.236.207/26058)"},
+		"302014": {template: asa302014, expected: "%ASA-6-302014: Teardown TCP connection 43836 for SrcInt:108.206.236.64/43831 to DstInt:72.229.3.233/38190 duration 3:44:00 bytes 13208 TCP segment partial overlap"},
+		"305011": {template: asa305011, expected: "%ASA-6-305011: Built static TCP translation from SrcInt:108.206.236.64/43831 to DstInt:72.229.3.233/38190"},
+		"113019": {template: asa113019, expected: "%ASA-4-113019: Group = RemoteAccess, Username = SrcUser, IP = 108.206.236.64, Session disconnected. Session Type: SSL, Duration: 3:44:00, Bytes xmt: 13208, Bytes rcv: 55731, Reason: Idle Timeout"},
+		"710003": {template: asa710003, expected: "%ASA-3-710003: TCP access denied by ACL from 108.206.236.64/43831 to DstInt:72.229.3.233/38190"},
 	}
 	for name, tc := range tests {
-		rand.Seed(1)
-		a := &Asa{}
+		random.Seed(1)
+		a := &Asa{rand: random.NewRand(nil)}
 		templ, err := template.New(name).Funcs(generator.FunctionMap).Parse(tc.template)
 		assert.Nil(t, err)
 		a.templates = []*template.Template{templ}
@@ -31,3 +38,103 @@ func TestNext(t *testing.T) {
 		assert.Equal(t, []byte(tc.expected), got, name)
 	}
 }
+
+func TestNextECS(t *testing.T) {
+	random.Seed(1)
+	a := &Asa{rand: random.NewRand(nil)}
+	templ, err := template.New("302014").Funcs(generator.FunctionMap).Parse(asa302014)
+	assert.Nil(t, err)
+	a.templates = []*template.Template{templ}
+	a.randomize()
+	doc, err := a.NextECS()
+	assert.Nil(t, err)
+
+	want := map[string]interface{}{
+		"message":          "%ASA-6-302014: Teardown TCP connection 43836 for SrcInt:108.206.236.64/43831 to DstInt:72.229.3.233/38190 duration 3:44:00 bytes 13208 TCP segment partial overlap",
+		"event.code":       "302014",
+		"event.action":     "connection-teardown",
+		"event.duration":   int64(13440000000000),
+		"event.reason":     "TCP segment partial overlap",
+		"network.bytes":    13208,
+		"observer.vendor":  "Cisco",
+		"source.ip":        "108.206.236.64",
+		"source.port":      43831,
+		"destination.ip":   "72.229.3.233",
+		"destination.port": 38190,
+	}
+	for k, v := range want {
+		got, err := doc.GetValue(k)
+		assert.Nil(t, err, k)
+		assert.Equal(t, v, got, k)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := map[string]struct {
+		config  map[string]interface{}
+		json    bool
+		hasErr  bool
+		errDesc string
+	}{
+		"text": {config: map[string]interface{}{"type": Name, "format": "text"}},
+		"json": {config: map[string]interface{}{"type": Name, "format": "json"}, json: true},
+		"unsupported": {
+			config:  map[string]interface{}{"type": "unsupported:text", "format": "json"},
+			hasErr:  true,
+			errDesc: "'json' format can not be used with 'unsupported:text'",
+		},
+	}
+	// a generator without NextECS
+	_ = generator.Register("unsupported:text", func(cfg *ucfg.Config) (generator.Generator, error) {
+		g, err := New(ucfg.MustNewFrom(map[string]interface{}{"type": Name}))
+		return struct{ generator.Generator }{g}, err
+	})
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			g, err := generator.New(ucfg.MustNewFrom(tc.config))
+			if tc.hasErr {
+				assert.EqualError(t, err, tc.errDesc)
+				return
+			}
+			assert.Nil(t, err)
+			got, err := g.Next()
+			assert.Nil(t, err)
+			var doc map[string]interface{}
+			if tc.json {
+				assert.Nil(t, json.Unmarshal(got, &doc))
+				assert.Equal(t, "Cisco", doc["observer"].(map[string]interface{})["vendor"])
+			} else {
+				assert.NotNil(t, json.Unmarshal(got, &doc))
+			}
+		})
+	}
+}
+
+func TestSessions(t *testing.T) {
+	random.Seed(1)
+	g, err := New(ucfg.MustNewFrom(map[string]interface{}{"type": Name, "syslog": true}))
+	assert.Nil(t, err)
+
+	built := map[string]string{}
+	re := regexp.MustCompile(`^<(\d+)>%ASA-(\d)-(\d+): (?:(?:Built (?:inbound|outbound)|Teardown) TCP connection (\d+) for (\S+) (?:\(\S+\) )?to (\S+))?`)
+	paired := 0
+	for i := 0; i < 2000; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		m := re.FindStringSubmatch(string(got))
+		assert.NotNil(t, m, string(got))
+		severity, _ := strconv.Atoi(m[2])
+		assert.Equal(t, fmt.Sprint(20*8+severity), m[1], string(got))
+		switch m[3] {
+		case "302013":
+			built[m[4]] = m[5] + " " + m[6]
+		case "302014":
+			if conn, ok := built[m[4]]; ok {
+				assert.Equal(t, conn, m[5]+" "+m[6], string(got))
+				delete(built, m[4])
+				paired++
+			}
+		}
+	}
+	assert.True(t, paired > 100, "paired %d", paired)
+}
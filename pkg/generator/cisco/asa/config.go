@@ -1,15 +1,22 @@
 package asa
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/leehinman/spigot/pkg/generator"
+)
 
 type config struct {
 	Type             string `config:"type" validate:"required"`
 	IncludeTimestamp bool   `config:"include_timestamp"`
+	Syslog           bool   `config:"syslog"`
+	Facility         int    `config:"facility"`
 }
 
 func defaultConfig() config {
 	return config{
-		Type: Name,
+		Type:     Name,
+		Facility: 20,
 	}
 }
 
@@ -17,5 +24,5 @@ func (c *config) Validate() error {
 	if c.Type != Name {
 		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
 	}
-	return nil
+	return generator.ValidFacility("facility", c.Facility)
 }
@@ -33,6 +33,16 @@ func TestConfigs(t *testing.T) {
 			hasError:    false,
 			errorString: "",
 		},
+		"Syslog": {
+			c:           map[string]interface{}{"type": Name, "syslog": true, "facility": 16},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Facility": {
+			c:           map[string]interface{}{"type": Name, "syslog": true, "facility": 24},
+			hasError:    true,
+			errorString: "'24' is not a valid value for 'facility' expected a value from 0 to 23 accessing config",
+		},
 	}
 	for name, tc := range tests {
 		c, err := ucfg.NewFrom(tc.c)
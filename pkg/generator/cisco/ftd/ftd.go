@@ -0,0 +1,363 @@
+// Package ftd implements the generator for Cisco Secure Firewall Threat
+// Defense (FTD) security event syslog messages.
+//
+// The intrusion (430001), connection (430002, 430003) and file (430004)
+// events are generated in the key:value format used when FTD sends
+// security events to a syslog server.  Connection events include
+// Security Intelligence variants, and every connection end event
+// (430003) refers to a connection that was previously reported by a
+// connection start event (430002).
+//
+// Configuration file supports including timestamps in log messages
+//
+//	generator:
+//	  type: cisco:ftd
+//	  include_timestamp: true
+package ftd
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name of the generator in the configuration file and registry
+const Name = "cisco:ftd"
+
+// maxOpenConnections is the number of connection start events that
+// are remembered so that a matching connection end event can be
+// emitted.
+const maxOpenConnections = 256
+
+const (
+	header    = "{{if .IncludeTimestamp}}{{.Timestamp.Format \"Jan 02 2006 15:04:05\"}}: {{end}}"
+	common    = "DeviceUUID: {{.DeviceUUID}}, InstanceID: {{.InstanceID}}, FirstPacketSecond: {{.FirstPacket.UTC.Format \"2006-01-02T15:04:05Z\"}}, ConnectionID: {{.ConnectionID}}"
+	flow      = "SrcIP: {{.SrcAddr}}, DstIP: {{.DstAddr}}, SrcPort: {{.SrcPort}}, DstPort: {{.DstPort}}, Protocol: {{.Protocol}}, IngressInterface: {{.IngressInterface}}, EgressInterface: {{.EgressInterface}}, IngressZone: {{.IngressZone}}, EgressZone: {{.EgressZone}}"
+	policy    = "ACPolicy: {{.ACPolicy}}, AccessControlRuleName: {{.RuleName}}"
+	secIntel  = "{{if .SecIntelCategory}}, SecIntelMatchingIP: {{.SecIntelMatch}}, IPReputationSICategory: {{.SecIntelCategory}}{{end}}"
+	ftd430001 = header + "%FTD-1-430001: " + common + ", " + flow + ", Priority: {{.Priority}}, GID: 1, SID: {{.SID}}, Revision: {{.Revision}}, Message: {{.Message}}, Classification: {{.Classification}}, Client: {{.Client}}, ApplicationProtocol: {{.Application}}, IntrusionPolicy: {{.IntrusionPolicy}}, " + policy + ", NAPPolicy: {{.NAPPolicy}}, InlineResult: {{.InlineResult}}"
+	ftd430002 = header + "%FTD-1-430002: " + common + ", AccessControlRuleAction: {{.Action}}{{if .Reason}}, AccessControlRuleReason: {{.Reason}}{{end}}, " + flow + ", " + policy + ", Prefilter Policy: {{.PrefilterPolicy}}, User: {{.User}}, Client: {{.Client}}, ApplicationProtocol: {{.Application}}, InitiatorPackets: {{.InitiatorPackets}}, ResponderPackets: {{.ResponderPackets}}, InitiatorBytes: {{.InitiatorBytes}}, ResponderBytes: {{.ResponderBytes}}, NAPPolicy: {{.NAPPolicy}}" + secIntel
+	ftd430003 = header + "%FTD-1-430003: " + common + ", AccessControlRuleAction: {{.Action}}{{if .Reason}}, AccessControlRuleReason: {{.Reason}}{{end}}, " + flow + ", " + policy + ", Prefilter Policy: {{.PrefilterPolicy}}, User: {{.User}}, ConnectionDuration: {{.Duration}}, Client: {{.Client}}, ApplicationProtocol: {{.Application}}, InitiatorPackets: {{.InitiatorPackets}}, ResponderPackets: {{.ResponderPackets}}, InitiatorBytes: {{.InitiatorBytes}}, ResponderBytes: {{.ResponderBytes}}, NAPPolicy: {{.NAPPolicy}}" + secIntel
+	ftd430004 = header + "%FTD-1-430004: " + common + ", " + flow + ", FileDirection: {{.FileDirection}}, FileAction: {{.FileAction}}, FileSHA256: {{.FileSHA256}}, SHA_Disposition: {{.Disposition}}, SperoDisposition: Spero detection not performed on file, ThreatName: {{.ThreatName}}, FileName: {{.FileName}}, FileType: {{.FileType}}, FileSize: {{.FileSize}}, ApplicationProtocol: {{.Application}}, Client: {{.Client}}, User: {{.User}}, FilePolicy: {{.FilePolicy}}, FileStorageStatus: Not Stored (Disposition Was Pending), FileSandboxStatus: File Size Is Too Large"
+	intrusion = "430001"
+	connStart = "430002"
+	connEnd   = "430003"
+	fileEvent = "430004"
+)
+
+var (
+	msgTemplates = map[string]string{
+		intrusion: ftd430001,
+		connStart: ftd430002,
+		connEnd:   ftd430003,
+		fileEvent: ftd430004,
+	}
+	// msgWeights controls how often each message is generated, so
+	// that there are more connection events than anything else.
+	msgWeights = [...]string{
+		intrusion,
+		connStart, connStart, connStart, connStart,
+		connEnd, connEnd, connEnd, connEnd,
+		fileEvent,
+	}
+	protocols        = [...]string{"tcp", "udp"}
+	interfaces       = [...]string{"inside", "outside", "dmz", "guest"}
+	zones            = [...]string{"inside_zone", "outside_zone", "dmz_zone", "guest_zone"}
+	acPolicies       = [...]string{"Default Access Control", "Corporate-ACP", "Branch-ACP"}
+	ruleNames        = [...]string{"Allow-Outbound", "Block-Inbound", "Allow-DNS", "Inspect-Web", "Default Action"}
+	actions          = [...]string{"Allow", "Trust", "Block", "Block with reset"}
+	prefilter        = [...]string{"Default Prefilter Policy", "Corporate-Prefilter"}
+	users            = [...]string{"No Authentication Required", "LAB\\alice", "LAB\\bob", "LAB\\eve", "Unknown"}
+	clients          = [...]string{"SSL client", "Chrome", "Firefox", "cURL", "Windows Update Agent"}
+	applications     = [...]string{"HTTPS", "HTTP", "DNS", "SSH", "SMB", "NTP"}
+	napPolicies      = [...]string{"Balanced Security and Connectivity", "Security Over Connectivity", "Connectivity Over Security"}
+	secIntelMatches  = [...]string{"Source", "Destination"}
+	secIntelCategory = [...]string{"Malware", "Attackers", "Bogon", "Bots", "CnC", "Phishing", "Spam", "Tor_exit_node"}
+	inlineResults    = [...]string{"blocked", "would have dropped", "dropped"}
+	directions       = [...]string{"Download", "Upload"}
+	fileActions      = [...]string{"Malware Cloud Lookup", "Block", "Detect", "Block Malware"}
+	dispositions     = [...]string{"Unavailable", "Clean", "Malware", "Unknown"}
+	fileNames        = [...]string{"invoice.pdf", "setup.exe", "report.docx", "update.zip", "payload.dll"}
+	fileTypes        = [...]string{"PDF", "MSEXE", "MSOLE2", "ZIP", "MSEXE"}
+	filePolicies     = [...]string{"Malware-and-File-Policy", "Block-Executables"}
+	signatures       = [...]struct {
+		SID            int
+		Message        string
+		Classification string
+		Priority       int
+	}{
+		{SID: 1201, Message: "INDICATOR-COMPROMISE 403 Forbidden", Classification: "Attempted Information Leak", Priority: 2},
+		{SID: 2023, Message: "PROTOCOL-DNS potential dns cache poisoning attempt", Classification: "Misc Attack", Priority: 2},
+		{SID: 30524, Message: "SERVER-OTHER OpenSSL TLSv1.1 heartbeat read overrun attempt", Classification: "Attempted Administrator Privilege Gain", Priority: 1},
+		{SID: 44228, Message: "SERVER-OTHER Apache Log4j logging remote code execution attempt", Classification: "Attempted User Privilege Gain", Priority: 1},
+		{SID: 58722, Message: "MALWARE-CNC Win.Trojan.Agent outbound connection", Classification: "A Network Trojan was Detected", Priority: 1},
+	}
+	threatNames = [...]string{"W32.Auto:2a2c5d.in03.Talos", "Win.Ransomware.Locky::95.sbx.vioc"}
+	// optional are the default percentages of events the fields are
+	// empty in.
+	optional = map[string]float64{"ThreatName": 50}
+)
+
+// connection is the state kept between connection start and end events.
+type connection struct {
+	ID          int
+	FirstPacket time.Time
+	SrcAddr     net.IP
+	DstAddr     net.IP
+	SrcPort     int
+	DstPort     int
+	Protocol    string
+	Action      string
+	Reason      string
+	RuleName    string
+	Category    string
+	Match       string
+}
+
+// Ftd holds the random fields for an FTD security event.
+type Ftd struct {
+	ACPolicy         string
+	Action           string
+	Application      string
+	Classification   string
+	Client           string
+	ConnectionID     int
+	DeviceUUID       string
+	Disposition      string
+	DstAddr          net.IP
+	DstPort          int
+	Duration         int
+	EgressInterface  string
+	EgressZone       string
+	FileAction       string
+	FileDirection    string
+	FileName         string
+	FilePolicy       string
+	FileSHA256       string
+	FileSize         int
+	FileType         string
+	FirstPacket      time.Time
+	IncludeTimestamp bool
+	IngressInterface string
+	IngressZone      string
+	InitiatorBytes   int
+	InitiatorPackets int
+	InlineResult     string
+	InstanceID       int
+	IntrusionPolicy  string
+	Message          string
+	NAPPolicy        string
+	PrefilterPolicy  string
+	Priority         int
+	Protocol         string
+	Reason           string
+	ResponderBytes   int
+	ResponderPackets int
+	Revision         int
+	RuleName         string
+	SecIntelCategory string
+	SecIntelMatch    string
+	SID              int
+	SrcAddr          net.IP
+	SrcPort          int
+	ThreatName       string
+	Timestamp        time.Time
+	User             string
+
+	rand      *rand.Rand
+	clock     *generator.Clock
+	pins      *generator.Pins
+	templates map[string]*template.Template
+	open      []connection
+	nextID    int
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is Factory for the ftd generator
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Ftd{
+		IncludeTimestamp: c.IncludeTimestamp,
+		DeviceUUID:       randomUUID(r),
+		InstanceID:       r.IntN(16) + 1,
+		rand:             r,
+		clock:            clock,
+		templates:        make(map[string]*template.Template),
+	}
+
+	f.pins, err = generator.NewPins(cfg, r, f)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.pins.Optional(optional); err != nil {
+		return nil, err
+	}
+
+	for id, v := range msgTemplates {
+		t, err := template.New(id).Funcs(generator.FunctionMap).Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		f.templates[id] = t
+	}
+
+	return f, nil
+}
+
+// Next produces the next ftd log entry
+func (f *Ftd) Next() ([]byte, error) {
+	var buf bytes.Buffer
+
+	id := msgWeights[f.rand.IntN(len(msgWeights))]
+	f.randomize(id)
+
+	err := f.templates[id].Execute(&buf, f)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), err
+}
+
+func (f *Ftd) randomize(id string) {
+	f.Timestamp = f.clock.Now()
+	f.IngressInterface = interfaces[f.rand.IntN(len(interfaces))]
+	f.EgressInterface = interfaces[f.rand.IntN(len(interfaces))]
+	f.IngressZone = zones[f.rand.IntN(len(zones))]
+	f.EgressZone = zones[f.rand.IntN(len(zones))]
+	f.ACPolicy = acPolicies[f.rand.IntN(len(acPolicies))]
+	f.PrefilterPolicy = prefilter[f.rand.IntN(len(prefilter))]
+	f.NAPPolicy = napPolicies[f.rand.IntN(len(napPolicies))]
+	f.IntrusionPolicy = napPolicies[f.rand.IntN(len(napPolicies))]
+	f.User = users[f.rand.IntN(len(users))]
+	f.Client = clients[f.rand.IntN(len(clients))]
+	f.Application = applications[f.rand.IntN(len(applications))]
+	f.InitiatorPackets = f.rand.IntN(1024) + 1
+	f.ResponderPackets = f.rand.IntN(1024)
+	f.InitiatorBytes = f.InitiatorPackets * (f.rand.IntN(1400) + 60)
+	f.ResponderBytes = f.ResponderPackets * (f.rand.IntN(1400) + 60)
+
+	var conn connection
+	if id == connEnd && len(f.open) > 0 {
+		i := f.rand.IntN(len(f.open))
+		conn = f.open[i]
+		f.open = append(f.open[:i], f.open[i+1:]...)
+	} else {
+		conn = f.newConnection(id)
+	}
+	if id == connStart {
+		if len(f.open) >= maxOpenConnections {
+			f.open = f.open[1:]
+		}
+		f.open = append(f.open, conn)
+		// A connection start event is sent before any data is exchanged.
+		f.InitiatorPackets = 1
+		f.ResponderPackets = 0
+		f.InitiatorBytes = f.rand.IntN(1400) + 60
+		f.ResponderBytes = 0
+	}
+
+	f.ConnectionID = conn.ID
+	f.FirstPacket = conn.FirstPacket
+	f.SrcAddr = conn.SrcAddr
+	f.DstAddr = conn.DstAddr
+	f.SrcPort = conn.SrcPort
+	f.DstPort = conn.DstPort
+	f.Protocol = conn.Protocol
+	f.Action = conn.Action
+	f.Reason = conn.Reason
+	f.RuleName = conn.RuleName
+	f.SecIntelCategory = conn.Category
+	f.SecIntelMatch = conn.Match
+	f.Duration = int(f.Timestamp.Sub(conn.FirstPacket).Seconds())
+
+	s := signatures[f.rand.IntN(len(signatures))]
+	f.SID = s.SID
+	f.Message = s.Message
+	f.Classification = s.Classification
+	f.Priority = s.Priority
+	f.Revision = f.rand.IntN(20) + 1
+	f.InlineResult = inlineResults[f.rand.IntN(len(inlineResults))]
+
+	f.FileDirection = directions[f.rand.IntN(len(directions))]
+	f.FileAction = fileActions[f.rand.IntN(len(fileActions))]
+	f.FileSHA256 = randomHex(f.rand, 32)
+	f.Disposition = dispositions[f.rand.IntN(len(dispositions))]
+	f.ThreatName = threatNames[f.rand.IntN(len(threatNames))]
+	n := f.rand.IntN(len(fileNames))
+	f.FileName = fileNames[n]
+	f.FileType = fileTypes[n]
+	f.FileSize = f.rand.IntN(1 << 24)
+	f.FilePolicy = filePolicies[f.rand.IntN(len(filePolicies))]
+
+	f.pins.Apply(f)
+}
+
+func (f *Ftd) newConnection(id string) connection {
+	f.nextID++
+	c := connection{
+		ID:          f.nextID,
+		FirstPacket: f.Timestamp.Add(-time.Duration(f.rand.IntN(3600)) * time.Second),
+		SrcAddr:     random.IPv4(f.rand),
+		DstAddr:     random.IPv4(f.rand),
+		SrcPort:     random.Port(f.rand),
+		DstPort:     random.Port(f.rand),
+		Protocol:    protocols[f.rand.IntN(len(protocols))],
+		Action:      actions[f.rand.IntN(len(actions))],
+		RuleName:    ruleNames[f.rand.IntN(len(ruleNames))],
+	}
+	if id == intrusion || id == fileEvent {
+		return c
+	}
+	// One in five connections is a Security Intelligence match.
+	if f.rand.IntN(5) == 0 {
+		c.Action = "Block"
+		c.Reason = "IP Block"
+		c.RuleName = "Security Intelligence"
+		c.Category = secIntelCategory[f.rand.IntN(len(secIntelCategory))]
+		c.Match = secIntelMatches[f.rand.IntN(len(secIntelMatches))]
+	}
+	return c
+}
+
+func randomHex(r *rand.Rand, n int) string {
+	b := make([]byte, n)
+	random.Read(r, b)
+	return fmt.Sprintf("%x", b)
+}
+
+func randomUUID(r *rand.Rand) string {
+	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x", r.Uint32(), r.IntN(1<<16), r.IntN(1<<16), r.IntN(1<<16), r.Int64N(1<<48))
+}
+
+// Templates returns the templates of the generator, for generator.Lint.
+func (f *Ftd) Templates() (interface{}, []*template.Template) {
+	var templates []*template.Template
+	for _, t := range f.templates {
+		templates = append(templates, t)
+	}
+	return f, templates
+}
@@ -0,0 +1,66 @@
+package ftd
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		id       string
+		expected string
+	}{
+		"430001": {id: intrusion, expected: `%FTD-1-430001: DeviceUUID: e08eb556-1f8f-ab3c-559b-ffbd92e43b2a, InstanceID: 3, FirstPacketSecond: 1970-01-02T03:04:05Z, ConnectionID: 1, SrcIP: 22.184.148.51, DstIP: 37.249.179.91, SrcPort: 40858, DstPort: 25403, Protocol: tcp, IngressInterface: inside, EgressInterface: guest, IngressZone: guest_zone, EgressZone: guest_zone, Priority: 2, GID: 1, SID: 1201, Revision: 16, Message: INDICATOR-COMPROMISE 403 Forbidden, Classification: Attempted Information Leak, Client: cURL, ApplicationProtocol: NTP, IntrusionPolicy: Balanced Security and Connectivity, ACPolicy: Branch-ACP, AccessControlRuleName: Allow-Outbound, NAPPolicy: Balanced Security and Connectivity, InlineResult: dropped`},
+		"430002": {id: connStart, expected: `%FTD-1-430002: DeviceUUID: e08eb556-1f8f-ab3c-559b-ffbd92e43b2a, InstanceID: 3, FirstPacketSecond: 1970-01-02T03:04:05Z, ConnectionID: 1, AccessControlRuleAction: Block, AccessControlRuleReason: IP Block, SrcIP: 22.184.148.51, DstIP: 37.249.179.91, SrcPort: 40858, DstPort: 25403, Protocol: tcp, IngressInterface: inside, EgressInterface: guest, IngressZone: guest_zone, EgressZone: guest_zone, ACPolicy: Branch-ACP, AccessControlRuleName: Security Intelligence, Prefilter Policy: Default Prefilter Policy, User: No Authentication Required, Client: cURL, ApplicationProtocol: NTP, InitiatorPackets: 1, ResponderPackets: 0, InitiatorBytes: 85, ResponderBytes: 0, NAPPolicy: Balanced Security and Connectivity, SecIntelMatchingIP: Destination, IPReputationSICategory: Spam`},
+		"430003": {id: connEnd, expected: `%FTD-1-430003: DeviceUUID: e08eb556-1f8f-ab3c-559b-ffbd92e43b2a, InstanceID: 3, FirstPacketSecond: 1970-01-02T03:04:05Z, ConnectionID: 1, AccessControlRuleAction: Block, AccessControlRuleReason: IP Block, SrcIP: 22.184.148.51, DstIP: 37.249.179.91, SrcPort: 40858, DstPort: 25403, Protocol: tcp, IngressInterface: inside, EgressInterface: guest, IngressZone: guest_zone, EgressZone: guest_zone, ACPolicy: Branch-ACP, AccessControlRuleName: Security Intelligence, Prefilter Policy: Default Prefilter Policy, User: No Authentication Required, ConnectionDuration: 42, Client: cURL, ApplicationProtocol: NTP, InitiatorPackets: 42, ResponderPackets: 458, InitiatorBytes: 49476, ResponderBytes: 236786, NAPPolicy: Balanced Security and Connectivity, SecIntelMatchingIP: Destination, IPReputationSICategory: Spam`},
+		"430004": {id: fileEvent, expected: `%FTD-1-430004: DeviceUUID: e08eb556-1f8f-ab3c-559b-ffbd92e43b2a, InstanceID: 3, FirstPacketSecond: 1970-01-02T03:04:05Z, ConnectionID: 1, SrcIP: 22.184.148.51, DstIP: 37.249.179.91, SrcPort: 40858, DstPort: 25403, Protocol: tcp, IngressInterface: inside, EgressInterface: guest, IngressZone: guest_zone, EgressZone: guest_zone, FileDirection: Upload, FileAction: Block Malware, FileSHA256: 3f6a229959f0597b39484ea809d9208e523189c8bb065645f43a969777efdf3b, SHA_Disposition: Clean, SperoDisposition: Spero detection not performed on file, ThreatName: W32.Auto:2a2c5d.in03.Talos, FileName: payload.dll, FileType: MSEXE, FileSize: 12979451, ApplicationProtocol: NTP, Client: cURL, User: No Authentication Required, FilePolicy: Malware-and-File-Policy, FileStorageStatus: Not Stored (Disposition Was Pending), FileSandboxStatus: File Size Is Too Large`},
+	}
+	testTime, err := time.Parse(time.RFC3339, "1970-01-02T03:04:05Z")
+	assert.Nil(t, err)
+	for name, tc := range tests {
+		random.Seed(1)
+		g, err := New(ucfg.MustNewFrom(map[string]interface{}{}))
+		assert.Nil(t, err, name)
+		f := g.(*Ftd)
+		f.randomize(tc.id)
+		f.FirstPacket = testTime
+		f.Duration = 42
+		var buf bytes.Buffer
+		err = f.templates[tc.id].Execute(&buf, f)
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, buf.String(), name)
+	}
+}
+
+func TestConnectionPairing(t *testing.T) {
+	random.Seed(1)
+	g, err := New(ucfg.MustNewFrom(map[string]interface{}{}))
+	assert.Nil(t, err)
+	f := g.(*Ftd)
+
+	started := map[string]bool{}
+	re := regexp.MustCompile(`%FTD-1-(43000[23]): .*ConnectionID: (\d+),`)
+	for i := 0; i < 1000; i++ {
+		b, err := f.Next()
+		assert.Nil(t, err)
+		m := re.FindSubmatch(b)
+		if m == nil {
+			continue
+		}
+		switch string(m[1]) {
+		case connStart:
+			started[string(m[2])] = true
+		case connEnd:
+			if len(started) > 0 {
+				assert.True(t, started[string(m[2])], "connection %s ended without starting", m[2])
+			}
+			delete(started, string(m[2]))
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package meraki
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+type config struct {
+	Type          string   `config:"type" validate:"required"`
+	EventType     string   `config:"event_type"`
+	Networks      []string `config:"networks"`
+	Serials       []string `config:"serials"`
+	Hostname      string   `config:"hostname"`
+	ClientVendors []string `config:"client_vendors"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:     Name,
+		Hostname: HostnameName,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if c.EventType != "" && !contains(eventTypes[:], c.EventType) {
+		return fmt.Errorf("'%s' is not a valid value for 'event_type' expected '%s'", c.EventType, strings.Join(eventTypes[:], ", "))
+	}
+	networks := len(c.Networks)
+	if networks == 0 {
+		networks = len(defaultNetworks)
+	}
+	if devices := networks * len(models); len(c.Serials) > devices {
+		return fmt.Errorf("'serials' expected at most %d serials, %d for each network", devices, len(models))
+	}
+	if !(c.Hostname == HostnameName || c.Hostname == HostnameSerial) {
+		return fmt.Errorf("'%s' is not a valid value for 'hostname' expected '%s, %s'", c.Hostname, HostnameName, HostnameSerial)
+	}
+	if _, err := random.NewMACPool("client_vendors", c.ClientVendors); err != nil {
+		return err
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
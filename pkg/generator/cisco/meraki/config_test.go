@@ -0,0 +1,74 @@
+package meraki
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Pools": {
+			c:           map[string]interface{}{"type": Name, "event_type": "ids-alerts", "networks": []string{"Store-0042"}, "serials": []string{"Q2PN-4XKD-9UAB"}, "hostname": "serial"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'cisco:meraki' accessing config",
+		},
+		"Invalid Event Type": {
+			c:           map[string]interface{}{"type": Name, "event_type": "security"},
+			hasError:    true,
+			errorString: "'security' is not a valid value for 'event_type' expected 'flows, urls, ids-alerts, events' accessing config",
+		},
+		"Too Many Serials": {
+			c:           map[string]interface{}{"type": Name, "networks": []string{"HQ"}, "serials": []string{"Q2AA-AAAA-AAA1", "Q2AA-AAAA-AAA2", "Q2AA-AAAA-AAA3", "Q2AA-AAAA-AAA4", "Q2AA-AAAA-AAA5"}},
+			hasError:    true,
+			errorString: "'serials' expected at most 4 serials, 4 for each network accessing config",
+		},
+		"Invalid Hostname": {
+			c:           map[string]interface{}{"type": Name, "hostname": "mac"},
+			hasError:    true,
+			errorString: "'mac' is not a valid value for 'hostname' expected 'name, serial' accessing config",
+		},
+		"Invalid Client Vendors": {
+			c:           map[string]interface{}{"type": Name, "client_vendors": []string{"apple", "nokia"}},
+			hasError:    true,
+			errorString: "'nokia' is not a valid value for 'client_vendors' expected 'apple, cisco, dell, hp, hyperv, intel, meraki, raspberrypi, vmware' accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			_, err = New(c)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
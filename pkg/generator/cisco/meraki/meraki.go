@@ -0,0 +1,358 @@
+// Package meraki generates Cisco Meraki syslog messages.
+//
+// Four kinds of messages are generated, in the key=value format of the
+// Meraki syslog server role.  Flows are the connections the firewall
+// of an MX appliance allows or denies, urls the HTTP requests of its
+// clients, ids-alerts the Snort alerts of its intrusion detection and
+// events the DHCP, VPN, wireless and switch port events of the MX
+// appliances, MR access points and MS switches.  Every network of the
+// networks pool has an MX84, two MR access points and an MS225 switch,
+// with the serials of the serials pool, in order, or random ones.  The
+// device in the header is its name, the network, the model and the end
+// of the serial, or its serial.
+//
+// Configuration:
+//
+//	event_type: Specify the type of event to generate, or leave blank for random.
+//	            Valid values are: flows, urls, ids-alerts, events.
+//	networks:   The names of the networks, defaults to HQ,
+//	            Branch-Amsterdam and Warehouse.
+//	serials:    The serials of the devices, 4 for each network.
+//	hostname:   The device in the header, name (the default) or serial.
+//	client_vendors: The vendors of the MAC addresses of the clients,
+//	            defaults to apple, dell, hp and intel.
+//
+//	- generator:
+//	    type: cisco:meraki
+//	    networks: [Store-0042, Store-0043]
+//	    serials: [Q2PN-4XKD-9UAB, Q3AC-7WJR-LM2Z]
+//	    client_vendors: [apple, raspberrypi]
+package meraki
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "cisco:meraki"
+
+// Event types and the devices in the header.
+const (
+	EventTypeFlows     = "flows"
+	EventTypeURLs      = "urls"
+	EventTypeIDSAlerts = "ids-alerts"
+	EventTypeEvents    = "events"
+
+	HostnameName   = "name"
+	HostnameSerial = "serial"
+)
+
+// Kinds of Meraki devices.
+const (
+	kindAppliance = "MX"
+	kindWireless  = "MR"
+	kindSwitch    = "MS"
+)
+
+var (
+	eventTypes      = [...]string{EventTypeFlows, EventTypeURLs, EventTypeIDSAlerts, EventTypeEvents}
+	defaultNetworks = []string{"HQ", "Branch-Amsterdam", "Warehouse"}
+	// defaultClientVendors are the vendors of the network cards of the
+	// clients of a network.
+	defaultClientVendors = []string{"apple", "dell", "hp", "intel"}
+	// models are the devices of each network.
+	models = [...]struct {
+		model, kind string
+	}{
+		{"MX84", kindAppliance},
+		{"MR46", kindWireless},
+		{"MR36", kindWireless},
+		{"MS225-48", kindSwitch},
+	}
+	header    = "<134>1 {{.Timestamp.Unix}}.{{printf \"%09d\" .Timestamp.Nanosecond}} {{.Device}} "
+	templates = [...]struct {
+		eventType, kind, text string
+	}{
+		{EventTypeFlows, kindAppliance, header + "flows src={{.SrcAddr}} dst={{.DstAddr}} mac={{.SrcMAC}} protocol={{.Protocol}}{{if eq .Protocol \"icmp\"}} type={{.ICMPType}}{{else}} sport={{.SrcPort}} dport={{.DstPort}}{{end}} pattern: {{.Pattern}}"},
+		{EventTypeURLs, kindAppliance, header + "urls src={{.SrcAddr}}:{{.SrcPort}} dst={{.DstAddr}}:{{.DstPort}} mac={{.SrcMAC}} agent='{{.UserAgent}}' request: {{.Method}} {{.URL}}"},
+		{EventTypeIDSAlerts, kindAppliance, header + "ids-alerts signature={{.Signature}} priority={{.Priority}} timestamp={{.Timestamp.Unix}}.{{printf \"%06d\" .Microseconds}} dhost={{.DstMAC}} direction={{.Direction}} protocol={{.IDSProtocol}} src={{.SrcAddr}}:{{.SrcPort}} dst={{.DstAddr}}:{{.DstPort}} decision={{.Decision}} message: {{.Message}}"},
+		{EventTypeEvents, kindAppliance, header + "events dhcp lease of ip {{.ClientAddr}} from server mac {{.DeviceMAC}} for client mac {{.SrcMAC}} from router {{.Gateway}} on subnet 255.255.255.0 with dns 8.8.8.8, 8.8.4.4"},
+		{EventTypeEvents, kindAppliance, header + "events type=vpn_connectivity_change vpn_type='site-to-site' peer_contact='{{.DstAddr}}:{{.DstPort}}' peer_ident='{{.PeerIdent}}' connectivity='{{.Connectivity}}'"},
+		{EventTypeEvents, kindWireless, header + "events type=association radio='{{.Radio}}' vap='{{.VAP}}' client_mac='{{.SrcMAC}}' channel='{{.Channel}}' rssi='{{.RSSI}}' aid='{{.AID}}'"},
+		{EventTypeEvents, kindWireless, header + "events type=disassociation radio='{{.Radio}}' vap='{{.VAP}}' client_mac='{{.SrcMAC}}' channel='{{.Channel}}' reason='{{.Reason}}' instigator='{{.Instigator}}' duration='{{.Duration}}' aid='{{.AID}}'"},
+		{EventTypeEvents, kindWireless, header + "events type=wpa_auth radio='{{.Radio}}' vap='{{.VAP}}' client_mac='{{.SrcMAC}}' aid='{{.AID}}'"},
+		{EventTypeEvents, kindSwitch, header + "events port {{.Port}} status changed from {{.FromStatus}} to {{.ToStatus}}"},
+	}
+	protocols  = [...]string{"tcp", "udp", "icmp"}
+	patterns   = [...]string{"allow all", "allow all", "allow all", "deny all", "0 (dst 10.0.0.0/8)", "1 (dst port 445)"}
+	methods    = [...]string{"GET", "GET", "GET", "POST", "PUT", "CONNECT"}
+	signatures = [...]struct {
+		id, protocol, message string
+		priority              int
+	}{
+		{"1:41978:5", "tcp/ip", "SERVER-WEBAPP Apache Struts remote code execution attempt", 1},
+		{"1:2008578:7", "tcp/ip", "ET SCAN Sipvicious Scan", 2},
+		{"1:44687:3", "tcp/ip", "SERVER-OTHER Microsoft Windows SMBv1 remote code execution attempt", 1},
+		{"129:4:1", "tcp/ip", "TCP Timestamp is outside of PAWS window", 3},
+		{"1:2402000:5782", "udp/ip", "ET DROP Dshield Block Listed Source group 1", 2},
+		{"1:1418:18", "udp/ip", "PROTOCOL-SNMP request tcp", 3},
+	}
+	decisions   = [...]string{"allowed", "blocked"}
+	reasons     = [...]string{"1", "3", "8", "23"}
+	instigators = [...]string{"0", "2", "3"}
+	statuses    = [...]string{"down", "100fdx", "1Gfdx", "10Gfdx"}
+)
+
+type device struct {
+	name, serial, network, model, kind string
+	mac                                string
+	lan                                *net.IPNet
+	gateway                            net.IP
+}
+
+// Meraki holds the random fields for a Meraki syslog message.
+type Meraki struct {
+	AID          int
+	Channel      int
+	ClientAddr   net.IP
+	Connectivity bool
+	Decision     string
+	Device       string
+	DeviceMAC    string
+	Direction    string
+	DstAddr      net.IP
+	DstMAC       string
+	DstPort      int
+	Duration     string
+	FromStatus   string
+	Gateway      net.IP
+	ICMPType     int
+	IDSProtocol  string
+	Message      string
+	Method       string
+	Microseconds int
+	Pattern      string
+	PeerIdent    string
+	Port         int
+	Priority     int
+	Protocol     string
+	Radio        int
+	Reason       string
+	Instigator   string
+	RSSI         int
+	Signature    string
+	SrcAddr      net.IP
+	SrcMAC       string
+	SrcPort      int
+	Timestamp    time.Time
+	ToStatus     string
+	URL          string
+	UserAgent    string
+	VAP          int
+
+	eventType string
+	hostname  string
+	clients   *random.MACPool
+	rand      *rand.Rand
+	clock     *generator.Clock
+	pins      *generator.Pins
+	devices   map[string][]device
+	templates map[string][]*template.Template
+	kinds     map[*template.Template]string
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Meraki objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Meraki{
+		eventType: c.EventType,
+		hostname:  c.Hostname,
+		rand:      r,
+		clock:     clock,
+		devices:   make(map[string][]device),
+		templates: make(map[string][]*template.Template),
+		kinds:     make(map[*template.Template]string),
+	}
+	if len(c.ClientVendors) == 0 {
+		c.ClientVendors = defaultClientVendors
+	}
+	// Validate checked the vendors.
+	m.clients, _ = random.NewMACPool("client_vendors", c.ClientVendors)
+	m.pins, err = generator.NewPins(cfg, r, m)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Networks) == 0 {
+		c.Networks = defaultNetworks
+	}
+	serials := c.Serials
+	for i, network := range c.Networks {
+		// The LAN of a network is a /24 of 10.0.0.0/8, its MX is .1.
+		lan := &net.IPNet{IP: net.IPv4(10, byte(i/256), byte(i%256), 0), Mask: net.CIDRMask(24, 32)}
+		gateway := net.IPv4(10, byte(i/256), byte(i%256), 1)
+		for _, model := range models {
+			d := device{network: network, model: model.model, kind: model.kind, lan: lan, gateway: gateway}
+			if len(serials) > 0 {
+				d.serial, serials = serials[0], serials[1:]
+			} else {
+				d.serial = serial(r)
+			}
+			suffix := d.serial
+			if len(suffix) > 4 {
+				suffix = suffix[len(suffix)-4:]
+			}
+			d.name = fmt.Sprintf("%s_%s_%s", network, model.model, suffix)
+			mac, _ := random.VendorMAC(r, "meraki")
+			d.mac = mac.String()
+			m.devices[d.kind] = append(m.devices[d.kind], d)
+		}
+	}
+
+	for i, v := range templates {
+		t, err := template.New(fmt.Sprintf("%s-%d", v.eventType, i)).Funcs(generator.FunctionMap).Parse(v.text)
+		if err != nil {
+			return nil, err
+		}
+		m.templates[v.eventType] = append(m.templates[v.eventType], t)
+		m.kinds[t] = v.kind
+	}
+
+	return m, nil
+}
+
+// serial returns a random Meraki serial, such as Q2PN-4XKD-9UAB.
+func serial(r *rand.Rand) string {
+	const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	b := []byte("Q2XX-XXXX-XXXX")
+	for i := 2; i < len(b); i++ {
+		if b[i] != '-' {
+			b[i] = alphabet[r.IntN(len(alphabet))]
+		}
+	}
+	return string(b)
+}
+
+// Next produces the next Meraki syslog message.
+//
+// Example:
+//
+// <134>1 1709553600.123456789 HQ_MX84_9UAB flows src=10.0.0.23 dst=142.250.179.196 mac=0C:8D:DB:12:34:56 protocol=tcp sport=51234 dport=443 pattern: allow all
+func (m *Meraki) Next() ([]byte, error) {
+	var buf bytes.Buffer
+
+	eventType := m.eventType
+	if eventType == "" {
+		eventType = eventTypes[m.rand.IntN(len(eventTypes))]
+	}
+	templates := m.templates[eventType]
+	t := templates[m.rand.IntN(len(templates))]
+	devices := m.devices[m.kinds[t]]
+	m.randomize(devices[m.rand.IntN(len(devices))])
+
+	if err := t.Execute(&buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// randomize sets the fields of a message of the device d.
+func (m *Meraki) randomize(d device) {
+	m.Timestamp = m.clock.Now()
+	m.Microseconds = m.Timestamp.Nanosecond() / 1000
+	m.Device = d.name
+	if m.hostname == HostnameSerial {
+		m.Device = d.serial
+	}
+	m.DeviceMAC = strings.ToLower(d.mac)
+
+	lan := random.IPv4FromCIDR(m.rand, d.lan)
+	wan := random.IPv4(m.rand)
+	m.ClientAddr = lan
+	m.Gateway = d.gateway
+	m.SrcAddr, m.DstAddr = lan, wan
+	m.SrcPort = random.Port(m.rand)
+	m.DstPort = [...]int{443, 443, 80, 53, 123, 445, 22}[m.rand.IntN(7)]
+	m.Direction = "egress"
+	if m.rand.IntN(3) == 0 {
+		// Inbound traffic of an alert.
+		m.SrcAddr, m.DstAddr, m.Direction = wan, lan, "ingress"
+	}
+	m.SrcMAC = strings.ToUpper(m.clients.MAC(m.rand).String())
+	m.DstMAC = strings.ToUpper(random.MAC(m.rand).String())
+
+	m.Protocol = protocols[m.rand.IntN(len(protocols))]
+	m.ICMPType = [...]int{0, 3, 8, 11}[m.rand.IntN(4)]
+	m.Pattern = patterns[m.rand.IntN(len(patterns))]
+
+	m.Method = methods[m.rand.IntN(len(methods))]
+	m.URL = random.URL(m.rand)
+	if m.DstPort == 80 {
+		m.URL = "http" + strings.TrimPrefix(m.URL, "https")
+	}
+	m.UserAgent = random.UserAgent(m.rand)
+
+	s := signatures[m.rand.IntN(len(signatures))]
+	m.Signature, m.IDSProtocol, m.Message, m.Priority = s.id, s.protocol, s.message, s.priority
+	m.Decision = decisions[m.rand.IntN(len(decisions))]
+
+	m.PeerIdent = random.Hex(m.rand, 16)
+	m.Connectivity = m.rand.IntN(4) != 0
+
+	m.Radio = m.rand.IntN(2)
+	m.VAP = m.rand.IntN(4)
+	if m.Radio == 0 {
+		m.Channel = [...]int{1, 6, 11}[m.rand.IntN(3)]
+	} else {
+		m.Channel = [...]int{36, 44, 100, 149}[m.rand.IntN(4)]
+	}
+	m.RSSI = m.rand.IntN(60) + 5
+	m.AID = m.rand.IntN(2147483647)
+	m.Reason = reasons[m.rand.IntN(len(reasons))]
+	m.Instigator = instigators[m.rand.IntN(len(instigators))]
+	m.Duration = fmt.Sprintf("%.1f", m.rand.Float64()*36000)
+
+	m.Port = m.rand.IntN(48) + 1
+	m.FromStatus, m.ToStatus = statuses[0], statuses[1+m.rand.IntN(len(statuses)-1)]
+	if m.rand.IntN(2) == 0 {
+		m.FromStatus, m.ToStatus = m.ToStatus, m.FromStatus
+	}
+
+	m.pins.Apply(m)
+}
+
+// Templates returns the templates of the generator, for generator.Lint.
+func (m *Meraki) Templates() (interface{}, []*template.Template) {
+	var templates []*template.Template
+	for _, t := range m.templates {
+		templates = append(templates, t...)
+	}
+	return m, templates
+}
@@ -0,0 +1,71 @@
+package meraki
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMeraki(t *testing.T, c map[string]interface{}) generator.Generator {
+	t.Helper()
+	cfg, err := ucfg.NewFrom(c)
+	assert.Nil(t, err)
+	g, err := New(cfg)
+	assert.Nil(t, err)
+	return g
+}
+
+func TestNext(t *testing.T) {
+	g := newMeraki(t, map[string]interface{}{"type": Name, "seed": 1, "networks": []string{"Store-0042"}, "serials": []string{"Q2PN-4XKD-9UAB", "Q3AC-7WJR-LM2Z"}})
+	header := regexp.MustCompile(`^<134>1 \d{10}\.\d{9} (Store-0042_(MX84|MR46|MR36|MS225-48)_[0-9A-Z]{4}) (flows|urls|ids-alerts|events) `)
+	seen := map[string]int{}
+	for i := 0; i < 500; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		m := header.FindStringSubmatch(string(got))
+		if !assert.NotNil(t, m, string(got)) {
+			continue
+		}
+		seen[m[3]]++
+		switch {
+		case m[3] != EventTypeEvents || strings.Contains(string(got), "dhcp lease") || strings.Contains(string(got), "vpn_type"):
+			assert.Equal(t, "MX84", m[2], string(got))
+		case strings.Contains(string(got), "events port"):
+			assert.Equal(t, "MS225-48", m[2], string(got))
+		default:
+			assert.Contains(t, []string{"MR46", "MR36"}, m[2], string(got))
+		}
+		if m[2] == "MX84" {
+			assert.Equal(t, "Store-0042_MX84_9UAB", m[1])
+		}
+	}
+	assert.Len(t, seen, len(eventTypes))
+}
+
+func TestSerials(t *testing.T) {
+	g := newMeraki(t, map[string]interface{}{"type": Name, "event_type": EventTypeFlows, "networks": []string{"HQ"}, "serials": []string{"Q2PN-4XKD-9UAB"}, "hostname": HostnameSerial})
+	for i := 0; i < 10; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		assert.Regexp(t, `^<134>1 \d+\.\d+ Q2PN-4XKD-9UAB flows (src=10\.0\.0\.\d+ |src=\S+ dst=10\.0\.0\.\d+ )`, string(got))
+	}
+}
+
+func TestClientVendors(t *testing.T) {
+	g := newMeraki(t, map[string]interface{}{"type": Name, "seed": 1, "event_type": EventTypeFlows, "client_vendors": []string{"raspberrypi"}})
+	for i := 0; i < 20; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		assert.Regexp(t, ` mac=(B8:27:EB|DC:A6:32|E4:5F:01):`, string(got))
+	}
+}
+
+func TestLint(t *testing.T) {
+	issues, err := generator.Lint(newMeraki(t, map[string]interface{}{"type": Name}), 100)
+	assert.Nil(t, err)
+	assert.Empty(t, issues)
+}
@@ -1,16 +1,49 @@
 // Package cef implements the generator for Citrix CEF logs.
 //
+// The header and extension values are escaped as the ArcSight CEF
+// specification requires: a backslash and a pipe in the header, a
+// backslash, an equal sign and line breaks in the extension.  For
+// testing how parsers handle malformed messages, a share of the
+// messages can be written without escaping, with a value that breaks
+// the format.
+//
+// Configuration:
+//
+//	unescaped: (number, optional) The percentage of messages that are
+//	           not escaped, from 0 (the default) to 100.
+//	ipv6: (number, optional) The percentage of the appliance and client
+//	      addresses that are IPv6, from 0 (the default) to 100.
+//	src_cidrs: (list, optional) The networks of the client addresses.
+//	dst_cidrs: (list, optional) The networks of the appliance
+//	           addresses.
+//	header: (string, optional) The syslog header before "CEF:", none,
+//	        rfc3164 or rfc5424.  By default the header of the
+//	        appliance, "Jan 2 15:04:05 <local0.info> 10.1.2.3 ".
+//	user_agents: (map, optional) The weights of the kinds of user agents
+//	             of the clients, browser, mobile, bot and cli.  When set
+//	             the messages have the user agent of the request in
+//	             requestClientApplication.
+//
 //	generator:
 //	  type: citrix:cef
+//	  unescaped: 5
+//	  src_cidrs: ["198.51.100.0/24", "203.0.113.0/24"]
+//	  dst_cidrs: ["10.1.0.0/16"]
+//	  header: rfc5424
+//	  user_agents:
+//	    browser: 70
+//	    bot: 20
+//	    cli: 10
 package cef
 
 import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
-	"math/rand"
+	"math/rand/v2"
 	"net"
 	"strconv"
+	"strings"
 	"text/template"
 	"time"
 
@@ -27,7 +60,7 @@ import (
 const Name = "citrix:cef"
 
 var (
-	tmpl         = `{{.Timestamp.Format .TimeLayout}} <{{.Facility}}.{{.Priority}}> {{.Addr}} CEF:{{.CEFVersion}}|{{.Vendor}}|{{.Product}}|{{.Version}}|{{.Module}}|{{.Violation}}|{{.Severity}}|src={{.SrcAddr}} {{with .Geo}}geolocation={{.}} {{end}}spt={{.SrcPort}} method={{.Method}} request={{.Request}} msg={{.Message}} cn1={{.EventID}} cn2={{.TxID}} cs1={{.Profile}} cs2={{.PPEID}} cs3={{.SessID}} cs4={{.SeverityLabel}} cs5={{.Year}} {{with .ViolationCategory}}cs6={{.}} {{end}}act={{.Action}}`
+	tmpl         = `{{if .Header}}{{template "cef_syslog_header" (dict "Header" .Header "Priority" .SyslogPriority "Timestamp" .Timestamp "Host" .Addr "AppName" "CEF")}}{{else}}{{.Timestamp.Format .TimeLayout}} <{{.Facility}}.{{.Priority}}> {{.Addr}} {{end}}{{template "cef_header" (dict "CEFVersion" .CEFVersion "Vendor" .Vendor "Product" .Product "Version" .Version "SignatureID" .Module "Name" .Violation "Severity" .Severity)}}src={{.SrcAddr}} {{with .Geo}}geolocation={{.}} {{end}}spt={{.SrcPort}} method={{.Method}} request={{.Request}} {{with .UserAgent}}requestClientApplication={{.}} {{end}}msg={{.Message}} cn1={{.EventID}} cn2={{.TxID}} cs1={{.Profile}} cs2={{.PPEID}} cs3={{.SessID}} cs4={{.SeverityLabel}} cs5={{.Year}} {{with .ViolationCategory}}cs6={{.}} {{end}}act={{.Action}}`
 	msgTemplates = []string{
 		tmpl,
 	}
@@ -41,6 +74,13 @@ var (
 	priorities = []string{
 		"debug", "info", "notice", "warning", "warn", "err", "error", "crit", "alert", "emerg", "panic",
 	}
+	// facilityCodes are the codes of the keywords of the facilities,
+	// for the syslog priority of the header option.  mark is internal
+	// to syslogd, its messages are sent with the syslog facility.
+	facilityCodes = map[string]int{
+		"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "mark": 5,
+		"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+	}
 	vendors = []string{
 		"Citrix",
 	}
@@ -62,7 +102,6 @@ var (
 		"APPFW_STARTURL",
 	}
 	locations = []string{
-		"",
 		"Unknown",
 		"NorthAmerica.Altimoria.Corvax.CityCenter.*.*",
 		"NorthAmerica.Florensia.Novath.TremorValley.*.*",
@@ -215,14 +254,13 @@ var (
 	methods = []string{
 		"GET", "POST",
 	}
-	requests = []string{
-		`http://aaron.stratum8.net/FFC/login.html`,
-		`http://aaron.stratum8.net/FFC/login.php?login_name=abc&passwd=123456789234&drinking_pref=on&text_area=&loginButton=ClickToLogin&as_sfid=AAAAAAWIahZuYoIFbjBhYMP05mJLTwEfIY0a7AKGMg3jIBaKmwtK4t7M7lNxOgj7Gmd3SZc8KUj6CR6a7W5kIWDRHN8PtK1Zc-txHkHNx1WknuG9DzTuM7t1THhluevXu9I4kp8%3D&as_fid=feeec8758b41740eedeeb6b35b85dfd3d5def30c`,
-		`http://aaron.stratum8.net/FFC/wwwboard/passwd.txt`,
-		`http://aaron.stratum8.net/FFC/CreditCardMind.html`,
-		`http://vpx247.example.net/FFC/CreditCardMind.html`,
-		`http://vpx247.example.net/FFC/login_post.html?abc\=def`,
-		`http://vpx247.example.net/FFC/wwwboard/passwd.txt`,
+	// requests are the URLs of the FFC sample application, protected by
+	// the app firewall profile pr_ffc.
+	requests = random.Wordlist{
+		Domains: []string{"aaron.stratum8.net", "vpx247.example.net"},
+		Paths:   []string{"FFC/login.html", "FFC/login.php", "FFC/login_post.html", "FFC/wwwboard/passwd.txt", "FFC/CreditCardMind.html"},
+		Params:  []string{"login_name", "passwd", "drinking_pref", "text_area", "loginButton", "as_sfid", "as_fid"},
+		Scheme:  "http",
 	}
 	messages = []string{
 		"Signature violation rule ID 807: web-cgi /wwwboard/passwd.txt access",
@@ -238,22 +276,29 @@ var (
 		"INFO", "ALERT",
 	}
 	violationCategory = []string{
-		"",
 		"web-cgi",
 		"sql-injection",
 		"phishing",
 	}
+	// optional are the default percentages of events the fields
+	// are empty in.
+	optional = map[string]float64{"Geo": 1, "ViolationCategory": 25}
+
 	actions = []string{
 		"blocked", "not blocked", "transformed",
 	}
+
+	headerEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+	extensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r\n", `\n`, "\n", `\n`, "\r", `\r`)
 )
 
 type CEF struct {
 	Timestamp  time.Time
 	TimeLayout string
 
-	Facility string
-	Priority string
+	Facility       string
+	Priority       string
+	SyslogPriority int
 
 	Addr net.IP
 
@@ -270,6 +315,7 @@ type CEF struct {
 	SrcPort           int
 	Method            string
 	Request           string
+	UserAgent         string
 	Message           string
 	EventID           int
 	TxID              int
@@ -281,7 +327,16 @@ type CEF struct {
 	ViolationCategory string
 	Action            string
 
-	templates []*template.Template
+	header     string
+	rand       *rand.Rand
+	clock      *generator.Clock
+	pins       *generator.Pins
+	unescaped  float64
+	ipv6       float64
+	src        *random.Pool
+	dst        *random.Pool
+	userAgents *random.UserAgents
+	templates  []*template.Template
 }
 
 func init() {
@@ -295,11 +350,40 @@ func New(cfg *ucfg.Config) (generator.Generator, error) {
 		return nil, err
 	}
 
-	c := &CEF{}
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := generator.NewHeader(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &CEF{header: header, rand: r, clock: clock, unescaped: def.Unescaped, ipv6: def.IPv6}
+	// Validate checked the networks.
+	c.src, _ = random.NewPool("src_cidrs", def.SrcCIDRs)
+	c.dst, _ = random.NewPool("dst_cidrs", def.DstCIDRs)
+	if def.UserAgents != nil {
+		c.userAgents = random.NewUserAgents(def.UserAgents)
+	}
+
+	c.pins, err = generator.NewPins(cfg, r, c)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.pins.Optional(optional); err != nil {
+		return nil, err
+	}
+
 	c.randomize()
 
 	for i, v := range msgTemplates {
-		t, err := template.New(strconv.Itoa(i)).Funcs(generator.FunctionMap).Parse(v)
+		t, err := generator.NewTemplate(strconv.Itoa(i), v)
 		if err != nil {
 			return nil, err
 		}
@@ -313,7 +397,12 @@ func New(cfg *ucfg.Config) (generator.Generator, error) {
 func (c *CEF) Next() ([]byte, error) {
 	var buf bytes.Buffer
 
-	err := c.templates[rand.Intn(len(c.templates))].Execute(&buf, c)
+	t := c.templates[c.rand.IntN(len(c.templates))]
+	e := c.escaped()
+	if c.unescaped > 0 && c.rand.Float64()*100 < c.unescaped {
+		e = c.malformed()
+	}
+	err := t.Execute(&buf, e)
 	if err != nil {
 		return nil, err
 	}
@@ -324,41 +413,89 @@ func (c *CEF) Next() ([]byte, error) {
 }
 
 func (c *CEF) randomize() {
-	c.Timestamp = time.Now()
-	c.TimeLayout = randString(timeLayouts)
-
-	c.Facility = randString(facilities)
-	c.Priority = randString(priorities)
-
-	c.Addr = random.IPv4()
-
-	c.CEFVersion = rand.Intn(2)
-	c.Vendor = randString(vendors)
-	c.Product = randString(products)
-	c.Version = randString(versions)
-	c.Module = randString(modules)
-	c.Violation = randString(violations)
-	c.Severity = rand.Intn(10) + 1
-
-	c.SrcAddr = random.IPv4()
-	c.Geo = randString(locations)
-	c.SrcPort = random.Port()
-	c.Method = randString(methods)
-	c.Request = randString(requests)
-	c.Message = randString(messages)
-	c.EventID = rand.Intn(1000)
-	c.TxID = rand.Intn(100000)
-	c.Profile = randString(profiles)
-	c.PPEID = fmt.Sprintf("PPE%d", rand.Intn(9)+1)
+	c.Timestamp = c.clock.Now()
+	c.TimeLayout = randString(c.rand, timeLayouts)
+
+	c.Facility = randString(c.rand, facilities)
+	c.Priority = randString(c.rand, priorities)
+
+	c.Addr = c.dst.IP(c.rand, c.ipv6)
+
+	c.CEFVersion = c.rand.IntN(2)
+	c.Vendor = randString(c.rand, vendors)
+	c.Product = randString(c.rand, products)
+	c.Version = randString(c.rand, versions)
+	c.Module = randString(c.rand, modules)
+	c.Violation = randString(c.rand, violations)
+	c.Severity = c.rand.IntN(10) + 1
+
+	c.SrcAddr = c.src.IP(c.rand, c.ipv6)
+	c.Geo = randString(c.rand, locations)
+	c.SrcPort = random.Port(c.rand)
+	c.Method = randString(c.rand, methods)
+	c.Request = requests.URL(c.rand)
+	if c.userAgents != nil {
+		c.UserAgent = c.userAgents.UserAgent(c.rand)
+	}
+	c.Message = randString(c.rand, messages)
+	c.EventID = c.rand.IntN(1000)
+	c.TxID = c.rand.IntN(100000)
+	c.Profile = randString(c.rand, profiles)
+	c.PPEID = fmt.Sprintf("PPE%d", c.rand.IntN(9)+1)
 	sessID := make([]byte, 16)
-	rand.Read(sessID)
+	random.Read(c.rand, sessID)
 	c.SessID = hex.EncodeToString(sessID)
-	c.SeverityLabel = randString(severityLabels)
+	c.SeverityLabel = randString(c.rand, severityLabels)
 	c.Year = c.Timestamp.Year()
-	c.ViolationCategory = randString(violationCategory)
-	c.Action = randString(actions)
+	c.ViolationCategory = randString(c.rand, violationCategory)
+	c.Action = randString(c.rand, actions)
+
+	c.pins.Apply(c)
+	// The priorities are all levels of syslog.
+	severity, _ := generator.LevelSeverity(c.Priority)
+	c.SyslogPriority = generator.Priority(facilityCodes[c.Facility], severity)
+}
+
+// escaped returns a copy of c with the header and extension values
+// escaped.
+func (c *CEF) escaped() *CEF {
+	e := *c
+	for _, v := range []*string{&e.Vendor, &e.Product, &e.Version, &e.Module, &e.Violation} {
+		*v = headerEscaper.Replace(*v)
+	}
+	for _, v := range []*string{&e.Geo, &e.Method, &e.Request, &e.UserAgent, &e.Message, &e.Profile, &e.PPEID, &e.SessID, &e.SeverityLabel, &e.ViolationCategory, &e.Action} {
+		*v = extensionEscaper.Replace(*v)
+	}
+	return &e
+}
+
+// malformed returns a copy of c that is not escaped, with a pipe in
+// the header or an equal sign or a trailing backslash in an extension
+// value, so that the message breaks the format even if the values had
+// nothing to escape.
+func (c *CEF) malformed() *CEF {
+	e := *c
+	switch c.rand.IntN(3) {
+	case 0:
+		e.Violation += "|APPFW_XSS"
+	case 1:
+		e.Message += " for field passwd=***"
+	default:
+		e.Request += `\`
+	}
+	return &e
+}
+
+// Header returns the header option, for the cef_syslog_header partial.
+func (c *CEF) Header() string {
+	return c.header
+}
+
+func randString(r *rand.Rand, s []string) string {
+	return s[r.IntN(len(s))]
 }
 
-func randString(s []string) string {
-	return s[rand.Intn(len(s))]
+// Templates returns the templates of the generator, for generator.Lint.
+func (c *CEF) Templates() (interface{}, []*template.Template) {
+	return c, c.templates
 }
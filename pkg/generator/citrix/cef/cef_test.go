@@ -1,12 +1,18 @@
 package cef
 
 import (
-	"math/rand"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
 	"testing"
 	"text/template"
 	"time"
 
+	"github.com/elastic/go-ucfg"
 	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestNext(t *testing.T) {
@@ -14,23 +20,24 @@ func TestNext(t *testing.T) {
 		seed int64
 		want string
 	}{
-		{seed: 1, want: `Jan 2 03:04:05 <mark.emerg> 118.9.14.112 CEF:1|Citrix|NetScalar|NS10.0|APPFW|APPFW_FIELDCONSISTENCY|5|src=95.181.74.208 geolocation=NorthAmerica.US.Arizona.Tucson.*.* spt=24561 method=GET request=http://vpx247.example.net/FFC/CreditCardMind.html msg=Disallow Illegal URL. cn1=445 cn2=23237 cs1=pr_ffc cs2=PPE3 cs3=448615bbda08313f6a8eb668d20bf505 cs4=ALERT cs5=2022 cs6=phishing act=not blocked`},
-		{seed: 3, want: `Jan 02 03:04:05 <local5.error> 244.161.164.196 CEF:1|Citrix|NetScalar|NS11.0|APPFW|APPFW_SAFECOMMERCE_XFORM|7|src=157.155.176.203 geolocation=Unknown spt=29735 method=GET request=http://aaron.stratum8.net/FFC/wwwboard/passwd.txt msg=Field consistency check failed for field passwd cn1=278 cn2=29074 cs1=pr_ffc cs2=PPE4 cs3=06d37841b74bcbbdf8987a19dcddc8e9 cs4=ALERT cs5=2022 cs6=web-cgi act=blocked`},
-		{seed: 4, want: `Jan 2 03:04:05 <local4.error> 63.132.159.242 CEF:1|Citrix|NetScalar|NS11.0|APPFW|APPFW_SAFECOMMERCE|8|src=201.132.96.184 spt=25717 method=GET request=http://aaron.stratum8.net/FFC/wwwboard/passwd.txt msg=Field consistency check failed for field passwd cn1=586 cn2=78840 cs1=pr_ffc cs2=PPE8 cs3=ab6d79345fe5e99adf9ddd3d1dbfe5db cs4=INFO cs5=2022 cs6=sql-injection act=transformed`},
+		{seed: 1, want: `Jan 2 03:04:05 <local7.emerg> 177.211.6.105 CEF:1|Citrix|NetScalar|NS10.0|APPFW|APPFW_SAFECOMMERCE|5|src=155.49.27.78 geolocation=Africa.Xyronia.Valtheris.Sunridge.*.* spt=26917 method=GET request=http://aaron.stratum8.net/FFC/CreditCardMind.html msg=Signature violation rule ID 807: web-cgi /wwwboard/passwd.txt access cn1=305 cn2=10114 cs1=pr_ffc cs2=PPE4 cs3=f3426a702ce974cd99cf8ce0573fd3d3 cs4=ALERT cs5=1970 cs6=phishing act=not blocked`},
+		{seed: 3, want: `Jan 2 03:04:05 <local6.crit> 228.134.30.67 CEF:1|Citrix|NetScalar|NS10.0|APPFW|APPFW_SAFECOMMERCE|2|src=53.119.33.48 geolocation=SouthAmerica.Vyxoria.Zandros.Shadowvale.*.* spt=27706 method=GET request=http://aaron.stratum8.net/FFC/login.php msg=Transformed (xout) potential credit card numbers seen in server response cn1=329 cn2=76939 cs1=pr_ffc cs2=PPE1 cs3=46b3b014b741f1ba0a6d2d810932056d cs4=ALERT cs5=1970 cs6=web-cgi act=not blocked`},
+		{seed: 4, want: `Jan 2 03:04:05 <mark.info> 34.15.43.50 CEF:0|Citrix|NetScalar|NS10.0|APPFW|APPFW_STARTURL|1|src=77.3.20.21 geolocation=NorthAmerica.Zeltria.Orex.Shadowridge.*.* spt=31442 method=POST request=http://vpx247.example.net/FFC/login.php msg=Maximum number of potential credit card numbers seen cn1=776 cn2=1800 cs1=pr_ffc cs2=PPE2 cs3=d7810bf03bf751682812e64c717a8337 cs4=ALERT cs5=1970 cs6=phishing act=not blocked`},
 	}
 	now, err := time.Parse(time.RFC3339, "1970-01-02T03:04:05Z")
 	if err != nil {
 		t.Fatal(err)
 	}
-	templ, err := template.New("cef").Funcs(generator.FunctionMap).Parse(tmpl)
+	templ, err := generator.NewTemplate("cef", tmpl)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 	c := &CEF{templates: []*template.Template{templ}}
 	for _, test := range tests {
-		rand.Seed(test.seed)
+		c.rand = random.NewRand(&test.seed)
 		c.randomize()
 		c.Timestamp = now
+		c.Year = now.Year()
 		got, err := c.Next()
 		if err != nil {
 			t.Errorf("unexpected error for c.Next() with seed=%d: %v", err, test.seed)
@@ -40,3 +47,127 @@ func TestNext(t *testing.T) {
 		}
 	}
 }
+
+func newCEF(t *testing.T, c map[string]interface{}) *CEF {
+	t.Helper()
+	cfg, err := ucfg.NewFrom(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g.(*CEF)
+}
+
+func TestEscaped(t *testing.T) {
+	c := newCEF(t, map[string]interface{}{"type": Name, "seed": 1})
+	c.Violation = `APPFW|SIG\1`
+	c.Request = `http://vpx247.example.net/login?user=a\b`
+	c.Message = "line1\r\nline2\nline3"
+
+	e := c.escaped()
+	assert.Equal(t, `APPFW\|SIG\\1`, e.Violation)
+	assert.Equal(t, `http://vpx247.example.net/login?user\=a\\b`, e.Request)
+	assert.Equal(t, `line1\nline2\nline3`, e.Message)
+	assert.Equal(t, `http://vpx247.example.net/login?user=a\b`, c.Request, "the values of c are not changed")
+
+	got, err := c.Next()
+	assert.Nil(t, err)
+	assert.Contains(t, string(got), `|APPFW\|SIG\\1|`)
+	assert.Contains(t, string(got), ` request=http://vpx247.example.net/login?user\=a\\b `)
+	assert.Contains(t, string(got), ` msg=line1\nline2\nline3 `)
+}
+
+func TestUnescaped(t *testing.T) {
+	tests := map[string]struct {
+		unescaped float64
+		want      int
+	}{
+		"None": {unescaped: 0, want: 0},
+		"All":  {unescaped: 100, want: 100},
+	}
+	for name, tc := range tests {
+		c := newCEF(t, map[string]interface{}{"type": Name, "seed": 1, "unescaped": tc.unescaped})
+		n := 0
+		for i := 0; i < 100; i++ {
+			got, err := c.Next()
+			assert.Nil(t, err, name)
+			if malformed(string(got)) {
+				n++
+			}
+		}
+		assert.Equal(t, tc.want, n, name)
+	}
+}
+
+// malformed reports whether line has one of the values CEF.malformed
+// adds.
+func malformed(line string) bool {
+	return strings.Contains(line, "|APPFW_XSS|") || strings.Contains(line, " passwd=*** ") || strings.Contains(line, `\ msg=`)
+}
+
+func TestIPv6(t *testing.T) {
+	c := newCEF(t, map[string]interface{}{"type": Name, "seed": 1, "ipv6": 100})
+	for i := 0; i < 100; i++ {
+		_, err := c.Next()
+		assert.Nil(t, err)
+		assert.Nil(t, c.Addr.To4(), c.Addr)
+		assert.Nil(t, c.SrcAddr.To4(), c.SrcAddr)
+		assert.True(t, c.SrcAddr.IsGlobalUnicast(), c.SrcAddr)
+	}
+}
+
+func TestCIDRs(t *testing.T) {
+	c := newCEF(t, map[string]interface{}{"type": Name, "seed": 1, "src_cidrs": []interface{}{"2001:db8::/32"}, "dst_cidrs": []interface{}{"10.1.0.0/16"}})
+	_, src, _ := net.ParseCIDR("2001:db8::/32")
+	_, dst, _ := net.ParseCIDR("10.1.0.0/16")
+	for i := 0; i < 100; i++ {
+		assert.True(t, src.Contains(c.SrcAddr), c.SrcAddr)
+		assert.True(t, dst.Contains(c.Addr), c.Addr)
+		want := " " + c.Addr.String() + " CEF:"
+		got, err := c.Next()
+		assert.Nil(t, err)
+		assert.Contains(t, string(got), want)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	tests := map[string]struct {
+		header string
+		want   *regexp.Regexp
+	}{
+		"default": {want: regexp.MustCompile(`^[A-Z][a-z]{2} \d{1,2} \d{2}:\d{2}:\d{2} <[a-z0-9]+\.[a-z]+> \S+ CEF:[01]\|`)},
+		"none":    {header: "none", want: regexp.MustCompile(`^CEF:[01]\|`)},
+		"rfc3164": {header: "rfc3164", want: regexp.MustCompile(`^<\d{1,3}>[A-Z][a-z]{2} [ 1-3]\d \d{2}:\d{2}:\d{2} \S+ CEF:[01]\|`)},
+		"rfc5424": {header: "rfc5424", want: regexp.MustCompile(`^<\d{1,3}>1 \d{4}-\d{2}-\d{2}T\S+ \S+ CEF - - CEF:[01]\|`)},
+	}
+	for name, tc := range tests {
+		c := newCEF(t, map[string]interface{}{"type": Name, "seed": 1, "header": tc.header})
+		for i := 0; i < 20; i++ {
+			pri := c.SyslogPriority
+			got, err := c.Next()
+			assert.Nil(t, err, name)
+			assert.Regexp(t, tc.want, string(got), name)
+			if tc.header == "rfc3164" || tc.header == "rfc5424" {
+				assert.True(t, strings.HasPrefix(string(got), fmt.Sprintf("<%d>", pri)), name)
+			}
+		}
+	}
+}
+
+func TestUserAgents(t *testing.T) {
+	c := newCEF(t, map[string]interface{}{"type": Name, "seed": 1})
+	got, err := c.Next()
+	assert.Nil(t, err)
+	assert.NotContains(t, string(got), "requestClientApplication=")
+
+	c = newCEF(t, map[string]interface{}{"type": Name, "seed": 1, "user_agents": map[string]interface{}{"cli": 1}})
+	for i := 0; i < 20; i++ {
+		ua := c.UserAgent
+		got, err := c.Next()
+		assert.Nil(t, err)
+		assert.Contains(t, string(got), " requestClientApplication="+ua+" msg=")
+	}
+}
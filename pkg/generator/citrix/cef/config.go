@@ -1,9 +1,18 @@
 package cef
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/leehinman/spigot/pkg/random"
+)
 
 type config struct {
-	Type string `config:"type" validate:"required"`
+	Type       string             `config:"type" validate:"required"`
+	Unescaped  float64            `config:"unescaped"`
+	IPv6       float64            `config:"ipv6"`
+	SrcCIDRs   []string           `config:"src_cidrs"`
+	DstCIDRs   []string           `config:"dst_cidrs"`
+	UserAgents map[string]float64 `config:"user_agents"`
 }
 
 func defaultConfig() config {
@@ -16,5 +25,17 @@ func (c *config) Validate() error {
 	if c.Type != Name {
 		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
 	}
-	return nil
+	if c.Unescaped < 0 || c.Unescaped > 100 {
+		return fmt.Errorf("'%v' is not a valid value for 'unescaped' expected a percentage from 0 to 100", c.Unescaped)
+	}
+	if c.IPv6 < 0 || c.IPv6 > 100 {
+		return fmt.Errorf("'%v' is not a valid value for 'ipv6' expected a percentage from 0 to 100", c.IPv6)
+	}
+	if _, err := random.NewPool("src_cidrs", c.SrcCIDRs); err != nil {
+		return err
+	}
+	if _, err := random.NewPool("dst_cidrs", c.DstCIDRs); err != nil {
+		return err
+	}
+	return random.ValidUserAgentWeights("user_agents", c.UserAgents)
 }
@@ -23,6 +23,41 @@ func TestConfigs(t *testing.T) {
 			hasError:    true,
 			errorString: "'Bob' is not a valid value for 'type' expected 'citrix:cef' accessing config",
 		},
+		"Valid Unescaped": {
+			c:           map[string]interface{}{"type": Name, "unescaped": 5},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Unescaped": {
+			c:           map[string]interface{}{"type": Name, "unescaped": 101},
+			hasError:    true,
+			errorString: "'101' is not a valid value for 'unescaped' expected a percentage from 0 to 100 accessing config",
+		},
+		"Invalid Dst CIDRs": {
+			c:           map[string]interface{}{"type": Name, "dst_cidrs": []interface{}{"10.1.0.0/33"}},
+			hasError:    true,
+			errorString: "'10.1.0.0/33' is not a valid value for 'dst_cidrs' expected a CIDR such as 10.0.0.0/8 accessing config",
+		},
+		"Invalid IPv6": {
+			c:           map[string]interface{}{"type": Name, "ipv6": -1},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'ipv6' expected a percentage from 0 to 100 accessing config",
+		},
+		"Invalid Header": {
+			c:           map[string]interface{}{"type": Name, "header": "citrix"},
+			hasError:    true,
+			errorString: "'citrix' is not a valid value for 'header' expected 'none, rfc3164, rfc5424' accessing config",
+		},
+		"Valid User Agents": {
+			c:           map[string]interface{}{"type": Name, "user_agents": map[string]interface{}{"browser": 3, "bot": 1}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid User Agents": {
+			c:           map[string]interface{}{"type": Name, "user_agents": map[string]interface{}{"tv": 1}},
+			hasError:    true,
+			errorString: "'tv' is not a valid value for 'user_agents' expected 'browser, mobile, bot, cli' accessing config",
+		},
 		"No Type": {
 			c:           map[string]interface{}{"type": ""},
 			hasError:    true,
@@ -13,7 +13,7 @@ package clf
 import (
 	"bytes"
 	"fmt"
-	"math/rand"
+	"math/rand/v2"
 	"net"
 	"strconv"
 	"text/template"
@@ -51,6 +51,9 @@ type Generator struct {
 	Record Record
 
 	tmpl       *template.Template
+	rand       *rand.Rand
+	clock      *generator.Clock
+	pins       *generator.Pins
 	staticTime *time.Time
 	buf        bytes.Buffer
 	combined   bool
@@ -73,29 +76,31 @@ func (g *Generator) Next() ([]byte, error) {
 }
 
 func (g *Generator) randomize() {
-	now := time.Now()
+	now := g.clock.Now()
 	if g.staticTime != nil {
 		now = *g.staticTime
 	}
 
 	g.Record = Record{
-		Host:     random.IPv4(),
+		Host:     random.IPv4(g.rand),
 		Ident:    "-",
 		AuthUser: "-",
 		Date:     now.Format(timestampFmt),
 		Request: fmt.Sprintf(
 			`"%s %s %s"`,
-			random.HTTPMethod(),
-			fmt.Sprintf("/random-%d.html", rand.Intn(100)),
-			random.HTTPVersion(),
+			random.HTTPMethod(g.rand),
+			fmt.Sprintf("/random-%d.html", g.rand.IntN(100)),
+			random.HTTPVersion(g.rand),
 		),
-		Status: strconv.Itoa(random.HTTPStatus()),
-		Bytes:  strconv.Itoa(rand.Intn(10000)),
+		Status: strconv.Itoa(random.HTTPStatus(g.rand)),
+		Bytes:  strconv.Itoa(g.rand.IntN(10000)),
 	}
 	if g.combined {
 		g.Record.Referer = "-"
-		g.Record.UserAgent = `"` + random.UserAgent() + `"`
+		g.Record.UserAgent = `"` + random.UserAgent(g.rand) + `"`
 	}
+
+	g.pins.Apply(&g.Record)
 }
 
 // New is the factory for Common Log Format objects.
@@ -107,8 +112,24 @@ func New(cfg *ucfg.Config) (generator.Generator, error) {
 		return nil, err
 	}
 
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	g := Generator{
 		combined: c.Combined,
+		rand:     r,
+		clock:    clock,
+	}
+
+	g.pins, err = generator.NewPins(cfg, r, &g.Record)
+	if err != nil {
+		return nil, err
 	}
 
 	if g.combined {
@@ -128,3 +149,8 @@ func init() {
 		panic(err)
 	}
 }
+
+// Templates returns the templates of the generator, which are executed with the Record, for generator.Lint.
+func (g *Generator) Templates() (interface{}, []*template.Template) {
+	return &g.Record, []*template.Template{g.tmpl}
+}
@@ -1,11 +1,11 @@
 package clf
 
 import (
-	"math/rand"
 	"testing"
 	"time"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,11 +16,11 @@ func TestGenerator_Next(t *testing.T) {
 	}{
 		"common": {
 			config:   map[string]interface{}{"combined": false},
-			expected: `66.4.203.154 - - [02/Jan/1970:03:04:05 +0700] "GET /random-47.html HTTP/2" 200 1318`,
+			expected: `86.181.142.224 - - [02/Jan/1970:03:04:05 +0700] "GET /random-99.html HTTP/2" 413 52`,
 		},
 		"combined": {
 			config:   map[string]interface{}{"combined": true},
-			expected: `66.4.203.154 - - [02/Jan/1970:03:04:05 +0700] "GET /random-47.html HTTP/2" 200 1318 - "Mozilla/5.0 (Macintosh; Intel Mac OS X 12.3; rv:98.0) Gecko/20100101 Firefox/98.0"`,
+			expected: `86.181.142.224 - - [02/Jan/1970:03:04:05 +0700] "GET /random-99.html HTTP/2" 413 52 - "Mozilla/5.0 (iPad; CPU OS 15_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.3 Mobile/15E148 Safari/604.1"`,
 		},
 	}
 
@@ -30,7 +30,7 @@ func TestGenerator_Next(t *testing.T) {
 	for name, tc := range tests {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
-			rand.Seed(1)
+			random.Seed(1)
 
 			g, err := New(ucfg.MustNewFrom(tc.config))
 			assert.NoError(t, err)
@@ -0,0 +1,304 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+)
+
+// Precisions of the "time" option.
+const (
+	PrecisionSecond      = "s"
+	PrecisionMillisecond = "ms"
+	PrecisionMicrosecond = "us"
+	PrecisionNanosecond  = "ns"
+)
+
+var (
+	precisions = [...]string{PrecisionSecond, PrecisionMillisecond, PrecisionMicrosecond, PrecisionNanosecond}
+	units      = map[string]time.Duration{
+		PrecisionSecond:      time.Second,
+		PrecisionMillisecond: time.Millisecond,
+		PrecisionMicrosecond: time.Microsecond,
+		PrecisionNanosecond:  time.Nanosecond,
+	}
+	// digits are the digits of the fraction of the seconds of a unit.
+	digits = map[time.Duration]int{time.Second: 0, time.Millisecond: 3, time.Microsecond: 6, time.Nanosecond: 9}
+	// seconds matches the seconds of a layout and their fraction.
+	seconds = regexp.MustCompile(`05([.,][09]+)?`)
+)
+
+type clockConfig struct {
+	Timestamp *timestampConfig `config:"timestamp"`
+	Time      *timeConfig      `config:"time"`
+}
+
+type timeConfig struct {
+	Precision string `config:"precision"`
+	Epoch     bool   `config:"epoch"`
+}
+
+func (c *timeConfig) Validate() error {
+	if _, ok := units[c.Precision]; c.Precision != "" && !ok {
+		return fmt.Errorf("'%s' is not a valid value for 'precision' expected '%s'", c.Precision, strings.Join(precisions[:], ", "))
+	}
+	return nil
+}
+
+type timestampConfig struct {
+	Start           string        `config:"start"`
+	End             string        `config:"end"`
+	Interval        time.Duration `config:"interval"`
+	EventsPerSecond float64       `config:"events_per_second"`
+	Scenario        string        `config:"scenario"`
+	Timezone        string        `config:"timezone"`
+	Window          time.Duration `config:"window"`
+}
+
+func (c *timestampConfig) Validate() error {
+	if c.Start == "" && c.Scenario == "" {
+		return fmt.Errorf("you must specify start or scenario")
+	}
+	start, err := time.Parse(time.RFC3339Nano, c.Start)
+	if err != nil && c.Start != "" {
+		return fmt.Errorf("'%s' is not a valid value for 'start' expected an RFC 3339 time", c.Start)
+	}
+	if c.Scenario != "" && !contains(scenarios[:], c.Scenario) {
+		return fmt.Errorf("'%s' is not a valid value for 'scenario' expected '%s'", c.Scenario, strings.Join(scenarios[:], ", "))
+	}
+	if c.Scenario != "" && c.End != "" {
+		return fmt.Errorf("only one of end and scenario can be set")
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return fmt.Errorf("'%s' is not a valid value for 'timezone' expected an IANA time zone", c.Timezone)
+	}
+	if c.Window < 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'window' expected 0 or more", c.Window)
+	}
+	if c.End != "" {
+		end, err := time.Parse(time.RFC3339Nano, c.End)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a valid value for 'end' expected an RFC 3339 time", c.End)
+		}
+		if end.Before(start) {
+			return fmt.Errorf("'%s' is not a valid value for 'end' expected a time after '%s'", c.End, c.Start)
+		}
+	}
+	if c.Interval < 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'interval' expected 0 or more", c.Interval)
+	}
+	if c.EventsPerSecond < 0 {
+		return fmt.Errorf("'%v' is not a valid value for 'events_per_second' expected 0 or more", c.EventsPerSecond)
+	}
+	if c.Interval == 0 && c.EventsPerSecond == 0 {
+		return fmt.Errorf("you must specify interval or events_per_second")
+	}
+	if c.Interval != 0 && c.EventsPerSecond != 0 {
+		return fmt.Errorf("only one of interval and events_per_second can be set")
+	}
+	return nil
+}
+
+// Clock is the time of the events of a generator.  Without the
+// "timestamp" option it is the current time.  With it the time walks
+// forward from start by a fixed step for every event, to backfill a
+// historical window:
+//
+//	generator:
+//	  type: cisco:asa
+//	  timestamp:
+//	    start: "2024-03-01T00:00:00Z"
+//	    end: "2024-03-15T00:00:00Z"
+//	    events_per_second: 2
+//
+// The step is the interval, a Go duration, or one second divided by
+// events_per_second.  The runner stops after the event at end, see
+// Events.  The times are in the IANA timezone, by default in the zone
+// of start.
+//
+// A scenario instead backfills the window, by default an hour, before
+// and after a boundary where timestamp parsing usually breaks, the
+// last one before now, or the first one after start.  At dst_start,
+// the start of daylight saving time in the timezone, the local times
+// skip an hour, at dst_end they happen twice.  year_end is midnight of
+// new year in the timezone, for the formats without a year, and at
+// leap_second the clock repeats 23:59:59, as systems do that step
+// their clock back:
+//
+//	generator:
+//	  type: cisco:asa
+//	  timestamp:
+//	    scenario: dst_end
+//	    timezone: Europe/Amsterdam
+//	    interval: 10s
+//
+// The "time" option sets the precision of the timestamps, s, ms, us or
+// ns, and with epoch writes them as the number of those units since
+// the Unix epoch, seconds without a precision:
+//
+//	generator:
+//	  type: system:metrics
+//	  time:
+//	    precision: ms
+//	    epoch: true
+//
+// The times of Now are truncated to the precision, and generators
+// write their timestamps with Format, or with Layout where the format
+// of the event needs a formatted time.
+type Clock struct {
+	backfill bool
+	start    time.Time
+	step     time.Duration
+	events   int64
+	n        int64
+	unit     time.Duration
+	epoch    bool
+	loc      *time.Location
+	leap     time.Time
+}
+
+// NewClock returns the Clock of the "timestamp" option in the
+// ucfg.Config.  Generators call its Now once for every event and
+// derive the other times of the event from it.
+func NewClock(cfg *ucfg.Config) (*Clock, error) {
+	c := clockConfig{}
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	clock := &Clock{}
+	if c.Time != nil {
+		clock.unit, clock.epoch = units[c.Time.Precision], c.Time.Epoch
+		if clock.epoch && clock.unit == 0 {
+			clock.unit = time.Second
+		}
+	}
+	if c.Timestamp == nil {
+		return clock, nil
+	}
+	// Validate checked the times and the time zone.
+	start, _ := time.Parse(time.RFC3339Nano, c.Timestamp.Start)
+	end, _ := time.Parse(time.RFC3339Nano, c.Timestamp.End)
+	if c.Timestamp.Timezone != "" {
+		clock.loc, _ = time.LoadLocation(c.Timestamp.Timezone)
+		start, end = start.In(clock.loc), end.In(clock.loc)
+	}
+	if c.Timestamp.Scenario != "" {
+		loc := clock.loc
+		if loc == nil {
+			loc = time.UTC
+		}
+		boundary, err := scenarioBoundary(c.Timestamp.Scenario, loc, start)
+		if err != nil {
+			return nil, err
+		}
+		if c.Timestamp.Scenario == ScenarioLeapSecond {
+			clock.leap = boundary
+		}
+		window := c.Timestamp.Window
+		if window == 0 {
+			window = time.Hour
+		}
+		start, end = boundary.Add(-window).In(loc), boundary.Add(window).In(loc)
+	}
+	step := c.Timestamp.Interval
+	if c.Timestamp.EventsPerSecond > 0 {
+		step = time.Duration(float64(time.Second) / c.Timestamp.EventsPerSecond)
+	}
+	if step <= 0 {
+		step = time.Nanosecond
+	}
+	clock.backfill, clock.start, clock.step = true, start, step
+	if !end.IsZero() {
+		clock.events = int64(end.Sub(start)/step) + 1
+	}
+	return clock, nil
+}
+
+// Now returns the time of the next event.  A nil Clock is the
+// current time.
+func (c *Clock) Now() time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	t := time.Now()
+	if c.backfill {
+		t = c.start.Add(time.Duration(c.n) * c.step)
+		c.n++
+		if !c.leap.IsZero() && !t.Before(c.leap) {
+			// The clock is a second behind from the leap second on.
+			t = t.Add(-time.Second)
+		}
+	}
+	if c.loc != nil {
+		t = t.In(c.loc)
+	}
+	if c.unit > 0 {
+		t = t.Truncate(c.unit)
+	}
+	return t
+}
+
+// Format returns t formatted with the layout at the precision of the
+// "time" option, or the number of units since the epoch with epoch.
+// A nil Clock formats t with the layout.
+func (c *Clock) Format(t time.Time, layout string) string {
+	if c.IsEpoch() {
+		return strconv.FormatInt(c.Epoch(t), 10)
+	}
+	return t.Format(c.Layout(layout))
+}
+
+// Epoch returns the number of units of the precision since the Unix
+// epoch of t, seconds without a precision.
+func (c *Clock) Epoch(t time.Time) int64 {
+	if c == nil || c.unit == 0 {
+		return t.Unix()
+	}
+	return t.UnixNano() / int64(c.unit)
+}
+
+// Value returns the timestamp of t of a JSON document, the number of
+// Epoch with epoch, or t formatted with Format.
+func (c *Clock) Value(t time.Time, layout string) interface{} {
+	if c.IsEpoch() {
+		return c.Epoch(t)
+	}
+	return c.Format(t, layout)
+}
+
+// IsEpoch reports whether the timestamps are numbers since the epoch.
+func (c *Clock) IsEpoch() bool {
+	return c != nil && c.epoch
+}
+
+// Layout returns the layout with the fraction of its seconds at the
+// precision of the "time" option, the layout itself without one or
+// without seconds.
+func (c *Clock) Layout(layout string) string {
+	loc := seconds.FindStringIndex(layout)
+	if c == nil || c.unit == 0 || loc == nil {
+		return layout
+	}
+	sep := "."
+	if loc[1]-loc[0] > 2 {
+		sep = layout[loc[0]+2 : loc[0]+3]
+	}
+	fraction := ""
+	if n := digits[c.unit]; n > 0 {
+		fraction = sep + strings.Repeat("0", n)
+	}
+	return layout[:loc[0]] + "05" + fraction + layout[loc[1]:]
+}
+
+// Events returns the number of events from start to end, 0 without an
+// end or the "timestamp" option.
+func (c *Clock) Events() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.events
+}
@@ -0,0 +1,166 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		c      map[string]interface{}
+		times  []time.Time
+		events int64
+	}{
+		"Interval": {
+			c:      map[string]interface{}{"start": "2024-03-01T00:00:00Z", "end": "2024-03-01T00:05:00Z", "interval": "1m"},
+			times:  []time.Time{start, start.Add(time.Minute), start.Add(2 * time.Minute)},
+			events: 6,
+		},
+		"Events Per Second": {
+			c:      map[string]interface{}{"start": "2024-03-01T00:00:00Z", "end": "2024-03-02T00:00:00Z", "events_per_second": 4},
+			times:  []time.Time{start, start.Add(250 * time.Millisecond), start.Add(500 * time.Millisecond)},
+			events: 86400*4 + 1,
+		},
+		"No End": {
+			c:     map[string]interface{}{"start": "2024-03-01T01:00:00+01:00", "interval": "1h"},
+			times: []time.Time{start, start.Add(time.Hour), start.Add(2 * time.Hour)},
+		},
+	}
+	for name, tc := range tests {
+		cfg, err := ucfg.NewFrom(map[string]interface{}{"type": "test", "timestamp": tc.c})
+		assert.Nil(t, err, name)
+		clock, err := NewClock(cfg)
+		assert.Nil(t, err, name)
+		for _, want := range tc.times {
+			assert.True(t, want.Equal(clock.Now()), name)
+		}
+		assert.Equal(t, tc.events, clock.Events(), name)
+	}
+}
+
+func TestClockNow(t *testing.T) {
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": "test"})
+	assert.Nil(t, err)
+	clock, err := NewClock(cfg)
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now(), clock.Now(), time.Minute)
+	assert.Equal(t, int64(0), clock.Events())
+
+	var none *Clock
+	assert.WithinDuration(t, time.Now(), none.Now(), time.Minute)
+}
+
+func TestClockConfig(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		errorString string
+	}{
+		"No Start": {
+			c:           map[string]interface{}{"interval": "1s"},
+			errorString: "you must specify start or scenario accessing 'timestamp'",
+		},
+		"Invalid Start": {
+			c:           map[string]interface{}{"start": "yesterday", "interval": "1s"},
+			errorString: "'yesterday' is not a valid value for 'start' expected an RFC 3339 time accessing 'timestamp'",
+		},
+		"Invalid End": {
+			c:           map[string]interface{}{"start": "2024-03-01T00:00:00Z", "end": "2024-03-02", "interval": "1s"},
+			errorString: "'2024-03-02' is not a valid value for 'end' expected an RFC 3339 time accessing 'timestamp'",
+		},
+		"End Before Start": {
+			c:           map[string]interface{}{"start": "2024-03-01T00:00:00Z", "end": "2024-02-01T00:00:00Z", "interval": "1s"},
+			errorString: "'2024-02-01T00:00:00Z' is not a valid value for 'end' expected a time after '2024-03-01T00:00:00Z' accessing 'timestamp'",
+		},
+		"Negative Interval": {
+			c:           map[string]interface{}{"start": "2024-03-01T00:00:00Z", "interval": "-1s"},
+			errorString: "'-1s' is not a valid value for 'interval' expected 0 or more accessing 'timestamp'",
+		},
+		"Negative Events Per Second": {
+			c:           map[string]interface{}{"start": "2024-03-01T00:00:00Z", "events_per_second": -2},
+			errorString: "'-2' is not a valid value for 'events_per_second' expected 0 or more accessing 'timestamp'",
+		},
+		"No Step": {
+			c:           map[string]interface{}{"start": "2024-03-01T00:00:00Z"},
+			errorString: "you must specify interval or events_per_second accessing 'timestamp'",
+		},
+		"Both Steps": {
+			c:           map[string]interface{}{"start": "2024-03-01T00:00:00Z", "interval": "1s", "events_per_second": 10},
+			errorString: "only one of interval and events_per_second can be set accessing 'timestamp'",
+		},
+	}
+	for name, tc := range tests {
+		cfg, err := ucfg.NewFrom(map[string]interface{}{"type": "test", "timestamp": tc.c})
+		assert.Nil(t, err, name)
+		_, err = NewClock(cfg)
+		if assert.NotNil(t, err, name) {
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+	}
+}
+
+func TestClockPrecision(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 30, 15, 123456789, time.UTC)
+	tests := map[string]struct {
+		c      map[string]interface{}
+		layout string
+		want   string
+	}{
+		"Seconds": {
+			c:      map[string]interface{}{"precision": "s"},
+			layout: time.RFC3339Nano,
+			want:   "2024-03-01T12:30:15Z",
+		},
+		"Milliseconds": {
+			c:      map[string]interface{}{"precision": "ms"},
+			layout: time.RFC3339,
+			want:   "2024-03-01T12:30:15.123Z",
+		},
+		"Microseconds": {
+			c:      map[string]interface{}{"precision": "us"},
+			layout: "2006-01-02 15:04:05,000",
+			want:   "2024-03-01 12:30:15,123456",
+		},
+		"Nanoseconds": {
+			c:      map[string]interface{}{"precision": "ns"},
+			layout: time.RFC3339,
+			want:   "2024-03-01T12:30:15.123456789Z",
+		},
+		"Epoch Seconds": {
+			c:      map[string]interface{}{"epoch": true},
+			layout: time.RFC3339,
+			want:   "1709296215",
+		},
+		"Epoch Milliseconds": {
+			c:      map[string]interface{}{"precision": "ms", "epoch": true},
+			layout: time.RFC3339,
+			want:   "1709296215123",
+		},
+		"No Seconds": {
+			c:      map[string]interface{}{"precision": "ms"},
+			layout: "2006-01-02",
+			want:   "2024-03-01",
+		},
+	}
+	for name, tc := range tests {
+		cfg, err := ucfg.NewFrom(map[string]interface{}{"type": "test", "time": tc.c, "timestamp": map[string]interface{}{"start": at.Format(time.RFC3339Nano), "interval": "1s"}})
+		assert.Nil(t, err, name)
+		clock, err := NewClock(cfg)
+		assert.Nil(t, err, name)
+		now := clock.Now()
+		assert.Equal(t, tc.want, clock.Format(now, tc.layout), name)
+		assert.Equal(t, tc.want, clock.Format(at, tc.layout), name)
+	}
+
+	var none *Clock
+	assert.Equal(t, "2024-03-01T12:30:15Z", none.Format(at, time.RFC3339))
+	assert.Equal(t, "2024-03-01T12:30:15.123456789Z", none.Format(at, time.RFC3339Nano))
+
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": "test", "time": map[string]interface{}{"precision": "m"}})
+	assert.Nil(t, err)
+	_, err = NewClock(cfg)
+	assert.EqualError(t, err, "'m' is not a valid value for 'precision' expected 's, ms, us, ns' accessing 'time'")
+}
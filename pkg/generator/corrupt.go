@@ -0,0 +1,173 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+
+	"github.com/elastic/go-ucfg"
+)
+
+// Kinds of the corruption of the "corrupt" option.
+const (
+	CorruptTruncate   = "truncate"
+	CorruptUTF8       = "invalid_utf8"
+	CorruptDelimiters = "swap_delimiters"
+	CorruptFields     = "missing_fields"
+)
+
+var (
+	corruptKinds = []string{CorruptTruncate, CorruptUTF8, CorruptDelimiters, CorruptFields}
+	// delimiters are the field delimiters swap_delimiters and
+	// missing_fields look for, the most common one in an event is its
+	// delimiter.
+	delimiters = []byte{',', '|', '\t', ';', ' '}
+	// invalidUTF8 are byte sequences that are not valid UTF-8: a stray
+	// continuation byte, bytes UTF-8 never uses, an overlong encoding
+	// and sequences cut short.
+	invalidUTF8 = [][]byte{{0x80}, {0xff}, {0xfe, 0xfe}, {0xc0, 0xaf}, {0xc3, 0x28}, {0xe2, 0x28, 0xa1}}
+)
+
+// corruptStream is the stream of the corrupt wrapper, see
+// newWrapperRand.
+const corruptStream = 1
+
+type corruptOptions struct {
+	Corrupt *corruptConfig `config:"corrupt"`
+}
+
+type corruptConfig struct {
+	Percent float64  `config:"percent"`
+	Kinds   []string `config:"kinds"`
+}
+
+func (c *corruptConfig) Validate() error {
+	if c.Percent < 0 || c.Percent > 100 {
+		return fmt.Errorf("'%v' is not a valid value for 'percent' expected a percentage from 0 to 100", c.Percent)
+	}
+	for _, kind := range c.Kinds {
+		if !contains(corruptKinds, kind) {
+			return fmt.Errorf("'%s' is not a valid value for 'kinds' expected '%s'", kind, strings.Join(corruptKinds, ", "))
+		}
+	}
+	return nil
+}
+
+// corruptGenerator corrupts a percentage of the events of a generator,
+// see New.
+type corruptGenerator struct {
+	Generator
+	rand    *rand.Rand
+	percent float64
+	kinds   []string
+}
+
+// newCorrupt returns g corrupted with the "corrupt" option in the
+// ucfg.Config, g itself without the option.
+func newCorrupt(g Generator, cfg *ucfg.Config) (Generator, error) {
+	c := corruptOptions{}
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	if c.Corrupt == nil || c.Corrupt.Percent == 0 {
+		return g, nil
+	}
+	r, err := newWrapperRand(cfg, corruptStream)
+	if err != nil {
+		return nil, err
+	}
+	kinds := c.Corrupt.Kinds
+	if len(kinds) == 0 {
+		kinds = corruptKinds
+	}
+	if IsBinary(g) {
+		kinds = []string{CorruptTruncate}
+	}
+	return &corruptGenerator{Generator: g, rand: r, percent: c.Corrupt.Percent, kinds: kinds}, nil
+}
+
+// Binary reports whether the events of the corrupted generator are
+// binary.
+func (c *corruptGenerator) Binary() bool {
+	return IsBinary(c.Generator)
+}
+
+func (c *corruptGenerator) Next() ([]byte, error) {
+	b, err := c.Generator.Next()
+	if err != nil || len(b) == 0 || c.rand.Float64()*100 >= c.percent {
+		return b, err
+	}
+	return corrupt(c.rand, c.kinds[c.rand.IntN(len(c.kinds))], b), nil
+}
+
+// corrupt returns the event b corrupted the kind of way.  Events
+// without fields to swap or drop are truncated instead.
+func corrupt(r *rand.Rand, kind string, b []byte) []byte {
+	switch kind {
+	case CorruptUTF8:
+		i := r.IntN(len(b) + 1)
+		bad := invalidUTF8[r.IntN(len(invalidUTF8))]
+		out := make([]byte, 0, len(b)+len(bad))
+		out = append(append(append(out, b[:i]...), bad...), b[i:]...)
+		return out
+	case CorruptDelimiters:
+		if d, ok := delimiter(b); ok {
+			others := bytes.Replace(delimiters, []byte{d}, nil, 1)
+			return bytes.ReplaceAll(b, []byte{d}, []byte{others[r.IntN(len(others))]})
+		}
+	case CorruptFields:
+		if out, ok := dropField(r, b); ok {
+			return out
+		}
+	}
+	return b[:r.IntN(len(b))]
+}
+
+// delimiter returns the most common of the delimiters in b.
+func delimiter(b []byte) (byte, bool) {
+	best, n := byte(0), 0
+	for _, d := range delimiters {
+		if c := bytes.Count(b, []byte{d}); c > n {
+			best, n = d, c
+		}
+	}
+	return best, n > 0
+}
+
+// dropField returns b without one of its fields, a key of a JSON
+// object or a field between the delimiters of b.
+func dropField(r *rand.Rand, b []byte) ([]byte, bool) {
+	var m map[string]json.RawMessage
+	if json.Unmarshal(b, &m) == nil {
+		if len(m) == 0 {
+			return nil, false
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		delete(m, keys[r.IntN(len(keys))])
+		out, err := json.Marshal(m)
+		return out, err == nil
+	}
+	d, ok := delimiter(b)
+	if !ok {
+		return nil, false
+	}
+	fields := bytes.Split(b, []byte{d})
+	i := r.IntN(len(fields))
+	return bytes.Join(append(fields[:i:i], fields[i+1:]...), []byte{d}), true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand/v2"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+type csvGenerator struct {
+	event  string
+	binary bool
+}
+
+func (c *csvGenerator) Next() ([]byte, error) {
+	return []byte(c.event), nil
+}
+
+func (c *csvGenerator) Binary() bool {
+	return c.binary
+}
+
+const testEvent = "2024-03-01T00:00:00Z,web-01,GET,/index.html,200,5120"
+
+func corrupted(t *testing.T, g Generator, c map[string]interface{}) Generator {
+	t.Helper()
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": "test", "seed": 1, "corrupt": c})
+	assert.Nil(t, err)
+	g, err = newCorrupt(g, cfg)
+	assert.Nil(t, err)
+	return g
+}
+
+func TestCorrupt(t *testing.T) {
+	g := corrupted(t, &csvGenerator{event: testEvent}, map[string]interface{}{"percent": 10})
+	n := 0
+	for i := 0; i < 10000; i++ {
+		b, err := g.Next()
+		assert.Nil(t, err)
+		if string(b) != testEvent {
+			n++
+		}
+	}
+	assert.InDelta(t, 1000, n, 100)
+}
+
+func TestCorruptStream(t *testing.T) {
+	g := corrupted(t, &csvGenerator{event: testEvent}, map[string]interface{}{"percent": 10})
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": "test", "seed": 1})
+	assert.Nil(t, err)
+	r, err := NewRand(cfg)
+	assert.Nil(t, err)
+	assert.NotEqual(t, r.Uint64(), g.(*corruptGenerator).rand.Uint64())
+}
+
+func TestCorruptKinds(t *testing.T) {
+	tests := map[string]func(t *testing.T, b []byte){
+		CorruptTruncate: func(t *testing.T, b []byte) {
+			assert.Less(t, len(b), len(testEvent))
+			assert.True(t, bytes.HasPrefix([]byte(testEvent), b), string(b))
+		},
+		CorruptUTF8: func(t *testing.T, b []byte) {
+			assert.False(t, utf8.Valid(b), string(b))
+			assert.Greater(t, len(b), len(testEvent))
+		},
+		CorruptDelimiters: func(t *testing.T, b []byte) {
+			assert.NotContains(t, string(b), ",")
+			assert.Len(t, b, len(testEvent))
+		},
+		CorruptFields: func(t *testing.T, b []byte) {
+			assert.Equal(t, 4, bytes.Count(b, []byte(",")), string(b))
+		},
+	}
+	for kind, check := range tests {
+		kind, check := kind, check
+		t.Run(kind, func(t *testing.T) {
+			g := corrupted(t, &csvGenerator{event: testEvent}, map[string]interface{}{"percent": 100, "kinds": []interface{}{kind}})
+			for i := 0; i < 100; i++ {
+				b, err := g.Next()
+				assert.Nil(t, err)
+				check(t, b)
+			}
+		})
+	}
+}
+
+func TestCorruptJSON(t *testing.T) {
+	event := `{"a":1,"b":"two","c":[3]}`
+	g := corrupted(t, &csvGenerator{event: event}, map[string]interface{}{"percent": 100, "kinds": []interface{}{CorruptFields}})
+	for i := 0; i < 100; i++ {
+		b, err := g.Next()
+		assert.Nil(t, err)
+		var m map[string]interface{}
+		assert.Nil(t, json.Unmarshal(b, &m), string(b))
+		assert.Len(t, m, 2)
+	}
+}
+
+func TestCorruptBinary(t *testing.T) {
+	g := corrupted(t, &csvGenerator{event: testEvent, binary: true}, map[string]interface{}{"percent": 100, "kinds": []interface{}{CorruptUTF8}})
+	assert.True(t, IsBinary(g))
+	for i := 0; i < 100; i++ {
+		b, err := g.Next()
+		assert.Nil(t, err)
+		assert.True(t, bytes.HasPrefix([]byte(testEvent), b), string(b))
+	}
+}
+
+func TestCorruptNone(t *testing.T) {
+	csv := &csvGenerator{event: testEvent}
+	g, err := newCorrupt(csv, ucfg.MustNewFrom(map[string]interface{}{"type": "test"}))
+	assert.Nil(t, err)
+	assert.Equal(t, csv, g)
+
+	// Events without delimiters are truncated instead.
+	b := corrupt(rand.New(rand.NewChaCha8([32]byte{1})), CorruptDelimiters, []byte("word"))
+	assert.True(t, bytes.HasPrefix([]byte("word"), b))
+	assert.Less(t, len(b), 4)
+}
+
+func TestCorruptErrors(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		errorString string
+	}{
+		"Invalid Percent": {
+			c:           map[string]interface{}{"percent": 101},
+			errorString: "'101' is not a valid value for 'percent' expected a percentage from 0 to 100 accessing 'corrupt'",
+		},
+		"Invalid Kind": {
+			c:           map[string]interface{}{"percent": 1, "kinds": []interface{}{"shuffle"}},
+			errorString: "'shuffle' is not a valid value for 'kinds' expected 'truncate, invalid_utf8, swap_delimiters, missing_fields' accessing 'corrupt'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			cfg := ucfg.MustNewFrom(map[string]interface{}{"type": "test", "corrupt": tc.c})
+			_, err := newCorrupt(&csvGenerator{}, cfg)
+			assert.EqualError(t, err, tc.errorString)
+		})
+	}
+}
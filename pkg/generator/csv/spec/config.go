@@ -0,0 +1,51 @@
+package spec
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+type config struct {
+	Type        string               `config:"type" validate:"required"`
+	Columns     []random.FieldConfig `config:"columns" validate:"required"`
+	Delimiter   string               `config:"delimiter"`
+	Header      string               `config:"header"`
+	HeaderEvery int                  `config:"header_every"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:      Name,
+		Delimiter: ",",
+		Header:    HeaderFirst,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if r, n := utf8.DecodeRuneInString(c.Delimiter); n == 0 || n != len(c.Delimiter) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+		return fmt.Errorf("'%s' is not a valid value for 'delimiter' expected a character other than a quote or a line break", c.Delimiter)
+	}
+	if c.Header != HeaderFirst && c.Header != HeaderNone {
+		return fmt.Errorf("'%s' is not a valid value for 'header' expected '%s'", c.Header, strings.Join([]string{HeaderFirst, HeaderNone}, ", "))
+	}
+	if c.HeaderEvery < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'header_every' expected a value of 0 or more", c.HeaderEvery)
+	}
+	if c.HeaderEvery > 0 && c.Header == HeaderNone {
+		return fmt.Errorf("'header_every' can not be used with '%s' header", HeaderNone)
+	}
+	names := make(map[string]bool)
+	for _, col := range c.Columns {
+		if names[col.Name] {
+			return fmt.Errorf("'%s' is not a valid value for 'columns.name' expected a unique name", col.Name)
+		}
+		names[col.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	column := []map[string]interface{}{{"name": "a"}}
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name, "columns": column},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid TSV": {
+			c:           map[string]interface{}{"type": Name, "columns": column, "delimiter": "\t", "header_every": 100},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "columns": column},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'csv:spec' accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": "", "columns": column},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+		"No Columns": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    true,
+			errorString: "missing required field accessing 'columns'",
+		},
+		"Invalid Delimiter": {
+			c:           map[string]interface{}{"type": Name, "columns": column, "delimiter": "||"},
+			hasError:    true,
+			errorString: "'||' is not a valid value for 'delimiter' expected a character other than a quote or a line break accessing config",
+		},
+		"Invalid Header": {
+			c:           map[string]interface{}{"type": Name, "columns": column, "header": "always"},
+			hasError:    true,
+			errorString: "'always' is not a valid value for 'header' expected 'first, none' accessing config",
+		},
+		"Header Every Without Header": {
+			c:           map[string]interface{}{"type": Name, "columns": column, "header": "none", "header_every": 10},
+			hasError:    true,
+			errorString: "'header_every' can not be used with 'none' header accessing config",
+		},
+		"Duplicate Column": {
+			c:           map[string]interface{}{"type": Name, "columns": []map[string]interface{}{{"name": "a"}, {"name": "a"}}},
+			hasError:    true,
+			errorString: "'a' is not a valid value for 'columns.name' expected a unique name accessing config",
+		},
+		"Invalid Column Type": {
+			c:           map[string]interface{}{"type": Name, "columns": []map[string]interface{}{{"name": "a", "type": "date"}}},
+			hasError:    true,
+			errorString: "'date' is not a valid value for 'type' expected 'string, integer, number, boolean, timestamp' accessing 'columns.0'",
+		},
+		"Invalid Column Range": {
+			c:           map[string]interface{}{"type": Name, "columns": []map[string]interface{}{{"name": "a", "type": "integer", "min": 10, "max": 1}}},
+			hasError:    true,
+			errorString: "'1' is not a valid value for 'max' expected a value of at least 10 accessing 'columns.0'",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			if err != nil {
+				assert.Equal(t, tc.errorString, err.Error(), name)
+			}
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Package spec generates the rows of CSV and TSV files whose columns
+// are defined in the configuration, for export-style feeds that do
+// not need a generator of their own.
+//
+// Every event is a row, a value of each column, quoted when the value
+// has the delimiter, a quote or a line break in it.  With the default
+// header policy the first event is the header, the names of the
+// columns, which is repeated after every header_every rows.
+//
+// Configuration:
+//
+//	columns:      (list) The columns, in order, with:
+//	                name:   (string) The name of the column.
+//	                type:   (string, optional) string (the default),
+//	                        integer, number, boolean or timestamp, the
+//	                        current time.
+//	                faker:  (string, optional) The faker of the
+//	                        values, such as ipv4, email or user_agent.
+//	                values: (list, optional) The values to pick from.
+//	                min:    (number, optional) The minimum of integers
+//	                        and numbers, 0 by default, or the minimum
+//	                        length of strings of random letters.
+//	                max:    (number, optional) The maximum of integers
+//	                        and numbers, 1000 by default, or the
+//	                        maximum length of strings.
+//	                layout: (string, optional) The Go time layout of
+//	                        timestamps, defaults to RFC 3339.
+//	                empty:  (number, optional) The percentage of rows
+//	                        the value is empty in.
+//	delimiter:    (string, optional) The delimiter of the values,
+//	              defaults to ",".  "\t" writes TSV.
+//	header:       (string, optional) first (the default) writes the
+//	              header as the first event, none writes no header.
+//	header_every: (integer, optional) The number of rows after which
+//	              the header is repeated, 0 (the default) never
+//	              repeats it.
+//
+//	- generator:
+//	    type: "csv:spec"
+//	    delimiter: "\t"
+//	    header_every: 1000
+//	    columns:
+//	      - {name: time, type: timestamp, layout: "2006-01-02 15:04:05"}
+//	      - {name: user, faker: email}
+//	      - {name: client_ip, faker: ipv4}
+//	      - {name: action, values: [download, upload, delete]}
+//	      - {name: bytes, type: integer, min: 100, max: 10000000}
+//	      - {name: comment, faker: sentence, empty: 80}
+package spec
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math/rand/v2"
+	"time"
+	"unicode/utf8"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "csv:spec"
+
+// Header policies of the header option.
+const (
+	HeaderFirst = "first"
+	HeaderNone  = "none"
+)
+
+// Spec generates the rows of the columns of its configuration.
+type Spec struct {
+	rand        *rand.Rand
+	columns     []*random.Field
+	delimiter   rune
+	header      bool
+	headerEvery int
+	rows        int
+	now         func() time.Time
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Spec objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Spec{
+		rand:        r,
+		header:      c.Header == HeaderFirst,
+		headerEvery: c.HeaderEvery,
+		now:         clock.Now,
+	}
+	s.delimiter, _ = utf8.DecodeRuneInString(c.Delimiter)
+	for _, col := range c.Columns {
+		s.columns = append(s.columns, random.NewField(r, col))
+	}
+
+	return s, nil
+}
+
+// Next produces the next row, or the header.
+//
+// Example:
+//
+// 2024-03-04T12:00:00Z,bjansen@example.org,66.4.203.154,upload,4823311,
+func (s *Spec) Next() ([]byte, error) {
+	record := make([]string, len(s.columns))
+	if s.header {
+		s.header = false
+		for i, col := range s.columns {
+			record[i] = col.Name()
+		}
+		return s.encode(record)
+	}
+
+	now := s.now()
+	for i, col := range s.columns {
+		record[i] = col.Value(now)
+	}
+	s.rows++
+	if s.headerEvery > 0 && s.rows%s.headerEvery == 0 {
+		s.header = true
+	}
+	return s.encode(record)
+}
+
+// encode returns the record as a line without the line break.
+func (s *Spec) encode(record []string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = s.delimiter
+	if err := w.Write(record); err != nil {
+		return nil, err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
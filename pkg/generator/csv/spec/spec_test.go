@@ -0,0 +1,116 @@
+package spec
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTime = time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+
+func newSpec(t *testing.T, cfg map[string]interface{}) *Spec {
+	t.Helper()
+	c, err := ucfg.NewFrom(cfg)
+	assert.Nil(t, err)
+	g, err := New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := g.(*Spec)
+	s.now = func() time.Time { return testTime }
+	return s
+}
+
+// parse returns the values of the line, with the delimiter.
+func parse(t *testing.T, line []byte, delimiter rune) []string {
+	t.Helper()
+	r := csv.NewReader(strings.NewReader(string(line)))
+	r.Comma = delimiter
+	record, err := r.Read()
+	if err != nil {
+		t.Fatalf("%v: %q", err, line)
+	}
+	return record
+}
+
+var columns = []map[string]interface{}{
+	{"name": "time", "type": "timestamp", "layout": "2006-01-02 15:04:05"},
+	{"name": "user", "faker": "email"},
+	{"name": "action", "values": []string{"download", "upload"}},
+	{"name": "bytes", "type": "integer", "min": 100, "max": 200},
+	{"name": "ratio", "type": "number", "min": 0, "max": 1},
+	{"name": "ok", "type": "boolean"},
+	{"name": "code", "min": 4, "max": 4},
+	{"name": "note", "values": []string{"a, b", `say "hi"`}},
+}
+
+func TestNext(t *testing.T) {
+	s := newSpec(t, map[string]interface{}{"type": Name, "seed": 1, "columns": columns})
+
+	header, err := s.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "time,user,action,bytes,ratio,ok,code,note", string(header))
+
+	for i := 0; i < 100; i++ {
+		got, err := s.Next()
+		assert.Nil(t, err)
+		record := parse(t, got, ',')
+		if !assert.Len(t, record, len(columns), string(got)) {
+			continue
+		}
+		assert.Equal(t, "2024-03-04 12:00:00", record[0])
+		assert.Regexp(t, `^[a-z]+@[a-z.]+$`, record[1])
+		assert.Contains(t, []string{"download", "upload"}, record[2])
+		assert.Regexp(t, `^(1\d\d|200)$`, record[3])
+		assert.Regexp(t, `^(0(\.\d+)?|1)$`, record[4])
+		assert.Contains(t, []string{"true", "false"}, record[5])
+		assert.Regexp(t, `^[a-z]{4}$`, record[6])
+		assert.Contains(t, []string{"a, b", `say "hi"`}, record[7])
+	}
+}
+
+func TestTSV(t *testing.T) {
+	s := newSpec(t, map[string]interface{}{
+		"type":      Name,
+		"delimiter": "\t",
+		"header":    HeaderNone,
+		"columns": []map[string]interface{}{
+			{"name": "a", "values": []string{"x\ty"}},
+			{"name": "b", "values": []string{"a, b"}},
+		},
+	})
+	got, err := s.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "\"x\ty\"\ta, b", string(got), "values with the delimiter are quoted")
+	assert.Equal(t, []string{"x\ty", "a, b"}, parse(t, got, '\t'))
+}
+
+func TestHeaderEvery(t *testing.T) {
+	s := newSpec(t, map[string]interface{}{"type": Name, "header_every": 2, "columns": []map[string]interface{}{{"name": "n", "type": "integer", "max": 9}}})
+	var headers []int
+	for i := 0; i < 9; i++ {
+		got, err := s.Next()
+		assert.Nil(t, err)
+		if string(got) == "n" {
+			headers = append(headers, i)
+		}
+	}
+	assert.Equal(t, []int{0, 3, 6}, headers)
+}
+
+func TestEmpty(t *testing.T) {
+	s := newSpec(t, map[string]interface{}{"type": Name, "seed": 1, "header": HeaderNone, "columns": []map[string]interface{}{{"name": "a", "faker": "ipv4", "empty": 25}}})
+	empty := 0
+	for i := 0; i < 1000; i++ {
+		got, err := s.Next()
+		assert.Nil(t, err)
+		if len(got) == 0 {
+			empty++
+		}
+	}
+	assert.InDelta(t, 250, empty, 50)
+}
@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/go-ucfg"
+)
+
+// Formats of the "format" option of a generator.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// ECSVersion is the version of the Elastic Common Schema of the
+// documents of the json format.
+const ECSVersion = "8.11.0"
+
+// ECSGenerator is implemented by generators that can produce their
+// events as Elastic Common Schema documents.
+//
+// NextECS generates the next event and returns it as an ECS document.
+// The document contains the same event Next would have generated,
+// with the log message in "message".
+type ECSGenerator interface {
+	Generator
+	NextECS() (mapstr.M, error)
+}
+
+// ECS returns the ECS document with the fields, which are keyed by
+// their dotted ECS names, for example "source.ip".  Fields with a nil
+// value are left out.
+func ECS(fields map[string]interface{}) mapstr.M {
+	doc := mapstr.M{}
+	for k, v := range fields {
+		if v == nil {
+			continue
+		}
+		_, _ = doc.Put(k, v)
+	}
+	_, _ = doc.Put("ecs.version", ECSVersion)
+	return doc
+}
+
+// jsonGenerator writes the ECS documents of an ECSGenerator as JSON,
+// with the @timestamp at the precision of the clock.
+type jsonGenerator struct {
+	ECSGenerator
+	clock *Clock
+}
+
+func (j jsonGenerator) Next() ([]byte, error) {
+	doc, err := j.NextECS()
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := doc["@timestamp"].(time.Time); ok && j.clock.unit > 0 {
+		doc["@timestamp"] = j.clock.Value(t, time.RFC3339Nano)
+	}
+	return json.Marshal(doc)
+}
+
+// newFormat returns the generator for "type", in the format of
+// "format".  Values of "format" other than "text" and "json" are left
+// to the generators with a format option of their own.
+func newFormat(factory Factory, c config, cfg *ucfg.Config) (Generator, error) {
+	switch c.Format {
+	case FormatText:
+		cfg, err := without(cfg, "format")
+		if err != nil {
+			return nil, err
+		}
+		return factory(cfg)
+	case FormatJSON:
+		cfg, err := without(cfg, "format")
+		if err != nil {
+			return nil, err
+		}
+		g, err := factory(cfg)
+		if err != nil {
+			return nil, err
+		}
+		e, ok := g.(ECSGenerator)
+		if !ok {
+			return nil, fmt.Errorf("'%s' format can not be used with '%s'", c.Format, c.Type)
+		}
+		clock, err := NewClock(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return jsonGenerator{e, clock}, nil
+	default:
+		return factory(cfg)
+	}
+}
+
+// without returns a copy of cfg without name.
+func without(cfg *ucfg.Config, name string) (*ucfg.Config, error) {
+	c, err := ucfg.NewFrom(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.Remove(name, -1); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
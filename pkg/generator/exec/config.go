@@ -0,0 +1,25 @@
+package exec
+
+import (
+	"fmt"
+)
+
+type config struct {
+	Type    string                 `config:"type" validate:"required"`
+	Command string                 `config:"command" validate:"required"`
+	Args    []string               `config:"args"`
+	Config  map[string]interface{} `config:"config"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type: Name,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Package exec generates log messages with an external process, so
+// formats can be added without changing spigot.
+//
+// The process is started when the generator is created and spigot
+// talks to it over stdin and stdout, one JSON object per line.  The
+// first request passes the config:
+//
+//	{"method":"init","config":{...}}
+//
+// and each following request asks for the next message:
+//
+//	{"method":"next"}
+//
+// The process replies to every request with one line, either
+// {"message":"..."} or {"error":"..."}.  The reply to init has no
+// message.  The process should exit when stdin is closed.
+//
+// Configuration:
+//
+//	command: Path of the program to run, required.
+//	args: Optional list of arguments.
+//	config: Optional object passed to the program in the init request.
+//
+//	- generator:
+//	    type: exec
+//	    command: /usr/local/bin/acme-gen
+//	    args: ["--format", "audit"]
+//	    config:
+//	      tenant: acme
+package exec
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	osexec "os/exec"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "exec"
+
+type request struct {
+	Method string                 `json:"method"`
+	Config map[string]interface{} `json:"config,omitempty"`
+}
+
+type response struct {
+	Message string `json:"message"`
+	Error   string `json:"error"`
+}
+
+// Exec holds the running process.
+type Exec struct {
+	command string
+	cmd     *osexec.Cmd
+	stdin   io.WriteCloser
+	stdout  *bufio.Reader
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Exec objects.  It starts the process and
+// sends it the init request.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	e := &Exec{
+		command: c.Command,
+		cmd:     osexec.Command(c.Command, c.Args...),
+	}
+
+	stdin, err := e.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	e.stdin = stdin
+	stdout, err := e.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	e.stdout = bufio.NewReader(stdout)
+
+	if err := e.cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	if _, err := e.call(request{Method: "init", Config: c.Config}); err != nil {
+		_ = e.stdin.Close()
+		_ = e.cmd.Wait()
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Next asks the process for the next log message.
+func (e *Exec) Next() ([]byte, error) {
+	msg, err := e.call(request{Method: "next"})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(msg), nil
+}
+
+func (e *Exec) call(req request) (string, error) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	if _, err := e.stdin.Write(append(b, '\n')); err != nil {
+		return "", fmt.Errorf("writing to %s: %w", e.command, err)
+	}
+
+	line, err := e.stdout.ReadBytes('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("%s exited", e.command)
+		}
+		return "", fmt.Errorf("reading from %s: %w", e.command, err)
+	}
+
+	var resp response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return "", fmt.Errorf("%s replied with invalid JSON: %w", e.command, err)
+	}
+	if resp.Error != "" {
+		return "", fmt.Errorf("%s: %s", e.command, resp.Error)
+	}
+
+	return resp.Message, nil
+}
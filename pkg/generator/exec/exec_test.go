@@ -0,0 +1,84 @@
+package exec
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	t.Setenv("SPIGOT_HELPER_PROCESS", "1")
+	c, err := ucfg.NewFrom(map[string]interface{}{
+		"type":    Name,
+		"command": os.Args[0],
+		"args":    []string{"-test.run=TestHelperProcess", "--"},
+		"config":  map[string]interface{}{"prefix": "acme"},
+	})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+
+	for i := 1; i <= 3; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, fmt.Sprintf("acme message %d", i), string(got))
+	}
+
+	_, err = g.Next()
+	assert.EqualError(t, err, os.Args[0]+": no more messages")
+
+	// The process has exited, writing to it or reading from it fails.
+	_, err = g.Next()
+	assert.NotNil(t, err)
+}
+
+func TestInitError(t *testing.T) {
+	t.Setenv("SPIGOT_HELPER_PROCESS", "1")
+	c, err := ucfg.NewFrom(map[string]interface{}{
+		"type":    Name,
+		"command": os.Args[0],
+		"args":    []string{"-test.run=TestHelperProcess", "--"},
+	})
+	assert.Nil(t, err)
+	_, err = New(c)
+	assert.EqualError(t, err, os.Args[0]+": missing prefix")
+}
+
+// TestHelperProcess is the external generator run by the tests.  It
+// replies with three messages and then an error before exiting.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("SPIGOT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	scanner := bufio.NewScanner(os.Stdin)
+	prefix := ""
+	n := 0
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			os.Exit(2)
+		}
+		switch {
+		case req.Method == "init" && req.Config["prefix"] == nil:
+			_ = enc.Encode(response{Error: "missing prefix"})
+			os.Exit(0)
+		case req.Method == "init":
+			prefix = req.Config["prefix"].(string)
+			_ = enc.Encode(response{})
+		case n < 3:
+			n++
+			_ = enc.Encode(response{Message: fmt.Sprintf("%s message %d", prefix, n)})
+		default:
+			_ = enc.Encode(response{Error: "no more messages"})
+			os.Exit(0)
+		}
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,350 @@
+// Package bigip generates F5 BIG-IP logs of the Advanced WAF (ASM)
+// and Local Traffic Manager (LTM) modules.
+//
+// The logs of ASM are the violations of the security policies of the
+// virtual servers, as they are sent by a remote logging profile in the
+// ArcSight CEF format or in the default key-value format, with the
+// violation, the attack type and signature, the support ID and whether
+// the request was blocked.  The logs of LTM are the lines of a request
+// logging profile, in key=value form, of the requests the virtual
+// servers balance to the members of their pools.  Every virtual server
+// has the pool of its index in the pools, an address and a security
+// policy of its own.
+//
+// Configuration:
+//
+//	module:          Specify the module of the logs, or leave blank for
+//	                 random.  Valid values are: asm, ltm.
+//	format:          Specify the format of the ASM logs, or leave blank
+//	                 for random.  Valid values are: cef, kv.
+//	header:          The syslog header before "CEF:", see
+//	                 generator.NewHeader.
+//	hostname:        The host name of the BIG-IP, defaults to
+//	                 bigip1.example.com.
+//	virtual_servers: The full paths of the virtual servers, defaults to
+//	                 /Common/vs_www, /Common/vs_api and /Common/vs_portal.
+//	pools:           The full paths of the pools, defaults to
+//	                 /Common/pool_www, /Common/pool_api and
+//	                 /Common/pool_portal.
+//
+//	- generator:
+//	    type: f5:bigip
+//	    module: asm
+//	    virtual_servers: [/Prod/vs_shop, /Prod/vs_checkout]
+//	    pools: [/Prod/pool_shop, /Prod/pool_checkout]
+package bigip
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"net"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "f5:bigip"
+
+// Modules of the logs.
+const (
+	ModuleASM = "asm"
+	ModuleLTM = "ltm"
+)
+
+// Formats of the ASM logs.
+const (
+	FormatCEF = "cef"
+	FormatKV  = "kv"
+)
+
+const (
+	statusBlocked = "blocked"
+	statusAlerted = "alerted"
+)
+
+var (
+	modules = [...]string{ModuleASM, ModuleLTM}
+	formats = [...]string{FormatCEF, FormatKV}
+
+	defaultVirtualServers = []string{"/Common/vs_www", "/Common/vs_api", "/Common/vs_portal"}
+	defaultPools          = []string{"/Common/pool_www", "/Common/pool_api", "/Common/pool_portal"}
+
+	asmCEF = `{{template "cef_syslog_header" (dict "Header" .Header "Priority" .Priority "Timestamp" .Timestamp "Host" .Hostname "AppName" "ASM")}}` +
+		`{{template "cef_header" (dict "Vendor" "F5" "Product" "ASM" "Version" .Version "SignatureID" .Violation "Name" .AttackType "Severity" .Severity)}}` +
+		`dvchost={{.Hostname}} dvc={{.MgmtAddr}} cs1={{.Policy}} cs1Label=policy_name cs2={{.VirtualServer}} cs2Label=http_class_name deviceCustomDate1={{.PolicyDate.Format "Jan 02 2006 15:04:05"}} deviceCustomDate1Label=policy_apply_date externalId={{.SupportID}} act={{.RequestStatus}} cn1={{.StatusCode}} cn1Label=response_code src={{.ClientAddr}} spt={{.ClientPort}} dst={{.VirtualAddr}} dpt={{.VirtualPort}} requestMethod={{.Method}} app={{.Protocol}} cs5=N/A cs5Label=x_forwarded_for_header_value rt={{.Timestamp.Format "Jan 02 2006 15:04:05"}} deviceExternalId=0 cs4={{.AttackType}} cs4Label=attack_type cs6={{.Geo}} cs6Label=geo_location cn2={{.Rating}} cn2Label=violation_rating suid={{.SessionID}} suser=N/A request={{.URI}}`
+	asmKV = `{{template "syslog_header" (dict "Priority" .Priority "Timestamp" .Timestamp "Host" .Hostname)}}ASM:` +
+		`unit_hostname="{{.Hostname}}",management_ip_address="{{.MgmtAddr}}",http_class_name="{{.VirtualServer}}",web_application_name="{{.Policy}}",policy_name="{{.Policy}}",policy_apply_date="{{.PolicyDate.Format "2006-01-02 15:04:05"}}",violations="{{.Violation}}",support_id="{{.SupportID}}",request_status="{{.RequestStatus}}",response_code="{{.StatusCode}}",ip_client="{{.ClientAddr}}",route_domain="0",method="{{.Method}}",protocol="{{.Protocol}}",query_string="{{.Query}}",x_forwarded_for_header_value="N/A",sig_ids="{{with .SigID}}{{.}}{{end}}",sig_names="{{.SigName}}",date_time="{{.Timestamp.Format "2006-01-02 15:04:05"}}",severity="{{.SeverityName}}",attack_type="{{.AttackType}}",geo_location="{{.Geo}}",ip_address_intelligence="N/A",username="N/A",session_id="{{.SessionID}}",src_port="{{.ClientPort}}",dest_port="{{.VirtualPort}}",dest_ip="{{.VirtualAddr}}",sub_violations="",virus_name="N/A",violation_rating="{{.Rating}}",uri="{{.URI}}",request="{{.Method}} {{.URI}}{{with .Query}}?{{.}}{{end}} HTTP/1.1\r\nHost: {{.Domain}}\r\nUser-Agent: {{.UserAgent}}\r\n"`
+	ltm = `{{template "syslog_header" (dict "Priority" .Priority "Timestamp" .Timestamp "Host" .Hostname)}}ltm_request: ` +
+		`client_ip={{.ClientAddr}} client_port={{.ClientPort}} virtual={{.VirtualServer}} virtual_ip={{.VirtualAddr}} virtual_port={{.VirtualPort}} pool={{.Pool}} server={{.Member}}:{{.MemberPort}} method={{.Method}} uri={{.URI}}{{with .Query}}?{{.}}{{end}} host={{.Domain}} status={{.StatusCode}} bytes={{.ResponseSize}} duration_ms={{.Duration}} user_agent="{{.UserAgent}}"`
+
+	// violations are the violations of the policies, weighted by how
+	// often they occur, with the rating of their requests.  The
+	// requests of a rating of 4 or more are blocked.
+	violations = [...]violation{
+		{"Attack signature detected", "SQL-Injection", 200002147, "SQL-INJ UNION SELECT (Parameter)", 5, 10},
+		{"Attack signature detected", "Cross Site Scripting (XSS)", 200001475, "XSS script tag end (Parameter)", 4, 10},
+		{"Attack signature detected", "Path Traversal", 200007003, "Directory traversal ../ (URI)", 4, 5},
+		{"Attack signature detected", "Command Execution", 200003041, "/etc/passwd access (URI)", 5, 3},
+		{"Illegal file type", "Forceful Browsing", 0, "", 3, 8},
+		{"Illegal meta character in value", "Parameter Tampering", 0, "", 2, 12},
+		{"HTTP protocol compliance failed", "HTTP Parser Attack", 0, "", 3, 6},
+		{"Illegal method", "Abuse of Functionality", 0, "", 2, 4},
+		{"Illegal URL length", "Buffer Overflow", 0, "", 3, 2},
+	}
+	// severities are the names of the severities of the ratings.
+	severities = [...]string{"Informational", "Informational", "Notice", "Warning", "Error", "Critical"}
+	versions   = [...]string{"15.1.10", "16.1.4", "17.1.1"}
+	geos       = [...]string{"N/A", "US", "DE", "NL", "GB", "CN", "RU", "BR"}
+	paths      = [...]string{"/", "/index.html", "/login.php", "/api/v1/orders", "/api/v1/users", "/search", "/static/app.js", "/cart/checkout", "/admin/config.php"}
+	methods    = [...]string{"GET", "GET", "GET", "POST", "POST", "PUT", "DELETE", "HEAD"}
+	statuses   = [...]int{200, 200, 200, 200, 201, 204, 301, 302, 304, 400, 401, 403, 404, 500, 502, 503}
+)
+
+type violation struct {
+	name, attackType string
+	sigID            int
+	sigName          string
+	rating, weight   int
+}
+
+type virtualServer struct {
+	name, pool, policy, domain string
+	addr                       net.IP
+	members                    []net.IP
+}
+
+// BigIP holds the random fields for a BIG-IP log.
+type BigIP struct {
+	AttackType    string
+	ClientAddr    net.IP
+	ClientPort    int
+	Domain        string
+	Duration      int
+	Geo           string
+	Hostname      string
+	Member        net.IP
+	MemberPort    int
+	Method        string
+	MgmtAddr      net.IP
+	Policy        string
+	PolicyDate    time.Time
+	Pool          string
+	Priority      int
+	Protocol      string
+	Query         string
+	Rating        int
+	RequestStatus string
+	ResponseSize  int
+	SessionID     string
+	Severity      int
+	SeverityName  string
+	SigID         int
+	SigName       string
+	StatusCode    int
+	SupportID     uint64
+	Timestamp     time.Time
+	URI           string
+	UserAgent     string
+	Version       string
+	Violation     string
+	VirtualAddr   net.IP
+	VirtualPort   int
+	VirtualServer string
+
+	header    string
+	module    string
+	format    string
+	rand      *rand.Rand
+	clock     *generator.Clock
+	pins      *generator.Pins
+	servers   []virtualServer
+	templates map[string]*template.Template
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for BigIP objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+	header, err := generator.NewHeader(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &BigIP{
+		header:    header,
+		Hostname:  c.Hostname,
+		MgmtAddr:  net.IPv4(192, 168, 1, byte(200+r.IntN(50))),
+		Version:   versions[r.IntN(len(versions))],
+		module:    c.Module,
+		format:    c.Format,
+		rand:      r,
+		clock:     clock,
+		templates: make(map[string]*template.Template),
+	}
+	b.pins, err = generator.NewPins(cfg, r, b)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.VirtualServers) == 0 {
+		c.VirtualServers = defaultVirtualServers
+	}
+	if len(c.Pools) == 0 {
+		c.Pools = defaultPools
+	}
+	// The policies were applied some days before the logs.
+	b.PolicyDate = clock.Now().Add(-time.Duration(1+r.IntN(90)) * 24 * time.Hour).Truncate(time.Second)
+	for i, name := range c.VirtualServers {
+		v := virtualServer{
+			name:   name,
+			pool:   c.Pools[i%len(c.Pools)],
+			policy: path.Dir(name) + "/asm_" + strings.TrimPrefix(path.Base(name), "vs_"),
+			domain: random.Domain(r),
+			addr:   random.IPv4(r),
+		}
+		for m := 0; m < 2+r.IntN(3); m++ {
+			v.members = append(v.members, net.IPv4(10, 1, byte(i%len(c.Pools)), byte(10+m)))
+		}
+		b.servers = append(b.servers, v)
+	}
+
+	for k, v := range map[string]string{FormatCEF: asmCEF, FormatKV: asmKV, ModuleLTM: ltm} {
+		t, err := generator.NewTemplate(k, v)
+		if err != nil {
+			return nil, err
+		}
+		b.templates[k] = t
+	}
+
+	return b, nil
+}
+
+// Next produces the next BIG-IP log.
+//
+// Example:
+//
+// <134>Mar  4 12:00:00 bigip1.example.com ltm_request: client_ip=198.51.100.7 client_port=51234 virtual=/Common/vs_www virtual_ip=203.0.113.10 virtual_port=443 pool=/Common/pool_www server=10.1.0.11:8080 method=GET uri=/index.html host=silverpinevalley.com status=200 bytes=5120 duration_ms=23 user_agent="Mozilla/5.0 ..."
+func (b *BigIP) Next() ([]byte, error) {
+	var buf bytes.Buffer
+
+	module := b.module
+	if module == "" {
+		module = modules[b.rand.IntN(len(modules))]
+	}
+	key := ModuleLTM
+	if module == ModuleASM {
+		key = b.format
+		if key == "" {
+			key = formats[b.rand.IntN(len(formats))]
+		}
+	}
+	b.randomize(module, b.servers[b.rand.IntN(len(b.servers))])
+
+	if err := b.templates[key].Execute(&buf, b); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// randomize sets the fields of a log of the module of a request to the
+// virtual server v.
+func (b *BigIP) randomize(module string, v virtualServer) {
+	b.Timestamp = b.clock.Now()
+	b.VirtualServer = v.name
+	b.VirtualAddr = v.addr
+	b.Pool = v.pool
+	b.Policy = v.policy
+	b.Domain = v.domain
+	b.Member = v.members[b.rand.IntN(len(v.members))]
+	b.MemberPort = 8080
+	b.Protocol, b.VirtualPort = "HTTPS", 443
+	if b.rand.IntN(5) == 0 {
+		b.Protocol, b.VirtualPort, b.MemberPort = "HTTP", 80, 80
+	}
+
+	b.ClientAddr = random.IPv4(b.rand)
+	b.ClientPort = 1024 + b.rand.IntN(64512)
+	b.Method = methods[b.rand.IntN(len(methods))]
+	b.URI = paths[b.rand.IntN(len(paths))]
+	b.Query = ""
+	if b.rand.IntN(2) == 0 {
+		b.Query = random.Query(b.rand)
+	}
+	b.UserAgent = random.UserAgent(b.rand)
+	b.StatusCode = statuses[b.rand.IntN(len(statuses))]
+	b.ResponseSize = b.rand.IntN(50000)
+	b.Duration = 1 + b.rand.IntN(500)
+	b.SessionID = random.Hex(b.rand, 8)
+	b.SupportID = b.rand.Uint64()
+	b.Geo = geos[b.rand.IntN(len(geos))]
+
+	// The local0 facility, with the severity of the rating for ASM
+	// and info for LTM.
+	b.Priority = 16*8 + 6
+	if module == ModuleASM {
+		w := weighted(b.rand)
+		b.Violation, b.AttackType, b.SigID, b.SigName, b.Rating = w.name, w.attackType, w.sigID, w.sigName, w.rating
+		b.SeverityName = severities[b.Rating]
+		b.Severity = 2 * b.Rating
+		b.Priority = 16*8 + 7 - b.Rating
+		b.RequestStatus = statusAlerted
+		if b.Rating >= 4 {
+			// ASM answers the blocked requests with its blocking
+			// page, the response code of the log is 0.
+			b.RequestStatus, b.StatusCode = statusBlocked, 0
+		}
+	}
+
+	b.pins.Apply(b)
+}
+
+// weighted returns a violation drawn by the weights of the violations.
+func weighted(r *rand.Rand) violation {
+	total := 0
+	for _, v := range violations {
+		total += v.weight
+	}
+	n := r.IntN(total)
+	for _, v := range violations {
+		if n < v.weight {
+			return v
+		}
+		n -= v.weight
+	}
+	return violations[len(violations)-1]
+}
+
+// Header returns the header option, for the cef_syslog_header partial.
+func (b *BigIP) Header() string {
+	return b.header
+}
+
+// Templates returns the templates of the generator, for generator.Lint.
+func (b *BigIP) Templates() (interface{}, []*template.Template) {
+	var templates []*template.Template
+	for _, t := range b.templates {
+		templates = append(templates, t)
+	}
+	return b, templates
+}
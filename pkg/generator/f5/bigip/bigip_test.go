@@ -0,0 +1,70 @@
+package bigip
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBigIP(t *testing.T, c map[string]interface{}) generator.Generator {
+	t.Helper()
+	cfg, err := ucfg.NewFrom(c)
+	assert.Nil(t, err)
+	g, err := New(cfg)
+	assert.Nil(t, err)
+	return g
+}
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		c    map[string]interface{}
+		want *regexp.Regexp
+	}{
+		"ASM CEF": {
+			c:    map[string]interface{}{"module": ModuleASM, "format": FormatCEF},
+			want: regexp.MustCompile(`^CEF:0\|F5\|ASM\|[\d.]+\|[A-Za-z ]+\|[^|]+\|(4|6|8|10)\|dvchost=lb01 dvc=192\.168\.1\.\d+ cs1=/Prod/asm_(shop|checkout) cs1Label=policy_name cs2=/Prod/vs_(shop|checkout) cs2Label=http_class_name .* externalId=\d+ act=(blocked cn1=0|alerted cn1=[1-5]\d\d) cn1Label=response_code .* request=/\S*$`),
+		},
+		"ASM KV": {
+			c:    map[string]interface{}{"module": ModuleASM, "format": FormatKV},
+			want: regexp.MustCompile(`^<13[0-5]>[A-Z][a-z]{2} [ 1-3]\d \d{2}:\d{2}:\d{2} lb01 ASM:unit_hostname="lb01",management_ip_address="192\.168\.1\.\d+",http_class_name="/Prod/vs_(shop|checkout)",web_application_name="/Prod/asm_(shop|checkout)",.*,request_status="(blocked|alerted)",.*,request="[A-Z]+ /\S* HTTP/1\.1\\r\\nHost: [^"]+\\r\\n"$`),
+		},
+		"LTM": {
+			c:    map[string]interface{}{"module": ModuleLTM},
+			want: regexp.MustCompile(`^<134>[A-Z][a-z]{2} [ 1-3]\d \d{2}:\d{2}:\d{2} lb01 ltm_request: client_ip=\S+ client_port=\d+ virtual=/Prod/vs_(shop|checkout) virtual_ip=\S+ virtual_port=(80|443) pool=/Prod/pool_(shop|checkout) server=10\.1\.[01]\.1\d:(80|8080) method=[A-Z]+ uri=/\S* host=\S+ status=\d{3} bytes=\d+ duration_ms=\d+ user_agent="[^"]+"$`),
+		},
+	}
+	for name, tc := range tests {
+		c := map[string]interface{}{"type": Name, "seed": 1, "hostname": "lb01", "virtual_servers": []string{"/Prod/vs_shop", "/Prod/vs_checkout"}, "pools": []string{"/Prod/pool_shop", "/Prod/pool_checkout"}}
+		for k, v := range tc.c {
+			c[k] = v
+		}
+		g := newBigIP(t, c)
+		for i := 0; i < 100; i++ {
+			got, err := g.Next()
+			assert.Nil(t, err, name)
+			m := tc.want.FindStringSubmatch(string(got))
+			if assert.NotNil(t, m, "%s: %s", name, got) && name == "LTM" {
+				// The virtual servers balance to their own pools.
+				assert.Equal(t, m[1], m[3], string(got))
+			}
+		}
+	}
+}
+
+func TestHeader(t *testing.T) {
+	g := newBigIP(t, map[string]interface{}{"type": Name, "module": ModuleASM, "format": FormatCEF, "header": "rfc5424"})
+	for i := 0; i < 20; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		assert.Regexp(t, `^<13[0-5]>1 \S+ bigip1\.example\.com ASM - - CEF:0\|F5\|ASM\|`, string(got))
+	}
+}
+
+func TestLint(t *testing.T) {
+	issues, err := generator.Lint(newBigIP(t, map[string]interface{}{"type": Name}), 100)
+	assert.Nil(t, err)
+	assert.Empty(t, issues)
+}
@@ -0,0 +1,66 @@
+package bigip
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Type           string   `config:"type" validate:"required"`
+	Module         string   `config:"module"`
+	Format         string   `config:"format"`
+	Hostname       string   `config:"hostname"`
+	VirtualServers []string `config:"virtual_servers"`
+	Pools          []string `config:"pools"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:     Name,
+		Hostname: "bigip1.example.com",
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if c.Module != "" && !contains(modules[:], c.Module) {
+		return fmt.Errorf("'%s' is not a valid value for 'module' expected '%s'", c.Module, strings.Join(modules[:], ", "))
+	}
+	if c.Format != "" && !contains(formats[:], c.Format) {
+		return fmt.Errorf("'%s' is not a valid value for 'format' expected '%s'", c.Format, strings.Join(formats[:], ", "))
+	}
+	if c.Hostname == "" {
+		return fmt.Errorf("'hostname' can not be empty")
+	}
+	for _, v := range c.VirtualServers {
+		if err := validPath("virtual_servers", v); err != nil {
+			return err
+		}
+	}
+	for _, p := range c.Pools {
+		if err := validPath("pools", p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validPath checks that name is the full path of an object of a
+// partition, such as /Common/pool_www.
+func validPath(option, name string) error {
+	if !strings.HasPrefix(name, "/") || strings.Count(name, "/") < 2 || strings.ContainsAny(name, " \",") {
+		return fmt.Errorf("'%s' is not a valid value for '%s' expected a full path such as /Common/%s", name, option, strings.TrimSuffix(option, "s"))
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
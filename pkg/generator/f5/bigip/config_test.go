@@ -0,0 +1,79 @@
+package bigip
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Virtual Servers": {
+			c:           map[string]interface{}{"type": Name, "module": "asm", "format": "kv", "hostname": "lb01", "virtual_servers": []string{"/Prod/vs_shop"}, "pools": []string{"/Prod/pool_shop"}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'f5:bigip' accessing config",
+		},
+		"Invalid Module": {
+			c:           map[string]interface{}{"type": Name, "module": "apm"},
+			hasError:    true,
+			errorString: "'apm' is not a valid value for 'module' expected 'asm, ltm' accessing config",
+		},
+		"Invalid Format": {
+			c:           map[string]interface{}{"type": Name, "format": "leef"},
+			hasError:    true,
+			errorString: "'leef' is not a valid value for 'format' expected 'cef, kv' accessing config",
+		},
+		"Invalid Header": {
+			c:           map[string]interface{}{"type": Name, "header": "f5"},
+			hasError:    true,
+			errorString: "'f5' is not a valid value for 'header' expected 'none, rfc3164, rfc5424' accessing config",
+		},
+		"Empty Hostname": {
+			c:           map[string]interface{}{"type": Name, "hostname": ""},
+			hasError:    true,
+			errorString: "'hostname' can not be empty accessing config",
+		},
+		"Invalid Virtual Server": {
+			c:           map[string]interface{}{"type": Name, "virtual_servers": []string{"vs_www"}},
+			hasError:    true,
+			errorString: "'vs_www' is not a valid value for 'virtual_servers' expected a full path such as /Common/virtual_server accessing config",
+		},
+		"Invalid Pool": {
+			c:           map[string]interface{}{"type": Name, "pools": []string{"/Common/pool www"}},
+			hasError:    true,
+			errorString: "'/Common/pool www' is not a valid value for 'pools' expected a full path such as /Common/pool accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
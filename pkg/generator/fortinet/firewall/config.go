@@ -1,14 +1,27 @@
 package firewall
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
 
 type config struct {
-	Type string `config:"type" validate:"required"`
+	Type     string             `config:"type" validate:"required"`
+	LogTypes map[string]float64 `config:"log_types"`
+	IPv6     float64            `config:"ipv6"`
+	SrcCIDRs []string           `config:"src_cidrs"`
+	DstCIDRs []string           `config:"dst_cidrs"`
+	Version  int                `config:"version"`
+	Facility *int               `config:"facility"`
 }
 
 func defaultConfig() config {
 	return config{
-		Type: Name,
+		Type:    Name,
+		Version: 6,
 	}
 }
 
@@ -16,5 +29,47 @@ func (c *config) Validate() error {
 	if c.Type != Name {
 		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
 	}
+	if c.IPv6 < 0 || c.IPv6 > 100 {
+		return fmt.Errorf("'%v' is not a valid value for 'ipv6' expected a percentage from 0 to 100", c.IPv6)
+	}
+	if c.Version != 6 && c.Version != 7 {
+		return fmt.Errorf("'%d' is not a valid value for 'version' expected 6 or 7", c.Version)
+	}
+	if c.Facility != nil {
+		if err := generator.ValidFacility("facility", *c.Facility); err != nil {
+			return err
+		}
+	}
+	if _, err := random.NewPool("src_cidrs", c.SrcCIDRs); err != nil {
+		return err
+	}
+	if _, err := random.NewPool("dst_cidrs", c.DstCIDRs); err != nil {
+		return err
+	}
+	if c.LogTypes == nil {
+		return nil
+	}
+	total := 0.0
+	for logType, w := range c.LogTypes {
+		if !validLogType(logType) {
+			return fmt.Errorf("'%s' is not a valid value for 'log_types' expected '%s'", logType, strings.Join(msgTypes[:], ", "))
+		}
+		if w < 0 {
+			return fmt.Errorf("'%v' is not a valid value for 'log_types.%s' expected a weight of 0 or more", w, logType)
+		}
+		total += w
+	}
+	if total == 0 {
+		return fmt.Errorf("'log_types' expected a weight greater than 0")
+	}
 	return nil
 }
+
+func validLogType(logType string) bool {
+	for _, t := range msgTypes {
+		if t == logType {
+			return true
+		}
+	}
+	return false
+}
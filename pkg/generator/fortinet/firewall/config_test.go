@@ -23,6 +23,66 @@ func TestConfigs(t *testing.T) {
 			hasError:    true,
 			errorString: "'Bob' is not a valid value for 'type' expected 'fortinet:firewall' accessing config",
 		},
+		"Valid Log Types": {
+			c:           map[string]interface{}{"type": Name, "log_types": map[string]interface{}{"traffic-local": 10, "utm-webfilter": 5, "utm-ips": 0}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Log Type": {
+			c:           map[string]interface{}{"type": Name, "log_types": map[string]interface{}{"utm-av": 1}},
+			hasError:    true,
+			errorString: "'utm-av' is not a valid value for 'log_types' expected 'event-user, event-system, utm-dns, traffic-forward, event-vpn, utm-webfilter, utm-ips, traffic-local' accessing config",
+		},
+		"Valid IPv6": {
+			c:           map[string]interface{}{"type": Name, "ipv6": 20},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Version": {
+			c:           map[string]interface{}{"type": Name, "version": 5},
+			hasError:    true,
+			errorString: "'5' is not a valid value for 'version' expected 6 or 7 accessing config",
+		},
+		"Invalid Upgrade": {
+			c:           map[string]interface{}{"type": Name, "upgrade": map[string]interface{}{"version": 8, "at": "2024-03-04T00:05:00Z"}},
+			hasError:    true,
+			errorString: "'8' is not a valid value for 'upgrade.version' expected '6, 7'",
+		},
+		"Invalid IPv6": {
+			c:           map[string]interface{}{"type": Name, "ipv6": 101},
+			hasError:    true,
+			errorString: "'101' is not a valid value for 'ipv6' expected a percentage from 0 to 100 accessing config",
+		},
+		"Valid Facility": {
+			c:           map[string]interface{}{"type": Name, "facility": 23},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Facility": {
+			c:           map[string]interface{}{"type": Name, "facility": 24},
+			hasError:    true,
+			errorString: "'24' is not a valid value for 'facility' expected a value from 0 to 23 accessing config",
+		},
+		"Valid CIDRs": {
+			c:           map[string]interface{}{"type": Name, "src_cidrs": []interface{}{"10.0.0.0/8"}, "dst_cidrs": []interface{}{"203.0.113.0/24", "2001:db8::/32"}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Src CIDRs": {
+			c:           map[string]interface{}{"type": Name, "src_cidrs": []interface{}{"10.0.0.0"}},
+			hasError:    true,
+			errorString: "'10.0.0.0' is not a valid value for 'src_cidrs' expected a CIDR such as 10.0.0.0/8 accessing config",
+		},
+		"Negative Log Type Weight": {
+			c:           map[string]interface{}{"type": Name, "log_types": map[string]interface{}{"utm-ips": -1}},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'log_types.utm-ips' expected a weight of 0 or more accessing config",
+		},
+		"No Log Type Weight": {
+			c:           map[string]interface{}{"type": Name, "log_types": map[string]interface{}{"utm-ips": 0}},
+			hasError:    true,
+			errorString: "'log_types' expected a weight greater than 0 accessing config",
+		},
 		"No Type": {
 			c:           map[string]interface{}{"type": ""},
 			hasError:    true,
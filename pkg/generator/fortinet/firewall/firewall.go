@@ -1,20 +1,76 @@
 // Package firewall generates Fortinet Firewall log messages
 //
-// For the configuration file there are no options so only the following is needed:
+// The log types are event-user, event-system, event-vpn (IPsec
+// tunnel-up and tunnel-down), utm-dns, utm-webfilter, utm-ips,
+// traffic-forward and traffic-local.  By default every log type is
+// as likely, log_types picks them with weights instead, the log types
+// without a weight are left out.  For dual stack networks ipv6 sets
+// the share of the source and destination addresses that are IPv6.
+// src_cidrs and dst_cidrs pick the addresses from networks instead,
+// such as internal sources and external destinations.  With entities
+// the users and their source addresses are of the shared population of
+// package entities, so they match the other logs of the run, and are
+// mostly the users at work at the time of the log.  The exfiltration
+// anomaly of generator.Anomalies multiplies the bytes a source sends,
+// and the anomalous ECS documents are tagged with the label of the
+// anomaly.  The logs are of FortiOS 6 by default, version 7 sends the
+// eventtime in nanoseconds, adds the timezone to the traffic logs and
+// the sentdelta and rcvddelta of the bytes since the last log of a
+// session, and upgrade upgrades the firewalls from version 6 to 7 at a
+// time, see generator.Upgrade.  With facility the logs have the
+// priority header of syslog, "<PRI>", as FortiOS sends them, with the
+// severity of the level of the log, such as err for level="error".
 //
-//   - generator:
-//     type: "fortinet:firewall"
+// Configuration:
+//
+//	log_types: (map, optional) The weights of the log types.
+//	ipv6: (number, optional) The percentage of addresses that are IPv6,
+//	      from 0 (the default) to 100.
+//	src_cidrs: (list, optional) The networks of the source addresses.
+//	dst_cidrs: (list, optional) The networks of the destination
+//	           addresses.
+//	entities: (bool, optional) Draw the users and sources from the
+//	          shared population.
+//	anomalies: (list, optional) The anomalies to inject, see
+//	           generator.Anomalies.
+//	severity: (map, optional) The mix of the levels and the bursts of
+//	          severe logs, see generator.Severities.  The levels are
+//	          emergency, alert, critical, error, warning, notice,
+//	          information and debug.
+//	version: (int, optional) The major FortiOS version, 6 (the default)
+//	         or 7.
+//	upgrade: (map, optional) The version to upgrade to and the time at
+//	         which.
+//	facility: (int, optional) The syslog facility of the priority
+//	          header, from 0 to 23, FortiOS defaults to 23 (local7).
+//	          Without it the logs have no header.
+//
+//	- generator:
+//	    type: "fortinet:firewall"
+//	    log_types:
+//	      traffic-forward: 60
+//	      traffic-local: 10
+//	      utm-webfilter: 20
+//	      utm-ips: 5
+//	      event-vpn: 5
+//	    ipv6: 20
+//	    src_cidrs: ["10.0.0.0/8", "192.168.0.0/16"]
+//	    dst_cidrs: ["203.0.113.0/24", "2001:db8::/32"]
 package firewall
 
 import (
 	"bytes"
-	"math/rand"
+	"fmt"
+	"math/rand/v2"
 	"net"
 	"strconv"
+	"strings"
 	"text/template"
 	"time"
 
+	"github.com/elastic/elastic-agent-libs/mapstr"
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/entities"
 	"github.com/leehinman/spigot/pkg/generator"
 	"github.com/leehinman/spigot/pkg/random"
 )
@@ -27,12 +83,21 @@ var (
 	eventSystemTemplate    = "date={{.Date.UTC.Format \"2006-01-02\"}} time={{.Timestamp}} devname=\"{{.DevName}}\" devid=\"{{.DevId}}\" logid=\"{{.LogId}}\" type=\"event\" subtype=\"system\" level=\"{{.Level}}\" vd=\"{{.Vd}}\" eventtime={{.Date.Unix}} tz=\"{{.Timezone}}\" logdesc=\"FortiSandbox AV database updated\" version=\"1.522479\" msg=\"FortiSandbox AV database updated\""
 	utmDnsTemplate         = "date={{.Date.UTC.Format \"2006-01-02\"}} time={{.Timestamp}} devname=\"{{.DevName}}\" devid=\"{{.DevId}}\" logid=\"{{.LogId}}\" type=\"utm\" subtype=\"dns\" eventtype=\"dns-query\" level=\"{{.Level}}\" vd=\"{{.Vd}}\" eventtime={{.Date.Unix}} tz=\"{{.Timezone}}\" policyid={{.PolicyId}} sessionid={{.SessionId}} srcip={{.SrcIp}} srcport={{.SrcPort}} srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstip={{.DstIp}} dstport=53 dstintf=\"{{.Interface2}}\" dstintfrole=\"{{.InterfaceRole2}}\" proto={{.Protocol}} profile=\"{{.Server}}\" xid={{.XId}} qname=\"{{.QueryName}}\" qtype=\"{{.QueryType}}\" qtypeval=1 qclass=\"IN\""
 	trafficForwardTemplate = "date={{.Date.UTC.Format \"2006-01-02\"}} time={{.Timestamp}} devname=\"{{.DevName}}\" devid=\"{{.DevId}}\" logid=\"{{.LogId}}\" type=\"traffic\" subtype=\"forward\" level=\"{{.Level}}\" vd=\"{{.Vd}}\" eventtime={{.Date.Unix}} srcip={{.SrcIp}} srcport={{.SrcPort}} srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstip={{.DstIp}} dstport={{.DstPort}} dstintf=\"{{.Interface2}}\" dstintfrole=\"{{.InterfaceRole2}}\" sessionid={{.SessionId}} proto={{.Protocol}} action=\"{{.TrafficAction}}\" policyid={{.PolicyId}} policytype=\"policy\" service=\"SNMP\" dstcountry=\"Reserved\" srccountry=\"Reserved\" trandisp=\"noop\" duration={{.Duration}} sentbyte={{.SentBytes}} rcvdbyte={{.SentBytes}} sentpkt={{.SentPackets}} appcat=\"unscanned\" crscore=30 craction=131072 crlevel=\"high\""
+	eventVpnTemplate       = "date={{.Date.UTC.Format \"2006-01-02\"}} time={{.Timestamp}} devname=\"{{.DevName}}\" devid=\"{{.DevId}}\" logid=\"{{.VpnLogId}}\" type=\"event\" subtype=\"vpn\" level=\"notice\" vd=\"{{.Vd}}\" eventtime={{.Date.Unix}} tz=\"{{.Timezone}}\" logdesc=\"IPsec connection status changed\" msg=\"IPsec connection status change\" action=\"{{.VpnAction}}\" remip={{.SrcIp}} locip={{.DstIp}} remport=500 locport=500 outintf=\"{{.Interface2}}\" cookies=\"{{.Cookies}}\" user=\"N/A\" group=\"N/A\" xauthuser=\"N/A\" xauthgroup=\"N/A\" assignip=N/A vpntunnel=\"{{.VpnTunnel}}\" tunnelip=N/A tunnelid={{.TunnelId}} tunneltype=\"ipsec\" {{if eq .VpnAction \"tunnel-up\"}}duration=0 sentbyte=0 rcvdbyte=0{{else}}duration={{.Duration}} sentbyte={{.SentBytes}} rcvdbyte={{.ReceivedBytes}}{{end}} nextstat=0"
+	utmWebfilterTemplate   = "date={{.Date.UTC.Format \"2006-01-02\"}} time={{.Timestamp}} devname=\"{{.DevName}}\" devid=\"{{.DevId}}\" logid=\"{{.WebLogId}}\" type=\"utm\" subtype=\"webfilter\" eventtype=\"{{.WebEventType}}\" level=\"{{if eq .WebAction \"blocked\"}}warning{{else}}notice{{end}}\" vd=\"{{.Vd}}\" eventtime={{.Date.Unix}} tz=\"{{.Timezone}}\" policyid={{.PolicyId}} sessionid={{.SessionId}} srcip={{.SrcIp}} srcport={{.SrcPort}} srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstip={{.DstIp}} dstport=443 dstintf=\"{{.Interface2}}\" dstintfrole=\"{{.InterfaceRole2}}\" proto=6 service=\"HTTPS\" hostname=\"{{.QueryName}}\" profile=\"default\" action=\"{{.WebAction}}\" reqtype=\"direct\" url=\"https://{{.QueryName}}{{.URLPath}}\" sentbyte={{.WebSentBytes}} rcvdbyte={{.WebReceivedBytes}} direction=\"outgoing\" msg=\"{{if eq .WebAction \"blocked\"}}URL belongs to a denied category in policy{{else}}URL belongs to an allowed category in policy{{end}}\" method=\"domain\" cat={{.WebCategory}} catdesc=\"{{.WebCategoryDesc}}\""
+	utmIpsTemplate         = "date={{.Date.UTC.Format \"2006-01-02\"}} time={{.Timestamp}} devname=\"{{.DevName}}\" devid=\"{{.DevId}}\" logid=\"0419016384\" type=\"utm\" subtype=\"ips\" eventtype=\"signature\" level=\"alert\" vd=\"{{.Vd}}\" eventtime={{.Date.Unix}} tz=\"{{.Timezone}}\" severity=\"{{.AttackSeverity}}\" srcip={{.SrcIp}} srccountry=\"Reserved\" dstip={{.DstIp}} dstcountry=\"Reserved\" srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstintf=\"{{.Interface2}}\" dstintfrole=\"{{.InterfaceRole2}}\" sessionid={{.SessionId}} action=\"{{.IpsAction}}\" proto=6 service=\"{{.AttackService}}\" policyid={{.PolicyId}} attack=\"{{.Attack}}\" srcport={{.SrcPort}} dstport={{.AttackPort}} direction=\"outgoing\" attackid={{.AttackId}} profile=\"default\" ref=\"http://www.fortinet.com/ids/VID{{.AttackId}}\" incidentserialno={{.IncidentSerial}} msg=\"{{.AttackCategory}}: {{.Attack}},\""
+	trafficLocalTemplate   = "date={{.Date.UTC.Format \"2006-01-02\"}} time={{.Timestamp}} devname=\"{{.DevName}}\" devid=\"{{.DevId}}\" logid=\"0001000014\" type=\"traffic\" subtype=\"local\" level=\"notice\" vd=\"{{.Vd}}\" eventtime={{.Date.Unix}} srcip={{.SrcIp}} srcport={{.SrcPort}} srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstip={{.DstIp}} dstport={{.LocalPort}} dstintf=\"{{.Vd}}\" dstintfrole=\"undefined\" sessionid={{.SessionId}} proto=6 action=\"{{.LocalAction}}\" policyid=0 policytype=\"local-in-policy\" service=\"{{.LocalService}}\" dstcountry=\"Reserved\" srccountry=\"Reserved\" trandisp=\"noop\" app=\"{{.LocalApp}}\" duration={{.LocalDuration}} sentbyte={{.LocalSentBytes}} rcvdbyte={{.LocalReceivedBytes}} sentpkt={{.LocalSentPackets}} rcvdpkt={{.LocalReceivedPackets}} appcat=\"unscanned\""
 	msgTemplates           = [...]string{
 		eventUserTemplate,
 		eventSystemTemplate,
 		utmDnsTemplate,
 		trafficForwardTemplate,
+		eventVpnTemplate,
+		utmWebfilterTemplate,
+		utmIpsTemplate,
+		trafficLocalTemplate,
 	}
+	msgTypes       = [...]string{"event-user", "event-system", "utm-dns", "traffic-forward", "event-vpn", "utm-webfilter", "utm-ips", "traffic-local"}
 	devices        = [...]string{"Lakewood", "Midvale", "Brookside", "Holloway", "Fairview", "Westport", "Elmswood", "Ridgefield", "Pinehurst", "Stonebridge", "Mapleton", "Riverside", "Graysville", "Windermere", "Briarcliff", "Oakridge", "Highland", "Copperfield", "Woodhaven", "Silverton", "Rosewood", "Cedarcrest", "Ashford", "Elmwood", "Woodbury", "Springfield", "Ravenswood", "Stonegate", "Brookhaven", "Southgate", "Seabrook", "Edgewood", "Greenfield", "Meadowbrook", "Bellevue", "Clarksville", "Oakwood", "Ridgemont", "Crystal_Lake", "Riverview", "Whispering_Pines", "Forest_Hill", "Sunnydale", "Mountview", "Woodlake", "Baywood", "Brentwood", "Lincolnwood", "Summitville", "Elm_Grove"}
 	devid          = [...]string{"Lakew", "Midva", "Broos", "Hollo", "Fairv", "Westp", "Elmsw", "Ridge", "Pineh", "Stonb", "Maple", "Rivers", "Grayv", "Windm", "Briac", "Oakri", "Highl", "Copfi", "Woodh", "Silve", "Rosew", "Cedcr", "Ashfo", "Elmwo", "Woodb", "Sprin", "Raven", "Stoga", "Brooh", "South", "Seabr", "Edgew", "Green", "Meado", "Belle", "Clark", "Oakwo", "Ridgm", "Cryla", "Rivew", "Whisp", "Foreh", "Sunny", "Mount", "Woodl", "Baywo", "Brewd", "Lincw", "Summi", "Elmgv"}
 	users          = [...]string{"Liam_Walters", "Emma_Douglas", "Noah_Hamilton", "Olivia_Stevens", "Elijah_Baker", "Ava_Reynolds", "James_Thompson", "Sophia_Parker", "Lucas_Bennett", "Isabella_Brooks", "Mason_Rogers", "Mia_Campbell", "Ethan_Phillips", "Amelia_Bell", "Alexander_Carter", "Charlotte_Adams", "Henry_Patterson", "Harper_Wright", "Sebastian_Cooper", "Evelyn_Gray", "Jack_Hughes", "Lily_Ross", "Owen_Morris", "Ella_Hayes", "Daniel_Peterson", "Aria_Myers", "Samuel_Long", "Chloe_Collins", "Matthew_Hughes", "Grace_Cook", "Wyatt_Warren", "Scarlett_Reed", "Caleb_Bryant", "Penelope_Rogers", "Isaac_Murphy", "Nora_Jenkins", "Jacob_Cunningham", "Hazel_Clark", "Levi_Morgan", "Riley_Perry", "Nathaniel_Foster", "Zoey_Ford", "Joshua_Harrison", "Lillian_Sullivan", "David_McCarthy", "Avery_Hart", "Andrew_Walker", "Stella_Price", "Thomas_Ward", "Hannah_Hall"}
@@ -40,10 +105,85 @@ var (
 	interfaces     = [...]string{"int0", "int1", "int2", "int3", "int4", "int5", "int6", "int7"}
 	roles          = [...]string{"lan", "wan", "internal", "external", "inbound", "outbound"}
 	protocols      = [...]int{6, 17}
-	queries        = [...]string{"www.silverpinevalley.com", "www.brickstoneridge.net", "www.oakwoodgrove.org", "www.bluewaterhaven.co", "www.copperhollow.info", "www.windyriverplains.com", "www.crystalbayvillage.net", "www.ironwoodcove.org", "www.sunsetbluffresort.co", "www.whisperinghillspoint.info", "www.mapleridgeranch.com", "www.goldenpeakfarms.net", "www.riverviewmeadows.org", "www.stonecreekwoods.co", "www.briarwoodcrossing.info", "www.highlandgrovesprings.com", "www.greenfieldretreat.net", "www.silverlakehollow.org", "www.rosewoodvista.co", "www.ashforddunes.info", "www.willowbrookcourt.com", "www.oakridgefalls.net", "www.copperfieldgrove.org", "www.windermerebay.co", "www.meadowbrookhaven.info", "www.bellavistaacres.com", "www.ridgemontestates.net", "www.sunnydaleshores.org", "www.lakewoodreserves.co", "www.westportpines.info", "www.elmswoodmeadow.com", "www.ridgefieldplaza.net", "www.pinehurstcove.org", "www.stonebridgeflats.co", "www.mapletonlodge.info", "www.graysvillemanor.com", "www.windermerepoint.net", "www.briarcliffheights.org", "www.oakridgebay.co", "www.highlandcrossing.info", "www.copperfieldterrace.com", "www.woodhavenhills.net", "www.silvertonview.org", "www.rosewoodvalley.co", "www.cedarcrestgrove.info", "www.ashfordpeaks.com", "www.elmwoodlakes.net", "www.woodburyridge.org", "www.springfieldbluff.co"}
 	queryTypes     = [...]string{"A", "AAAA"}
 	servers        = [...]string{"Zeus_prod", "Hera_test", "Poseidon_dev", "Demeter_prod", "Athena_dev", "Apollo_test", "Artemis_prod", "Ares_dev", "Aphrodite_test", "Hephaestus_prod", "Hermes_dev", "Hestia_test", "Dionysus_prod", "Hades_dev", "Persephone_test", "Hecate_prod", "Gaia_dev", "Cronus_test", "Rhea_prod", "Eros_dev", "Helios_test", "Selene_prod", "Eos_dev", "Nike_test", "Nemesis_prod", "Iris_dev", "Hypnos_test", "Thanatos_prod", "Morpheus_dev", "Tyche_test", "Pan_prod", "Eris_dev", "Hebe_test", "Nyx_prod", "Khione_dev", "Themis_test", "Harmonia_prod", "Phoebe_dev", "Leto_test", "Tethys_prod", "Metis_dev", "Aether_test", "Hemera_prod", "Eurus_dev", "Notus_test", "Boreas_prod", "Zephyrus_dev", "Styx_test", "Phobos_prod", "Deimos_dev"}
 	trafficActions = [...]string{"deny", "accept"}
+
+	vpnTunnels = [...]string{"to_HQ", "to_DC1", "to_DC2", "branch_Lakewood", "branch_Midvale", "aws_vpc_prod", "azure_vnet_hub"}
+	// webCategories are FortiGuard web filter categories, of which
+	// the blocked ones are denied by the default profile.
+	webCategories = [...]struct {
+		id      int
+		desc    string
+		blocked bool
+	}{
+		{26, "Malicious Websites", true},
+		{61, "Phishing", true},
+		{86, "Spam URLs", true},
+		{59, "Proxy Avoidance", true},
+		{52, "Information Technology", false},
+		{49, "Business", false},
+		{37, "Social Networking", false},
+		{25, "Streaming Media and Download", false},
+		{31, "Finance and Banking", false},
+		{41, "Search Engines and Portals", false},
+	}
+	urlPaths = [...]string{"/", "/index.html", "/login", "/api/v2/items", "/download/setup.exe", "/search?q=report", "/assets/app.js", "/wp-admin/"}
+	// attacks are IPS signatures with their ID, severity, category
+	// and the service and port they target.
+	attacks = [...]struct {
+		name     string
+		id       int
+		severity string
+		category string
+		service  string
+		port     int
+	}{
+		{"Apache.Log4j.Error.Log.Remote.Code.Execution", 51006, "critical", "applications3", "HTTPS", 443},
+		{"MS.SMB.Server.SMB1.Trans2.Secondary.Handling.Code.Execution", 43796, "critical", "applications3", "SMB", 445},
+		{"Bash.Function.Definitions.Remote.Code.Execution", 39294, "critical", "applications3", "HTTP", 80},
+		{"SSH.Login.Brute.Force", 19331, "medium", "anomaly", "SSH", 22},
+		{"Microsoft.Exchange.Server.ProxyShell.Remote.Code.Execution", 50388, "critical", "applications3", "HTTPS", 443},
+		{"SQL.Injection.Attack", 15621, "high", "applications3", "HTTP", 80},
+		{"PHPUnit.Eval-stdin.PHP.Remote.Code.Execution", 45405, "critical", "applications3", "HTTP", 80},
+		{"ZGrab.Scanner", 49244, "low", "applications3", "HTTP", 80},
+	}
+	ipsActions = [...]string{"dropped", "dropped", "reset", "detected"}
+	// localServices are the services of the FortiGate itself, with
+	// their port and application.
+	localServices = [...]struct {
+		name string
+		port int
+		app  string
+	}{
+		{"HTTPS", 443, "Web Management(HTTPS)"},
+		{"SSH", 22, "SSH"},
+		{"SNMP", 161, "SNMP"},
+		{"DNS", 53, "DNS"},
+		{"IKE", 500, "IKE"},
+		{"NTP", 123, "NTP"},
+	}
+	localActions = [...]string{"accept", "deny", "close", "server-rst", "timeout"}
+	// versions are the major FortiOS versions, from the oldest to the
+	// newest.
+	versions = []string{"6", "7"}
+	// fos7Fields are the fields FortiOS 7 appends to the log types.
+	fos7Fields = map[string]string{
+		"traffic-forward": " srcserver=0 sentdelta={{.SentBytes}} rcvddelta={{.SentBytes}}",
+		"traffic-local":   " srcserver=0 sentdelta={{.LocalSentBytes}} rcvddelta={{.LocalReceivedBytes}}",
+	}
+	// severities are the levels of the severity option, from the most
+	// to the least severe.
+	severities = []string{"emergency", "alert", "critical", "error", "warning", "notice", "information", "debug"}
+	// anomalies are the defaults of the anomalies of the firewall: an
+	// exfiltration is a source, and its user, sending a thousand times
+	// the bytes of a session.
+	anomalies = map[string]generator.AnomalyConfig{
+		generator.AnomalyExfiltration: {
+			Hold:  []string{"SrcIp", "User"},
+			Scale: []string{"SentBytes", "SentPackets", "WebSentBytes", "LocalSentBytes", "LocalSentPackets"},
+		},
+	}
 )
 
 // Firewall holds the random fields for a firewall record
@@ -79,6 +219,55 @@ type Firewall struct {
 	User           string
 	Vd             string
 	XId            int
+
+	VpnLogId  string
+	VpnAction string
+	VpnTunnel string
+	TunnelId  int
+	Cookies   string
+
+	WebLogId         string
+	WebEventType     string
+	WebAction        string
+	WebCategory      int
+	WebCategoryDesc  string
+	URLPath          string
+	WebSentBytes     int
+	WebReceivedBytes int
+
+	Attack         string
+	AttackId       int
+	AttackSeverity string
+	AttackCategory string
+	AttackService  string
+	AttackPort     int
+	IpsAction      string
+	IncidentSerial int
+
+	LocalService         string
+	LocalPort            int
+	LocalApp             string
+	LocalAction          string
+	LocalDuration        int
+	LocalSentBytes       int
+	LocalReceivedBytes   int
+	LocalSentPackets     int
+	LocalReceivedPackets int
+
+	rand     *rand.Rand
+	clock    *generator.Clock
+	pins     *generator.Pins
+	anoms    *generator.Anomalies
+	severity *generator.Severities
+	upgrade  *generator.Upgrade
+	upgraded []*template.Template
+	weights  []float64
+	total    float64
+	ipv6     float64
+	src      *random.Pool
+	dst      *random.Pool
+	entities *entities.Population
+	facility int
 }
 
 func init() {
@@ -92,19 +281,114 @@ func New(cfg *ucfg.Config) (generator.Generator, error) {
 		return nil, err
 	}
 
-	f := &Firewall{}
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Firewall{rand: r, clock: clock, ipv6: c.IPv6, facility: -1}
+	if c.Facility != nil {
+		f.facility = *c.Facility
+	}
+	// Validate checked the networks.
+	f.src, _ = random.NewPool("src_cidrs", c.SrcCIDRs)
+	f.dst, _ = random.NewPool("dst_cidrs", c.DstCIDRs)
+	f.entities, err = entities.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	f.pins, err = generator.NewPins(cfg, r, f)
+	if err != nil {
+		return nil, err
+	}
+	f.anoms, err = generator.NewAnomalies(cfg, r, f, anomalies)
+	if err != nil {
+		return nil, err
+	}
+	f.severity, err = generator.NewSeverities(cfg, r, f, "Level", severities)
+	if err != nil {
+		return nil, err
+	}
+
+	f.upgrade, err = generator.NewUpgrade(cfg, strconv.Itoa(c.Version), versions)
+	if err != nil {
+		return nil, err
+	}
+
 	f.randomize()
 
-	for i, v := range msgTemplates {
-		t, err := template.New(strconv.Itoa(i)).Funcs(generator.FunctionMap).Parse(v)
+	f.Templates, err = parse(c.Version)
+	if err != nil {
+		return nil, err
+	}
+	if f.upgrade != nil {
+		// NewUpgrade checked the version.
+		version, _ := strconv.Atoi(f.upgrade.Version)
+		f.upgraded, err = parse(version)
 		if err != nil {
 			return nil, err
 		}
-		f.Templates = append(f.Templates, t)
+	}
+	for _, msgType := range msgTypes {
+		if c.LogTypes != nil {
+			f.weights = append(f.weights, c.LogTypes[msgType])
+			f.total += c.LogTypes[msgType]
+		}
 	}
 	return f, nil
 }
 
+// parse returns the templates of the log types for a FortiOS version.
+func parse(version int) ([]*template.Template, error) {
+	var templates []*template.Template
+	for i, v := range msgTemplates {
+		if version >= 7 {
+			v = strings.Replace(v, "eventtime={{.Date.Unix}}", "eventtime={{.Date.UnixNano}}", 1)
+			if !strings.Contains(v, " tz=") {
+				v = strings.Replace(v, "eventtime={{.Date.UnixNano}}", "eventtime={{.Date.UnixNano}} tz=\"{{.Timezone}}\"", 1)
+			}
+			v += fos7Fields[msgTypes[i]]
+		}
+		t, err := template.New(msgTypes[i]).Funcs(generator.FunctionMap).Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// template returns the template of the next record, picked with the
+// weights of the log types when they are set.
+func (f *Firewall) template() *template.Template {
+	templates := f.Templates
+	if f.upgrade.Upgraded(f.Date) {
+		templates = f.upgraded
+	}
+	if f.weights == nil {
+		return templates[f.rand.IntN(len(templates))]
+	}
+	n := f.rand.Float64() * f.total
+	for i, w := range f.weights {
+		if n < w {
+			return templates[i]
+		}
+		n -= w
+	}
+	// n can only be left over by rounding, the last template with a
+	// weight is the pick.
+	for i := len(f.weights) - 1; ; i-- {
+		if f.weights[i] > 0 {
+			return templates[i]
+		}
+	}
+}
+
 // Next produces the next firewall record.
 //
 // Example:
@@ -113,7 +397,13 @@ func New(cfg *ucfg.Config) (generator.Generator, error) {
 func (f *Firewall) Next() ([]byte, error) {
 	var buf bytes.Buffer
 
-	err := f.Templates[rand.Intn(len(f.Templates))].Execute(&buf, f)
+	t := f.template()
+	if f.facility >= 0 {
+		// The levels are all levels of syslog.
+		severity, _ := generator.LevelSeverity(f.level(t.Name()))
+		fmt.Fprintf(&buf, "<%d>", generator.Priority(f.facility, severity))
+	}
+	err := t.Execute(&buf, f)
 	if err != nil {
 		return nil, err
 	}
@@ -123,33 +413,292 @@ func (f *Firewall) Next() ([]byte, error) {
 	return buf.Bytes(), err
 }
 
+// NextECS produces the next firewall record as an ECS document.
+func (f *Firewall) NextECS() (mapstr.M, error) {
+	var buf bytes.Buffer
+
+	t := f.template()
+	err := t.Execute(&buf, f)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := f.document(t.Name(), buf.String())
+
+	f.randomize()
+
+	return doc, nil
+}
+
+func (f *Firewall) document(msgType, message string) mapstr.M {
+	fields := map[string]interface{}{
+		"@timestamp":                f.Date.UTC(),
+		"message":                   message,
+		"event.kind":                "event",
+		"event.code":                strconv.Itoa(f.LogId),
+		"event.timezone":            f.Timezone,
+		"log.level":                 f.level(msgType),
+		"observer.vendor":           "Fortinet",
+		"observer.product":          "FortiGate",
+		"observer.type":             "firewall",
+		"observer.name":             f.DevName,
+		"observer.serial_number":    f.DevId,
+		"fortinet.firewall.type":    strings.SplitN(msgType, "-", 2)[0],
+		"fortinet.firewall.subtype": strings.SplitN(msgType, "-", 2)[1],
+		"fortinet.firewall.vd":      f.Vd,
+	}
+	switch msgType {
+	case "event-user":
+		fields["event.action"] = "FSSO-logon"
+		fields["event.category"] = []string{"authentication"}
+		fields["event.type"] = []string{"start"}
+		fields["event.outcome"] = "success"
+		fields["source.ip"] = f.SrcIp.String()
+		fields["source.user.name"] = f.User
+		fields["user.name"] = f.User
+	case "event-system":
+		fields["event.category"] = []string{"configuration"}
+		fields["event.type"] = []string{"change"}
+	case "utm-dns":
+		fields["event.action"] = "dns-query"
+		fields["event.category"] = []string{"network"}
+		fields["event.type"] = []string{"protocol"}
+		fields["network.protocol"] = "dns"
+		fields["network.iana_number"] = strconv.Itoa(f.Protocol)
+		fields["dns.id"] = strconv.Itoa(f.XId)
+		fields["dns.question.name"] = f.QueryName
+		fields["dns.question.type"] = f.QueryType
+		fields["dns.question.class"] = "IN"
+		fields["rule.id"] = strconv.Itoa(f.PolicyId)
+		fields["source.ip"] = f.SrcIp.String()
+		fields["source.port"] = f.SrcPort
+		fields["destination.ip"] = f.DstIp.String()
+		fields["destination.port"] = 53
+		fields["observer.ingress.interface.name"] = f.Interface1
+		fields["observer.egress.interface.name"] = f.Interface2
+	case "traffic-forward":
+		fields["event.action"] = f.TrafficAction
+		fields["event.category"] = []string{"network"}
+		fields["event.type"] = []string{"connection", "end"}
+		if f.TrafficAction == "deny" {
+			fields["event.outcome"] = "failure"
+		} else {
+			fields["event.outcome"] = "success"
+		}
+		fields["event.duration"] = (time.Duration(f.Duration) * time.Second).Nanoseconds()
+		fields["network.iana_number"] = strconv.Itoa(f.Protocol)
+		fields["rule.id"] = strconv.Itoa(f.PolicyId)
+		fields["source.ip"] = f.SrcIp.String()
+		fields["source.port"] = f.SrcPort
+		fields["source.bytes"] = f.SentBytes
+		fields["source.packets"] = f.SentPackets
+		fields["destination.ip"] = f.DstIp.String()
+		fields["destination.port"] = f.DstPort
+		fields["destination.bytes"] = f.SentBytes
+		fields["network.bytes"] = 2 * f.SentBytes
+		fields["observer.ingress.interface.name"] = f.Interface1
+		fields["observer.egress.interface.name"] = f.Interface2
+	case "event-vpn":
+		fields["event.code"] = f.VpnLogId
+		fields["event.action"] = f.VpnAction
+		fields["event.category"] = []string{"network"}
+		if f.VpnAction == "tunnel-up" {
+			fields["event.type"] = []string{"connection", "start"}
+		} else {
+			fields["event.type"] = []string{"connection", "end"}
+			fields["event.duration"] = (time.Duration(f.Duration) * time.Second).Nanoseconds()
+			fields["source.bytes"] = f.ReceivedBytes
+			fields["destination.bytes"] = f.SentBytes
+		}
+		fields["source.ip"] = f.SrcIp.String()
+		fields["source.port"] = 500
+		fields["destination.ip"] = f.DstIp.String()
+		fields["destination.port"] = 500
+		fields["observer.egress.interface.name"] = f.Interface2
+		fields["fortinet.firewall.vpntunnel"] = f.VpnTunnel
+		fields["fortinet.firewall.tunnelid"] = strconv.Itoa(f.TunnelId)
+		fields["fortinet.firewall.tunneltype"] = "ipsec"
+	case "utm-webfilter":
+		fields["event.code"] = f.WebLogId
+		fields["event.action"] = f.WebAction
+		fields["event.category"] = []string{"network"}
+		if f.WebAction == "blocked" {
+			fields["event.type"] = []string{"denied"}
+		} else {
+			fields["event.type"] = []string{"allowed"}
+		}
+		fields["network.iana_number"] = "6"
+		fields["rule.id"] = strconv.Itoa(f.PolicyId)
+		fields["rule.category"] = f.WebCategoryDesc
+		fields["url.domain"] = f.QueryName
+		fields["url.path"] = f.URLPath
+		fields["url.original"] = "https://" + f.QueryName + f.URLPath
+		fields["source.ip"] = f.SrcIp.String()
+		fields["source.port"] = f.SrcPort
+		fields["source.bytes"] = f.WebSentBytes
+		fields["destination.ip"] = f.DstIp.String()
+		fields["destination.port"] = 443
+		fields["destination.bytes"] = f.WebReceivedBytes
+		fields["observer.ingress.interface.name"] = f.Interface1
+		fields["observer.egress.interface.name"] = f.Interface2
+	case "utm-ips":
+		fields["event.code"] = "0419016384"
+		fields["event.action"] = f.IpsAction
+		fields["event.category"] = []string{"network", "intrusion_detection"}
+		if f.IpsAction == "detected" {
+			fields["event.type"] = []string{"info"}
+		} else {
+			fields["event.type"] = []string{"denied"}
+		}
+		fields["network.iana_number"] = "6"
+		fields["rule.id"] = strconv.Itoa(f.PolicyId)
+		fields["rule.name"] = f.Attack
+		fields["rule.category"] = f.AttackCategory
+		fields["vulnerability.id"] = strconv.Itoa(f.AttackId)
+		fields["source.ip"] = f.SrcIp.String()
+		fields["source.port"] = f.SrcPort
+		fields["destination.ip"] = f.DstIp.String()
+		fields["destination.port"] = f.AttackPort
+		fields["observer.ingress.interface.name"] = f.Interface1
+		fields["observer.egress.interface.name"] = f.Interface2
+		fields["fortinet.firewall.severity"] = f.AttackSeverity
+	case "traffic-local":
+		fields["event.code"] = "0001000014"
+		fields["event.action"] = f.LocalAction
+		fields["event.category"] = []string{"network"}
+		fields["event.type"] = []string{"connection", "end"}
+		if f.LocalAction == "deny" {
+			fields["event.outcome"] = "failure"
+		} else {
+			fields["event.outcome"] = "success"
+		}
+		fields["event.duration"] = (time.Duration(f.LocalDuration) * time.Second).Nanoseconds()
+		fields["network.iana_number"] = "6"
+		fields["network.application"] = f.LocalApp
+		fields["rule.id"] = "0"
+		fields["source.ip"] = f.SrcIp.String()
+		fields["source.port"] = f.SrcPort
+		fields["source.bytes"] = f.LocalSentBytes
+		fields["source.packets"] = f.LocalSentPackets
+		fields["destination.ip"] = f.DstIp.String()
+		fields["destination.port"] = f.LocalPort
+		fields["destination.bytes"] = f.LocalReceivedBytes
+		fields["destination.packets"] = f.LocalReceivedPackets
+		fields["network.bytes"] = f.LocalSentBytes + f.LocalReceivedBytes
+		fields["observer.ingress.interface.name"] = f.Interface1
+	}
+	if f.facility >= 0 {
+		severity, _ := generator.LevelSeverity(f.level(msgType))
+		fields["log.syslog.facility.code"] = f.facility
+		fields["log.syslog.severity.code"] = severity
+		fields["log.syslog.priority"] = generator.Priority(f.facility, severity)
+	}
+	if label := f.anoms.Label(); label != "" {
+		fields["tags"] = []string{label}
+	}
+	return generator.ECS(fields)
+}
+
+// level returns the level of the log of msgType, as its template
+// writes it, the event-vpn, utm-webfilter, utm-ips and traffic-local
+// logs have levels of their own.
+func (f *Firewall) level(msgType string) string {
+	switch msgType {
+	case "event-vpn", "traffic-local":
+		return "notice"
+	case "utm-webfilter":
+		if f.WebAction == "blocked" {
+			return "warning"
+		}
+		return "notice"
+	case "utm-ips":
+		return "alert"
+	}
+	return f.Level
+}
+
 func (f *Firewall) randomize() {
-	f.Timestamp = random.Randomtime()
-	f.DevName = devices[rand.Intn(len(devices))]
-	f.DevId = devid[rand.Intn(len(devid))]
-	f.LogId = rand.Intn(10)
+	f.Timestamp = random.Randomtime(f.rand)
+	f.DevName = devices[f.rand.IntN(len(devices))]
+	f.DevId = devid[f.rand.IntN(len(devid))]
+	f.LogId = f.rand.IntN(10)
 	f.Timezone = "-0500"
-	f.Date = time.Now()
+	f.Date = f.clock.Now()
 	f.Vd = "root"
-	f.User = users[rand.Intn(len(users))]
-	f.Server = servers[rand.Intn(len(servers))]
-	f.SrcIp = random.IPv4()
-	f.SrcPort = random.Port()
-	f.DstIp = random.IPv4()
-	f.DstPort = random.Port()
-	f.PolicyId = rand.Intn(256)
-	f.SessionId = rand.Intn(65536)
-	f.Interface1 = interfaces[rand.Intn(len(interfaces))]
-	f.Interface2 = interfaces[rand.Intn(len(interfaces))]
-	f.InterfaceRole1 = roles[rand.Intn(len(roles))]
-	f.InterfaceRole2 = roles[rand.Intn(len(roles))]
-	f.Protocol = protocols[rand.Intn(len(protocols))]
-	f.QueryName = queries[rand.Intn(len(queries))]
-	f.QueryType = queryTypes[rand.Intn(len(queryTypes))]
-	f.XId = rand.Intn(256)
-	f.Level = levels[rand.Intn(len(levels))]
-	f.TrafficAction = trafficActions[rand.Intn(len(trafficActions))]
-	f.SentPackets = rand.Intn(65536)
+	f.User = users[f.rand.IntN(len(users))]
+	f.Server = servers[f.rand.IntN(len(servers))]
+	f.SrcIp = f.src.IP(f.rand, f.ipv6)
+	if f.entities != nil {
+		u := f.entities.ActiveUser(f.rand, f.Date)
+		f.User = u.Name
+		f.SrcIp = f.entities.HostOf(u).IP
+	}
+	f.SrcPort = random.Port(f.rand)
+	f.DstIp = f.dst.IP(f.rand, f.ipv6)
+	f.DstPort = random.Port(f.rand)
+	f.PolicyId = f.rand.IntN(256)
+	f.SessionId = f.rand.IntN(65536)
+	f.Interface1 = interfaces[f.rand.IntN(len(interfaces))]
+	f.Interface2 = interfaces[f.rand.IntN(len(interfaces))]
+	f.InterfaceRole1 = roles[f.rand.IntN(len(roles))]
+	f.InterfaceRole2 = roles[f.rand.IntN(len(roles))]
+	f.Protocol = protocols[f.rand.IntN(len(protocols))]
+	f.QueryName = "www." + random.Domain(f.rand)
+	f.QueryType = queryTypes[f.rand.IntN(len(queryTypes))]
+	f.XId = f.rand.IntN(256)
+	f.Level = levels[f.rand.IntN(len(levels))]
+	f.TrafficAction = trafficActions[f.rand.IntN(len(trafficActions))]
+	f.SentPackets = f.rand.IntN(65536)
 	f.SentBytes = f.SentPackets * 1500
-	f.Duration = rand.Intn(1024)
+	f.Duration = f.rand.IntN(1024)
+
+	f.VpnAction = "tunnel-up"
+	f.VpnLogId = "0101037138"
+	if f.rand.IntN(2) == 0 {
+		f.VpnAction = "tunnel-down"
+		f.VpnLogId = "0101037141"
+	}
+	f.VpnTunnel = vpnTunnels[f.rand.IntN(len(vpnTunnels))]
+	f.TunnelId = f.rand.IntN(1 << 31)
+	f.Cookies = random.Hex(f.rand, 8) + "/" + random.Hex(f.rand, 8)
+	f.ReceivedBytes = f.rand.IntN(1 << 30)
+
+	category := webCategories[f.rand.IntN(len(webCategories))]
+	f.WebCategory = category.id
+	f.WebCategoryDesc = category.desc
+	f.WebLogId, f.WebEventType, f.WebAction = "0317013312", "ftgd_allow", "passthrough"
+	if category.blocked {
+		f.WebLogId, f.WebEventType, f.WebAction = "0316013056", "ftgd_blk", "blocked"
+	}
+	f.URLPath = urlPaths[f.rand.IntN(len(urlPaths))]
+	f.WebSentBytes = f.rand.IntN(2000) + 300
+	f.WebReceivedBytes = 0
+	if !category.blocked {
+		f.WebReceivedBytes = f.rand.IntN(500000) + 500
+	}
+
+	attack := attacks[f.rand.IntN(len(attacks))]
+	f.Attack = attack.name
+	f.AttackId = attack.id
+	f.AttackSeverity = attack.severity
+	f.AttackCategory = attack.category
+	f.AttackService = attack.service
+	f.AttackPort = attack.port
+	f.IpsAction = ipsActions[f.rand.IntN(len(ipsActions))]
+	f.IncidentSerial = f.rand.IntN(1 << 30)
+
+	service := localServices[f.rand.IntN(len(localServices))]
+	f.LocalService = service.name
+	f.LocalPort = service.port
+	f.LocalApp = service.app
+	f.LocalAction = localActions[f.rand.IntN(len(localActions))]
+	f.LocalDuration = f.rand.IntN(120)
+	f.LocalSentPackets = f.rand.IntN(50) + 1
+	f.LocalReceivedPackets = f.rand.IntN(50) + 1
+	f.LocalSentBytes = f.LocalSentPackets * (f.rand.IntN(1400) + 60)
+	f.LocalReceivedBytes = f.LocalReceivedPackets * (f.rand.IntN(1400) + 60)
+
+	f.severity.Apply(f, f.Date)
+	f.pins.Apply(f)
+	f.anoms.Apply(f, f.Date)
 }
@@ -1,12 +1,18 @@
 package firewall
 
 import (
-	"math/rand"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 	"text/template"
 	"time"
 
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/entities"
 	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -16,26 +22,328 @@ func TestNext(t *testing.T) {
 		expected string
 	}{
 		"EventUser": {template: eventUserTemplate,
-			expected: "date=1970-01-02 time=03:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"0123456789\" type=\"event\" subtype=\"user\" level=\"error\" vd=\"root\" eventtime=97445 tz=\"-0500\" logdesc=\"FSSO logon authentication status\" srcip=142.155.32.170 user=\"user07\" server=\"srv7\" action=\"FSSO-logon\" msg=\"FSSO-logon event from FSSO_srv7: user user07 logged on 142.155.32.170\""},
+			expected: "<6>date=1970-01-02 time=03:04:05 devname=\"Elmwood\" devid=\"Elmgv\" logid=\"8\" type=\"event\" subtype=\"user\" level=\"information\" vd=\"root\" eventtime=97445 tz=\"-0500\" logdesc=\"FSSO logon authentication status\" srcip=148.3.128.236 user=\"Hazel_Clark\" server=\"Zeus_prod\" action=\"FSSO-logon\" msg=\"FSSO-logon event from FSSO_Zeus_prod: user Hazel_Clark logged on 148.3.128.236\""},
 		"EventSystem": {template: eventSystemTemplate,
-			expected: "date=1970-01-02 time=03:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"0123456789\" type=\"event\" subtype=\"system\" level=\"error\" vd=\"root\" eventtime=97445 tz=\"-0500\" logdesc=\"FortiSandbox AV database updated\" version=\"1.522479\" msg=\"FortiSandbox AV database updated\""},
+			expected: "<6>date=1970-01-02 time=03:04:05 devname=\"Elmwood\" devid=\"Elmgv\" logid=\"8\" type=\"event\" subtype=\"system\" level=\"information\" vd=\"root\" eventtime=97445 tz=\"-0500\" logdesc=\"FortiSandbox AV database updated\" version=\"1.522479\" msg=\"FortiSandbox AV database updated\""},
 		"UtmDns": {template: utmDnsTemplate,
-			expected: "date=1970-01-02 time=03:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"0123456789\" type=\"utm\" subtype=\"dns\" eventtype=\"dns-query\" level=\"error\" vd=\"root\" eventtime=97445 tz=\"-0500\" policyid=57 sessionid=53932 srcip=142.155.32.170 srcport=1211 srcintf=\"int0\" srcintfrole=\"internal\" dstip=2.11.181.108 dstport=53 dstintf=\"int4\" dstintfrole=\"wan\" proto=6 profile=\"elastictest\" xid=26 qname=\"elastic.co\" qtype=\"A\" qtypeval=1 qclass=\"IN\""},
+			expected: "<6>date=1970-01-02 time=03:04:05 devname=\"Elmwood\" devid=\"Elmgv\" logid=\"8\" type=\"utm\" subtype=\"dns\" eventtype=\"dns-query\" level=\"information\" vd=\"root\" eventtime=97445 tz=\"-0500\" policyid=26 sessionid=38190 srcip=148.3.128.236 srcport=53439 srcintf=\"int3\" srcintfrole=\"wan\" dstip=108.206.236.64 dstport=53 dstintf=\"int2\" dstintfrole=\"external\" proto=17 profile=\"Zeus_prod\" xid=95 qname=\"www.waterrosehollow.com\" qtype=\"AAAA\" qtypeval=1 qclass=\"IN\""},
 		"TrafficForward": {template: trafficForwardTemplate,
-			expected: "date=1970-01-02 time=03:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"0123456789\" type=\"traffic\" subtype=\"forward\" level=\"error\" vd=\"root\" eventtime=97445 srcip=142.155.32.170 srcport=1211 srcintf=\"int0\" srcintfrole=\"internal\" dstip=2.11.181.108 dstport=53638 dstintf=\"int4\" dstintfrole=\"wan\" sessionid=53932 proto=6 action=\"accept\" policyid=57 policytype=\"policy\" service=\"SNMP\" dstcountry=\"Reserved\" srccountry=\"Reserved\" trandisp=\"noop\" duration=994 sentbyte=24247500 rcvdbyte=24247500 sentpkt=16165 appcat=\"unscanned\" crscore=30 craction=131072 crlevel=\"high\""},
+			expected: "<6>date=1970-01-02 time=03:04:05 devname=\"Elmwood\" devid=\"Elmgv\" logid=\"8\" type=\"traffic\" subtype=\"forward\" level=\"information\" vd=\"root\" eventtime=97445 srcip=148.3.128.236 srcport=53439 srcintf=\"int3\" srcintfrole=\"wan\" dstip=108.206.236.64 dstport=43831 dstintf=\"int2\" dstintfrole=\"external\" sessionid=38190 proto=17 action=\"accept\" policyid=26 policytype=\"policy\" service=\"SNMP\" dstcountry=\"Reserved\" srccountry=\"Reserved\" trandisp=\"noop\" duration=797 sentbyte=71046000 rcvdbyte=71046000 sentpkt=47364 appcat=\"unscanned\" crscore=30 craction=131072 crlevel=\"high\""},
 	}
 	test_time, err := time.Parse(time.RFC3339, "1970-01-02T03:04:05Z")
 	assert.Nil(t, err)
 	for name, tc := range tests {
-		rand.Seed(1)
-		f := &Firewall{}
+		random.Seed(1)
+		f := &Firewall{rand: random.NewRand(nil)}
 		f.randomize()
 		templ, err := template.New(name).Funcs(generator.FunctionMap).Parse(tc.template)
 		assert.Nil(t, err)
 		f.Templates = []*template.Template{templ}
 		f.Date = test_time
+		f.Timestamp = test_time.Format("15:04:05")
 		got, err := f.Next()
 		assert.Nil(t, err)
 		assert.Equal(t, []byte(tc.expected), got, name)
 	}
 }
+
+func TestNextECS(t *testing.T) {
+	random.Seed(1)
+	f := &Firewall{rand: random.NewRand(nil)}
+	templ, err := template.New("traffic-forward").Funcs(generator.FunctionMap).Parse(trafficForwardTemplate)
+	assert.Nil(t, err)
+	f.Templates = []*template.Template{templ}
+	f.randomize()
+	doc, err := f.NextECS()
+	assert.Nil(t, err)
+
+	want := map[string]interface{}{
+		"event.action":           "accept",
+		"event.code":             "8",
+		"event.duration":         int64(797000000000),
+		"event.outcome":          "success",
+		"fortinet.firewall.type": "traffic",
+		"network.iana_number":    "17",
+		"observer.vendor":        "Fortinet",
+		"observer.name":          "Elmwood",
+		"rule.id":                "26",
+		"source.ip":              "148.3.128.236",
+		"source.port":            53439,
+		"source.bytes":           71046000,
+		"destination.ip":         "108.206.236.64",
+		"destination.port":       43831,
+	}
+	for k, v := range want {
+		got, err := doc.GetValue(k)
+		assert.Nil(t, err, k)
+		assert.Equal(t, v, got, k)
+	}
+}
+
+func newFirewall(t *testing.T, logTypes map[string]interface{}) *Firewall {
+	t.Helper()
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "seed": 1, "log_types": logTypes})
+	assert.Nil(t, err)
+	g, err := New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g.(*Firewall)
+}
+
+var kv = regexp.MustCompile(`(\w+)=("[^"]*"|\S+)`)
+
+// fields returns the key=value pairs of a record, without the quotes.
+func fields(record string) map[string]string {
+	m := make(map[string]string)
+	for _, match := range kv.FindAllStringSubmatch(record, -1) {
+		m[match[1]] = strings.Trim(match[2], `"`)
+	}
+	return m
+}
+
+func TestLogTypes(t *testing.T) {
+	f := newFirewall(t, map[string]interface{}{"utm-ips": 3, "event-vpn": 1})
+	seen := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		got, err := f.Next()
+		assert.Nil(t, err)
+		m := fields(string(got))
+		seen[m["type"]+"-"+m["subtype"]]++
+	}
+	assert.Len(t, seen, 2)
+	assert.InDelta(t, 750, seen["utm-ips"], 60)
+	assert.InDelta(t, 250, seen["event-vpn"], 60)
+}
+
+func TestWebfilter(t *testing.T) {
+	f := newFirewall(t, map[string]interface{}{"utm-webfilter": 1})
+	for i := 0; i < 100; i++ {
+		got, err := f.Next()
+		assert.Nil(t, err)
+		m := fields(string(got))
+		if m["action"] == "blocked" {
+			assert.Equal(t, "0316013056", m["logid"])
+			assert.Equal(t, "ftgd_blk", m["eventtype"])
+			assert.Equal(t, "0", m["rcvdbyte"])
+		} else {
+			assert.Equal(t, "passthrough", m["action"])
+			assert.Equal(t, "0317013312", m["logid"])
+			assert.Equal(t, "ftgd_allow", m["eventtype"])
+		}
+		assert.Equal(t, "https://"+m["hostname"], m["url"][:len(m["hostname"])+8])
+	}
+}
+
+func TestVpn(t *testing.T) {
+	f := newFirewall(t, map[string]interface{}{"event-vpn": 1})
+	for i := 0; i < 100; i++ {
+		got, err := f.Next()
+		assert.Nil(t, err)
+		m := fields(string(got))
+		switch m["action"] {
+		case "tunnel-up":
+			assert.Equal(t, "0101037138", m["logid"])
+			assert.Equal(t, "0", m["duration"])
+		case "tunnel-down":
+			assert.Equal(t, "0101037141", m["logid"])
+		default:
+			t.Errorf("unexpected action %q", m["action"])
+		}
+		assert.Equal(t, "ipsec", m["tunneltype"])
+	}
+}
+
+var pri = regexp.MustCompile(`^<(\d+)>date=`)
+
+func TestFacility(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "seed": 1, "facility": 23})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	for i := 0; i < 200; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		match := pri.FindStringSubmatch(string(got))
+		if !assert.NotNil(t, match, string(got)) {
+			continue
+		}
+		severity, ok := generator.LevelSeverity(fields(string(got))["level"])
+		assert.True(t, ok, string(got))
+		assert.Equal(t, strconv.Itoa(23*8+severity), match[1], string(got))
+	}
+
+	for i := 0; i < 50; i++ {
+		doc, err := g.(*Firewall).NextECS()
+		assert.Nil(t, err)
+		level, _ := doc.GetValue("log.level")
+		severity, _ := generator.LevelSeverity(level.(string))
+		facility, _ := doc.GetValue("log.syslog.facility.code")
+		assert.Equal(t, 23, facility)
+		priority, _ := doc.GetValue("log.syslog.priority")
+		assert.Equal(t, 23*8+severity, priority)
+		message, _ := doc.GetValue("message")
+		assert.Equal(t, level, fields(message.(string))["level"])
+	}
+
+	// Without facility the logs have no header.
+	got, err := newFirewall(t, map[string]interface{}{"event-user": 1}).Next()
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(got), "date="), string(got))
+}
+
+func TestNextECSLogTypes(t *testing.T) {
+	for _, logType := range msgTypes {
+		f := newFirewall(t, map[string]interface{}{logType: 1})
+		doc, err := f.NextECS()
+		assert.Nil(t, err, logType)
+		parts := strings.SplitN(logType, "-", 2)
+		for k, v := range map[string]string{"fortinet.firewall.type": parts[0], "fortinet.firewall.subtype": parts[1]} {
+			got, err := doc.GetValue(k)
+			assert.Nil(t, err, logType)
+			assert.Equal(t, v, got, logType)
+		}
+		m := fields(doc["message"].(string))
+		code, err := doc.GetValue("event.code")
+		assert.Nil(t, err, logType)
+		if logType != "event-user" && logType != "event-system" && logType != "utm-dns" && logType != "traffic-forward" {
+			assert.Equal(t, m["logid"], code, logType)
+		}
+	}
+}
+
+func TestIPv6(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "seed": 1, "log_types": map[string]interface{}{"traffic-forward": 1}, "ipv6": 25})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	n := 0
+	for i := 0; i < 1000; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		m := fields(string(got))
+		for _, k := range []string{"srcip", "dstip"} {
+			ip := net.ParseIP(m[k])
+			assert.NotNil(t, ip, m[k])
+			if ip.To4() == nil {
+				assert.True(t, ip.IsGlobalUnicast(), m[k])
+				n++
+			}
+		}
+	}
+	assert.InDelta(t, 500, n, 60)
+}
+
+func TestCIDRs(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "seed": 1, "log_types": map[string]interface{}{"traffic-forward": 1}, "src_cidrs": []interface{}{"10.0.0.0/8", "192.168.1.0/24"}, "dst_cidrs": []interface{}{"203.0.113.0/24"}})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	_, internal, _ := net.ParseCIDR("10.0.0.0/8")
+	_, home, _ := net.ParseCIDR("192.168.1.0/24")
+	_, external, _ := net.ParseCIDR("203.0.113.0/24")
+	seen := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		m := fields(string(got))
+		src, dst := net.ParseIP(m["srcip"]), net.ParseIP(m["dstip"])
+		switch {
+		case internal.Contains(src):
+			seen["10.0.0.0/8"]++
+		case home.Contains(src):
+			seen["192.168.1.0/24"]++
+		default:
+			t.Errorf("srcip %s is not in src_cidrs", src)
+		}
+		assert.True(t, external.Contains(dst), dst)
+	}
+	assert.InDelta(t, 500, seen["10.0.0.0/8"], 60)
+	assert.InDelta(t, 500, seen["192.168.1.0/24"], 60)
+}
+
+func TestEntities(t *testing.T) {
+	p, err := entities.Build(ucfg.MustNewFrom(map[string]interface{}{"users": 10, "hosts": 10, "seed": 1}))
+	assert.Nil(t, err)
+	entities.Set(p)
+	defer entities.Set(nil)
+
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "seed": 1, "entities": true, "log_types": map[string]interface{}{"event-user": 1}})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	hosts := map[string]string{}
+	for _, u := range p.Users {
+		hosts[u.Name] = p.HostOf(u).IP.String()
+	}
+	for i := 0; i < 100; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		m := fields(string(got))
+		assert.Contains(t, hosts, m["user"])
+		assert.Equal(t, hosts[m["user"]], m["srcip"], string(got))
+	}
+}
+
+func TestAnomalies(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{
+		"type":      Name,
+		"seed":      1,
+		"log_types": map[string]interface{}{"traffic-forward": 1},
+		"timestamp": map[string]interface{}{"start": "2024-03-04T00:00:00Z", "interval": "1m"},
+		"anomalies": []interface{}{map[string]interface{}{"type": "exfiltration", "rate": 2}},
+	})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	f := g.(*Firewall)
+
+	tagged := 0
+	for i := 0; i < 1000; i++ {
+		doc, err := f.NextECS()
+		assert.Nil(t, err)
+		bytes, err := doc.GetValue("source.bytes")
+		assert.Nil(t, err)
+		tags, err := doc.GetValue("tags")
+		if err != nil {
+			assert.Less(t, bytes, 1<<28, doc)
+			continue
+		}
+		tagged++
+		assert.Equal(t, []string{generator.AnomalyExfiltration}, tags)
+		assert.GreaterOrEqual(t, bytes, 1000, doc)
+	}
+	assert.Greater(t, tagged, 10)
+}
+
+func TestUpgrade(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{
+		"type":      Name,
+		"seed":      1,
+		"log_types": map[string]interface{}{"traffic-forward": 1, "traffic-local": 1, "utm-dns": 1},
+		"timestamp": map[string]interface{}{"start": "2024-03-04T00:00:00Z", "interval": "1s"},
+		"upgrade":   map[string]interface{}{"version": 7, "at": "2024-03-04T00:05:00Z"},
+	})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	upgrade := time.Date(2024, 3, 4, 0, 5, 0, 0, time.UTC)
+	for i := 0; i < 600; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		m := fields(string(got))
+		// Event i is at i seconds, the first 300 are before the upgrade.
+		at := upgrade.Add(time.Duration(i-300) * time.Second)
+		if i < 300 {
+			assert.Equal(t, strconv.FormatInt(at.Unix(), 10), m["eventtime"], string(got))
+			assert.NotContains(t, m, "sentdelta", string(got))
+			continue
+		}
+		assert.Equal(t, strconv.FormatInt(at.UnixNano(), 10), m["eventtime"], string(got))
+		assert.Equal(t, "-0500", m["tz"], string(got))
+		if m["type"] == "traffic" {
+			assert.Contains(t, m, "sentdelta", string(got))
+			assert.Contains(t, m, "rcvddelta", string(got))
+		}
+	}
+}
@@ -4,33 +4,111 @@
 package generator
 
 import (
+	"math/rand/v2"
 	"strings"
 	"text/template"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/expr"
+	"github.com/leehinman/spigot/pkg/random"
 )
 
 var (
 	FunctionMap = template.FuncMap{
 		"ToLower": strings.ToLower,
 		"ToUpper": strings.ToUpper,
+		"eval":    expr.TemplateEval,
+		"dict":    dict,
+		"pairs":   kvPairs,
 	}
 )
 
 // Generator is the interface that wraps the Next method.
 //
 // Next generates the next log message and returns it as an array of bytes.
+//
+// A message is one event, which may span lines, such as a stack trace
+// or the records of an audit event.  The lines are separated by "\n"
+// and the message has no trailing line break, the outputs frame it:
+// the file output writes it as it is, the syslog output escapes or
+// splits the line breaks and the JSON outputs encode it as a string.
 type Generator interface {
 	Next() ([]byte, error)
 }
 
+// BinaryGenerator is implemented by generators whose events are
+// binary, such as NetFlow packets or protobuf messages, instead of
+// text.  The runner writes them with output.BinaryOutput.
+type BinaryGenerator interface {
+	Generator
+	Binary() bool
+}
+
+// IsBinary reports whether the events of g are binary.
+func IsBinary(g Generator) bool {
+	b, ok := g.(BinaryGenerator)
+	return ok && b.Binary()
+}
+
 type config struct {
-	Type string `config:"type" validate:"required"`
+	Type   string `config:"type" validate:"required"`
+	Format string `config:"format"`
 }
 
 // New creates a new instance of the generator that is specified by
 // the "type" in the ucfg.Config that is passed in.  If no matching
 // generator is found for that type than an error is returned.
+//
+// With "format: json" the generator writes its events as ECS JSON
+// documents instead, if it implements ECSGenerator.
+//
+// With "corrupt" a "percent" of the events is corrupted, to test the
+// handling of events a parser fails on.  The "kinds" of corruption
+// are truncate, invalid_utf8, swap_delimiters, which replaces the
+// delimiter of the fields with another one, and missing_fields, which
+// drops a field, by default all of them:
+//
+//	generator:
+//	  type: cisco:asa
+//	  corrupt:
+//	    percent: 2
+//	    kinds: [truncate, invalid_utf8]
+//
+// Binary events are only truncated.
+//
+// With "stress" a "percent" of the JSON events, by default all of
+// them, probe the limits of mappers and parsers, with one of a URL of
+// "long_field" bytes in url.original, "keys" keys in labels or objects
+// nested "depth" levels deep in nested.  The stressed events are tagged
+// with "stress_" and the kind, such as "stress_long_field":
+//
+//	generator:
+//	  type: json:schema
+//	  schema_file: event.json
+//	  stress:
+//	    percent: 1
+//	    long_field: 65536
+//	    keys: 2000
+//	    depth: 100
+//
+// The events of the generator must be JSON objects, such as those of
+// the json format.
+//
+// With "payload" every event has a payload of "length" bytes of text,
+// to tune the compressibility of the events.  Its "entropy", from 0
+// to 1, by default 0.5, is the share of the words that are random
+// tokens instead of one of a few repeated words.  A "ratio" instead
+// picks the entropy of the payloads that gzip compresses by that
+// ratio:
+//
+//	generator:
+//	  type: cisco:asa
+//	  payload:
+//	    length: 256
+//	    ratio: 4
+//
+// JSON objects have the payload in "payload", other events after a
+// space.
 func New(cfg *ucfg.Config) (Generator, error) {
 	c := config{}
 	err := cfg.Unpack(&c)
@@ -41,5 +119,40 @@ func New(cfg *ucfg.Config) (Generator, error) {
 	if err != nil {
 		return nil, err
 	}
-	return factory(cfg)
+	g, err := newFormat(factory, c, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if g, err = newStress(g, cfg); err != nil {
+		return nil, err
+	}
+	if g, err = newPayload(g, cfg); err != nil {
+		return nil, err
+	}
+	return newCorrupt(g, cfg)
+}
+
+type seedConfig struct {
+	Seed *int64 `config:"seed"`
+}
+
+// NewRand returns the *rand.Rand a generator should use, seeded with
+// the optional "seed" in the ucfg.Config.  Without a seed the
+// generator gets its own stream derived from the run seed.
+func NewRand(cfg *ucfg.Config) (*rand.Rand, error) {
+	c := seedConfig{}
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	return random.NewRand(c.Seed), nil
+}
+
+// newWrapperRand returns the *rand.Rand of the wrapper of stream, like
+// NewRand, but with a seed a stream other than that of the generator.
+func newWrapperRand(cfg *ucfg.Config, stream uint64) (*rand.Rand, error) {
+	c := seedConfig{}
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	return random.NewWrapperRand(c.Seed, stream), nil
 }
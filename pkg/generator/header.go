@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-ucfg"
+)
+
+// Headers of the "header" option of the CEF generators, the syslog
+// header written before "CEF:".  The parsers of CEF differ on what
+// they expect before it, nothing, an RFC 3164 or an RFC 5424 header.
+const (
+	HeaderNone    = "none"
+	HeaderRFC3164 = "rfc3164"
+	HeaderRFC5424 = "rfc5424"
+)
+
+var headers = [...]string{HeaderNone, HeaderRFC3164, HeaderRFC5424}
+
+type headerConfig struct {
+	Header string `config:"header"`
+}
+
+func (c *headerConfig) Validate() error {
+	if c.Header != "" && !contains(headers[:], c.Header) {
+		return fmt.Errorf("'%s' is not a valid value for 'header' expected '%s'", c.Header, strings.Join(headers[:], ", "))
+	}
+	return nil
+}
+
+// NewHeader returns the header of the optional "header" in the
+// ucfg.Config, for the cef_syslog_header partial.  Without a header it
+// returns "", which the partial writes as HeaderNone, so a generator
+// can keep a header of its own for it.
+func NewHeader(cfg *ucfg.Config) (string, error) {
+	c := headerConfig{}
+	if err := cfg.Unpack(&c); err != nil {
+		return "", err
+	}
+	return c.Header, nil
+}
+
+// CEFPriority returns the syslog priority of an event of the CEF
+// severity, from 0 to 10, with the user facility.  The severities of
+// CEF are Low (0-3) for info, Medium (4-6) for warning, High (7-8) for
+// err and Very-High (9-10) for crit.
+func CEFPriority(severity int) int {
+	const user = 1
+	switch {
+	case severity >= 9:
+		return user*8 + 2
+	case severity >= 7:
+		return user*8 + 3
+	case severity >= 4:
+		return user*8 + 4
+	default:
+		return user*8 + 6
+	}
+}
@@ -0,0 +1,44 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHeader(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		want        string
+		errorString string
+	}{
+		"Default": {
+			c:    map[string]interface{}{},
+			want: "",
+		},
+		"RFC 5424": {
+			c:    map[string]interface{}{"header": "rfc5424"},
+			want: HeaderRFC5424,
+		},
+		"Invalid": {
+			c:           map[string]interface{}{"header": "leef"},
+			errorString: "'leef' is not a valid value for 'header' expected 'none, rfc3164, rfc5424' accessing config",
+		},
+	}
+	for name, tc := range tests {
+		got, err := NewHeader(ucfg.MustNewFrom(tc.c))
+		if tc.errorString != "" {
+			assert.EqualError(t, err, tc.errorString, name)
+			continue
+		}
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.want, got, name)
+	}
+}
+
+func TestCEFPriority(t *testing.T) {
+	for severity, want := range map[int]int{0: 14, 3: 14, 4: 12, 6: 12, 7: 11, 8: 11, 9: 10, 10: 10} {
+		assert.Equal(t, want, CEFPriority(severity), severity)
+	}
+}
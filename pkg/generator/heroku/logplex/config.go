@@ -0,0 +1,30 @@
+package logplex
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Type      string `config:"type" validate:"required"`
+	EventType string `config:"event_type"`
+	App       string `config:"app"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type: Name,
+		App:  "spigot-demo",
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if !(c.EventType == "" || c.EventType == EventTypeRouter || c.EventType == EventTypeApp || c.EventType == EventTypeHeroku) {
+		return fmt.Errorf("'%s' is not a valid value for 'event_type' expected '%s'", c.EventType, strings.Join([]string{EventTypeRouter, EventTypeApp, EventTypeHeroku}, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package logplex
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Router": {
+			c:           map[string]interface{}{"type": Name, "event_type": "router"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with App": {
+			c:           map[string]interface{}{"type": Name, "event_type": "app", "app": "shop"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Heroku": {
+			c:           map[string]interface{}{"type": Name, "event_type": "heroku"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'heroku:logplex' accessing config",
+		},
+		"Invalid Event Type": {
+			c:           map[string]interface{}{"type": Name, "event_type": "addon"},
+			hasError:    true,
+			errorString: "'addon' is not a valid value for 'event_type' expected 'router, app, heroku' accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			_, err = New(c)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+// Package logplex generates Heroku Logplex log drain messages.
+//
+// Messages are RFC 5424 syslog lines as Heroku sends them to log
+// drains.  Router messages carry the request fields logged by the
+// Heroku router (method, path, dyno, connect and service times,
+// status and bytes) including H error codes, app messages are lines
+// written by the application's dynos, and heroku messages are dyno
+// state changes logged by the platform.
+//
+// Use the http output with "framing: logplex" to deliver the
+// messages with the octet counted framing and headers of an HTTPS
+// drain.
+//
+// Configuration:
+//
+//	event_type: Specify the type of message to generate, or leave blank for random.
+//	            Valid values are: router, app, heroku.
+//	app: Name of the Heroku app, defaults to "spigot-demo".
+//
+//	- generator:
+//	    type: heroku:logplex
+//	    event_type: router
+//	    app: shop
+package logplex
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "heroku:logplex"
+
+const (
+	EventTypeRouter = "router"
+	EventTypeApp    = "app"
+	EventTypeHeroku = "heroku"
+)
+
+var (
+	header         = `{{template "syslog5424_header" (dict "Priority" .Priority "Timestamp" .Timestamp "Layout" "2006-01-02T15:04:05.000000+00:00" "Host" "host" "AppName" .AppName "ProcID" .ProcID)}}`
+	routerTemplate = header + "at={{.At}}{{with .Code}} code={{.}} desc=\"{{$.Description}}\"{{end}} method={{.Method}} path=\"{{.Path}}\" host={{.Host}} request_id={{.RequestID}} fwd=\"{{.Fwd}}\" dyno={{.Dyno}} connect={{.Connect}}ms service={{.Service}}ms status={{.Status}} bytes={{.Bytes}} protocol={{.Protocol}}"
+	appTemplates   = [...]string{
+		header + "{{.Fwd}} - - [{{.Timestamp.Format \"02/Jan/2006:15:04:05 -0700\"}}] \"{{.Method}} {{.Path}} HTTP/1.1\" {{.Status}} {{.Bytes}}",
+		header + "Started {{.Method}} \"{{.Path}}\" for {{.Fwd}} at {{.Timestamp.Format \"2006-01-02 15:04:05 -0700\"}}",
+		header + "Completed {{.Status}} in {{.Service}}ms",
+		header + "source={{.Dyno}} sample#memory_total={{.Memory}}MB sample#memory_rss={{.Memory}}MB sample#memory_cache=0.00MB",
+	}
+	herokuTemplates = [...]string{
+		header + "State changed from starting to up",
+		header + "State changed from up to down",
+		header + "Starting process with command `{{.Command}}`",
+		header + "Process exited with status {{.ExitStatus}}",
+		header + "Error R14 (Memory quota exceeded)",
+		header + "Cycling",
+	}
+	routerErrors = [...]struct {
+		code        string
+		description string
+		status      int
+	}{
+		{code: "H10", description: "App crashed", status: 503},
+		{code: "H12", description: "Request timeout", status: 503},
+		{code: "H13", description: "Connection closed without response", status: 503},
+		{code: "H14", description: "No web processes running", status: 503},
+		{code: "H18", description: "Server Request Interrupted", status: 503},
+	}
+	processes = [...]struct {
+		name    string
+		count   int
+		command string
+	}{
+		{name: "web", count: 4, command: "bundle exec puma -C config/puma.rb"},
+		{name: "worker", count: 2, command: "bundle exec sidekiq"},
+	}
+	paths     = [...]string{"/", "/login", "/products", "/products/42", "/cart", "/api/v1/orders", "/assets/application.css", "/healthz"}
+	statuses  = [...]int{200, 200, 200, 200, 201, 204, 301, 302, 304, 400, 401, 404, 422, 500}
+	protocols = [...]string{"https", "https", "http"}
+)
+
+// Logplex holds the random fields for a Logplex drain message.
+type Logplex struct {
+	AppName     string
+	At          string
+	Bytes       int
+	Code        string
+	Command     string
+	Connect     int
+	Description string
+	Dyno        string
+	ExitStatus  int
+	Fwd         net.IP
+	Host        string
+	Memory      string
+	Method      string
+	Path        string
+	Priority    int
+	ProcID      string
+	Protocol    string
+	RequestID   string
+	Service     int
+	Status      int
+	Timestamp   time.Time
+
+	app        string
+	eventType  string
+	rand       *rand.Rand
+	pins       *generator.Pins
+	staticTime *time.Time
+	clock      *generator.Clock
+	templates  map[string][]*template.Template
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Logplex objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Logplex{
+		app:       c.App,
+		eventType: c.EventType,
+		rand:      r,
+		clock:     clock,
+		templates: make(map[string][]*template.Template),
+	}
+
+	l.pins, err = generator.NewPins(cfg, r, l)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := map[string][]string{
+		EventTypeRouter: {routerTemplate},
+		EventTypeApp:    appTemplates[:],
+		EventTypeHeroku: herokuTemplates[:],
+	}
+	for k, v := range sets {
+		for i, text := range v {
+			t, err := generator.NewTemplate(fmt.Sprintf("%s%d", k, i), text)
+			if err != nil {
+				return nil, err
+			}
+			l.templates[k] = append(l.templates[k], t)
+		}
+	}
+
+	return l, nil
+}
+
+// Next produces the next Logplex drain message.
+//
+// Example:
+//
+// <158>1 2023-01-02T03:04:05.123456+00:00 host heroku router - at=info method=GET path="/" host=spigot-demo.herokuapp.com request_id=8601b555-6a83-4c12-8269-97c8e32cdb22 fwd="204.204.204.204" dyno=web.1 connect=1ms service=18ms status=200 bytes=13 protocol=https
+func (l *Logplex) Next() ([]byte, error) {
+	var buf bytes.Buffer
+
+	eventType := l.eventType
+	if eventType == "" {
+		eventType = [...]string{EventTypeRouter, EventTypeApp, EventTypeHeroku}[l.rand.IntN(3)]
+	}
+
+	l.randomize(eventType)
+
+	templates := l.templates[eventType]
+	err := templates[l.rand.IntN(len(templates))].Execute(&buf, l)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), err
+}
+
+func (l *Logplex) randomize(eventType string) {
+	p := processes[l.rand.IntN(len(processes))]
+	if eventType == EventTypeRouter {
+		p = processes[0]
+	}
+
+	l.Timestamp = l.getTime()
+	l.Dyno = fmt.Sprintf("%s.%d", p.name, l.rand.IntN(p.count)+1)
+	l.Command = p.command
+	l.Method = random.HTTPMethod(l.rand)
+	l.Path = paths[l.rand.IntN(len(paths))]
+	l.Host = l.app + ".herokuapp.com"
+	l.RequestID = randomUUID(l.rand)
+	l.Fwd = random.IPv4(l.rand)
+	l.Connect = l.rand.IntN(5)
+	l.Service = l.rand.IntN(500) + 1
+	l.Status = statuses[l.rand.IntN(len(statuses))]
+	l.Bytes = l.rand.IntN(65536)
+	l.Protocol = protocols[l.rand.IntN(len(protocols))]
+	l.Memory = fmt.Sprintf("%.2f", l.rand.Float64()*512)
+	l.ExitStatus = [...]int{0, 0, 1, 137, 143}[l.rand.IntN(5)]
+	l.At = "info"
+	l.Code = ""
+	l.Description = ""
+
+	switch eventType {
+	case EventTypeRouter:
+		l.Priority = 158
+		l.AppName = "heroku"
+		l.ProcID = "router"
+		if l.rand.IntN(20) == 0 {
+			e := routerErrors[l.rand.IntN(len(routerErrors))]
+			l.At = "error"
+			l.Code = e.code
+			l.Description = e.description
+			l.Status = e.status
+			l.Bytes = 0
+			if e.code == "H12" {
+				l.Service = 30000
+			}
+		}
+	case EventTypeApp:
+		l.Priority = 190
+		l.AppName = "app"
+		l.ProcID = l.Dyno
+	case EventTypeHeroku:
+		l.Priority = 45
+		l.AppName = "heroku"
+		l.ProcID = l.Dyno
+	}
+
+	l.pins.Apply(l)
+}
+
+func randomUUID(r *rand.Rand) string {
+	return fmt.Sprintf("%08x-%04x-4%03x-%04x-%012x", r.Uint32(), r.IntN(1<<16), r.IntN(1<<12), r.IntN(1<<14)|0x8000, r.Int64N(1<<48))
+}
+
+func (l *Logplex) getTime() time.Time {
+	if l.staticTime != nil {
+		return *l.staticTime
+	}
+
+	return l.clock.Now().UTC()
+}
+
+// Templates returns the templates of the generator, for generator.Lint.
+func (l *Logplex) Templates() (interface{}, []*template.Template) {
+	var templates []*template.Template
+	for _, t := range l.templates {
+		templates = append(templates, t...)
+	}
+	return l, templates
+}
@@ -0,0 +1,59 @@
+package logplex
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		eventType string
+		expected  string
+	}{
+		"Router": {eventType: EventTypeRouter, expected: `<158>1 1970-01-02T03:04:05.123456+00:00 host heroku router - at=info method=PUT path="/products/42" host=spigot-demo.herokuapp.com request_id=bde2ffbd-d9a2-4398-90bf-ce6c39c0bb83 fwd="24.223.140.158" dyno=web.4 connect=4ms service=78ms status=200 bytes=29730 protocol=https`},
+		"App":    {eventType: EventTypeApp, expected: `<190>1 1970-01-02T03:04:05.123456+00:00 host app web.4 - Started PUT "/products/42" for 24.223.140.158 at 1970-01-02 03:04:05 +0000`},
+		"Heroku": {eventType: EventTypeHeroku, expected: `<45>1 1970-01-02T03:04:05.123456+00:00 host heroku web.4 - Error R14 (Memory quota exceeded)`},
+	}
+	for name, tc := range tests {
+		random.Seed(1)
+		l := newLogplex(t, tc.eventType)
+		got, err := l.Next()
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, string(got), name)
+	}
+}
+
+func TestRouterErrors(t *testing.T) {
+	random.Seed(1)
+	l := newLogplex(t, EventTypeRouter)
+
+	errors := 0
+	for i := 0; i < 1000; i++ {
+		got, err := l.Next()
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(string(got), "<158>1 1970-01-02T03:04:05.123456+00:00 host heroku router - at="))
+		if !strings.Contains(string(got), "at=error") {
+			continue
+		}
+		errors++
+		assert.Contains(t, string(got), " status=503 bytes=0 ")
+	}
+	assert.NotZero(t, errors)
+}
+
+func newLogplex(t *testing.T, eventType string) *Logplex {
+	testTime, err := time.Parse(time.RFC3339Nano, "1970-01-02T03:04:05.123456Z")
+	assert.Nil(t, err)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "event_type": eventType})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	l := g.(*Logplex)
+	l.staticTime = &testTime
+	return l
+}
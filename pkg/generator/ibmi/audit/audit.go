@@ -0,0 +1,234 @@
+// Package audit generates IBM i (AS/400) security audit journal entries.
+//
+// Entries are modelled on the QAUDJRN journal entry types (AF, PW,
+// CA, CP, DO, SV, ZR, ZC, CD and PS) and are written in the two forms
+// commonly produced when forwarding the journal to a SIEM: a syslog
+// message with the entry fields as structured data, and CEF.
+//
+// Configuration:
+//
+//	format: Specify the format of entries to generate, or leave blank for random.
+//	        Valid values are: syslog, cef.
+//	header: The syslog header before "CEF:" of the cef entries, none (the
+//	        default), rfc3164 or rfc5424.
+//
+//	- generator:
+//	    type: ibmi:audit
+//	    format: cef
+//	    header: rfc3164
+package audit
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "ibmi:audit"
+
+const (
+	FormatSyslog = "syslog"
+	FormatCEF    = "cef"
+)
+
+var (
+	syslogTemplate = `QAUDJRN: [{{.EntryType}}@0 event="{{.EntryType}}-{{.EntryDescription}}" event_type="{{.SubType}}-{{.SubTypeDescription}}" sequence="{{.Sequence}}" timestamp="{{.Timestamp.Format "2006-01-02-15.04.05.000000"}}" system_name="{{.SystemName}}" job_name="{{.JobName}}" job_user="{{.JobUser}}" job_number="{{.JobNumber}}" current_user="{{.CurrentUser}}" program_name="{{.ProgramName}}" program_library="{{.ProgramLibrary}}" object="{{.Object}}" object_library="{{.ObjectLibrary}}" object_type="{{.ObjectType}}" remote_address="{{.RemoteAddr}}" remote_port="{{.RemotePort}}"]`
+	cefTemplate    = `{{template "cef_syslog_header" (dict "Header" .Header "Priority" .Priority "Timestamp" .Timestamp "Host" .SystemName "AppName" "QAUDJRN")}}{{template "cef_header" (dict "Vendor" "IBM" "Product" "IBM i" "Version" .Release "SignatureID" .EntryType "Name" .EntryDescription "Severity" .Severity)}}rt={{.Timestamp.UnixMilli}} dvchost={{.SystemName}} suser={{.CurrentUser}} src={{.RemoteAddr}} spt={{.RemotePort}} act={{.SubType}}-{{.SubTypeDescription}} sproc={{.ProgramLibrary}}/{{.ProgramName}} fname={{.ObjectLibrary}}/{{.Object}} fileType={{.ObjectType}} cs1Label=JobName cs1={{.JobNumber}}/{{.JobUser}}/{{.JobName}} cn1Label=SequenceNumber cn1={{.Sequence}}`
+
+	entries = [...]struct {
+		entryType   string
+		description string
+		severity    int
+		subTypes    [][2]string
+		objectTypes []string
+	}{
+		{entryType: "AF", description: "Authority failure", severity: 7, subTypes: [][2]string{{"A", "Not authorized to object"}, {"C", "Object validation failure"}, {"P", "Profile token not valid"}, {"S", "Default sign-on attempt"}}, objectTypes: []string{"*FILE", "*PGM", "*DTAARA"}},
+		{entryType: "PW", description: "Password not valid", severity: 6, subTypes: [][2]string{{"P", "Password not valid"}, {"U", "User profile name not valid"}, {"Q", "Attempted sign-on, user profile disabled"}}, objectTypes: []string{"*USRPRF"}},
+		{entryType: "CA", description: "Authority changes", severity: 5, subTypes: [][2]string{{"A", "Change to object authority"}}, objectTypes: []string{"*FILE", "*LIB", "*PGM"}},
+		{entryType: "CP", description: "User profile changed, created, or restored", severity: 5, subTypes: [][2]string{{"A", "Create"}, {"C", "Change"}, {"D", "Delete"}, {"P", "Password changed"}}, objectTypes: []string{"*USRPRF"}},
+		{entryType: "DO", description: "Delete operation", severity: 4, subTypes: [][2]string{{"A", "Object was deleted"}}, objectTypes: []string{"*FILE", "*PGM", "*DTAARA", "*JRNRCV"}},
+		{entryType: "SV", description: "System value changed", severity: 8, subTypes: [][2]string{{"A", "Change to system values"}}, objectTypes: []string{"*SYSVAL"}},
+		{entryType: "ZR", description: "Read of object", severity: 2, subTypes: [][2]string{{"R", "Read of object"}}, objectTypes: []string{"*FILE", "*DTAARA"}},
+		{entryType: "ZC", description: "Change to object", severity: 3, subTypes: [][2]string{{"C", "Change to object"}, {"U", "Upgrade of open access to an object"}}, objectTypes: []string{"*FILE", "*DTAARA", "*PGM"}},
+		{entryType: "CD", description: "Command string audit", severity: 3, subTypes: [][2]string{{"C", "Command run"}, {"L", "S/36E control language statement"}}, objectTypes: []string{"*CMD"}},
+		{entryType: "PS", description: "Profile swap", severity: 6, subTypes: [][2]string{{"A", "Profile swap"}, {"H", "Profile handle generated"}}, objectTypes: []string{"*USRPRF"}},
+	}
+	objects = map[string][][2]string{
+		"*FILE":   {{"PAYROLL", "HRLIB"}, {"CUSTMAST", "SALESLIB"}, {"ORDHDR", "SALESLIB"}, {"GLDETL", "FINLIB"}, {"QAUDJRN", "QSYS"}},
+		"*PGM":    {{"PAY001R", "HRLIB"}, {"ORD100", "SALESLIB"}, {"QCMD", "QSYS"}},
+		"*DTAARA": {{"NEXTINV", "FINLIB"}, {"SYSCTL", "APPLIB"}},
+		"*LIB":    {{"HRLIB", "QSYS"}, {"FINLIB", "QSYS"}},
+		"*JRNRCV": {{"AUDRCV0042", "QGPL"}},
+		"*USRPRF": {{"JDOE", "QSYS"}, {"QSECOFR", "QSYS"}, {"BATCHUSR", "QSYS"}, {"ASMITH", "QSYS"}},
+		"*SYSVAL": {{"QSECURITY", "QSYS"}, {"QAUDCTL", "QSYS"}, {"QPWDEXPITV", "QSYS"}, {"QMAXSIGN", "QSYS"}},
+		"*CMD":    {{"CHGUSRPRF", "QSYS"}, {"STRSQL", "QSYS"}, {"DSPFD", "QSYS"}, {"CHGSYSVAL", "QSYS"}},
+	}
+	systems  = [...]string{"PRODAS4", "DEVAS4", "S1024A7B"}
+	releases = [...]string{"V7R3M0", "V7R4M0", "V7R5M0"}
+	users    = [...]string{"JDOE", "ASMITH", "QSECOFR", "BATCHUSR", "QUSER", "OPERATOR"}
+	jobs     = [...]string{"QPADEV0001", "QPADEV0007", "QZDASOINIT", "QZRCSRVS", "QRWTSRVR", "NIGHTLY"}
+	programs = [...][2]string{{"QCMD", "QSYS"}, {"QZDASOINIT", "QSYS"}, {"QTMFTPS", "QSYS"}, {"PAY001R", "HRLIB"}, {"QSQSRVR", "QSYS"}}
+)
+
+// Audit holds the random fields for an IBM i audit journal entry.
+type Audit struct {
+	CurrentUser        string
+	EntryDescription   string
+	EntryType          string
+	JobName            string
+	JobNumber          string
+	JobUser            string
+	Object             string
+	ObjectLibrary      string
+	ObjectType         string
+	Priority           int
+	ProgramLibrary     string
+	ProgramName        string
+	Release            string
+	RemoteAddr         net.IP
+	RemotePort         int
+	Sequence           int
+	Severity           int
+	SubType            string
+	SubTypeDescription string
+	SystemName         string
+	Timestamp          time.Time
+
+	header    string
+	format    string
+	rand      *rand.Rand
+	clock     *generator.Clock
+	pins      *generator.Pins
+	templates map[string]*template.Template
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Audit objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := generator.NewHeader(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Audit{
+		header:    header,
+		format:    c.Format,
+		rand:      r,
+		clock:     clock,
+		templates: make(map[string]*template.Template),
+		Sequence:  r.IntN(1000000),
+	}
+
+	a.pins, err = generator.NewPins(cfg, r, a)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range map[string]string{FormatSyslog: syslogTemplate, FormatCEF: cefTemplate} {
+		t, err := generator.NewTemplate(k, v)
+		if err != nil {
+			return nil, err
+		}
+		a.templates[k] = t
+	}
+
+	a.randomize()
+
+	return a, nil
+}
+
+// Next produces the next IBM i audit journal entry.
+//
+// Example:
+//
+// CEF:0|IBM|IBM i|V7R4M0|PW|Password not valid|6|rt=1672628645000 dvchost=PRODAS4 suser=QUSER src=10.1.2.3 spt=50123 act=P-Password not valid sproc=QSYS/QZDASOINIT fname=QSYS/JDOE fileType=*USRPRF cs1Label=JobName cs1=123456/QUSER/QZDASOINIT cn1Label=SequenceNumber cn1=42
+func (a *Audit) Next() ([]byte, error) {
+	var buf bytes.Buffer
+
+	format := a.format
+	if format == "" {
+		format = [...]string{FormatSyslog, FormatCEF}[a.rand.IntN(2)]
+	}
+
+	err := a.templates[format].Execute(&buf, a)
+	if err != nil {
+		return nil, err
+	}
+
+	a.randomize()
+
+	return buf.Bytes(), err
+}
+
+func (a *Audit) randomize() {
+	e := entries[a.rand.IntN(len(entries))]
+	st := e.subTypes[a.rand.IntN(len(e.subTypes))]
+	a.ObjectType = e.objectTypes[a.rand.IntN(len(e.objectTypes))]
+	obj := objects[a.ObjectType][a.rand.IntN(len(objects[a.ObjectType]))]
+	pgm := programs[a.rand.IntN(len(programs))]
+
+	a.EntryType = e.entryType
+	a.EntryDescription = e.description
+	a.Severity = e.severity
+	a.SubType = st[0]
+	a.SubTypeDescription = st[1]
+	a.Object = obj[0]
+	a.ObjectLibrary = obj[1]
+	a.ProgramName = pgm[0]
+	a.ProgramLibrary = pgm[1]
+	a.Sequence++
+	a.Timestamp = a.clock.Now()
+	a.SystemName = systems[a.rand.IntN(len(systems))]
+	a.Release = releases[a.rand.IntN(len(releases))]
+	a.JobName = jobs[a.rand.IntN(len(jobs))]
+	a.JobNumber = fmt.Sprintf("%06d", a.rand.IntN(1000000))
+	a.CurrentUser = users[a.rand.IntN(len(users))]
+	a.JobUser = a.CurrentUser
+	if a.JobName == "QZDASOINIT" || a.JobName == "QZRCSRVS" || a.JobName == "QRWTSRVR" {
+		a.JobUser = "QUSER"
+	}
+	a.RemoteAddr = random.IPv4(a.rand)
+	a.RemotePort = random.Port(a.rand)
+
+	a.pins.Apply(a)
+	a.Priority = generator.CEFPriority(a.Severity)
+}
+
+// Header returns the header option, for the cef_syslog_header partial.
+func (a *Audit) Header() string {
+	return a.header
+}
+
+// Templates returns the templates of the generator, for generator.Lint.
+func (a *Audit) Templates() (interface{}, []*template.Template) {
+	var templates []*template.Template
+	for _, t := range a.templates {
+		templates = append(templates, t)
+	}
+	return a, templates
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		format   string
+		header   string
+		expected string
+	}{
+		"Syslog":       {format: FormatSyslog, expected: `QAUDJRN: [DO@0 event="DO-Delete operation" event_type="A-Object was deleted" sequence="877178" timestamp="1970-01-02-03.04.05.123456" system_name="S1024A7B" job_name="QPADEV0007" job_user="OPERATOR" job_number="619337" current_user="OPERATOR" program_name="QCMD" program_library="QSYS" object="AUDRCV0042" object_library="QGPL" object_type="*JRNRCV" remote_address="202.184.111.39" remote_port="49867"]`},
+		"CEF":          {format: FormatCEF, expected: `CEF:0|IBM|IBM i|V7R5M0|DO|Delete operation|4|rt=97445123 dvchost=S1024A7B suser=OPERATOR src=202.184.111.39 spt=49867 act=A-Object was deleted sproc=QSYS/QCMD fname=QGPL/AUDRCV0042 fileType=*JRNRCV cs1Label=JobName cs1=619337/OPERATOR/QPADEV0007 cn1Label=SequenceNumber cn1=877178`},
+		"CEF RFC 3164": {format: FormatCEF, header: "rfc3164", expected: `<12>Jan  2 03:04:05 S1024A7B CEF:0|IBM|IBM i|V7R5M0|DO|Delete operation|4|rt=97445123 dvchost=S1024A7B suser=OPERATOR src=202.184.111.39 spt=49867 act=A-Object was deleted sproc=QSYS/QCMD fname=QGPL/AUDRCV0042 fileType=*JRNRCV cs1Label=JobName cs1=619337/OPERATOR/QPADEV0007 cn1Label=SequenceNumber cn1=877178`},
+		"CEF RFC 5424": {format: FormatCEF, header: "rfc5424", expected: `<12>1 1970-01-02T03:04:05.123456Z S1024A7B QAUDJRN - - CEF:0|IBM|IBM i|V7R5M0|DO|Delete operation|4|rt=97445123 dvchost=S1024A7B suser=OPERATOR src=202.184.111.39 spt=49867 act=A-Object was deleted sproc=QSYS/QCMD fname=QGPL/AUDRCV0042 fileType=*JRNRCV cs1Label=JobName cs1=619337/OPERATOR/QPADEV0007 cn1Label=SequenceNumber cn1=877178`},
+	}
+	testTime, err := time.Parse(time.RFC3339Nano, "1970-01-02T03:04:05.123456Z")
+	assert.Nil(t, err)
+	for name, tc := range tests {
+		random.Seed(1)
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "format": tc.format, "header": tc.header})
+		assert.Nil(t, err, name)
+		g, err := New(c)
+		assert.Nil(t, err, name)
+		a := g.(*Audit)
+		a.Timestamp = testTime
+		got, err := a.Next()
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, string(got), name)
+	}
+}
@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Type   string `config:"type" validate:"required"`
+	Format string `config:"format"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type: Name,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if !(c.Format == "" || c.Format == FormatSyslog || c.Format == FormatCEF) {
+		return fmt.Errorf("'%s' is not a valid value for 'format' expected '%s'", c.Format, strings.Join([]string{FormatSyslog, FormatCEF}, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package line
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+type config struct {
+	Type        string               `config:"type" validate:"required"`
+	Measurement string               `config:"measurement"`
+	Tags        []tagConfig          `config:"tags"`
+	Fields      []random.FieldConfig `config:"fields" validate:"required"`
+	Precision   string               `config:"precision"`
+}
+
+// tagConfig is a tag of the points, with one of values or of
+// cardinality values "<name>-<n>".
+type tagConfig struct {
+	Name        string   `config:"name" validate:"required"`
+	Cardinality int      `config:"cardinality"`
+	Values      []string `config:"values"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:        Name,
+		Measurement: "spigot",
+		Precision:   PrecisionNanosecond,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if c.Measurement == "" {
+		return fmt.Errorf("'measurement' can not be empty")
+	}
+	if !contains(precisions[:], c.Precision) {
+		return fmt.Errorf("'%s' is not a valid value for 'precision' expected '%s'", c.Precision, strings.Join(precisions[:], ", "))
+	}
+	names := make(map[string]bool)
+	for _, t := range c.Tags {
+		if names[t.Name] {
+			return fmt.Errorf("'%s' is not a valid value for 'tags.name' expected a unique name", t.Name)
+		}
+		names[t.Name] = true
+	}
+	names = make(map[string]bool)
+	always := false
+	for _, f := range c.Fields {
+		if names[f.Name] {
+			return fmt.Errorf("'%s' is not a valid value for 'fields.name' expected a unique name", f.Name)
+		}
+		names[f.Name] = true
+		always = always || f.Empty == 0
+	}
+	if len(c.Fields) > 0 && !always {
+		return fmt.Errorf("at least one of 'fields' must never be empty")
+	}
+	return nil
+}
+
+func (c *tagConfig) Validate() error {
+	if c.Cardinality < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'cardinality' expected 0 or more", c.Cardinality)
+	}
+	if c.Cardinality > 0 && len(c.Values) > 0 {
+		return fmt.Errorf("only one of cardinality and values can be set")
+	}
+	for _, v := range c.Values {
+		if v == "" {
+			return fmt.Errorf("'' is not a valid value for 'values' expected a tag value")
+		}
+	}
+	return nil
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
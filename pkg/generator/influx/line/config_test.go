@@ -0,0 +1,85 @@
+package line
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	field := []map[string]interface{}{{"name": "value", "type": "number"}}
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name, "fields": field},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Tags": {
+			c:           map[string]interface{}{"type": Name, "fields": field, "measurement": "cpu", "precision": "s", "tags": []map[string]interface{}{{"name": "host", "cardinality": 1000}, {"name": "dc", "values": []string{"ams", "fra"}}}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "fields": field},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'influx:line' accessing config",
+		},
+		"No Fields": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    true,
+			errorString: "missing required field accessing 'fields'",
+		},
+		"Empty Measurement": {
+			c:           map[string]interface{}{"type": Name, "fields": field, "measurement": ""},
+			hasError:    true,
+			errorString: "'measurement' can not be empty accessing config",
+		},
+		"Invalid Precision": {
+			c:           map[string]interface{}{"type": Name, "fields": field, "precision": "m"},
+			hasError:    true,
+			errorString: "'m' is not a valid value for 'precision' expected 'ns, us, ms, s' accessing config",
+		},
+		"Duplicate Tag": {
+			c:           map[string]interface{}{"type": Name, "fields": field, "tags": []map[string]interface{}{{"name": "host"}, {"name": "host"}}},
+			hasError:    true,
+			errorString: "'host' is not a valid value for 'tags.name' expected a unique name accessing config",
+		},
+		"Invalid Cardinality": {
+			c:           map[string]interface{}{"type": Name, "fields": field, "tags": []map[string]interface{}{{"name": "host", "cardinality": -1}}},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'cardinality' expected 0 or more accessing 'tags.0'",
+		},
+		"Cardinality And Values": {
+			c:           map[string]interface{}{"type": Name, "fields": field, "tags": []map[string]interface{}{{"name": "host", "cardinality": 2, "values": []string{"a"}}}},
+			hasError:    true,
+			errorString: "only one of cardinality and values can be set accessing 'tags.0'",
+		},
+		"Duplicate Field": {
+			c:           map[string]interface{}{"type": Name, "fields": []map[string]interface{}{{"name": "a"}, {"name": "a"}}},
+			hasError:    true,
+			errorString: "'a' is not a valid value for 'fields.name' expected a unique name accessing config",
+		},
+		"All Fields Empty": {
+			c:           map[string]interface{}{"type": Name, "fields": []map[string]interface{}{{"name": "a", "empty": 10}}},
+			hasError:    true,
+			errorString: "at least one of 'fields' must never be empty accessing config",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
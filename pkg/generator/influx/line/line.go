@@ -0,0 +1,198 @@
+// Package line generates points of the InfluxDB line protocol, for
+// time series ingestion tests alongside logs.
+//
+// Every point is of the measurement, with each of the tags and each of
+// the fields.  A tag has one of its values, or one of cardinality
+// values "<name>-<n>", 10 by default, so the number of series is the
+// product of the cardinalities of the tags.  The tags are sorted by
+// name, as InfluxDB recommends.  Fields have the options of the
+// columns of csv:spec; integers are written as integers, numbers as
+// floats, booleans as booleans and strings and timestamps as strings.
+// Empty values leave the field out of the point.  The timestamp of the
+// point is the time of the event with the precision.
+//
+// Configuration:
+//
+//	measurement: (string, optional) The measurement, defaults to
+//	             "spigot".
+//	tags:        (list, optional) The tags: name, and cardinality or
+//	             values.
+//	fields:      (list) The fields: name, type (string, integer,
+//	             number, boolean or timestamp), faker, values, min,
+//	             max, layout and empty.
+//	precision:   (string, optional) ns (the default), us, ms or s.
+//
+//	- generator:
+//	    type: "influx:line"
+//	    measurement: cpu
+//	    tags:
+//	      - {name: host, cardinality: 500}
+//	      - {name: region, values: [us-east-1, eu-west-1]}
+//	    fields:
+//	      - {name: usage_user, type: number, max: 100}
+//	      - {name: usage_system, type: number, max: 100}
+//	      - {name: processes, type: integer, min: 50, max: 400}
+//	      - {name: state, values: [ok, degraded], empty: 90}
+package line
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "influx:line"
+
+// Precisions of the timestamps.
+const (
+	PrecisionNanosecond  = "ns"
+	PrecisionMicrosecond = "us"
+	PrecisionMillisecond = "ms"
+	PrecisionSecond      = "s"
+)
+
+var precisions = [...]string{PrecisionNanosecond, PrecisionMicrosecond, PrecisionMillisecond, PrecisionSecond}
+
+// defaultCardinality is the cardinality of tags without values.
+const defaultCardinality = 10
+
+var (
+	measurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `)
+	keyEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `)
+	stringEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+// Line generates the points of its configuration.
+type Line struct {
+	rand        *rand.Rand
+	measurement string
+	tags        []tag
+	fields      []*random.Field
+	unit        time.Duration
+	now         func() time.Time
+}
+
+type tag struct {
+	key    string
+	values []string
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Line objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Line{
+		rand:        r,
+		measurement: measurementEscaper.Replace(c.Measurement),
+		unit:        units[c.Precision],
+		now:         clock.Now,
+	}
+	for _, t := range c.Tags {
+		values := append([]string(nil), t.Values...)
+		if len(values) == 0 {
+			n := t.Cardinality
+			if n == 0 {
+				n = defaultCardinality
+			}
+			values = make([]string, n)
+			for i := range values {
+				values[i] = fmt.Sprintf("%s-%d", t.Name, i)
+			}
+		}
+		for i, v := range values {
+			values[i] = keyEscaper.Replace(v)
+		}
+		l.tags = append(l.tags, tag{key: keyEscaper.Replace(t.Name), values: values})
+	}
+	sort.Slice(l.tags, func(i, j int) bool { return l.tags[i].key < l.tags[j].key })
+	for _, f := range c.Fields {
+		l.fields = append(l.fields, random.NewField(r, f))
+	}
+
+	return l, nil
+}
+
+var units = map[string]time.Duration{
+	PrecisionNanosecond:  time.Nanosecond,
+	PrecisionMicrosecond: time.Microsecond,
+	PrecisionMillisecond: time.Millisecond,
+	PrecisionSecond:      time.Second,
+}
+
+// Next produces the next point.
+//
+// Example:
+//
+// cpu,host=host-17,region=eu-west-1 usage_user=42.17,usage_system=3.5,processes=212i 1709553600000000000
+func (l *Line) Next() ([]byte, error) {
+	var buf bytes.Buffer
+	now := l.now()
+	buf.WriteString(l.measurement)
+	for _, t := range l.tags {
+		buf.WriteByte(',')
+		buf.WriteString(t.key)
+		buf.WriteByte('=')
+		buf.WriteString(t.values[l.rand.IntN(len(t.values))])
+	}
+	sep := byte(' ')
+	for _, f := range l.fields {
+		v := f.Value(now)
+		if v == "" {
+			continue
+		}
+		buf.WriteByte(sep)
+		sep = ','
+		buf.WriteString(keyEscaper.Replace(f.Name()))
+		buf.WriteByte('=')
+		buf.WriteString(fieldValue(f.Type(), v))
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.FormatInt(now.UnixNano()/int64(l.unit), 10))
+	return buf.Bytes(), nil
+}
+
+// fieldValue returns the value v of a field of type typ in the line
+// protocol.  Values of fakers or values that are not of the type are
+// strings.
+func fieldValue(typ, v string) string {
+	switch typ {
+	case random.FieldInteger:
+		if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return v + "i"
+		}
+	case random.FieldNumber:
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			return v
+		}
+	case random.FieldBoolean:
+		if v == "true" || v == "false" {
+			return v
+		}
+	}
+	return `"` + stringEscaper.Replace(v) + `"`
+}
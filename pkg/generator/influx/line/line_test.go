@@ -0,0 +1,80 @@
+package line
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTime = time.Date(2024, 3, 4, 12, 0, 0, 123456789, time.UTC)
+
+func newLine(t *testing.T, cfg map[string]interface{}) *Line {
+	t.Helper()
+	c, err := ucfg.NewFrom(cfg)
+	assert.Nil(t, err)
+	g, err := New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := g.(*Line)
+	l.now = func() time.Time { return testTime }
+	return l
+}
+
+func next(t *testing.T, l *Line) string {
+	t.Helper()
+	got, err := l.Next()
+	assert.Nil(t, err)
+	return string(got)
+}
+
+func TestNext(t *testing.T) {
+	l := newLine(t, map[string]interface{}{
+		"type":        Name,
+		"seed":        1,
+		"measurement": "cpu",
+		"tags": []map[string]interface{}{
+			{"name": "region", "values": []string{"us-east-1", "eu-west-1"}},
+			{"name": "host", "cardinality": 3},
+		},
+		"fields": []map[string]interface{}{
+			{"name": "usage_user", "type": "number", "max": 100},
+			{"name": "processes", "type": "integer", "min": 50, "max": 400},
+			{"name": "up", "type": "boolean"},
+			{"name": "state", "values": []string{"ok"}, "empty": 50},
+		},
+	})
+	re := regexp.MustCompile(`^cpu,host=host-[0-2],region=(us-east-1|eu-west-1) usage_user=\d+(\.\d+)?,processes=\d+i,up=(true|false)(,state="ok")? 1709553600123456789$`)
+	hosts := make(map[string]bool)
+	for i := 0; i < 200; i++ {
+		p := next(t, l)
+		assert.Regexp(t, re, p)
+		hosts[strings.SplitN(strings.SplitN(p, "host=", 2)[1], ",", 2)[0]] = true
+	}
+	assert.Len(t, hosts, 3)
+}
+
+func TestEscape(t *testing.T) {
+	l := newLine(t, map[string]interface{}{
+		"type":        Name,
+		"measurement": "disk usage,total",
+		"precision":   "ms",
+		"tags":        []map[string]interface{}{{"name": "mount point", "values": []string{"C:\\Program Files"}}},
+		"fields": []map[string]interface{}{
+			{"name": "msg", "values": []string{`say "hi" \o/`}},
+			{"name": "count", "type": "integer", "values": []string{"many"}},
+		},
+	})
+	assert.Equal(t, `disk\ usage\,total,mount\ point=C:\Program\ Files msg="say \"hi\" \\o/",count="many" 1709553600123`, next(t, l))
+}
+
+func TestPrecision(t *testing.T) {
+	for precision, want := range map[string]string{"ns": "1709553600123456789", "us": "1709553600123456", "ms": "1709553600123", "s": "1709553600"} {
+		l := newLine(t, map[string]interface{}{"type": Name, "precision": precision, "fields": []map[string]interface{}{{"name": "v", "type": "integer", "min": 1, "max": 1}}})
+		assert.Equal(t, "spigot v=1i "+want, next(t, l), precision)
+	}
+}
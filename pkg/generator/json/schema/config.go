@@ -0,0 +1,40 @@
+package schema
+
+import "fmt"
+
+type config struct {
+	Type       string                 `config:"type" validate:"required"`
+	SchemaFile string                 `config:"schema_file"`
+	Schema     map[string]interface{} `config:"schema"`
+	Fields     map[string]interface{} `config:"fields"`
+	Missing    float64                `config:"missing"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:    Name,
+		Missing: 50,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	n := 0
+	for _, set := range []bool{c.SchemaFile != "", c.Schema != nil, c.Fields != nil} {
+		if set {
+			n++
+		}
+	}
+	if n == 0 {
+		return fmt.Errorf("you must specify schema_file, schema or fields")
+	}
+	if n > 1 {
+		return fmt.Errorf("only one of schema_file, schema and fields can be set")
+	}
+	if c.Missing < 0 || c.Missing > 100 {
+		return fmt.Errorf("'%v' is not a valid value for 'missing' expected a percentage from 0 to 100", c.Missing)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Fields": {
+			c:           map[string]interface{}{"type": Name, "fields": map[string]interface{}{"a": map[string]interface{}{"faker": "ipv4"}}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Schema": {
+			c:           map[string]interface{}{"type": Name, "schema": map[string]interface{}{"type": "object"}, "missing": 10},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "schema": map[string]interface{}{"type": "object"}},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'json:schema' accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+		"No Schema": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    true,
+			errorString: "you must specify schema_file, schema or fields accessing config",
+		},
+		"Schema and Fields": {
+			c:           map[string]interface{}{"type": Name, "schema": map[string]interface{}{"type": "object"}, "fields": map[string]interface{}{"a": map[string]interface{}{"type": "string"}}},
+			hasError:    true,
+			errorString: "only one of schema_file, schema and fields can be set accessing config",
+		},
+		"Invalid Missing": {
+			c:           map[string]interface{}{"type": Name, "schema": map[string]interface{}{"type": "object"}, "missing": 101},
+			hasError:    true,
+			errorString: "'101' is not a valid value for 'missing' expected a percentage from 0 to 100 accessing config",
+		},
+		"Invalid Schema Type": {
+			c:           map[string]interface{}{"type": Name, "schema": map[string]interface{}{"properties": map[string]interface{}{"a": map[string]interface{}{"type": "text"}}}},
+			hasError:    true,
+			errorString: "'text' is not a valid value for 'schema.properties.a.type' expected 'string, integer, number, boolean, array, object, null'",
+		},
+		"Invalid Faker": {
+			c:           map[string]interface{}{"type": Name, "fields": map[string]interface{}{"a": map[string]interface{}{"faker": "bob"}}},
+			hasError:    true,
+			errorString: "'bob' is not a valid value for 'fields.a.faker' expected 'aws_region, country_code, domain, email, first_name, hex, hostname, http_method, http_status, http_version, ipv4, ipv6, last_name, mac, name, port, sentence, url, user_agent, username, uuid, word'",
+		},
+		"Invalid Field Key": {
+			c:           map[string]interface{}{"type": Name, "fields": map[string]interface{}{"a": map[string]interface{}{"type": "string", "length": 3}}},
+			hasError:    true,
+			errorString: "'length' is not a valid key for 'fields.a' expected 'type, format, enum, const, faker, min, max, items, fields, missing'",
+		},
+		"Invalid Range": {
+			c:           map[string]interface{}{"type": Name, "fields": map[string]interface{}{"a": map[string]interface{}{"type": "integer", "min": 10, "max": 1}}},
+			hasError:    true,
+			errorString: "'1' is not a valid value for 'fields.a.maximum' expected a value of at least the minimum",
+		},
+		"Recursive Reference": {
+			c:           map[string]interface{}{"type": Name, "schema": map[string]interface{}{"$ref": "#/$defs/a", "$defs": map[string]interface{}{"a": map[string]interface{}{"properties": map[string]interface{}{"b": map[string]interface{}{"$ref": "#/$defs/a"}}}}}},
+			hasError:    true,
+			errorString: "'#/$defs/a' is not a valid value for '#/$defs/a.properties.b.$ref' expected a reference that is not recursive",
+		},
+		"Missing Schema File": {
+			c:           map[string]interface{}{"type": Name, "schema_file": "/nonexistent/schema.json"},
+			hasError:    true,
+			errorString: "open /nonexistent/schema.json: no such file or directory",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			if err != nil {
+				assert.Equal(t, tc.errorString, err.Error(), name)
+			}
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,693 @@
+// Package schema generates random JSON documents that conform to a
+// JSON Schema, for JSON sources that do not need a generator of their
+// own.
+//
+// The schema is read from a JSON or YAML file, or set in the
+// configuration, either as a JSON Schema or as a field spec.  These
+// keywords of JSON Schema are used, the others are ignored:
+//
+//	type:             One of string, integer, number, boolean, array,
+//	                  object and null, or a list of them one is picked
+//	                  from.
+//	enum, const:      The values to pick from, or the value.
+//	oneOf, anyOf:     The schemas to pick one from.
+//	$ref:             A reference in the schema, such as
+//	                  "#/$defs/user".  Recursive references are not
+//	                  supported.
+//	properties:       The properties of an object.  The properties
+//	                  that are not required are left out of the
+//	                  percentage of documents of the missing option.
+//	required:         The properties that are in every document.
+//	items:            The schema of the items of an array.
+//	minimum, maximum: The range of integers and numbers, defaults to
+//	                  0 to 1000.  exclusiveMinimum and exclusiveMaximum
+//	                  are used for integers.
+//	minLength, maxLength: The length of strings of random letters.
+//	                  Strings without a length are words.
+//	minItems, maxItems: The number of items of an array, defaults to
+//	                  1 to 3.
+//	format:           date-time, date and time are the current time,
+//	                  email, hostname, ipv4, ipv6, uri and uuid are
+//	                  random values.
+//	faker:            The faker of a string, for values JSON Schema
+//	                  has no format for, such as user_agent or
+//	                  first_name.
+//	x-missing:        The percentage of documents a property is left
+//	                  out of, instead of the missing option.
+//
+// A field spec is a map of names to fields, where a dotted name or a
+// map without a type is an object.  The fields have a type, format,
+// enum, const or faker and the keywords:
+//
+//	min, max: The range of a number, the length of a string or the
+//	          number of items of an array.
+//	items:    The field of the items of an array.
+//	fields:   The fields of an object.
+//	missing:  The percentage of documents the field is left out of.
+//	          Fields are in every document by default.
+//
+// The fakers are aws_region, country_code, domain, email, first_name,
+// hex, hostname, http_method, http_status, http_version, ipv4, ipv6,
+// last_name, mac, name, port, sentence, url, user_agent, username,
+// uuid and word.
+//
+// Configuration:
+//
+//	schema_file: (string) The file with the JSON Schema.
+//	schema:      (map) The JSON Schema.  Dotted property names are
+//	             nested objects, as in the rest of the configuration.
+//	fields:      (map) The field spec.
+//	missing:     (number, optional) The percentage of documents an
+//	             optional property of a JSON Schema is left out of,
+//	             defaults to 50.
+//
+//	- generator:
+//	    type: "json:schema"
+//	    fields:
+//	      "@timestamp": {type: string, format: date-time}
+//	      source.ip: {faker: ipv4}
+//	      source.port: {faker: port}
+//	      user.name: {faker: username, missing: 20}
+//	      event.action: {enum: [login, logout, password-change]}
+//	      event.duration: {type: integer, min: 1, max: 5000}
+//	      tags: {type: array, items: {faker: word}, max: 4}
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/elastic/go-ucfg/yaml"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "json:schema"
+
+var (
+	types = [...]string{"string", "integer", "number", "boolean", "array", "object", "null"}
+
+	// formats are the fakers of the formats that are not times.
+	formats = map[string]string{
+		"email":    "email",
+		"hostname": "hostname",
+		"ipv4":     "ipv4",
+		"ipv6":     "ipv6",
+		"uri":      "url",
+		"uuid":     "uuid",
+	}
+
+	// specKeys are the keys of the fields of a field spec.
+	specKeys = [...]string{"type", "format", "enum", "const", "faker", "min", "max", "items", "fields", "missing"}
+)
+
+// node is a compiled schema.
+type node struct {
+	types      []string
+	format     string
+	fake       func(r *rand.Rand) interface{}
+	enum       []interface{}
+	constant   interface{}
+	hasConst   bool
+	choices    []*node
+	properties []property
+	items      *node
+
+	minimum, maximum     float64
+	minLength, maxLength int
+	hasLength            bool
+	minItems, maxItems   int
+}
+
+type property struct {
+	name    string
+	node    *node
+	missing float64
+}
+
+// Schema generates the documents of a schema.
+type Schema struct {
+	rand *rand.Rand
+	root *node
+	now  func() time.Time
+	// clock formats the date-time strings.
+	clock *generator.Clock
+	// time is the time of the document, for all its date-time,
+	// date and time strings.
+	time time.Time
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Schema objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s, option := c.Schema, "schema"
+	switch {
+	case c.SchemaFile != "":
+		if s, err = readSchema(c.SchemaFile); err != nil {
+			return nil, err
+		}
+		option = "schema_file"
+	case c.Fields != nil:
+		if s, err = specSchema(c.Fields, "fields"); err != nil {
+			return nil, err
+		}
+		option = "fields"
+	}
+
+	comp := compiler{root: s, missing: c.Missing, spec: c.Fields != nil, refs: make(map[string]bool)}
+	root, err := comp.compile(s, option)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schema{rand: r, root: root, now: clock.Now, clock: clock}, nil
+}
+
+// readSchema reads the JSON Schema in the JSON or YAML file path.
+func readSchema(path string) (map[string]interface{}, error) {
+	cfg, err := yaml.NewConfigWithFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := make(map[string]interface{})
+	if err := cfg.Unpack(&s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Next produces the next document.
+func (s *Schema) Next() ([]byte, error) {
+	s.time = s.now()
+	return json.Marshal(s.value(s.root))
+}
+
+// value returns a random value of n.
+func (s *Schema) value(n *node) interface{} {
+	switch {
+	case n.hasConst:
+		return n.constant
+	case len(n.enum) > 0:
+		return n.enum[s.rand.IntN(len(n.enum))]
+	case len(n.choices) > 0:
+		return s.value(n.choices[s.rand.IntN(len(n.choices))])
+	case n.fake != nil:
+		return n.fake(s.rand)
+	}
+
+	switch n.types[s.rand.IntN(len(n.types))] {
+	case "null":
+		return nil
+	case "boolean":
+		return s.rand.IntN(2) == 1
+	case "integer":
+		lo, hi := int64(math.Ceil(n.minimum)), int64(math.Floor(n.maximum))
+		return lo + s.rand.Int64N(hi-lo+1)
+	case "number":
+		v := math.Round((n.minimum+s.rand.Float64()*(n.maximum-n.minimum))*100) / 100
+		return math.Max(n.minimum, math.Min(n.maximum, v))
+	case "array":
+		items := make([]interface{}, n.minItems+s.rand.IntN(n.maxItems-n.minItems+1))
+		for i := range items {
+			items[i] = s.value(n.items)
+		}
+		return items
+	case "object":
+		doc := make(map[string]interface{}, len(n.properties))
+		for _, p := range n.properties {
+			if p.missing > 0 && s.rand.Float64()*100 < p.missing {
+				continue
+			}
+			doc[p.name] = s.value(p.node)
+		}
+		return doc
+	}
+	return s.text(n)
+}
+
+// text returns a random string of n.
+func (s *Schema) text(n *node) string {
+	switch n.format {
+	case "date-time":
+		return s.clock.Format(s.time.UTC(), time.RFC3339Nano)
+	case "date":
+		return s.time.UTC().Format("2006-01-02")
+	case "time":
+		return s.time.UTC().Format("15:04:05Z")
+	}
+	if !n.hasLength {
+		return random.Word(s.rand)
+	}
+	b := make([]byte, n.minLength+s.rand.IntN(n.maxLength-n.minLength+1))
+	for i := range b {
+		b[i] = byte('a' + s.rand.IntN(26))
+	}
+	return string(b)
+}
+
+// compiler compiles the schemas of the JSON Schema root.
+type compiler struct {
+	root    map[string]interface{}
+	missing float64
+	// spec is set for a field spec, whose fields are not in the
+	// properties of their object.
+	spec bool
+	// refs are the references that are being compiled.
+	refs map[string]bool
+}
+
+// compile compiles the schema s at path, which is used in errors.
+func (c *compiler) compile(s map[string]interface{}, path string) (*node, error) {
+	if ref, ok := s["$ref"]; ok {
+		return c.ref(ref, path+".$ref")
+	}
+
+	n := &node{}
+	if v, ok := s["const"]; ok {
+		n.constant, n.hasConst = v, true
+		return n, nil
+	}
+	if v, ok := s["enum"]; ok {
+		enum, ok := v.([]interface{})
+		if !ok || len(enum) == 0 {
+			return nil, fmt.Errorf("'%v' is not a valid value for '%s.enum' expected a list of values", v, path)
+		}
+		n.enum = enum
+		return n, nil
+	}
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		v, ok := s[keyword]
+		if !ok {
+			continue
+		}
+		schemas, ok := v.([]interface{})
+		if !ok || len(schemas) == 0 {
+			return nil, fmt.Errorf("'%v' is not a valid value for '%s.%s' expected a list of schemas", v, path, keyword)
+		}
+		for i, v := range schemas {
+			choice, err := c.schema(v, fmt.Sprintf("%s.%s.%d", path, keyword, i))
+			if err != nil {
+				return nil, err
+			}
+			n.choices = append(n.choices, choice)
+		}
+		return n, nil
+	}
+	if v, ok := s["faker"]; ok {
+		fake, ok := random.Faker(fmt.Sprint(v))
+		if !ok {
+			return nil, fmt.Errorf("'%v' is not a valid value for '%s.faker' expected '%s'", v, path, strings.Join(random.FakerNames(), ", "))
+		}
+		n.fake = fake
+		return n, nil
+	}
+
+	if err := c.types(n, s, path); err != nil {
+		return nil, err
+	}
+	if v, ok := s["format"]; ok {
+		n.format = fmt.Sprint(v)
+		if faker, ok := formats[n.format]; ok {
+			n.fake, _ = random.Faker(faker)
+		}
+	}
+	if err := c.bounds(n, s, path); err != nil {
+		return nil, err
+	}
+
+	if v, ok := s["items"]; ok {
+		items, err := c.schema(v, path+".items")
+		if err != nil {
+			return nil, err
+		}
+		n.items = items
+	} else {
+		n.items = &node{types: []string{"string"}}
+	}
+
+	return n, c.properties(n, s, path)
+}
+
+// schema compiles the schema v at path.
+func (c *compiler) schema(v interface{}, path string) (*node, error) {
+	s, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("'%v' is not a valid value for '%s' expected a schema", v, path)
+	}
+	return c.compile(s, path)
+}
+
+// ref compiles the schema the reference ref at path points to.
+func (c *compiler) ref(ref interface{}, path string) (*node, error) {
+	name := fmt.Sprint(ref)
+	if !strings.HasPrefix(name, "#/") {
+		return nil, fmt.Errorf("'%s' is not a valid value for '%s' expected a reference in the schema, such as '#/$defs/name'", name, path)
+	}
+	if c.refs[name] {
+		return nil, fmt.Errorf("'%s' is not a valid value for '%s' expected a reference that is not recursive", name, path)
+	}
+	var v interface{} = c.root
+	for _, part := range strings.Split(name[2:], "/") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			v = nil
+			break
+		}
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		v = m[part]
+	}
+	s, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("'%s' is not a valid value for '%s' expected a reference to a schema", name, path)
+	}
+	c.refs[name] = true
+	defer delete(c.refs, name)
+	return c.compile(s, name)
+}
+
+// types sets the types of n.  Without a type, a schema with properties
+// is an object, one with items an array and other schemas strings.
+func (c *compiler) types(n *node, s map[string]interface{}, path string) error {
+	switch v := s["type"].(type) {
+	case nil:
+		switch {
+		case s["properties"] != nil:
+			n.types = []string{"object"}
+		case s["items"] != nil:
+			n.types = []string{"array"}
+		default:
+			n.types = []string{"string"}
+		}
+	case []interface{}:
+		for _, t := range v {
+			n.types = append(n.types, fmt.Sprint(t))
+		}
+	default:
+		n.types = []string{fmt.Sprint(v)}
+	}
+	if len(n.types) == 0 {
+		return fmt.Errorf("'%s.type' has no types", path)
+	}
+	for _, t := range n.types {
+		if !contains(types[:], t) {
+			return fmt.Errorf("'%s' is not a valid value for '%s.type' expected '%s'", t, path, strings.Join(types[:], ", "))
+		}
+	}
+	return nil
+}
+
+// bounds sets the range, length and number of items of n.
+func (c *compiler) bounds(n *node, s map[string]interface{}, path string) error {
+	var err error
+	var hasMin, hasMax bool
+	if n.minimum, hasMin, err = numberOf(s, "minimum", path); err != nil {
+		return err
+	}
+	if n.maximum, hasMax, err = numberOf(s, "maximum", path); err != nil {
+		return err
+	}
+	// exclusiveMinimum and exclusiveMaximum are numbers since draft 6.
+	if v, ok, err := numberOf(s, "exclusiveMinimum", path); err != nil {
+		return err
+	} else if ok {
+		n.minimum, hasMin = math.Floor(v)+1, true
+	}
+	if v, ok, err := numberOf(s, "exclusiveMaximum", path); err != nil {
+		return err
+	} else if ok {
+		n.maximum, hasMax = math.Ceil(v)-1, true
+	}
+	switch {
+	case !hasMin && !hasMax:
+		n.minimum, n.maximum = 0, 1000
+	case !hasMin:
+		n.minimum = math.Min(0, n.maximum-1000)
+	case !hasMax:
+		n.maximum = n.minimum + 1000
+	}
+	if n.maximum < n.minimum || (contains(n.types, "integer") && math.Floor(n.maximum) < math.Ceil(n.minimum)) {
+		return fmt.Errorf("'%v' is not a valid value for '%s.maximum' expected a value of at least the minimum", n.maximum, path)
+	}
+
+	minLength, hasMinLength, err := countOf(s, "minLength", path)
+	if err != nil {
+		return err
+	}
+	maxLength, hasMaxLength, err := countOf(s, "maxLength", path)
+	if err != nil {
+		return err
+	}
+	n.hasLength = hasMinLength || hasMaxLength
+	n.minLength, n.maxLength = minLength, maxLength
+	switch {
+	case !hasMinLength && hasMaxLength:
+		n.minLength = 1
+		if maxLength < 1 {
+			n.minLength = 0
+		}
+	case hasMinLength && !hasMaxLength:
+		n.maxLength = minLength + 10
+	}
+	if n.maxLength < n.minLength {
+		return fmt.Errorf("'%d' is not a valid value for '%s.maxLength' expected a value of at least the minLength", n.maxLength, path)
+	}
+
+	minItems, hasMinItems, err := countOf(s, "minItems", path)
+	if err != nil {
+		return err
+	}
+	maxItems, hasMaxItems, err := countOf(s, "maxItems", path)
+	if err != nil {
+		return err
+	}
+	n.minItems, n.maxItems = 1, 3
+	switch {
+	case hasMinItems && hasMaxItems:
+		n.minItems, n.maxItems = minItems, maxItems
+	case hasMinItems:
+		n.minItems, n.maxItems = minItems, minItems+2
+	case hasMaxItems:
+		n.maxItems = maxItems
+		if maxItems < 1 {
+			n.minItems = 0
+		}
+	}
+	if n.maxItems < n.minItems {
+		return fmt.Errorf("'%d' is not a valid value for '%s.maxItems' expected a value of at least the minItems", n.maxItems, path)
+	}
+	return nil
+}
+
+// properties compiles the properties of n, in the order of their
+// names.
+func (c *compiler) properties(n *node, s map[string]interface{}, path string) error {
+	v, ok := s["properties"]
+	if !ok {
+		return nil
+	}
+	props, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("'%v' is not a valid value for '%s.properties' expected a map of schemas", v, path)
+	}
+	required := make(map[string]bool)
+	if v, ok := s["required"]; ok {
+		names, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("'%v' is not a valid value for '%s.required' expected a list of names", v, path)
+		}
+		for _, name := range names {
+			required[fmt.Sprint(name)] = true
+		}
+	}
+
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ppath := path + ".properties." + name
+		if c.spec {
+			ppath = path + "." + name
+		}
+		prop, ok := props[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("'%v' is not a valid value for '%s' expected a schema", props[name], ppath)
+		}
+		pn, err := c.compile(prop, ppath)
+		if err != nil {
+			return err
+		}
+		p := property{name: name, node: pn}
+		if !required[name] {
+			p.missing = c.missing
+		}
+		if v, ok, err := numberOf(prop, "x-missing", ppath); err != nil {
+			return err
+		} else if ok {
+			if v < 0 || v > 100 {
+				return fmt.Errorf("'%v' is not a valid value for '%s.x-missing' expected a percentage from 0 to 100", v, ppath)
+			}
+			p.missing = v
+		}
+		n.properties = append(n.properties, p)
+	}
+	return nil
+}
+
+// specSchema returns the JSON Schema of the field spec fields at path.
+func specSchema(fields map[string]interface{}, path string) (map[string]interface{}, error) {
+	props := make(map[string]interface{}, len(fields))
+	var required []interface{}
+	for name, v := range fields {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("'%v' is not a valid value for '%s.%s' expected a field", v, path, name)
+		}
+		var s map[string]interface{}
+		var err error
+		if isField(m) {
+			s, err = fieldSchema(m, path+"."+name)
+		} else {
+			s, err = specSchema(m, path+"."+name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		props[name] = s
+		if _, ok := s["x-missing"]; !ok {
+			required = append(required, name)
+		}
+	}
+	return map[string]interface{}{"type": "object", "properties": props, "required": required}, nil
+}
+
+// isField reports whether m is a field, and not a map of the fields
+// of an object.
+func isField(m map[string]interface{}) bool {
+	for _, key := range []string{"type", "format", "enum", "const", "faker"} {
+		if v, ok := m[key]; ok {
+			if _, nested := v.(map[string]interface{}); !nested {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldSchema returns the JSON Schema of the field f at path.
+func fieldSchema(f map[string]interface{}, path string) (map[string]interface{}, error) {
+	s := make(map[string]interface{}, len(f))
+	for key, v := range f {
+		switch key {
+		case "type", "format", "enum", "const", "faker":
+			s[key] = v
+		case "missing":
+			s["x-missing"] = v
+		case "min", "max":
+			// the keyword depends on the type, which is set below.
+		case "items":
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("'%v' is not a valid value for '%s.items' expected a field", v, path)
+			}
+			items, err := fieldSchema(m, path+".items")
+			if err != nil {
+				return nil, err
+			}
+			s["items"] = items
+		case "fields":
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("'%v' is not a valid value for '%s.fields' expected a map of fields", v, path)
+			}
+			o, err := specSchema(m, path+".fields")
+			if err != nil {
+				return nil, err
+			}
+			s["properties"], s["required"] = o["properties"], o["required"]
+		default:
+			return nil, fmt.Errorf("'%s' is not a valid key for '%s' expected '%s'", key, path, strings.Join(specKeys[:], ", "))
+		}
+	}
+	min, max := "minimum", "maximum"
+	switch s["type"] {
+	case "string":
+		min, max = "minLength", "maxLength"
+	case "array":
+		min, max = "minItems", "maxItems"
+	}
+	if v, ok := f["min"]; ok {
+		s[min] = v
+	}
+	if v, ok := f["max"]; ok {
+		s[max] = v
+	}
+	return s, nil
+}
+
+// numberOf returns the number of the keyword of s, and whether s has
+// the keyword.
+func numberOf(s map[string]interface{}, keyword, path string) (float64, bool, error) {
+	v, ok := s[keyword]
+	if !ok {
+		return 0, false, nil
+	}
+	switch n := v.(type) {
+	case int:
+		return float64(n), true, nil
+	case int64:
+		return float64(n), true, nil
+	case uint64:
+		return float64(n), true, nil
+	case float64:
+		return n, true, nil
+	}
+	return 0, false, fmt.Errorf("'%v' is not a valid value for '%s.%s' expected a number", v, path, keyword)
+}
+
+// countOf returns the count of the keyword of s, a number of 0 or
+// more, and whether s has the keyword.
+func countOf(s map[string]interface{}, keyword, path string) (int, bool, error) {
+	v, ok, err := numberOf(s, keyword, path)
+	if err != nil || !ok {
+		return 0, ok, err
+	}
+	if v < 0 || v != math.Trunc(v) {
+		return 0, false, fmt.Errorf("'%v' is not a valid value for '%s.%s' expected a whole number of 0 or more", v, path, keyword)
+	}
+	return int(v), true, nil
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/elastic/go-ucfg/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTime = time.Date(2024, 3, 4, 12, 0, 0, 123456000, time.UTC)
+
+// newSchema returns the generator of the YAML config, which is read
+// with the dotted names the config file has.
+func newSchema(t *testing.T, config string) *Schema {
+	t.Helper()
+	c, err := yaml.NewConfig([]byte(config), ucfg.PathSep("."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := g.(*Schema)
+	s.now = func() time.Time { return testTime }
+	return s
+}
+
+func next(t *testing.T, s *Schema) map[string]interface{} {
+	t.Helper()
+	b, err := s.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := make(map[string]interface{})
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("%v: %s", err, b)
+	}
+	return doc
+}
+
+func TestFields(t *testing.T) {
+	s := newSchema(t, `
+type: json:schema
+seed: 1
+fields:
+  "@timestamp": {type: string, format: date-time}
+  source.ip: {faker: ipv4}
+  source.port: {faker: port}
+  event.action: {enum: [login, logout]}
+  event.duration: {type: integer, min: 1, max: 5000}
+  event.score: {type: number, min: 0.5, max: 1}
+  event.id: {type: string, min: 8, max: 8}
+  event.kind: {const: event}
+  tags: {type: array, items: {faker: word}, max: 4}
+  user: {type: object, fields: {name: {faker: username}, admin: {type: boolean}}}
+`)
+	for i := 0; i < 100; i++ {
+		doc := next(t, s)
+		assert.Equal(t, "2024-03-04T12:00:00.123456Z", doc["@timestamp"])
+
+		source := doc["source"].(map[string]interface{})
+		assert.Regexp(t, `^\d+\.\d+\.\d+\.\d+$`, source["ip"])
+		assert.IsType(t, float64(0), source["port"])
+
+		event := doc["event"].(map[string]interface{})
+		assert.Contains(t, []interface{}{"login", "logout"}, event["action"])
+		d := event["duration"].(float64)
+		assert.True(t, d >= 1 && d <= 5000 && d == float64(int(d)), d)
+		score := event["score"].(float64)
+		assert.True(t, score >= 0.5 && score <= 1, score)
+		assert.Regexp(t, `^[a-z]{8}$`, event["id"])
+		assert.Equal(t, "event", event["kind"])
+
+		tags := doc["tags"].([]interface{})
+		assert.True(t, len(tags) >= 1 && len(tags) <= 4, tags)
+
+		user := doc["user"].(map[string]interface{})
+		assert.Regexp(t, `^[a-z]+$`, user["name"])
+		assert.IsType(t, true, user["admin"])
+	}
+}
+
+func TestSchema(t *testing.T) {
+	s := newSchema(t, `
+type: json:schema
+seed: 1
+schema:
+  type: object
+  required: [id, user, status]
+  properties:
+    id: {type: string, format: uuid}
+    user: {$ref: "#/$defs/user"}
+    status: {oneOf: [{type: integer, minimum: 200, exclusiveMaximum: 300}, {type: "null"}]}
+    note: {type: string}
+  $defs:
+    user:
+      type: object
+      required: [email]
+      properties:
+        email: {type: string, format: email}
+`)
+	notes := 0
+	for i := 0; i < 1000; i++ {
+		doc := next(t, s)
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, doc["id"])
+		assert.Regexp(t, `^[a-z]+@[a-z.]+$`, doc["user"].(map[string]interface{})["email"])
+		if status, ok := doc["status"].(float64); ok {
+			assert.True(t, status >= 200 && status < 300, status)
+		} else {
+			assert.Nil(t, doc["status"])
+		}
+		if _, ok := doc["note"]; ok {
+			notes++
+		}
+	}
+	assert.InDelta(t, 500, notes, 60, "optional properties are in half of the documents")
+}
+
+func TestSchemaFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	err := os.WriteFile(path, []byte(`{
+  "type": "object",
+  "properties": {
+    "host.name": {"type": "string", "format": "hostname"},
+    "level": {"enum": ["info", "warn", "error"]},
+    "optional": {"type": "boolean", "x-missing": 100}
+  },
+  "required": ["host.name", "level"]
+}`), 0o644)
+	assert.Nil(t, err)
+
+	s := newSchema(t, "type: json:schema\nschema_file: "+path)
+	for i := 0; i < 100; i++ {
+		doc := next(t, s)
+		assert.Regexp(t, `^[a-z]+\d\d\.[a-z.]+$`, doc["host.name"], "names in a file are not split at dots")
+		assert.Contains(t, []interface{}{"info", "warn", "error"}, doc["level"])
+		assert.NotContains(t, doc, "optional")
+	}
+}
+
+func TestMissing(t *testing.T) {
+	s := newSchema(t, `
+type: json:schema
+seed: 1
+missing: 0
+schema:
+  properties:
+    a: {type: integer}
+    b: {type: integer, x-missing: 25}
+`)
+	b := 0
+	for i := 0; i < 1000; i++ {
+		doc := next(t, s)
+		assert.Contains(t, doc, "a")
+		if _, ok := doc["b"]; ok {
+			b++
+		}
+	}
+	assert.InDelta(t, 750, b, 60)
+}
+
+func TestSeed(t *testing.T) {
+	config := "type: json:schema\nseed: 7\nfields:\n  a: {faker: uuid}\n  b: {type: number}\n"
+	s1, s2 := newSchema(t, config), newSchema(t, config)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, next(t, s1), next(t, s2))
+	}
+}
@@ -0,0 +1,71 @@
+package spec
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+type config struct {
+	Type      string               `config:"type" validate:"required"`
+	Fields    []random.FieldConfig `config:"fields" validate:"required"`
+	Separator string               `config:"separator"`
+	Assign    string               `config:"assign"`
+	Quote     string               `config:"quote"`
+	QuoteChar string               `config:"quote_char"`
+	Escape    string               `config:"escape"`
+	OmitEmpty bool                 `config:"omit_empty"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:      Name,
+		Separator: " ",
+		Assign:    "=",
+		Quote:     QuoteAuto,
+		QuoteChar: `"`,
+		Escape:    EscapeBackslash,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if c.Separator == "" {
+		return fmt.Errorf("'separator' can not be empty")
+	}
+	if c.Assign == "" {
+		return fmt.Errorf("'assign' can not be empty")
+	}
+	if !contains(quotes[:], c.Quote) {
+		return fmt.Errorf("'%s' is not a valid value for 'quote' expected '%s'", c.Quote, strings.Join(quotes[:], ", "))
+	}
+	if len(c.QuoteChar) != 1 {
+		return fmt.Errorf("'%s' is not a valid value for 'quote_char' expected a single character", c.QuoteChar)
+	}
+	if !contains(escapes[:], c.Escape) {
+		return fmt.Errorf("'%s' is not a valid value for 'escape' expected '%s'", c.Escape, strings.Join(escapes[:], ", "))
+	}
+	names := make(map[string]bool)
+	for _, f := range c.Fields {
+		if strings.Contains(f.Name, c.Assign) || strings.Contains(f.Name, c.Separator) {
+			return fmt.Errorf("'%s' is not a valid value for 'fields.name' expected a key without the assign or separator", f.Name)
+		}
+		if names[f.Name] {
+			return fmt.Errorf("'%s' is not a valid value for 'fields.name' expected a unique name", f.Name)
+		}
+		names[f.Name] = true
+	}
+	return nil
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
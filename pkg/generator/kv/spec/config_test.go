@@ -0,0 +1,92 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	field := []map[string]interface{}{{"name": "a"}}
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name, "fields": field},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Policies": {
+			c:           map[string]interface{}{"type": Name, "fields": field, "separator": ", ", "assign": ":", "quote": "always", "quote_char": "'", "escape": "double"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "fields": field},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'kv:spec' accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": "", "fields": field},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+		"No Fields": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    true,
+			errorString: "missing required field accessing 'fields'",
+		},
+		"Empty Separator": {
+			c:           map[string]interface{}{"type": Name, "fields": field, "separator": ""},
+			hasError:    true,
+			errorString: "'separator' can not be empty accessing config",
+		},
+		"Invalid Quote": {
+			c:           map[string]interface{}{"type": Name, "fields": field, "quote": "sometimes"},
+			hasError:    true,
+			errorString: "'sometimes' is not a valid value for 'quote' expected 'auto, strings, always, never' accessing config",
+		},
+		"Invalid Quote Char": {
+			c:           map[string]interface{}{"type": Name, "fields": field, "quote_char": "<>"},
+			hasError:    true,
+			errorString: "'<>' is not a valid value for 'quote_char' expected a single character accessing config",
+		},
+		"Invalid Escape": {
+			c:           map[string]interface{}{"type": Name, "fields": field, "escape": "html"},
+			hasError:    true,
+			errorString: "'html' is not a valid value for 'escape' expected 'backslash, double, none' accessing config",
+		},
+		"Key With Assign": {
+			c:           map[string]interface{}{"type": Name, "fields": []map[string]interface{}{{"name": "a=b"}}},
+			hasError:    true,
+			errorString: "'a=b' is not a valid value for 'fields.name' expected a key without the assign or separator accessing config",
+		},
+		"Duplicate Key": {
+			c:           map[string]interface{}{"type": Name, "fields": []map[string]interface{}{{"name": "a"}, {"name": "a"}}},
+			hasError:    true,
+			errorString: "'a' is not a valid value for 'fields.name' expected a unique name accessing config",
+		},
+		"Invalid Faker": {
+			c:           map[string]interface{}{"type": Name, "fields": []map[string]interface{}{{"name": "a", "faker": "bob"}}},
+			hasError:    true,
+			errorString: "'bob' is not a valid value for 'faker' expected 'aws_region, country_code, domain, email, first_name, hex, hostname, http_method, http_status, http_version, ipv4, ipv6, last_name, mac, name, port, sentence, url, user_agent, username, uuid, word' accessing 'fields.0'",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			if err != nil {
+				assert.Equal(t, tc.errorString, err.Error(), name)
+			}
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package spec generates key=value messages whose pairs are defined
+// in the configuration, for the many appliance formats that are
+// variations of this shape.
+//
+// Every event has a pair of each field, in order, joined by the
+// separator.  The quote policy sets which values are quoted: auto
+// quotes the values that are empty or have a space, the separator,
+// the assign or the quote character in them, strings quotes the
+// values of string fields, as FortiGate does, and always and never
+// quote all or none of the values.  Quote characters in quoted values
+// are escaped with a backslash, doubled, or left as they are to test
+// parsers with broken messages.
+//
+// Configuration:
+//
+//	fields:     (list) The fields, in order, with the options of the
+//	            columns of csv:spec: name, type (string, integer,
+//	            number, boolean or timestamp), faker, values, min, max,
+//	            layout and empty.
+//	separator:  (string, optional) The separator of the pairs,
+//	            defaults to " ".
+//	assign:     (string, optional) The separator of a key and its
+//	            value, defaults to "=".
+//	quote:      (string, optional) auto (the default), strings, always
+//	            or never.
+//	quote_char: (string, optional) The quote character, defaults to
+//	            '"'.
+//	escape:     (string, optional) backslash (the default), double or
+//	            none.
+//	omit_empty: (bool, optional) Leave out the pairs of empty values.
+//
+//	- generator:
+//	    type: "kv:spec"
+//	    quote: strings
+//	    fields:
+//	      - {name: date, type: timestamp, layout: "2006-01-02"}
+//	      - {name: time, type: timestamp, layout: "15:04:05"}
+//	      - {name: srcip, faker: ipv4}
+//	      - {name: user, faker: username, empty: 30}
+//	      - {name: action, values: [accept, deny]}
+//	      - {name: sentbyte, type: integer, max: 100000}
+//	      - {name: msg, faker: sentence}
+package spec
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "kv:spec"
+
+// Policies of the quote option.
+const (
+	QuoteAuto    = "auto"
+	QuoteStrings = "strings"
+	QuoteAlways  = "always"
+	QuoteNever   = "never"
+)
+
+// Escapes of the escape option.
+const (
+	EscapeBackslash = "backslash"
+	EscapeDouble    = "double"
+	EscapeNone      = "none"
+)
+
+var (
+	quotes  = [...]string{QuoteAuto, QuoteStrings, QuoteAlways, QuoteNever}
+	escapes = [...]string{EscapeBackslash, EscapeDouble, EscapeNone}
+)
+
+// Spec generates the key=value messages of the fields of its
+// configuration.
+type Spec struct {
+	rand      *rand.Rand
+	fields    []*random.Field
+	separator string
+	assign    string
+	quote     string
+	quoteChar string
+	escaper   *strings.Replacer
+	omitEmpty bool
+	now       func() time.Time
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Spec objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Spec{
+		rand:      r,
+		separator: c.Separator,
+		assign:    c.Assign,
+		quote:     c.Quote,
+		quoteChar: c.QuoteChar,
+		omitEmpty: c.OmitEmpty,
+		now:       clock.Now,
+	}
+	switch c.Escape {
+	case EscapeBackslash:
+		s.escaper = strings.NewReplacer(`\`, `\\`, c.QuoteChar, `\`+c.QuoteChar)
+	case EscapeDouble:
+		s.escaper = strings.NewReplacer(c.QuoteChar, c.QuoteChar+c.QuoteChar)
+	}
+	for _, f := range c.Fields {
+		s.fields = append(s.fields, random.NewField(r, f))
+	}
+
+	return s, nil
+}
+
+// Next produces the next message.
+//
+// Example:
+//
+// date=2024-03-04 time=12:00:00 srcip=66.4.203.154 user=bjansen action=deny sentbyte=48213 msg="Session queue token expired."
+func (s *Spec) Next() ([]byte, error) {
+	var buf bytes.Buffer
+	now := s.now()
+	for _, f := range s.fields {
+		v := f.Value(now)
+		if v == "" && s.omitEmpty {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteString(s.separator)
+		}
+		buf.WriteString(f.Name())
+		buf.WriteString(s.assign)
+		if !s.quoted(f, v) {
+			buf.WriteString(v)
+			continue
+		}
+		if s.escaper != nil {
+			v = s.escaper.Replace(v)
+		}
+		buf.WriteString(s.quoteChar)
+		buf.WriteString(v)
+		buf.WriteString(s.quoteChar)
+	}
+	return buf.Bytes(), nil
+}
+
+// quoted reports whether the value v of f is quoted.
+func (s *Spec) quoted(f *random.Field, v string) bool {
+	switch s.quote {
+	case QuoteAlways:
+		return true
+	case QuoteNever:
+		return false
+	case QuoteStrings:
+		return f.Type() == random.FieldString
+	}
+	return v == "" || strings.ContainsAny(v, " \t") || strings.Contains(v, s.separator) ||
+		strings.Contains(v, s.assign) || strings.Contains(v, s.quoteChar)
+}
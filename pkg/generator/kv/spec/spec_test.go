@@ -0,0 +1,95 @@
+package spec
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTime = time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+
+func newSpec(t *testing.T, cfg map[string]interface{}) *Spec {
+	t.Helper()
+	c, err := ucfg.NewFrom(cfg)
+	assert.Nil(t, err)
+	g, err := New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := g.(*Spec)
+	s.now = func() time.Time { return testTime }
+	return s
+}
+
+func next(t *testing.T, s *Spec) string {
+	t.Helper()
+	got, err := s.Next()
+	assert.Nil(t, err)
+	return string(got)
+}
+
+func TestNext(t *testing.T) {
+	s := newSpec(t, map[string]interface{}{
+		"type": Name,
+		"seed": 1,
+		"fields": []map[string]interface{}{
+			{"name": "date", "type": "timestamp", "layout": "2006-01-02"},
+			{"name": "srcip", "faker": "ipv4"},
+			{"name": "action", "values": []string{"accept", "deny"}},
+			{"name": "bytes", "type": "integer", "min": 1, "max": 9},
+			{"name": "msg", "faker": "sentence"},
+		},
+	})
+	re := regexp.MustCompile(`^date=2024-03-04 srcip=\d+\.\d+\.\d+\.\d+ action=(accept|deny) bytes=\d msg="[A-Z][a-z ]+\."$`)
+	for i := 0; i < 100; i++ {
+		assert.Regexp(t, re, next(t, s))
+	}
+}
+
+func TestQuote(t *testing.T) {
+	fields := []map[string]interface{}{
+		{"name": "a", "values": []string{`say "hi"`}},
+		{"name": "b", "values": []string{"plain"}},
+		{"name": "n", "type": "integer", "min": 7, "max": 7},
+		{"name": "e", "values": []string{"x"}, "empty": 100},
+	}
+	tests := map[string]struct {
+		c    map[string]interface{}
+		want string
+	}{
+		"Auto": {
+			c:    map[string]interface{}{},
+			want: `a="say \"hi\"" b=plain n=7 e=""`,
+		},
+		"Strings": {
+			c:    map[string]interface{}{"quote": QuoteStrings},
+			want: `a="say \"hi\"" b="plain" n=7 e=""`,
+		},
+		"Always Double": {
+			c:    map[string]interface{}{"quote": QuoteAlways, "escape": EscapeDouble},
+			want: `a="say ""hi""" b="plain" n="7" e=""`,
+		},
+		"Never": {
+			c:    map[string]interface{}{"quote": QuoteNever},
+			want: `a=say "hi" b=plain n=7 e=`,
+		},
+		"Unescaped": {
+			c:    map[string]interface{}{"escape": EscapeNone},
+			want: `a="say "hi"" b=plain n=7 e=""`,
+		},
+		"Comma Separated": {
+			c:    map[string]interface{}{"separator": ",", "assign": ":", "quote_char": "'", "omit_empty": true},
+			want: `a:'say "hi"',b:plain,n:7`,
+		},
+	}
+	for name, tc := range tests {
+		c := map[string]interface{}{"type": Name, "fields": fields}
+		for k, v := range tc.c {
+			c[k] = v
+		}
+		assert.Equal(t, tc.want, next(t, newSpec(t, c)), name)
+	}
+}
@@ -0,0 +1,302 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"text/template"
+	"text/template/parse"
+)
+
+// Templater is implemented by generators that write their events with
+// text/template, so their templates can be checked by Lint.
+//
+// Templates returns the templates of the generator and the value they
+// are executed with, a pointer to a struct.
+type Templater interface {
+	Templates() (data interface{}, templates []*template.Template)
+}
+
+// Issue is a problem Lint found in the templates of a generator.
+// Issues that are a Warning do not break the generator, the others
+// are fields executing the template fails on.
+type Issue struct {
+	Template string
+	Field    string
+	Message  string
+	Warning  bool
+}
+
+func (i Issue) String() string {
+	level := "error"
+	if i.Warning {
+		level = "warning"
+	}
+	if i.Template == "" {
+		return fmt.Sprintf("%s: field '%s' %s", level, i.Field, i.Message)
+	}
+	return fmt.Sprintf("%s: template '%s' field '%s' %s", level, i.Template, i.Field, i.Message)
+}
+
+// Lint checks the templates of g against the fields of the value they
+// are executed with.  It reports fields the templates reference that
+// do not exist, for example a field of the generator used inside a
+// {{with}} that changed the dot, and exported fields of the generator
+// that no template references.
+//
+// With samples greater than 0 Lint also generates samples events and
+// reports the fields the templates reference that were never set to a
+// value other than their zero value.  This consumes events of g.
+//
+// Generators that do not implement Templater have no issues.
+func Lint(g Generator, samples int) ([]Issue, error) {
+	if c, ok := g.(*corruptGenerator); ok {
+		g = c.Generator
+	}
+	if p, ok := g.(*payloadGenerator); ok {
+		g = p.Generator
+	}
+	if s, ok := g.(*stressGenerator); ok {
+		g = s.Generator
+	}
+	if j, ok := g.(jsonGenerator); ok {
+		g = j.ECSGenerator
+	}
+	t, ok := g.(Templater)
+	if !ok {
+		return nil, nil
+	}
+	data, templates := t.Templates()
+	root := reflect.TypeOf(data)
+
+	l := &linter{used: make(map[string]bool), seen: make(map[string]bool)}
+	for _, tmpl := range templates {
+		for _, tt := range tmpl.Templates() {
+			if tt.Tree == nil || isPartial(tt.Name()) {
+				continue
+			}
+			l.template = tt
+			l.walk(tt.Tree.Root, root, root)
+		}
+	}
+
+	st := indirect(root)
+	if st.Kind() == reflect.Struct {
+		for i := 0; i < st.NumField(); i++ {
+			f := st.Field(i)
+			if !f.IsExported() || f.Anonymous || isTemplate(f.Type) || l.used[f.Name] {
+				continue
+			}
+			l.issues = append(l.issues, Issue{Field: f.Name, Message: "is not used by any template", Warning: true})
+		}
+	}
+
+	if samples > 0 && st.Kind() == reflect.Struct {
+		set := make(map[string]bool)
+		record := func() {
+			v := reflect.Indirect(reflect.ValueOf(data))
+			for name := range l.used {
+				if f := v.FieldByName(name); f.IsValid() && !f.IsZero() {
+					set[name] = true
+				}
+			}
+		}
+		record()
+		for i := 0; i < samples; i++ {
+			if _, err := g.Next(); err != nil {
+				return nil, err
+			}
+			record()
+		}
+		for name := range l.used {
+			if _, ok := st.FieldByName(name); ok && !set[name] {
+				l.issues = append(l.issues, Issue{Field: name, Message: fmt.Sprintf("is used by a template but was not set in %d events", samples), Warning: true})
+			}
+		}
+	}
+
+	sort.Slice(l.issues, func(i, j int) bool {
+		if l.issues[i].Template != l.issues[j].Template {
+			return l.issues[i].Template < l.issues[j].Template
+		}
+		return l.issues[i].Field < l.issues[j].Field
+	})
+	return l.issues, nil
+}
+
+// linter walks the parse tree of a template, keeping track of the
+// type of dot.  A nil type is a dot of unknown type, which is not
+// checked.
+type linter struct {
+	template *template.Template
+	used     map[string]bool
+	issues   []Issue
+	seen     map[string]bool
+}
+
+func (l *linter) walk(node parse.Node, dot, root reflect.Type) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			l.walk(c, dot, root)
+		}
+	case *parse.ActionNode:
+		l.pipe(n.Pipe, dot, root)
+	case *parse.IfNode:
+		l.pipe(n.Pipe, dot, root)
+		l.walk(n.List, dot, root)
+		l.walk(n.ElseList, dot, root)
+	case *parse.WithNode:
+		t := l.pipe(n.Pipe, dot, root)
+		l.walk(n.List, t, root)
+		l.walk(n.ElseList, dot, root)
+	case *parse.RangeNode:
+		t := l.pipe(n.Pipe, dot, root)
+		l.walk(n.List, elem(t), root)
+		l.walk(n.ElseList, dot, root)
+	case *parse.TemplateNode:
+		t := dot
+		if n.Pipe != nil {
+			t = l.pipe(n.Pipe, dot, root)
+		}
+		key := fmt.Sprintf("%s %v", n.Name, t)
+		if sub := l.template.Lookup(n.Name); sub != nil && sub.Tree != nil && !l.seen[key] {
+			l.seen[key] = true
+			l.walk(sub.Tree.Root, t, root)
+		}
+	}
+}
+
+// pipe checks the commands of a pipeline and returns the type of its
+// result.
+func (l *linter) pipe(p *parse.PipeNode, dot, root reflect.Type) reflect.Type {
+	if p == nil {
+		return nil
+	}
+	var t reflect.Type
+	for _, cmd := range p.Cmds {
+		t = nil
+		for i, arg := range cmd.Args {
+			at := l.arg(arg, dot, root)
+			if i == 0 {
+				t = at
+			}
+		}
+		if len(cmd.Args) > 0 {
+			if _, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+				t = nil
+			}
+		}
+	}
+	return t
+}
+
+// arg checks an argument of a command and returns its type.
+func (l *linter) arg(node parse.Node, dot, root reflect.Type) reflect.Type {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return l.fields(dot, n.Ident, dot == root)
+	case *parse.VariableNode:
+		if n.Ident[0] != "$" {
+			return nil
+		}
+		return l.fields(root, n.Ident[1:], true)
+	case *parse.ChainNode:
+		var t reflect.Type
+		switch c := n.Node.(type) {
+		case *parse.PipeNode:
+			t = l.pipe(c, dot, root)
+		default:
+			t = l.arg(c, dot, root)
+		}
+		return l.fields(t, n.Field, false)
+	case *parse.PipeNode:
+		return l.pipe(n, dot, root)
+	}
+	return nil
+}
+
+// fields resolves the chain of field or method names on t and returns
+// the type of the last one.  With top the first name is a field of
+// the value the template is executed with.
+func (l *linter) fields(t reflect.Type, names []string, top bool) reflect.Type {
+	for i, name := range names {
+		if t == nil {
+			return nil
+		}
+		if top && i == 0 {
+			l.used[name] = true
+		}
+		if m, ok := t.MethodByName(name); ok {
+			t = result(m.Type)
+			continue
+		}
+		if t.Kind() != reflect.Pointer {
+			if m, ok := reflect.PointerTo(t).MethodByName(name); ok {
+				t = result(m.Type)
+				continue
+			}
+		}
+		st := indirect(t)
+		switch st.Kind() {
+		case reflect.Struct:
+			f, ok := st.FieldByName(name)
+			if !ok || !f.IsExported() {
+				l.issues = append(l.issues, Issue{Template: l.template.Name(), Field: name, Message: fmt.Sprintf("is not a field of %s", st)})
+				return nil
+			}
+			t = f.Type
+		case reflect.Map:
+			t = st.Elem()
+		default:
+			return nil
+		}
+	}
+	return t
+}
+
+// result returns the type of the first result of a method, or nil.
+func result(m reflect.Type) reflect.Type {
+	if m.NumOut() == 0 {
+		return nil
+	}
+	return m.Out(0)
+}
+
+// elem returns the type of the elements range iterates over, or nil.
+func elem(t reflect.Type) reflect.Type {
+	if t == nil {
+		return nil
+	}
+	switch t = indirect(t); t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return t.Elem()
+	}
+	return nil
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+var templateType = reflect.TypeOf((*template.Template)(nil))
+
+// isTemplate returns true for fields that hold templates.
+func isTemplate(t reflect.Type) bool {
+	for {
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+			continue
+		}
+		return t == templateType
+	}
+}
@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type geo struct {
+	City string
+}
+
+type lintGenerator struct {
+	SrcAddr   string
+	SrcPort   int
+	Geo       *geo
+	Timestamp time.Time
+	Unused    string
+	Never     string
+
+	n         int
+	templates []*template.Template
+}
+
+func (g *lintGenerator) Next() ([]byte, error) {
+	g.n++
+	g.SrcPort = g.n
+	return nil, nil
+}
+
+func (g *lintGenerator) Templates() (interface{}, []*template.Template) {
+	return g, g.templates
+}
+
+func TestLint(t *testing.T) {
+	tmpl := template.Must(template.New("event").Funcs(FunctionMap).Parse(
+		`{{.Timestamp.Format "2006"}} src={{.SrcAddr | ToLower}} {{with .Geo}}city={{.City}} spt={{.SrcPort}}{{end}} {{range $i, $v := .SrcAddr}}{{$.Never}}{{end}}{{.Missing}}`))
+	g := &lintGenerator{SrcAddr: "10.0.0.1", Timestamp: time.Now(), Geo: &geo{City: "Utrecht"}, templates: []*template.Template{tmpl}}
+
+	issues, err := Lint(g, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []Issue{
+		{Field: "SrcPort", Message: "is not used by any template", Warning: true},
+		{Field: "Unused", Message: "is not used by any template", Warning: true},
+		{Template: "event", Field: "Missing", Message: "is not a field of generator.lintGenerator"},
+		{Template: "event", Field: "SrcPort", Message: "is not a field of generator.geo"},
+	}, issues)
+
+	issues, err = Lint(g, 10)
+	assert.Nil(t, err)
+	assert.Contains(t, issues, Issue{Field: "Never", Message: "is used by a template but was not set in 10 events", Warning: true})
+	assert.NotContains(t, issues, Issue{Field: "SrcAddr", Message: "is used by a template but was not set in 10 events", Warning: true})
+	assert.Equal(t, 10, g.n)
+
+	assert.Equal(t, "error: template 'event' field 'SrcPort' is not a field of generator.geo", issues[len(issues)-1].String())
+}
+
+func TestLintNotTemplater(t *testing.T) {
+	issues, err := Lint(struct{ Generator }{}, 10)
+	assert.Nil(t, err)
+	assert.Nil(t, issues)
+}
@@ -0,0 +1,305 @@
+// Package auditd generates Linux audit records, as auditd writes them
+// to /var/log/audit/audit.log.
+//
+// An audit event is a group of records with the same
+// msg=audit(timestamp:serial), which are written one after the other.
+// The execution of a program is a SYSCALL, EXECVE, CWD, two PATH and a
+// PROCTITLE record, the opening of a file a SYSCALL, CWD, PATH and
+// PROCTITLE record.  Opening a file a user may not read fails with
+// EACCES.  Values that auditd hex encodes, such as arguments with
+// spaces, are hex encoded.
+//
+// Configuration:
+//
+//	node:      The hostname of the "node=" prefix of the records, which
+//	           auditd adds with name_format.  Leave blank for no prefix.
+//	multiline: If true every message is a whole event, its records on
+//	           lines of their own.  Defaults to false, a message per
+//	           record.
+//
+//	- generator:
+//	    type: linux:auditd
+//	    node: web01
+//	    multiline: true
+package auditd
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "linux:auditd"
+
+// unset is the auid and ses of processes without a login session.
+const unset = 4294967295
+
+const (
+	syscallExecve = 59
+	syscallOpenat = 257
+	// atFDCWD is AT_FDCWD, the dirfd of openat for a path relative to
+	// the working directory.
+	atFDCWD = "ffffff9c"
+)
+
+var (
+	users = [...]struct {
+		name string
+		id   int
+		home string
+		tty  string
+	}{
+		{"root", 0, "/root", "pts0"},
+		{"alice", 1000, "/home/alice", "pts1"},
+		{"bob", 1001, "/home/bob", "pts2"},
+		{"www-data", 33, "/var/www", "(none)"},
+	}
+	commands = [...]struct {
+		exe  string
+		argv []string
+	}{
+		{"/usr/bin/ls", []string{"ls", "-la"}},
+		{"/usr/bin/cat", []string{"cat", "/etc/hostname"}},
+		{"/usr/bin/sudo", []string{"sudo", "systemctl", "restart", "nginx"}},
+		{"/usr/bin/curl", []string{"curl", "-s", "-o", "/tmp/install.sh", "https://example.com/install.sh"}},
+		{"/usr/bin/bash", []string{"bash", "-c", "echo hello world > /tmp/out"}},
+		{"/usr/bin/ssh", []string{"ssh", "bob@10.0.0.5"}},
+		{"/usr/bin/python3.10", []string{"python3", "/opt/app/run.py", "--config", "/etc/app/app.yml"}},
+		{"/usr/bin/tar", []string{"tar", "czf", "/tmp/backup.tgz", "/var/www"}},
+		{"/usr/bin/id", []string{"id"}},
+		{"/usr/bin/grep", []string{"grep", "-r", "password", "."}},
+	}
+	files = [...]struct {
+		name  string
+		inode int
+		mode  string
+		// private files can only be opened by root
+		private bool
+	}{
+		{"/etc/passwd", 1048601, "0100644", false},
+		{"/etc/shadow", 1048622, "0100640", true},
+		{"/etc/sudoers", 1048709, "0100440", true},
+		{"/etc/hosts", 1048605, "0100644", false},
+		{"/var/log/auth.log", 2097321, "0100640", true},
+		{"/etc/ssh/sshd_config", 1051201, "0100644", false},
+	}
+	openers = [...]string{"/usr/bin/cat", "/usr/bin/vim.basic", "/usr/bin/less", "/usr/bin/python3.10"}
+)
+
+// field is a name=value field of a record.
+type field struct {
+	name  string
+	value interface{}
+}
+
+// record is a record of an event.
+type record struct {
+	typ    string
+	fields []field
+}
+
+// Auditd holds the state of the auditd generator.
+type Auditd struct {
+	rand      *rand.Rand
+	node      string
+	multiline bool
+	serial    int
+	pending   []string
+	now       func() time.Time
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Auditd objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Auditd{
+		rand:      r,
+		node:      c.Node,
+		multiline: c.Multiline,
+		serial:    r.IntN(100000) + 1000,
+		now:       clock.Now,
+	}, nil
+}
+
+// Next produces the next audit record, or the next event with
+// multiline.
+//
+// Example:
+//
+// type=SYSCALL msg=audit(1709553600.000:99083): arch=c000003e syscall=59 success=yes exit=0 a0=55f183c471d4 a1=55587cb3ad0b a2=551aa42655d9 a3=11 items=2 ppid=24237 pid=24344 auid=1001 uid=1001 gid=1001 euid=1001 suid=1001 fsuid=1001 egid=1001 sgid=1001 fsgid=1001 tty=pts2 ses=6 comm="cat" exe="/usr/bin/cat" subj=unconfined key="exec"
+func (a *Auditd) Next() ([]byte, error) {
+	for len(a.pending) == 0 {
+		a.event()
+	}
+	line := a.pending[0]
+	a.pending = a.pending[1:]
+	return []byte(line), nil
+}
+
+// event queues the records of a new event.
+func (a *Auditd) event() {
+	a.serial++
+	now := a.now()
+	msg := fmt.Sprintf("audit(%d.%03d:%d)", now.Unix(), now.Nanosecond()/1e6, a.serial)
+
+	var records []record
+	if a.rand.IntN(3) == 0 {
+		records = a.open()
+	} else {
+		records = a.execve()
+	}
+	lines := make([]string, len(records))
+	for i, r := range records {
+		lines[i] = a.format(msg, r)
+	}
+	if a.multiline {
+		a.pending = append(a.pending, strings.Join(lines, "\n"))
+		return
+	}
+	a.pending = append(a.pending, lines...)
+}
+
+// process returns the fields of the SYSCALL record about the process,
+// from ppid to ses, of user u.
+func (a *Auditd) process(u int) []field {
+	user := users[u]
+	auid, ses, tty := user.id, a.rand.IntN(20)+1, user.tty
+	if user.tty == "(none)" {
+		auid, ses = unset, unset
+	}
+	ppid := a.rand.IntN(30000) + 1000
+	return []field{
+		{"ppid", ppid}, {"pid", ppid + a.rand.IntN(500) + 1}, {"auid", auid},
+		{"uid", user.id}, {"gid", user.id}, {"euid", user.id}, {"suid", user.id}, {"fsuid", user.id},
+		{"egid", user.id}, {"sgid", user.id}, {"fsgid", user.id}, {"tty", tty}, {"ses", ses},
+	}
+}
+
+// execve returns the records of the execution of a program.
+func (a *Auditd) execve() []record {
+	cmd := commands[a.rand.IntN(len(commands))]
+	u := a.rand.IntN(len(users))
+	cwd := users[u].home
+
+	sys := []field{
+		{"arch", "c000003e"}, {"syscall", syscallExecve}, {"success", "yes"}, {"exit", 0},
+		{"a0", a.pointer()}, {"a1", a.pointer()}, {"a2", a.pointer()}, {"a3", a.rand.IntN(16)},
+		{"items", 2},
+	}
+	sys = append(sys, a.process(u)...)
+	sys = append(sys, field{"comm", encode(comm(cmd.exe))}, field{"exe", encode(cmd.exe)}, field{"subj", "unconfined"}, field{"key", `"exec"`})
+
+	args := []field{{"argc", len(cmd.argv)}}
+	for i, arg := range cmd.argv {
+		args = append(args, field{fmt.Sprintf("a%d", i), encode(arg)})
+	}
+
+	return []record{
+		{"SYSCALL", sys},
+		{"EXECVE", args},
+		{"CWD", []field{{"cwd", encode(cwd)}}},
+		a.path(0, cmd.exe, 1048000+a.rand.IntN(5000), "0100755"),
+		a.path(1, "/lib64/ld-linux-x86-64.so.2", 1310871, "0100755"),
+		{"PROCTITLE", []field{{"proctitle", encode(strings.Join(cmd.argv, "\x00"))}}},
+	}
+}
+
+// open returns the records of the opening of a file.
+func (a *Auditd) open() []record {
+	f := files[a.rand.IntN(len(files))]
+	exe := openers[a.rand.IntN(len(openers))]
+	u := a.rand.IntN(len(users))
+	cwd := users[u].home
+
+	success, exit := "yes", a.rand.IntN(10)+3
+	if f.private && users[u].id != 0 {
+		// EACCES
+		success, exit = "no", -13
+	}
+	sys := []field{
+		{"arch", "c000003e"}, {"syscall", syscallOpenat}, {"success", success}, {"exit", exit},
+		{"a0", atFDCWD}, {"a1", a.pointer()}, {"a2", 0}, {"a3", 0},
+		{"items", 1},
+	}
+	sys = append(sys, a.process(u)...)
+	sys = append(sys, field{"comm", encode(comm(exe))}, field{"exe", encode(exe)}, field{"subj", "unconfined"}, field{"key", `"access"`})
+
+	return []record{
+		{"SYSCALL", sys},
+		{"CWD", []field{{"cwd", encode(cwd)}}},
+		a.path(0, f.name, f.inode, f.mode),
+		{"PROCTITLE", []field{{"proctitle", encode(comm(exe) + "\x00" + f.name)}}},
+	}
+}
+
+// path returns the PATH record of item.
+func (a *Auditd) path(item int, name string, inode int, mode string) record {
+	return record{"PATH", []field{
+		{"item", item}, {"name", encode(name)}, {"inode", inode}, {"dev", "fd:01"}, {"mode", mode},
+		{"ouid", 0}, {"ogid", 0}, {"rdev", "00:00"}, {"nametype", "NORMAL"},
+		{"cap_fp", 0}, {"cap_fi", 0}, {"cap_fe", 0}, {"cap_fver", 0}, {"cap_frootid", 0},
+	}}
+}
+
+// pointer returns a user space address, the way auditd writes
+// syscall arguments.
+func (a *Auditd) pointer() string {
+	return fmt.Sprintf("%x", 0x550000000000+a.rand.Int64N(0x10000000000))
+}
+
+// format returns the line of record r of the event msg.
+func (a *Auditd) format(msg string, r record) string {
+	var buf bytes.Buffer
+	if a.node != "" {
+		fmt.Fprintf(&buf, "node=%s ", a.node)
+	}
+	fmt.Fprintf(&buf, "type=%s msg=%s:", r.typ, msg)
+	for _, f := range r.fields {
+		fmt.Fprintf(&buf, " %s=%v", f.name, f.value)
+	}
+	return buf.String()
+}
+
+// comm returns the command name of the process of exe, which the
+// kernel truncates to 15 characters.
+func comm(exe string) string {
+	name := exe[strings.LastIndex(exe, "/")+1:]
+	if len(name) > 15 {
+		name = name[:15]
+	}
+	return name
+}
+
+// encode returns s the way the kernel logs untrusted strings, quoted,
+// or hex encoded when s contains a space, a quote or a character that
+// is not printable ASCII.
+func encode(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] <= 0x20 || s[i] >= 0x7f || s[i] == '"' {
+			return fmt.Sprintf("%X", s)
+		}
+	}
+	return `"` + s + `"`
+}
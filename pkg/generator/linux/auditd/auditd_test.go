@@ -0,0 +1,126 @@
+package auditd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testTime = time.Date(2024, 3, 4, 12, 0, 0, 500000000, time.UTC)
+	line     = regexp.MustCompile(`^(?:node=(\S+) )?type=([A-Z]+) msg=(audit\(1709553600\.500:\d+\)): (.*)$`)
+)
+
+func newAuditd(t *testing.T, cfg map[string]interface{}) *Auditd {
+	c, err := ucfg.NewFrom(cfg)
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	a := g.(*Auditd)
+	a.now = func() time.Time { return testTime }
+	return a
+}
+
+func TestNext(t *testing.T) {
+	a := newAuditd(t, map[string]interface{}{"type": Name, "seed": 1})
+
+	// read whole events, a group of records with the same msg
+	var groups [][][]string
+	msg := ""
+	for i := 0; i < 1000 || len(a.pending) > 0; i++ {
+		got, err := a.Next()
+		assert.Nil(t, err)
+		m := line.FindStringSubmatch(string(got))
+		if !assert.NotNil(t, m, string(got)) {
+			continue
+		}
+		assert.Empty(t, m[1])
+		if m[3] != msg {
+			groups = append(groups, nil)
+			msg = m[3]
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], m[2:])
+	}
+
+	serials := map[string]bool{}
+	for _, g := range groups {
+		assert.False(t, serials[g[0][1]], "serial is unique")
+		serials[g[0][1]] = true
+
+		var types []string
+		for _, r := range g {
+			types = append(types, r[0])
+		}
+		assert.Equal(t, "SYSCALL", types[0])
+		assert.Equal(t, "PROCTITLE", types[len(types)-1])
+		assert.Contains(t, types, "CWD")
+
+		items := regexp.MustCompile(` items=(\d+) `).FindStringSubmatch(g[0][2])
+		paths := 0
+		for _, r := range g {
+			if r[0] == "PATH" {
+				assert.Contains(t, r[2], "item="+strconv.Itoa(paths)+" ")
+				paths++
+			}
+		}
+		assert.Equal(t, items[1], strconv.Itoa(paths))
+
+		switch {
+		case strings.Contains(g[0][2], "syscall=59 "):
+			assert.Equal(t, []string{"SYSCALL", "EXECVE", "CWD", "PATH", "PATH", "PROCTITLE"}, types)
+			argc := regexp.MustCompile(`^argc=(\d+)`).FindStringSubmatch(g[1][2])
+			n, _ := strconv.Atoi(argc[1])
+			assert.Len(t, strings.Fields(g[1][2]), n+1)
+		case strings.Contains(g[0][2], "syscall=257 "):
+			assert.Equal(t, []string{"SYSCALL", "CWD", "PATH", "PROCTITLE"}, types)
+			if strings.Contains(g[0][2], "success=no") {
+				assert.Contains(t, g[0][2], "exit=-13 ")
+				assert.NotContains(t, g[0][2], " uid=0 ")
+			}
+		default:
+			assert.Fail(t, "unexpected syscall", g[0][2])
+		}
+	}
+}
+
+func TestNode(t *testing.T) {
+	a := newAuditd(t, map[string]interface{}{"type": Name, "node": "web01"})
+	got, err := a.Next()
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(got), "node=web01 type=SYSCALL msg=audit(1709553600.500:"), string(got))
+}
+
+func TestEncode(t *testing.T) {
+	assert.Equal(t, `"/usr/bin/ls"`, encode("/usr/bin/ls"))
+	assert.Equal(t, "6563686F2068656C6C6F", encode("echo hello"))
+	assert.Equal(t, "6C73002D6C61", encode("ls\x00-la"))
+	assert.Equal(t, "612262", encode(`a"b`))
+	assert.Equal(t, "python3.10", comm("/usr/bin/python3.10"))
+	assert.Equal(t, "0123456789abcde", comm("/opt/0123456789abcdefgh"))
+}
+
+func TestMultiline(t *testing.T) {
+	a := newAuditd(t, map[string]interface{}{"type": Name, "multiline": true})
+	for i := 0; i < 100; i++ {
+		got, err := a.Next()
+		assert.Nil(t, err)
+		lines := strings.Split(string(got), "\n")
+		assert.GreaterOrEqual(t, len(lines), 4)
+		first := line.FindStringSubmatch(lines[0])
+		if !assert.NotNil(t, first, lines[0]) {
+			continue
+		}
+		assert.Equal(t, "SYSCALL", first[2])
+		for _, l := range lines[1:] {
+			m := line.FindStringSubmatch(l)
+			if assert.NotNil(t, m, l) {
+				assert.Equal(t, first[3], m[3], "records of an event have the same msg")
+			}
+		}
+	}
+}
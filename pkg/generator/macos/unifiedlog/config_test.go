@@ -0,0 +1,64 @@
+package unifiedlog
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Unified Log": {
+			c:           map[string]interface{}{"type": Name, "event_type": "unifiedlog"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Jamf": {
+			c:           map[string]interface{}{"type": Name, "event_type": "jamf"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'macos:unifiedlog' accessing config",
+		},
+		"Invalid Event Type": {
+			c:           map[string]interface{}{"type": Name, "event_type": "syslog"},
+			hasError:    true,
+			errorString: "'syslog' is not a valid value for 'event_type' expected 'unifiedlog, jamf' accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			_, err = New(c)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
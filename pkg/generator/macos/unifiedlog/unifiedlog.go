@@ -0,0 +1,408 @@
+// Package unifiedlog generates macOS unified log records, as produced
+// by `log show --style ndjson`, and Jamf Pro audit events, as
+// delivered by Jamf Pro webhooks.
+//
+// Configuration:
+//
+//	event_type: Specify the type of event to generate, or leave blank for random.
+//	            Valid values are: unifiedlog, jamf.
+//
+//	- generator:
+//	    type: macos:unifiedlog
+//	    event_type: unifiedlog
+package unifiedlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "macos:unifiedlog"
+
+const timestampFmt = "2006-01-02 15:04:05.000000-0700"
+
+const (
+	EventTypeUnifiedLog = "unifiedlog"
+	EventTypeJamf       = "jamf"
+)
+
+// process describes a logging process along with the subsystem,
+// category and messages it typically emits.
+type process struct {
+	ImagePath  string
+	SenderPath string
+	Subsystem  string
+	Category   string
+	Messages   []string
+}
+
+var (
+	eventTypes   = [...]string{EventTypeUnifiedLog, EventTypeJamf}
+	messageTypes = [...]string{"Default", "Info", "Debug", "Error", "Fault"}
+	processes    = [...]process{
+		{
+			ImagePath:  "/usr/libexec/sshd-keygen-wrapper",
+			SenderPath: "/usr/sbin/sshd",
+			Subsystem:  "com.openssh.sshd",
+			Messages: []string{
+				"Accepted publickey for %s from %s port %d ssh2",
+				"Failed password for %s from %s port %d ssh2",
+			},
+		},
+		{
+			ImagePath:  "/System/Library/Frameworks/LocalAuthentication.framework/Support/coreauthd",
+			SenderPath: "/System/Library/Frameworks/LocalAuthentication.framework/Support/coreauthd",
+			Subsystem:  "com.apple.LocalAuthentication",
+			Category:   "Daemon",
+			Messages: []string{
+				"Authentication succeeded for user %s from %s port %d",
+				"Authentication failed for user %s from %s port %d",
+			},
+		},
+		{
+			ImagePath:  "/usr/libexec/opendirectoryd",
+			SenderPath: "/System/Library/OpenDirectory/Modules/AppleODClient.bundle/Contents/MacOS/AppleODClient",
+			Subsystem:  "com.apple.opendirectoryd",
+			Category:   "auth",
+			Messages: []string{
+				"Authentication succeeded for %s (client: %s:%d)",
+				"Authentication failed for %s (client: %s:%d)",
+			},
+		},
+		{
+			ImagePath:  "/usr/sbin/screensharingd",
+			SenderPath: "/usr/sbin/screensharingd",
+			Subsystem:  "com.apple.screensharing",
+			Category:   "server",
+			Messages: []string{
+				"Authentication: SUCCEEDED :: User Name: %s :: Viewer Address: %s :: Port: %d",
+				"Authentication: FAILED :: User Name: %s :: Viewer Address: %s :: Port: %d",
+			},
+		},
+		{
+			ImagePath:  "/usr/local/jamf/bin/jamf",
+			SenderPath: "/usr/local/jamf/bin/jamf",
+			Subsystem:  "com.jamf.management",
+			Category:   "policy",
+			Messages: []string{
+				"Executing policy for user %s, server %s, attempt %d",
+				"Policy check-in failed for user %s, server %s, attempt %d",
+			},
+		},
+	}
+	users = [...]string{"alice", "bob", "carol", "dave", "eve", "mallory", "root", "jamfadmin"}
+
+	jamfEvents = [...]string{
+		"ComputerCheckIn",
+		"ComputerInventoryCompleted",
+		"ComputerPolicyFinished",
+		"RestAPIOperation",
+		"SmartGroupComputerMembershipChange",
+	}
+	jamfOperations  = [...]string{"GET", "POST", "PUT", "DELETE"}
+	jamfObjectTypes = [...]string{"Computer", "Static Computer Group", "Policy", "Configuration Profile", "Script", "User"}
+	jamfGroups      = [...]string{"All Managed Clients", "FileVault Not Enabled", "Out Of Date OS", "Engineering Laptops"}
+	osVersions      = [...]string{"12.6.3", "13.2.1", "13.4", "14.0"}
+	models          = [...]string{"MacBookPro18,3", "MacBookAir10,1", "Macmini9,1", "iMac21,1"}
+)
+
+// Frame is a single frame in a unified log backtrace.
+type Frame struct {
+	ImageOffset int    `json:"imageOffset"`
+	ImageUUID   string `json:"imageUUID"`
+}
+
+// Backtrace is the backtrace of a unified log record.
+type Backtrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+// UnifiedLog holds the fields of a unified log record.
+type UnifiedLog struct {
+	TraceID                  uint64    `json:"traceID"`
+	EventMessage             string    `json:"eventMessage"`
+	EventType                string    `json:"eventType"`
+	Source                   *string   `json:"source"`
+	FormatString             string    `json:"formatString"`
+	ActivityIdentifier       int       `json:"activityIdentifier"`
+	Subsystem                string    `json:"subsystem"`
+	Category                 string    `json:"category"`
+	ThreadID                 int       `json:"threadID"`
+	SenderImageUUID          string    `json:"senderImageUUID"`
+	Backtrace                Backtrace `json:"backtrace"`
+	BootUUID                 string    `json:"bootUUID"`
+	ProcessImagePath         string    `json:"processImagePath"`
+	Timestamp                string    `json:"timestamp"`
+	SenderImagePath          string    `json:"senderImagePath"`
+	MachTimestamp            uint64    `json:"machTimestamp"`
+	MessageType              string    `json:"messageType"`
+	ProcessImageUUID         string    `json:"processImageUUID"`
+	ProcessID                int       `json:"processID"`
+	SenderProgramCounter     int       `json:"senderProgramCounter"`
+	ParentActivityIdentifier int       `json:"parentActivityIdentifier"`
+	TimezoneName             string    `json:"timezoneName"`
+}
+
+// JamfWebhook describes the webhook that delivered a Jamf Pro event.
+type JamfWebhook struct {
+	EventTimestamp int64  `json:"eventTimestamp"`
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	WebhookEvent   string `json:"webhookEvent"`
+}
+
+// JamfComputer holds the computer fields of a Jamf Pro event.
+type JamfComputer struct {
+	UDID         string `json:"udid"`
+	DeviceName   string `json:"deviceName"`
+	Model        string `json:"model"`
+	MacAddress   string `json:"macAddress"`
+	SerialNumber string `json:"serialNumber"`
+	OSVersion    string `json:"osVersion"`
+	Username     string `json:"username"`
+	IPAddress    string `json:"ipAddress"`
+	JSSID        int    `json:"jssID"`
+}
+
+// JamfEvent holds the fields of a Jamf Pro event.  Only the fields
+// relevant to the webhook event are populated.
+type JamfEvent struct {
+	Computer *JamfComputer `json:"computer,omitempty"`
+
+	// ComputerPolicyFinished
+	PolicyID   int   `json:"policyId,omitempty"`
+	Successful *bool `json:"successful,omitempty"`
+
+	// RestAPIOperation
+	AuthorizedUsername   string `json:"authorizedUsername,omitempty"`
+	ObjectID             int    `json:"objectID,omitempty"`
+	ObjectName           string `json:"objectName,omitempty"`
+	ObjectTypeName       string `json:"objectTypeName,omitempty"`
+	OperationSuccessful  *bool  `json:"operationSuccessful,omitempty"`
+	RestAPIOperationType string `json:"restAPIOperationType,omitempty"`
+
+	// SmartGroupComputerMembershipChange
+	GroupAddedDevicesIDs   []int  `json:"groupAddedDevicesIds,omitempty"`
+	GroupRemovedDevicesIDs []int  `json:"groupRemovedDevicesIds,omitempty"`
+	JSSID                  int    `json:"jssid,omitempty"`
+	Name                   string `json:"name,omitempty"`
+	SmartGroup             *bool  `json:"smartGroup,omitempty"`
+}
+
+// Jamf holds the fields of a Jamf Pro webhook event.
+type Jamf struct {
+	Event   JamfEvent   `json:"event"`
+	Webhook JamfWebhook `json:"webhook"`
+}
+
+// Generator provides a macOS unified log and Jamf Pro event generator.
+type Generator struct {
+	UnifiedLog UnifiedLog
+	Jamf       Jamf
+
+	eventType  string
+	current    string
+	bootUUID   string
+	rand       *rand.Rand
+	pins       *generator.Pins
+	staticTime *time.Time
+	clock      *generator.Clock
+}
+
+func init() {
+	_ = generator.Register(Name, New)
+}
+
+// New is the factory for macOS unified log objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	g := Generator{
+		eventType: c.EventType,
+		bootUUID:  randomUUID(r),
+		rand:      r,
+		clock:     clock,
+	}
+
+	g.pins, err = generator.NewPins(cfg, r, &g)
+	if err != nil {
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+// Next produces the next unified log record or Jamf Pro event.
+func (g *Generator) Next() ([]byte, error) {
+	g.randomize()
+
+	var v interface{}
+	switch g.current {
+	case EventTypeJamf:
+		v = &g.Jamf
+	default:
+		v = &g.UnifiedLog
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal %s data: %w", Name, err)
+	}
+
+	return data, nil
+}
+
+func (g *Generator) now() time.Time {
+	if g.staticTime != nil {
+		return *g.staticTime
+	}
+
+	return g.clock.Now()
+}
+
+func (g *Generator) randomize() {
+	if g.eventType == "" {
+		g.current = eventTypes[g.rand.IntN(len(eventTypes))]
+	} else {
+		g.current = g.eventType
+	}
+
+	switch g.current {
+	case EventTypeJamf:
+		g.randomizeJamf()
+	default:
+		g.randomizeUnifiedLog()
+	}
+
+	g.pins.Apply(g)
+}
+
+func (g *Generator) randomizeUnifiedLog() {
+	now := g.now()
+	p := processes[g.rand.IntN(len(processes))]
+	format := p.Messages[g.rand.IntN(len(p.Messages))]
+	senderUUID := randomUUID(g.rand)
+
+	g.UnifiedLog = UnifiedLog{
+		TraceID:            g.rand.Uint64(),
+		EventMessage:       fmt.Sprintf(format, users[g.rand.IntN(len(users))], random.IPv4(g.rand), random.Port(g.rand)),
+		EventType:          "logEvent",
+		FormatString:       format,
+		ActivityIdentifier: g.rand.IntN(1 << 20),
+		Subsystem:          p.Subsystem,
+		Category:           p.Category,
+		ThreadID:           g.rand.IntN(1 << 24),
+		SenderImageUUID:    senderUUID,
+		Backtrace: Backtrace{
+			Frames: []Frame{
+				{ImageOffset: g.rand.IntN(1 << 20), ImageUUID: senderUUID},
+			},
+		},
+		BootUUID:                 g.bootUUID,
+		ProcessImagePath:         p.ImagePath,
+		Timestamp:                now.Format(timestampFmt),
+		SenderImagePath:          p.SenderPath,
+		MachTimestamp:            uint64(now.UnixNano()),
+		MessageType:              messageTypes[g.rand.IntN(len(messageTypes))],
+		ProcessImageUUID:         randomUUID(g.rand),
+		ProcessID:                g.rand.IntN(65536),
+		SenderProgramCounter:     g.rand.IntN(1 << 20),
+		ParentActivityIdentifier: 0,
+		TimezoneName:             "",
+	}
+}
+
+func (g *Generator) randomizeJamf() {
+	now := g.now()
+	event := jamfEvents[g.rand.IntN(len(jamfEvents))]
+	successful := g.rand.IntN(10) != 0
+
+	g.Jamf = Jamf{
+		Webhook: JamfWebhook{
+			EventTimestamp: now.UnixMilli(),
+			ID:             g.rand.IntN(100) + 1,
+			Name:           event + " Webhook",
+			WebhookEvent:   event,
+		},
+	}
+
+	switch event {
+	case "RestAPIOperation":
+		g.Jamf.Event = JamfEvent{
+			AuthorizedUsername:   users[g.rand.IntN(len(users))],
+			ObjectID:             g.rand.IntN(10000) + 1,
+			ObjectName:           fmt.Sprintf("Object-%d", g.rand.IntN(1000)),
+			ObjectTypeName:       jamfObjectTypes[g.rand.IntN(len(jamfObjectTypes))],
+			OperationSuccessful:  &successful,
+			RestAPIOperationType: jamfOperations[g.rand.IntN(len(jamfOperations))],
+		}
+	case "SmartGroupComputerMembershipChange":
+		smart := true
+		g.Jamf.Event = JamfEvent{
+			GroupAddedDevicesIDs:   randomIDs(g.rand),
+			GroupRemovedDevicesIDs: randomIDs(g.rand),
+			JSSID:                  g.rand.IntN(100) + 1,
+			Name:                   jamfGroups[g.rand.IntN(len(jamfGroups))],
+			SmartGroup:             &smart,
+		}
+	case "ComputerPolicyFinished":
+		g.Jamf.Event = JamfEvent{
+			Computer:   randomComputer(g.rand),
+			PolicyID:   g.rand.IntN(500) + 1,
+			Successful: &successful,
+		}
+	default:
+		g.Jamf.Event = JamfEvent{
+			Computer: randomComputer(g.rand),
+		}
+	}
+}
+
+func randomComputer(r *rand.Rand) *JamfComputer {
+	return &JamfComputer{
+		UDID:         randomUUID(r),
+		DeviceName:   fmt.Sprintf("MAC-%04d", r.IntN(10000)),
+		Model:        models[r.IntN(len(models))],
+		MacAddress:   randomMAC(r),
+		SerialNumber: fmt.Sprintf("C02%09X", r.Int64N(1<<36)),
+		OSVersion:    osVersions[r.IntN(len(osVersions))],
+		Username:     users[r.IntN(len(users))],
+		IPAddress:    random.IPv4(r).String(),
+		JSSID:        r.IntN(10000) + 1,
+	}
+}
+
+func randomIDs(r *rand.Rand) []int {
+	ids := make([]int, r.IntN(4))
+	for i := range ids {
+		ids[i] = r.IntN(10000) + 1
+	}
+	return ids
+}
+
+func randomUUID(r *rand.Rand) string {
+	return fmt.Sprintf("%08X-%04X-%04X-%04X-%012X", r.Uint32(), r.IntN(1<<16), r.IntN(1<<16), r.IntN(1<<16), r.Int64N(1<<48))
+}
+
+func randomMAC(r *rand.Rand) string {
+	return fmt.Sprintf("%02X:%02X:%02X:%02X:%02X:%02X", r.IntN(256), r.IntN(256), r.IntN(256), r.IntN(256), r.IntN(256), r.IntN(256))
+}
@@ -0,0 +1,46 @@
+package unifiedlog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator_Next(t *testing.T) {
+	tests := map[string]struct {
+		config   map[string]interface{}
+		expected string
+	}{
+		"unifiedlog": {
+			config:   map[string]interface{}{"event_type": "unifiedlog"},
+			expected: `{"traceID":163209243133002443,"eventMessage":"Accepted publickey for carol from 145.251.210.48 port 49447 ssh2","eventType":"logEvent","source":null,"formatString":"Accepted publickey for %s from %s port %d ssh2","activityIdentifier":793929,"subsystem":"com.openssh.sshd","category":"","threadID":8308777,"senderImageUUID":"D92B02C3-BB83-AB37-131A-B8CAF101952E","backtrace":{"frames":[{"imageOffset":878026,"imageUUID":"D92B02C3-BB83-AB37-131A-B8CAF101952E"}]},"bootUUID":"E08EB556-1F8F-AB3C-559B-FFBD92E43B2A","processImagePath":"/usr/libexec/sshd-keygen-wrapper","timestamp":"1970-01-02 03:04:05.000000+0700","senderImagePath":"/usr/sbin/sshd","machTimestamp":72245000000000,"messageType":"Error","processImageUUID":"5392CE35-D9B3-6CB1-415F-95227AA59F9A","processID":25403,"senderProgramCounter":47364,"parentActivityIdentifier":0,"timezoneName":""}`,
+		},
+		"jamf": {
+			config:   map[string]interface{}{"event_type": "jamf"},
+			expected: `{"event":{"computer":{"udid":"40ECCE6C-AB37-131A-952E-D5F12100C2CB","deviceName":"MAC-2351","model":"MacBookPro18,3","macAddress":"27:49:29:CA:D8:1D","serialNumber":"C02626D6D9B3","osVersion":"13.2.1","username":"jamfadmin","ipAddress":"34.149.211.0","jssID":5540}},"webhook":{"eventTimestamp":72245000,"id":85,"name":"ComputerCheckIn Webhook","webhookEvent":"ComputerCheckIn"}}`,
+		},
+	}
+
+	testTime, err := time.Parse(time.RFC3339, "1970-01-02T03:04:05+07:00")
+	assert.NoError(t, err)
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			random.Seed(1)
+
+			g, err := New(ucfg.MustNewFrom(tc.config))
+			assert.NoError(t, err)
+
+			g.(*Generator).staticTime = &testTime
+
+			got, err := g.Next()
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expected, string(got))
+		})
+	}
+}
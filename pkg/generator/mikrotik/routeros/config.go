@@ -0,0 +1,28 @@
+package routeros
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Type  string `config:"type" validate:"required"`
+	Topic string `config:"topic"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type: Name,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if !(c.Topic == "" || c.Topic == TopicFirewall || c.Topic == TopicDHCP || c.Topic == TopicWireless) {
+		return fmt.Errorf("'%s' is not a valid value for 'topic' expected '%s'", c.Topic, strings.Join([]string{TopicFirewall, TopicDHCP, TopicWireless}, ", "))
+	}
+
+	return nil
+}
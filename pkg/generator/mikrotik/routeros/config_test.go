@@ -0,0 +1,69 @@
+package routeros
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Firewall": {
+			c:           map[string]interface{}{"type": Name, "topic": "firewall"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with DHCP": {
+			c:           map[string]interface{}{"type": Name, "topic": "dhcp"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Wireless": {
+			c:           map[string]interface{}{"type": Name, "topic": "wireless"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'mikrotik:routeros' accessing config",
+		},
+		"Invalid Topic": {
+			c:           map[string]interface{}{"type": Name, "topic": "ppp"},
+			hasError:    true,
+			errorString: "'ppp' is not a valid value for 'topic' expected 'firewall, dhcp, wireless' accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			_, err = New(c)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
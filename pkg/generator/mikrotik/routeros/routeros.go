@@ -0,0 +1,270 @@
+// Package routeros generates MikroTik RouterOS log messages.
+//
+// Messages are generated for the firewall, dhcp and wireless topics
+// in the format RouterOS uses when logging to a remote syslog
+// server, for example "firewall,info input: in:ether1 out:(unknown 0), ...".
+//
+// Each simulated router has a fixed set of interfaces and clients,
+// so the interface names, MAC addresses and IP addresses in firewall,
+// dhcp and wireless messages are consistent with one another.
+//
+// Configuration:
+//
+//	topic: Specify the topic of messages to generate, or leave blank for random.
+//	       Valid values are: firewall, dhcp, wireless.
+//
+//	- generator:
+//	    type: mikrotik:routeros
+//	    topic: firewall
+package routeros
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"text/template"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "mikrotik:routeros"
+
+const (
+	TopicFirewall = "firewall"
+	TopicDHCP     = "dhcp"
+	TopicWireless = "wireless"
+)
+
+const (
+	wanInterface   = "ether1"
+	lanInterface   = "bridge"
+	wlanInterface  = "wlan1"
+	dhcpServer     = "dhcp1"
+	clientsPerHost = 8
+)
+
+var (
+	firewallTemplate = "firewall,info {{.Chain}}: in:{{.InInterface}} out:{{.OutInterface}}, {{if .SrcMAC}}src-mac {{.SrcMAC}}, {{end}}proto {{.Protocol}}{{if eq .Protocol \"TCP\"}} ({{.Flags}}){{end}}, {{.SrcAddr}}{{if ne .Protocol \"ICMP\"}}:{{.SrcPort}}{{end}}->{{.DstAddr}}{{if ne .Protocol \"ICMP\"}}:{{.DstPort}}{{end}}, len {{.Length}}"
+	dhcpTemplates    = [...]string{
+		"dhcp,info {{.Server}} assigned {{.ClientAddr}} to {{.ClientMAC}}",
+		"dhcp,info {{.Server}} deassigned {{.ClientAddr}} from {{.ClientMAC}}",
+	}
+	wirelessTemplates = [...]string{
+		"wireless,info {{.StationMAC}}@{{.Interface}}: connected, signal strength {{.Signal}}",
+		"wireless,info {{.StationMAC}}@{{.Interface}}: disconnected, {{.Reason}}, signal strength {{.Signal}}",
+	}
+	chains    = [...]string{"input", "forward", "output"}
+	protocols = [...]string{"TCP", "UDP", "ICMP"}
+	tcpFlags  = [...]string{"SYN", "ACK", "ACK,PSH", "ACK,FIN", "RST"}
+	reasons   = [...]string{
+		"received disassoc: sending station leaving (8)",
+		"received deauth: unspecified (1)",
+		"extensive data loss",
+		"group key exchange timeout",
+	}
+)
+
+// client is a host on a router's LAN that has a DHCP lease and may
+// be associated with the router's wireless interface.
+type client struct {
+	mac  string
+	addr net.IP
+}
+
+// host is a simulated router with fixed interface MAC addresses and
+// a pool of LAN clients, some of which are wireless stations.
+type host struct {
+	wanMAC   string
+	lanAddr  net.IP
+	clients  []client
+	stations []client
+}
+
+// RouterOS holds the random fields for a RouterOS log message.
+type RouterOS struct {
+	Chain        string
+	ClientAddr   net.IP
+	ClientMAC    string
+	DstAddr      net.IP
+	DstPort      int
+	Flags        string
+	InInterface  string
+	Interface    string
+	Length       int
+	OutInterface string
+	Protocol     string
+	Reason       string
+	Server       string
+	Signal       int
+	SrcAddr      net.IP
+	SrcMAC       string
+	SrcPort      int
+	StationMAC   string
+
+	rand      *rand.Rand
+	pins      *generator.Pins
+	hosts     []host
+	topic     string
+	templates map[string][]*template.Template
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for RouterOS objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	rnd, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &RouterOS{
+		rand:      rnd,
+		topic:     c.Topic,
+		templates: make(map[string][]*template.Template),
+	}
+
+	r.pins, err = generator.NewPins(cfg, rnd, r)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := template.New(TopicFirewall).Funcs(generator.FunctionMap).Parse(firewallTemplate)
+	if err != nil {
+		return nil, err
+	}
+	r.templates[TopicFirewall] = []*template.Template{t}
+
+	for i, v := range dhcpTemplates {
+		t, err := template.New(fmt.Sprintf("%s%d", TopicDHCP, i)).Funcs(generator.FunctionMap).Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		r.templates[TopicDHCP] = append(r.templates[TopicDHCP], t)
+	}
+
+	for i, v := range wirelessTemplates {
+		t, err := template.New(fmt.Sprintf("%s%d", TopicWireless, i)).Funcs(generator.FunctionMap).Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		r.templates[TopicWireless] = append(r.templates[TopicWireless], t)
+	}
+
+	r.hosts = newHosts(rnd)
+	r.randomize()
+
+	return r, nil
+}
+
+// Next produces the next RouterOS log message.
+//
+// Example:
+//
+// firewall,info forward: in:bridge out:ether1, src-mac 4c:5e:0c:12:34:56, proto TCP (SYN), 192.168.88.23:51234->93.184.216.34:443, len 60
+func (r *RouterOS) Next() ([]byte, error) {
+	var buf bytes.Buffer
+
+	topic := r.topic
+	if topic == "" {
+		topic = [...]string{TopicFirewall, TopicDHCP, TopicWireless}[r.rand.IntN(3)]
+	}
+	templates := r.templates[topic]
+
+	err := templates[r.rand.IntN(len(templates))].Execute(&buf, r)
+	if err != nil {
+		return nil, err
+	}
+
+	r.randomize()
+
+	return buf.Bytes(), err
+}
+
+func (r *RouterOS) randomize() {
+	h := r.hosts[r.rand.IntN(len(r.hosts))]
+	c := h.clients[r.rand.IntN(len(h.clients))]
+
+	r.Chain = chains[r.rand.IntN(len(chains))]
+	r.Protocol = protocols[r.rand.IntN(len(protocols))]
+	r.Flags = tcpFlags[r.rand.IntN(len(tcpFlags))]
+	r.SrcPort = random.Port(r.rand)
+	r.DstPort = random.Port(r.rand)
+	r.Length = r.rand.IntN(1460) + 40
+
+	switch r.Chain {
+	case "input":
+		r.InInterface = wanInterface
+		r.OutInterface = "(unknown 0)"
+		r.SrcMAC = h.wanMAC
+		r.SrcAddr = random.IPv4(r.rand)
+		r.DstAddr = random.IPv4(r.rand)
+	case "forward":
+		r.InInterface = lanInterface
+		r.OutInterface = wanInterface
+		r.SrcMAC = c.mac
+		r.SrcAddr = c.addr
+		r.DstAddr = random.IPv4(r.rand)
+	case "output":
+		r.InInterface = "(unknown 0)"
+		r.OutInterface = lanInterface
+		r.SrcMAC = ""
+		r.SrcAddr = h.lanAddr
+		r.DstAddr = c.addr
+	}
+
+	r.Server = dhcpServer
+	r.ClientMAC = c.mac
+	r.ClientAddr = c.addr
+
+	r.StationMAC = h.stations[r.rand.IntN(len(h.stations))].mac
+	r.Interface = wlanInterface
+	r.Signal = -(r.rand.IntN(50) + 40)
+	r.Reason = reasons[r.rand.IntN(len(reasons))]
+
+	r.pins.Apply(r)
+}
+
+// newHosts creates the pool of simulated routers.  Every router has
+// at least one wireless client.
+func newHosts(r *rand.Rand) []host {
+	hosts := make([]host, 3)
+	for i := range hosts {
+		hosts[i].wanMAC = randomMAC(r)
+		hosts[i].lanAddr = net.IPv4(192, 168, byte(88+i), 1)
+		hosts[i].clients = make([]client, clientsPerHost)
+		for j := range hosts[i].clients {
+			hosts[i].clients[j] = client{
+				mac:  randomMAC(r),
+				addr: net.IPv4(192, 168, byte(88+i), byte(254-j)),
+			}
+			if j == 0 || r.IntN(2) == 0 {
+				hosts[i].stations = append(hosts[i].stations, hosts[i].clients[j])
+			}
+		}
+	}
+	return hosts
+}
+
+func randomMAC(r *rand.Rand) string {
+	return fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x", r.IntN(256), r.IntN(256), r.IntN(256), r.IntN(256), r.IntN(256), r.IntN(256))
+}
+
+// Templates returns the templates of the generator, for generator.Lint.
+func (r *RouterOS) Templates() (interface{}, []*template.Template) {
+	var templates []*template.Template
+	for _, t := range r.templates {
+		templates = append(templates, t...)
+	}
+	return r, templates
+}
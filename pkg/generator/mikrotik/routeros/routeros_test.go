@@ -0,0 +1,83 @@
+package routeros
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		topic    string
+		expected string
+	}{
+		"Firewall": {topic: TopicFirewall, expected: `firewall,info forward: in:bridge out:ether1, src-mac c4:8d:ad:34:e0:15, proto TCP (ACK), 192.168.89.253:34732->147.41.163.156:42901, len 987`},
+		"DHCP":     {topic: TopicDHCP, expected: `dhcp,info dhcp1 deassigned 192.168.89.253 from c4:8d:ad:34:e0:15`},
+		"Wireless": {topic: TopicWireless, expected: `wireless,info 4e:0e:da:4a:04:7b@wlan1: disconnected, group key exchange timeout, signal strength -86`},
+	}
+	for name, tc := range tests {
+		random.Seed(1)
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "topic": tc.topic})
+		assert.Nil(t, err, name)
+		g, err := New(c)
+		assert.Nil(t, err, name)
+		got, err := g.Next()
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, string(got), name)
+	}
+}
+
+func TestConsistency(t *testing.T) {
+	random.Seed(1)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	r := g.(*RouterOS)
+
+	known := make(map[string]bool)
+	for _, h := range r.hosts {
+		for _, c := range h.clients {
+			known[c.mac] = true
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		switch {
+		case r.Chain == "forward":
+			assert.True(t, known[r.SrcMAC], "forward src-mac %s is not a LAN client", r.SrcMAC)
+		case r.Chain == "input":
+			assert.False(t, known[r.SrcMAC], "input src-mac %s is a LAN client", r.SrcMAC)
+		}
+		assert.True(t, known[r.ClientMAC], "dhcp client %s is not a LAN client", r.ClientMAC)
+		assert.True(t, known[r.StationMAC], "wireless station %s is not a LAN client", r.StationMAC)
+		assert.True(t, strings.HasPrefix(r.ClientAddr.String(), "192.168."), r.ClientAddr.String())
+		_, err := r.Next()
+		assert.Nil(t, err)
+	}
+}
+
+func TestPin(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{
+		"type":  Name,
+		"topic": TopicFirewall,
+		"pin": map[string]interface{}{
+			"Chain":   "forward",
+			"DstPort": []interface{}{22, 3389},
+			"DstAddr": "203.0.113.10",
+		},
+	})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	for i := 0; i < 100; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(string(got), "firewall,info forward: "), string(got))
+		if !strings.Contains(string(got), "proto ICMP") {
+			assert.Regexp(t, `->203\.0\.113\.10:(22|3389), `, string(got))
+		}
+	}
+}
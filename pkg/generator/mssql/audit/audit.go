@@ -0,0 +1,313 @@
+// Package audit generates Microsoft SQL Server audit and ERRORLOG messages.
+//
+// Two kinds of messages are generated.  Audit events are SQL Server
+// Audit records in the shape produced by exporting the extended events
+// audit file to JSON, with the columns returned by sys.fn_get_audit_file.
+// Errorlog messages are lines from the SQL Server ERRORLOG, including
+// the two line "Error: 18456" login failure pattern with the state
+// and reason for the failure.
+//
+// Configuration:
+//
+//	event_type: Specify the type of event to generate, or leave blank for random.
+//	            Valid values are: audit, errorlog.
+//
+//	- generator:
+//	    type: mssql:audit
+//	    event_type: errorlog
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "mssql:audit"
+
+const (
+	EventTypeAudit    = "audit"
+	EventTypeErrorlog = "errorlog"
+)
+
+const (
+	auditTimestampFmt    = "2006-01-02T15:04:05.0000000Z"
+	errorlogTimestampFmt = "2006-01-02 15:04:05.00"
+)
+
+var (
+	actions = [...]struct {
+		id        string
+		classType string
+		statement string
+	}{
+		{id: "LGIS", classType: "LX"},
+		{id: "LGIF", classType: "LX"},
+		{id: "LGO", classType: "LX"},
+		{id: "SL", classType: "U", statement: "SELECT %[1]s FROM [%[2]s].[%[3]s]"},
+		{id: "IN", classType: "U", statement: "INSERT INTO [%[2]s].[%[3]s] (%[1]s) VALUES (@p1)"},
+		{id: "UP", classType: "U", statement: "UPDATE [%[2]s].[%[3]s] SET %[1]s = @p1 WHERE Id = @p2"},
+		{id: "DL", classType: "U", statement: "DELETE FROM [%[2]s].[%[3]s] WHERE Id = @p1"},
+		{id: "EX", classType: "P", statement: "EXEC [%[2]s].[%[3]s] @p1"},
+		{id: "AL", classType: "SL", statement: "ALTER LOGIN [%[4]s] WITH PASSWORD = '******'"},
+		{id: "G", classType: "U", statement: "GRANT SELECT ON [%[2]s].[%[3]s] TO [%[4]s]"},
+		{id: "AUSC", classType: "A"},
+	}
+	objects = [...]struct {
+		database string
+		schema   string
+		name     string
+		column   string
+	}{
+		{database: "Sales", schema: "dbo", name: "Orders", column: "OrderTotal"},
+		{database: "Sales", schema: "dbo", name: "Customers", column: "CreditCard"},
+		{database: "HR", schema: "hr", name: "Employees", column: "Salary"},
+		{database: "HR", schema: "hr", name: "usp_UpdatePayroll", column: "EmployeeId"},
+		{database: "Finance", schema: "ledger", name: "Entries", column: "Amount"},
+	}
+	instances    = [...]string{"SQL01", "SQL02\\REPORTING", "SQLPROD01", "AZSQL-MI-01"}
+	logins       = [...]string{"sa", "CORP\\jdoe", "CORP\\svc_sql", "app_user", "report_reader", "CORP\\asmith"}
+	applications = [...]string{"Microsoft SQL Server Management Studio", "Microsoft SQL Server Management Studio - Query", ".Net SqlClient Data Provider", "SQLAgent - TSQL JobStep", "sqlcmd"}
+
+	loginFailures = [...]struct {
+		state  int
+		reason string
+	}{
+		{state: 5, reason: "Could not find a login matching the name provided."},
+		{state: 8, reason: "Password did not match that for the login provided."},
+		{state: 8, reason: "Password did not match that for the login provided."},
+		{state: 7, reason: "Login is disabled."},
+		{state: 38, reason: "Failed to open the explicitly specified database '%[1]s'."},
+		{state: 58, reason: "An attempt to login using SQL authentication failed. Server is configured for Windows authentication only."},
+	}
+	errorlogMessages = [...]struct {
+		source  string
+		message string
+	}{
+		{source: "Logon", message: "Login succeeded for user '%[1]s'. Connection made using %[3]s authentication. [CLIENT: %[2]s]"},
+		{source: "spid%[1]d", message: "Starting up database '%[5]s'."},
+		{source: "Backup", message: "Database backed up. Database: %[5]s, creation date(time): 2023/01/01(09:00:00), pages dumped: %[4]d, first LSN: 41:128:37, last LSN: 41:160:1, number of dump devices: 1, device information: (FILE=1, TYPE=DISK: {'D:\\Backup\\%[5]s.bak'}). This is an informational message only. No user action is required."},
+		{source: "spid%[1]ds", message: "SQL Server blocked access to procedure 'sys.xp_cmdshell' of component 'xp_cmdshell' because this component is turned off as part of the security configuration for this server."},
+		{source: "spid%[1]d", message: "Configuration option 'xp_cmdshell' changed from 0 to 1. Run the RECONFIGURE statement to install."},
+		{source: "spid%[1]d", message: "Audit: Server Audit: 65536, State changed from: START_FAILED to: STARTED"},
+	}
+)
+
+// Event holds the fields of a SQL Server Audit record.
+type Event struct {
+	EventTime                 string `json:"event_time"`
+	SequenceNumber            int    `json:"sequence_number"`
+	ActionID                  string `json:"action_id"`
+	Succeeded                 bool   `json:"succeeded"`
+	PermissionBitmask         string `json:"permission_bitmask"`
+	IsColumnPermission        bool   `json:"is_column_permission"`
+	SessionID                 int    `json:"session_id"`
+	ServerPrincipalID         int    `json:"server_principal_id"`
+	DatabasePrincipalID       int    `json:"database_principal_id"`
+	ObjectID                  int    `json:"object_id"`
+	ClassType                 string `json:"class_type"`
+	SessionServerPrincipal    string `json:"session_server_principal_name"`
+	ServerPrincipalName       string `json:"server_principal_name"`
+	ServerPrincipalSID        string `json:"server_principal_sid"`
+	DatabasePrincipalName     string `json:"database_principal_name"`
+	TargetServerPrincipalName string `json:"target_server_principal_name"`
+	ServerInstanceName        string `json:"server_instance_name"`
+	DatabaseName              string `json:"database_name"`
+	SchemaName                string `json:"schema_name"`
+	ObjectName                string `json:"object_name"`
+	Statement                 string `json:"statement"`
+	AdditionalInformation     string `json:"additional_information"`
+	FileName                  string `json:"file_name"`
+	AuditFileOffset           int    `json:"audit_file_offset"`
+	ClientIP                  string `json:"client_ip"`
+	ApplicationName           string `json:"application_name"`
+	DurationMilliseconds      int    `json:"duration_milliseconds"`
+	AffectedRows              int    `json:"affected_rows"`
+}
+
+// Audit holds the state for SQL Server audit and ERRORLOG messages.
+type Audit struct {
+	Event Event
+
+	eventType  string
+	pending    []string
+	rand       *rand.Rand
+	pins       *generator.Pins
+	staticTime *time.Time
+	clock      *generator.Clock
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Audit objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Audit{eventType: c.EventType, rand: r, clock: clock}
+	a.pins, err = generator.NewPins(cfg, r, a)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Next produces the next SQL Server audit or ERRORLOG message.
+//
+// Example:
+//
+// 2023-01-02 03:04:05.12 Logon       Error: 18456, Severity: 14, State: 8.
+// 2023-01-02 03:04:05.12 Logon       Login failed for user 'sa'. Reason: Password did not match that for the login provided. [CLIENT: 10.1.2.3]
+func (a *Audit) Next() ([]byte, error) {
+	// The second line of a login failure is always written right
+	// after the first.
+	if len(a.pending) > 0 {
+		line := a.pending[0]
+		a.pending = a.pending[1:]
+		return []byte(line), nil
+	}
+
+	eventType := a.eventType
+	if eventType == "" {
+		eventType = [...]string{EventTypeAudit, EventTypeErrorlog}[a.rand.IntN(2)]
+	}
+
+	if eventType == EventTypeAudit {
+		a.Event = a.randomizeEvent()
+		a.pins.Apply(a)
+		return json.Marshal(&a.Event)
+	}
+
+	lines := a.errorlog()
+	a.pending = lines[1:]
+	return []byte(lines[0]), nil
+}
+
+func (a *Audit) randomizeEvent() Event {
+	act := actions[a.rand.IntN(len(actions))]
+	obj := objects[a.rand.IntN(len(objects))]
+	login := logins[a.rand.IntN(len(logins))]
+	target := logins[a.rand.IntN(len(logins))]
+	instance := instances[a.rand.IntN(len(instances))]
+
+	e := Event{
+		EventTime:              a.getTime().UTC().Format(auditTimestampFmt),
+		SequenceNumber:         1,
+		ActionID:               act.id,
+		Succeeded:              a.rand.IntN(10) != 0,
+		PermissionBitmask:      "0x00000000000000000000000000000000",
+		SessionID:              a.rand.IntN(200) + 51,
+		ServerPrincipalID:      a.rand.IntN(300) + 256,
+		ClassType:              act.classType,
+		SessionServerPrincipal: login,
+		ServerPrincipalName:    login,
+		ServerPrincipalSID:     fmt.Sprintf("0x%016X%016X", a.rand.Uint64(), a.rand.Uint64()),
+		ServerInstanceName:     instance,
+		FileName:               fmt.Sprintf("D:\\Audit\\ServerAudit_%08X-%04X.sqlaudit", a.rand.Uint32(), a.rand.IntN(1<<16)),
+		AuditFileOffset:        a.rand.IntN(1 << 20),
+		ClientIP:               random.IPv4(a.rand).String(),
+		ApplicationName:        applications[a.rand.IntN(len(applications))],
+	}
+
+	switch act.id {
+	case "LGIS", "LGO":
+		e.Succeeded = true
+		e.AdditionalInformation = fmt.Sprintf("<action_info xmlns=\"http://schemas.microsoft.com/sqlserver/2008/sqlaudit_data\"><pooled_connection>0</pooled_connection><client_options>0x28000020</client_options><client_options1>0x00009838</client_options1><connect_options>0x00000000</connect_options><packet_data_size>8000</packet_data_size><address>%s</address><is_dac>0</is_dac><total_logout_time>0</total_logout_time></action_info>", e.ClientIP)
+	case "LGIF":
+		e.Succeeded = false
+		f := loginFailures[a.rand.IntN(len(loginFailures))]
+		e.Statement = fmt.Sprintf("Login failed for user '%s'. Reason: %s", login, expand(f.reason, obj.database))
+		e.AdditionalInformation = fmt.Sprintf("<action_info xmlns=\"http://schemas.microsoft.com/sqlserver/2008/sqlaudit_data\"><pooled_connection>0</pooled_connection><error>0x00004818</error><state>%d</state><address>%s</address><PasswordFirstNibbleHash>%X</PasswordFirstNibbleHash></action_info>", f.state, e.ClientIP, a.rand.IntN(16))
+	case "AUSC":
+		e.Succeeded = true
+		e.AdditionalInformation = "<action_info xmlns=\"http://schemas.microsoft.com/sqlserver/2008/sqlaudit_data\"><session><![CDATA[ServerAudit$A]]></session><action>event enabled</action><startup_type>automatic</startup_type><object><![CDATA[audit_event]]></object></action_info>"
+	default:
+		e.DatabasePrincipalID = a.rand.IntN(10) + 1
+		e.DatabasePrincipalName = "dbo"
+		e.DatabaseName = obj.database
+		e.SchemaName = obj.schema
+		e.ObjectName = obj.name
+		e.ObjectID = a.rand.IntN(1<<30) + 1
+		e.PermissionBitmask = fmt.Sprintf("0x%032X", 1<<uint(a.rand.IntN(8)))
+		e.Statement = fmt.Sprintf(act.statement, obj.column, obj.schema, obj.name, target)
+		e.DurationMilliseconds = a.rand.IntN(5000)
+		if e.Succeeded && act.id != "G" && act.id != "AL" {
+			e.AffectedRows = a.rand.IntN(1000)
+		}
+		if act.id == "AL" || act.id == "G" {
+			e.TargetServerPrincipalName = target
+		}
+	}
+
+	return e
+}
+
+// errorlog returns the lines for the next ERRORLOG message.
+func (a *Audit) errorlog() []string {
+	ts := a.getTime().Format(errorlogTimestampFmt)
+	login := logins[a.rand.IntN(len(logins))]
+	client := random.IPv4(a.rand).String()
+	obj := objects[a.rand.IntN(len(objects))]
+
+	if a.rand.IntN(2) == 0 {
+		f := loginFailures[a.rand.IntN(len(loginFailures))]
+		return []string{
+			line(ts, "Logon", fmt.Sprintf("Error: 18456, Severity: 14, State: %d.", f.state)),
+			line(ts, "Logon", fmt.Sprintf("Login failed for user '%s'. Reason: %s [CLIENT: %s]", login, expand(f.reason, obj.database), client)),
+		}
+	}
+
+	auth := "SQL Server"
+	if strings.Contains(login, "\\") {
+		auth = "Windows"
+	}
+	m := errorlogMessages[a.rand.IntN(len(errorlogMessages))]
+	spid := a.rand.IntN(200) + 51
+	return []string{
+		line(ts, expand(m.source, spid), expand(m.message, login, client, auth, spid, obj.database)),
+	}
+}
+
+// expand formats s with args.  Messages only use some of the args,
+// so explicit argument indexes are used and messages without any
+// verbs are returned unchanged.
+func expand(s string, args ...interface{}) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	return fmt.Sprintf(s, args...)
+}
+
+// line formats an ERRORLOG line, the source is padded to a fixed width.
+func line(ts, source, message string) string {
+	return fmt.Sprintf("%s %-11s %s", ts, source, message)
+}
+
+func (a *Audit) getTime() time.Time {
+	if a.staticTime != nil {
+		return *a.staticTime
+	}
+
+	return a.clock.Now()
+}
@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		eventType string
+		expected  string
+	}{
+		"Audit":    {eventType: EventTypeAudit, expected: `{"event_time":"1970-01-02T03:04:05.1234560Z","sequence_number":1,"action_id":"G","succeeded":false,"permission_bitmask":"0x00000000000000000000000000000002","is_column_permission":false,"session_id":235,"server_principal_id":510,"database_principal_id":7,"object_id":477896010,"class_type":"U","session_server_principal_name":"CORP\\asmith","server_principal_name":"CORP\\asmith","server_principal_sid":"0x40ECCE6C39C0BB839E8CDF18A8ABAB37","database_principal_name":"dbo","target_server_principal_name":"CORP\\asmith","server_instance_name":"SQLPROD01","database_name":"HR","schema_name":"hr","object_name":"Employees","statement":"GRANT SELECT ON [hr].[Employees] TO [CORP\\asmith]","additional_information":"","file_name":"D:\\Audit\\ServerAudit_E903E548-952E.sqlaudit","audit_file_offset":49867,"client_ip":"246.6.48.60","application_name":"Microsoft SQL Server Management Studio","duration_milliseconds":1365,"affected_rows":0}`},
+		"Errorlog": {eventType: EventTypeErrorlog, expected: `1970-01-02 03:04:05.12 spid52      Configuration option 'xp_cmdshell' changed from 0 to 1. Run the RECONFIGURE statement to install.`},
+	}
+	for name, tc := range tests {
+		random.Seed(1)
+		a := newAudit(t, tc.eventType)
+		got, err := a.Next()
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, string(got), name)
+	}
+}
+
+func TestLoginFailed(t *testing.T) {
+	random.Seed(1)
+	a := newAudit(t, EventTypeErrorlog)
+
+	failures := 0
+	for i := 0; i < 1000; i++ {
+		got, err := a.Next()
+		assert.Nil(t, err)
+		assert.NotContains(t, string(got), "%!")
+		if !strings.Contains(string(got), "Error: 18456") {
+			continue
+		}
+		failures++
+		got, err = a.Next()
+		assert.Nil(t, err)
+		assert.Contains(t, string(got), "Logon       Login failed for user '")
+	}
+	assert.NotZero(t, failures)
+}
+
+func newAudit(t *testing.T, eventType string) *Audit {
+	testTime, err := time.Parse(time.RFC3339Nano, "1970-01-02T03:04:05.123456Z")
+	assert.Nil(t, err)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "event_type": eventType})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	a := g.(*Audit)
+	a.staticTime = &testTime
+	return a
+}
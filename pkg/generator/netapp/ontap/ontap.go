@@ -0,0 +1,370 @@
+// Package ontap generates NetApp ONTAP audit and EMS log messages.
+//
+// Two kinds of messages are generated.  CIFS audit records are the
+// XML events ONTAP writes when native file access auditing is
+// enabled on an SVM; they follow the Windows Security log schema
+// (event IDs 4624, 4625, 4634, 4656, 4660, 4663 and 4670).  EMS
+// messages are Event Management System events forwarded to syslog,
+// for example "[cluster1-01: wafl: wafl.vol.full:alert]: ...".
+//
+// Configuration:
+//
+//	event_type: Specify the type of event to generate, or leave blank for random.
+//	            Valid values are: cifs, ems.
+//
+//	- generator:
+//	    type: netapp:ontap
+//	    event_type: cifs
+package ontap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "netapp:ontap"
+
+const (
+	EventTypeCIFS = "cifs"
+	EventTypeEMS  = "ems"
+)
+
+const (
+	providerName = "NetApp-Security-Auditing"
+	providerGUID = "{3CB2A168-FE19-4A4E-BDAD-DCF422F13473}"
+)
+
+var (
+	emsTemplate = "[{{.Node}}: {{.Process}}: {{.Name}}:{{.Severity}}]: {{.Message}}"
+	emsEvents   = [...]struct {
+		name     string
+		process  string
+		severity string
+		message  string
+	}{
+		{name: "wafl.vol.full", process: "wafl", severity: "alert", message: "Insufficient space on volume {{.Volume}}@vserver:{{.SVMUUID}} to perform operation. {{.Requested}}KB was requested but only {{.Available}}KB was available."},
+		{name: "wafl.vol.autoSize.done", process: "wafl", severity: "notice", message: "Volume autosize: Automatic grow of volume '{{.Volume}}@vserver:{{.SVMUUID}}' by {{.Requested}}KB complete."},
+		{name: "arw.volume.state", process: "mgwd", severity: "info", message: "Anti-ransomware state was changed to \"enabled\" on volume \"{{.Volume}}\" (UUID: \"{{.VolumeUUID}}\") in Vserver \"{{.SVM}}\" (UUID: \"{{.SVMUUID}}\")."},
+		{name: "callhome.arw.activity.seen", process: "mgwd", severity: "alert", message: "Call home for POSSIBLE RANSOMWARE ACTIVITY DETECTED."},
+		{name: "secd.cifsAuth.problem", process: "secd", severity: "error", message: "vserver ({{.SVM}}) General CIFS authentication problem. Error: User authentication procedure failed CIFS SMB2 Share mapping - Client Ip = {{.ClientAddr}}"},
+		{name: "secd.nfsAuth.noNameMap", process: "secd", severity: "error", message: "vserver ({{.SVM}}) Cannot map UNIX name to CIFS name. Error: Get user credentials procedure failed [  0 ms] Determined UNIX id {{.UID}} is UNIX user '{{.Username}}'"},
+		{name: "mgmtgwd.jobmgr.jobcomplete.failure", process: "mgwd", severity: "notice", message: "Job \"Vol Reaper\" [id {{.JobID}}] (Volume Reaper Job) completed unsuccessfully: Internal error. (1)."},
+		{name: "sshd.auth.loginDenied", process: "sshd", severity: "notice", message: "Login attempt from {{.ClientAddr}} to user {{.Username}} is denied."},
+		{name: "vifmgr.lifdown.noports", process: "vifmgr", severity: "error", message: "LIF {{.LIF}} (on virtual server {{.SVMID}}), IP address {{.LIFAddr}}, is not being hosted because the broadcast domain for this LIF has no ports."},
+	}
+	cifsEvents = [...]struct {
+		id       int
+		name     string
+		category string
+	}{
+		{id: 4624, name: "Logon", category: "logon"},
+		{id: 4625, name: "Logon Failure", category: "logon"},
+		{id: 4634, name: "Logoff", category: "logoff"},
+		{id: 4656, name: "Open Object", category: "object"},
+		{id: 4660, name: "Delete Object", category: "object"},
+		{id: 4663, name: "Get Object Attributes", category: "object"},
+		{id: 4670, name: "Object Permissions Changed", category: "object"},
+	}
+	accesses = [...]struct {
+		mask    string
+		list    string
+		desired string
+	}{
+		{mask: "1", list: "%%4416", desired: "Read Data;"},
+		{mask: "2", list: "%%4417", desired: "Write Data;"},
+		{mask: "80", list: "%%4423", desired: "Read Attributes;"},
+		{mask: "81", list: "%%4416 %%4423", desired: "Read Data; Read Attributes;"},
+		{mask: "10000", list: "%%1537", desired: "Delete;"},
+		{mask: "40000", list: "%%1539", desired: "Write DAC;"},
+	}
+	nodes      = [...]string{"cluster1-01", "cluster1-02", "cluster1-03", "cluster1-04"}
+	svms       = [...]string{"svm_cifs", "svm_nas", "svm_finance", "svm_eng"}
+	volumes    = [...]string{"vol_home", "vol_projects", "vol_finance", "vol_scratch", "vol_vdi"}
+	shares     = [...]string{"home", "projects", "finance", "scratch", "public"}
+	paths      = [...]string{"/", "/Budget/FY24.xlsx", "/hr/salaries.csv", "/src/main.go", "/Reports/Q3.pdf", "/users/desktop.ini", "/backup/db.bak"}
+	domains    = [...]string{"CORP", "ENG", "FINANCE"}
+	usernames  = [...]string{"jdoe", "asmith", "bwilson", "cjohnson", "svc_backup", "administrator"}
+	lifs       = [...]string{"lif_cifs_01", "lif_nfs_01", "lif_mgmt"}
+	logonTypes = [...]int{3, 3, 3, 10}
+)
+
+// Event holds the fields for a CIFS audit record.
+type Event struct {
+	XMLName xml.Name `xml:"Event"`
+
+	Provider     Provider    `xml:"System>Provider"`
+	EventID      int         `xml:"System>EventID"`
+	EventName    string      `xml:"System>EventName"`
+	Version      string      `xml:"System>Version"`
+	Source       string      `xml:"System>Source"`
+	Level        int         `xml:"System>Level"`
+	Opcode       int         `xml:"System>Opcode"`
+	Keywords     string      `xml:"System>Keywords"`
+	Result       string      `xml:"System>Result"`
+	TimeCreated  TimeCreated `xml:"System>TimeCreated"`
+	Channel      string      `xml:"System>Channel"`
+	Computer     string      `xml:"System>Computer"`
+	ComputerUUID string      `xml:"System>ComputerUUID"`
+
+	EventData []Data `xml:"EventData>Data"`
+}
+
+// Provider identifies the provider that logged the event.
+type Provider struct {
+	Name string `xml:"Name,attr"`
+	GUID string `xml:"Guid,attr"`
+}
+
+// TimeCreated contains the system time of when the event was logged.
+type TimeCreated struct {
+	SystemTime time.Time `xml:"SystemTime,attr"`
+}
+
+// Data is a named value in the event data.
+type Data struct {
+	Name      string `xml:"Name,attr"`
+	IPVersion string `xml:"IPVersion,attr,omitempty"`
+	Value     string `xml:",chardata"`
+}
+
+// Ontap holds the random fields for an ONTAP log message.
+type Ontap struct {
+	Available  int
+	ClientAddr net.IP
+	JobID      int
+	LIF        string
+	LIFAddr    net.IP
+	Message    string
+	Name       string
+	Node       string
+	Process    string
+	Requested  int
+	Severity   string
+	SVM        string
+	SVMID      int
+	SVMUUID    string
+	UID        int
+	Username   string
+	Volume     string
+	VolumeUUID string
+
+	Event Event
+
+	eventType  string
+	rand       *rand.Rand
+	pins       *generator.Pins
+	staticTime *time.Time
+	clock      *generator.Clock
+	ems        *template.Template
+	messages   []*template.Template
+	svmUUIDs   map[string]string
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Ontap objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Ontap{
+		eventType: c.EventType,
+		rand:      r,
+		clock:     clock,
+		svmUUIDs:  make(map[string]string),
+	}
+
+	o.pins, err = generator.NewPins(cfg, r, o)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := template.New(EventTypeEMS).Funcs(generator.FunctionMap).Parse(emsTemplate)
+	if err != nil {
+		return nil, err
+	}
+	o.ems = t
+
+	for _, v := range emsEvents {
+		t, err := template.New(v.name).Funcs(generator.FunctionMap).Parse(v.message)
+		if err != nil {
+			return nil, err
+		}
+		o.messages = append(o.messages, t)
+	}
+
+	for _, v := range svms {
+		o.svmUUIDs[v] = randomUUID(r)
+	}
+
+	return o, nil
+}
+
+// Next produces the next ONTAP log message.
+//
+// Example:
+//
+// [cluster1-01: wafl: wafl.vol.full:alert]: Insufficient space on volume vol_home@vserver:6c4a1a2e-1f7b-11ee-8d2a-005056b6f3c1 to perform operation. 4096KB was requested but only 12KB was available.
+func (o *Ontap) Next() ([]byte, error) {
+	eventType := o.eventType
+	if eventType == "" {
+		eventType = [...]string{EventTypeCIFS, EventTypeEMS}[o.rand.IntN(2)]
+	}
+
+	if eventType == EventTypeCIFS {
+		o.Event = o.randomizeCIFS()
+		o.pins.Apply(o)
+		return xml.Marshal(&o.Event)
+	}
+
+	var buf bytes.Buffer
+	i := o.randomizeEMS()
+	o.pins.Apply(o)
+	if err := o.messages[i].Execute(&buf, o); err != nil {
+		return nil, err
+	}
+	o.Message = buf.String()
+
+	buf.Reset()
+	if err := o.ems.Execute(&buf, o); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// randomizeEMS sets the fields for an EMS message and returns the
+// index of the message in emsEvents.
+func (o *Ontap) randomizeEMS() int {
+	i := o.rand.IntN(len(emsEvents))
+
+	o.Name = emsEvents[i].name
+	o.Process = emsEvents[i].process
+	o.Severity = emsEvents[i].severity
+	o.Node = nodes[o.rand.IntN(len(nodes))]
+	o.SVMID = o.rand.IntN(len(svms)) + 2
+	o.SVM = svms[o.SVMID-2]
+	o.SVMUUID = o.svmUUIDs[o.SVM]
+	o.Volume = volumes[o.rand.IntN(len(volumes))]
+	o.VolumeUUID = randomUUID(o.rand)
+	o.Requested = (o.rand.IntN(1024) + 1) * 4
+	o.Available = o.rand.IntN(64)
+	o.ClientAddr = random.IPv4(o.rand)
+	o.Username = usernames[o.rand.IntN(len(usernames))]
+	o.UID = o.rand.IntN(60000) + 1000
+	o.JobID = o.rand.IntN(10000)
+	o.LIF = lifs[o.rand.IntN(len(lifs))]
+	o.LIFAddr = net.IPv4(10, 10, byte(o.SVMID), byte(o.rand.IntN(254)+1))
+
+	return i
+}
+
+func (o *Ontap) randomizeCIFS() Event {
+	ev := cifsEvents[o.rand.IntN(len(cifsEvents))]
+	svm := svms[o.rand.IntN(len(svms))]
+	domain := domains[o.rand.IntN(len(domains))]
+	user := usernames[o.rand.IntN(len(usernames))]
+	sid := fmt.Sprintf("S-1-5-21-%d-%d-%d-%d", o.rand.Int32(), o.rand.Int32(), o.rand.Int32(), o.rand.IntN(10000)+1000)
+
+	e := Event{
+		Provider:     Provider{Name: providerName, GUID: providerGUID},
+		EventID:      ev.id,
+		EventName:    ev.name,
+		Version:      "101.3",
+		Source:       "CIFS",
+		Keywords:     "0x8020000000000000",
+		Result:       "Audit Success",
+		TimeCreated:  TimeCreated{SystemTime: o.getTime()},
+		Channel:      "Security",
+		Computer:     svm,
+		ComputerUUID: o.svmUUIDs[svm],
+	}
+	if ev.id == 4625 {
+		e.Keywords = "0x8010000000000000"
+		e.Result = "Audit Failure"
+	}
+
+	e.EventData = []Data{
+		{Name: "SubjectIP", IPVersion: "4", Value: random.IPv4(o.rand).String()},
+		{Name: "SubjectPort", Value: fmt.Sprint(random.Port(o.rand))},
+		{Name: "SubjectUserSid", Value: sid},
+		{Name: "SubjectUserIsLocal", Value: "false"},
+		{Name: "SubjectDomainName", Value: domain},
+		{Name: "SubjectUserName", Value: user},
+	}
+
+	switch ev.category {
+	case "logon":
+		e.EventData = append(e.EventData,
+			Data{Name: "LogonType", Value: fmt.Sprint(logonTypes[o.rand.IntN(len(logonTypes))])},
+			Data{Name: "AuthenticationPackageName", Value: [...]string{"NTLM_V2", "KRB5"}[o.rand.IntN(2)]},
+		)
+		if ev.id == 4625 {
+			e.EventData = append(e.EventData, Data{Name: "Status", Value: [...]string{"0xc000006d", "0xc0000064", "0xc0000234"}[o.rand.IntN(3)]})
+		}
+	case "logoff":
+		e.EventData = append(e.EventData, Data{Name: "LogonID", Value: fmt.Sprintf("0x%x", o.rand.Int64())})
+	case "object":
+		a := accesses[o.rand.IntN(len(accesses))]
+		objectType := "File"
+		path := paths[o.rand.IntN(len(paths))]
+		if path == "/" {
+			objectType = "Directory"
+		}
+		e.EventData = append(e.EventData,
+			Data{Name: "ObjectServer", Value: "Security"},
+			Data{Name: "ObjectType", Value: objectType},
+			Data{Name: "HandleID", Value: fmt.Sprintf("%020d;00;%08x;%08x", o.rand.IntN(100000), o.rand.IntN(1<<20), o.rand.Int32())},
+			Data{Name: "ObjectName", Value: fmt.Sprintf("(%s);%s", shares[o.rand.IntN(len(shares))], path)},
+			Data{Name: "AccessList", Value: a.list},
+			Data{Name: "AccessMask", Value: a.mask},
+			Data{Name: "DesiredAccess", Value: a.desired},
+			Data{Name: "Attributes", Value: "Open a non-directory;"},
+		)
+		if objectType == "Directory" {
+			e.EventData[len(e.EventData)-1].Value = "Open a directory;"
+		}
+	}
+
+	return e
+}
+
+func (o *Ontap) getTime() time.Time {
+	if o.staticTime != nil {
+		return *o.staticTime
+	}
+
+	return o.clock.Now()
+}
+
+func randomUUID(r *rand.Rand) string {
+	return fmt.Sprintf("%08x-%04x-11ee-%04x-%012x", r.Uint32(), r.IntN(1<<16), r.IntN(1<<16), r.Int64N(1<<48))
+}
+
+// Templates returns the templates of the generator, for generator.Lint.
+func (o *Ontap) Templates() (interface{}, []*template.Template) {
+	return o, append([]*template.Template{o.ems}, o.messages...)
+}
@@ -0,0 +1,34 @@
+package ontap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		eventType string
+		expected  string
+	}{
+		"CIFS": {eventType: EventTypeCIFS, expected: `<Event><System><Provider Name="NetApp-Security-Auditing" Guid="{3CB2A168-FE19-4A4E-BDAD-DCF422F13473}"></Provider><EventID>4663</EventID><EventName>Get Object Attributes</EventName><Version>101.3</Version><Source>CIFS</Source><Level>0</Level><Opcode>0</Opcode><Keywords>0x8020000000000000</Keywords><Result>Audit Success</Result><TimeCreated SystemTime="1970-01-02T03:04:05.123456Z"></TimeCreated><Channel>Security</Channel><Computer>svm_nas</Computer><ComputerUUID>bde2ffbd-d9a2-11ee-3398-02c38567d0bf</ComputerUUID></System><EventData><Data Name="SubjectIP" IPVersion="4">34.149.211.0</Data><Data Name="SubjectPort">25403</Data><Data Name="SubjectUserSid">S-1-5-21-701064986-417012891-432692235-4582</Data><Data Name="SubjectUserIsLocal">false</Data><Data Name="SubjectDomainName">CORP</Data><Data Name="SubjectUserName">svc_backup</Data><Data Name="ObjectServer">Security</Data><Data Name="ObjectType">File</Data><Data Name="HandleID">00000000000000007115;00;000d7e0b;62714fc1</Data><Data Name="ObjectName">(public);/Budget/FY24.xlsx</Data><Data Name="AccessList">%%4416</Data><Data Name="AccessMask">1</Data><Data Name="DesiredAccess">Read Data;</Data><Data Name="Attributes">Open a non-directory;</Data></EventData></Event>`},
+		"EMS":  {eventType: EventTypeEMS, expected: `[cluster1-02: sshd: sshd.auth.loginDenied:notice]: Login attempt from 144.11.198.10 to user asmith is denied.`},
+	}
+	testTime, err := time.Parse(time.RFC3339Nano, "1970-01-02T03:04:05.123456Z")
+	assert.Nil(t, err)
+	for name, tc := range tests {
+		random.Seed(1)
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "event_type": tc.eventType})
+		assert.Nil(t, err, name)
+		g, err := New(c)
+		assert.Nil(t, err, name)
+		o := g.(*Ontap)
+		o.staticTime = &testTime
+		got, err := o.Next()
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, string(got), name)
+	}
+}
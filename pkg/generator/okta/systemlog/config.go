@@ -0,0 +1,35 @@
+package systemlog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+type config struct {
+	Type      string             `config:"type" validate:"required"`
+	EventType string             `config:"event_type"`
+	Users     int                `config:"users"`
+	Churn     random.ChurnConfig `config:"churn"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:  Name,
+		Users: 50,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if !(c.EventType == "" || c.EventType == EventTypeSessionStart || c.EventType == EventTypeSSO || c.EventType == EventTypeSignOnPolicy) {
+		return fmt.Errorf("'%s' is not a valid value for 'event_type' expected '%s'", c.EventType, strings.Join([]string{EventTypeSessionStart, EventTypeSSO, EventTypeSignOnPolicy}, ", "))
+	}
+	if c.Users < 1 {
+		return fmt.Errorf("'%d' is not a valid value for 'users' expected a value greater than 0", c.Users)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package systemlog
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Event Type and Users": {
+			c:           map[string]interface{}{"type": Name, "event_type": EventTypeSSO, "users": 5},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'okta:systemlog' accessing config",
+		},
+		"Invalid Event Type": {
+			c:           map[string]interface{}{"type": Name, "event_type": "user.session.end"},
+			hasError:    true,
+			errorString: "'user.session.end' is not a valid value for 'event_type' expected 'user.session.start, user.authentication.sso, policy.evaluate_sign_on' accessing config",
+		},
+		"Invalid Users": {
+			c:           map[string]interface{}{"type": Name, "users": 0},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'users' expected a value greater than 0 accessing config",
+		},
+		"Invalid Churn": {
+			c:           map[string]interface{}{"type": Name, "churn": map[string]interface{}{"arrive": -2}},
+			hasError:    true,
+			errorString: "'-2' is not a valid value for 'arrive' expected a value of 0 or more accessing 'churn'",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			_, err = New(c)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
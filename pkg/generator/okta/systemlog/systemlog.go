@@ -0,0 +1,659 @@
+// Package systemlog generates Okta System Log events, in the JSON of
+// the System Log API.
+//
+// Events are generated for the sign ins of a pool of users.  A sign in
+// is the evaluation of the sign-on policy, the start of the Okta
+// session and single sign on to one or more apps.  Every user signs in
+// from their own address, location and browser, and the events of a
+// sign in share the transaction of the sign in or the Okta session.
+// Some sign ins are denied by the policy or fail with invalid
+// credentials.
+//
+// With churn the pool has joiners, movers and leavers over the run.
+// An administrator creates and activates the account of a joiner and
+// adds it to a group, moves a mover from their group to another, and
+// deactivates the account of a leaver, who no longer signs in.  The
+// rates are per hour of the event timestamps.  The lifecycle events
+// are only generated without an event_type.
+//
+// The brute_force and impossible_travel anomalies of
+// generator.Anomalies are logins of a user that fail from an address
+// abroad, and a login from abroad right after a login from home.  The
+// debug data of the anomalous events has the label of the anomaly in
+// "anomaly".
+//
+// Configuration:
+//
+//	event_type: Specify the event type to generate, or leave blank for
+//	            all.  Valid values are: user.session.start,
+//	            user.authentication.sso, policy.evaluate_sign_on.
+//	users:      The number of users in the pool, defaults to 50.
+//	entities:   Use the users of the shared population of package
+//	            entities as the pool instead, with their email
+//	            addresses as the Okta logins.  They mostly sign in
+//	            in their working hours.
+//	churn:      The joiners ("arrive"), leavers ("retire") and movers
+//	            ("reassign") per hour, all 0 by default.
+//	anomalies:  The anomalies to inject, see generator.Anomalies.
+//	severity:   The mix of the severities and the bursts of severe
+//	            events, see generator.Severities.  The severities are
+//	            ERROR, WARN, INFO and DEBUG.
+//
+//	- generator:
+//	    type: okta:systemlog
+//	    event_type: user.session.start
+//	    users: 200
+//
+//	- generator:
+//	    type: okta:systemlog
+//	    churn:
+//	      arrive: 3
+//	      retire: 1
+//	      reassign: 2
+//
+//	- generator:
+//	    type: okta:systemlog
+//	    anomalies:
+//	      - type: brute_force
+//	        rate: 0.5
+//	      - type: impossible_travel
+package systemlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/entities"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "okta:systemlog"
+
+const (
+	EventTypeSessionStart = "user.session.start"
+	EventTypeSSO          = "user.authentication.sso"
+	EventTypeSignOnPolicy = "policy.evaluate_sign_on"
+
+	EventTypeUserCreate     = "user.lifecycle.create"
+	EventTypeUserActivate   = "user.lifecycle.activate"
+	EventTypeUserDeactivate = "user.lifecycle.deactivate"
+	EventTypeGroupAdd       = "group.user_membership.add"
+	EventTypeGroupRemove    = "group.user_membership.remove"
+
+	publishedFmt = "2006-01-02T15:04:05.000Z"
+	idChars      = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+var (
+	firstNames = [...]string{"Alice", "Bob", "Carol", "David", "Erin", "Frank", "Grace", "Heidi", "Ivan", "Judy", "Mallory", "Niaj", "Olivia", "Peggy", "Rupert", "Sybil", "Trent", "Victor", "Walter", "Yvonne"}
+	lastNames  = [...]string{"Anderson", "Brown", "Chen", "Davis", "Evans", "Fischer", "Garcia", "Hughes", "Ito", "Jansen", "Kowalski", "Lopez", "Miller", "Novak", "Okafor", "Patel"}
+	locations  = [...]struct {
+		city, state, country, postalCode string
+		lat, lon                         float64
+		asNumber                         int
+		asOrg, isp, domain               string
+	}{
+		{"San Francisco", "California", "United States", "94107", 37.7697, -122.3933, 7922, "comcast", "comcast cable communications llc", "comcast.net"},
+		{"New York", "New York", "United States", "10001", 40.7484, -73.9967, 701, "verizon", "verizon business", "verizon.net"},
+		{"Austin", "Texas", "United States", "78701", 30.2711, -97.7437, 11427, "charter communications", "charter communications inc", "rr.com"},
+		{"Amsterdam", "North Holland", "Netherlands", "1012", 52.3730, 4.8924, 1136, "kpn b.v.", "kpn", "kpn.net"},
+		{"London", "England", "United Kingdom", "EC1A", 51.5164, -0.0930, 2856, "british telecommunications plc", "bt", "bt.com"},
+		{"Sydney", "New South Wales", "Australia", "2000", -33.8715, 151.2006, 1221, "telstra corporation ltd", "telstra", "bigpond.net.au"},
+	}
+	browsers = [...]struct {
+		raw, os, browser string
+		device           string
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0.0.0 Safari/537.36", "Windows 10", "CHROME", "Computer"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0.0.0 Safari/537.36", "Mac OS X", "CHROME", "Computer"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.3 Safari/605.1.15", "Mac OS X", "SAFARI", "Computer"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:123.0) Gecko/20100101 Firefox/123.0", "Windows 10", "FIREFOX", "Computer"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0.0.0 Safari/537.36 Edg/122.0.0.0", "Windows 10", "EDGE_CHROMIUM", "Computer"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_3 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.3 Mobile/15E148 Safari/604.1", "iOS", "SAFARI", "Mobile"},
+	}
+	// abroad are the addresses and locations of the logins of the
+	// anomalies, far from the locations of the users.
+	abroad = map[string]interface{}{
+		"Client.IPAddress":                       []interface{}{"105.112.20.14", "95.165.12.34", "177.71.200.5", "175.41.130.9"},
+		"Client.GeographicalContext.City":        []interface{}{"Lagos", "Moscow", "Sao Paulo", "Singapore"},
+		"Client.GeographicalContext.State":       []interface{}{"Lagos", "Moscow", "Sao Paulo", "Central Singapore"},
+		"Client.GeographicalContext.Country":     []interface{}{"Nigeria", "Russia", "Brazil", "Singapore"},
+		"Client.GeographicalContext.PostalCode":  []interface{}{"100001", "101000", "01000", "179094"},
+		"Client.GeographicalContext.Geolocation": map[string]interface{}{"Lat": []interface{}{6.4541, 55.7558, -23.5505, 1.2897}, "Lon": []interface{}{3.3947, 37.6173, -46.6333, 103.8501}},
+	}
+	anomalies = map[string]generator.AnomalyConfig{
+		generator.AnomalyBruteForce: {
+			Match: map[string]interface{}{"EventType": EventTypeSessionStart},
+			Hold:  []string{"Actor.ID", "Actor.AlternateID", "Actor.DisplayName"},
+			Set: merge(abroad, map[string]interface{}{
+				"LegacyEventType": "core.user_auth.login_failed",
+				"Outcome.Result":  "FAILURE",
+				"Outcome.Reason":  "INVALID_CREDENTIALS",
+				"Severity":        "WARN",
+			}),
+		},
+		generator.AnomalyImpossibleTravel: {
+			Match: map[string]interface{}{"EventType": EventTypeSessionStart},
+			Hold:  []string{"Actor.ID", "Actor.AlternateID", "Actor.DisplayName"},
+			Set:   abroad,
+		},
+	}
+	// severities are the severities of the severity option, from the
+	// most to the least severe.
+	severities = []string{"ERROR", "WARN", "INFO", "DEBUG"}
+	groups     = [...]string{"Engineering", "Sales", "Marketing", "Finance", "Human Resources", "Support", "Legal", "IT"}
+	apps       = [...]struct {
+		name, label string
+	}{
+		{"salesforce", "Salesforce.com"},
+		{"slack", "Slack"},
+		{"workday", "Workday"},
+		{"github_enterprise_cloud", "GitHub Enterprise Cloud"},
+		{"amazon_aws", "AWS Account Federation"},
+		{"zoomus", "Zoom"},
+		{"office365", "Microsoft Office 365"},
+	}
+)
+
+// Actor is the actor of an event.
+type Actor struct {
+	ID          string      `json:"id"`
+	Type        string      `json:"type"`
+	AlternateID string      `json:"alternateId"`
+	DisplayName string      `json:"displayName"`
+	DetailEntry interface{} `json:"detailEntry"`
+}
+
+// Target is an entity an event acts on.
+type Target struct {
+	ID          string      `json:"id"`
+	Type        string      `json:"type"`
+	AlternateID string      `json:"alternateId"`
+	DisplayName string      `json:"displayName"`
+	DetailEntry interface{} `json:"detailEntry"`
+}
+
+// UserAgent is the user agent of a client.
+type UserAgent struct {
+	RawUserAgent string `json:"rawUserAgent"`
+	OS           string `json:"os"`
+	Browser      string `json:"browser"`
+}
+
+// Geolocation is the latitude and longitude of a location.
+type Geolocation struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// GeographicalContext is the location of an address.
+type GeographicalContext struct {
+	City        string      `json:"city"`
+	State       string      `json:"state"`
+	Country     string      `json:"country"`
+	PostalCode  string      `json:"postalCode"`
+	Geolocation Geolocation `json:"geolocation"`
+}
+
+// Client is the client of the actor.
+type Client struct {
+	UserAgent           UserAgent           `json:"userAgent"`
+	Zone                string              `json:"zone"`
+	Device              string              `json:"device"`
+	ID                  *string             `json:"id"`
+	IPAddress           string              `json:"ipAddress"`
+	GeographicalContext GeographicalContext `json:"geographicalContext"`
+}
+
+// Outcome is the result of an event.
+type Outcome struct {
+	Result string  `json:"result"`
+	Reason *string `json:"reason"`
+}
+
+// Transaction is the request an event is part of.
+type Transaction struct {
+	Type   string                 `json:"type"`
+	ID     string                 `json:"id"`
+	Detail map[string]interface{} `json:"detail"`
+}
+
+// DebugContext holds the debug data of an event.
+type DebugContext struct {
+	DebugData map[string]string `json:"debugData"`
+}
+
+// AuthenticationContext is the authentication of the actor.
+type AuthenticationContext struct {
+	AuthenticationProvider *string `json:"authenticationProvider"`
+	CredentialProvider     *string `json:"credentialProvider"`
+	CredentialType         *string `json:"credentialType"`
+	Issuer                 *string `json:"issuer"`
+	Interface              *string `json:"interface"`
+	AuthenticationStep     int     `json:"authenticationStep"`
+	ExternalSessionID      string  `json:"externalSessionId"`
+}
+
+// SecurityContext is the network the client is on.
+type SecurityContext struct {
+	AsNumber int    `json:"asNumber"`
+	AsOrg    string `json:"asOrg"`
+	ISP      string `json:"isp"`
+	Domain   string `json:"domain"`
+	IsProxy  bool   `json:"isProxy"`
+}
+
+// IPChainEntry is an address of the request.
+type IPChainEntry struct {
+	IP                  string              `json:"ip"`
+	GeographicalContext GeographicalContext `json:"geographicalContext"`
+	Version             string              `json:"version"`
+	Source              *string             `json:"source"`
+}
+
+// Request is the request of an event.
+type Request struct {
+	IPChain []IPChainEntry `json:"ipChain"`
+}
+
+// Event is a System Log event.
+type Event struct {
+	Actor                 Actor                 `json:"actor"`
+	Client                Client                `json:"client"`
+	AuthenticationContext AuthenticationContext `json:"authenticationContext"`
+	DisplayMessage        string                `json:"displayMessage"`
+	EventType             string                `json:"eventType"`
+	Outcome               Outcome               `json:"outcome"`
+	Published             string                `json:"published"`
+	SecurityContext       SecurityContext       `json:"securityContext"`
+	Severity              string                `json:"severity"`
+	DebugContext          DebugContext          `json:"debugContext"`
+	LegacyEventType       *string               `json:"legacyEventType"`
+	Transaction           Transaction           `json:"transaction"`
+	UUID                  string                `json:"uuid"`
+	Version               string                `json:"version"`
+	Request               Request               `json:"request"`
+	Target                []Target              `json:"target"`
+}
+
+// user is a user of the pool.
+type user struct {
+	actor    Actor
+	ip       net.IP
+	location int
+	browser  int
+	group    int
+	entity   *entities.User
+}
+
+// SystemLog holds the state of the Okta System Log generator.
+type SystemLog struct {
+	Event Event
+
+	rand       *rand.Rand
+	clock      *generator.Clock
+	pins       *generator.Pins
+	eventType  string
+	users      []user
+	logins     map[string]bool
+	joined     int
+	admin      user
+	groupIDs   []string
+	churn      *random.Churn
+	anoms      *generator.Anomalies
+	severity   *generator.Severities
+	entities   *entities.Population
+	appIDs     []string
+	policyID   string
+	ruleID     string
+	pending    []Event
+	staticTime *time.Time
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for SystemLog objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SystemLog{
+		rand:      r,
+		clock:     clock,
+		eventType: c.EventType,
+		logins:    map[string]bool{},
+	}
+
+	s.pins, err = generator.NewPins(cfg, r, &s.Event)
+	if err != nil {
+		return nil, err
+	}
+	s.anoms, err = generator.NewAnomalies(cfg, r, &s.Event, anomalies)
+	if err != nil {
+		return nil, err
+	}
+	s.severity, err = generator.NewSeverities(cfg, r, &s.Event, "Severity", severities)
+	if err != nil {
+		return nil, err
+	}
+
+	pop, err := entities.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if pop != nil {
+		s.entities = pop
+		for i, u := range pop.Users {
+			s.logins[u.Name] = true
+			su := s.user(u.Email, u.DisplayName)
+			su.entity = &pop.Users[i]
+			s.users = append(s.users, su)
+		}
+	} else {
+		for i := 0; i < c.Users; i++ {
+			s.users = append(s.users, s.newUser())
+		}
+	}
+	for range apps {
+		s.appIDs = append(s.appIDs, s.id("0oa"))
+	}
+	s.policyID = s.id("00p")
+	s.ruleID = s.id("0pr")
+
+	if s.churn = random.NewChurn(r, c.Churn); s.churn != nil {
+		s.admin = s.newUser()
+		s.admin.actor.AlternateID = "okta.admin@example.com"
+		s.admin.actor.DisplayName = "Okta Admin"
+		for range groups {
+			s.groupIDs = append(s.groupIDs, s.id("00g"))
+		}
+	}
+
+	return s, nil
+}
+
+// newUser returns a new user of the pool, with a login of their own.
+func (s *SystemLog) newUser() user {
+	r := s.rand
+	first := firstNames[r.IntN(len(firstNames))]
+	last := lastNames[r.IntN(len(lastNames))]
+	login := strings.ToLower(fmt.Sprintf("%s.%s", first, last))
+	if s.logins[login] {
+		login += fmt.Sprint(s.joined)
+	}
+	s.logins[login] = true
+	s.joined++
+	return s.user(login+"@example.com", first+" "+last)
+}
+
+// user returns a user of the pool with the login and name.
+func (s *SystemLog) user(login, name string) user {
+	r := s.rand
+	return user{
+		actor: Actor{
+			ID:          s.id("00u"),
+			Type:        "User",
+			AlternateID: login,
+			DisplayName: name,
+		},
+		ip:       random.IPv4(r),
+		location: r.IntN(len(locations)),
+		browser:  r.IntN(len(browsers)),
+		group:    r.IntN(len(groups)),
+	}
+}
+
+// Next produces the next System Log event.
+//
+// Example:
+//
+// {"actor":{"id":"00uVhm7hTdMuOWka0tBt","type":"User","alternateId":"yvonne.anderson@example.com","displayName":"Yvonne Anderson","detailEntry":null},"client":{...},"displayMessage":"User login to Okta","eventType":"user.session.start","outcome":{"result":"SUCCESS","reason":null},...}
+func (s *SystemLog) Next() ([]byte, error) {
+	for len(s.pending) == 0 {
+		s.signIn()
+	}
+	s.Event = s.pending[0]
+	s.pending = s.pending[1:]
+	published, _ := time.Parse(publishedFmt, s.Event.Published)
+	s.severity.Apply(&s.Event, published)
+	s.pins.Apply(&s.Event)
+	s.anoms.Apply(&s.Event, published)
+	if label := s.anoms.Label(); label != "" {
+		s.Event.DebugContext.DebugData["anomaly"] = label
+	}
+
+	return json.Marshal(&s.Event)
+}
+
+// signIn queues the events of a sign in of a random user.
+func (s *SystemLog) signIn() {
+	now := s.clock.Now()
+	if s.staticTime != nil {
+		now = *s.staticTime
+	}
+	s.lifecycle(now)
+	u := s.pick(now)
+	tx := s.token(27)
+	session := "102" + s.token(22)
+
+	policy := s.event(u, EventTypeSignOnPolicy, "Evaluation of sign-on policy", now, tx, session)
+	policy.LegacyEventType = nil
+	policy.DebugContext.DebugData["requestUri"] = "/idp/idx/identify"
+	policy.DebugContext.DebugData["url"] = "/idp/idx/identify?"
+	policy.DebugContext.DebugData["risk"] = "{level=LOW}"
+	policy.Target = []Target{
+		{ID: s.policyID, Type: "PolicyEntity", AlternateID: "unknown", DisplayName: "Default Policy"},
+		{ID: s.ruleID, Type: "PolicyRule", AlternateID: s.policyID, DisplayName: "Default Rule"},
+	}
+	allow := s.rand.IntN(20) != 0
+	if allow {
+		policy.Outcome = Outcome{Result: "ALLOW", Reason: str("Sign-on policy evaluation resulted in ALLOW")}
+	} else {
+		policy.Outcome = Outcome{Result: "DENY", Reason: str("Sign-on policy evaluation resulted in DENY")}
+		policy.Severity = "WARN"
+	}
+	s.queue(policy)
+	if !allow {
+		return
+	}
+
+	now = now.Add(time.Duration(s.rand.IntN(3000)+500) * time.Millisecond)
+	start := s.event(u, EventTypeSessionStart, "User login to Okta", now, tx, session)
+	start.AuthenticationContext.CredentialType = str("PASSWORD")
+	start.DebugContext.DebugData["requestUri"] = "/idp/idx/authenticators/poll"
+	start.DebugContext.DebugData["url"] = "/idp/idx/authenticators/poll?"
+	if s.rand.IntN(10) == 0 {
+		start.LegacyEventType = str("core.user_auth.login_failed")
+		start.Outcome = Outcome{Result: "FAILURE", Reason: str("INVALID_CREDENTIALS")}
+		start.Severity = "WARN"
+		s.queue(start)
+		return
+	}
+	start.LegacyEventType = str("core.user_auth.login_success")
+	s.queue(start)
+
+	for i := s.rand.IntN(3) + 1; i > 0; i-- {
+		now = now.Add(time.Duration(s.rand.IntN(60000)+200) * time.Millisecond)
+		app := s.rand.IntN(len(apps))
+		sso := s.event(u, EventTypeSSO, "User single sign on to app", now, s.token(27), session)
+		sso.LegacyEventType = str("app.auth.sso")
+		sso.DebugContext.DebugData["requestUri"] = fmt.Sprintf("/app/%s/%s/sso/saml", apps[app].name, s.appIDs[app])
+		sso.DebugContext.DebugData["url"] = sso.DebugContext.DebugData["requestUri"] + "?"
+		sso.Target = []Target{
+			{ID: s.appIDs[app], Type: "AppInstance", AlternateID: apps[app].label, DisplayName: apps[app].label},
+			{ID: s.id("0ua"), Type: "AppUser", AlternateID: u.actor.AlternateID, DisplayName: u.actor.DisplayName},
+		}
+		s.queue(sso)
+	}
+}
+
+// pick returns a random user of the pool.  The users of the shared
+// population are mostly picked in their working hours.
+func (s *SystemLog) pick(now time.Time) user {
+	u := s.users[s.rand.IntN(len(s.users))]
+	for try := 1; u.entity != nil && try < 100 && !s.entities.Acts(s.rand, *u.entity, now); try++ {
+		u = s.users[s.rand.IntN(len(s.users))]
+	}
+	return u
+}
+
+// lifecycle queues the events of the joiners, movers and leavers of the
+// churn of the pool up to now.  The pool always keeps one user.
+func (s *SystemLog) lifecycle(now time.Time) {
+	arrive, retire, reassign := s.churn.Changes(now)
+	for ; arrive > 0; arrive-- {
+		u := s.newUser()
+		s.users = append(s.users, u)
+		s.administer(u, EventTypeUserCreate, "Create Okta user", now, "/api/v1/users", nil)
+		s.administer(u, EventTypeUserActivate, "Activate Okta user", now, "/api/v1/users/"+u.actor.ID+"/lifecycle/activate", nil)
+		s.administer(u, EventTypeGroupAdd, "Add user to group membership", now, s.membershipURI(u), s.groupTarget(u.group))
+	}
+	for ; reassign > 0; reassign-- {
+		i := s.rand.IntN(len(s.users))
+		u := &s.users[i]
+		s.administer(*u, EventTypeGroupRemove, "Remove user from group membership", now, s.membershipURI(*u), s.groupTarget(u.group))
+		u.group = (u.group + 1 + s.rand.IntN(len(groups)-1)) % len(groups)
+		s.administer(*u, EventTypeGroupAdd, "Add user to group membership", now, s.membershipURI(*u), s.groupTarget(u.group))
+	}
+	for ; retire > 0 && len(s.users) > 1; retire-- {
+		i := s.rand.IntN(len(s.users))
+		u := s.users[i]
+		s.users = append(s.users[:i], s.users[i+1:]...)
+		s.administer(u, EventTypeUserDeactivate, "Deactivate Okta user", now, "/api/v1/users/"+u.actor.ID+"/lifecycle/deactivate", nil)
+	}
+}
+
+// administer queues the event of the administrator acting on the user
+// u at the request uri, with the target of u and any other targets.
+func (s *SystemLog) administer(u user, eventType, message string, now time.Time, uri string, targets []Target) {
+	tx := s.token(27)
+	e := s.event(s.admin, eventType, message, now, tx, "102"+s.token(22))
+	e.DebugContext.DebugData["requestUri"] = uri
+	e.DebugContext.DebugData["url"] = uri + "?"
+	e.Target = append([]Target{{ID: u.actor.ID, Type: "User", AlternateID: u.actor.AlternateID, DisplayName: u.actor.DisplayName}}, targets...)
+	s.queue(e)
+}
+
+// membershipURI returns the request uri of a change of the group
+// membership of u.
+func (s *SystemLog) membershipURI(u user) string {
+	return "/api/v1/groups/" + s.groupIDs[u.group] + "/users/" + u.actor.ID
+}
+
+// groupTarget returns the target of the group g.
+func (s *SystemLog) groupTarget(g int) []Target {
+	return []Target{{ID: s.groupIDs[g], Type: "UserGroup", AlternateID: "unknown", DisplayName: groups[g]}}
+}
+
+// event returns an event of u, with the fields that are the same for
+// every event type.
+func (s *SystemLog) event(u user, eventType, message string, published time.Time, tx, session string) Event {
+	loc := locations[u.location]
+	b := browsers[u.browser]
+	geo := GeographicalContext{
+		City:        loc.city,
+		State:       loc.state,
+		Country:     loc.country,
+		PostalCode:  loc.postalCode,
+		Geolocation: Geolocation{Lat: loc.lat, Lon: loc.lon},
+	}
+	return Event{
+		Actor: u.actor,
+		Client: Client{
+			UserAgent:           UserAgent{RawUserAgent: b.raw, OS: b.os, Browser: b.browser},
+			Zone:                "null",
+			Device:              b.device,
+			IPAddress:           u.ip.String(),
+			GeographicalContext: geo,
+		},
+		AuthenticationContext: AuthenticationContext{ExternalSessionID: session},
+		DisplayMessage:        message,
+		EventType:             eventType,
+		Outcome:               Outcome{Result: "SUCCESS"},
+		Published:             s.clock.Format(published.UTC(), publishedFmt),
+		SecurityContext: SecurityContext{
+			AsNumber: loc.asNumber,
+			AsOrg:    loc.asOrg,
+			ISP:      loc.isp,
+			Domain:   loc.domain,
+		},
+		Severity: "INFO",
+		DebugContext: DebugContext{DebugData: map[string]string{
+			"requestId":       tx,
+			"threatSuspected": "false",
+		}},
+		Transaction: Transaction{Type: "WEB", ID: tx, Detail: map[string]interface{}{}},
+		UUID:        s.uuid(),
+		Version:     "0",
+		Request: Request{IPChain: []IPChainEntry{
+			{IP: u.ip.String(), GeographicalContext: geo, Version: "V4"},
+		}},
+	}
+}
+
+// merge returns the fields of a and b.
+func merge(a, b map[string]interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, len(a)+len(b))
+	for k, v := range a {
+		m[k] = v
+	}
+	for k, v := range b {
+		m[k] = v
+	}
+	return m
+}
+
+// queue queues e if it is of the configured event type.
+func (s *SystemLog) queue(e Event) {
+	if s.eventType != "" && s.eventType != e.EventType {
+		return
+	}
+	s.pending = append(s.pending, e)
+}
+
+// id returns an Okta id with the prefix of the object type, which is
+// 20 characters long.
+func (s *SystemLog) id(prefix string) string {
+	return prefix + s.token(20-len(prefix))
+}
+
+// token returns n random characters of an id.
+func (s *SystemLog) token(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = idChars[s.rand.IntN(len(idChars))]
+	}
+	return string(b)
+}
+
+func (s *SystemLog) uuid() string {
+	b := make([]byte, 16)
+	random.Read(s.rand, b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func str(s string) *string {
+	return &s
+}
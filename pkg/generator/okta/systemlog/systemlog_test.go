@@ -0,0 +1,161 @@
+package systemlog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	random.Seed(1)
+	testTime, err := time.Parse(time.RFC3339, "2024-03-04T12:00:00Z")
+	assert.Nil(t, err)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	g.(*SystemLog).staticTime = &testTime
+
+	seen := map[string]int{}
+	sessions := map[string]Event{}
+	for i := 0; i < 1000; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		var e Event
+		if !assert.Nil(t, json.Unmarshal(got, &e), string(got)) {
+			continue
+		}
+		seen[e.EventType]++
+		assert.Equal(t, "0", e.Version)
+		assert.Equal(t, "User", e.Actor.Type)
+		assert.Equal(t, e.Client.IPAddress, e.Request.IPChain[0].IP)
+		_, err = time.Parse(time.RFC3339, e.Published)
+		assert.Nil(t, err)
+
+		switch e.EventType {
+		case EventTypeSessionStart:
+			if e.Outcome.Result == "SUCCESS" {
+				sessions[e.AuthenticationContext.ExternalSessionID] = e
+			}
+		case EventTypeSSO:
+			start, ok := sessions[e.AuthenticationContext.ExternalSessionID]
+			if !assert.True(t, ok, "sso without session start") {
+				continue
+			}
+			assert.Equal(t, start.Actor, e.Actor)
+			assert.Equal(t, start.Client, e.Client)
+			assert.Equal(t, "AppInstance", e.Target[0].Type)
+		}
+	}
+	assert.Greater(t, seen[EventTypeSessionStart], 0)
+	assert.Greater(t, seen[EventTypeSSO], 0)
+	assert.Greater(t, seen[EventTypeSignOnPolicy], 0)
+}
+
+func TestEventType(t *testing.T) {
+	for _, eventType := range []string{EventTypeSessionStart, EventTypeSSO, EventTypeSignOnPolicy} {
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "event_type": eventType})
+		assert.Nil(t, err)
+		g, err := New(c)
+		assert.Nil(t, err)
+		for i := 0; i < 100; i++ {
+			got, err := g.Next()
+			assert.Nil(t, err)
+			var e Event
+			assert.Nil(t, json.Unmarshal(got, &e))
+			assert.Equal(t, eventType, e.EventType)
+		}
+	}
+}
+
+func TestChurn(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{
+		"type":      Name,
+		"seed":      1,
+		"users":     20,
+		"timestamp": map[string]interface{}{"start": "2024-03-04T00:00:00Z", "interval": "1m"},
+		"churn":     map[string]interface{}{"arrive": 5, "retire": 5, "reassign": 5},
+	})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+
+	seen := map[string]int{}
+	left := map[string]bool{}
+	for i := 0; i < 5000; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		var e Event
+		if !assert.Nil(t, json.Unmarshal(got, &e), string(got)) {
+			continue
+		}
+		seen[e.EventType]++
+		switch e.EventType {
+		case EventTypeUserCreate, EventTypeUserActivate, EventTypeUserDeactivate:
+			assert.Equal(t, "okta.admin@example.com", e.Actor.AlternateID)
+			assert.Len(t, e.Target, 1)
+			assert.Equal(t, "User", e.Target[0].Type)
+			if e.EventType == EventTypeUserDeactivate {
+				left[e.Target[0].AlternateID] = true
+			}
+		case EventTypeGroupAdd, EventTypeGroupRemove:
+			assert.Len(t, e.Target, 2)
+			assert.Equal(t, "UserGroup", e.Target[1].Type)
+		default:
+			assert.False(t, left[e.Actor.AlternateID], "sign in of a leaver")
+		}
+	}
+	assert.Greater(t, seen[EventTypeUserCreate], 0)
+	assert.Equal(t, seen[EventTypeUserCreate], seen[EventTypeUserActivate])
+	assert.Greater(t, seen[EventTypeUserDeactivate], 0)
+	assert.Greater(t, seen[EventTypeGroupRemove], 0)
+	assert.Equal(t, seen[EventTypeUserCreate]+seen[EventTypeGroupRemove], seen[EventTypeGroupAdd])
+}
+
+func TestAnomalies(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{
+		"type":      Name,
+		"seed":      1,
+		"timestamp": map[string]interface{}{"start": "2024-03-04T00:00:00Z", "interval": "1m"},
+		"anomalies": []interface{}{
+			map[string]interface{}{"type": "brute_force", "rate": 1, "events": 10},
+			map[string]interface{}{"type": "impossible_travel", "rate": 1},
+		},
+	})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+
+	seen := map[string]int{}
+	last := ""
+	for i := 0; i < 5000; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		var e Event
+		if !assert.Nil(t, json.Unmarshal(got, &e), string(got)) {
+			continue
+		}
+		label, ok := e.DebugContext.DebugData["anomaly"]
+		if !ok {
+			if e.EventType == EventTypeSessionStart {
+				last = e.Actor.AlternateID
+			}
+			continue
+		}
+		seen[label]++
+		assert.Equal(t, EventTypeSessionStart, e.EventType)
+		assert.Equal(t, last, e.Actor.AlternateID)
+		assert.Contains(t, []string{"Nigeria", "Russia", "Brazil", "Singapore"}, e.Client.GeographicalContext.Country)
+		if label == "brute_force" {
+			assert.Equal(t, "FAILURE", e.Outcome.Result)
+			assert.Equal(t, "INVALID_CREDENTIALS", *e.Outcome.Reason)
+		}
+	}
+	assert.Greater(t, seen["brute_force"], 0)
+	assert.Equal(t, 0, seen["brute_force"]%10)
+	assert.Greater(t, seen["impossible_travel"], 0)
+}
@@ -0,0 +1,297 @@
+// Package audit generates Oracle Database unified audit records.
+//
+// Two formats are generated.  Syslog records are the messages the
+// database writes when UNIFIED_AUDIT_SYSTEMLOG is set, for example
+// "Oracle Unified Audit[4711]: LENGTH: '...' TYPE:\"4\" ... ACTION:\"100\" RETCODE:\"0\" ...".
+// XML records contain the columns of the UNIFIED_AUDIT_TRAIL view,
+// including the action name and the SQL text of the audited
+// statement.  Syslog records do not carry SQL text, matching what the
+// database writes.
+//
+// Configuration:
+//
+//	format: Specify the format of records to generate, or leave blank for random.
+//	        Valid values are: syslog, xml.
+//	sql_text_length: Maximum length of the SQL text in XML records, longer
+//	                 statements are truncated.  0 omits the SQL text.  Defaults to 256.
+//
+//	- generator:
+//	    type: oracle:audit
+//	    format: xml
+//	    sql_text_length: 64
+package audit
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "oracle:audit"
+
+const (
+	FormatSyslog = "syslog"
+	FormatXML    = "xml"
+)
+
+var (
+	syslogTemplate = "Oracle Unified Audit[{{.Record.OSProcess}}]: LENGTH: '{{.Length}}' {{.Body}}"
+	bodyTemplate   = "TYPE:\"4\" DBID:\"{{.Record.DBID}}\" SESID:\"{{.Record.SessionID}}\" CLIENTID:\"\" ENTRYID:\"{{.Record.EntryID}}\" STMTID:\"{{.Record.StatementID}}\" DBUSER:\"{{.Record.DBUsername}}\" CURUSER:\"{{.Record.CurrentUser}}\" ACTION:\"{{.Action}}\" RETCODE:\"{{.Record.ReturnCode}}\" SCHEMA:\"{{.Record.ObjectSchema}}\" OBJNAME:\"{{.Record.ObjectName}}\" PDB_GUID:\"{{.PDBGUID}}\""
+
+	actions = [...]struct {
+		code   int
+		name   string
+		object bool
+		sql    string
+	}{
+		{code: 100, name: "LOGON"},
+		{code: 101, name: "LOGOFF"},
+		{code: 3, name: "SELECT", object: true, sql: "SELECT {{cols}} FROM {{obj}} WHERE {{pred}}"},
+		{code: 2, name: "INSERT", object: true, sql: "INSERT INTO {{obj}} ({{cols}}) VALUES ({{binds}})"},
+		{code: 6, name: "UPDATE", object: true, sql: "UPDATE {{obj}} SET {{set}} WHERE {{pred}}"},
+		{code: 7, name: "DELETE", object: true, sql: "DELETE FROM {{obj}} WHERE {{pred}}"},
+		{code: 1, name: "CREATE TABLE", object: true, sql: "CREATE TABLE {{obj}} (ID NUMBER PRIMARY KEY, {{cols}} VARCHAR2(100))"},
+		{code: 12, name: "DROP TABLE", object: true, sql: "DROP TABLE {{obj}} PURGE"},
+		{code: 17, name: "GRANT", object: true, sql: "GRANT SELECT, UPDATE ON {{obj}} TO {{grantee}}"},
+		{code: 43, name: "ALTER USER", sql: "ALTER USER {{grantee}} IDENTIFIED BY *****"},
+		{code: 47, name: "EXECUTE", object: true, sql: "BEGIN {{obj}}(:1, :2); END;"},
+	}
+	returnCodes = map[string][]int{
+		"LOGON": {0, 0, 0, 0, 1017, 28000},
+		"":      {0, 0, 0, 0, 0, 0, 0, 942, 1031},
+	}
+	objects = [...]struct {
+		schema string
+		name   string
+		cols   []string
+	}{
+		{schema: "HR", name: "EMPLOYEES", cols: []string{"EMPLOYEE_ID", "FIRST_NAME", "LAST_NAME", "EMAIL", "SALARY", "MANAGER_ID"}},
+		{schema: "HR", name: "DEPARTMENTS", cols: []string{"DEPARTMENT_ID", "DEPARTMENT_NAME", "LOCATION_ID"}},
+		{schema: "SALES", name: "ORDERS", cols: []string{"ORDER_ID", "CUSTOMER_ID", "ORDER_DATE", "ORDER_TOTAL", "STATUS"}},
+		{schema: "SALES", name: "CUSTOMERS", cols: []string{"CUSTOMER_ID", "NAME", "CREDIT_LIMIT", "PHONE"}},
+		{schema: "FIN", name: "LEDGER", cols: []string{"ENTRY_ID", "ACCOUNT", "AMOUNT", "POSTED_AT"}},
+		{schema: "APP", name: "PKG_BILLING.RUN_INVOICES", cols: []string{"BATCH_ID"}},
+	}
+	dbUsers   = [...]string{"SYS", "SYSTEM", "HR", "SALES_APP", "REPORTING", "C##DBA_OPS", "JDOE"}
+	osUsers   = [...]string{"oracle", "appsvc", "jdoe", "batch"}
+	userhosts = [...]string{"app01.corp.example.com", "app02.corp.example.com", "bastion01", "WORKSTATION-17"}
+	terminals = [...]string{"pts/0", "pts/1", "unknown", "WORKSTATION-17"}
+	programs  = [...]string{"sqlplus@app01 (TNS V1-V3)", "JDBC Thin Client", "SQL Developer", "python3@batch01 (TNS V1-V3)"}
+	policies  = [...]string{"ORA_LOGON_FAILURES", "ORA_SECURECONFIG", "ORA_ACCOUNT_MGMT", "HR_TABLE_POLICY"}
+)
+
+// Record holds the columns of a unified audit trail record.
+type Record struct {
+	XMLName xml.Name `xml:"AuditRecord"`
+
+	AuditType          string    `xml:"AUDIT_TYPE"`
+	SessionID          int64     `xml:"SESSIONID"`
+	OSUsername         string    `xml:"OS_USERNAME"`
+	Userhost           string    `xml:"USERHOST"`
+	Terminal           string    `xml:"TERMINAL"`
+	DBID               int64     `xml:"DBID"`
+	DBUsername         string    `xml:"DBUSERNAME"`
+	CurrentUser        string    `xml:"CURRENT_USER"`
+	ClientProgramName  string    `xml:"CLIENT_PROGRAM_NAME"`
+	EventTimestampUTC  time.Time `xml:"EVENT_TIMESTAMP_UTC"`
+	EntryID            int       `xml:"ENTRY_ID"`
+	StatementID        int       `xml:"STATEMENT_ID"`
+	ActionName         string    `xml:"ACTION_NAME"`
+	ReturnCode         int       `xml:"RETURN_CODE"`
+	ObjectSchema       string    `xml:"OBJECT_SCHEMA"`
+	ObjectName         string    `xml:"OBJECT_NAME"`
+	SQLText            string    `xml:"SQL_TEXT,omitempty"`
+	UnifiedAuditPolicy string    `xml:"UNIFIED_AUDIT_POLICIES"`
+	OSProcess          int       `xml:"OS_PROCESS"`
+}
+
+// Audit holds the random fields for an Oracle unified audit record.
+type Audit struct {
+	Action  int
+	Body    string
+	Length  int
+	PDBGUID string
+	Record  Record
+
+	format        string
+	sqlTextLength int
+	dbid          int64
+	rand          *rand.Rand
+	pins          *generator.Pins
+	staticTime    *time.Time
+	clock         *generator.Clock
+	syslog        *template.Template
+	body          *template.Template
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Audit objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Audit{
+		format:        c.Format,
+		sqlTextLength: c.SQLTextLength,
+		dbid:          r.Int64N(1 << 32),
+		PDBGUID:       fmt.Sprintf("%016X%016X", r.Uint64(), r.Uint64()),
+		rand:          r,
+		clock:         clock,
+	}
+
+	a.pins, err = generator.NewPins(cfg, r, a)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := template.New(FormatSyslog).Funcs(generator.FunctionMap).Parse(syslogTemplate)
+	if err != nil {
+		return nil, err
+	}
+	a.syslog = t
+
+	t, err = template.New("body").Funcs(generator.FunctionMap).Parse(bodyTemplate)
+	if err != nil {
+		return nil, err
+	}
+	a.body = t
+
+	return a, nil
+}
+
+// Next produces the next Oracle unified audit record.
+//
+// Example:
+//
+// Oracle Unified Audit[4711]: LENGTH: '275' TYPE:"4" DBID:"1639807207" SESID:"2187491150" CLIENTID:"" ENTRYID:"1" STMTID:"1" DBUSER:"HR" CURUSER:"HR" ACTION:"3" RETCODE:"0" SCHEMA:"HR" OBJNAME:"EMPLOYEES" PDB_GUID:"..."
+func (a *Audit) Next() ([]byte, error) {
+	format := a.format
+	if format == "" {
+		format = [...]string{FormatSyslog, FormatXML}[a.rand.IntN(2)]
+	}
+
+	a.randomize()
+
+	if format == FormatXML {
+		return xml.Marshal(&a.Record)
+	}
+
+	var buf bytes.Buffer
+	if err := a.body.Execute(&buf, a); err != nil {
+		return nil, err
+	}
+	a.Body = buf.String()
+	a.Length = len(a.Body)
+
+	buf.Reset()
+	if err := a.syslog.Execute(&buf, a); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (a *Audit) randomize() {
+	act := actions[a.rand.IntN(len(actions))]
+	obj := objects[a.rand.IntN(len(objects))]
+	user := dbUsers[a.rand.IntN(len(dbUsers))]
+
+	codes := returnCodes[""]
+	if act.name == "LOGON" {
+		codes = returnCodes[act.name]
+	}
+
+	a.Action = act.code
+	a.Record = Record{
+		AuditType:          "Standard",
+		SessionID:          a.rand.Int64N(1 << 32),
+		OSUsername:         osUsers[a.rand.IntN(len(osUsers))],
+		Userhost:           userhosts[a.rand.IntN(len(userhosts))],
+		Terminal:           terminals[a.rand.IntN(len(terminals))],
+		DBID:               a.dbid,
+		DBUsername:         user,
+		CurrentUser:        user,
+		ClientProgramName:  programs[a.rand.IntN(len(programs))],
+		EventTimestampUTC:  a.getTime().UTC(),
+		EntryID:            a.rand.IntN(100) + 1,
+		StatementID:        a.rand.IntN(1000) + 1,
+		ActionName:         act.name,
+		ReturnCode:         codes[a.rand.IntN(len(codes))],
+		UnifiedAuditPolicy: policies[a.rand.IntN(len(policies))],
+		OSProcess:          a.rand.IntN(65536),
+	}
+	if act.object {
+		a.Record.ObjectSchema = obj.schema
+		a.Record.ObjectName = obj.name
+	}
+	if act.sql != "" && a.sqlTextLength > 0 {
+		a.Record.SQLText = truncate(sqlText(a.rand, act.sql, obj.schema+"."+obj.name, obj.cols), a.sqlTextLength)
+	}
+
+	a.pins.Apply(a)
+}
+
+// sqlText expands the placeholders in an action's SQL statement.
+func sqlText(r *rand.Rand, stmt, obj string, cols []string) string {
+	n := r.IntN(len(cols)) + 1
+	used := cols[:n]
+
+	binds := make([]string, n)
+	set := make([]string, n)
+	for i, c := range used {
+		binds[i] = fmt.Sprintf(":%d", i+1)
+		set[i] = fmt.Sprintf("%s = :%d", c, i+1)
+	}
+
+	rep := strings.NewReplacer(
+		"{{obj}}", obj,
+		"{{cols}}", strings.Join(used, ", "),
+		"{{binds}}", strings.Join(binds, ", "),
+		"{{set}}", strings.Join(set, ", "),
+		"{{pred}}", fmt.Sprintf("%s = %d", cols[0], r.IntN(100000)),
+		"{{grantee}}", dbUsers[r.IntN(len(dbUsers))],
+	)
+	return rep.Replace(stmt)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+func (a *Audit) getTime() time.Time {
+	if a.staticTime != nil {
+		return *a.staticTime
+	}
+
+	return a.clock.Now()
+}
+
+// Templates returns the templates of the generator, for generator.Lint.
+func (a *Audit) Templates() (interface{}, []*template.Template) {
+	return a, []*template.Template{a.syslog, a.body}
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		format        string
+		sqlTextLength int
+		expected      string
+	}{
+		"Syslog":     {format: FormatSyslog, sqlTextLength: 256, expected: `Oracle Unified Audit[49447]: LENGTH: '196' TYPE:"4" DBID:"252730892" SESID:"2507879320" CLIENTID:"" ENTRYID:"16" STMTID:"9" DBUSER:"SYS" CURUSER:"SYS" ACTION:"43" RETCODE:"0" SCHEMA:"" OBJNAME:"" PDB_GUID:"775B889664A21F8FFD8D3CB4830FAB3C"`},
+		"XML":        {format: FormatXML, sqlTextLength: 256, expected: `<AuditRecord><AUDIT_TYPE>Standard</AUDIT_TYPE><SESSIONID>2507879320</SESSIONID><OS_USERNAME>batch</OS_USERNAME><USERHOST>WORKSTATION-17</USERHOST><TERMINAL>WORKSTATION-17</TERMINAL><DBID>252730892</DBID><DBUSERNAME>SYS</DBUSERNAME><CURRENT_USER>SYS</CURRENT_USER><CLIENT_PROGRAM_NAME>SQL Developer</CLIENT_PROGRAM_NAME><EVENT_TIMESTAMP_UTC>1970-01-02T03:04:05.123456Z</EVENT_TIMESTAMP_UTC><ENTRY_ID>16</ENTRY_ID><STATEMENT_ID>9</STATEMENT_ID><ACTION_NAME>ALTER USER</ACTION_NAME><RETURN_CODE>0</RETURN_CODE><OBJECT_SCHEMA></OBJECT_SCHEMA><OBJECT_NAME></OBJECT_NAME><SQL_TEXT>ALTER USER SYSTEM IDENTIFIED BY *****</SQL_TEXT><UNIFIED_AUDIT_POLICIES>ORA_LOGON_FAILURES</UNIFIED_AUDIT_POLICIES><OS_PROCESS>49447</OS_PROCESS></AuditRecord>`},
+		"XML Short":  {format: FormatXML, sqlTextLength: 16, expected: `<AuditRecord><AUDIT_TYPE>Standard</AUDIT_TYPE><SESSIONID>2507879320</SESSIONID><OS_USERNAME>batch</OS_USERNAME><USERHOST>WORKSTATION-17</USERHOST><TERMINAL>WORKSTATION-17</TERMINAL><DBID>252730892</DBID><DBUSERNAME>SYS</DBUSERNAME><CURRENT_USER>SYS</CURRENT_USER><CLIENT_PROGRAM_NAME>SQL Developer</CLIENT_PROGRAM_NAME><EVENT_TIMESTAMP_UTC>1970-01-02T03:04:05.123456Z</EVENT_TIMESTAMP_UTC><ENTRY_ID>16</ENTRY_ID><STATEMENT_ID>9</STATEMENT_ID><ACTION_NAME>ALTER USER</ACTION_NAME><RETURN_CODE>0</RETURN_CODE><OBJECT_SCHEMA></OBJECT_SCHEMA><OBJECT_NAME></OBJECT_NAME><SQL_TEXT>ALTER USER SYSTE</SQL_TEXT><UNIFIED_AUDIT_POLICIES>ORA_LOGON_FAILURES</UNIFIED_AUDIT_POLICIES><OS_PROCESS>49447</OS_PROCESS></AuditRecord>`},
+		"XML No SQL": {format: FormatXML, sqlTextLength: 0, expected: `<AuditRecord><AUDIT_TYPE>Standard</AUDIT_TYPE><SESSIONID>2507879320</SESSIONID><OS_USERNAME>batch</OS_USERNAME><USERHOST>WORKSTATION-17</USERHOST><TERMINAL>WORKSTATION-17</TERMINAL><DBID>252730892</DBID><DBUSERNAME>SYS</DBUSERNAME><CURRENT_USER>SYS</CURRENT_USER><CLIENT_PROGRAM_NAME>SQL Developer</CLIENT_PROGRAM_NAME><EVENT_TIMESTAMP_UTC>1970-01-02T03:04:05.123456Z</EVENT_TIMESTAMP_UTC><ENTRY_ID>16</ENTRY_ID><STATEMENT_ID>9</STATEMENT_ID><ACTION_NAME>ALTER USER</ACTION_NAME><RETURN_CODE>0</RETURN_CODE><OBJECT_SCHEMA></OBJECT_SCHEMA><OBJECT_NAME></OBJECT_NAME><UNIFIED_AUDIT_POLICIES>ORA_LOGON_FAILURES</UNIFIED_AUDIT_POLICIES><OS_PROCESS>49447</OS_PROCESS></AuditRecord>`},
+	}
+	testTime, err := time.Parse(time.RFC3339Nano, "1970-01-02T03:04:05.123456Z")
+	assert.Nil(t, err)
+	for name, tc := range tests {
+		random.Seed(1)
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "format": tc.format, "sql_text_length": tc.sqlTextLength})
+		assert.Nil(t, err, name)
+		g, err := New(c)
+		assert.Nil(t, err, name)
+		a := g.(*Audit)
+		a.staticTime = &testTime
+		got, err := a.Next()
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, string(got), name)
+	}
+}
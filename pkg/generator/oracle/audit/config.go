@@ -0,0 +1,33 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Type          string `config:"type" validate:"required"`
+	Format        string `config:"format"`
+	SQLTextLength int    `config:"sql_text_length"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:          Name,
+		SQLTextLength: 256,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if !(c.Format == "" || c.Format == FormatSyslog || c.Format == FormatXML) {
+		return fmt.Errorf("'%s' is not a valid value for 'format' expected '%s'", c.Format, strings.Join([]string{FormatSyslog, FormatXML}, ", "))
+	}
+	if c.SQLTextLength < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'sql_text_length' expected a value >= 0", c.SQLTextLength)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Syslog": {
+			c:           map[string]interface{}{"type": Name, "format": "syslog"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with XML": {
+			c:           map[string]interface{}{"type": Name, "format": "xml", "sql_text_length": 32},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'oracle:audit' accessing config",
+		},
+		"Invalid Format": {
+			c:           map[string]interface{}{"type": Name, "format": "json"},
+			hasError:    true,
+			errorString: "'json' is not a valid value for 'format' expected 'syslog, xml' accessing config",
+		},
+		"Invalid SQL Text Length": {
+			c:           map[string]interface{}{"type": Name, "sql_text_length": -1},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'sql_text_length' expected a value >= 0 accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			_, err = New(c)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
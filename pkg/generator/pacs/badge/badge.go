@@ -0,0 +1,207 @@
+// Package badge generates physical access control (PACS) badge events.
+//
+// Events are JSON records of badge reads at door readers, either an
+// access grant or an access deny with a reason.  Each generator has a
+// fixed pool of users, every user has one badge and a home site, so
+// badge IDs, names and departments are consistent across events.
+//
+// About one in fifty granted badge-ins is followed by a badge-in with
+// the same badge at a different site two to ten minutes later, an
+// impossible sequence for insider risk correlation.  These events
+// have anomaly set to true.
+//
+// Configuration:
+//
+//	users: Number of users in the pool, defaults to 100.
+//
+//	- generator:
+//	    type: pacs:badge
+//	    users: 250
+package badge
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "pacs:badge"
+
+const (
+	EventTypeGranted = "access_granted"
+	EventTypeDenied  = "access_denied"
+)
+
+var (
+	sites = [...]struct {
+		name  string
+		city  string
+		doors []string
+	}{
+		{name: "HQ", city: "Amsterdam", doors: []string{"Main Entrance", "Lobby Turnstile 1", "Lobby Turnstile 2", "Data Center", "Executive Floor", "Loading Dock"}},
+		{name: "DC-EAST", city: "Ashburn", doors: []string{"Main Entrance", "Mantrap", "Cage 12", "Cage 14", "NOC"}},
+		{name: "OFFICE-LON", city: "London", doors: []string{"Main Entrance", "Reception", "Finance Suite", "Server Room"}},
+		{name: "OFFICE-SGP", city: "Singapore", doors: []string{"Main Entrance", "Lift Lobby", "Lab"}},
+	}
+	denyReasons = [...]string{
+		"No access level for door",
+		"Badge expired",
+		"Badge lost or stolen",
+		"Anti-passback violation",
+		"Outside of time zone",
+		"Invalid PIN",
+	}
+	firstNames  = [...]string{"Anna", "Bas", "Chloe", "David", "Emma", "Farid", "Grace", "Hiro", "Ines", "Jan", "Kofi", "Lena", "Mila", "Noah", "Olga", "Priya"}
+	lastNames   = [...]string{"de Vries", "Smith", "Jansen", "Garcia", "Tan", "Okafor", "Muller", "Bakker", "Chen", "Novak", "Ahmed", "Visser"}
+	departments = [...]string{"Engineering", "Finance", "Facilities", "HR", "IT Operations", "Legal", "Sales", "Security"}
+	directions  = [...]string{"in", "in", "out"}
+)
+
+// User is a badge holder.
+type User struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Department string `json:"department"`
+}
+
+// Event holds the fields of a badge event.
+type Event struct {
+	Timestamp time.Time `json:"@timestamp"`
+	EventID   int       `json:"event_id"`
+	EventType string    `json:"event_type"`
+	Site      string    `json:"site"`
+	City      string    `json:"city"`
+	Door      string    `json:"door"`
+	Reader    string    `json:"reader"`
+	Direction string    `json:"direction"`
+	BadgeID   string    `json:"badge_id"`
+	User      User      `json:"user"`
+	Reason    string    `json:"reason,omitempty"`
+	Anomaly   bool      `json:"anomaly,omitempty"`
+}
+
+// holder is a user in the pool with their badge and home site.
+type holder struct {
+	user    User
+	badgeID string
+	site    int
+}
+
+// Badge holds the state for generating badge events.
+type Badge struct {
+	holders    []holder
+	eventID    int
+	pending    []*Event
+	rand       *rand.Rand
+	staticTime *time.Time
+	clock      *generator.Clock
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Badge objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Badge{
+		eventID: r.IntN(1000000),
+		rand:    r,
+		clock:   clock,
+	}
+	for i := 0; i < c.Users; i++ {
+		b.holders = append(b.holders, holder{
+			user: User{
+				ID:         fmt.Sprintf("E%05d", 10000+i),
+				Name:       firstNames[r.IntN(len(firstNames))] + " " + lastNames[r.IntN(len(lastNames))],
+				Department: departments[r.IntN(len(departments))],
+			},
+			badgeID: fmt.Sprintf("%010d", r.Int64N(10000000000)),
+			site:    r.IntN(len(sites)),
+		})
+	}
+
+	return b, nil
+}
+
+// Next produces the next badge event.
+//
+// Example:
+//
+// {"@timestamp":"2023-01-02T03:04:05.123456Z","event_id":81212,"event_type":"access_granted","site":"HQ","city":"Amsterdam","door":"Main Entrance","reader":"HQ-RDR-01","direction":"in","badge_id":"0093455652","user":{"id":"E10042","name":"Emma Jansen","department":"Finance"}}
+func (b *Badge) Next() ([]byte, error) {
+	if len(b.pending) > 0 {
+		e := b.pending[0]
+		b.pending = b.pending[1:]
+		return json.Marshal(e)
+	}
+
+	h := b.holders[b.rand.IntN(len(b.holders))]
+	site := h.site
+	if b.rand.IntN(10) == 0 {
+		site = b.rand.IntN(len(sites))
+	}
+
+	e := b.event(h, site)
+	if b.rand.IntN(20) == 0 {
+		e.EventType = EventTypeDenied
+		e.Reason = denyReasons[b.rand.IntN(len(denyReasons))]
+	}
+
+	if e.EventType == EventTypeGranted && e.Direction == "in" && b.rand.IntN(50) == 0 {
+		other := (site + b.rand.IntN(len(sites)-1) + 1) % len(sites)
+		a := b.event(h, other)
+		a.Timestamp = e.Timestamp.Add(time.Duration(b.rand.IntN(480)+120) * time.Second)
+		a.Direction = "in"
+		a.Anomaly = true
+		b.pending = append(b.pending, a)
+	}
+
+	return json.Marshal(e)
+}
+
+// event returns a granted event for the holder at a random door of site.
+func (b *Badge) event(h holder, site int) *Event {
+	s := sites[site]
+	door := b.rand.IntN(len(s.doors))
+	b.eventID++
+
+	return &Event{
+		Timestamp: b.getTime(),
+		EventID:   b.eventID,
+		EventType: EventTypeGranted,
+		Site:      s.name,
+		City:      s.city,
+		Door:      s.doors[door],
+		Reader:    fmt.Sprintf("%s-RDR-%02d", s.name, door+1),
+		Direction: directions[b.rand.IntN(len(directions))],
+		BadgeID:   h.badgeID,
+		User:      h.user,
+	}
+}
+
+func (b *Badge) getTime() time.Time {
+	if b.staticTime != nil {
+		return *b.staticTime
+	}
+
+	return b.clock.Now()
+}
@@ -0,0 +1,82 @@
+package badge
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	random.Seed(1)
+	b := newBadge(t, 10)
+	got, err := b.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, `{"@timestamp":"1970-01-02T03:04:05.123456Z","event_id":877178,"event_type":"access_granted","site":"OFFICE-SGP","city":"Singapore","door":"Main Entrance","reader":"OFFICE-SGP-RDR-01","direction":"in","badge_id":"0032284936","user":{"id":"E10004","name":"David Jansen","department":"Security"}}`, string(got))
+}
+
+func TestImpossibleSequence(t *testing.T) {
+	random.Seed(1)
+	b := newBadge(t, 10)
+
+	badges := make(map[string]bool)
+	for _, h := range b.holders {
+		badges[h.badgeID] = true
+	}
+
+	anomalies := 0
+	var prev Event
+	for i := 0; i < 5000; i++ {
+		got, err := b.Next()
+		assert.Nil(t, err)
+		var e Event
+		assert.Nil(t, json.Unmarshal(got, &e))
+		assert.True(t, badges[e.BadgeID], e.BadgeID)
+		if e.Anomaly {
+			anomalies++
+			assert.Equal(t, prev.BadgeID, e.BadgeID)
+			assert.Equal(t, EventTypeGranted, prev.EventType)
+			assert.NotEqual(t, prev.Site, e.Site)
+			assert.True(t, e.Timestamp.After(prev.Timestamp))
+			assert.True(t, e.Timestamp.Sub(prev.Timestamp) <= 10*time.Minute)
+		}
+		prev = e
+	}
+	assert.NotZero(t, anomalies)
+}
+
+func TestSeed(t *testing.T) {
+	records := func(seed int64) []string {
+		random.Seed(time.Now().UnixNano())
+		b := newBadgeFrom(t, map[string]interface{}{"type": Name, "users": 10, "seed": seed})
+		var got []string
+		for i := 0; i < 100; i++ {
+			msg, err := b.Next()
+			assert.Nil(t, err)
+			got = append(got, string(msg))
+		}
+		return got
+	}
+
+	assert.Equal(t, records(42), records(42))
+	assert.NotEqual(t, records(42), records(43))
+}
+
+func newBadge(t *testing.T, users int) *Badge {
+	return newBadgeFrom(t, map[string]interface{}{"type": Name, "users": users})
+}
+
+func newBadgeFrom(t *testing.T, cfg map[string]interface{}) *Badge {
+	testTime, err := time.Parse(time.RFC3339Nano, "1970-01-02T03:04:05.123456Z")
+	assert.Nil(t, err)
+	c, err := ucfg.NewFrom(cfg)
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	b := g.(*Badge)
+	b.staticTime = &testTime
+	return b
+}
@@ -0,0 +1,28 @@
+package badge
+
+import (
+	"fmt"
+)
+
+type config struct {
+	Type  string `config:"type" validate:"required"`
+	Users int    `config:"users"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:  Name,
+		Users: 100,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if c.Users < 1 {
+		return fmt.Errorf("'%d' is not a valid value for 'users' expected a value greater than 0", c.Users)
+	}
+
+	return nil
+}
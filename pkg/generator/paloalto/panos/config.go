@@ -0,0 +1,32 @@
+package panos
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Type    string `config:"type" validate:"required"`
+	LogType string `config:"log_type"`
+	Version int    `config:"version"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:    Name,
+		Version: 10,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if _, ok := logFields[c.LogType]; !(c.LogType == "" || ok) {
+		return fmt.Errorf("'%s' is not a valid value for 'log_type' expected '%s'", c.LogType, strings.Join(logTypes[:], ", "))
+	}
+	if c.Version != 9 && c.Version != 10 {
+		return fmt.Errorf("'%d' is not a valid value for 'version' expected 9 or 10", c.Version)
+	}
+	return nil
+}
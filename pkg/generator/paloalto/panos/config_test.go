@@ -0,0 +1,79 @@
+package panos
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Traffic": {
+			c:           map[string]interface{}{"type": Name, "log_type": "traffic"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with GlobalProtect and Version 9": {
+			c:           map[string]interface{}{"type": Name, "log_type": "globalprotect", "version": 9},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'paloalto:panos' accessing config",
+		},
+		"Invalid Log Type": {
+			c:           map[string]interface{}{"type": Name, "log_type": "hipmatch"},
+			hasError:    true,
+			errorString: "'hipmatch' is not a valid value for 'log_type' expected 'traffic, threat, system, globalprotect' accessing config",
+		},
+		"Invalid Version": {
+			c:           map[string]interface{}{"type": Name, "version": 11},
+			hasError:    true,
+			errorString: "'11' is not a valid value for 'version' expected 9 or 10 accessing config",
+		},
+		"Valid Upgrade": {
+			c:           map[string]interface{}{"type": Name, "version": 9, "upgrade": map[string]interface{}{"version": 10, "at": "2024-03-01T12:00:00Z"}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Upgrade": {
+			c:           map[string]interface{}{"type": Name, "upgrade": map[string]interface{}{"version": 10, "at": "2024-03-01T12:00:00Z"}},
+			hasError:    true,
+			errorString: "'10' is not a valid value for 'upgrade.version' expected a version after '10'",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			_, err = New(c)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
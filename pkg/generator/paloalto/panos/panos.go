@@ -0,0 +1,564 @@
+// Package panos generates Palo Alto Networks PAN-OS syslog messages.
+//
+// Messages are the comma separated values PAN-OS sends to a syslog
+// server profile, for the TRAFFIC, THREAT, SYSTEM and GLOBALPROTECT
+// log types, for example "1,2024/03/04 12:00:00,007919727887,TRAFFIC,end,...".
+//
+// The number and order of the fields follows the PAN-OS version.
+// PAN-OS 10.x appends fields to every log type, for example the device
+// identification and application characteristics fields of TRAFFIC and
+// THREAT logs, so version 9 and version 10 messages are not the same
+// length.  Fields PAN-OS reserves as FUTURE_USE are sent empty.  With
+// upgrade the firewalls are upgraded from version 9 to 10 at a time,
+// see generator.Upgrade.
+//
+// Configuration:
+//
+//	log_type: Specify the type of log to generate, or leave blank for random.
+//	          Valid values are: traffic, threat, system, globalprotect.
+//	version:  The major PAN-OS version, 9 or 10.  Defaults to 10.
+//	upgrade:  (map, optional) The version to upgrade to and the time
+//	          at which.
+//
+//	- generator:
+//	    type: paloalto:panos
+//	    log_type: traffic
+//	    version: 9
+//	    upgrade:
+//	      version: 10
+//	      at: "2024-03-01T12:00:00Z"
+package panos
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "paloalto:panos"
+
+const (
+	LogTypeTraffic       = "traffic"
+	LogTypeThreat        = "threat"
+	LogTypeSystem        = "system"
+	LogTypeGlobalProtect = "globalprotect"
+)
+
+const (
+	timeFormat     = "2006/01/02 15:04:05"
+	highResFormat  = "2006-01-02T15:04:05.000-07:00"
+	receiveTime    = "{{.Timestamp.Format \"" + timeFormat + "\"}}"
+	generatedTime  = "{{.Generated.Format \"" + timeFormat + "\"}}"
+	highResTime    = "{{.Generated.Format \"" + highResFormat + "\"}}"
+	deviceGroups   = "0,0,0,0"
+	devicesPerPool = 4
+	maxSequence    = 1 << 40
+)
+
+var (
+	logTypes = [...]string{LogTypeTraffic, LogTypeThreat, LogTypeSystem, LogTypeGlobalProtect}
+	// versions are the major PAN-OS versions, from the oldest to the newest.
+	versions = [...]string{"9", "10"}
+
+	// logFields are the fields of each log type after the common
+	// header, for PAN-OS 9.x and the fields PAN-OS 10.x appends.
+	logFields = map[string]struct{ v9, v10 []string }{
+		LogTypeTraffic: {
+			v9: []string{
+				"{{.SrcIp}}", "{{.DstIp}}", "{{.NatSrcIp}}", "{{.NatDstIp}}", "{{.Rule}}", "{{.SrcUser}}", "", "{{.App}}", "{{.Vsys}}",
+				"{{.SrcZone}}", "{{.DstZone}}", "{{.InInterface}}", "{{.OutInterface}}", "default", "", "{{.SessionId}}", "1",
+				"{{.SrcPort}}", "{{.DstPort}}", "{{.NatSrcPort}}", "{{.NatDstPort}}", "0x400019", "{{.Protocol}}", "{{.Action}}",
+				"{{.Bytes}}", "{{.BytesSent}}", "{{.BytesReceived}}", "{{.Packets}}", "{{.Start.Format \"" + timeFormat + "\"}}",
+				"{{.Elapsed}}", "{{.Category}}", "", "{{.Sequence}}", "0x8000000000000000", "{{.SrcLocation}}", "{{.DstLocation}}", "",
+				"{{.PacketsSent}}", "{{.PacketsReceived}}", "{{.EndReason}}", deviceGroups, "", "{{.DeviceName}}", "from-policy",
+				"", "", "0", "", "0", "", "N/A", "0", "0", "0", "0", "{{.RuleUUID}}", "0", "0", "", "0", "", "", "", "", "",
+			},
+			v10: append(empty(1+16+10),
+				highResTime, "", "",
+				"{{.AppSubcategory}}", "{{.AppCategory}}", "{{.AppTechnology}}", "{{.AppRisk}}", "", "", "", "no", "no", "0"),
+		},
+		LogTypeThreat: {
+			v9: []string{
+				"{{.SrcIp}}", "{{.DstIp}}", "{{.NatSrcIp}}", "{{.NatDstIp}}", "{{.Rule}}", "{{.SrcUser}}", "", "{{.App}}", "{{.Vsys}}",
+				"{{.SrcZone}}", "{{.DstZone}}", "{{.InInterface}}", "{{.OutInterface}}", "default", "", "{{.SessionId}}", "1",
+				"{{.SrcPort}}", "{{.DstPort}}", "{{.NatSrcPort}}", "{{.NatDstPort}}", "0x80402000", "{{.Protocol}}", "{{.ThreatAction}}",
+				"\"{{.URL}}\"", "{{.ThreatName}}({{.ThreatId}})", "{{.Category}}", "{{.Severity}}", "{{.Direction}}", "{{.Sequence}}",
+				"0x8000000000000000", "{{.SrcLocation}}", "{{.DstLocation}}", "", "", "0", "", "", "0", "", "", "", "", "", "", "", "0",
+				deviceGroups, "", "{{.DeviceName}}", "", "", "", "", "0", "", "0", "", "N/A", "{{.ThreatCategory}}", "8400-7895", "",
+				"0", "0", "", "", "{{.RuleUUID}}", "0", "",
+			},
+			v10: append(empty(1+16+11),
+				highResTime, "", "", "",
+				"{{.AppSubcategory}}", "{{.AppCategory}}", "{{.AppTechnology}}", "{{.AppRisk}}", "", "", "", "no", "no"),
+		},
+		LogTypeSystem: {
+			v9: []string{
+				"{{.Vsys}}", "{{.EventId}}", "", "", "", "{{.Module}}", "{{.SystemSeverity}}", "\"{{.Description}}\"", "{{.Sequence}}",
+				"0x0", deviceGroups, "", "{{.DeviceName}}",
+			},
+			v10: []string{"", "", highResTime},
+		},
+		LogTypeGlobalProtect: {
+			v9: []string{
+				"{{.Vsys}}", "{{.GPEvent}}", "{{.Stage}}", "{{.AuthMethod}}", "{{.TunnelType}}", "{{.SrcUser}}", "{{.SrcLocation}}",
+				"{{.MachineName}}", "{{.PublicIp}}", "0.0.0.0", "{{.PrivateIp}}", "0.0.0.0", "{{.HostId}}", "{{.ClientSerial}}",
+				"{{.ClientVersion}}", "{{.ClientOS}}", "\"{{.ClientOSVersion}}\"", "1", "", "", "\"{{.GPDescription}}\"", "{{.Status}}",
+				"", "{{.LoginDuration}}", "{{.ConnectMethod}}", "0", "{{.Portal}}", "{{.Sequence}}", "0x8000000000000000",
+				highResTime, "", "", "", "", "{{.Gateway}}", deviceGroups, "", "{{.DeviceName}}", "1",
+			},
+			v10: []string{""},
+		},
+	}
+
+	// upper case log type of the header
+	headerTypes = map[string]string{
+		LogTypeTraffic:       "TRAFFIC",
+		LogTypeThreat:        "THREAT",
+		LogTypeSystem:        "SYSTEM",
+		LogTypeGlobalProtect: "GLOBALPROTECT",
+	}
+
+	applications = [...]struct {
+		name, subcategory, category, technology string
+		risk                                    int
+		ports                                   []int
+	}{
+		{"ssl", "encrypted-tunnel", "networking", "browser-based", 4, []int{443}},
+		{"web-browsing", "internet-utility", "general-internet", "browser-based", 4, []int{80, 8080}},
+		{"dns", "infrastructure", "networking", "network-protocol", 3, []int{53}},
+		{"ms-office365-base", "office-programs", "business-systems", "browser-based", 1, []int{443}},
+		{"ssh", "encrypted-tunnel", "networking", "client-server", 4, []int{22}},
+		{"ntp", "infrastructure", "networking", "network-protocol", 2, []int{123}},
+		{"smtp", "email", "collaboration", "client-server", 3, []int{25, 587}},
+		{"ms-rdp", "remote-access", "networking", "client-server", 4, []int{3389}},
+	}
+	fwRules        = [...]string{"allow-outbound-web", "allow-dns", "allow-mgmt", "deny-all", "allow-o365", "intrazone-default", "interzone-default"}
+	trafficActions = [...]string{"allow", "deny", "drop", "reset-both"}
+	trafficTypes   = [...]string{"end", "start", "drop", "deny"}
+	endReasons     = [...]string{"aged-out", "tcp-fin", "tcp-rst-from-client", "tcp-rst-from-server", "policy-deny", "threat", "decrypt-error", "n/a"}
+	urlCategories  = [...]string{"any", "business-and-economy", "computer-and-internet-info", "search-engines", "social-networking", "malware", "news", "web-advertisements"}
+	zones          = [...][2]string{{"trust", "ethernet1/2"}, {"untrust", "ethernet1/1"}, {"dmz", "ethernet1/3"}, {"vpn", "tunnel.1"}}
+	locations      = [...]string{"10.0.0.0-10.255.255.255", "192.168.0.0-192.168.255.255", "United States", "Netherlands", "Germany", "Japan", "Brazil"}
+	users          = [...]string{"acme\\jdoe", "acme\\asmith", "acme\\mlee", "acme\\kpatel", "acme\\rgarcia", "acme\\svc_backup", ""}
+	threats        = [...]struct {
+		subtype, name, category, severity string
+		id                                int
+	}{
+		{"vulnerability", "SSH User Authentication Brute Force Attempt", "brute-force", "high", 40016},
+		{"vulnerability", "Microsoft Windows SMB Remote Code Execution Vulnerability", "code-execution", "critical", 91456},
+		{"vulnerability", "Apache Log4j Remote Code Execution Vulnerability", "code-execution", "critical", 91991},
+		{"vulnerability", "HTTP Directory Traversal Vulnerability", "info-leak", "medium", 30844},
+		{"spyware", "Suspicious DNS Query", "dns-c2", "medium", 109001001},
+		{"virus", "Virus/Win32.WGeneric.dwtkq", "virus", "high", 451234567},
+		{"file", "Windows Executable (EXE)", "file", "low", 52020},
+		{"url", "Non-RFC Compliant SSL Traffic on Port 443", "protocol-anomaly", "informational", 56112},
+	}
+	threatActions = [...]string{"alert", "block-url", "reset-both", "drop", "allow", "sinkhole"}
+	urls          = [...]string{"www.silverpinevalley.com/", "www.brickstoneridge.net/login.php", "cdn.oakwoodgrove.org/assets/app.js", "update.copperhollow.info/", "www.windyriverplains.com/wp-admin/", "mail.crystalbayvillage.net/owa/", "setup.exe", ""}
+	systemEvents  = [...]struct {
+		module, event, severity, description string
+	}{
+		{"general", "general", "informational", "User admin logged in via Web from 10.0.1.15 using https"},
+		{"general", "auth-fail", "medium", "failed authentication for user 'admin'. Reason: Invalid username/password. From: 198.51.100.23."},
+		{"general", "config-commit", "informational", "Commit job succeeded, job id 4521"},
+		{"ha", "state-change", "critical", "HA Group 1: Moved from state Passive to state Active"},
+		{"vpn", "ike-nego-p2-succ", "informational", "IKE phase-2 negotiation is succeeded as initiator, quick mode. Established SA: 203.0.113.4[500]-198.51.100.77[500] message id:0x0B7A22C1."},
+		{"general", "ntpd-sync", "informational", "NTP sync to server 10.0.0.2 succeeded"},
+		{"general", "disk-usage", "high", "Disk usage for /opt/panlogs exceeds limit, 92 percent in use"},
+	}
+	gpEvents = [...]struct {
+		event, stage, status, description string
+	}{
+		{"portal-auth", "login", "success", "GlobalProtect portal user authentication succeeded. Login from: {{.PublicIp}}, Source region: {{.SrcLocation}}, User name: {{.SrcUser}}, Auth type: profile, Client OS version: {{.ClientOSVersion}}."},
+		{"gateway-auth", "login", "success", "GlobalProtect gateway user authentication succeeded. Login from: {{.PublicIp}}, Source region: {{.SrcLocation}}, User name: {{.SrcUser}}, Client OS version: {{.ClientOSVersion}}."},
+		{"gateway-connected", "connected", "success", "GlobalProtect gateway connected. Login from: {{.PublicIp}}, User name: {{.SrcUser}}, Private IP: {{.PrivateIp}}."},
+		{"gateway-logout", "logout", "success", "GlobalProtect gateway user logout succeeded. User name: {{.SrcUser}}, Reason: client logout."},
+		{"portal-auth", "login", "failure", "GlobalProtect portal user authentication failed. Login from: {{.PublicIp}}, User name: {{.SrcUser}}, Reason: Authentication failed: Invalid username or password."},
+	}
+	authMethods    = [...]string{"LDAP", "SAML", "RADIUS", "Kerberos", "Client Certificate"}
+	tunnelTypes    = [...]string{"IPSec", "SSL"}
+	clients        = [...][2]string{{"Windows", "Microsoft Windows 10 Pro , 64-bit"}, {"Mac", "Apple Mac OS X 12.6.1"}, {"Windows", "Microsoft Windows 11 Enterprise , 64-bit"}, {"Linux", "Ubuntu 22.04 LTS"}}
+	clientVersions = [...]string{"5.2.12-14", "6.0.4-26", "6.1.1-5", "6.2.0-89"}
+	connectMethods = [...]string{"user-logon", "on-demand", "pre-logon"}
+	gateways       = [...]string{"gp-gw-us-east", "gp-gw-eu-west", "gp-gw-ap-south"}
+)
+
+// device is a simulated firewall.
+type device struct {
+	name   string
+	serial string
+}
+
+// PanOS holds the random fields for a PAN-OS log message.
+type PanOS struct {
+	Action          string
+	App             string
+	AppCategory     string
+	AppRisk         int
+	AppSubcategory  string
+	AppTechnology   string
+	AuthMethod      string
+	Bytes           int
+	BytesReceived   int
+	BytesSent       int
+	Category        string
+	ClientOS        string
+	ClientOSVersion string
+	ClientSerial    string
+	ClientVersion   string
+	ConnectMethod   string
+	Description     string
+	DeviceName      string
+	Direction       string
+	DstIp           net.IP
+	DstLocation     string
+	DstPort         int
+	DstZone         string
+	Elapsed         int
+	EndReason       string
+	EventId         string
+	Gateway         string
+	Generated       time.Time
+	GPDescription   string
+	GPEvent         string
+	HostId          string
+	InInterface     string
+	LoginDuration   int
+	MachineName     string
+	Module          string
+	NatDstIp        net.IP
+	NatDstPort      int
+	NatSrcIp        net.IP
+	NatSrcPort      int
+	OutInterface    string
+	Packets         int
+	PacketsReceived int
+	PacketsSent     int
+	Portal          string
+	PrivateIp       net.IP
+	Protocol        string
+	PublicIp        net.IP
+	Rule            string
+	RuleUUID        string
+	Sequence        int64
+	SessionId       int
+	Serial          string
+	Severity        string
+	SrcIp           net.IP
+	SrcLocation     string
+	SrcPort         int
+	SrcUser         string
+	SrcZone         string
+	Stage           string
+	Start           time.Time
+	Status          string
+	Subtype         string
+	SystemSeverity  string
+	ThreatAction    string
+	ThreatCategory  string
+	ThreatId        int
+	ThreatName      string
+	ThreatSubtype   string
+	Timestamp       time.Time
+	TunnelType      string
+	URL             string
+	Vsys            string
+
+	rand         *rand.Rand
+	clock        *generator.Clock
+	pins         *generator.Pins
+	logType      string
+	devices      []device
+	ruleUUIDs    map[string]string
+	templates    map[string]*template.Template
+	upgrade      *generator.Upgrade
+	upgraded     map[string]*template.Template
+	descriptions []*template.Template
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for PanOS objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	rnd, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PanOS{
+		rand:      rnd,
+		clock:     clock,
+		logType:   c.LogType,
+		ruleUUIDs: make(map[string]string),
+	}
+
+	p.pins, err = generator.NewPins(cfg, rnd, p)
+	if err != nil {
+		return nil, err
+	}
+
+	p.upgrade, err = generator.NewUpgrade(cfg, strconv.Itoa(c.Version), versions[:])
+	if err != nil {
+		return nil, err
+	}
+
+	p.templates, err = parse(c.Version)
+	if err != nil {
+		return nil, err
+	}
+	if p.upgrade != nil {
+		// NewUpgrade checked the version.
+		version, _ := strconv.Atoi(p.upgrade.Version)
+		p.upgraded, err = parse(version)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i, v := range gpEvents {
+		t, err := template.New(fmt.Sprintf("%s%d", LogTypeGlobalProtect, i)).Funcs(generator.FunctionMap).Parse(v.description)
+		if err != nil {
+			return nil, err
+		}
+		p.descriptions = append(p.descriptions, t)
+	}
+
+	for i := 0; i < devicesPerPool; i++ {
+		p.devices = append(p.devices, device{
+			name:   fmt.Sprintf("PA-%d-%02d", [...]int{440, 3220, 5250, 850}[i], i+1),
+			serial: fmt.Sprintf("0079%08d", rnd.IntN(100000000)),
+		})
+	}
+	for _, rule := range fwRules {
+		p.ruleUUIDs[rule] = randomUUID(rnd)
+	}
+	p.Sequence = rnd.Int64N(maxSequence)
+
+	if err := p.randomize(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Next produces the next PAN-OS log message.
+//
+// Example:
+//
+// 1,2024/03/04 12:00:00,007919727887,SYSTEM,general,,2024/03/04 12:00:00,vsys1,ntpd-sync,,,,general,informational,"NTP sync to server 10.0.0.2 succeeded",631200023301,0x0,0,0,0,0,,PA-3220-02,,,2024-03-04T12:00:00.000+00:00
+func (p *PanOS) Next() ([]byte, error) {
+	var buf bytes.Buffer
+
+	logType := p.logType
+	if logType == "" {
+		logType = logTypes[p.rand.IntN(len(logTypes))]
+	}
+	switch logType {
+	case LogTypeTraffic:
+		p.Subtype = trafficTypes[p.rand.IntN(len(trafficTypes))]
+		p.trafficSubtype()
+	case LogTypeThreat:
+		p.Subtype = p.ThreatSubtype
+	case LogTypeSystem:
+		p.Subtype = p.Module
+	default:
+		p.Subtype = ""
+	}
+
+	templates := p.templates
+	if p.upgrade.Upgraded(p.Timestamp) {
+		templates = p.upgraded
+	}
+	if err := templates[logType].Execute(&buf, p); err != nil {
+		return nil, err
+	}
+
+	if err := p.randomize(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// trafficSubtype makes the action and session end reason of a
+// TRAFFIC log agree with its subtype.
+func (p *PanOS) trafficSubtype() {
+	switch p.Subtype {
+	case "start":
+		p.Action = "allow"
+		p.EndReason = "n/a"
+		p.Elapsed = 0
+		p.Start = p.Generated
+	case "drop", "deny":
+		p.Action = p.Subtype
+		p.EndReason = "policy-deny"
+		p.BytesReceived = 0
+		p.PacketsReceived = 0
+		p.Bytes = p.BytesSent
+		p.Packets = p.PacketsSent
+	default:
+		p.Action = "allow"
+	}
+}
+
+// parse returns the templates of the log types for a PAN-OS version.
+func parse(version int) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template)
+	for _, logType := range logTypes {
+		t, err := template.New(logType).Funcs(generator.FunctionMap).Parse(format(logType, version))
+		if err != nil {
+			return nil, err
+		}
+		templates[logType] = t
+	}
+	return templates, nil
+}
+
+// format returns the template of a log type for a PAN-OS version.
+func format(logType string, version int) string {
+	header := []string{"1", receiveTime, "{{.Serial}}", headerTypes[logType], "{{.Subtype}}", "", generatedTime}
+	fields := append(header, logFields[logType].v9...)
+	if version >= 10 {
+		fields = append(fields, logFields[logType].v10...)
+	}
+	return strings.Join(fields, ",")
+}
+
+// empty returns n empty fields.
+func empty(n int) []string {
+	return make([]string, n)
+}
+
+func (p *PanOS) randomize() error {
+	d := p.devices[p.rand.IntN(len(p.devices))]
+	app := applications[p.rand.IntN(len(applications))]
+	src := zones[p.rand.IntN(len(zones))]
+	dst := zones[p.rand.IntN(len(zones))]
+
+	p.Timestamp = p.clock.Now()
+	p.Generated = p.Timestamp.Add(-time.Duration(p.rand.IntN(3)) * time.Second)
+	p.Serial = d.serial
+	p.DeviceName = d.name
+	p.Vsys = "vsys1"
+	p.Sequence++
+
+	p.SrcIp = random.IPv4(p.rand)
+	p.DstIp = random.IPv4(p.rand)
+	p.NatSrcIp = random.IPv4(p.rand)
+	p.NatDstIp = p.DstIp
+	p.SrcPort = random.Port(p.rand)
+	p.DstPort = app.ports[p.rand.IntN(len(app.ports))]
+	p.NatSrcPort = random.Port(p.rand)
+	p.NatDstPort = p.DstPort
+	p.SrcZone, p.InInterface = src[0], src[1]
+	p.DstZone, p.OutInterface = dst[0], dst[1]
+	p.SrcLocation = locations[p.rand.IntN(len(locations))]
+	p.DstLocation = locations[p.rand.IntN(len(locations))]
+	p.SrcUser = users[p.rand.IntN(len(users))]
+	p.SessionId = p.rand.IntN(1000000)
+
+	p.App = app.name
+	p.AppSubcategory = app.subcategory
+	p.AppCategory = app.category
+	p.AppTechnology = app.technology
+	p.AppRisk = app.risk
+	p.Protocol = "tcp"
+	if app.name == "dns" || app.name == "ntp" {
+		p.Protocol = "udp"
+	}
+	p.Rule = fwRules[p.rand.IntN(len(fwRules))]
+	p.RuleUUID = p.ruleUUIDs[p.Rule]
+	p.Action = trafficActions[p.rand.IntN(len(trafficActions))]
+	p.PacketsSent = p.rand.IntN(1000) + 1
+	p.PacketsReceived = p.rand.IntN(1000)
+	p.Packets = p.PacketsSent + p.PacketsReceived
+	p.BytesSent = p.PacketsSent * (p.rand.IntN(1400) + 60)
+	p.BytesReceived = p.PacketsReceived * (p.rand.IntN(1400) + 60)
+	p.Bytes = p.BytesSent + p.BytesReceived
+	p.Elapsed = p.rand.IntN(3600)
+	p.Start = p.Generated.Add(-time.Duration(p.Elapsed) * time.Second)
+	p.Category = urlCategories[p.rand.IntN(len(urlCategories))]
+	p.EndReason = endReasons[p.rand.IntN(len(endReasons))]
+
+	t := threats[p.rand.IntN(len(threats))]
+	p.ThreatSubtype = t.subtype
+	p.ThreatName = t.name
+	p.ThreatId = t.id
+	p.ThreatCategory = t.category
+	p.Severity = t.severity
+	p.ThreatAction = threatActions[p.rand.IntN(len(threatActions))]
+	p.URL = urls[p.rand.IntN(len(urls))]
+	p.Direction = [...]string{"client-to-server", "server-to-client"}[p.rand.IntN(2)]
+
+	e := systemEvents[p.rand.IntN(len(systemEvents))]
+	p.Module = e.module
+	p.EventId = e.event
+	p.SystemSeverity = e.severity
+	p.Description = e.description
+
+	client := clients[p.rand.IntN(len(clients))]
+	p.AuthMethod = authMethods[p.rand.IntN(len(authMethods))]
+	p.TunnelType = tunnelTypes[p.rand.IntN(len(tunnelTypes))]
+	p.MachineName = fmt.Sprintf("ACME-%s-%04d", strings.ToUpper(client[0][:3]), p.rand.IntN(10000))
+	p.PublicIp = random.IPv4(p.rand)
+	p.PrivateIp = net.IPv4(10, 200, byte(p.rand.IntN(256)), byte(p.rand.IntN(254)+1))
+	p.HostId = randomUUID(p.rand)
+	p.ClientSerial = fmt.Sprintf("%s%08d", strings.ToUpper(client[0][:1]), p.rand.IntN(100000000))
+	p.ClientVersion = clientVersions[p.rand.IntN(len(clientVersions))]
+	p.ClientOS = client[0]
+	p.ClientOSVersion = client[1]
+	p.ConnectMethod = connectMethods[p.rand.IntN(len(connectMethods))]
+	p.LoginDuration = p.rand.IntN(28800)
+	p.Portal = "gp-portal"
+	p.Gateway = gateways[p.rand.IntN(len(gateways))]
+
+	i := p.rand.IntN(len(gpEvents))
+	p.GPEvent = gpEvents[i].event
+	p.Stage = gpEvents[i].stage
+	p.Status = gpEvents[i].status
+	var buf bytes.Buffer
+	if err := p.descriptions[i].Execute(&buf, p); err != nil {
+		return err
+	}
+	p.GPDescription = buf.String()
+
+	p.pins.Apply(p)
+
+	return nil
+}
+
+func randomUUID(r *rand.Rand) string {
+	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x", r.Uint32(), r.IntN(1<<16), r.IntN(1<<12)|0x4000, r.IntN(1<<14)|0x8000, r.Int64N(1<<48))
+}
+
+// Templates returns the templates of the generator, for generator.Lint.
+func (p *PanOS) Templates() (interface{}, []*template.Template) {
+	templates := append([]*template.Template{}, p.descriptions...)
+	for _, t := range p.templates {
+		templates = append(templates, t)
+	}
+	for _, t := range p.upgraded {
+		templates = append(templates, t)
+	}
+	return p, templates
+}
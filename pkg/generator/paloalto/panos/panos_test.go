@@ -0,0 +1,116 @@
+package panos
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		logType  string
+		version  int
+		expected string
+	}{
+		"System 9":        {logType: LogTypeSystem, version: 9, expected: `1,2024/03/04 12:00:00,007946624044,SYSTEM,ha,,2024/03/04 12:00:00,vsys1,state-change,,,,ha,critical,"HA Group 1: Moved from state Passive to state Active",965871916047,0x0,0,0,0,0,,PA-3220-02`},
+		"System 10":       {logType: LogTypeSystem, version: 10, expected: `1,2024/03/04 12:00:00,007946624044,SYSTEM,ha,,2024/03/04 12:00:00,vsys1,state-change,,,,ha,critical,"HA Group 1: Moved from state Passive to state Active",965871916047,0x0,0,0,0,0,,PA-3220-02,,,2024-03-04T12:00:00.000+00:00`},
+		"GlobalProtect 9": {logType: LogTypeGlobalProtect, version: 9, expected: `1,2024/03/04 12:00:00,007946624044,GLOBALPROTECT,,,2024/03/04 12:00:00,vsys1,portal-auth,login,Client Certificate,SSL,acme\jdoe,Netherlands,ACME-WIN-8687,116.24.153.102,0.0.0.0,10.200.141.252,0.0.0.0,edf2a5ac-6ce0-4a15-b62b-b895ff097e8a,W68827150,5.2.12-14,Windows,"Microsoft Windows 10 Pro , 64-bit",1,,,"GlobalProtect portal user authentication succeeded. Login from: 116.24.153.102, Source region: Netherlands, User name: acme\jdoe, Auth type: profile, Client OS version: Microsoft Windows 10 Pro , 64-bit.",success,,2514,on-demand,0,gp-portal,965871916047,0x8000000000000000,2024-03-04T12:00:00.000+00:00,,,,,gp-gw-us-east,0,0,0,0,,PA-3220-02,1`},
+	}
+	testTime, err := time.Parse(time.RFC3339, "2024-03-04T12:00:00Z")
+	assert.Nil(t, err)
+	for name, tc := range tests {
+		random.Seed(1)
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "log_type": tc.logType, "version": tc.version})
+		assert.Nil(t, err, name)
+		g, err := New(c)
+		assert.Nil(t, err, name)
+		p := g.(*PanOS)
+		p.Timestamp = testTime
+		p.Generated = testTime
+		got, err := g.Next()
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, string(got), name)
+	}
+}
+
+func TestFieldCounts(t *testing.T) {
+	tests := map[string]struct {
+		logType string
+		v9, v10 int
+	}{
+		"Traffic":       {logType: LogTypeTraffic, v9: 75, v10: 115},
+		"Threat":        {logType: LogTypeThreat, v9: 79, v10: 120},
+		"System":        {logType: LogTypeSystem, v9: 23, v10: 26},
+		"GlobalProtect": {logType: LogTypeGlobalProtect, v9: 49, v10: 50},
+	}
+	for name, tc := range tests {
+		for version, want := range map[int]int{9: tc.v9, 10: tc.v10} {
+			c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "log_type": tc.logType, "version": version})
+			assert.Nil(t, err, name)
+			g, err := New(c)
+			assert.Nil(t, err, name)
+			for i := 0; i < 100; i++ {
+				got, err := g.Next()
+				assert.Nil(t, err, name)
+				record, err := csv.NewReader(strings.NewReader(string(got))).Read()
+				assert.Nil(t, err, name)
+				assert.Len(t, record, want, "%s version %d: %s", name, version, got)
+				assert.Equal(t, strings.ToUpper(tc.logType), record[3], name)
+			}
+		}
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{
+		"type":      Name,
+		"log_type":  LogTypeTraffic,
+		"version":   9,
+		"upgrade":   map[string]interface{}{"version": 10, "at": "2024-03-01T00:10:00Z"},
+		"timestamp": map[string]interface{}{"start": "2024-03-01T00:00:00Z", "interval": "1s"},
+	})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	for i := 0; i < 1200; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		record, err := csv.NewReader(strings.NewReader(string(got))).Read()
+		assert.Nil(t, err)
+		// Event i is at i seconds, the first 600 are before the upgrade.
+		want := 115
+		if i < 600 {
+			want = 75
+		}
+		assert.Len(t, record, want, "event %d: %s", i, got)
+	}
+}
+
+func TestTraffic(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "log_type": LogTypeTraffic})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	for i := 0; i < 1000; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		record, err := csv.NewReader(strings.NewReader(string(got))).Read()
+		assert.Nil(t, err)
+		subtype, action, reason := record[4], record[30], record[46]
+		switch subtype {
+		case "start":
+			assert.Equal(t, "allow", action, string(got))
+			assert.Equal(t, "n/a", reason, string(got))
+		case "drop", "deny":
+			assert.Equal(t, subtype, action, string(got))
+			assert.Equal(t, "policy-deny", reason, string(got))
+		default:
+			assert.Equal(t, "allow", action, string(got))
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"fmt"
+	"text/template"
+)
+
+// The partials are the templates the templates of NewTemplate can
+// compose with {{template}}, so the generators of variants of an
+// appliance share the headers instead of copies of them.  They are
+// executed with a dict, the keys that are left out are left out of
+// the header or, for the fields a header must have, their defaults:
+//
+//	syslog_header:      The RFC 3164 header, "<Priority>Jan _2 15:04:05 Host ",
+//	                    without a Priority no "<Priority>".
+//	syslog5424_header:  The RFC 5424 header, "<Priority>1 Timestamp Host
+//	                    AppName ProcID MsgID ", the Timestamp in the Layout,
+//	                    by default RFC 3339 with microseconds, and "-" for
+//	                    the missing fields.
+//	cef_header:         The CEF header, "CEF:CEFVersion|Vendor|Product|
+//	                    Version|SignatureID|Name|Severity|", without a
+//	                    CEFVersion version 0.
+//	cef_syslog_header:  The syslog header before the CEF header of the
+//	                    Header, see NewHeader, syslog_header for
+//	                    HeaderRFC3164, syslog5424_header for
+//	                    HeaderRFC5424 and nothing otherwise.
+//
+// kv_pairs is executed with a list, of "key=value" pairs such as those
+// of pairs, and writes them separated by a space.
+//
+//	{{template "cef_header" (dict "Vendor" "IBM" "Product" "IBM i" "Version" .Release ...)}}{{template "kv_pairs" (pairs "rt" .Timestamp.UnixMilli ...)}}
+const partialsText = `{{define "syslog_header"}}{{with .Priority}}<{{.}}>{{end}}{{.Timestamp.Format "Jan _2 15:04:05"}} {{.Host}} {{end}}` +
+	`{{define "syslog5424_header"}}<{{or .Priority 0}}>1 {{.Timestamp.Format (or .Layout "2006-01-02T15:04:05.000000Z07:00")}} {{or .Host "-"}} {{or .AppName "-"}} {{or .ProcID "-"}} {{or .MsgID "-"}} {{end}}` +
+	`{{define "cef_header"}}CEF:{{or .CEFVersion 0}}|{{.Vendor}}|{{.Product}}|{{.Version}}|{{.SignatureID}}|{{.Name}}|{{.Severity}}|{{end}}` +
+	`{{define "cef_syslog_header"}}{{if eq (or .Header "") "rfc3164"}}{{template "syslog_header" .}}{{else if eq (or .Header "") "rfc5424"}}{{template "syslog5424_header" .}}{{end}}{{end}}` +
+	`{{define "kv_pairs"}}{{range $i, $v := .}}{{if $i}} {{end}}{{$v}}{{end}}{{end}}`
+
+var partials = template.Must(template.New("partials").Funcs(FunctionMap).Parse(partialsText))
+
+// NewTemplate returns the template name of text, with the FunctionMap
+// and the partials.
+func NewTemplate(name, text string) (*template.Template, error) {
+	t, err := partials.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return t.New(name).Parse(text)
+}
+
+// isPartial returns true for the names of the partials, which Lint
+// only checks where a template executes them.
+func isPartial(name string) bool {
+	return partials.Lookup(name) != nil
+}
+
+// dict returns the map of the keys and values of pairs, for executing
+// a partial.
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict expects pairs of keys and values, got %d arguments", len(pairs))
+	}
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		k, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("'%v' is not a valid key for dict expected a string", pairs[i])
+		}
+		m[k] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// kvPairs returns the "key=value" pairs of the keys and values of
+// pairs, in order, for kv_pairs.
+func kvPairs(pairs ...interface{}) ([]string, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("pairs expects pairs of keys and values, got %d arguments", len(pairs))
+	}
+	kv := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		kv = append(kv, fmt.Sprintf("%v=%v", pairs[i], pairs[i+1]))
+	}
+	return kv, nil
+}
@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartials(t *testing.T) {
+	ts := time.Date(2024, 3, 5, 7, 8, 9, 123456000, time.UTC)
+	tests := map[string]struct {
+		text string
+		want string
+	}{
+		"syslog": {
+			text: `{{template "syslog_header" (dict "Priority" 134 "Timestamp" .Timestamp "Host" "fw01")}}msg`,
+			want: "<134>Mar  5 07:08:09 fw01 msg",
+		},
+		"syslog no priority": {
+			text: `{{template "syslog_header" (dict "Timestamp" .Timestamp "Host" "fw01")}}msg`,
+			want: "Mar  5 07:08:09 fw01 msg",
+		},
+		"syslog5424": {
+			text: `{{template "syslog5424_header" (dict "Priority" 14 "Timestamp" .Timestamp "Host" "fw01" "AppName" "sshd")}}msg`,
+			want: "<14>1 2024-03-05T07:08:09.123456Z fw01 sshd - - msg",
+		},
+		"syslog5424 layout": {
+			text: `{{template "syslog5424_header" (dict "Timestamp" .Timestamp "Layout" "2006-01-02T15:04:05Z07:00")}}msg`,
+			want: "<0>1 2024-03-05T07:08:09Z - - - - msg",
+		},
+		"cef": {
+			text: `{{template "cef_header" (dict "Vendor" "Vapor" "Product" "Ware" "Version" "1.0" "SignatureID" 100 "Name" "Login" "Severity" 5)}}{{template "kv_pairs" (pairs "src" .SrcAddr "spt" .SrcPort)}}`,
+			want: "CEF:0|Vapor|Ware|1.0|100|Login|5|src=10.0.0.1 spt=443",
+		},
+		"cef syslog rfc3164": {
+			text: `{{template "cef_syslog_header" (dict "Header" "rfc3164" "Priority" 14 "Timestamp" .Timestamp "Host" "fw01" "AppName" "Ware")}}CEF:0`,
+			want: "<14>Mar  5 07:08:09 fw01 CEF:0",
+		},
+		"cef syslog rfc5424": {
+			text: `{{template "cef_syslog_header" (dict "Header" "rfc5424" "Priority" 14 "Timestamp" .Timestamp "Host" "fw01" "AppName" "Ware")}}CEF:0`,
+			want: "<14>1 2024-03-05T07:08:09.123456Z fw01 Ware - - CEF:0",
+		},
+		"cef syslog none": {
+			text: `{{template "cef_syslog_header" (dict "Header" "none" "Priority" 14 "Timestamp" .Timestamp "Host" "fw01")}}CEF:0`,
+			want: "CEF:0",
+		},
+		"cef syslog no header": {
+			text: `{{template "cef_syslog_header" (dict "Priority" 14 "Timestamp" .Timestamp "Host" "fw01")}}CEF:0`,
+			want: "CEF:0",
+		},
+		"kv_pairs empty": {
+			text: `[{{template "kv_pairs" (pairs)}}]`,
+			want: "[]",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := NewTemplate(name, tc.text)
+			assert.Nil(t, err)
+			var buf bytes.Buffer
+			err = tmpl.Execute(&buf, &lintGenerator{SrcAddr: "10.0.0.1", SrcPort: 443, Timestamp: ts})
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, buf.String())
+		})
+	}
+}
+
+func TestPartialsErrors(t *testing.T) {
+	for _, text := range []string{`{{template "cef_header" (dict "Vendor")}}`, `{{template "cef_header" (dict 1 2)}}`, `{{template "kv_pairs" (pairs "src")}}`} {
+		tmpl, err := NewTemplate("event", text)
+		assert.Nil(t, err)
+		var buf bytes.Buffer
+		assert.NotNil(t, tmpl.Execute(&buf, nil), text)
+	}
+}
+
+func TestLintPartials(t *testing.T) {
+	tmpl, err := NewTemplate("event", `{{template "syslog_header" (dict "Timestamp" .Timestamp "Host" .Missing)}}{{template "kv_pairs" (pairs "src" .SrcAddr "spt" .SrcPort)}}`)
+	assert.Nil(t, err)
+	g := &lintGenerator{templates: []*template.Template{tmpl}}
+
+	issues, err := Lint(g, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []Issue{
+		{Field: "Geo", Message: "is not used by any template", Warning: true},
+		{Field: "Never", Message: "is not used by any template", Warning: true},
+		{Field: "Unused", Message: "is not used by any template", Warning: true},
+		{Template: "event", Field: "Missing", Message: "is not a field of generator.lintGenerator"},
+	}, issues)
+}
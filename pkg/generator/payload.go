@@ -0,0 +1,172 @@
+package generator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+const (
+	// payloadAlphabet is the alphabet of the random tokens of a
+	// payload.
+	payloadAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	// payloadWords is the size of the vocabulary of the repetitive
+	// text of a payload.
+	payloadWords = 8
+	// payloadSamples is the number of payloads the ratio of an entropy
+	// is measured on.
+	payloadSamples = 200
+	// payloadStream is the stream of the payload wrapper, see
+	// newWrapperRand.
+	payloadStream = 2
+)
+
+type payloadOptions struct {
+	Payload *payloadConfig `config:"payload"`
+}
+
+type payloadConfig struct {
+	Length  int      `config:"length"`
+	Entropy *float64 `config:"entropy"`
+	Ratio   float64  `config:"ratio"`
+}
+
+func (c *payloadConfig) Validate() error {
+	if c.Length < 1 {
+		return fmt.Errorf("'%d' is not a valid value for 'length' expected a length greater than 0", c.Length)
+	}
+	if c.Entropy != nil && c.Ratio != 0 {
+		return fmt.Errorf("you must specify entropy or ratio, not both")
+	}
+	if c.Entropy != nil && (*c.Entropy < 0 || *c.Entropy > 1) {
+		return fmt.Errorf("'%v' is not a valid value for 'entropy' expected a value from 0 to 1", *c.Entropy)
+	}
+	if c.Ratio != 0 && c.Ratio <= 1 {
+		return fmt.Errorf("'%v' is not a valid value for 'ratio' expected a ratio greater than 1", c.Ratio)
+	}
+	return nil
+}
+
+// payloadGenerator adds a payload of text of a tuned entropy to the
+// events of a generator, see New.
+type payloadGenerator struct {
+	Generator
+	rand    *rand.Rand
+	length  int
+	entropy float64
+	words   []string
+}
+
+// newPayload returns g with the payload of the "payload" option in the
+// ucfg.Config, g itself without the option.
+func newPayload(g Generator, cfg *ucfg.Config) (Generator, error) {
+	c := payloadOptions{}
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	if c.Payload == nil {
+		return g, nil
+	}
+	if IsBinary(g) {
+		return nil, fmt.Errorf("'payload' can not be used with binary events")
+	}
+	r, err := newWrapperRand(cfg, payloadStream)
+	if err != nil {
+		return nil, err
+	}
+	p := &payloadGenerator{Generator: g, rand: r, length: c.Payload.Length, entropy: 0.5}
+	for len(p.words) < payloadWords {
+		if w := random.Word(r); !contains(p.words, w) {
+			p.words = append(p.words, w)
+		}
+	}
+	switch {
+	case c.Payload.Entropy != nil:
+		p.entropy = *c.Payload.Entropy
+	case c.Payload.Ratio != 0:
+		if p.entropy, err = p.calibrate(c.Payload.Ratio); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// calibrate returns the entropy of the payloads whose gzip ratio is
+// ratio.  The ratio falls as the entropy grows, so it is a bisection.
+func (p *payloadGenerator) calibrate(ratio float64) (float64, error) {
+	max, min := p.ratio(0), p.ratio(1)
+	if ratio > max || ratio < min {
+		return 0, fmt.Errorf("'%v' is not a valid value for 'ratio' expected a ratio from %.1f to %.1f for a length of %d", ratio, min, max, p.length)
+	}
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 20; i++ {
+		mid := (lo + hi) / 2
+		if p.ratio(mid) > ratio {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2, nil
+}
+
+// ratio returns the gzip ratio of the payloads of the entropy, as they
+// are in a file of them, one per line.  The payloads are those of a
+// fixed seed, so the ratio of an entropy is always the same.
+func (p *payloadGenerator) ratio(entropy float64) float64 {
+	r := rand.New(rand.NewChaCha8([32]byte{1}))
+	var raw bytes.Buffer
+	for i := 0; i < payloadSamples; i++ {
+		raw.WriteString(p.text(r, entropy))
+		raw.WriteByte('\n')
+	}
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	w.Write(raw.Bytes())
+	w.Close()
+	return float64(raw.Len()) / float64(compressed.Len())
+}
+
+// text returns a payload of length bytes of words, where each word is
+// a random token with the probability of the entropy, or one of the
+// few words of the vocabulary.
+func (p *payloadGenerator) text(r *rand.Rand, entropy float64) string {
+	var b strings.Builder
+	for b.Len() < p.length {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		if r.Float64() >= entropy {
+			b.WriteString(p.words[r.IntN(len(p.words))])
+			continue
+		}
+		for i := 4 + r.IntN(9); i > 0; i-- {
+			b.WriteByte(payloadAlphabet[r.IntN(len(payloadAlphabet))])
+		}
+	}
+	return b.String()[:p.length]
+}
+
+func (p *payloadGenerator) Next() ([]byte, error) {
+	b, err := p.Generator.Next()
+	if err != nil {
+		return b, err
+	}
+	text := p.text(p.rand, p.entropy)
+	if bytes.HasPrefix(b, []byte("{")) {
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.UseNumber()
+		var doc map[string]interface{}
+		if err := d.Decode(&doc); err == nil && doc != nil {
+			doc["payload"] = text
+			return json.Marshal(doc)
+		}
+	}
+	return append(append(b[:len(b):len(b)], ' '), text...), nil
+}
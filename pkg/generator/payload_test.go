@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func payloaded(t *testing.T, g Generator, c map[string]interface{}) (Generator, error) {
+	t.Helper()
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": "test", "seed": 1, "payload": c})
+	assert.Nil(t, err)
+	return newPayload(g, cfg)
+}
+
+// ratio returns the gzip ratio of n events of g, one per line.
+func ratio(t *testing.T, g Generator, n int) float64 {
+	t.Helper()
+	var raw, compressed bytes.Buffer
+	for i := 0; i < n; i++ {
+		b, err := g.Next()
+		assert.Nil(t, err)
+		raw.Write(b)
+		raw.WriteByte('\n')
+	}
+	w := gzip.NewWriter(&compressed)
+	w.Write(raw.Bytes())
+	w.Close()
+	return float64(raw.Len()) / float64(compressed.Len())
+}
+
+func TestPayload(t *testing.T) {
+	g, err := payloaded(t, &csvGenerator{event: testEvent}, map[string]interface{}{"length": 100})
+	assert.Nil(t, err)
+	b, err := g.Next()
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(b), testEvent+" "))
+	assert.Len(t, b, len(testEvent)+1+100)
+
+	g, err = payloaded(t, &csvGenerator{event: testDocument}, map[string]interface{}{"length": 100})
+	assert.Nil(t, err)
+	b, err = g.Next()
+	assert.Nil(t, err)
+	var doc map[string]interface{}
+	assert.Nil(t, json.Unmarshal(b, &doc))
+	assert.Len(t, doc["payload"], 100)
+	assert.Contains(t, string(b), `"bytes":1234567890123`)
+}
+
+func TestPayloadStream(t *testing.T) {
+	g, err := payloaded(t, &csvGenerator{event: testEvent}, map[string]interface{}{"length": 100})
+	assert.Nil(t, err)
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": "test", "seed": 1})
+	assert.Nil(t, err)
+	r, err := NewRand(cfg)
+	assert.Nil(t, err)
+	assert.NotEqual(t, random.Word(r), g.(*payloadGenerator).words[0])
+}
+
+func TestPayloadEntropy(t *testing.T) {
+	low, err := payloaded(t, &csvGenerator{}, map[string]interface{}{"length": 512, "entropy": 0})
+	assert.Nil(t, err)
+	high, err := payloaded(t, &csvGenerator{}, map[string]interface{}{"length": 512, "entropy": 1})
+	assert.Nil(t, err)
+	assert.Greater(t, ratio(t, low, 500), 3*ratio(t, high, 500))
+}
+
+func TestPayloadRatio(t *testing.T) {
+	for _, target := range []float64{1.5, 2, 4} {
+		g, err := payloaded(t, &csvGenerator{}, map[string]interface{}{"length": 512, "ratio": target})
+		assert.Nil(t, err)
+		assert.InEpsilon(t, target, ratio(t, g, 1000), 0.1)
+	}
+}
+
+func TestPayloadErrors(t *testing.T) {
+	_, err := payloaded(t, &csvGenerator{binary: true}, map[string]interface{}{"length": 100})
+	assert.EqualError(t, err, "'payload' can not be used with binary events")
+
+	_, err = payloaded(t, &csvGenerator{}, map[string]interface{}{"length": 100, "ratio": 50})
+	assert.Error(t, err)
+	assert.Regexp(t, "^'50' is not a valid value for 'ratio' expected a ratio from 1.0 to [0-9.]+ for a length of 100$", err.Error())
+
+	tests := map[string]struct {
+		c           map[string]interface{}
+		errorString string
+	}{
+		"No Length": {
+			c:           map[string]interface{}{"entropy": 0.5},
+			errorString: "'0' is not a valid value for 'length' expected a length greater than 0 accessing 'payload'",
+		},
+		"Invalid Entropy": {
+			c:           map[string]interface{}{"length": 10, "entropy": 2},
+			errorString: "'2' is not a valid value for 'entropy' expected a value from 0 to 1 accessing 'payload'",
+		},
+		"Invalid Ratio": {
+			c:           map[string]interface{}{"length": 10, "ratio": 0.5},
+			errorString: "'0.5' is not a valid value for 'ratio' expected a ratio greater than 1 accessing 'payload'",
+		},
+		"Entropy and Ratio": {
+			c:           map[string]interface{}{"length": 10, "entropy": 0.5, "ratio": 2},
+			errorString: "you must specify entropy or ratio, not both accessing 'payload'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			_, err := payloaded(t, &csvGenerator{}, tc.c)
+			assert.EqualError(t, err, tc.errorString)
+		})
+	}
+}
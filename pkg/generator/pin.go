@@ -0,0 +1,324 @@
+package generator
+
+import (
+	"encoding"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+type pinConfig struct {
+	Pin     map[string]interface{} `config:"pin"`
+	Empty   map[string]interface{} `config:"empty"`
+	Unicode map[string]interface{} `config:"unicode"`
+}
+
+// Pins are the fields of a generator that are pinned with the "pin"
+// option, to a value or to a list of values one is picked from for
+// every event.  The names are the names of the exported fields of the
+// generator, with a dot for fields of nested structs:
+//
+//	generator:
+//	  type: fortinet:firewall
+//	  pin:
+//	    Vd: corp
+//	    DevName: [FGT-HQ-01, FGT-HQ-02]
+//	    Level: [alert, critical, emergency]
+//
+// Pinned fields are set after the generator picked its random values,
+// so templates use the pinned values.  Values derived from a pinned
+// field when it was picked, like a message that includes it, are not
+// changed.
+//
+// The "empty" option sets the percentage of events an optional field
+// is left empty in, its zero value.  Generators set the default
+// percentages of their optional fields with Optional:
+//
+//	generator:
+//	  type: citrix:cef
+//	  empty:
+//	    Geo: 50
+//	    ViolationCategory: 0
+//
+// The "unicode" option sets the percentage of events a string field
+// has non-ASCII content in, accented, CJK and right-to-left names and
+// words and internationalized host names of the shape of its value,
+// see random.Localize, to test the UTF-8 handling of a pipeline:
+//
+//	generator:
+//	  type: fortinet:firewall
+//	  unicode:
+//	    User: 10
+//	    QueryName: 5
+type Pins struct {
+	rand    *rand.Rand
+	t       reflect.Type
+	pins    []pin
+	empty   []empty
+	unicode []empty
+}
+
+type pin struct {
+	name   string
+	index  []int
+	values []reflect.Value
+}
+
+type empty struct {
+	name  string
+	index []int
+	zero  reflect.Value
+	rate  float64
+}
+
+// NewPins returns the Pins of the "pin", "empty" and "unicode" options in the
+// ucfg.Config for the generator v, a pointer to a struct.  An error
+// is returned for names that are not exported fields of v, or values
+// that can not be converted to the type of their field.
+func NewPins(cfg *ucfg.Config, r *rand.Rand, v interface{}) (*Pins, error) {
+	c := pinConfig{}
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	p := &Pins{rand: r, t: reflect.TypeOf(v).Elem()}
+
+	fields := make(map[string]interface{})
+	flatten("", c.Pin, fields)
+	for _, name := range sorted(fields) {
+		index, ft, err := field(p.t, "pin", name)
+		if err != nil {
+			return nil, err
+		}
+		values, ok := fields[name].([]interface{})
+		if !ok {
+			values = []interface{}{fields[name]}
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("'pin.%s' has no values", name)
+		}
+		pn := pin{name: name, index: index}
+		for _, value := range values {
+			rv, err := convert(value, ft)
+			if err != nil {
+				return nil, fmt.Errorf("'%v' is not a valid value for 'pin.%s' expected %s", value, name, ft)
+			}
+			pn.values = append(pn.values, rv)
+		}
+		p.pins = append(p.pins, pn)
+	}
+
+	rates := make(map[string]interface{})
+	flatten("", c.Empty, rates)
+	for _, name := range sorted(rates) {
+		if _, ok := fields[name]; ok {
+			return nil, fmt.Errorf("'%s' can not be in both 'pin' and 'empty'", name)
+		}
+		rate, err := strconv.ParseFloat(fmt.Sprint(rates[name]), 64)
+		if err != nil || rate < 0 || rate > 100 {
+			return nil, fmt.Errorf("'%v' is not a valid value for 'empty.%s' expected a percentage from 0 to 100", rates[name], name)
+		}
+		if err := p.addEmpty(name, rate); err != nil {
+			return nil, err
+		}
+	}
+
+	rates = make(map[string]interface{})
+	flatten("", c.Unicode, rates)
+	for _, name := range sorted(rates) {
+		rate, err := strconv.ParseFloat(fmt.Sprint(rates[name]), 64)
+		if err != nil || rate < 0 || rate > 100 {
+			return nil, fmt.Errorf("'%v' is not a valid value for 'unicode.%s' expected a percentage from 0 to 100", rates[name], name)
+		}
+		index, ft, err := field(p.t, "unicode", name)
+		if err != nil {
+			return nil, err
+		}
+		if ft.Kind() != reflect.String {
+			return nil, fmt.Errorf("'%s' is not a valid value for 'unicode' expected a field of a string", name)
+		}
+		p.unicode = append(p.unicode, empty{name: name, index: index, rate: rate})
+	}
+	return p, nil
+}
+
+// Optional sets the default percentages of events the optional fields
+// are left empty in, for the fields the "empty" option does not set.
+// It returns an error for names that are not fields of the generator.
+func (p *Pins) Optional(rates map[string]float64) error {
+	set := make(map[string]bool)
+	for _, e := range p.empty {
+		set[e.name] = true
+	}
+	for _, pn := range p.pins {
+		set[pn.name] = true
+	}
+	names := make([]string, 0, len(rates))
+	for name := range rates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if set[name] {
+			continue
+		}
+		if err := p.addEmpty(name, rates[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Pins) addEmpty(name string, rate float64) error {
+	index, ft, err := field(p.t, "empty", name)
+	if err != nil {
+		return err
+	}
+	p.empty = append(p.empty, empty{name: name, index: index, zero: reflect.Zero(ft), rate: rate})
+	return nil
+}
+
+// Apply sets the pinned fields of v, the pointer to the struct the
+// Pins were created for.
+func (p *Pins) Apply(v interface{}) {
+	if p == nil || len(p.pins)+len(p.empty)+len(p.unicode) == 0 {
+		return
+	}
+	s := reflect.ValueOf(v).Elem()
+	for _, pn := range p.pins {
+		value := pn.values[0]
+		if len(pn.values) > 1 {
+			value = pn.values[p.rand.IntN(len(pn.values))]
+		}
+		set(s, pn.index, value)
+	}
+	for _, e := range p.empty {
+		if p.rand.Float64()*100 < e.rate {
+			set(s, e.index, e.zero)
+		}
+	}
+	for _, u := range p.unicode {
+		if p.rand.Float64()*100 >= u.rate {
+			continue
+		}
+		if f, err := s.FieldByIndexErr(u.index); err == nil && f.String() != "" {
+			f.SetString(random.Localize(p.rand, f.String()))
+		}
+	}
+}
+
+// set sets the field of s at index.  Fields of nil embedded pointers
+// are left alone.
+func set(s reflect.Value, index []int, value reflect.Value) {
+	if f, err := s.FieldByIndexErr(index); err == nil {
+		f.Set(value)
+	}
+}
+
+func sorted(m map[string]interface{}) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// flatten adds the values of m to fields, with the names of nested
+// maps joined by dots.
+func flatten(prefix string, m map[string]interface{}, fields map[string]interface{}) {
+	for k, v := range m {
+		if nested, ok := v.(map[string]interface{}); ok {
+			flatten(prefix+k+".", nested, fields)
+			continue
+		}
+		fields[prefix+k] = v
+	}
+}
+
+// field returns the index and type of the dotted name of the option
+// in the struct t.
+func field(t reflect.Type, option, name string) ([]int, reflect.Type, error) {
+	var index []int
+	for _, part := range strings.Split(name, ".") {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return nil, nil, fmt.Errorf("'%s' is not a valid value for '%s' expected a field of a struct", name, option)
+		}
+		f, ok := t.FieldByName(part)
+		if !ok || !f.IsExported() {
+			return nil, nil, fmt.Errorf("'%s' is not a valid value for '%s' expected a field of %s", name, option, t)
+		}
+		index = append(index, f.Index...)
+		t = f.Type
+	}
+	return index, t, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// convert converts a config value to t.  A pointer points to the
+// converted value.
+func convert(value interface{}, t reflect.Type) (reflect.Value, error) {
+	if t.Kind() == reflect.Pointer {
+		v, err := convert(value, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(v)
+		return p, nil
+	}
+	s := fmt.Sprint(value)
+	if reflect.PointerTo(t).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) {
+		rv := reflect.New(t)
+		if err := rv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return reflect.Value{}, err
+		}
+		return rv.Elem(), nil
+	}
+	if t == durationType {
+		d, err := time.ParseDuration(s)
+		return reflect.ValueOf(d), err
+	}
+	rv := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		rv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		rv.SetFloat(f)
+	default:
+		return reflect.Value{}, fmt.Errorf("can not pin a %s", t)
+	}
+	return rv, nil
+}
@@ -0,0 +1,228 @@
+package generator
+
+import (
+	"math/rand/v2"
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+type pinGenerator struct {
+	Vd       string
+	Level    string
+	Severity int
+	Port     uint16
+	Blocked  bool
+	Score    float64
+	SrcIP    net.IP
+	Duration time.Duration
+	Geo      geo
+	Location *geo
+
+	devname string
+}
+
+func TestPins(t *testing.T) {
+	cfg, err := ucfg.NewFrom(map[string]interface{}{
+		"type": "test",
+		"pin": map[string]interface{}{
+			"Vd":       "corp",
+			"Level":    []interface{}{"alert", "critical"},
+			"Severity": 7,
+			"Port":     "514",
+			"Blocked":  true,
+			"Score":    9.5,
+			"SrcIP":    "10.1.2.3",
+			"Duration": "1m30s",
+			"Geo.City": "Utrecht",
+			"Location": map[string]interface{}{"City": "Delft"},
+		},
+	}, ucfg.PathSep("."))
+	assert.Nil(t, err)
+
+	g := &pinGenerator{Location: &geo{}}
+	p, err := NewPins(cfg, rand.New(rand.NewChaCha8([32]byte{1})), g)
+	assert.Nil(t, err)
+
+	levels := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		p.Apply(g)
+		levels[g.Level] = true
+	}
+	assert.Equal(t, map[string]bool{"alert": true, "critical": true}, levels)
+	assert.Equal(t, "corp", g.Vd)
+	assert.Equal(t, 7, g.Severity)
+	assert.Equal(t, uint16(514), g.Port)
+	assert.True(t, g.Blocked)
+	assert.Equal(t, 9.5, g.Score)
+	assert.Equal(t, "10.1.2.3", g.SrcIP.String())
+	assert.Equal(t, 90*time.Second, g.Duration)
+	assert.Equal(t, "Utrecht", g.Geo.City)
+	assert.Equal(t, "Delft", g.Location.City)
+
+	// No pin leaves the generator alone.
+	cfg, err = ucfg.NewFrom(map[string]interface{}{"type": "test"})
+	assert.Nil(t, err)
+	p, err = NewPins(cfg, rand.New(rand.NewChaCha8([32]byte{1})), g)
+	assert.Nil(t, err)
+	g.Vd = "root"
+	p.Apply(g)
+	assert.Equal(t, "root", g.Vd)
+}
+
+func TestPinsErrors(t *testing.T) {
+	tests := map[string]struct {
+		pin         map[string]interface{}
+		errorString string
+	}{
+		"Unknown Field": {
+			pin:         map[string]interface{}{"Missing": "x"},
+			errorString: "'Missing' is not a valid value for 'pin' expected a field of generator.pinGenerator",
+		},
+		"Unexported Field": {
+			pin:         map[string]interface{}{"devname": "x"},
+			errorString: "'devname' is not a valid value for 'pin' expected a field of generator.pinGenerator",
+		},
+		"Unknown Nested Field": {
+			pin:         map[string]interface{}{"Geo": map[string]interface{}{"Country": "NL"}},
+			errorString: "'Geo.Country' is not a valid value for 'pin' expected a field of generator.geo",
+		},
+		"Field Of String": {
+			pin:         map[string]interface{}{"Vd": map[string]interface{}{"Name": "corp"}},
+			errorString: "'Vd.Name' is not a valid value for 'pin' expected a field of a struct",
+		},
+		"Invalid Int": {
+			pin:         map[string]interface{}{"Severity": []interface{}{7, "high"}},
+			errorString: "'high' is not a valid value for 'pin.Severity' expected int",
+		},
+		"Invalid IP": {
+			pin:         map[string]interface{}{"SrcIP": "10.1.2"},
+			errorString: "'10.1.2' is not a valid value for 'pin.SrcIP' expected net.IP",
+		},
+		"No Values": {
+			pin:         map[string]interface{}{"Level": []interface{}{}},
+			errorString: "'pin.Level' has no values",
+		},
+	}
+	for name, tc := range tests {
+		cfg, err := ucfg.NewFrom(map[string]interface{}{"type": "test", "pin": tc.pin})
+		assert.Nil(t, err, name)
+		_, err = NewPins(cfg, rand.New(rand.NewChaCha8([32]byte{1})), &pinGenerator{})
+		if assert.Error(t, err, name) {
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+	}
+}
+
+func TestPinsEmpty(t *testing.T) {
+	cfg, err := ucfg.NewFrom(map[string]interface{}{
+		"type":  "test",
+		"pin":   map[string]interface{}{"Vd": "corp"},
+		"empty": map[string]interface{}{"Level": 50, "Geo": map[string]interface{}{"City": 0}},
+	}, ucfg.PathSep("."))
+	assert.Nil(t, err)
+
+	g := &pinGenerator{}
+	p, err := NewPins(cfg, rand.New(rand.NewChaCha8([32]byte{1})), g)
+	assert.Nil(t, err)
+	// Defaults do not override the configuration, or pinned fields.
+	assert.Nil(t, p.Optional(map[string]float64{"Level": 100, "Geo.City": 100, "Vd": 100, "SrcIP": 100}))
+
+	const n = 1000
+	empty := 0
+	for i := 0; i < n; i++ {
+		g.Level, g.Geo.City, g.SrcIP = "alert", "Utrecht", net.IPv4(10, 1, 2, 3)
+		p.Apply(g)
+		if g.Level == "" {
+			empty++
+		}
+		assert.Equal(t, "corp", g.Vd)
+		assert.Equal(t, "Utrecht", g.Geo.City)
+		assert.Nil(t, g.SrcIP)
+	}
+	assert.InDelta(t, n/2, empty, n/10)
+
+	assert.Error(t, p.Optional(map[string]float64{"Missing": 10}))
+
+	tests := map[string]struct {
+		c           map[string]interface{}
+		errorString string
+	}{
+		"Invalid Rate": {
+			c:           map[string]interface{}{"empty": map[string]interface{}{"Level": 150}},
+			errorString: "'150' is not a valid value for 'empty.Level' expected a percentage from 0 to 100",
+		},
+		"Unknown Field": {
+			c:           map[string]interface{}{"empty": map[string]interface{}{"Missing": 10}},
+			errorString: "'Missing' is not a valid value for 'empty' expected a field of generator.pinGenerator",
+		},
+		"Pinned And Empty": {
+			c:           map[string]interface{}{"pin": map[string]interface{}{"Level": "alert"}, "empty": map[string]interface{}{"Level": 10}},
+			errorString: "'Level' can not be in both 'pin' and 'empty'",
+		},
+	}
+	for name, tc := range tests {
+		cfg, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = NewPins(cfg, rand.New(rand.NewChaCha8([32]byte{1})), &pinGenerator{})
+		if assert.Error(t, err, name) {
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+	}
+}
+
+func TestPinsUnicode(t *testing.T) {
+	cfg, err := ucfg.NewFrom(map[string]interface{}{
+		"type":    "test",
+		"pin":     map[string]interface{}{"Vd": "web03.example.com"},
+		"unicode": map[string]interface{}{"Vd": 100, "Level": 30, "Geo": map[string]interface{}{"City": 0}},
+	})
+	assert.Nil(t, err)
+
+	g := &pinGenerator{}
+	p, err := NewPins(cfg, rand.New(rand.NewChaCha8([32]byte{1})), g)
+	assert.Nil(t, err)
+
+	const n = 1000
+	localized := 0
+	for i := 0; i < n; i++ {
+		g.Level, g.Geo.City = "alert", "Utrecht"
+		p.Apply(g)
+		// The pinned value is localized, and keeps its shape.
+		assert.True(t, utf8.ValidString(g.Vd))
+		assert.True(t, strings.HasSuffix(g.Vd, ".example.com"), g.Vd)
+		assert.NotEqual(t, "web03.example.com", g.Vd)
+		if g.Level != "alert" {
+			assert.True(t, utf8.ValidString(g.Level))
+			assert.Greater(t, len(g.Level), utf8.RuneCountInString(g.Level), g.Level)
+			localized++
+		}
+		assert.Equal(t, "Utrecht", g.Geo.City)
+	}
+	assert.InDelta(t, n*3/10, localized, n/10)
+
+	tests := map[string]struct {
+		unicode     map[string]interface{}
+		errorString string
+	}{
+		"Invalid Rate": {
+			unicode:     map[string]interface{}{"Level": -1},
+			errorString: "'-1' is not a valid value for 'unicode.Level' expected a percentage from 0 to 100",
+		},
+		"Not A String": {
+			unicode:     map[string]interface{}{"SrcIP": 10},
+			errorString: "'SrcIP' is not a valid value for 'unicode' expected a field of a string",
+		},
+	}
+	for name, tc := range tests {
+		cfg, err := ucfg.NewFrom(map[string]interface{}{"type": "test", "unicode": tc.unicode})
+		assert.Nil(t, err, name)
+		_, err = NewPins(cfg, rand.New(rand.NewChaCha8([32]byte{1})), &pinGenerator{})
+		assert.EqualError(t, err, tc.errorString, name)
+	}
+}
@@ -0,0 +1,20 @@
+package generator
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// LoadPlugin opens the Go plugin at path.  The plugin registers its
+// generators with Register from its init functions, so once loaded
+// they can be used like the built in generators.
+//
+// Plugins must be built with "go build -buildmode=plugin" against the
+// same version of spigot and Go as the spigot binary.  Go plugins are
+// only supported on Linux, FreeBSD and macOS.
+func LoadPlugin(path string) error {
+	if _, err := plugin.Open(path); err != nil {
+		return fmt.Errorf("Error loading plugin '%s': %w", path, err)
+	}
+	return nil
+}
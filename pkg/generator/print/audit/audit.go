@@ -0,0 +1,295 @@
+// Package audit generates print server audit log messages.
+//
+// Two kinds of messages are generated.  PrintService messages are the
+// Windows Event XML for event 307 "Document printed" from the
+// Microsoft-Windows-PrintService/Operational channel.  CUPS messages
+// are lines from the CUPS page_log in the default PageLogFormat.
+// Document names include sensitive looking files and page counts are
+// occasionally large, for insider threat detection scenarios.
+//
+// Configuration:
+//
+//	event_type: Specify the type of event to generate, or leave blank for random.
+//	            Valid values are: printservice, cups.
+//
+//	- generator:
+//	    type: print:audit
+//	    event_type: printservice
+package audit
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "print:audit"
+
+const (
+	EventTypePrintService = "printservice"
+	EventTypeCUPS         = "cups"
+)
+
+const (
+	eventNamespace   = "http://schemas.microsoft.com/win/2004/08/events/event"
+	spoolerNamespace = "http://manifests.microsoft.com/win/2005/08/windows/printing/spooler/core/events"
+	providerName     = "Microsoft-Windows-PrintService"
+	providerGUID     = "{747EF6FD-E535-4D16-B510-42C90F6873A1}"
+	channel          = "Microsoft-Windows-PrintService/Operational"
+)
+
+var (
+	cupsTemplate = "{{.Printer}} {{.Username}} {{.JobID}} [{{.Timestamp.Format \"02/Jan/2006:15:04:05 -0700\"}}] total {{.Pages}} - {{.ClientAddr}} {{.Document}} {{.Media}} {{.Sides}}"
+	printers     = [...]struct {
+		name string
+		port string
+	}{
+		{name: "HP LaserJet M607 Floor2", port: "IP_10.20.2.15"},
+		{name: "Canon iR-ADV C5535 Finance", port: "IP_10.20.3.21"},
+		{name: "Xerox VersaLink B405 HR", port: "IP_10.20.4.30"},
+		{name: "Brother HL-L6200DW Legal", port: "USB001"},
+		{name: "Microsoft Print to PDF", port: "PORTPROMPT:"},
+	}
+	documents = [...]string{
+		"Quarterly Report.pdf",
+		"Meeting Notes.docx",
+		"Boarding Pass.pdf",
+		"Invoice 10442.pdf",
+		"salaries_2024.xlsx",
+		"customer_list_export.csv",
+		"Confidential - Merger Plan.docx",
+		"source_code_review.txt",
+		"Employee SSN Roster.xlsx",
+		"https://intranet.corp.example.com/wiki/Roadmap",
+	}
+	usernames = [...]string{"jdoe", "asmith", "bwilson", "cjohnson", "mgarcia", "tnguyen"}
+	media     = [...]string{"na_letter_8.5x11in", "na_legal_8.5x14in", "iso_a4_210x297mm"}
+	sides     = [...]string{"one-sided", "two-sided-long-edge"}
+)
+
+// Event holds the fields for a PrintService 307 event.
+type Event struct {
+	XMLName xml.Name `xml:"Event"`
+	XMLNS   string   `xml:"xmlns,attr"`
+
+	Provider      Provider    `xml:"System>Provider"`
+	EventID       int         `xml:"System>EventID"`
+	Version       int         `xml:"System>Version"`
+	Level         int         `xml:"System>Level"`
+	Task          int         `xml:"System>Task"`
+	Opcode        int         `xml:"System>Opcode"`
+	Keywords      string      `xml:"System>Keywords"`
+	TimeCreated   TimeCreated `xml:"System>TimeCreated"`
+	EventRecordID int         `xml:"System>EventRecordID"`
+	Execution     Execution   `xml:"System>Execution"`
+	Channel       string      `xml:"System>Channel"`
+	Computer      string      `xml:"System>Computer"`
+	Security      Security    `xml:"System>Security"`
+
+	DocumentPrinted DocumentPrinted `xml:"UserData>DocumentPrinted"`
+}
+
+// Provider identifies the provider that logged the event.
+type Provider struct {
+	Name string `xml:"Name,attr"`
+	GUID string `xml:"Guid,attr"`
+}
+
+// TimeCreated contains the system time of when the event was logged.
+type TimeCreated struct {
+	SystemTime time.Time `xml:"SystemTime,attr"`
+}
+
+// Execution contains the process and thread that logged the event.
+type Execution struct {
+	ProcessID int `xml:"ProcessID,attr"`
+	ThreadID  int `xml:"ThreadID,attr"`
+}
+
+// Security contains the SID of the user that printed the document.
+type Security struct {
+	UserID string `xml:"UserID,attr"`
+}
+
+// DocumentPrinted is the user data of a 307 event.  Param1 is the
+// job ID, Param2 the document name, Param3 the owner, Param4 the
+// client, Param5 the printer, Param6 the port, Param7 the size in
+// bytes and Param8 the pages printed.
+type DocumentPrinted struct {
+	XMLNS  string `xml:"xmlns,attr"`
+	Param1 int    `xml:"Param1"`
+	Param2 string `xml:"Param2"`
+	Param3 string `xml:"Param3"`
+	Param4 string `xml:"Param4"`
+	Param5 string `xml:"Param5"`
+	Param6 string `xml:"Param6"`
+	Param7 int    `xml:"Param7"`
+	Param8 int    `xml:"Param8"`
+}
+
+// Audit holds the random fields for a print audit message.
+type Audit struct {
+	ClientAddr net.IP
+	Document   string
+	JobID      int
+	Media      string
+	Pages      int
+	Printer    string
+	Sides      string
+	Timestamp  time.Time
+	Username   string
+
+	Event Event
+
+	eventType  string
+	port       string
+	recordID   int
+	rand       *rand.Rand
+	pins       *generator.Pins
+	staticTime *time.Time
+	clock      *generator.Clock
+	cups       *template.Template
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Audit objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := template.New(EventTypeCUPS).Funcs(generator.FunctionMap).Parse(cupsTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Audit{
+		eventType: c.EventType,
+		recordID:  r.IntN(100000),
+		rand:      r,
+		clock:     clock,
+		cups:      t,
+	}
+	a.pins, err = generator.NewPins(cfg, r, a)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Next produces the next print audit message.
+//
+// Example:
+//
+// HP_LaserJet_M607_Floor2 jdoe 1042 [02/Jan/2006:15:04:05 +0000] total 3 - 10.20.2.101 Quarterly_Report.pdf na_letter_8.5x11in one-sided
+func (a *Audit) Next() ([]byte, error) {
+	eventType := a.eventType
+	if eventType == "" {
+		eventType = [...]string{EventTypePrintService, EventTypeCUPS}[a.rand.IntN(2)]
+	}
+
+	a.randomize()
+
+	if eventType == EventTypePrintService {
+		a.Event = a.event()
+		return xml.Marshal(&a.Event)
+	}
+
+	// CUPS fields are space separated.
+	a.Printer = strings.ReplaceAll(a.Printer, " ", "_")
+	a.Document = strings.ReplaceAll(a.Document, " ", "_")
+
+	var buf bytes.Buffer
+	if err := a.cups.Execute(&buf, a); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (a *Audit) randomize() {
+	p := printers[a.rand.IntN(len(printers))]
+
+	a.Timestamp = a.getTime()
+	a.Printer = p.name
+	a.port = p.port
+	a.Username = usernames[a.rand.IntN(len(usernames))]
+	a.Document = documents[a.rand.IntN(len(documents))]
+	a.JobID = a.rand.IntN(10000) + 1
+	a.ClientAddr = net.IPv4(10, 20, byte(a.rand.IntN(5)+1), byte(a.rand.IntN(254)+1))
+	a.Media = media[a.rand.IntN(len(media))]
+	a.Sides = sides[a.rand.IntN(len(sides))]
+	a.Pages = a.rand.IntN(20) + 1
+	if a.rand.IntN(20) == 0 {
+		a.Pages = a.rand.IntN(900) + 100
+	}
+
+	a.pins.Apply(a)
+}
+
+func (a *Audit) event() Event {
+	a.recordID++
+
+	return Event{
+		XMLNS:         eventNamespace,
+		Provider:      Provider{Name: providerName, GUID: providerGUID},
+		EventID:       307,
+		Level:         4,
+		Task:          26,
+		Opcode:        11,
+		Keywords:      "0x4000000000000840",
+		TimeCreated:   TimeCreated{SystemTime: a.Timestamp},
+		EventRecordID: a.recordID,
+		Execution:     Execution{ProcessID: a.rand.IntN(8000) + 1000, ThreadID: a.rand.IntN(8000) + 1000},
+		Channel:       channel,
+		Computer:      "PRINTSRV01.corp.example.com",
+		Security:      Security{UserID: fmt.Sprintf("S-1-5-21-%d-%d-%d-%d", a.rand.Int32(), a.rand.Int32(), a.rand.Int32(), a.rand.IntN(10000)+1000)},
+		DocumentPrinted: DocumentPrinted{
+			XMLNS:  spoolerNamespace,
+			Param1: a.JobID,
+			Param2: a.Document,
+			Param3: a.Username,
+			Param4: fmt.Sprintf("\\\\%s", a.ClientAddr),
+			Param5: a.Printer,
+			Param6: a.port,
+			Param7: a.Pages * (a.rand.IntN(200000) + 20000),
+			Param8: a.Pages,
+		},
+	}
+}
+
+func (a *Audit) getTime() time.Time {
+	if a.staticTime != nil {
+		return *a.staticTime
+	}
+
+	return a.clock.Now()
+}
+
+// Templates returns the templates of the generator, for generator.Lint.
+func (a *Audit) Templates() (interface{}, []*template.Template) {
+	return a, []*template.Template{a.cups}
+}
@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		eventType string
+		expected  string
+	}{
+		"PrintService": {eventType: EventTypePrintService, expected: `<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event"><System><Provider Name="Microsoft-Windows-PrintService" Guid="{747EF6FD-E535-4D16-B510-42C90F6873A1}"></Provider><EventID>307</EventID><Version>0</Version><Level>4</Level><Task>26</Task><Opcode>11</Opcode><Keywords>0x4000000000000840</Keywords><TimeCreated SystemTime="1970-01-02T03:04:05.123456Z"></TimeCreated><EventRecordID>87718</EventRecordID><Execution ProcessID="2232" ThreadID="1070"></Execution><Channel>Microsoft-Windows-PrintService/Operational</Channel><Computer>PRINTSRV01.corp.example.com</Computer><Security UserID="S-1-5-21-504890235-409566664-1392936630-9396"></Security></System><UserData><DocumentPrinted xmlns="http://manifests.microsoft.com/win/2005/08/windows/printing/spooler/core/events"><Param1>7418</Param1><Param2>Employee SSN Roster.xlsx</Param2><Param3>tnguyen</Param3><Param4>\\10.20.1.235</Param4><Param5>Xerox VersaLink B405 HR</Param5><Param6>IP_10.20.4.30</Param6><Param7>2371707</Param7><Param8>13</Param8></DocumentPrinted></UserData></Event>`},
+		"CUPS":         {eventType: EventTypeCUPS, expected: `Xerox_VersaLink_B405_HR tnguyen 7418 [02/Jan/1970:03:04:05 +0000] total 13 - 10.20.1.235 Employee_SSN_Roster.xlsx iso_a4_210x297mm two-sided-long-edge`},
+	}
+	testTime, err := time.Parse(time.RFC3339Nano, "1970-01-02T03:04:05.123456Z")
+	assert.Nil(t, err)
+	for name, tc := range tests {
+		random.Seed(1)
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "event_type": tc.eventType})
+		assert.Nil(t, err, name)
+		g, err := New(c)
+		assert.Nil(t, err, name)
+		a := g.(*Audit)
+		a.staticTime = &testTime
+		got, err := a.Next()
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, string(got), name)
+	}
+}
+
+func TestCUPSFields(t *testing.T) {
+	random.Seed(1)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "event_type": EventTypeCUPS})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+
+	for i := 0; i < 100; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		assert.Len(t, strings.Fields(string(got)), 12, string(got))
+	}
+}
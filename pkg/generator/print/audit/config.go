@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Type      string `config:"type" validate:"required"`
+	EventType string `config:"event_type"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type: Name,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if !(c.EventType == "" || c.EventType == EventTypePrintService || c.EventType == EventTypeCUPS) {
+		return fmt.Errorf("'%s' is not a valid value for 'event_type' expected '%s'", c.EventType, strings.Join([]string{EventTypePrintService, EventTypeCUPS}, ", "))
+	}
+
+	return nil
+}
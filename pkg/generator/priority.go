@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// levelSeverities are the syslog severities of the levels of the
+// messages, the keywords of syslog and the names the vendors write in
+// their level fields, such as error for err and information for info.
+var levelSeverities = map[string]int{
+	"emerg": 0, "emergency": 0, "panic": 0, "alert": 1, "crit": 2, "critical": 2, "err": 3, "error": 3, "warning": 4, "warn": 4,
+	"notice": 5, "info": 6, "information": 6, "informational": 6, "debug": 7,
+}
+
+// LevelSeverity returns the syslog severity, from 0 (emerg) to 7
+// (debug), of the level of a message, so the priority of its header
+// agrees with the level field, such as 3 for the level=error of a
+// Fortinet log.  It returns false for a level that is not of syslog.
+func LevelSeverity(level string) (int, bool) {
+	s, ok := levelSeverities[strings.ToLower(level)]
+	return s, ok
+}
+
+// ValidFacility returns an error if facility of option is not a
+// syslog facility, from 0 (kern) to 23 (local7).
+func ValidFacility(option string, facility int) error {
+	if facility < 0 || facility > 23 {
+		return fmt.Errorf("'%d' is not a valid value for '%s' expected a value from 0 to 23", facility, option)
+	}
+	return nil
+}
+
+// Priority returns the PRI of a syslog header, "<PRI>", of the
+// facility and the severity.
+func Priority(facility, severity int) int {
+	return facility*8 + severity
+}
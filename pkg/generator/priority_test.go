@@ -0,0 +1,30 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelSeverity(t *testing.T) {
+	for level, want := range map[string]int{"emergency": 0, "alert": 1, "critical": 2, "error": 3, "err": 3, "WARNING": 4, "notice": 5, "information": 6, "debug": 7} {
+		got, ok := LevelSeverity(level)
+		assert.True(t, ok, level)
+		assert.Equal(t, want, got, level)
+	}
+	_, ok := LevelSeverity("verbose")
+	assert.False(t, ok)
+}
+
+func TestValidFacility(t *testing.T) {
+	assert.Nil(t, ValidFacility("facility", 0))
+	assert.Nil(t, ValidFacility("facility", 23))
+	assert.EqualError(t, ValidFacility("facility", 24), "'24' is not a valid value for 'facility' expected a value from 0 to 23")
+	assert.EqualError(t, ValidFacility("facility", -1), "'-1' is not a valid value for 'facility' expected a value from 0 to 23")
+}
+
+func TestPriority(t *testing.T) {
+	assert.Equal(t, 0, Priority(0, 0))
+	assert.Equal(t, 187, Priority(23, 3))
+	assert.Equal(t, 134, Priority(16, 6))
+}
@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Type            string        `config:"type" validate:"required"`
+	Provider        string        `config:"provider"`
+	Secret          string        `config:"secret" validate:"required"`
+	SignatureHeader string        `config:"signature_header"`
+	Events          []eventSchema `config:"events"`
+}
+
+// eventSchema describes the fields of one webhook event type.  Each
+// field maps a name to the kind of random value it holds, see
+// fieldKinds.
+type eventSchema struct {
+	Type   string            `config:"type" validate:"required"`
+	Fields map[string]string `config:"fields"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:            Name,
+		Provider:        ProviderStripe,
+		SignatureHeader: "X-Signature",
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if !(c.Provider == ProviderStripe || c.Provider == ProviderGitHub || c.Provider == ProviderGeneric) {
+		return fmt.Errorf("'%s' is not a valid value for 'provider' expected '%s'", c.Provider, strings.Join([]string{ProviderStripe, ProviderGitHub, ProviderGeneric}, ", "))
+	}
+	for _, e := range c.Events {
+		for name, kind := range e.Fields {
+			if !validKind(kind) {
+				return fmt.Errorf("'%s' is not a valid kind for field '%s' of event '%s'", kind, name, e.Type)
+			}
+		}
+	}
+
+	return nil
+}
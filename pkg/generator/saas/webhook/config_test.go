@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name, "secret": "whsec_test"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with GitHub": {
+			c:           map[string]interface{}{"type": Name, "secret": "s3cr3t", "provider": "github"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Events": {
+			c: map[string]interface{}{"type": Name, "secret": "s3cr3t", "provider": "generic", "events": []map[string]interface{}{
+				{"type": "payment.settled", "fields": map[string]interface{}{"id": "id:pay_", "amount": "amount", "state": "enum:settled|pending"}},
+			}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "secret": "whsec_test"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'saas:webhook' accessing config",
+		},
+		"Invalid Provider": {
+			c:           map[string]interface{}{"type": Name, "secret": "whsec_test", "provider": "paypal"},
+			hasError:    true,
+			errorString: "'paypal' is not a valid value for 'provider' expected 'stripe, github, generic' accessing config",
+		},
+		"Invalid Field Kind": {
+			c: map[string]interface{}{"type": Name, "secret": "s3cr3t", "events": []map[string]interface{}{
+				{"type": "payment.settled", "fields": map[string]interface{}{"amount": "money"}},
+			}},
+			hasError:    true,
+			errorString: "'money' is not a valid kind for field 'amount' of event 'payment.settled' accessing config",
+		},
+		"Invalid Field Expression": {
+			c: map[string]interface{}{"type": Name, "secret": "s3cr3t", "events": []map[string]interface{}{
+				{"type": "payment.settled", "fields": map[string]interface{}{"risk": "expr:'high' if"}},
+			}},
+			hasError:    true,
+			errorString: "'expr:'high' if' is not a valid kind for field 'risk' of event 'payment.settled' accessing config",
+		},
+		"No Secret": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    true,
+			errorString: "string value is not set accessing 'secret'",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": "", "secret": "whsec_test"},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
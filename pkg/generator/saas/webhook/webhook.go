@@ -0,0 +1,326 @@
+// Package webhook generates signed JSON webhook deliveries from SaaS
+// and financial APIs such as Stripe and GitHub.
+//
+// Each message is a JSON envelope holding the HTTP headers and the
+// body of one webhook delivery:
+//
+//	{"body":"{\"id\":\"evt_...\",...}","headers":{"Content-Type":"application/json","Stripe-Signature":"t=...,v1=..."}}
+//
+// The signature header is computed over the body with the configured
+// secret, the same way the provider does, so receivers that verify
+// signatures accept the deliveries.  Use the http output with
+// "envelope: true" to send the body with these headers.
+//
+// Signatures by provider:
+//
+//	stripe:  Stripe-Signature: t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">
+//	github:  X-Hub-Signature-256: sha256=<hex HMAC-SHA256 of body>
+//	generic: <signature_header>: <hex HMAC-SHA256 of body>
+//
+// Each provider has a built in set of event schemas, these are
+// replaced by "events" when it is given.  A field kind is one of id,
+// id:<prefix>, amount, currency, email, ip, timestamp, bool, int,
+// uuid, enum:<value>|<value>|... or expr:<expression>.  An expr field
+// is computed by a Starlark expression from the other fields of the
+// event that are not expr fields, for example
+// "expr:'high' if amount > 100000 else 'low'".
+//
+// Configuration:
+//
+//	provider: One of stripe, github or generic, defaults to stripe.
+//	secret: Secret used to sign the deliveries, required.
+//	signature_header: Header for generic signatures, defaults to X-Signature.
+//	events: Optional list of event schemas, each with a type and fields.
+//
+//	- generator:
+//	    type: saas:webhook
+//	    provider: stripe
+//	    secret: whsec_0123456789
+//	    events:
+//	      - type: charge.refunded
+//	        fields:
+//	          id: "id:ch_"
+//	          amount_refunded: amount
+//	          currency: currency
+//	          reason: "enum:duplicate|fraudulent|requested_by_customer"
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/expr"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "saas:webhook"
+
+const (
+	ProviderStripe  = "stripe"
+	ProviderGitHub  = "github"
+	ProviderGeneric = "generic"
+)
+
+const (
+	stripeAPIVersion = "2023-10-16"
+	idChars          = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+var (
+	fieldKinds = map[string]bool{
+		"id":        true,
+		"amount":    true,
+		"currency":  true,
+		"email":     true,
+		"ip":        true,
+		"timestamp": true,
+		"bool":      true,
+		"int":       true,
+		"uuid":      true,
+	}
+	builtinEvents = map[string][]eventSchema{
+		ProviderStripe: {
+			{Type: "charge.succeeded", Fields: map[string]string{"id": "id:ch_", "object": "enum:charge", "amount": "amount", "currency": "currency", "customer": "id:cus_", "paid": "bool", "receipt_email": "email", "created": "timestamp"}},
+			{Type: "charge.failed", Fields: map[string]string{"id": "id:ch_", "object": "enum:charge", "amount": "amount", "currency": "currency", "customer": "id:cus_", "failure_code": "enum:card_declined|expired_card|incorrect_cvc|processing_error", "created": "timestamp"}},
+			{Type: "customer.created", Fields: map[string]string{"id": "id:cus_", "object": "enum:customer", "email": "email", "created": "timestamp"}},
+			{Type: "invoice.paid", Fields: map[string]string{"id": "id:in_", "object": "enum:invoice", "amount_paid": "amount", "currency": "currency", "customer": "id:cus_", "subscription": "id:sub_", "created": "timestamp"}},
+			{Type: "payout.paid", Fields: map[string]string{"id": "id:po_", "object": "enum:payout", "amount": "amount", "currency": "currency", "destination": "id:ba_", "arrival_date": "timestamp"}},
+			{Type: "charge.refunded", Fields: map[string]string{"id": "id:ch_", "object": "enum:charge", "amount_refunded": "amount", "currency": "currency", "refunded": "bool", "created": "timestamp"}},
+		},
+		ProviderGitHub: {
+			{Type: "push", Fields: map[string]string{"ref": "enum:refs/heads/main|refs/heads/develop|refs/heads/feature/login", "before": "id", "after": "id", "forced": "bool", "pusher": "email"}},
+			{Type: "pull_request", Fields: map[string]string{"action": "enum:opened|closed|reopened|synchronize", "number": "int", "sender": "email"}},
+			{Type: "repository", Fields: map[string]string{"action": "enum:created|deleted|publicized|privatized", "sender": "email"}},
+			{Type: "member", Fields: map[string]string{"action": "enum:added|removed|edited", "member": "email", "sender": "email"}},
+		},
+	}
+	currencies = [...]string{"usd", "eur", "gbp", "jpy", "cad", "aud"}
+	users      = [...]string{"jenny.rosen", "john.doe", "a.smith", "billing", "ops"}
+	domains    = [...]string{"example.com", "example.org", "example.net"}
+)
+
+// Delivery is one webhook delivery, the HTTP headers and body.
+type Delivery struct {
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// Webhook holds the state for generating webhook deliveries.
+type Webhook struct {
+	provider        string
+	secret          []byte
+	signatureHeader string
+	events          []eventSchema
+	rand            *rand.Rand
+	staticTime      *time.Time
+	clock           *generator.Clock
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Webhook objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Webhook{
+		provider:        c.Provider,
+		secret:          []byte(c.Secret),
+		signatureHeader: c.SignatureHeader,
+		events:          c.Events,
+		rand:            r,
+		clock:           clock,
+	}
+	if len(w.events) == 0 {
+		w.events = builtinEvents[c.Provider]
+		if c.Provider == ProviderGeneric {
+			w.events = builtinEvents[ProviderStripe]
+		}
+	}
+
+	return w, nil
+}
+
+// Next produces the next webhook delivery.
+func (w *Webhook) Next() ([]byte, error) {
+	e := w.events[w.rand.IntN(len(w.events))]
+	now := w.getTime()
+
+	data, err := randomFields(w.rand, e.Fields, now)
+	if err != nil {
+		return nil, err
+	}
+	id := randomID(w.rand, "evt_")
+
+	var payload interface{}
+	switch w.provider {
+	case ProviderStripe:
+		payload = map[string]interface{}{
+			"id":          id,
+			"object":      "event",
+			"api_version": stripeAPIVersion,
+			"created":     now.Unix(),
+			"type":        e.Type,
+			"livemode":    false,
+			"data":        map[string]interface{}{"object": data},
+		}
+	case ProviderGitHub:
+		payload = data
+	default:
+		payload = map[string]interface{}{
+			"id":      id,
+			"type":    e.Type,
+			"created": now.Unix(),
+			"data":    data,
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	d := Delivery{
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body:    string(body),
+	}
+	switch w.provider {
+	case ProviderStripe:
+		ts := strconv.FormatInt(now.Unix(), 10)
+		d.Headers["Stripe-Signature"] = fmt.Sprintf("t=%s,v1=%s", ts, w.sign([]byte(ts+"."), body))
+	case ProviderGitHub:
+		d.Headers["X-GitHub-Event"] = e.Type
+		d.Headers["X-GitHub-Delivery"] = randomUUID(w.rand)
+		d.Headers["X-Hub-Signature-256"] = "sha256=" + w.sign(body)
+	default:
+		d.Headers[w.signatureHeader] = w.sign(body)
+	}
+
+	return json.Marshal(&d)
+}
+
+// sign returns the hex encoded HMAC-SHA256 of the concatenated parts.
+func (w *Webhook) sign(parts ...[]byte) string {
+	mac := hmac.New(sha256.New, w.secret)
+	for _, p := range parts {
+		mac.Write(p)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (w *Webhook) getTime() time.Time {
+	if w.staticTime != nil {
+		return *w.staticTime
+	}
+
+	return w.clock.Now()
+}
+
+// randomFields returns a random value for each field.  Fields are
+// filled in name order so the values are reproducible for a seed.
+func randomFields(r *rand.Rand, fields map[string]string, now time.Time) (map[string]interface{}, error) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	m := make(map[string]interface{}, len(fields))
+	var computed []string
+	for _, name := range names {
+		if strings.HasPrefix(fields[name], "expr:") {
+			computed = append(computed, name)
+			continue
+		}
+		m[name] = randomValue(r, fields[name], now)
+	}
+
+	vars := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		vars[k] = v
+	}
+	for _, name := range computed {
+		v, err := expr.Eval(strings.TrimPrefix(fields[name], "expr:"), vars)
+		if err != nil {
+			return nil, err
+		}
+		m[name] = v
+	}
+	return m, nil
+}
+
+func randomValue(r *rand.Rand, kind string, now time.Time) interface{} {
+	kind, arg, _ := strings.Cut(kind, ":")
+	switch kind {
+	case "id":
+		return randomID(r, arg)
+	case "amount":
+		return (r.IntN(100000) + 1) * 5
+	case "currency":
+		return currencies[r.IntN(len(currencies))]
+	case "email":
+		return users[r.IntN(len(users))] + "@" + domains[r.IntN(len(domains))]
+	case "ip":
+		return random.IPv4(r).String()
+	case "timestamp":
+		return now.Add(-time.Duration(r.IntN(86400)) * time.Second).Unix()
+	case "bool":
+		return r.IntN(2) == 0
+	case "int":
+		return r.IntN(10000)
+	case "uuid":
+		return randomUUID(r)
+	case "enum":
+		values := strings.Split(arg, "|")
+		return values[r.IntN(len(values))]
+	}
+	return nil
+}
+
+func validKind(kind string) bool {
+	kind, arg, _ := strings.Cut(kind, ":")
+	switch kind {
+	case "enum":
+		return arg != ""
+	case "expr":
+		_, err := expr.Compile(arg)
+		return err == nil
+	}
+	return fieldKinds[kind]
+}
+
+func randomID(r *rand.Rand, prefix string) string {
+	b := make([]byte, 24)
+	for i := range b {
+		b[i] = idChars[r.IntN(len(idChars))]
+	}
+	return prefix + string(b)
+}
+
+func randomUUID(r *rand.Rand) string {
+	return fmt.Sprintf("%08x-%04x-4%03x-%04x-%012x", r.Uint32(), r.IntN(1<<16), r.IntN(1<<12), r.IntN(1<<14)|0x8000, r.Int64N(1<<48))
+}
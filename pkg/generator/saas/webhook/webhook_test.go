@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		provider string
+		expected string
+	}{
+		"Stripe":  {provider: ProviderStripe, expected: `{"headers":{"Content-Type":"application/json","Stripe-Signature":"t=97445,v1=2aa7d68107944a8a3debf22290b5b4db5dafbc28817709386f6e6283c38e82e3"},"body":"{\"api_version\":\"2023-10-16\",\"created\":97445,\"data\":{\"object\":{\"amount_refunded\":233125,\"created\":11872,\"currency\":\"aud\",\"id\":\"ch_j0vqFcu90EBeqoGnKCCM0Y2D\",\"object\":\"charge\",\"refunded\":false}},\"id\":\"evt_lu12TYGEARrgEpvWYeXFNSl3\",\"livemode\":false,\"object\":\"event\",\"type\":\"charge.refunded\"}"}`},
+		"GitHub":  {provider: ProviderGitHub, expected: `{"headers":{"Content-Type":"application/json","X-GitHub-Delivery":"18b8d9a6-ea15-462b-be8a-8fb42ea1fde0","X-GitHub-Event":"push","X-Hub-Signature-256":"sha256=2d5e2d6608179c3d2237d7db3d1123fd60ed1aa9b23b0718450bc817d2413f17"},"body":"{\"after\":\"Szsj0vqFcu90EBeqoGnKCCM0\",\"before\":\"Y2D45lu12TYGEARrgEpvWYeX\",\"forced\":true,\"pusher\":\"john.doe@example.org\",\"ref\":\"refs/heads/feature/login\"}"}`},
+		"Generic": {provider: ProviderGeneric, expected: `{"headers":{"Content-Type":"application/json","X-Signature":"bfe3c6c4cfa6c9b191c27409804c847b569a17620b517696753b4888908581ef"},"body":"{\"created\":97445,\"data\":{\"amount_refunded\":233125,\"created\":11872,\"currency\":\"aud\",\"id\":\"ch_j0vqFcu90EBeqoGnKCCM0Y2D\",\"object\":\"charge\",\"refunded\":false},\"id\":\"evt_lu12TYGEARrgEpvWYeXFNSl3\",\"type\":\"charge.refunded\"}"}`},
+	}
+	for name, tc := range tests {
+		random.Seed(1)
+		w := newWebhook(t, tc.provider)
+		got, err := w.Next()
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, string(got), name)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	random.Seed(1)
+	for _, provider := range []string{ProviderStripe, ProviderGitHub, ProviderGeneric} {
+		w := newWebhook(t, provider)
+		for i := 0; i < 100; i++ {
+			got, err := w.Next()
+			assert.Nil(t, err, provider)
+
+			var d Delivery
+			assert.Nil(t, json.Unmarshal(got, &d), provider)
+
+			mac := hmac.New(sha256.New, []byte("whsec_test"))
+			switch provider {
+			case ProviderStripe:
+				ts, sig, _ := strings.Cut(strings.TrimPrefix(d.Headers["Stripe-Signature"], "t="), ",v1=")
+				mac.Write([]byte(ts + "." + d.Body))
+				assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), sig, provider)
+			case ProviderGitHub:
+				mac.Write([]byte(d.Body))
+				assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), d.Headers["X-Hub-Signature-256"], provider)
+			default:
+				mac.Write([]byte(d.Body))
+				assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), d.Headers["X-Signature"], provider)
+			}
+		}
+	}
+}
+
+func TestComputedFields(t *testing.T) {
+	random.Seed(1)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "provider": ProviderGeneric, "secret": "whsec_test", "events": []map[string]interface{}{
+		{"type": "payment.settled", "fields": map[string]interface{}{
+			"amount": "amount",
+			"risk":   "expr:'high' if amount > 250000 else 'low'",
+			"fee":    "expr:amount // 100",
+		}},
+	}})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+
+	for i := 0; i < 100; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+
+		var d Delivery
+		assert.Nil(t, json.Unmarshal(got, &d))
+		var body struct {
+			Data struct {
+				Amount int    `json:"amount"`
+				Fee    int    `json:"fee"`
+				Risk   string `json:"risk"`
+			} `json:"data"`
+		}
+		assert.Nil(t, json.Unmarshal([]byte(d.Body), &body))
+		assert.Equal(t, body.Data.Amount/100, body.Data.Fee)
+		if body.Data.Amount > 250000 {
+			assert.Equal(t, "high", body.Data.Risk)
+		} else {
+			assert.Equal(t, "low", body.Data.Risk)
+		}
+	}
+}
+
+func newWebhook(t *testing.T, provider string) *Webhook {
+	testTime, err := time.Parse(time.RFC3339, "1970-01-02T03:04:05Z")
+	assert.Nil(t, err)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "provider": provider, "secret": "whsec_test"})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	w := g.(*Webhook)
+	w.staticTime = &testTime
+	return w
+}
@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"fmt"
+	"time"
+)
+
+// Scenarios of the "timestamp" option, see Clock.
+const (
+	ScenarioDSTStart   = "dst_start"
+	ScenarioDSTEnd     = "dst_end"
+	ScenarioYearEnd    = "year_end"
+	ScenarioLeapSecond = "leap_second"
+)
+
+var (
+	scenarios = [...]string{ScenarioDSTStart, ScenarioDSTEnd, ScenarioYearEnd, ScenarioLeapSecond}
+	// leapSeconds are the ends of the days with a leap second, the
+	// times the inserted second 23:59:60 ends.
+	leapSeconds = [...]time.Time{
+		time.Date(2005, 12, 31, 24, 0, 0, 0, time.UTC),
+		time.Date(2008, 12, 31, 24, 0, 0, 0, time.UTC),
+		time.Date(2012, 6, 30, 24, 0, 0, 0, time.UTC),
+		time.Date(2015, 6, 30, 24, 0, 0, 0, time.UTC),
+		time.Date(2016, 12, 31, 24, 0, 0, 0, time.UTC),
+	}
+)
+
+// scenarioBoundary returns the boundary of the scenario in loc, the
+// first one after start, or the last one before now without a start.
+func scenarioBoundary(scenario string, loc *time.Location, start time.Time) (time.Time, error) {
+	after := !start.IsZero()
+	ref := start
+	if !after {
+		ref = time.Now()
+	}
+	switch scenario {
+	case ScenarioYearEnd:
+		year := ref.In(loc).Year()
+		if after {
+			year++
+		}
+		return time.Date(year, 1, 1, 0, 0, 0, 0, loc), nil
+	case ScenarioLeapSecond:
+		if after {
+			for _, l := range leapSeconds {
+				if l.After(ref) {
+					return l, nil
+				}
+			}
+			last := leapSeconds[len(leapSeconds)-1]
+			return time.Time{}, fmt.Errorf("'%s' is not a valid value for 'start' expected a time before the last leap second at '%s'", start.Format(time.RFC3339Nano), last.Format(time.RFC3339))
+		}
+		for i := len(leapSeconds) - 1; i >= 0; i-- {
+			if leapSeconds[i].Before(ref) {
+				return leapSeconds[i], nil
+			}
+		}
+		return leapSeconds[0], nil
+	}
+	// The start of daylight saving time moves the offset forward, its
+	// end back.
+	forward := scenario == ScenarioDSTStart
+	step := time.Hour
+	if !after {
+		step = -step
+	}
+	t := ref
+	for i := 0; i < 366*24; i++ {
+		next := t.Add(step)
+		_, from := t.In(loc).Zone()
+		_, to := next.In(loc).Zone()
+		if !after {
+			from, to = to, from
+		}
+		if from != to && (to > from) == forward {
+			lo, hi := t, next
+			if !after {
+				lo, hi = next, t
+			}
+			return transition(loc, lo, hi), nil
+		}
+		t = next
+	}
+	return time.Time{}, fmt.Errorf("'%s' is not a valid value for 'timezone' expected a time zone with daylight saving time", loc)
+}
+
+// transition returns the first second after lo up to hi with the
+// offset of hi in loc.
+func transition(loc *time.Location, lo, hi time.Time) time.Time {
+	_, offset := hi.In(loc).Zone()
+	lo, hi = lo.Truncate(time.Second), hi.Truncate(time.Second)
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2).Truncate(time.Second)
+		if _, o := mid.In(loc).Zone(); o == offset {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi
+}
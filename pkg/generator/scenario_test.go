@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func scenarioClock(t *testing.T, c map[string]interface{}) (*Clock, error) {
+	t.Helper()
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": "test", "timestamp": c})
+	assert.Nil(t, err)
+	return NewClock(cfg)
+}
+
+func TestScenarioDST(t *testing.T) {
+	clock, err := scenarioClock(t, map[string]interface{}{"scenario": "dst_end", "timezone": "Europe/Amsterdam", "start": "2024-06-01T00:00:00Z", "interval": "15m"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(9), clock.Events())
+	var local []string
+	for i := int64(0); i < clock.Events(); i++ {
+		local = append(local, clock.Now().Format("Jan _2 15:04 MST"))
+	}
+	// 2024-10-27 03:00 CEST is 02:00 CET, the hour of 02:00 happens
+	// twice.
+	assert.Equal(t, []string{
+		"Oct 27 02:00 CEST", "Oct 27 02:15 CEST", "Oct 27 02:30 CEST", "Oct 27 02:45 CEST",
+		"Oct 27 02:00 CET", "Oct 27 02:15 CET", "Oct 27 02:30 CET", "Oct 27 02:45 CET", "Oct 27 03:00 CET",
+	}, local)
+
+	clock, err = scenarioClock(t, map[string]interface{}{"scenario": "dst_start", "timezone": "America/New_York", "start": "2024-01-01T00:00:00Z", "interval": "30m", "window": "30m"})
+	assert.Nil(t, err)
+	var times []string
+	for i := int64(0); i < clock.Events(); i++ {
+		times = append(times, clock.Now().Format("15:04 MST"))
+	}
+	// 2024-03-10 02:00 EST is 03:00 EDT.
+	assert.Equal(t, []string{"01:30 EST", "03:00 EDT", "03:30 EDT"}, times)
+
+	_, err = scenarioClock(t, map[string]interface{}{"scenario": "dst_start", "timezone": "Asia/Tokyo", "interval": "1m"})
+	assert.EqualError(t, err, "'Asia/Tokyo' is not a valid value for 'timezone' expected a time zone with daylight saving time")
+}
+
+func TestScenarioYearEnd(t *testing.T) {
+	clock, err := scenarioClock(t, map[string]interface{}{"scenario": "year_end", "timezone": "Europe/Amsterdam", "start": "2023-06-01T00:00:00Z", "interval": "30m", "window": "1h"})
+	assert.Nil(t, err)
+	var times []string
+	for i := int64(0); i < clock.Events(); i++ {
+		times = append(times, clock.Now().Format("Jan _2 15:04:05"))
+	}
+	assert.Equal(t, []string{"Dec 31 23:00:00", "Dec 31 23:30:00", "Jan  1 00:00:00", "Jan  1 00:30:00", "Jan  1 01:00:00"}, times)
+
+	clock, err = scenarioClock(t, map[string]interface{}{"scenario": "year_end", "interval": "1m"})
+	assert.Nil(t, err)
+	first := clock.Now()
+	assert.Equal(t, time.Date(time.Now().UTC().Year()-1, 12, 31, 23, 0, 0, 0, time.UTC), first)
+}
+
+func TestScenarioLeapSecond(t *testing.T) {
+	clock, err := scenarioClock(t, map[string]interface{}{"scenario": "leap_second", "interval": "500ms", "window": "1s"})
+	assert.Nil(t, err)
+	var times []string
+	for i := int64(0); i < clock.Events(); i++ {
+		times = append(times, clock.Now().Format("2006-01-02T15:04:05.0Z07:00"))
+	}
+	assert.Equal(t, []string{
+		"2016-12-31T23:59:59.0Z", "2016-12-31T23:59:59.5Z",
+		"2016-12-31T23:59:59.0Z", "2016-12-31T23:59:59.5Z",
+		"2017-01-01T00:00:00.0Z",
+	}, times)
+
+	_, err = scenarioClock(t, map[string]interface{}{"scenario": "leap_second", "start": "2020-01-01T00:00:00Z", "interval": "1s"})
+	assert.EqualError(t, err, "'2020-01-01T00:00:00Z' is not a valid value for 'start' expected a time before the last leap second at '2017-01-01T00:00:00Z'")
+}
+
+func TestScenarioConfig(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		errorString string
+	}{
+		"Invalid Scenario": {
+			c:           map[string]interface{}{"scenario": "y2k", "interval": "1s"},
+			errorString: "'y2k' is not a valid value for 'scenario' expected 'dst_start, dst_end, year_end, leap_second' accessing 'timestamp'",
+		},
+		"Invalid Timezone": {
+			c:           map[string]interface{}{"scenario": "dst_end", "timezone": "Mars/Olympus", "interval": "1s"},
+			errorString: "'Mars/Olympus' is not a valid value for 'timezone' expected an IANA time zone accessing 'timestamp'",
+		},
+		"Scenario and End": {
+			c:           map[string]interface{}{"scenario": "year_end", "start": "2024-03-01T00:00:00Z", "end": "2024-03-02T00:00:00Z", "interval": "1s"},
+			errorString: "only one of end and scenario can be set accessing 'timestamp'",
+		},
+		"Negative Window": {
+			c:           map[string]interface{}{"scenario": "year_end", "window": "-1h", "interval": "1s"},
+			errorString: "'-1h0m0s' is not a valid value for 'window' expected 0 or more accessing 'timestamp'",
+		},
+	}
+	for name, tc := range tests {
+		_, err := scenarioClock(t, tc.c)
+		if assert.NotNil(t, err, name) {
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+	}
+}
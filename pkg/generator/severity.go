@@ -0,0 +1,199 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+)
+
+type severityOptions struct {
+	Severity severityConfig `config:"severity"`
+}
+
+type severityConfig struct {
+	Mix    map[string]float64 `config:"mix"`
+	Bursts burstConfig        `config:"bursts"`
+}
+
+type burstConfig struct {
+	Rate     float64       `config:"rate"`
+	Duration time.Duration `config:"duration"`
+	Levels   []string      `config:"levels"`
+}
+
+func (c *burstConfig) Validate() error {
+	if c.Rate < 0 {
+		return fmt.Errorf("'%v' is not a valid value for 'rate' expected a value of 0 or more", c.Rate)
+	}
+	if c.Duration <= 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'duration' expected a positive duration", c.Duration)
+	}
+	return nil
+}
+
+// Severities are the severity of the events of a generator with the
+// "severity" option.  The "mix" is the weights of the levels, instead
+// of the generator's own distribution, and "bursts" are short bursts
+// of severe events, at "rate" bursts per hour of the event timestamps,
+// in which every event has one of the "levels", by default the most
+// severe level of the generator, for "duration", by default a minute:
+//
+//	generator:
+//	  type: fortinet:firewall
+//	  severity:
+//	    mix:
+//	      information: 80
+//	      notice: 15
+//	      warning: 5
+//	    bursts:
+//	      rate: 2
+//	      duration: 30s
+//	      levels: [critical, alert]
+//
+// The levels are the names of the generator, from the most to the
+// least severe.  A string field is set to the name of the level and
+// an integer field to its place in the levels, as for the syslog
+// severity codes.
+type Severities struct {
+	rand   *rand.Rand
+	levels []string
+	index  []int
+	values []reflect.Value
+
+	codes  []int
+	totals []float64
+
+	rate     float64
+	duration time.Duration
+	burst    []int
+	next     time.Time
+	until    time.Time
+}
+
+// NewSeverities returns the Severities of the "severity" option in the
+// ucfg.Config for the field name of the generator v, a pointer to a
+// struct, with the levels of the generator from the most to the least
+// severe.  An error is returned for levels that are not levels of the
+// generator.
+func NewSeverities(cfg *ucfg.Config, r *rand.Rand, v interface{}, name string, levels []string) (*Severities, error) {
+	c := severityOptions{Severity: severityConfig{Bursts: burstConfig{Duration: time.Minute}}}
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	index, ft, err := field(reflect.TypeOf(v).Elem(), "severity", name)
+	if err != nil {
+		return nil, err
+	}
+	s := &Severities{rand: r, levels: levels, index: index, rate: c.Severity.Bursts.Rate, duration: c.Severity.Bursts.Duration}
+	for i, level := range levels {
+		var rv reflect.Value
+		switch ft.Kind() {
+		case reflect.String:
+			rv = reflect.ValueOf(level).Convert(ft)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			rv = reflect.ValueOf(i).Convert(ft)
+		default:
+			return nil, fmt.Errorf("'%s' of %s can not hold a severity", name, reflect.TypeOf(v).Elem())
+		}
+		s.values = append(s.values, rv)
+	}
+
+	if m := c.Severity.Mix; m != nil {
+		total := 0.0
+		for i, level := range levels {
+			if w, ok := m[level]; ok {
+				total += w
+				s.codes = append(s.codes, i)
+				s.totals = append(s.totals, total)
+			}
+		}
+		for level, w := range m {
+			if s.code(level) < 0 {
+				return nil, fmt.Errorf("'%s' is not a valid value for 'severity.mix' expected '%s'", level, strings.Join(levels, ", "))
+			}
+			if w < 0 {
+				return nil, fmt.Errorf("'%v' is not a valid value for 'severity.mix.%s' expected a weight of 0 or more", w, level)
+			}
+		}
+		if total == 0 {
+			return nil, fmt.Errorf("'severity.mix' expected a weight greater than 0")
+		}
+	}
+
+	burst := c.Severity.Bursts.Levels
+	if len(burst) == 0 {
+		burst = levels[:1]
+	}
+	for _, level := range burst {
+		i := s.code(level)
+		if i < 0 {
+			return nil, fmt.Errorf("'%s' is not a valid value for 'severity.bursts.levels' expected '%s'", level, strings.Join(levels, ", "))
+		}
+		s.burst = append(s.burst, i)
+	}
+	return s, nil
+}
+
+// Apply sets the severity of v, the pointer to the struct the
+// Severities were created for, for the event at now.  Without the
+// "severity" option v is left alone.
+func (s *Severities) Apply(v interface{}, now time.Time) {
+	if s == nil || (s.codes == nil && s.rate == 0) {
+		return
+	}
+	level := -1
+	if s.Burst(now) {
+		level = s.burst[s.rand.IntN(len(s.burst))]
+	} else if s.codes != nil {
+		n := s.rand.Float64() * s.totals[len(s.totals)-1]
+		for i, total := range s.totals {
+			if n < total {
+				level = s.codes[i]
+				break
+			}
+		}
+	}
+	if level >= 0 {
+		set(reflect.ValueOf(v).Elem(), s.index, s.values[level])
+	}
+}
+
+// Burst reports whether now is in a burst of severe events.  The
+// bursts are a Poisson process of the rate, and a burst that starts
+// in a burst extends it.
+func (s *Severities) Burst(now time.Time) bool {
+	if s == nil || s.rate == 0 {
+		return false
+	}
+	if s.next.IsZero() {
+		s.next = now.Add(s.wait())
+	}
+	for !now.Before(s.next) {
+		if s.until.Before(s.next) {
+			s.until = s.next
+		}
+		s.until = s.until.Add(s.duration)
+		s.next = s.next.Add(s.wait())
+	}
+	return now.Before(s.until)
+}
+
+func (s *Severities) wait() time.Duration {
+	return time.Duration(s.rand.ExpFloat64() / s.rate * float64(time.Hour))
+}
+
+// code returns the place of level in the levels, -1 if it is not one
+// of them.
+func (s *Severities) code(level string) int {
+	for i, l := range s.levels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
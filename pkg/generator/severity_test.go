@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+type severityGenerator struct {
+	Level string
+	Code  int
+	Score float64
+}
+
+var testLevels = []string{"critical", "error", "warning", "info"}
+
+func TestSeveritiesMix(t *testing.T) {
+	cfg := ucfg.MustNewFrom(map[string]interface{}{
+		"type":     "test",
+		"severity": map[string]interface{}{"mix": map[string]interface{}{"info": 3, "error": 1, "warning": 0}},
+	})
+	g := &severityGenerator{}
+	s, err := NewSeverities(cfg, rand.New(rand.NewChaCha8([32]byte{1})), g, "Level", testLevels)
+	assert.Nil(t, err)
+
+	seen := map[string]int{}
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4000; i++ {
+		g.Level = "critical"
+		s.Apply(g, now)
+		seen[g.Level]++
+	}
+	assert.Len(t, seen, 2)
+	assert.InDelta(t, 3000, seen["info"], 150)
+	assert.InDelta(t, 1000, seen["error"], 150)
+
+	// An integer field is set to the place of the level.
+	s, err = NewSeverities(cfg, rand.New(rand.NewChaCha8([32]byte{1})), g, "Code", testLevels)
+	assert.Nil(t, err)
+	for i := 0; i < 100; i++ {
+		s.Apply(g, now)
+		assert.Contains(t, []int{1, 3}, g.Code)
+	}
+}
+
+func TestSeveritiesBursts(t *testing.T) {
+	cfg := ucfg.MustNewFrom(map[string]interface{}{
+		"type":     "test",
+		"severity": map[string]interface{}{"bursts": map[string]interface{}{"rate": 4, "duration": "2m", "levels": []interface{}{"critical", "error"}}},
+	})
+	g := &severityGenerator{}
+	s, err := NewSeverities(cfg, rand.New(rand.NewChaCha8([32]byte{1})), g, "Level", testLevels)
+	assert.Nil(t, err)
+
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	bursts, severe, in := 0, 0, false
+	for i := 0; i < 10*3600; i++ {
+		g.Level = "info"
+		s.Apply(g, now)
+		if g.Level != "info" {
+			assert.Contains(t, []string{"critical", "error"}, g.Level)
+			severe++
+			if !in {
+				bursts++
+			}
+		}
+		in = g.Level != "info"
+		now = now.Add(time.Second)
+	}
+	// About 4 bursts an hour of 2 minutes each over 10 hours.
+	assert.InDelta(t, 40, bursts, 15)
+	assert.InDelta(t, 40*120, severe, 2000)
+
+	// Without the option the severity is left alone.
+	s, err = NewSeverities(ucfg.MustNewFrom(map[string]interface{}{"type": "test"}), rand.New(rand.NewChaCha8([32]byte{1})), g, "Level", testLevels)
+	assert.Nil(t, err)
+	g.Level = "warning"
+	s.Apply(g, now)
+	assert.Equal(t, "warning", g.Level)
+	assert.False(t, s.Burst(now))
+}
+
+func TestSeveritiesErrors(t *testing.T) {
+	tests := map[string]struct {
+		severity    map[string]interface{}
+		field       string
+		errorString string
+	}{
+		"Unknown Level": {
+			severity:    map[string]interface{}{"mix": map[string]interface{}{"fatal": 1}},
+			errorString: "'fatal' is not a valid value for 'severity.mix' expected 'critical, error, warning, info'",
+		},
+		"Negative Weight": {
+			severity:    map[string]interface{}{"mix": map[string]interface{}{"info": -1, "error": 2}},
+			errorString: "'-1' is not a valid value for 'severity.mix.info' expected a weight of 0 or more",
+		},
+		"No Weight": {
+			severity:    map[string]interface{}{"mix": map[string]interface{}{"info": 0}},
+			errorString: "'severity.mix' expected a weight greater than 0",
+		},
+		"Unknown Burst Level": {
+			severity:    map[string]interface{}{"bursts": map[string]interface{}{"rate": 1, "levels": []interface{}{"panic"}}},
+			errorString: "'panic' is not a valid value for 'severity.bursts.levels' expected 'critical, error, warning, info'",
+		},
+		"Invalid Rate": {
+			severity:    map[string]interface{}{"bursts": map[string]interface{}{"rate": -1}},
+			errorString: "'-1' is not a valid value for 'rate' expected a value of 0 or more accessing 'severity.bursts'",
+		},
+		"Invalid Duration": {
+			severity:    map[string]interface{}{"bursts": map[string]interface{}{"rate": 1, "duration": "-1s"}},
+			errorString: "'-1s' is not a valid value for 'duration' expected a positive duration accessing 'severity.bursts'",
+		},
+		"Not A Severity": {
+			severity:    map[string]interface{}{},
+			field:       "Score",
+			errorString: "'Score' of generator.severityGenerator can not hold a severity",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			field := tc.field
+			if field == "" {
+				field = "Level"
+			}
+			cfg := ucfg.MustNewFrom(map[string]interface{}{"type": "test", "severity": tc.severity})
+			_, err := NewSeverities(cfg, rand.New(rand.NewChaCha8([32]byte{1})), &severityGenerator{}, field, testLevels)
+			assert.EqualError(t, err, tc.errorString)
+		})
+	}
+}
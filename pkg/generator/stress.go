@@ -0,0 +1,151 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Kinds of the stress of the "stress" option, the tags of the
+// stressed events are "stress_" and the kind.
+const (
+	StressLongField = "long_field"
+	StressKeys      = "keys"
+	StressDepth     = "depth"
+)
+
+var stressKinds = []string{StressLongField, StressKeys, StressDepth}
+
+// stressStream is the stream of the stress wrapper, see newWrapperRand.
+const stressStream = 3
+
+type stressOptions struct {
+	Stress *stressConfig `config:"stress"`
+}
+
+type stressConfig struct {
+	Percent   float64 `config:"percent"`
+	LongField int     `config:"long_field"`
+	Keys      int     `config:"keys"`
+	Depth     int     `config:"depth"`
+}
+
+func (c *stressConfig) Validate() error {
+	if c.Percent < 0 || c.Percent > 100 {
+		return fmt.Errorf("'%v' is not a valid value for 'percent' expected a percentage from 0 to 100", c.Percent)
+	}
+	if c.LongField < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'long_field' expected a length of 0 or more", c.LongField)
+	}
+	if c.Keys < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'keys' expected a number of 0 or more", c.Keys)
+	}
+	if c.Depth < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'depth' expected a number of 0 or more", c.Depth)
+	}
+	if c.LongField+c.Keys+c.Depth == 0 {
+		return fmt.Errorf("you must specify long_field, keys or depth")
+	}
+	return nil
+}
+
+// stressGenerator adds stress to a percentage of the JSON events of a
+// generator, see New.
+type stressGenerator struct {
+	Generator
+	rand    *rand.Rand
+	percent float64
+	kinds   []string
+	c       stressConfig
+}
+
+// newStress returns g stressed with the "stress" option in the
+// ucfg.Config, g itself without the option.
+func newStress(g Generator, cfg *ucfg.Config) (Generator, error) {
+	if ok, err := cfg.Has("stress", -1); err != nil || !ok {
+		return g, err
+	}
+	c := stressOptions{Stress: &stressConfig{Percent: 100}}
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	if IsBinary(g) {
+		return nil, fmt.Errorf("'stress' can not be used with binary events")
+	}
+	r, err := newWrapperRand(cfg, stressStream)
+	if err != nil {
+		return nil, err
+	}
+	s := &stressGenerator{Generator: g, rand: r, percent: c.Stress.Percent, c: *c.Stress}
+	for i, n := range []int{s.c.LongField, s.c.Keys, s.c.Depth} {
+		if n > 0 {
+			s.kinds = append(s.kinds, stressKinds[i])
+		}
+	}
+	return s, nil
+}
+
+func (s *stressGenerator) Next() ([]byte, error) {
+	b, err := s.Generator.Next()
+	if err != nil || s.rand.Float64()*100 >= s.percent {
+		return b, err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var doc map[string]interface{}
+	if err := d.Decode(&doc); err != nil || doc == nil {
+		return nil, fmt.Errorf("'stress' expected events that are JSON objects")
+	}
+
+	kind := s.kinds[s.rand.IntN(len(s.kinds))]
+	switch kind {
+	case StressLongField:
+		object(doc, "url")["original"] = longURL(s.rand, s.c.LongField)
+	case StressKeys:
+		labels := object(doc, "labels")
+		for i := 0; i < s.c.Keys; i++ {
+			labels[fmt.Sprintf("stress_%05d", i)] = random.Word(s.rand)
+		}
+	case StressDepth:
+		var nested map[string]interface{}
+		for i := s.c.Depth; i > 0; i-- {
+			level := map[string]interface{}{"level": i}
+			if nested != nil {
+				level["nested"] = nested
+			}
+			nested = level
+		}
+		doc["nested"] = nested
+	}
+	tags, _ := doc["tags"].([]interface{})
+	doc["tags"] = append(tags, "stress_"+kind)
+	return json.Marshal(doc)
+}
+
+// object returns the object of the key of doc, which replaces a value
+// that is not an object.
+func object(doc map[string]interface{}, key string) map[string]interface{} {
+	m, ok := doc[key].(map[string]interface{})
+	if !ok {
+		m = make(map[string]interface{})
+		doc[key] = m
+	}
+	return m
+}
+
+// longURL returns a URL of n bytes with a path of random words.
+func longURL(r *rand.Rand, n int) string {
+	var b strings.Builder
+	b.WriteString("https://")
+	b.WriteString(random.Hostname(r))
+	for b.Len() < n {
+		b.WriteString("/")
+		b.WriteString(random.Word(r))
+	}
+	return b.String()[:n]
+}
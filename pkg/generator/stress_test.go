@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDocument = `{"@timestamp":"2024-03-01T00:00:00Z","event":{"action":"login"},"labels":{"env":"prod"},"tags":["auth"],"bytes":1234567890123}`
+
+func stressed(t *testing.T, g Generator, c map[string]interface{}) (Generator, error) {
+	t.Helper()
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": "test", "seed": 1, "stress": c})
+	assert.Nil(t, err)
+	return newStress(g, cfg)
+}
+
+func TestStress(t *testing.T) {
+	g, err := stressed(t, &csvGenerator{event: testDocument}, map[string]interface{}{"long_field": 65536, "keys": 2000, "depth": 100})
+	assert.Nil(t, err)
+
+	seen := map[string]int{}
+	for i := 0; i < 300; i++ {
+		b, err := g.Next()
+		assert.Nil(t, err)
+		var doc map[string]interface{}
+		assert.Nil(t, json.Unmarshal(b, &doc))
+		// The event is kept, and tagged with the kind.
+		assert.Equal(t, "login", doc["event"].(map[string]interface{})["action"])
+		assert.Contains(t, string(b), `"bytes":1234567890123`)
+		tags := doc["tags"].([]interface{})
+		assert.Len(t, tags, 2)
+		assert.Equal(t, "auth", tags[0])
+		seen[tags[1].(string)]++
+
+		switch tags[1] {
+		case "stress_" + StressLongField:
+			url := doc["url"].(map[string]interface{})["original"].(string)
+			assert.Len(t, url, 65536)
+		case "stress_" + StressKeys:
+			labels := doc["labels"].(map[string]interface{})
+			assert.Len(t, labels, 2001)
+			assert.Equal(t, "prod", labels["env"])
+		case "stress_" + StressDepth:
+			depth := 0
+			for n, ok := doc["nested"].(map[string]interface{}); ok; n, ok = n["nested"].(map[string]interface{}) {
+				depth++
+				assert.EqualValues(t, depth, n["level"])
+			}
+			assert.Equal(t, 100, depth)
+		default:
+			t.Errorf("unexpected tag %v", tags[1])
+		}
+	}
+	assert.Len(t, seen, 3)
+}
+
+func TestStressStream(t *testing.T) {
+	g, err := stressed(t, &csvGenerator{event: testDocument}, map[string]interface{}{"keys": 10})
+	assert.Nil(t, err)
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": "test", "seed": 1})
+	assert.Nil(t, err)
+	r, err := NewRand(cfg)
+	assert.Nil(t, err)
+	assert.NotEqual(t, r.Uint64(), g.(*stressGenerator).rand.Uint64())
+}
+
+func TestStressPercent(t *testing.T) {
+	g, err := stressed(t, &csvGenerator{event: testDocument}, map[string]interface{}{"percent": 10, "depth": 3})
+	assert.Nil(t, err)
+	n := 0
+	for i := 0; i < 10000; i++ {
+		b, err := g.Next()
+		assert.Nil(t, err)
+		if string(b) != testDocument {
+			n++
+		}
+	}
+	assert.InDelta(t, 1000, n, 100)
+}
+
+func TestStressErrors(t *testing.T) {
+	g, err := stressed(t, &csvGenerator{event: testEvent}, map[string]interface{}{"depth": 3})
+	assert.Nil(t, err)
+	_, err = g.Next()
+	assert.EqualError(t, err, "'stress' expected events that are JSON objects")
+
+	_, err = stressed(t, &csvGenerator{binary: true}, map[string]interface{}{"depth": 3})
+	assert.EqualError(t, err, "'stress' can not be used with binary events")
+
+	tests := map[string]struct {
+		c           map[string]interface{}
+		errorString string
+	}{
+		"Invalid Percent": {
+			c:           map[string]interface{}{"percent": 120, "keys": 10},
+			errorString: "'120' is not a valid value for 'percent' expected a percentage from 0 to 100 accessing 'stress'",
+		},
+		"Invalid Keys": {
+			c:           map[string]interface{}{"keys": -10},
+			errorString: "'-10' is not a valid value for 'keys' expected a number of 0 or more accessing 'stress'",
+		},
+		"No Stress": {
+			c:           map[string]interface{}{"percent": 5},
+			errorString: "you must specify long_field, keys or depth accessing 'stress'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			_, err := stressed(t, &csvGenerator{}, tc.c)
+			assert.EqualError(t, err, tc.errorString)
+		})
+	}
+}
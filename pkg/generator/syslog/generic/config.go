@@ -0,0 +1,81 @@
+package generic
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Type           string             `config:"type" validate:"required"`
+	Protocol       string             `config:"protocol"`
+	Facilities     map[string]float64 `config:"facilities"`
+	Severities     map[string]float64 `config:"severities"`
+	Hostnames      []string           `config:"hostnames"`
+	StructuredData float64            `config:"structured_data"`
+	MessagesFile   string             `config:"messages_file"`
+}
+
+// The default weights are not in defaultConfig, as ucfg would merge
+// the configured weights into them.
+var (
+	defaultFacilities = map[string]float64{
+		"kern": 5, "user": 10, "mail": 5, "daemon": 30, "auth": 10, "syslog": 5, "cron": 10, "authpriv": 10, "local0": 15,
+	}
+	defaultSeverities = map[string]float64{
+		"crit": 1, "err": 5, "warning": 10, "notice": 14, "info": 60, "debug": 10,
+	}
+)
+
+func defaultConfig() config {
+	return config{
+		Type:           Name,
+		Protocol:       ProtocolRFC3164,
+		StructuredData: 50,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if c.Protocol != ProtocolRFC3164 && c.Protocol != ProtocolRFC5424 {
+		return fmt.Errorf("'%s' is not a valid value for 'protocol' expected '%s'", c.Protocol, strings.Join([]string{ProtocolRFC3164, ProtocolRFC5424}, ", "))
+	}
+	if err := validWeights("facilities", c.Facilities, facilities[:]); err != nil {
+		return err
+	}
+	if err := validWeights("severities", c.Severities, severities[:]); err != nil {
+		return err
+	}
+	for _, h := range c.Hostnames {
+		if h == "" || strings.ContainsAny(h, " \t\n") {
+			return fmt.Errorf("'%s' is not a valid value for 'hostnames' expected a hostname without spaces", h)
+		}
+	}
+	if c.StructuredData < 0 || c.StructuredData > 100 {
+		return fmt.Errorf("'%v' is not a valid value for 'structured_data' expected a percentage from 0 to 100", c.StructuredData)
+	}
+	return nil
+}
+
+// validWeights checks the weights of option, which are keyed by the
+// names of the codes.
+func validWeights(option string, weights map[string]float64, names []string) error {
+	if weights == nil {
+		return nil
+	}
+	total := 0.0
+	for name, w := range weights {
+		if code(names, name) < 0 {
+			return fmt.Errorf("'%s' is not a valid value for '%s' expected '%s'", name, option, strings.Join(names, ", "))
+		}
+		if w < 0 {
+			return fmt.Errorf("'%v' is not a valid value for '%s.%s' expected a weight of 0 or more", w, option, name)
+		}
+		total += w
+	}
+	if total == 0 {
+		return fmt.Errorf("'%s' expected a weight greater than 0", option)
+	}
+	return nil
+}
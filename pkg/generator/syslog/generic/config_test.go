@@ -0,0 +1,89 @@
+package generic
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid RFC5424 with Weights": {
+			c:           map[string]interface{}{"type": Name, "protocol": "rfc5424", "facilities": map[string]interface{}{"daemon": 70, "local7": 30}, "severities": map[string]interface{}{"info": 1}, "hostnames": []string{"web01"}, "structured_data": 100},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'syslog:generic' accessing config",
+		},
+		"Invalid Protocol": {
+			c:           map[string]interface{}{"type": Name, "protocol": "rfc3195"},
+			hasError:    true,
+			errorString: "'rfc3195' is not a valid value for 'protocol' expected 'rfc3164, rfc5424' accessing config",
+		},
+		"Invalid Facility": {
+			c:           map[string]interface{}{"type": Name, "facilities": map[string]interface{}{"local8": 1}},
+			hasError:    true,
+			errorString: "'local8' is not a valid value for 'facilities' expected 'kern, user, mail, daemon, auth, syslog, lpr, news, uucp, cron, authpriv, ftp, ntp, security, console, solaris-cron, local0, local1, local2, local3, local4, local5, local6, local7' accessing config",
+		},
+		"Invalid Severity Weight": {
+			c:           map[string]interface{}{"type": Name, "severities": map[string]interface{}{"info": -1}},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'severities.info' expected a weight of 0 or more accessing config",
+		},
+		"No Severity Weight": {
+			c:           map[string]interface{}{"type": Name, "severities": map[string]interface{}{"info": 0}},
+			hasError:    true,
+			errorString: "'severities' expected a weight greater than 0 accessing config",
+		},
+		"Invalid Hostname": {
+			c:           map[string]interface{}{"type": Name, "hostnames": []string{"web 01"}},
+			hasError:    true,
+			errorString: "'web 01' is not a valid value for 'hostnames' expected a hostname without spaces accessing config",
+		},
+		"Invalid Structured Data": {
+			c:           map[string]interface{}{"type": Name, "structured_data": 101},
+			hasError:    true,
+			errorString: "'101' is not a valid value for 'structured_data' expected a percentage from 0 to 100 accessing config",
+		},
+		"Missing Messages File": {
+			c:           map[string]interface{}{"type": Name, "messages_file": "/nonexistent/messages.txt"},
+			hasError:    true,
+			errorString: "open /nonexistent/messages.txt: no such file or directory",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			_, err = New(c)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
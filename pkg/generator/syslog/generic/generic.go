@@ -0,0 +1,319 @@
+// Package generic generates syslog messages of no particular vendor,
+// in the RFC 3164 (BSD) or RFC 5424 format, as baseline noise between
+// the messages of the vendor generators.
+//
+// The facility and severity of every message are drawn from weighted
+// distributions, the hostname from a pool and the message from a
+// corpus, the built-in one or one loaded from a file with a message
+// per line.  RFC 5424 messages have structured data with the chance
+// of structured_data.
+//
+// Configuration:
+//
+//	protocol:        rfc3164 (the default) or rfc5424.
+//	facilities:      The weights of the facilities by keyword, kern,
+//	                 user, mail, daemon, auth, syslog, lpr, news, uucp,
+//	                 cron, authpriv, ftp, ntp, security, console,
+//	                 solaris-cron and local0 to local7.  The default
+//	                 is mostly daemon, local0 and auth.
+//	severities:      The weights of the severities by keyword, emerg,
+//	                 alert, crit, err, warning, notice, info and debug.
+//	                 The default is mostly info.
+//	hostnames:       The pool of hostnames, defaults to host01 to
+//	                 host10.
+//	structured_data: The percentage of RFC 5424 messages with
+//	                 structured data, defaults to 50.
+//	messages_file:   A file with the messages of the corpus, one per
+//	                 line.  Empty lines are skipped.
+//	severity:        The bursts of severe messages, see
+//	                 generator.Severities.  Its mix replaces the
+//	                 weights of severities.
+//
+//	- generator:
+//	    type: syslog:generic
+//	    protocol: rfc5424
+//	    facilities:
+//	      daemon: 70
+//	      auth: 30
+//	    hostnames: [web01, web02, db01]
+//	    messages_file: /etc/spigot/messages.txt
+package generic
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "syslog:generic"
+
+const (
+	ProtocolRFC3164 = "rfc3164"
+	ProtocolRFC5424 = "rfc5424"
+
+	rfc3164Fmt = "Jan _2 15:04:05"
+	rfc5424Fmt = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+var (
+	// facilities and severities are the keywords of the codes, in
+	// the order of the codes.
+	facilities = [...]string{
+		"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news", "uucp", "cron", "authpriv", "ftp",
+		"ntp", "security", "console", "solaris-cron", "local0", "local1", "local2", "local3", "local4", "local5",
+		"local6", "local7",
+	}
+	severities = [...]string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+	apps = [...]string{"sshd", "CRON", "systemd", "kernel", "nginx", "postfix/smtpd", "dhclient", "sudo", "rsyslogd", "app"}
+	// corpus is the built-in corpus of messages.
+	corpus = [...]string{
+		"Accepted publickey for deploy from 10.0.4.17 port 50122 ssh2: RSA SHA256:Lq3g2xR0",
+		"Failed password for invalid user admin from 203.0.113.45 port 41870 ssh2",
+		"pam_unix(cron:session): session opened for user root by (uid=0)",
+		"Started Daily apt download activities.",
+		"Reached target Timers.",
+		"eth0: link up, 1000Mbps, full-duplex",
+		"Out of memory: Killed process 4242 (java) total-vm:8123456kB",
+		"connect from unknown[198.51.100.7]",
+		"DHCPACK of 10.0.4.23 from 10.0.4.1",
+		"deploy : TTY=pts/0 ; PWD=/home/deploy ; USER=root ; COMMAND=/usr/bin/systemctl restart nginx",
+		"rsyslogd's groupid changed to 110",
+		"upstream timed out (110: Connection timed out) while reading response header from upstream",
+		"disk usage on /var is 91%",
+		"configuration reloaded",
+		"health check passed in 12ms",
+	}
+	sdIDs = [...]string{"timeQuality", "origin", "meta", "exampleSDID@32473"}
+)
+
+// Generic holds the random fields of a syslog message.
+type Generic struct {
+	Facility  int
+	Severity  int
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	ProcID    int
+	MsgID     string
+	Message   string
+
+	rand           *rand.Rand
+	pins           *generator.Pins
+	severity       *generator.Severities
+	rfc5424        bool
+	facilities     weights
+	severities     weights
+	hostnames      []string
+	messages       []string
+	structuredData float64
+	sd             string
+	now            func() time.Time
+	// layout is the layout of RFC 5424 timestamps, at the precision
+	// of the clock.
+	layout string
+}
+
+// weights is a weighted distribution of codes.
+type weights struct {
+	codes  []int
+	totals []float64
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Generic objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Facilities == nil {
+		c.Facilities = defaultFacilities
+	}
+	if c.Severities == nil {
+		c.Severities = defaultSeverities
+	}
+	g := &Generic{
+		rand:           r,
+		rfc5424:        c.Protocol == ProtocolRFC5424,
+		facilities:     newWeights(c.Facilities, facilities[:]),
+		severities:     newWeights(c.Severities, severities[:]),
+		hostnames:      c.Hostnames,
+		messages:       corpus[:],
+		structuredData: c.StructuredData,
+		now:            clock.Now,
+		layout:         clock.Layout(rfc5424Fmt),
+	}
+	if len(g.hostnames) == 0 {
+		for i := 1; i <= 10; i++ {
+			g.hostnames = append(g.hostnames, fmt.Sprintf("host%02d", i))
+		}
+	}
+	if c.MessagesFile != "" {
+		if g.messages, err = readMessages(c.MessagesFile); err != nil {
+			return nil, err
+		}
+	}
+
+	g.pins, err = generator.NewPins(cfg, r, g)
+	if err != nil {
+		return nil, err
+	}
+	g.severity, err = generator.NewSeverities(cfg, r, g, "Severity", severities[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// readMessages returns the non-empty lines of the file name.
+func readMessages(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var messages []string
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		if line := strings.TrimRight(s.Text(), "\r"); strings.TrimSpace(line) != "" {
+			messages = append(messages, line)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("'%s' is not a valid value for 'messages_file' expected a file with at least one message", name)
+	}
+	return messages, nil
+}
+
+// newWeights returns the distribution of the weights by keyword of
+// names.  The codes are sorted, so a seeded generator draws the same
+// codes.
+func newWeights(m map[string]float64, names []string) weights {
+	var w weights
+	for name := range m {
+		w.codes = append(w.codes, code(names, name))
+	}
+	sort.Ints(w.codes)
+	total := 0.0
+	for _, c := range w.codes {
+		total += m[names[c]]
+		w.totals = append(w.totals, total)
+	}
+	return w
+}
+
+// pick returns a random code of the distribution.
+func (w weights) pick(r *rand.Rand) int {
+	n := r.Float64() * w.totals[len(w.totals)-1]
+	return w.codes[sort.Search(len(w.totals), func(i int) bool { return w.totals[i] > n })]
+}
+
+// code returns the code of the keyword name, -1 if it is not one of
+// names.
+func code(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Next produces the next syslog message.
+//
+// Example:
+//
+// <20>Mar  4 12:00:00 host01 nginx[14611]: health check passed in 12ms
+func (g *Generic) Next() ([]byte, error) {
+	g.randomize()
+
+	var buf bytes.Buffer
+	pri := g.Facility*8 + g.Severity
+	if g.rfc5424 {
+		procID := "-"
+		if g.ProcID > 0 {
+			procID = fmt.Sprint(g.ProcID)
+		}
+		fmt.Fprintf(&buf, "<%d>1 %s %s %s %s %s %s %s", pri, g.Timestamp.UTC().Format(g.layout), g.Hostname, g.AppName, procID, g.MsgID, g.sd, g.Message)
+		return buf.Bytes(), nil
+	}
+	fmt.Fprintf(&buf, "<%d>%s %s %s", pri, g.Timestamp.Format(rfc3164Fmt), g.Hostname, g.AppName)
+	if g.ProcID > 0 {
+		fmt.Fprintf(&buf, "[%d]", g.ProcID)
+	}
+	fmt.Fprintf(&buf, ": %s", g.Message)
+	return buf.Bytes(), nil
+}
+
+func (g *Generic) randomize() {
+	g.Facility = g.facilities.pick(g.rand)
+	g.Severity = g.severities.pick(g.rand)
+	g.Timestamp = g.now()
+	g.Hostname = g.hostnames[g.rand.IntN(len(g.hostnames))]
+	g.AppName = apps[g.rand.IntN(len(apps))]
+	g.ProcID = g.rand.IntN(32000) + 100
+	if g.AppName == "kernel" {
+		g.ProcID = 0
+	}
+	g.MsgID = "-"
+	if g.rand.IntN(4) == 0 {
+		g.MsgID = fmt.Sprintf("ID%d", g.rand.IntN(100))
+	}
+	g.Message = g.messages[g.rand.IntN(len(g.messages))]
+
+	g.sd = "-"
+	if g.rfc5424 && g.rand.Float64()*100 < g.structuredData {
+		g.sd = g.structured()
+	}
+
+	g.severity.Apply(g, g.Timestamp)
+	g.pins.Apply(g)
+}
+
+// structured returns one or two random SD-ELEMENTs, with different
+// SD-IDs.
+func (g *Generic) structured() string {
+	var b strings.Builder
+	for _, i := range g.rand.Perm(len(sdIDs))[:g.rand.IntN(2)+1] {
+		switch id := sdIDs[i]; id {
+		case "timeQuality":
+			fmt.Fprintf(&b, `[timeQuality tzKnown="1" isSynced="%d"]`, g.rand.IntN(2))
+		case "origin":
+			fmt.Fprintf(&b, `[origin ip="10.0.%d.%d" software="spigot"]`, g.rand.IntN(256), g.rand.IntN(254)+1)
+		case "meta":
+			fmt.Fprintf(&b, `[meta sequenceId="%d"]`, g.rand.IntN(1000000)+1)
+		default:
+			fmt.Fprintf(&b, `[%s iut="%d" eventSource="Application" eventID="%d"]`, id, g.rand.IntN(9)+1, g.rand.IntN(9000)+1000)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,136 @@
+package generic
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testTime = time.Date(2024, 3, 4, 12, 0, 0, 123456000, time.UTC)
+	rfc3164  = regexp.MustCompile(`^<(\d+)>Mar  4 12:00:00 (\S+) ([\w/]+)(\[\d+\])?: (.+)$`)
+	rfc5424  = regexp.MustCompile(`^<(\d+)>1 2024-03-04T12:00:00\.123456Z (\S+) (\S+) (\d+|-) (ID\d+|-) (-|(?:\[[^\]]+\])+) (.+)$`)
+)
+
+func newGeneric(t *testing.T, cfg map[string]interface{}) *Generic {
+	c, err := ucfg.NewFrom(cfg)
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	s := g.(*Generic)
+	s.now = func() time.Time { return testTime }
+	return s
+}
+
+func TestNext(t *testing.T) {
+	g := newGeneric(t, map[string]interface{}{"type": Name, "seed": 1})
+	for i := 0; i < 200; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		m := rfc3164.FindStringSubmatch(string(got))
+		if assert.NotNil(t, m, string(got)) {
+			assert.Regexp(t, `^host(0[1-9]|10)$`, m[2])
+			assert.Contains(t, corpus, m[5])
+		}
+	}
+}
+
+func TestRFC5424(t *testing.T) {
+	g := newGeneric(t, map[string]interface{}{"type": Name, "protocol": ProtocolRFC5424, "hostnames": []string{"web01", "db01"}})
+	sd := 0
+	for i := 0; i < 1000; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		m := rfc5424.FindStringSubmatch(string(got))
+		if !assert.NotNil(t, m, string(got)) {
+			continue
+		}
+		assert.Contains(t, []string{"web01", "db01"}, m[2])
+		if m[6] != "-" {
+			sd++
+		}
+	}
+	assert.InDelta(t, 500, sd, 60)
+}
+
+func TestWeights(t *testing.T) {
+	g := newGeneric(t, map[string]interface{}{"type": Name, "facilities": map[string]interface{}{"auth": 3, "local7": 1, "mail": 0}, "severities": map[string]interface{}{"err": 1}})
+	counts := map[int]int{}
+	const n = 4000
+	for i := 0; i < n; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		m := rfc3164.FindStringSubmatch(string(got))
+		if !assert.NotNil(t, m, string(got)) {
+			continue
+		}
+		pri, _ := strconv.Atoi(m[1])
+		assert.Equal(t, 3, pri%8, "severity err")
+		counts[pri/8]++
+	}
+	assert.Len(t, counts, 2)
+	assert.InDelta(t, 0.75, float64(counts[4])/n, 0.03, "auth")
+	assert.InDelta(t, 0.25, float64(counts[23])/n, 0.03, "local7")
+}
+
+func TestMessagesFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "messages.txt")
+	assert.Nil(t, os.WriteFile(name, []byte("first message\r\n\n  \nsecond message\n"), 0o644))
+	g := newGeneric(t, map[string]interface{}{"type": Name, "messages_file": name})
+	assert.Equal(t, []string{"first message", "second message"}, g.messages)
+	for i := 0; i < 20; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		m := rfc3164.FindStringSubmatch(string(got))
+		if assert.NotNil(t, m, string(got)) {
+			assert.Contains(t, g.messages, m[5])
+		}
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.txt")
+	assert.Nil(t, os.WriteFile(empty, []byte("\n"), 0o644))
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "messages_file": empty})
+	assert.Nil(t, err)
+	_, err = New(c)
+	assert.EqualError(t, err, "'"+empty+"' is not a valid value for 'messages_file' expected a file with at least one message")
+}
+
+func TestPin(t *testing.T) {
+	g := newGeneric(t, map[string]interface{}{"type": Name, "pin": map[string]interface{}{"Hostname": "fw01", "AppName": "myapp"}})
+	got, err := g.Next()
+	assert.Nil(t, err)
+	m := rfc3164.FindStringSubmatch(string(got))
+	if assert.NotNil(t, m, string(got)) {
+		assert.Equal(t, "fw01", m[2])
+		assert.Equal(t, "myapp", m[3])
+	}
+}
+
+func TestSeverityBursts(t *testing.T) {
+	g := newGeneric(t, map[string]interface{}{"type": Name, "seed": 1, "severities": map[string]interface{}{"info": 1}, "severity": map[string]interface{}{"bursts": map[string]interface{}{"rate": 6, "duration": "1m", "levels": []interface{}{"crit", "alert"}}}})
+	now := testTime
+	g.now = func() time.Time { now = now.Add(time.Second); return now }
+	priority := regexp.MustCompile(`^<(\d+)>`)
+	severe := 0
+	for i := 0; i < 3600; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		m := priority.FindStringSubmatch(string(got))
+		if !assert.NotNil(t, m, string(got)) {
+			continue
+		}
+		pri, _ := strconv.Atoi(m[1])
+		if pri%8 != 6 {
+			assert.Contains(t, []int{1, 2}, pri%8)
+			severe++
+		}
+	}
+	// About 6 bursts of a minute in the hour.
+	assert.InDelta(t, 360, severe, 240)
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+type config struct {
+	Type       string             `config:"type" validate:"required"`
+	Hosts      int                `config:"hosts"`
+	Windows    float64            `config:"windows"`
+	Metricsets []string           `config:"metricsets"`
+	Period     time.Duration      `config:"period"`
+	Churn      random.ChurnConfig `config:"churn"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:       Name,
+		Hosts:      10,
+		Windows:    50,
+		Metricsets: []string{MetricsetCPU, MetricsetMemory, MetricsetNetwork},
+		Period:     10 * time.Second,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if c.Hosts < 1 {
+		return fmt.Errorf("'%d' is not a valid value for 'hosts' expected a value greater than 0", c.Hosts)
+	}
+	if c.Windows < 0 || c.Windows > 100 {
+		return fmt.Errorf("'%v' is not a valid value for 'windows' expected a percentage from 0 to 100", c.Windows)
+	}
+	if len(c.Metricsets) == 0 {
+		return fmt.Errorf("'metricsets' expected at least one of '%s'", strings.Join(metricsets[:], ", "))
+	}
+	for _, m := range c.Metricsets {
+		if !validMetricset(m) {
+			return fmt.Errorf("'%s' is not a valid value for 'metricsets' expected '%s'", m, strings.Join(metricsets[:], ", "))
+		}
+	}
+	if c.Period <= 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'period' expected a positive duration", c.Period)
+	}
+	return nil
+}
+
+func validMetricset(m string) bool {
+	for _, v := range metricsets {
+		if v == m {
+			return true
+		}
+	}
+	return false
+}
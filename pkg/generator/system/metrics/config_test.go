@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Options": {
+			c:           map[string]interface{}{"type": Name, "hosts": 200, "windows": 80, "metricsets": []interface{}{"cpu", "perfmon"}, "period": "30s", "churn": map[string]interface{}{"arrive": 1, "retire": 1, "reassign": 2}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'system:metrics' accessing config",
+		},
+		"Invalid Hosts": {
+			c:           map[string]interface{}{"type": Name, "hosts": 0},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'hosts' expected a value greater than 0 accessing config",
+		},
+		"Invalid Windows": {
+			c:           map[string]interface{}{"type": Name, "windows": 101},
+			hasError:    true,
+			errorString: "'101' is not a valid value for 'windows' expected a percentage from 0 to 100 accessing config",
+		},
+		"Invalid Metricset": {
+			c:           map[string]interface{}{"type": Name, "metricsets": []interface{}{"cpu", "diskio"}},
+			hasError:    true,
+			errorString: "'diskio' is not a valid value for 'metricsets' expected 'cpu, memory, network, perfmon' accessing config",
+		},
+		"Invalid Churn": {
+			c:           map[string]interface{}{"type": Name, "churn": map[string]interface{}{"retire": -1}},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'retire' expected a value of 0 or more accessing 'churn'",
+		},
+		"Invalid Period": {
+			c:           map[string]interface{}{"type": Name, "period": "-1s"},
+			hasError:    true,
+			errorString: "'-1s' is not a valid value for 'period' expected a positive duration accessing config",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			_, err = New(c)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
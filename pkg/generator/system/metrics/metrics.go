@@ -0,0 +1,514 @@
+// Package metrics generates the system metrics documents of
+// Metricbeat, in the JSON Metricbeat writes to Elasticsearch, for
+// load testing indices that mix logs and metrics.
+//
+// The documents are of a pool of Linux and Windows hosts, each with its
+// own name, OS, cores, memory and network interfaces.  The metrics of
+// a host are continuous: the CPU and memory usage wander from document
+// to document, and the network counters of each interface only grow,
+// at a rate of the interface, with the ingress and egress since the
+// previous document of the interface in host.network.  A round of
+// documents has one document of each metricset for every host, one
+// for every interface with network, and one for every perfmon object
+// of the Windows hosts.
+//
+// With churn the pool drifts over the run, like a real inventory: new
+// hosts appear, with the next numbers of the names, old ones retire,
+// and addresses move to other hosts, the addresses of the retired
+// hosts first.  The rates are per hour of the event timestamps.
+//
+// Configuration:
+//
+//	hosts:      (number, optional) The number of hosts, defaults to 10.
+//	windows:    (number, optional) The percentage of the hosts that run
+//	            Windows, from 0 to 100, defaults to 50.
+//	metricsets: (list, optional) The metricsets of the documents, cpu,
+//	            memory, network and perfmon, windows.perfmon of the
+//	            Windows hosts, defaults to cpu, memory and network.
+//	period:     (duration, optional) The metricset.period of the
+//	            documents, defaults to 10s.
+//	churn:      (object, optional) The hosts per hour that "arrive" and
+//	            "retire", and the address "reassign"ments per hour,
+//	            all 0 by default.
+//
+//	- generator:
+//	    type: system:metrics
+//	    hosts: 200
+//	    windows: 80
+//	    metricsets: [cpu, memory, network, perfmon]
+//	    churn:
+//	      arrive: 2
+//	      retire: 2
+//	      reassign: 5
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "system:metrics"
+
+// Metricsets of the documents.
+const (
+	MetricsetCPU     = "cpu"
+	MetricsetMemory  = "memory"
+	MetricsetNetwork = "network"
+	MetricsetPerfmon = "perfmon"
+)
+
+const (
+	agentVersion = "8.12.2"
+	ecsVersion   = "8.0.0"
+	timestampFmt = "2006-01-02T15:04:05.000Z"
+)
+
+var (
+	metricsets = [...]string{MetricsetCPU, MetricsetMemory, MetricsetNetwork, MetricsetPerfmon}
+
+	linuxNames   = [...]string{"web", "app", "db", "cache", "queue", "k8s-node", "build", "log"}
+	windowsNames = [...]string{"DC", "SQL", "IIS", "EXCH", "FS", "RDS", "WSUS", "HV"}
+	linuxOS      = [...]mapstr.M{
+		{"type": "linux", "family": "debian", "platform": "ubuntu", "name": "Ubuntu", "version": "22.04.4 LTS (Jammy Jellyfish)", "codename": "jammy", "kernel": "5.15.0-97-generic"},
+		{"type": "linux", "family": "debian", "platform": "debian", "name": "Debian GNU/Linux", "version": "12 (bookworm)", "codename": "bookworm", "kernel": "6.1.0-18-amd64"},
+		{"type": "linux", "family": "redhat", "platform": "rhel", "name": "Red Hat Enterprise Linux", "version": "9.3 (Plow)", "codename": "Plow", "kernel": "5.14.0-362.18.1.el9_3.x86_64"},
+	}
+	windowsOS = [...]mapstr.M{
+		{"type": "windows", "family": "windows", "platform": "windows", "name": "Windows Server 2019 Datacenter", "version": "10.0", "build": "17763.5329", "kernel": "10.0.17763.5329 (WinBuild.160101.0800)"},
+		{"type": "windows", "family": "windows", "platform": "windows", "name": "Windows Server 2022 Standard", "version": "10.0", "build": "20348.2227", "kernel": "10.0.20348.2227 (WinBuild.160101.0800)"},
+	}
+	coreCounts  = [...]int{2, 4, 8, 16, 32}
+	memoryGiBs  = [...]uint64{4, 8, 16, 32, 64, 128}
+	linuxIfaces = [...]string{"eth0", "eth1", "ens5", "bond0"}
+	// perfmonObjects are the perfmon objects of the Windows hosts,
+	// with the instance and the counters in the metricbeat field names.
+	perfmonObjects = [...]struct {
+		object, instance string
+		counters         []string
+	}{
+		{"Processor", "_Total", []string{"processor_time", "privileged_time", "interrupts_per_sec"}},
+		{"Memory", "", []string{"available_mbytes", "pages_per_sec", "committed_bytes_in_use"}},
+		{"LogicalDisk", "C:", []string{"free_space", "avg_disk_queue_length", "disk_reads_per_sec", "disk_writes_per_sec"}},
+	}
+)
+
+// host is a host of the pool and the state of its metrics.
+type host struct {
+	name        string
+	id          string
+	windows     bool
+	os          mapstr.M
+	ips         []string
+	macs        []string
+	cores       int
+	memTotal    uint64
+	swapTotal   uint64
+	agentID     string
+	ephemeralID string
+	cpu         float64
+	mem         float64
+	swap        float64
+	cache       float64
+	disk        float64
+	ifaces      []*iface
+}
+
+// iface is a network interface of a host, with its counters.
+type iface struct {
+	name    string
+	rate    float64
+	last    time.Time
+	in, out counters
+}
+
+type counters struct {
+	bytes, packets, errors, dropped uint64
+}
+
+// sample is a document of a round, of the metricset of a host, and for
+// network and perfmon the interface or object.
+type sample struct {
+	host      *host
+	metricset string
+	index     int
+}
+
+// Metrics holds the state of the system metrics generator.
+type Metrics struct {
+	rand       *rand.Rand
+	clock      *generator.Clock
+	hosts      []*host
+	metricsets []string
+	period     time.Duration
+	windows    float64
+	churn      *random.Churn
+	seq        int
+	freeIPs    []string
+	pending    []sample
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Metrics objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Metrics{
+		rand:       r,
+		clock:      clock,
+		metricsets: c.Metricsets,
+		period:     c.Period,
+		windows:    c.Windows,
+		churn:      random.NewChurn(r, c.Churn),
+	}
+	for i := 0; i < c.Hosts; i++ {
+		m.addHost()
+	}
+
+	return m, nil
+}
+
+// addHost adds the next host to the pool.
+func (m *Metrics) addHost() {
+	m.hosts = append(m.hosts, m.newHost(m.seq, m.rand.Float64()*100 < m.windows))
+	m.seq++
+}
+
+// newHost returns the i-th host of the pool.
+func (m *Metrics) newHost(i int, windows bool) *host {
+	r := m.rand
+	h := &host{
+		windows:     windows,
+		cores:       coreCounts[r.IntN(len(coreCounts))],
+		memTotal:    memoryGiBs[r.IntN(len(memoryGiBs))] << 30,
+		agentID:     random.UUID(r),
+		ephemeralID: random.UUID(r),
+		cpu:         0.05 + r.Float64()*0.5,
+		mem:         0.2 + r.Float64()*0.6,
+		swap:        r.Float64() * 0.2,
+		cache:       0.1 + r.Float64()*0.2,
+		disk:        0.1 + r.Float64()*0.7,
+	}
+	h.swapTotal = h.memTotal / 4
+	n := 1 + r.IntN(2)
+	if windows {
+		h.name = fmt.Sprintf("%s%02d", windowsNames[r.IntN(len(windowsNames))], i+1)
+		h.id = random.UUID(r)
+		h.os = windowsOS[r.IntN(len(windowsOS))]
+		for j := 0; j < n; j++ {
+			name := "Ethernet"
+			if j > 0 {
+				name = fmt.Sprintf("Ethernet %d", j+1)
+			}
+			h.ifaces = append(h.ifaces, &iface{name: name})
+		}
+	} else {
+		h.name = fmt.Sprintf("%s%02d", linuxNames[r.IntN(len(linuxNames))], i+1)
+		h.id = random.Hex(r, 16)
+		h.os = linuxOS[r.IntN(len(linuxOS))]
+		h.ifaces = append(h.ifaces, &iface{name: "lo"})
+		k := r.IntN(len(linuxIfaces))
+		for j := 0; j < n; j++ {
+			h.ifaces = append(h.ifaces, &iface{name: linuxIfaces[(j+k)%len(linuxIfaces)]})
+		}
+	}
+	for _, f := range h.ifaces {
+		f.rate = math.Exp(r.Float64()*8) * 1000
+		f.in = counters{bytes: uint64(r.Int64N(1 << 40)), packets: uint64(r.Int64N(1 << 30))}
+		f.out = counters{bytes: uint64(r.Int64N(1 << 38)), packets: uint64(r.Int64N(1 << 29))}
+		if f.name == "lo" {
+			continue
+		}
+		h.ips = append(h.ips, m.address())
+		h.macs = append(h.macs, strings.ToUpper(strings.ReplaceAll(random.MAC(r).String(), ":", "-")))
+	}
+	return h
+}
+
+// address returns an address for an interface, a free address of a
+// retired host if there is one.
+func (m *Metrics) address() string {
+	if n := len(m.freeIPs); n > 0 {
+		ip := m.freeIPs[n-1]
+		m.freeIPs = m.freeIPs[:n-1]
+		return ip
+	}
+	r := m.rand
+	return fmt.Sprintf("10.%d.%d.%d", r.IntN(256), r.IntN(256), 1+r.IntN(254))
+}
+
+// drift applies the churn of the pool up to t.  The pool always keeps
+// one host.
+func (m *Metrics) drift(t time.Time) {
+	arrive, retire, reassign := m.churn.Changes(t)
+	for ; retire > 0 && len(m.hosts) > 1; retire-- {
+		i := m.rand.IntN(len(m.hosts))
+		m.freeIPs = append(m.freeIPs, m.hosts[i].ips...)
+		m.hosts = append(m.hosts[:i], m.hosts[i+1:]...)
+	}
+	for ; arrive > 0; arrive-- {
+		m.addHost()
+	}
+	for ; reassign > 0; reassign-- {
+		h := m.hosts[m.rand.IntN(len(m.hosts))]
+		if len(h.ips) == 0 {
+			continue
+		}
+		j := m.rand.IntN(len(h.ips))
+		old := h.ips[j]
+		h.ips[j] = m.address()
+		m.freeIPs = append(m.freeIPs, old)
+	}
+}
+
+// Next produces the next document of the round, starting a new round
+// when the documents of the previous round are written.
+func (m *Metrics) Next() ([]byte, error) {
+	now := m.clock.Now()
+	for len(m.pending) == 0 {
+		m.drift(now)
+		m.round()
+	}
+	s := m.pending[0]
+	m.pending = m.pending[1:]
+
+	return json.Marshal(m.document(s, now))
+}
+
+// round queues the documents of a round.
+func (m *Metrics) round() {
+	for _, h := range m.hosts {
+		for _, ms := range m.metricsets {
+			switch ms {
+			case MetricsetNetwork:
+				for i := range h.ifaces {
+					m.pending = append(m.pending, sample{host: h, metricset: ms, index: i})
+				}
+			case MetricsetPerfmon:
+				if !h.windows {
+					continue
+				}
+				for i := range perfmonObjects {
+					m.pending = append(m.pending, sample{host: h, metricset: ms, index: i})
+				}
+			default:
+				m.pending = append(m.pending, sample{host: h, metricset: ms})
+			}
+		}
+	}
+}
+
+// document returns the document of s at t.
+func (m *Metrics) document(s sample, t time.Time) mapstr.M {
+	h := s.host
+	module, dataset := "system", "system."+s.metricset
+	if s.metricset == MetricsetPerfmon {
+		module, dataset = "windows", "windows.perfmon"
+	}
+	hostFields := mapstr.M{
+		"name":         h.name,
+		"hostname":     h.name,
+		"id":           h.id,
+		"architecture": "x86_64",
+		"os":           h.os,
+		"ip":           h.ips,
+		"mac":          h.macs,
+	}
+	doc := mapstr.M{
+		"@timestamp": m.clock.Value(t.UTC(), timestampFmt),
+		"agent": mapstr.M{
+			"type":         "metricbeat",
+			"version":      agentVersion,
+			"name":         h.name,
+			"id":           h.agentID,
+			"ephemeral_id": h.ephemeralID,
+		},
+		"ecs": mapstr.M{"version": ecsVersion},
+		"event": mapstr.M{
+			"module":   module,
+			"dataset":  dataset,
+			"duration": 100000 + m.rand.IntN(5000000),
+		},
+		"metricset": mapstr.M{"name": s.metricset, "period": m.period.Milliseconds()},
+		"service":   mapstr.M{"type": module},
+		"host":      hostFields,
+	}
+
+	switch s.metricset {
+	case MetricsetCPU:
+		h.cpu = m.walk(h.cpu, 0.05, 0.01, 0.98)
+		hostFields["cpu"] = mapstr.M{"usage": round(h.cpu)}
+		doc["system"] = mapstr.M{"cpu": m.cpu(h)}
+	case MetricsetMemory:
+		h.mem = m.walk(h.mem, 0.02, 0.05, 0.97)
+		h.swap = m.walk(h.swap, 0.01, 0, 0.9)
+		doc["system"] = mapstr.M{"memory": memory(h)}
+	case MetricsetNetwork:
+		f := h.ifaces[s.index]
+		in, out := m.traffic(f, t)
+		hostFields["network"] = mapstr.M{
+			"ingress": mapstr.M{"bytes": in.bytes, "packets": in.packets},
+			"egress":  mapstr.M{"bytes": out.bytes, "packets": out.packets},
+		}
+		doc["system"] = mapstr.M{"network": mapstr.M{
+			"name": f.name,
+			"in":   mapstr.M{"bytes": f.in.bytes, "packets": f.in.packets, "errors": f.in.errors, "dropped": f.in.dropped},
+			"out":  mapstr.M{"bytes": f.out.bytes, "packets": f.out.packets, "errors": f.out.errors, "dropped": f.out.dropped},
+		}}
+	case MetricsetPerfmon:
+		o := perfmonObjects[s.index]
+		perfmon := mapstr.M{"object": o.object, "metrics": m.perfmon(h, o.object, o.counters)}
+		if o.instance != "" {
+			perfmon["instance"] = o.instance
+		}
+		doc["windows"] = mapstr.M{"perfmon": perfmon}
+	}
+	return doc
+}
+
+// cpu returns the system.cpu fields of h, with the percentages of all
+// the cores and normalized to one core.
+func (m *Metrics) cpu(h *host) mapstr.M {
+	cores := float64(h.cores)
+	user := h.cpu * (0.55 + m.rand.Float64()*0.2)
+	system := h.cpu - user
+	pct := func(v float64) mapstr.M {
+		return mapstr.M{"pct": round(v * cores), "norm": mapstr.M{"pct": round(v)}}
+	}
+	fields := mapstr.M{
+		"cores":  h.cores,
+		"total":  pct(h.cpu),
+		"user":   pct(user),
+		"system": pct(system),
+		"idle":   pct(1 - h.cpu),
+	}
+	if !h.windows {
+		iowait := system * m.rand.Float64() * 0.2
+		fields["system"] = pct(system - iowait)
+		fields["iowait"] = pct(iowait)
+		fields["nice"] = pct(0)
+		fields["irq"] = pct(0)
+		fields["softirq"] = pct(0)
+		fields["steal"] = pct(0)
+	}
+	return fields
+}
+
+// memory returns the system.memory fields of h.  The actual memory of
+// Linux hosts does not count the page cache.
+func memory(h *host) mapstr.M {
+	used := uint64(float64(h.memTotal) * h.mem)
+	actual := used
+	if !h.windows {
+		actual = uint64(float64(used) * (1 - h.cache))
+	}
+	swapUsed := uint64(float64(h.swapTotal) * h.swap)
+	return mapstr.M{
+		"total": h.memTotal,
+		"used":  mapstr.M{"bytes": used, "pct": round(float64(used) / float64(h.memTotal))},
+		"free":  h.memTotal - used,
+		"actual": mapstr.M{
+			"used": mapstr.M{"bytes": actual, "pct": round(float64(actual) / float64(h.memTotal))},
+			"free": h.memTotal - actual,
+		},
+		"swap": mapstr.M{
+			"total": h.swapTotal,
+			"used":  mapstr.M{"bytes": swapUsed, "pct": round(h.swap)},
+			"free":  h.swapTotal - swapUsed,
+		},
+	}
+}
+
+// traffic advances the counters of f to t, at the rate of f, and
+// returns the increments since the previous document of f.
+func (m *Metrics) traffic(f *iface, t time.Time) (in, out counters) {
+	elapsed := m.period.Seconds()
+	if !f.last.IsZero() {
+		elapsed = math.Max(t.Sub(f.last).Seconds(), 0)
+	}
+	f.last = t
+	f.rate = m.walk(f.rate, f.rate*0.1, 100, 1e9)
+	in.bytes = uint64(f.rate * elapsed * (0.5 + m.rand.Float64()))
+	out.bytes = uint64(f.rate * elapsed * (0.2 + m.rand.Float64()*0.6))
+	in.packets = in.bytes / uint64(200+m.rand.IntN(1200))
+	out.packets = out.bytes / uint64(200+m.rand.IntN(1200))
+	if in.packets > 0 && m.rand.IntN(100) == 0 {
+		in.errors, in.dropped = uint64(m.rand.IntN(3)), uint64(m.rand.IntN(10))
+	}
+	if out.packets > 0 && m.rand.IntN(200) == 0 {
+		out.dropped = uint64(m.rand.IntN(5))
+	}
+	f.in = f.in.add(in)
+	f.out = f.out.add(out)
+	return in, out
+}
+
+func (c counters) add(d counters) counters {
+	return counters{bytes: c.bytes + d.bytes, packets: c.packets + d.packets, errors: c.errors + d.errors, dropped: c.dropped + d.dropped}
+}
+
+// perfmon returns the values of the counters of the perfmon object of
+// h, consistent with the cpu and memory of h.
+func (m *Metrics) perfmon(h *host, object string, names []string) mapstr.M {
+	metrics := mapstr.M{}
+	for _, name := range names {
+		var v float64
+		switch name {
+		case "processor_time":
+			v = h.cpu * 100
+		case "privileged_time":
+			v = h.cpu * 100 * (0.2 + m.rand.Float64()*0.2)
+		case "interrupts_per_sec":
+			v = float64(h.cores) * (500 + m.rand.Float64()*3000)
+		case "available_mbytes":
+			v = math.Floor(float64(h.memTotal) * (1 - h.mem) / (1 << 20))
+		case "pages_per_sec":
+			v = m.rand.ExpFloat64() * 20
+		case "committed_bytes_in_use":
+			v = (h.mem + h.swap*0.25) * 100
+		case "free_space":
+			h.disk = m.walk(h.disk, 0.001, 0.01, 0.99)
+			v = (1 - h.disk) * 100
+		case "avg_disk_queue_length":
+			v = m.rand.ExpFloat64() * 0.05
+		default:
+			v = m.rand.ExpFloat64() * 50
+		}
+		metrics[name] = round(v)
+	}
+	return metrics
+}
+
+// walk returns v moved by a normal step of stddev, within min and max.
+func (m *Metrics) walk(v, stddev, min, max float64) float64 {
+	return math.Min(math.Max(v+m.rand.NormFloat64()*stddev, min), max)
+}
+
+// round rounds v to 4 decimals, like the percentages of metricbeat.
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,167 @@
+package metrics
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+type document struct {
+	Timestamp string `json:"@timestamp"`
+	Event     struct {
+		Dataset string `json:"dataset"`
+	} `json:"event"`
+	Metricset struct {
+		Name   string `json:"name"`
+		Period int64  `json:"period"`
+	} `json:"metricset"`
+	Host struct {
+		Name string `json:"name"`
+		OS   struct {
+			Type string `json:"type"`
+		} `json:"os"`
+		Network struct {
+			Ingress struct {
+				Bytes uint64 `json:"bytes"`
+			} `json:"ingress"`
+		} `json:"network"`
+	} `json:"host"`
+	System struct {
+		CPU struct {
+			Cores int `json:"cores"`
+			Total struct {
+				Pct  float64 `json:"pct"`
+				Norm struct {
+					Pct float64 `json:"pct"`
+				} `json:"norm"`
+			} `json:"total"`
+		} `json:"cpu"`
+		Memory struct {
+			Total uint64 `json:"total"`
+			Used  struct {
+				Bytes uint64 `json:"bytes"`
+			} `json:"used"`
+			Free uint64 `json:"free"`
+		} `json:"memory"`
+		Network struct {
+			Name string `json:"name"`
+			In   struct {
+				Bytes uint64 `json:"bytes"`
+			} `json:"in"`
+		} `json:"network"`
+	} `json:"system"`
+	Windows struct {
+		Perfmon struct {
+			Object  string             `json:"object"`
+			Metrics map[string]float64 `json:"metrics"`
+		} `json:"perfmon"`
+	} `json:"windows"`
+}
+
+func TestNext(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "seed": 1, "hosts": 5, "metricsets": []interface{}{"cpu", "memory", "network", "perfmon"}})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	m := g.(*Metrics)
+
+	seen := map[string]int{}
+	counters := map[string]uint64{}
+	for i := 0; i < 500; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		var d document
+		if !assert.Nil(t, json.Unmarshal(got, &d), string(got)) {
+			continue
+		}
+		seen[d.Event.Dataset]++
+		assert.Equal(t, int64(10000), d.Metricset.Period)
+		switch d.Metricset.Name {
+		case "cpu":
+			assert.Equal(t, "system.cpu", d.Event.Dataset)
+			assert.InDelta(t, d.System.CPU.Total.Norm.Pct*float64(d.System.CPU.Cores), d.System.CPU.Total.Pct, 0.01)
+		case "memory":
+			assert.Equal(t, d.System.Memory.Total, d.System.Memory.Used.Bytes+d.System.Memory.Free)
+		case "network":
+			key := d.Host.Name + "/" + d.System.Network.Name
+			if last, ok := counters[key]; ok {
+				assert.Equal(t, last+d.Host.Network.Ingress.Bytes, d.System.Network.In.Bytes, key)
+			}
+			counters[key] = d.System.Network.In.Bytes
+		case "perfmon":
+			assert.Equal(t, "windows.perfmon", d.Event.Dataset)
+			assert.Equal(t, "windows", d.Host.OS.Type)
+			assert.NotEmpty(t, d.Windows.Perfmon.Object)
+			assert.NotEmpty(t, d.Windows.Perfmon.Metrics)
+		}
+	}
+	assert.Equal(t, seen["system.cpu"], seen["system.memory"])
+	assert.Greater(t, seen["system.network"], seen["system.cpu"])
+	windows := 0
+	for _, h := range m.hosts {
+		if h.windows {
+			windows++
+		}
+	}
+	if windows > 0 {
+		assert.Greater(t, seen["windows.perfmon"], 0)
+	}
+	assert.Len(t, counters, func() int {
+		n := 0
+		for _, h := range m.hosts {
+			n += len(h.ifaces)
+		}
+		return n
+	}())
+}
+
+func TestChurn(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{
+		"type":      Name,
+		"seed":      1,
+		"hosts":     20,
+		"timestamp": map[string]interface{}{"start": "2024-03-01T00:00:00Z", "interval": "1m"},
+		"churn":     map[string]interface{}{"arrive": 10, "retire": 10, "reassign": 20},
+	})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	m := g.(*Metrics)
+
+	first := map[string]bool{}
+	for _, h := range m.hosts {
+		first[h.name] = true
+	}
+	owners := map[string]string{}
+	moved := 0
+	// a day of rounds, one document a minute.
+	for i := 0; i < 24*60; i++ {
+		_, err := g.Next()
+		assert.Nil(t, err)
+		for _, h := range m.hosts {
+			for _, ip := range h.ips {
+				if owner, ok := owners[ip]; ok && owner != h.name {
+					moved++
+				}
+				owners[ip] = h.name
+			}
+		}
+	}
+
+	assert.NotEmpty(t, m.hosts)
+	assert.Greater(t, m.seq, 20+100)
+	retired := 0
+	for name := range first {
+		found := false
+		for _, h := range m.hosts {
+			found = found || h.name == name
+		}
+		if !found {
+			retired++
+		}
+	}
+	assert.Greater(t, retired, 0)
+	assert.Greater(t, moved, 0)
+}
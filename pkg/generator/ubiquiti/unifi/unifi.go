@@ -0,0 +1,251 @@
+// Package unifi generates Ubiquiti UniFi and EdgeRouter log messages.
+//
+// Two kinds of messages are generated.  Firewall messages are the
+// kernel iptables lines logged by UniFi gateways and EdgeRouters,
+// prefixed with the rule set, rule number and action, for example
+// "[WAN_LOCAL-default-D]".  Controller messages are the events logged
+// by the UniFi Network controller for clients and devices.
+//
+// Configuration:
+//
+//	event_type: Specify the type of event to generate, or leave blank for random.
+//	            Valid values are: firewall, controller.
+//
+//	- generator:
+//	    type: ubiquiti:unifi
+//	    event_type: firewall
+package unifi
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "ubiquiti:unifi"
+
+const (
+	EventTypeFirewall   = "firewall"
+	EventTypeController = "controller"
+)
+
+var (
+	header              = `{{template "syslog_header" (dict "Timestamp" .Timestamp "Host" .Host)}}`
+	firewallTemplate    = header + "kernel: [{{.RuleSet}}-{{.Rule}}-{{.Action}}]IN={{.InInterface}} OUT={{.OutInterface}} MAC={{.DstMAC}}:{{.SrcMAC}}:08:00 SRC={{.SrcAddr}} DST={{.DstAddr}} LEN={{.Length}} TOS=0x00 PREC=0x00 TTL={{.TTL}} ID={{.ID}} {{if eq .Protocol \"TCP\"}}DF {{end}}PROTO={{.Protocol}}{{if ne .Protocol \"ICMP\"}} SPT={{.SrcPort}} DPT={{.DstPort}}{{end}}{{if eq .Protocol \"TCP\"}} WINDOW={{.Window}} RES=0x00 {{.Flags}} URGP=0{{end}}{{if eq .Protocol \"UDP\"}} LEN={{.UDPLength}}{{end}}{{if eq .Protocol \"ICMP\"}} TYPE=8 CODE=0 ID={{.SrcPort}} SEQ={{.Sequence}}{{end}}"
+	controllerTemplates = [...]string{
+		header + "unifi: {{.Key}}: User[{{.ClientMAC}}] has connected to AP[{{.AP}}]({{.APMAC}}) with SSID \"{{.SSID}}\" on \"channel {{.Channel}}\"",
+		header + "unifi: {{.Key}}: User[{{.ClientMAC}}] disconnected from \"{{.SSID}}\" ({{.Duration}}m connected, {{.Bytes}} bytes, last AP[{{.APMAC}}])",
+		header + "unifi: {{.Key}}: User[{{.ClientMAC}}] roams from AP[{{.AP}}] to AP[{{.APMAC}}] on \"channel {{.Channel}}\"",
+		header + "unifi: {{.Key}}: AP[{{.APMAC}}] was disconnected",
+		header + "unifi: {{.Key}}: Admin[{{.Admin}}] log in from {{.SrcAddr}}",
+		header + "unifi: {{.Key}}: Gateway[{{.Host}}] had an IPS Alert on {{.SrcAddr}} to {{.DstAddr}}",
+	}
+	controllerKeys = [...]string{
+		"EVT_WU_Connected",
+		"EVT_WU_Disconnected",
+		"EVT_WU_Roam",
+		"EVT_AP_Lost_Contact",
+		"EVT_AD_Login",
+		"EVT_IPS_IpsAlert",
+	}
+	hosts    = [...]string{"USG-Pro-4", "UDM-Pro", "UXG-Lite", "ER-X", "ER-4"}
+	ruleSets = [...]struct {
+		Name string
+		In   string
+		Out  string
+	}{
+		{Name: "WAN_LOCAL", In: "eth0", Out: ""},
+		{Name: "WAN_IN", In: "eth0", Out: "eth1"},
+		{Name: "LAN_IN", In: "eth1", Out: "eth0"},
+		{Name: "LAN_LOCAL", In: "eth1", Out: ""},
+		{Name: "GUEST_IN", In: "eth1.10", Out: "eth0"},
+		{Name: "WAN_OUT", In: "eth1", Out: "eth0"},
+	}
+	rules     = [...]string{"default", "2000", "2001", "3000", "3001", "4000"}
+	fwActions = [...]string{"A", "D", "R"}
+	protocols = [...]string{"TCP", "UDP", "ICMP"}
+	tcpFlags  = [...]string{"SYN", "ACK", "ACK PSH", "ACK FIN", "RST"}
+	aps       = [...]string{"U6-Lite-Office", "U6-LR-Lobby", "UAP-AC-Pro-Warehouse", "U6-Mesh-Patio"}
+	ssids     = [...]string{"Corp", "Corp-IoT", "Guest", "Lab"}
+	channels  = [...]string{"1(ng)", "6(ng)", "11(ng)", "36(na)", "44(na)", "149(na)"}
+	admins    = [...]string{"admin", "netops", "helpdesk"}
+)
+
+// Unifi holds the random fields for a UniFi log message.
+type Unifi struct {
+	Action       string
+	Admin        string
+	AP           string
+	APMAC        string
+	Bytes        int
+	Channel      string
+	ClientMAC    string
+	DstAddr      net.IP
+	DstMAC       string
+	DstPort      int
+	Duration     int
+	Flags        string
+	Host         string
+	ID           int
+	InInterface  string
+	Key          string
+	Length       int
+	OutInterface string
+	Protocol     string
+	Rule         string
+	RuleSet      string
+	Sequence     int
+	SrcAddr      net.IP
+	SrcMAC       string
+	SrcPort      int
+	SSID         string
+	Timestamp    time.Time
+	TTL          int
+	UDPLength    int
+	Window       int
+
+	eventType string
+	rand      *rand.Rand
+	clock     *generator.Clock
+	pins      *generator.Pins
+	templates map[string][]*template.Template
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Unifi objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &Unifi{
+		eventType: c.EventType,
+		rand:      r,
+		clock:     clock,
+		templates: make(map[string][]*template.Template),
+	}
+
+	u.pins, err = generator.NewPins(cfg, r, u)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := generator.NewTemplate(EventTypeFirewall, firewallTemplate)
+	if err != nil {
+		return nil, err
+	}
+	u.templates[EventTypeFirewall] = []*template.Template{t}
+
+	for i, v := range controllerTemplates {
+		t, err := generator.NewTemplate(controllerKeys[i], v)
+		if err != nil {
+			return nil, err
+		}
+		u.templates[EventTypeController] = append(u.templates[EventTypeController], t)
+	}
+
+	u.randomize()
+
+	return u, nil
+}
+
+// Next produces the next UniFi log message.
+//
+// Example:
+//
+// Jan  2 03:04:05 UDM-Pro kernel: [WAN_LOCAL-default-D]IN=eth0 OUT= MAC=74:ac:b9:01:02:03:fc:ec:da:04:05:06:08:00 SRC=203.0.113.5 DST=198.51.100.1 LEN=40 TOS=0x00 PREC=0x00 TTL=244 ID=54321 DF PROTO=TCP SPT=51234 DPT=22 WINDOW=1024 RES=0x00 SYN URGP=0
+func (u *Unifi) Next() ([]byte, error) {
+	var buf bytes.Buffer
+
+	eventType := u.eventType
+	if eventType == "" {
+		eventType = [...]string{EventTypeFirewall, EventTypeController}[u.rand.IntN(2)]
+	}
+	templates := u.templates[eventType]
+	t := templates[u.rand.IntN(len(templates))]
+	u.Key = t.Name()
+
+	err := t.Execute(&buf, u)
+	if err != nil {
+		return nil, err
+	}
+
+	u.randomize()
+
+	return buf.Bytes(), err
+}
+
+func (u *Unifi) randomize() {
+	rs := ruleSets[u.rand.IntN(len(ruleSets))]
+
+	u.Timestamp = u.clock.Now()
+	u.Host = hosts[u.rand.IntN(len(hosts))]
+	u.RuleSet = rs.Name
+	u.InInterface = rs.In
+	u.OutInterface = rs.Out
+	u.Rule = rules[u.rand.IntN(len(rules))]
+	if u.Rule == "default" {
+		u.Action = "D"
+	} else {
+		u.Action = fwActions[u.rand.IntN(len(fwActions))]
+	}
+	u.SrcMAC = randomMAC(u.rand)
+	u.DstMAC = randomMAC(u.rand)
+	u.SrcAddr = random.IPv4(u.rand)
+	u.DstAddr = random.IPv4(u.rand)
+	u.Protocol = protocols[u.rand.IntN(len(protocols))]
+	u.SrcPort = random.Port(u.rand)
+	u.DstPort = random.Port(u.rand)
+	u.Length = u.rand.IntN(1460) + 40
+	u.UDPLength = u.Length - 20
+	u.TTL = u.rand.IntN(255) + 1
+	u.ID = u.rand.IntN(65536)
+	u.Window = u.rand.IntN(65536)
+	u.Flags = tcpFlags[u.rand.IntN(len(tcpFlags))]
+	u.Sequence = u.rand.IntN(65536)
+
+	u.ClientMAC = randomMAC(u.rand)
+	u.AP = aps[u.rand.IntN(len(aps))]
+	u.APMAC = randomMAC(u.rand)
+	u.SSID = ssids[u.rand.IntN(len(ssids))]
+	u.Channel = channels[u.rand.IntN(len(channels))]
+	u.Duration = u.rand.IntN(60)
+	u.Bytes = u.rand.IntN(1 << 30)
+	u.Admin = admins[u.rand.IntN(len(admins))]
+
+	u.pins.Apply(u)
+}
+
+func randomMAC(r *rand.Rand) string {
+	return fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x", r.IntN(256), r.IntN(256), r.IntN(256), r.IntN(256), r.IntN(256), r.IntN(256))
+}
+
+// Templates returns the templates of the generator, for generator.Lint.
+func (u *Unifi) Templates() (interface{}, []*template.Template) {
+	var templates []*template.Template
+	for _, t := range u.templates {
+		templates = append(templates, t...)
+	}
+	return u, templates
+}
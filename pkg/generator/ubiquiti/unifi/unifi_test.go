@@ -0,0 +1,37 @@
+package unifi
+
+import (
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		template string
+		expected string
+	}{
+		"Firewall":            {template: firewallTemplate, expected: `Jan  2 03:04:05 UXG-Lite kernel: [WAN_OUT-4000-R]IN=eth1 OUT=eth0 MAC=1a:2e:cb:22:28:27:2a:a2:98:bf:83:37:08:00 SRC=102.254.241.214 DST=180.47.236.207 LEN=323 TOS=0x00 PREC=0x00 TTL=52 ID=16735 DF PROTO=TCP SPT=51672 DPT=13853 WINDOW=40858 RES=0x00 ACK PSH URGP=0`},
+		"ControllerConnected": {template: controllerTemplates[0], expected: `Jan  2 03:04:05 UXG-Lite unifi: ControllerConnected: User[1d:0e:0b:a6:83:8d] has connected to AP[U6-Mesh-Patio](3f:39:52:f4:a9:0e) with SSID "Corp-IoT" on "channel 44(na)"`},
+		"ControllerRoam":      {template: controllerTemplates[2], expected: `Jan  2 03:04:05 UXG-Lite unifi: ControllerRoam: User[1d:0e:0b:a6:83:8d] roams from AP[U6-Mesh-Patio] to AP[3f:39:52:f4:a9:0e] on "channel 44(na)"`},
+	}
+	testTime, err := time.Parse(time.RFC3339, "1970-01-02T03:04:05Z")
+	assert.Nil(t, err)
+	for name, tc := range tests {
+		random.Seed(1)
+		u := &Unifi{rand: random.NewRand(nil)}
+		templ, err := generator.NewTemplate(name, tc.template)
+		assert.Nil(t, err)
+		u.templates = map[string][]*template.Template{EventTypeFirewall: {templ}}
+		u.eventType = EventTypeFirewall
+		u.randomize()
+		u.Timestamp = testTime
+		got, err := u.Next()
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, string(got), name)
+	}
+}
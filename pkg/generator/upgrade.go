@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+)
+
+type upgradeOptions struct {
+	Upgrade *upgradeConfig `config:"upgrade"`
+}
+
+type upgradeConfig struct {
+	Version string `config:"version" validate:"required"`
+	At      string `config:"at" validate:"required"`
+}
+
+func (c *upgradeConfig) Validate() error {
+	if _, err := time.Parse(time.RFC3339Nano, c.At); err != nil {
+		return fmt.Errorf("'%s' is not a valid value for 'at' expected an RFC 3339 time", c.At)
+	}
+	return nil
+}
+
+// Upgrade is the upgrade of the devices of a generator with the
+// "upgrade" option to a newer version mid-run, so the field drift of
+// an upgrade shows in one run.  From "at" on, by the event timestamps,
+// the generator uses the templates of "version":
+//
+//	generator:
+//	  type: paloalto:panos
+//	  version: 9
+//	  upgrade:
+//	    version: 10
+//	    at: "2024-03-01T12:00:00Z"
+//
+// The version is a version of the generator newer than the one it
+// starts with.
+type Upgrade struct {
+	Version string
+	at      time.Time
+}
+
+// NewUpgrade returns the Upgrade of the "upgrade" option in the
+// ucfg.Config, nil without the option.  version is the version the
+// generator starts with and versions are the versions of the
+// generator, from the oldest to the newest.
+func NewUpgrade(cfg *ucfg.Config, version string, versions []string) (*Upgrade, error) {
+	c := upgradeOptions{}
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	if c.Upgrade == nil {
+		return nil, nil
+	}
+	from, to := -1, -1
+	for i, v := range versions {
+		if v == version {
+			from = i
+		}
+		if v == c.Upgrade.Version {
+			to = i
+		}
+	}
+	if to < 0 {
+		return nil, fmt.Errorf("'%s' is not a valid value for 'upgrade.version' expected '%s'", c.Upgrade.Version, strings.Join(versions, ", "))
+	}
+	if to <= from {
+		return nil, fmt.Errorf("'%s' is not a valid value for 'upgrade.version' expected a version after '%s'", c.Upgrade.Version, version)
+	}
+	// Validate checked the time.
+	at, _ := time.Parse(time.RFC3339Nano, c.Upgrade.At)
+	return &Upgrade{Version: c.Upgrade.Version, at: at}, nil
+}
+
+// Upgraded reports whether the event at now is of the upgraded
+// version.  Without the "upgrade" option it is never.
+func (u *Upgrade) Upgraded(now time.Time) bool {
+	return u != nil && !now.Before(u.at)
+}
@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+var testVersions = []string{"6", "7", "8"}
+
+func TestUpgrade(t *testing.T) {
+	cfg := ucfg.MustNewFrom(map[string]interface{}{
+		"type":    "test",
+		"upgrade": map[string]interface{}{"version": 7, "at": "2024-03-01T12:00:00Z"},
+	})
+	u, err := NewUpgrade(cfg, "6", testVersions)
+	assert.Nil(t, err)
+	assert.Equal(t, "7", u.Version)
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.False(t, u.Upgraded(at.Add(-time.Nanosecond)))
+	assert.True(t, u.Upgraded(at))
+	assert.True(t, u.Upgraded(at.Add(time.Hour)))
+
+	// Without the option there is no upgrade.
+	u, err = NewUpgrade(ucfg.MustNewFrom(map[string]interface{}{"type": "test"}), "6", testVersions)
+	assert.Nil(t, err)
+	assert.Nil(t, u)
+	assert.False(t, u.Upgraded(at))
+}
+
+func TestUpgradeErrors(t *testing.T) {
+	tests := map[string]struct {
+		upgrade     map[string]interface{}
+		errorString string
+	}{
+		"Unknown Version": {
+			upgrade:     map[string]interface{}{"version": 11, "at": "2024-03-01T12:00:00Z"},
+			errorString: "'11' is not a valid value for 'upgrade.version' expected '6, 7, 8'",
+		},
+		"Older Version": {
+			upgrade:     map[string]interface{}{"version": 6, "at": "2024-03-01T12:00:00Z"},
+			errorString: "'6' is not a valid value for 'upgrade.version' expected a version after '7'",
+		},
+		"Invalid At": {
+			upgrade:     map[string]interface{}{"version": 8, "at": "noon"},
+			errorString: "'noon' is not a valid value for 'at' expected an RFC 3339 time accessing 'upgrade'",
+		},
+		"Missing At": {
+			upgrade:     map[string]interface{}{"version": 8},
+			errorString: "string value is not set accessing 'upgrade.at'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			cfg := ucfg.MustNewFrom(map[string]interface{}{"type": "test", "upgrade": tc.upgrade})
+			_, err := NewUpgrade(cfg, "7", testVersions)
+			assert.EqualError(t, err, tc.errorString)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package wasm
+
+import (
+	"fmt"
+)
+
+type config struct {
+	Type         string              `config:"type" validate:"required"`
+	Module       string              `config:"module" validate:"required"`
+	Dictionaries map[string][]string `config:"dictionaries"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type: Name,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package wasm
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name, "module": "testdata/users.wasm"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "module": "testdata/users.wasm"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'wasm' accessing config",
+		},
+		"No Module": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    true,
+			errorString: "string value is not set accessing 'module'",
+		},
+		"Missing Module": {
+			c:           map[string]interface{}{"type": Name, "module": "testdata/missing.wasm"},
+			hasError:    true,
+			errorString: "open testdata/missing.wasm: no such file or directory",
+		},
+		"Invalid Module": {
+			c:           map[string]interface{}{"type": Name, "module": "testdata/users.wat"},
+			hasError:    true,
+			errorString: "Error loading wasm module 'testdata/users.wat': invalid magic number",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			_, err = New(c)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
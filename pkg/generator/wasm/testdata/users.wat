@@ -0,0 +1,20 @@
+;; users.wasm is this module.  Each call of next emits "login user="
+;; followed by a random entry of the "users" dictionary.
+(module
+  (import "spigot" "rand_intn" (func $rand_intn (param i32) (result i32)))
+  (import "spigot" "dict_len" (func $dict_len (param i32 i32) (result i32)))
+  (import "spigot" "dict_get" (func $dict_get (param i32 i32 i32 i32 i32) (result i32)))
+  (import "spigot" "emit" (func $emit (param i32 i32)))
+  (memory (export "memory") 1)
+  (data (i32.const 0) "users")
+  (data (i32.const 16) "login user=")
+  (func (export "next") (result i32)
+    (local $n i32)
+    (local.set $n
+      (call $dict_get (i32.const 0) (i32.const 5)
+        (call $rand_intn (call $dict_len (i32.const 0) (i32.const 5)))
+        (i32.const 27) (i32.const 64)))
+    (if (i32.lt_s (local.get $n) (i32.const 0))
+      (then (return (i32.const 1))))
+    (call $emit (i32.const 16) (i32.add (i32.const 11) (local.get $n)))
+    (i32.const 0)))
@@ -0,0 +1,214 @@
+// Package wasm generates log messages with a user defined WebAssembly
+// module.
+//
+// The module runs in a sandbox with the WASI preview 1 API and a host
+// module named "spigot" with the following functions:
+//
+//	rand_intn(n i32) i32
+//	    Random number in [0, n), 0 if n is not positive.
+//	rand_float64() f64
+//	    Random number in [0.0, 1.0).
+//	clock_now() i64
+//	    Time of the message in nanoseconds since the Unix epoch, the
+//	    current time unless the "timestamp" option is set, see
+//	    generator.Clock.
+//	dict_len(name_ptr, name_len i32) i32
+//	    Number of entries in the named dictionary, -1 if there is none.
+//	dict_get(name_ptr, name_len, index, buf_ptr, buf_len i32) i32
+//	    Copies up to buf_len bytes of an entry of the named dictionary
+//	    to buf_ptr and returns the length of the entry, -1 if there is
+//	    no such entry.
+//	emit(ptr, len i32)
+//	    Sets the log message.
+//
+// The module must export its memory as "memory" and a function
+// "next() i32" that calls emit and returns 0, any other value is
+// returned as an error.  If the module exports "_initialize" it is
+// called once when the module is loaded.
+//
+// Dictionaries are lists of strings from the configuration, so the
+// same module can be used with different users, hosts and so on.
+//
+// Configuration:
+//
+//	module: Path of the .wasm file, required.
+//	dictionaries: Optional map of dictionary name to list of strings.
+//
+//	- generator:
+//	    type: wasm
+//	    module: /usr/local/lib/spigot/acme.wasm
+//	    dictionaries:
+//	      users: [alice, bob]
+package wasm
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "wasm"
+
+// Wasm holds the instantiated module.
+type Wasm struct {
+	path         string
+	dictionaries map[string][]string
+	next         api.Function
+	message      []byte
+	rand         *rand.Rand
+	staticTime   *time.Time
+	clock        *generator.Clock
+	// now is the time of the message next is emitting.
+	now time.Time
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Wasm objects.  It compiles and instantiates
+// the module.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	rnd, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := os.ReadFile(c.Module)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Wasm{
+		path:         c.Module,
+		dictionaries: c.Dictionaries,
+		rand:         rnd,
+		clock:        clock,
+	}
+
+	ctx := context.Background()
+	r := wazero.NewRuntime(ctx)
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, r); err != nil {
+		return nil, err
+	}
+	if err := w.instantiateHost(ctx, r); err != nil {
+		return nil, err
+	}
+
+	m, err := r.InstantiateWithConfig(ctx, code, wazero.NewModuleConfig().WithStartFunctions("_initialize"))
+	if err != nil {
+		return nil, fmt.Errorf("Error loading wasm module '%s': %w", c.Module, err)
+	}
+	w.next = m.ExportedFunction("next")
+	if w.next == nil {
+		return nil, fmt.Errorf("Error loading wasm module '%s': 'next' is not exported", c.Module)
+	}
+
+	return w, nil
+}
+
+// Next calls the module's next function and returns the message it
+// emitted.
+func (w *Wasm) Next() ([]byte, error) {
+	w.message = nil
+	w.now = w.getTime()
+
+	res, err := w.next.Call(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if rc := int32(res[0]); rc != 0 {
+		return nil, fmt.Errorf("%s: next returned %d", w.path, rc)
+	}
+
+	return w.message, nil
+}
+
+// instantiateHost instantiates the "spigot" host module.
+func (w *Wasm) instantiateHost(ctx context.Context, r wazero.Runtime) error {
+	_, err := r.NewHostModuleBuilder("spigot").
+		NewFunctionBuilder().WithFunc(w.randIntn).Export("rand_intn").
+		NewFunctionBuilder().WithFunc(w.rand.Float64).Export("rand_float64").
+		NewFunctionBuilder().WithFunc(w.clockNow).Export("clock_now").
+		NewFunctionBuilder().WithFunc(w.dictLen).Export("dict_len").
+		NewFunctionBuilder().WithFunc(w.dictGet).Export("dict_get").
+		NewFunctionBuilder().WithFunc(w.emit).Export("emit").
+		Instantiate(ctx)
+	return err
+}
+
+func (w *Wasm) randIntn(n int32) int32 {
+	if n <= 0 {
+		return 0
+	}
+	return w.rand.Int32N(n)
+}
+
+func (w *Wasm) clockNow() int64 {
+	return w.now.UnixNano()
+}
+
+func (w *Wasm) dictLen(_ context.Context, m api.Module, namePtr, nameLen uint32) int32 {
+	d, ok := w.dictionary(m, namePtr, nameLen)
+	if !ok {
+		return -1
+	}
+	return int32(len(d))
+}
+
+func (w *Wasm) dictGet(_ context.Context, m api.Module, namePtr, nameLen, index, bufPtr, bufLen uint32) int32 {
+	d, ok := w.dictionary(m, namePtr, nameLen)
+	if !ok || int(index) >= len(d) {
+		return -1
+	}
+	v := d[index]
+	n := len(v)
+	if n > int(bufLen) {
+		n = int(bufLen)
+	}
+	if !m.Memory().Write(bufPtr, []byte(v[:n])) {
+		return -1
+	}
+	return int32(len(v))
+}
+
+func (w *Wasm) emit(_ context.Context, m api.Module, ptr, length uint32) {
+	if b, ok := m.Memory().Read(ptr, length); ok {
+		w.message = append([]byte(nil), b...)
+	}
+}
+
+func (w *Wasm) dictionary(m api.Module, ptr, length uint32) ([]string, bool) {
+	name, ok := m.Memory().Read(ptr, length)
+	if !ok {
+		return nil, false
+	}
+	d, ok := w.dictionaries[string(name)]
+	return d, ok
+}
+
+func (w *Wasm) getTime() time.Time {
+	if w.staticTime != nil {
+		return *w.staticTime
+	}
+
+	return w.clock.Now()
+}
@@ -0,0 +1,37 @@
+package wasm
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	random.Seed(1)
+	c, err := ucfg.NewFrom(map[string]interface{}{
+		"type":         Name,
+		"module":       "testdata/users.wasm",
+		"dictionaries": map[string]interface{}{"users": []string{"alice", "bob", "carol"}},
+	})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+
+	for _, expected := range []string{"login user=carol", "login user=bob", "login user=carol"} {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, expected, string(got))
+	}
+}
+
+func TestNextError(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "module": "testdata/users.wasm"})
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+
+	_, err = g.Next()
+	assert.EqualError(t, err, "testdata/users.wasm: next returned 1")
+}
@@ -0,0 +1,315 @@
+// Package access generates Apache and Nginx web server access log
+// messages.
+//
+// The status codes, paths, referrers and user agents follow the
+// traffic of a public web site: mostly pages and their assets, with
+// search engine crawlers and scanners probing for well known admin
+// pages.  The share of 4xx and 5xx responses is set with error_rate,
+// of which four in five are client errors.  With entities the users
+// of the API requests, and their client addresses, are of the shared
+// population of package entities, as for an internal web application
+// used in working hours.
+//
+// Configuration:
+//
+//	log_format: (string, optional) The format of the access log, one of
+//	            apache_combined (the default), apache_common or nginx,
+//	            the "main" format of the default nginx.conf.
+//	error_rate: (number, optional) The percentage of responses with a
+//	            4xx or 5xx status code, from 0 to 100, defaults to 5.
+//	entities:   (bool, optional) Draw the users of the API from the
+//	            shared population.
+//	user_agents: (map, optional) The weights of the kinds of user
+//	             agents of the visitors, browser, mobile, bot and cli.
+//	             By default a flat list of browsers.
+//
+//	- generator:
+//	    type: "webserver:access"
+//	    log_format: nginx
+//	    error_rate: 20
+//	    user_agents:
+//	      browser: 60
+//	      mobile: 30
+//	      bot: 10
+package access
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/entities"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "webserver:access"
+
+const (
+	LogFormatApacheCombined = "apache_combined"
+	LogFormatApacheCommon   = "apache_common"
+	LogFormatNginx          = "nginx"
+
+	timestampFmt = "02/Jan/2006:15:04:05 -0700"
+)
+
+var (
+	logFormats = [...]string{LogFormatApacheCombined, LogFormatApacheCommon, LogFormatNginx}
+	templates  = map[string]string{
+		LogFormatApacheCommon:   `{{.ClientIP}} - {{.User}} [{{.Time}}] "{{.Method}} {{.Path}} {{.Protocol}}" {{.Status}} {{.Bytes}}`,
+		LogFormatApacheCombined: `{{.ClientIP}} - {{.User}} [{{.Time}}] "{{.Method}} {{.Path}} {{.Protocol}}" {{.Status}} {{.Bytes}} "{{.Referrer}}" "{{.UserAgent}}"`,
+		LogFormatNginx:          `{{.ClientIP}} - {{.User}} [{{.Time}}] "{{.Method}} {{.Path}} {{.Protocol}}" {{.Status}} {{.Bytes}} "{{.Referrer}}" "{{.UserAgent}}" "{{.ForwardedFor}}"`,
+	}
+
+	// The statuses are repeated to weight them.
+	okStatuses = [...]int{
+		200, 200, 200, 200, 200, 200, 200, 200, 200, 200,
+		200, 200, 200, 200, 200, 200, 304, 304, 304, 301,
+		302, 302, 206, 204,
+	}
+	clientErrors = [...]int{404, 404, 404, 404, 404, 404, 403, 403, 401, 400, 405, 429}
+	serverErrors = [...]int{500, 500, 500, 500, 502, 502, 503, 503, 504}
+
+	host  = "www.example.com"
+	pages = [...]string{
+		"/", "/", "/", "/index.html", "/about", "/blog/", "/blog/2024/03/release-notes", "/products/widgets",
+		"/products/widgets?color=blue&page=2", "/search?q=spigot", "/contact", "/pricing", "/docs/getting-started",
+	}
+	assets = [...]struct {
+		path string
+		size int
+	}{
+		{"/static/css/main.css", 48213},
+		{"/static/js/app.js", 312877},
+		{"/static/js/vendor.js", 801422},
+		{"/images/logo.png", 10933},
+		{"/images/hero.jpg", 254301},
+		{"/favicon.ico", 1150},
+		{"/fonts/inter.woff2", 97012},
+	}
+	apiPaths = [...]string{"/api/v1/status", "/api/v1/products", "/api/v1/cart", "/api/v1/login"}
+	// probes are the paths scanners request, which mostly do not
+	// exist.
+	probes = [...]string{
+		"/wp-login.php", "/.env", "/admin/", "/phpmyadmin/", "/.git/config", "/xmlrpc.php", "/cgi-bin/luci",
+		"/server-status", "/vendor/phpunit/phpunit/src/Util/PHP/eval-stdin.php", "/actuator/health",
+	}
+	users     = [...]string{"alice", "bob", "carol", "dave", "admin"}
+	referrers = [...]string{"https://www.google.com/", "https://www.bing.com/", "https://duckduckgo.com/", "https://t.co/a1B2c3D4", "https://news.ycombinator.com/"}
+	crawlers  = [...]string{
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+		"Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)",
+		"Mozilla/5.0 (compatible; YandexBot/3.0; +http://yandex.com/bots)",
+	}
+	scanners = [...]string{
+		"curl/8.4.0",
+		"python-requests/2.31.0",
+		"Go-http-client/1.1",
+		"Mozilla/5.0 zgrab/0.x",
+		"Wget/1.21.3",
+	}
+	protocols = [...]string{"HTTP/1.1", "HTTP/1.1", "HTTP/1.1", "HTTP/2.0", "HTTP/2.0", "HTTP/1.0"}
+)
+
+// Access holds the random fields of an access log message.
+type Access struct {
+	ClientIP     net.IP
+	User         string
+	Time         string
+	Method       string
+	Path         string
+	Protocol     string
+	Status       int
+	Bytes        string
+	Referrer     string
+	UserAgent    string
+	ForwardedFor string
+
+	rand       *rand.Rand
+	clock      *generator.Clock
+	pins       *generator.Pins
+	logFormat  string
+	errorRate  float64
+	userAgents *random.UserAgents
+	entities   *entities.Population
+	staticTime *time.Time
+	template   *template.Template
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Access objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Access{
+		rand:      r,
+		clock:     clock,
+		logFormat: c.LogFormat,
+		errorRate: c.ErrorRate,
+	}
+	if c.UserAgents != nil {
+		a.userAgents = random.NewUserAgents(c.UserAgents)
+	}
+
+	a.entities, err = entities.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	a.pins, err = generator.NewPins(cfg, r, a)
+	if err != nil {
+		return nil, err
+	}
+
+	a.template, err = template.New(c.LogFormat).Funcs(generator.FunctionMap).Parse(templates[c.LogFormat])
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Next produces the next access log message.
+//
+// Example:
+//
+// 66.4.203.154 - - [04/Mar/2024:12:00:00 +0000] "GET /blog/2024/03/release-notes HTTP/2.0" 200 16425 "-" "Mozilla/5.0 (iPhone; CPU iPhone OS 15_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.3 Mobile/15E148 Safari/604.1"
+func (a *Access) Next() ([]byte, error) {
+	a.randomize()
+
+	var buf bytes.Buffer
+	if err := a.template.Execute(&buf, a); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Templates returns the template of the generator, for generator.Lint.
+func (a *Access) Templates() (interface{}, []*template.Template) {
+	return a, []*template.Template{a.template}
+}
+
+func (a *Access) randomize() {
+	now := a.clock.Now()
+	if a.staticTime != nil {
+		now = *a.staticTime
+	}
+
+	a.ClientIP = random.IPv4(a.rand)
+	a.User = "-"
+	a.Time = now.Format(timestampFmt)
+	a.Method = "GET"
+	a.Protocol = protocols[a.rand.IntN(len(protocols))]
+	a.Referrer = "-"
+	if a.userAgents != nil {
+		a.UserAgent = a.userAgents.UserAgent(a.rand)
+	} else {
+		a.UserAgent = random.UserAgent(a.rand)
+	}
+	a.ForwardedFor = "-"
+	if a.rand.IntN(5) == 0 {
+		a.ForwardedFor = random.IPv4(a.rand).String()
+	}
+
+	size, probe := 0, false
+	switch n := a.rand.IntN(20); {
+	case n < 8:
+		// a page, from a search engine, a link or typed in
+		a.Path = pages[a.rand.IntN(len(pages))]
+		size = a.rand.IntN(60000) + 2000
+		switch a.rand.IntN(3) {
+		case 0:
+			a.Referrer = referrers[a.rand.IntN(len(referrers))]
+		case 1:
+			a.Referrer = "https://" + host + pages[a.rand.IntN(len(pages))]
+		}
+	case n < 16:
+		// an asset of a page
+		asset := assets[a.rand.IntN(len(assets))]
+		a.Path = asset.path
+		size = asset.size
+		a.Referrer = "https://" + host + pages[a.rand.IntN(len(pages))]
+	case n < 18:
+		// the API, used by logged in users
+		a.Path = apiPaths[a.rand.IntN(len(apiPaths))]
+		a.Method = [...]string{"GET", "GET", "POST", "PUT", "DELETE"}[a.rand.IntN(5)]
+		a.User = users[a.rand.IntN(len(users))]
+		if a.entities != nil {
+			u := a.entities.ActiveUser(a.rand, now)
+			a.User = u.Name
+			a.ClientIP = a.entities.HostOf(u).IP
+		}
+		a.UserAgent = scanners[a.rand.IntN(2)]
+		size = a.rand.IntN(4000) + 20
+	case n < 19:
+		a.Path = pages[a.rand.IntN(len(pages))]
+		a.UserAgent = crawlers[a.rand.IntN(len(crawlers))]
+		size = a.rand.IntN(60000) + 2000
+	default:
+		a.Path = probes[a.rand.IntN(len(probes))]
+		a.Method = [...]string{"GET", "GET", "HEAD", "POST"}[a.rand.IntN(4)]
+		a.UserAgent = scanners[a.rand.IntN(len(scanners))]
+		a.Protocol = "HTTP/1.1"
+		size = a.rand.IntN(400) + 150
+		probe = true
+	}
+
+	a.Status = a.status(probe)
+	if a.Status == 401 {
+		a.User = "-"
+	}
+	switch {
+	case a.Status == 204 || a.Status == 304 || a.Method == "HEAD":
+		size = 0
+	case a.Status == 206:
+		size = a.rand.IntN(size) + 1
+	case a.Status >= 300 && a.Status < 400:
+		size = a.rand.IntN(200) + 150
+	case a.Status >= 400:
+		size = a.rand.IntN(800) + 150
+	}
+
+	// Apache writes "-" for a response without a body, nginx writes 0.
+	a.Bytes = strconv.Itoa(size)
+	if size == 0 && a.logFormat != LogFormatNginx {
+		a.Bytes = "-"
+	}
+
+	a.pins.Apply(a)
+}
+
+// status returns the status of the response, an error with the error
+// rate.  The client errors of probes are 404s.
+func (a *Access) status(probe bool) int {
+	if a.rand.Float64()*100 < a.errorRate {
+		if a.rand.IntN(5) == 0 {
+			return serverErrors[a.rand.IntN(len(serverErrors))]
+		}
+		if probe {
+			return 404
+		}
+		return clientErrors[a.rand.IntN(len(clientErrors))]
+	}
+	return okStatuses[a.rand.IntN(len(okStatuses))]
+}
@@ -0,0 +1,66 @@
+package access
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		logFormat string
+		expected  string
+	}{
+		"Apache Common":   {logFormat: LogFormatApacheCommon, expected: `86.181.142.224 - - [04/Mar/2024:12:00:00 +0000] "GET /static/css/main.css HTTP/1.1" 200 48213`},
+		"Apache Combined": {logFormat: LogFormatApacheCombined, expected: `86.181.142.224 - - [04/Mar/2024:12:00:00 +0000] "GET /static/css/main.css HTTP/1.1" 200 48213 "https://www.example.com/docs/getting-started" "Mozilla/5.0 (iPad; CPU OS 15_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.3 Mobile/15E148 Safari/604.1"`},
+		"Nginx":           {logFormat: LogFormatNginx, expected: `86.181.142.224 - - [04/Mar/2024:12:00:00 +0000] "GET /static/css/main.css HTTP/1.1" 200 48213 "https://www.example.com/docs/getting-started" "Mozilla/5.0 (iPad; CPU OS 15_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.3 Mobile/15E148 Safari/604.1" "-"`},
+	}
+	testTime, err := time.Parse(time.RFC3339, "2024-03-04T12:00:00Z")
+	assert.Nil(t, err)
+	for name, tc := range tests {
+		random.Seed(1)
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "log_format": tc.logFormat})
+		assert.Nil(t, err, name)
+		g, err := New(c)
+		assert.Nil(t, err, name)
+		g.(*Access).staticTime = &testTime
+		got, err := g.Next()
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, string(got), name)
+	}
+}
+
+var combined = regexp.MustCompile(`^\d+\.\d+\.\d+\.\d+ - \S+ \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}\] "(GET|HEAD|POST|PUT|DELETE) \S+ HTTP/\d\.\d" (\d{3}) (\d+|-) "[^"]*" "[^"]*"$`)
+
+func TestErrorRate(t *testing.T) {
+	for _, rate := range []float64{0, 5, 50, 100} {
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "error_rate": rate})
+		assert.Nil(t, err)
+		g, err := New(c)
+		assert.Nil(t, err)
+
+		const n = 10000
+		errors := 0
+		for i := 0; i < n; i++ {
+			got, err := g.Next()
+			assert.Nil(t, err)
+			m := combined.FindStringSubmatch(string(got))
+			if !assert.NotNil(t, m, string(got)) {
+				continue
+			}
+			status, _ := strconv.Atoi(m[2])
+			if status >= 400 {
+				errors++
+			}
+			if status == 304 || status == 204 || m[1] == "HEAD" {
+				assert.Equal(t, "-", m[3], string(got))
+			}
+		}
+		assert.InDelta(t, rate, float64(errors)*100/n, 1.5, "error rate %v", rate)
+	}
+}
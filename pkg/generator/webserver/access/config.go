@@ -0,0 +1,36 @@
+package access
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+type config struct {
+	Type       string             `config:"type" validate:"required"`
+	LogFormat  string             `config:"log_format"`
+	ErrorRate  float64            `config:"error_rate"`
+	UserAgents map[string]float64 `config:"user_agents"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:      Name,
+		LogFormat: LogFormatApacheCombined,
+		ErrorRate: 5,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if _, ok := templates[c.LogFormat]; !ok {
+		return fmt.Errorf("'%s' is not a valid value for 'log_format' expected '%s'", c.LogFormat, strings.Join(logFormats[:], ", "))
+	}
+	if c.ErrorRate < 0 || c.ErrorRate > 100 {
+		return fmt.Errorf("'%v' is not a valid value for 'error_rate' expected a percentage from 0 to 100", c.ErrorRate)
+	}
+	return random.ValidUserAgentWeights("user_agents", c.UserAgents)
+}
@@ -0,0 +1,74 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Nginx and Error Rate": {
+			c:           map[string]interface{}{"type": Name, "log_format": "nginx", "error_rate": 12.5},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'webserver:access' accessing config",
+		},
+		"Invalid Log Format": {
+			c:           map[string]interface{}{"type": Name, "log_format": "iis"},
+			hasError:    true,
+			errorString: "'iis' is not a valid value for 'log_format' expected 'apache_combined, apache_common, nginx' accessing config",
+		},
+		"Invalid Error Rate": {
+			c:           map[string]interface{}{"type": Name, "error_rate": 101},
+			hasError:    true,
+			errorString: "'101' is not a valid value for 'error_rate' expected a percentage from 0 to 100 accessing config",
+		},
+		"Valid User Agents": {
+			c:           map[string]interface{}{"type": Name, "user_agents": map[string]interface{}{"browser": 3, "mobile": 1}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid User Agents": {
+			c:           map[string]interface{}{"type": Name, "user_agents": map[string]interface{}{"bot": -1}},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'user_agents.bot' expected a weight of 0 or more accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			_, err = New(c)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
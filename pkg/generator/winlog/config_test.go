@@ -26,7 +26,7 @@ func TestConfigs(t *testing.T) {
 		"Invalid ID": {
 			config:      map[string]interface{}{"event_id": 1},
 			hasError:    true,
-			errorString: "'1' is not a valid value for 'event_id' expected one of [4624 4634 4723 4741 4743 4768] accessing config",
+			errorString: "'1' is not a valid value for 'event_id' expected one of [4624 4634 4720 4723 4725 4728 4741 4743 4768] accessing config",
 		},
 		"No Type": {
 			config:      map[string]interface{}{"type": ""},
@@ -1,7 +1,6 @@
 package winlog
 
 import (
-	"math/rand"
 	"strconv"
 
 	"github.com/leehinman/spigot/pkg/random"
@@ -12,12 +11,12 @@ const event4624 = 4624
 // randomize4624 generates a random event with
 // ID 4624 (An account was successfully logged on).
 func randomize4624(g *Generator) Event {
-	computerName := RandomComputerName("")
+	computerName := RandomComputerName(g.rand, "")
 
 	subjectName := computerName + "$"
-	targetName := RandomUser()
+	targetName := RandomUser(g.rand)
 
-	evt := RandomEvent(event4624, g.getTime())
+	evt := RandomEvent(g.rand, event4624, g.getTime())
 	evt.Provider = Provider{
 		Name: "Microsoft-Windows-Security-Auditing",
 		GUID: "{54849625-5478-4994-A5BA-3E3B0328C30D}",
@@ -29,11 +28,11 @@ func randomize4624(g *Generator) Event {
 			{Key: "SubjectUserSid", Value: "S-1-5-18"},
 			{Key: "SubjectUserName", Value: subjectName},
 			{Key: "SubjectDomainName", Value: "WORKGROUP"},
-			{Key: "SubjectLogonId", Value: "0x" + strconv.FormatInt(int64(rand.Intn(65536)), 16)},
-			{Key: "TargetUserSid", Value: RandomUserSID(targetName)},
+			{Key: "SubjectLogonId", Value: "0x" + strconv.FormatInt(int64(g.rand.IntN(65536)), 16)},
+			{Key: "TargetUserSid", Value: RandomUserSID(g.rand, targetName)},
 			{Key: "TargetUserName", Value: targetName},
 			{Key: "TargetDomainName", Value: computerName},
-			{Key: "TargetLogonId", Value: "0x" + strconv.FormatInt(int64(rand.Intn(65536)), 16)},
+			{Key: "TargetLogonId", Value: "0x" + strconv.FormatInt(int64(g.rand.IntN(65536)), 16)},
 			{Key: "LogonType", Value: "2"},
 			{Key: "LogonProcessName", Value: "User32"},
 			{Key: "AuthenticationPackageName", Value: "Negotiate"},
@@ -42,10 +41,10 @@ func randomize4624(g *Generator) Event {
 			{Key: "TransmittedServices", Value: "-"},
 			{Key: "LmPackageName", Value: "-"},
 			{Key: "KeyLength", Value: "0"},
-			{Key: "ProcessId", Value: "0x" + strconv.FormatInt(int64(rand.Intn(65536)), 16)},
+			{Key: "ProcessId", Value: "0x" + strconv.FormatInt(int64(g.rand.IntN(65536)), 16)},
 			{Key: "ProcessName", Value: `C:\\Windows\\System32\\svchost.exe`},
-			{Key: "IpAddress", Value: random.IPv4().String()},
-			{Key: "IpPort", Value: strconv.Itoa(random.Port())},
+			{Key: "IpAddress", Value: random.IPv4(g.rand).String()},
+			{Key: "IpPort", Value: strconv.Itoa(random.Port(g.rand))},
 			{Key: "ImpersonationLevel", Value: "%%1833"},
 			{Key: "RestrictedAdminMode", Value: "-"},
 			{Key: "TargetOutboundUserName", Value: "-"},
@@ -0,0 +1,59 @@
+package winlog
+
+import (
+	"strconv"
+)
+
+const event4720 = 4720
+
+// randomize4720 generates a random event with
+// ID 4720 (A user account was created).
+func randomize4720(g *Generator) Event {
+	now := g.getTime()
+
+	domain := RandomDomain(g.rand)
+	computerName := RandomComputerName(g.rand, domain)
+
+	targetName := RandomUser(g.rand)
+	subjectName := RandomUser(g.rand)
+
+	evt := RandomEvent(g.rand, event4720, now)
+	evt.Provider = Provider{
+		Name: "Microsoft-Windows-Security-Auditing",
+		GUID: "{54849625-5478-4994-A5BA-3E3B0328C30D}",
+	}
+	evt.Channel = "Security"
+	evt.Computer = computerName
+	evt.EventData = EventData{
+		Data: []KeyValue{
+			{Key: "TargetUserName", Value: targetName},
+			{Key: "TargetDomainName", Value: domain},
+			{Key: "TargetSid", Value: RandomUserSID(g.rand, targetName)},
+			{Key: "SubjectUserSid", Value: RandomUserSID(g.rand, subjectName)},
+			{Key: "SubjectUserName", Value: subjectName},
+			{Key: "SubjectDomainName", Value: domain},
+			{Key: "SubjectLogonId", Value: "0x" + strconv.FormatInt(int64(g.rand.IntN(65536)), 16)},
+			{Key: "PrivilegeList", Value: "-"},
+			{Key: "SamAccountName", Value: targetName},
+			{Key: "DisplayName", Value: "%%1793"},
+			{Key: "UserPrincipalName", Value: targetName + "@" + domain},
+			{Key: "HomeDirectory", Value: "%%1793"},
+			{Key: "HomePath", Value: "%%1793"},
+			{Key: "ScriptPath", Value: "%%1793"},
+			{Key: "ProfilePath", Value: "%%1793"},
+			{Key: "UserWorkstations", Value: "%%1793"},
+			{Key: "PasswordLastSet", Value: "%%1794"},
+			{Key: "AccountExpires", Value: "%%1794"},
+			{Key: "PrimaryGroupId", Value: "513"},
+			{Key: "AllowedToDelegateTo", Value: "-"},
+			{Key: "OldUacValue", Value: "0x0"},
+			{Key: "NewUacValue", Value: "0x15"},
+			{Key: "UserAccountControl", Value: "%%2080 %%2082 %%2084"},
+			{Key: "UserParameters", Value: "%%1793"},
+			{Key: "SidHistory", Value: "-"},
+			{Key: "LogonHours", Value: "%%1797"},
+		},
+	}
+
+	return evt
+}
@@ -1,7 +1,6 @@
 package winlog
 
 import (
-	"math/rand"
 	"strconv"
 )
 
@@ -10,14 +9,14 @@ const event4723 = 4723
 // randomize4723 generates a random event with
 // ID 4723 (An attempt was made to change an account's password).
 func randomize4723(g *Generator) Event {
-	domain := RandomDomain()
-	hostname := RandomComputerName("")
+	domain := RandomDomain(g.rand)
+	hostname := RandomComputerName(g.rand, "")
 	computerName := hostname + "." + domain
 
 	targetName := hostname + "$"
-	subjectName := RandomUser()
+	subjectName := RandomUser(g.rand)
 
-	evt := RandomEvent(event4723, g.getTime())
+	evt := RandomEvent(g.rand, event4723, g.getTime())
 	evt.Provider = Provider{
 		Name: "Microsoft-Windows-Security-Auditing",
 		GUID: "{54849625-5478-4994-A5BA-3E3B0328C30D}",
@@ -26,13 +25,13 @@ func randomize4723(g *Generator) Event {
 	evt.Computer = computerName
 	evt.EventData = EventData{
 		Data: []KeyValue{
-			{Key: "TargetUserSid", Value: RandomUserSID(targetName)},
+			{Key: "TargetUserSid", Value: RandomUserSID(g.rand, targetName)},
 			{Key: "TargetUserName", Value: targetName},
 			{Key: "TargetDomainName", Value: domain},
-			{Key: "SubjectUserSid", Value: RandomUserSID(subjectName)},
+			{Key: "SubjectUserSid", Value: RandomUserSID(g.rand, subjectName)},
 			{Key: "SubjectUserName", Value: subjectName},
 			{Key: "SubjectDomainName", Value: domain},
-			{Key: "SubjectLogonId", Value: "0x" + strconv.FormatInt(int64(rand.Intn(65536)), 16)},
+			{Key: "SubjectLogonId", Value: "0x" + strconv.FormatInt(int64(g.rand.IntN(65536)), 16)},
 			{Key: "PrivilegeList", Value: "-"},
 		},
 	}
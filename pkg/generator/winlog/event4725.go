@@ -0,0 +1,38 @@
+package winlog
+
+import (
+	"strconv"
+)
+
+const event4725 = 4725
+
+// randomize4725 generates a random event with
+// ID 4725 (A user account was disabled).
+func randomize4725(g *Generator) Event {
+	domain := RandomDomain(g.rand)
+	computerName := RandomComputerName(g.rand, domain)
+
+	targetName := RandomUser(g.rand)
+	subjectName := RandomUser(g.rand)
+
+	evt := RandomEvent(g.rand, event4725, g.getTime())
+	evt.Provider = Provider{
+		Name: "Microsoft-Windows-Security-Auditing",
+		GUID: "{54849625-5478-4994-A5BA-3E3B0328C30D}",
+	}
+	evt.Channel = "Security"
+	evt.Computer = computerName
+	evt.EventData = EventData{
+		Data: []KeyValue{
+			{Key: "TargetUserName", Value: targetName},
+			{Key: "TargetDomainName", Value: domain},
+			{Key: "TargetSid", Value: RandomUserSID(g.rand, targetName)},
+			{Key: "SubjectUserSid", Value: RandomUserSID(g.rand, subjectName)},
+			{Key: "SubjectUserName", Value: subjectName},
+			{Key: "SubjectDomainName", Value: domain},
+			{Key: "SubjectLogonId", Value: "0x" + strconv.FormatInt(int64(g.rand.IntN(65536)), 16)},
+		},
+	}
+
+	return evt
+}
@@ -0,0 +1,45 @@
+package winlog
+
+import (
+	"strconv"
+)
+
+const event4728 = 4728
+
+// groups are the security enabled global groups of the domains.
+var groups = [...]string{"Engineering", "Sales", "Marketing", "Finance", "Human Resources", "Support", "Legal", "IT"}
+
+// randomize4728 generates a random event with
+// ID 4728 (A member was added to a security-enabled global group).
+func randomize4728(g *Generator) Event {
+	domain := RandomDomain(g.rand)
+	computerName := RandomComputerName(g.rand, domain)
+
+	memberName := RandomUser(g.rand)
+	subjectName := RandomUser(g.rand)
+	group := groups[g.rand.IntN(len(groups))]
+
+	evt := RandomEvent(g.rand, event4728, g.getTime())
+	evt.Provider = Provider{
+		Name: "Microsoft-Windows-Security-Auditing",
+		GUID: "{54849625-5478-4994-A5BA-3E3B0328C30D}",
+	}
+	evt.Channel = "Security"
+	evt.Computer = computerName
+	evt.EventData = EventData{
+		Data: []KeyValue{
+			{Key: "MemberName", Value: "CN=" + memberName + ",CN=Users,DC=" + domain},
+			{Key: "MemberSid", Value: RandomUserSID(g.rand, memberName)},
+			{Key: "TargetUserName", Value: group},
+			{Key: "TargetDomainName", Value: domain},
+			{Key: "TargetSid", Value: RandomServiceSID(g.rand, group)},
+			{Key: "SubjectUserSid", Value: RandomUserSID(g.rand, subjectName)},
+			{Key: "SubjectUserName", Value: subjectName},
+			{Key: "SubjectDomainName", Value: domain},
+			{Key: "SubjectLogonId", Value: "0x" + strconv.FormatInt(int64(g.rand.IntN(65536)), 16)},
+			{Key: "PrivilegeList", Value: "-"},
+		},
+	}
+
+	return evt
+}
@@ -2,7 +2,6 @@ package winlog
 
 import (
 	"fmt"
-	"math/rand"
 	"strconv"
 )
 
@@ -13,14 +12,14 @@ const event4741 = 4741
 func randomize4741(g *Generator) Event {
 	now := g.getTime()
 
-	domain := RandomDomain()
-	hostname := RandomComputerName("")
+	domain := RandomDomain(g.rand)
+	hostname := RandomComputerName(g.rand, "")
 	computerName := hostname + "." + domain
 
 	targetName := hostname + "$"
-	subjectName := RandomUser()
+	subjectName := RandomUser(g.rand)
 
-	evt := RandomEvent(event4741, g.getTime())
+	evt := RandomEvent(g.rand, event4741, now)
 	evt.Provider = Provider{
 		Name: "Microsoft-Windows-Security-Auditing",
 		GUID: "{54849625-5478-4994-A5BA-3E3B0328C30D}",
@@ -29,13 +28,13 @@ func randomize4741(g *Generator) Event {
 	evt.Computer = computerName
 	evt.EventData = EventData{
 		Data: []KeyValue{
-			{Key: "TargetUserSid", Value: RandomUserSID(targetName)},
+			{Key: "TargetUserSid", Value: RandomUserSID(g.rand, targetName)},
 			{Key: "TargetUserName", Value: targetName},
 			{Key: "TargetDomainName", Value: domain},
-			{Key: "SubjectUserSid", Value: RandomUserSID(subjectName)},
+			{Key: "SubjectUserSid", Value: RandomUserSID(g.rand, subjectName)},
 			{Key: "SubjectUserName", Value: subjectName},
 			{Key: "SubjectDomainName", Value: domain},
-			{Key: "SubjectLogonId", Value: "0x" + strconv.FormatInt(int64(rand.Intn(65536)), 16)},
+			{Key: "SubjectLogonId", Value: "0x" + strconv.FormatInt(int64(g.rand.IntN(65536)), 16)},
 			{Key: "PrivilegeList", Value: "-"},
 			{Key: "SamAccountName", Value: hostname + "$"},
 			{Key: "DisplayName", Value: "-"},
@@ -47,7 +46,7 @@ func randomize4741(g *Generator) Event {
 			{Key: "UserWorkstations", Value: "-"},
 			{Key: "PasswordLastSet", Value: now.Format("2/1/2006 03:04:05 PM")},
 			{Key: "AccountExpires", Value: "%%1794"},
-			{Key: "PrimaryGroupId", Value: strconv.Itoa(rand.Intn(10000))},
+			{Key: "PrimaryGroupId", Value: strconv.Itoa(g.rand.IntN(10000))},
 			{Key: "AllowedToDelegateTo", Value: "-"},
 			{Key: "OldUacValue", Value: "0x0"},
 			{Key: "NewUacValue", Value: "0x80"},
@@ -2,7 +2,7 @@ package winlog
 
 import (
 	"fmt"
-	"math/rand"
+	"math/rand/v2"
 	"strconv"
 	"sync"
 	"time"
@@ -16,14 +16,14 @@ var (
 )
 
 // RandomUser generates a random user name.
-func RandomUser() string {
-	return "user" + strconv.Itoa(rand.Intn(100))
+func RandomUser(r *rand.Rand) string {
+	return "user" + strconv.Itoa(r.IntN(100))
 }
 
 // RandomComputerName generates a random computer name. If domain is provided,
 // it will be app.
-func RandomComputerName(domain string) string {
-	name := "COMPUTER-" + strconv.Itoa(rand.Intn(1000))
+func RandomComputerName(r *rand.Rand, domain string) string {
+	name := "COMPUTER-" + strconv.Itoa(r.IntN(1000))
 	if domain != "" {
 		name += "." + domain
 	}
@@ -32,24 +32,24 @@ func RandomComputerName(domain string) string {
 }
 
 // RandomDomain generates a random domain.
-func RandomDomain() string {
-	return "DOMAIN-" + strconv.Itoa(rand.Intn(10))
+func RandomDomain(r *rand.Rand) string {
+	return "DOMAIN-" + strconv.Itoa(r.IntN(10))
 }
 
 // RandomSID generates a random SID.
-func RandomSID() string {
+func RandomSID(r *rand.Rand) string {
 	return fmt.Sprintf(
 		"S-1-5-21-%d-%d-%d-%d",
-		rand.Intn(1<<32),
-		rand.Intn(1<<32),
-		rand.Intn(1<<32),
-		rand.Intn(1<<16),
+		r.IntN(1<<32),
+		r.IntN(1<<32),
+		r.IntN(1<<32),
+		r.IntN(1<<16),
 	)
 }
 
 // RandomServiceSID generates a random SID for a service with name. If a SID
 // has already been generated for this name, it will be returned.
-func RandomServiceSID(name string) string {
+func RandomServiceSID(r *rand.Rand, name string) string {
 	mapMu.Lock()
 	defer mapMu.Unlock()
 
@@ -57,7 +57,7 @@ func RandomServiceSID(name string) string {
 		return sid
 	}
 
-	sid := RandomSID()
+	sid := RandomSID(r)
 	serviceSIDMap[name] = sid
 
 	return sid
@@ -65,7 +65,7 @@ func RandomServiceSID(name string) string {
 
 // RandomUserSID generates a random SID for a user with name. If a SID
 // has already been generated for this name, it will be returned.
-func RandomUserSID(name string) string {
+func RandomUserSID(r *rand.Rand, name string) string {
 	mapMu.Lock()
 	defer mapMu.Unlock()
 
@@ -73,27 +73,27 @@ func RandomUserSID(name string) string {
 		return sid
 	}
 
-	sid := RandomSID()
+	sid := RandomSID(r)
 	userSIDMap[name] = sid
 
 	return sid
 }
 
-func RandomEvent(eventID uint32, now time.Time) Event {
+func RandomEvent(r *rand.Rand, eventID uint32, now time.Time) Event {
 	return Event{
 		EventID: EventID{
 			ID: eventID,
 		},
-		Task:     uint16(rand.Intn(65536)),
+		Task:     uint16(r.IntN(65536)),
 		Keywords: 0x8020000000000000,
 		TimeCreated: TimeCreated{
 			SystemTime: now,
 		},
-		RecordID:    rand.Uint64(),
+		RecordID:    r.Uint64(),
 		Correlation: Correlation{},
 		Execution: Execution{
-			ProcessID: uint32(rand.Intn(65536)),
-			ThreadID:  uint32(rand.Intn(65536)),
+			ProcessID: uint32(r.IntN(65536)),
+			ThreadID:  uint32(r.IntN(65536)),
 		},
 	}
 }
@@ -15,7 +15,7 @@ package winlog
 import (
 	"encoding/xml"
 	"fmt"
-	"math/rand"
+	"math/rand/v2"
 	"sort"
 	"time"
 
@@ -31,7 +31,10 @@ var (
 	eventRandomizers = map[int]randomizerFunc{
 		event4624: randomize4624,
 		event4634: randomize4634,
+		event4720: randomize4720,
 		event4723: randomize4723,
+		event4725: randomize4725,
+		event4728: randomize4728,
 		event4741: randomize4741,
 		event4743: randomize4743,
 		event4768: randomize4768,
@@ -128,7 +131,9 @@ type Generator struct {
 	Event Event
 
 	eventID    *int
+	rand       *rand.Rand
 	staticTime *time.Time
+	clock      *generator.Clock
 }
 
 // Next produces the next Windows Event XML record.
@@ -138,7 +143,7 @@ func (g *Generator) Next() ([]byte, error) {
 	if g.eventID != nil {
 		eventID = *g.eventID
 	} else {
-		eventID = eventIDs[rand.Intn(len(eventIDs))]
+		eventID = eventIDs[g.rand.IntN(len(eventIDs))]
 	}
 	fn, ok := eventRandomizers[eventID]
 	if !ok {
@@ -160,7 +165,7 @@ func (g *Generator) getTime() time.Time {
 		return *g.staticTime
 	}
 
-	return time.Now()
+	return g.clock.Now()
 }
 
 // New is the factory for Windows Event XML objects.
@@ -170,7 +175,16 @@ func New(cfg *ucfg.Config) (generator.Generator, error) {
 		return nil, err
 	}
 
-	g := Generator{}
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	g := Generator{rand: r, clock: clock}
 	if c.EventID > 0 {
 		g.eventID = &c.EventID
 	}
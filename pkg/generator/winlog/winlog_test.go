@@ -3,13 +3,13 @@ package winlog
 import (
 	"flag"
 	"io/ioutil"
-	"math/rand"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -59,10 +59,22 @@ func TestGenerator_Next(t *testing.T) {
 			config:       map[string]interface{}{"event_id": event4634},
 			expectedFile: "event4634.xml",
 		},
+		"event4720": {
+			config:       map[string]interface{}{"event_id": event4720},
+			expectedFile: "event4720.xml",
+		},
 		"event4723": {
 			config:       map[string]interface{}{"event_id": event4723},
 			expectedFile: "event4723.xml",
 		},
+		"event4725": {
+			config:       map[string]interface{}{"event_id": event4725},
+			expectedFile: "event4725.xml",
+		},
+		"event4728": {
+			config:       map[string]interface{}{"event_id": event4728},
+			expectedFile: "event4728.xml",
+		},
 		"event4741": {
 			config:       map[string]interface{}{"event_id": event4741},
 			expectedFile: "event4741.xml",
@@ -83,7 +95,7 @@ func TestGenerator_Next(t *testing.T) {
 	for name, tc := range tests {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
-			rand.Seed(1)
+			random.Seed(1)
 			// Reset package state. This test MUST NOT run in parallel.
 			serviceSIDMap = map[string]string{}
 			userSIDMap = map[string]string{}
@@ -0,0 +1,32 @@
+package zeek
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Type      string `config:"type" validate:"required"`
+	Log       string `config:"log"`
+	LogFormat string `config:"log_format"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:      Name,
+		LogFormat: LogFormatTSV,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if !(c.Log == "" || c.Log == LogConn || c.Log == LogDNS || c.Log == LogHTTP) {
+		return fmt.Errorf("'%s' is not a valid value for 'log' expected '%s'", c.Log, strings.Join([]string{LogConn, LogDNS, LogHTTP}, ", "))
+	}
+	if c.LogFormat != LogFormatTSV && c.LogFormat != LogFormatJSON {
+		return fmt.Errorf("'%s' is not a valid value for 'log_format' expected '%s'", c.LogFormat, strings.Join([]string{LogFormatTSV, LogFormatJSON}, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package zeek
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with DNS and JSON": {
+			c:           map[string]interface{}{"type": Name, "log": "dns", "log_format": "json"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'zeek' accessing config",
+		},
+		"Invalid Log": {
+			c:           map[string]interface{}{"type": Name, "log": "ssl"},
+			hasError:    true,
+			errorString: "'ssl' is not a valid value for 'log' expected 'conn, dns, http' accessing config",
+		},
+		"Invalid Log Format": {
+			c:           map[string]interface{}{"type": Name, "log_format": "csv"},
+			hasError:    true,
+			errorString: "'csv' is not a valid value for 'log_format' expected 'tsv, json' accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			_, err = New(c)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,409 @@
+// Package zeek generates Zeek conn.log, dns.log and http.log records.
+//
+// Every generated connection has a conn.log record and, for DNS and
+// HTTP connections, a dns.log or http.log record with the same uid
+// and connection tuple, so conn and protocol logs can be joined the
+// way they are for real Zeek logs.  The protocol record is written
+// before the conn record, as Zeek writes conn.log when the connection
+// ends.
+//
+// Records are written in Zeek's TSV format or as JSON.  In TSV the
+// header of a log, with its #fields and #types lines, is written
+// before its first record.  When all logs are generated JSON records
+// have a "_path" field with the name of their log.
+//
+// Configuration:
+//
+//	log:        Specify the log to generate, or leave blank for all.
+//	            Valid values are: conn, dns, http.
+//	log_format: tsv or json, defaults to tsv.
+//
+//	- generator:
+//	    type: zeek
+//	    log: conn
+//	    log_format: json
+package zeek
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "zeek"
+
+const (
+	LogConn = "conn"
+	LogDNS  = "dns"
+	LogHTTP = "http"
+)
+
+const (
+	LogFormatTSV  = "tsv"
+	LogFormatJSON = "json"
+)
+
+const idChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// column is a field of a log and its Zeek type.
+type column struct {
+	name string
+	typ  string
+}
+
+var (
+	connection = []column{
+		{"ts", "time"}, {"uid", "string"}, {"id.orig_h", "addr"}, {"id.orig_p", "port"}, {"id.resp_h", "addr"}, {"id.resp_p", "port"},
+	}
+	columns = map[string][]column{
+		LogConn: append(connection[:6:6],
+			column{"proto", "enum"}, column{"service", "string"}, column{"duration", "interval"}, column{"orig_bytes", "count"},
+			column{"resp_bytes", "count"}, column{"conn_state", "string"}, column{"local_orig", "bool"}, column{"local_resp", "bool"},
+			column{"missed_bytes", "count"}, column{"history", "string"}, column{"orig_pkts", "count"}, column{"orig_ip_bytes", "count"},
+			column{"resp_pkts", "count"}, column{"resp_ip_bytes", "count"}, column{"tunnel_parents", "set[string]"}),
+		LogDNS: append(connection[:6:6],
+			column{"proto", "enum"}, column{"trans_id", "count"}, column{"rtt", "interval"}, column{"query", "string"},
+			column{"qclass", "count"}, column{"qclass_name", "string"}, column{"qtype", "count"}, column{"qtype_name", "string"},
+			column{"rcode", "count"}, column{"rcode_name", "string"}, column{"AA", "bool"}, column{"TC", "bool"}, column{"RD", "bool"},
+			column{"RA", "bool"}, column{"Z", "count"}, column{"answers", "vector[string]"}, column{"TTLs", "vector[interval]"},
+			column{"rejected", "bool"}),
+		LogHTTP: append(connection[:6:6],
+			column{"trans_depth", "count"}, column{"method", "string"}, column{"host", "string"}, column{"uri", "string"},
+			column{"referrer", "string"}, column{"version", "string"}, column{"user_agent", "string"}, column{"origin", "string"},
+			column{"request_body_len", "count"}, column{"response_body_len", "count"}, column{"status_code", "count"},
+			column{"status_msg", "string"}, column{"info_code", "count"}, column{"info_msg", "string"}, column{"tags", "set[enum]"},
+			column{"username", "string"}, column{"password", "string"}, column{"proxied", "set[string]"},
+			column{"orig_fuids", "vector[string]"}, column{"orig_filenames", "vector[string]"}, column{"orig_mime_types", "vector[string]"},
+			column{"resp_fuids", "vector[string]"}, column{"resp_filenames", "vector[string]"}, column{"resp_mime_types", "vector[string]"}),
+	}
+
+	services = [...]struct {
+		name  string
+		proto string
+		port  int
+	}{
+		{"dns", "udp", 53},
+		{"http", "tcp", 80},
+		{"ssl", "tcp", 443},
+		{"ssh", "tcp", 22},
+		{"ntp", "udp", 123},
+	}
+	dnsServers = [...]string{"10.0.0.53", "10.0.1.53", "8.8.8.8", "1.1.1.1"}
+	domains    = [...]string{"www.silverpinevalley.com", "api.brickstoneridge.net", "cdn.oakwoodgrove.org", "mail.bluewaterhaven.co", "update.copperhollow.info", "www.windyriverplains.com", "login.crystalbayvillage.net", "static.ironwoodcove.org"}
+	qtypes     = [...]struct {
+		id   int
+		name string
+	}{{1, "A"}, {28, "AAAA"}, {15, "MX"}, {16, "TXT"}, {5, "CNAME"}}
+	paths     = [...]string{"/", "/index.html", "/api/v1/status", "/login", "/images/logo.png", "/wp-login.php", "/search?q=spigot", "/static/app.js"}
+	mimeTypes = map[string]string{"/images/logo.png": "image/png", "/static/app.js": "application/javascript", "/api/v1/status": "application/json"}
+)
+
+// record is a line of a log, the values of its columns.  A nil value
+// is unset.
+type record struct {
+	log    string
+	values []interface{}
+}
+
+// Zeek holds the state of the Zeek log generator.
+type Zeek struct {
+	rand    *rand.Rand
+	log     string
+	json    bool
+	headers map[string]bool
+	pending []record
+	now     func() time.Time
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Zeek objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	rnd, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Zeek{
+		rand:    rnd,
+		log:     c.Log,
+		json:    c.LogFormat == LogFormatJSON,
+		headers: make(map[string]bool),
+		now:     clock.Now,
+	}, nil
+}
+
+// Next produces the next Zeek log record.
+//
+// Example:
+//
+// 1709553595.751018	CUeoqH7yWxVdB5p7sm	10.23.92.216	51802	8.8.8.8	53	udp	dns	0.033484	41	73	SF	T	F	0	Dd	1	69	1	101	(empty)
+func (z *Zeek) Next() ([]byte, error) {
+	for len(z.pending) == 0 {
+		z.connection()
+	}
+	r := z.pending[0]
+	z.pending = z.pending[1:]
+
+	if z.json {
+		return z.marshal(r)
+	}
+
+	var buf bytes.Buffer
+	if !z.headers[r.log] {
+		z.headers[r.log] = true
+		z.header(&buf, r.log)
+	}
+	for i, v := range r.values {
+		if i > 0 {
+			buf.WriteByte('\t')
+		}
+		buf.WriteString(tsv(v))
+	}
+	return buf.Bytes(), nil
+}
+
+// header writes the TSV header of log.
+func (z *Zeek) header(buf *bytes.Buffer, log string) {
+	names := make([]string, len(columns[log]))
+	types := make([]string, len(columns[log]))
+	for i, c := range columns[log] {
+		names[i], types[i] = c.name, c.typ
+	}
+	fmt.Fprintf(buf, "#separator \\x09\n#set_separator\t,\n#empty_field\t(empty)\n#unset_field\t-\n#path\t%s\n#open\t%s\n", log, z.now().Format("2006-01-02-15-04-05"))
+	fmt.Fprintf(buf, "#fields\t%s\n#types\t%s\n", strings.Join(names, "\t"), strings.Join(types, "\t"))
+}
+
+// marshal returns the JSON object of r, with the fields in the order
+// of the columns.  Unset fields are left out.
+func (z *Zeek) marshal(r record) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	if z.log == "" {
+		fmt.Fprintf(&buf, `"_path":%q`, r.log)
+	}
+	for i, v := range r.values {
+		if v == nil {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		b, err := json.Marshal(jsonValue(v))
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "%q:%s", columns[r.log][i].name, b)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// tsv formats a value the way Zeek's TSV writer does.
+func tsv(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "-"
+	case time.Time:
+		return seconds(float64(v.UnixNano()) / 1e9)
+	case time.Duration:
+		return seconds(v.Seconds())
+	case bool:
+		if v {
+			return "T"
+		}
+		return "F"
+	case []string:
+		if len(v) == 0 {
+			return "(empty)"
+		}
+		return strings.Join(v, ",")
+	case []time.Duration:
+		if len(v) == 0 {
+			return "(empty)"
+		}
+		s := make([]string, len(v))
+		for i, d := range v {
+			s[i] = seconds(d.Seconds())
+		}
+		return strings.Join(s, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// jsonValue converts a value to the value of Zeek's JSON writer.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case time.Time:
+		return json.Number(seconds(float64(v.UnixNano()) / 1e9))
+	case time.Duration:
+		return json.Number(seconds(v.Seconds()))
+	case net.IP:
+		return v.String()
+	case []time.Duration:
+		s := make([]json.Number, len(v))
+		for i, d := range v {
+			s[i] = json.Number(seconds(d.Seconds()))
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+func seconds(s float64) string {
+	return strconv.FormatFloat(s, 'f', 6, 64)
+}
+
+// connection generates the records of a new connection and queues
+// the ones of the configured log.
+func (z *Zeek) connection() {
+	svc := services[z.rand.IntN(len(services))]
+	ts := z.now().Add(-time.Duration(z.rand.IntN(5000000)) * time.Microsecond)
+	uid := z.id('C')
+	orig := net.IPv4(10, byte(z.rand.IntN(32)), byte(z.rand.IntN(256)), byte(z.rand.IntN(254)+1))
+	origPort := random.Port(z.rand)%(65536-49152) + 49152
+	resp := random.IPv4(z.rand)
+	if svc.name == "dns" {
+		resp = net.ParseIP(dnsServers[z.rand.IntN(len(dnsServers))])
+	}
+	id := []interface{}{ts, uid, orig, origPort, resp, svc.port}
+
+	// one in ten TCP connections is never answered
+	state, history := "SF", "ShADadFf"
+	if svc.proto == "udp" {
+		state, history = "SF", "Dd"
+	} else if z.rand.IntN(10) == 0 {
+		state, history = "S0", "S"
+	}
+
+	origPkts, respPkts := 1, 1
+	origBytes, respBytes := 0, 0
+	duration := time.Duration(z.rand.IntN(2000000)) * time.Microsecond
+	var service interface{} = svc.name
+
+	switch {
+	case state == "S0":
+		respPkts = 0
+		service = nil
+		origPkts = z.rand.IntN(3) + 1
+		duration = time.Duration(z.rand.IntN(3)) * time.Second
+	case svc.name == "dns":
+		query := domains[z.rand.IntN(len(domains))]
+		origBytes = len(query) + 18
+		qtype := qtypes[z.rand.IntN(len(qtypes))]
+		rcode, rcodeName := 0, "NOERROR"
+		var answers []string
+		var ttls []time.Duration
+		if z.rand.IntN(10) == 0 {
+			rcode, rcodeName = 3, "NXDOMAIN"
+		} else {
+			for i := z.rand.IntN(3) + 1; i > 0; i-- {
+				answers = append(answers, dnsAnswer(z.rand, qtype.name, query))
+				ttls = append(ttls, time.Duration(z.rand.IntN(3600)+60)*time.Second)
+			}
+		}
+		respBytes = origBytes + 16*len(answers)
+		duration = time.Duration(z.rand.IntN(50000)+500) * time.Microsecond
+		z.pending = z.queue(z.pending, LogDNS, append(id[:6:6],
+			svc.proto, z.rand.IntN(65536), duration, query, 1, "C_INTERNET", qtype.id, qtype.name,
+			rcode, rcodeName, false, false, true, true, 0, answers, ttls, false))
+	case svc.name == "http":
+		path := paths[z.rand.IntN(len(paths))]
+		host := domains[z.rand.IntN(len(domains))]
+		method := [...]string{"GET", "GET", "GET", "POST"}[z.rand.IntN(4)]
+		status := [...]int{200, 200, 200, 200, 301, 304, 404, 403, 500}[z.rand.IntN(9)]
+		requestLen := 0
+		if method == "POST" {
+			requestLen = z.rand.IntN(4096) + 16
+		}
+		responseLen := z.rand.IntN(64000) + 128
+		mime := "text/html"
+		if m, ok := mimeTypes[path]; ok {
+			mime = m
+		}
+		var referrer interface{}
+		if z.rand.IntN(2) == 0 {
+			referrer = "http://" + host + "/"
+		}
+		origPkts = z.rand.IntN(10) + 3
+		respPkts = responseLen/1448 + 3
+		origBytes = requestLen + 350
+		respBytes = responseLen + 250
+		z.pending = z.queue(z.pending, LogHTTP, append(id[:6:6],
+			1, method, host, path, referrer, "1.1", random.UserAgent(z.rand), nil,
+			requestLen, responseLen, status, http.StatusText(status), nil, nil, []string{}, nil, nil, nil,
+			nil, nil, nil, []string{z.id('F')}, nil, []string{mime}))
+	case svc.proto == "tcp":
+		origPkts = z.rand.IntN(40) + 4
+		respPkts = z.rand.IntN(60) + 4
+		origBytes = origPkts * (z.rand.IntN(600) + 40)
+		respBytes = respPkts * (z.rand.IntN(1400) + 40)
+	default:
+		origBytes, respBytes = 48, 48
+	}
+
+	header := 20 + 20
+	if svc.proto == "udp" {
+		header = 20 + 8
+	}
+	z.pending = z.queue(z.pending, LogConn, append(id[:6:6],
+		svc.proto, service, duration, origBytes, respBytes, state, true, resp.IsPrivate(), 0, history,
+		origPkts, origBytes+origPkts*header, respPkts, respBytes+respPkts*header, []string{}))
+}
+
+// queue appends the record to pending if it is of the configured log.
+func (z *Zeek) queue(pending []record, log string, values []interface{}) []record {
+	if z.log != "" && z.log != log {
+		return pending
+	}
+	return append(pending, record{log: log, values: values})
+}
+
+// id returns a Zeek connection or file id with the prefix.
+func (z *Zeek) id(prefix byte) string {
+	b := make([]byte, 18)
+	b[0] = prefix
+	for i := 1; i < len(b); i++ {
+		b[i] = idChars[z.rand.IntN(len(idChars))]
+	}
+	return string(b)
+}
+
+func dnsAnswer(r *rand.Rand, qtype, query string) string {
+	switch qtype {
+	case "AAAA":
+		return fmt.Sprintf("2001:db8:%x::%x", r.IntN(65536), r.IntN(65536))
+	case "MX":
+		return "mail." + query[strings.Index(query, ".")+1:]
+	case "TXT":
+		return "TXT 36 v=spf1 include:_spf.example.com ~all"
+	case "CNAME":
+		return "edge-" + strconv.Itoa(r.IntN(100)) + ".cdn.example.net"
+	default:
+		return random.IPv4(r).String()
+	}
+}
@@ -0,0 +1,113 @@
+package zeek
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/random"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTime = time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+
+func newZeek(t *testing.T, cfg map[string]interface{}) *Zeek {
+	c, err := ucfg.NewFrom(cfg)
+	assert.Nil(t, err)
+	g, err := New(c)
+	assert.Nil(t, err)
+	z := g.(*Zeek)
+	z.now = func() time.Time { return testTime }
+	return z
+}
+
+func TestNext(t *testing.T) {
+	random.Seed(1)
+	z := newZeek(t, map[string]interface{}{"type": Name, "log": LogDNS})
+	got, err := z.Next()
+	assert.Nil(t, err)
+	lines := strings.Split(string(got), "\n")
+	assert.Equal(t, []string{
+		"#separator \\x09",
+		"#set_separator\t,",
+		"#empty_field\t(empty)",
+		"#unset_field\t-",
+		"#path\tdns",
+		"#open\t2024-03-04-12-00-00",
+		"#fields\tts\tuid\tid.orig_h\tid.orig_p\tid.resp_h\tid.resp_p\tproto\ttrans_id\trtt\tquery\tqclass\tqclass_name\tqtype\tqtype_name\trcode\trcode_name\tAA\tTC\tRD\tRA\tZ\tanswers\tTTLs\trejected",
+		"#types\ttime\tstring\taddr\tport\taddr\tport\tenum\tcount\tinterval\tstring\tcount\tstring\tcount\tstring\tcount\tstring\tbool\tbool\tbool\tbool\tcount\tvector[string]\tvector[interval]\tbool",
+	}, lines[:8])
+	assert.Len(t, lines, 9)
+
+	got, err = z.Next()
+	assert.Nil(t, err)
+	assert.NotContains(t, string(got), "#", "header is only written once")
+	assert.Len(t, strings.Split(string(got), "\t"), len(columns[LogDNS]))
+}
+
+func TestTSV(t *testing.T) {
+	for _, log := range []string{LogConn, LogDNS, LogHTTP} {
+		z := newZeek(t, map[string]interface{}{"type": Name, "log": log})
+		for i := 0; i < 100; i++ {
+			got, err := z.Next()
+			assert.Nil(t, err, log)
+			lines := strings.Split(string(got), "\n")
+			fields := strings.Split(lines[len(lines)-1], "\t")
+			assert.Len(t, fields, len(columns[log]), log)
+			for _, f := range fields {
+				assert.NotEmpty(t, f, log)
+			}
+		}
+	}
+}
+
+func TestUID(t *testing.T) {
+	z := newZeek(t, map[string]interface{}{"type": Name, "log_format": LogFormatJSON})
+	// protocol records are written before the conn record of their
+	// connection
+	protocol := make(map[string]map[string]interface{})
+	paths := make(map[string]int)
+	for i := 0; i < 500 || len(z.pending) > 0; i++ {
+		got, err := z.Next()
+		assert.Nil(t, err)
+		var r map[string]interface{}
+		assert.Nil(t, json.Unmarshal(got, &r), string(got))
+		uid, ok := r["uid"].(string)
+		assert.True(t, ok)
+		assert.Len(t, uid, 18)
+		assert.Equal(t, "C", uid[:1])
+
+		path := r["_path"].(string)
+		paths[path]++
+		if path != LogConn {
+			protocol[uid] = r
+			continue
+		}
+		if p, ok := protocol[uid]; ok {
+			for _, f := range []string{"id.orig_h", "id.orig_p", "id.resp_h", "id.resp_p"} {
+				assert.Equal(t, p[f], r[f], f)
+			}
+			assert.Equal(t, p["_path"], r["service"])
+			delete(protocol, uid)
+		}
+	}
+	assert.Empty(t, protocol, "every protocol record has a conn record")
+	assert.Greater(t, paths[LogConn], paths[LogDNS]+paths[LogHTTP])
+	assert.NotZero(t, paths[LogDNS])
+	assert.NotZero(t, paths[LogHTTP])
+}
+
+func TestJSON(t *testing.T) {
+	z := newZeek(t, map[string]interface{}{"type": Name, "log": LogHTTP, "log_format": LogFormatJSON})
+	got, err := z.Next()
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(got), `{"ts":1709553`), string(got))
+	var r map[string]interface{}
+	assert.Nil(t, json.Unmarshal(got, &r))
+	assert.NotContains(t, r, "_path")
+	assert.NotContains(t, r, "origin", "unset fields are left out")
+	assert.Equal(t, []interface{}{}, r["tags"])
+	assert.Equal(t, float64(1), r["trans_depth"])
+}
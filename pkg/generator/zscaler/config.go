@@ -0,0 +1,56 @@
+package zscaler
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Type       string   `config:"type" validate:"required"`
+	Product    string   `config:"product"`
+	FeedFormat string   `config:"feed_format"`
+	Fields     []string `config:"fields"`
+	Customer   string   `config:"customer"`
+	Domain     string   `config:"domain"`
+	Users      int      `config:"users"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:       Name,
+		FeedFormat: FeedFormatTSV,
+		Customer:   "Example Corp",
+		Domain:     "example.com",
+		Users:      50,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if c.Product != "" && c.Product != ProductZIA && c.Product != ProductZPA {
+		return fmt.Errorf("'%s' is not a valid value for 'product' expected '%s'", c.Product, strings.Join(products[:], ", "))
+	}
+	if c.FeedFormat != FeedFormatTSV && c.FeedFormat != FeedFormatCSV && c.FeedFormat != FeedFormatJSON {
+		return fmt.Errorf("'%s' is not a valid value for 'feed_format' expected '%s'", c.FeedFormat, strings.Join(feedFormats[:], ", "))
+	}
+	if len(c.Fields) > 0 && c.Product == ProductZPA {
+		return fmt.Errorf("'fields' can not be used with 'product' %s", ProductZPA)
+	}
+	for _, name := range c.Fields {
+		if _, ok := fields[name]; !ok {
+			return fmt.Errorf("'%s' is not a valid value for 'fields' expected a field of the ZIA web log", name)
+		}
+	}
+	if c.Customer == "" {
+		return fmt.Errorf("'customer' can not be empty")
+	}
+	if c.Domain == "" {
+		return fmt.Errorf("'domain' can not be empty")
+	}
+	if c.Users < 1 {
+		return fmt.Errorf("'%d' is not a valid value for 'users' expected a value greater than 0", c.Users)
+	}
+	return nil
+}
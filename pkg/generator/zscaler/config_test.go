@@ -0,0 +1,79 @@
+package zscaler
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Type with Fields": {
+			c:           map[string]interface{}{"type": Name, "product": "zia", "feed_format": "csv", "fields": []string{"time", "login", "url", "action"}, "users": 5},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'zscaler' accessing config",
+		},
+		"Invalid Product": {
+			c:           map[string]interface{}{"type": Name, "product": "zdx"},
+			hasError:    true,
+			errorString: "'zdx' is not a valid value for 'product' expected 'zia, zpa' accessing config",
+		},
+		"Invalid Feed Format": {
+			c:           map[string]interface{}{"type": Name, "feed_format": "leef"},
+			hasError:    true,
+			errorString: "'leef' is not a valid value for 'feed_format' expected 'tsv, csv, json' accessing config",
+		},
+		"Invalid Field": {
+			c:           map[string]interface{}{"type": Name, "fields": []string{"time", "srcip"}},
+			hasError:    true,
+			errorString: "'srcip' is not a valid value for 'fields' expected a field of the ZIA web log accessing config",
+		},
+		"Fields with ZPA": {
+			c:           map[string]interface{}{"type": Name, "product": "zpa", "fields": []string{"time"}},
+			hasError:    true,
+			errorString: "'fields' can not be used with 'product' zpa accessing config",
+		},
+		"Empty Domain": {
+			c:           map[string]interface{}{"type": Name, "domain": ""},
+			hasError:    true,
+			errorString: "'domain' can not be empty accessing config",
+		},
+		"Invalid Users": {
+			c:           map[string]interface{}{"type": Name, "users": 0},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'users' expected a value greater than 0 accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
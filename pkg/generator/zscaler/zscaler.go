@@ -0,0 +1,555 @@
+// Package zscaler generates Zscaler Internet Access (ZIA) web proxy
+// logs and Zscaler Private Access (ZPA) user activity logs.
+//
+// The ZIA logs are the lines of an NSS web log feed, the fields of the
+// feed output format in order, tab separated, as CSV or as the JSON
+// object of the NSS JSON output type.  The ZPA logs are the JSON
+// objects a Log Streaming Service (LSS) receiver gets for the user
+// activity log type.  The users are a pool of the users of the
+// customer, each of a department and a location, from the same device
+// and addresses.  Most web requests are allowed, requests to security
+// risks and blocked categories are blocked by the rules of the URL
+// filtering policy.  The private applications are behind the App
+// Connectors of a data center, the finance applications are only for
+// the users of Finance and the admin access only for IT, the other
+// users are rejected by policy.
+//
+// Configuration:
+//
+//	product:     Specify the product of the logs, or leave blank for
+//	             random.  Valid values are: zia, zpa.
+//	feed_format: The NSS feed output type of the ZIA logs, tsv (the
+//	             default), csv or json.
+//	fields:      The fields of the feed output format, in order, for
+//	             example [time, login, action, url, cip].  Defaults to
+//	             all fields.
+//	customer:    The name of the customer, defaults to Example Corp.
+//	domain:      The domain of the users and the private applications,
+//	             defaults to example.com.
+//	users:       The number of users, defaults to 50.
+//
+//	- generator:
+//	    type: zscaler
+//	    product: zia
+//	    feed_format: csv
+//	    fields: [time, login, proto, url, action, urlcat, cip, sip, respcode]
+package zscaler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "zscaler"
+
+// Products of the logs.
+const (
+	ProductZIA = "zia"
+	ProductZPA = "zpa"
+)
+
+// Feed output types of the ZIA logs.
+const (
+	FeedFormatTSV  = "tsv"
+	FeedFormatCSV  = "csv"
+	FeedFormatJSON = "json"
+)
+
+const idChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+var (
+	products    = [...]string{ProductZIA, ProductZPA}
+	feedFormats = [...]string{FeedFormatTSV, FeedFormatCSV, FeedFormatJSON}
+
+	// fields are the fields of the ZIA web log, by their NSS name,
+	// with the template pipeline of their value.
+	fields = map[string]string{
+		"time":            `.Timestamp.Format "Mon Jan 02 15:04:05 2006"`,
+		"epochtime":       `.Timestamp.Unix`,
+		"login":           `.Login`,
+		"dept":            `.Department`,
+		"location":        `.Location`,
+		"proto":           `.Protocol`,
+		"url":             `.URL`,
+		"host":            `.Host`,
+		"reqmethod":       `.Method`,
+		"respcode":        `.StatusCode`,
+		"action":          `.Action`,
+		"reason":          `.Reason`,
+		"appname":         `.AppName`,
+		"appclass":        `.AppClass`,
+		"urlclass":        `.URLClass`,
+		"urlsupercat":     `.URLSuperCategory`,
+		"urlcat":          `.URLCategory`,
+		"malwarecat":      `.MalwareCategory`,
+		"threatname":      `.ThreatName`,
+		"riskscore":       `.RiskScore`,
+		"dlpeng":          `"None"`,
+		"dlpdict":         `"None"`,
+		"reqsize":         `.RequestSize`,
+		"respsize":        `.ResponseSize`,
+		"cip":             `.ClientIP`,
+		"cintip":          `.ClientInternalIP`,
+		"sip":             `.ServerIP`,
+		"ua":              `.UserAgent`,
+		"referer":         `.Referer`,
+		"contenttype":     `.ContentType`,
+		"clienttranstime": `.ClientTime`,
+		"servertranstime": `.ServerTime`,
+		"devicehostname":  `.DeviceHostname`,
+		"deviceowner":     `.DeviceOwner`,
+		"rulelabel":       `.RuleLabel`,
+		"ruletype":        `.RuleType`,
+	}
+	// defaultFields are the fields of the default feed output format.
+	defaultFields = []string{
+		"time", "login", "proto", "url", "host", "action", "reason", "appname", "appclass", "reqmethod", "respcode", "reqsize",
+		"respsize", "urlclass", "urlsupercat", "urlcat", "malwarecat", "threatname", "riskscore", "dlpeng", "dlpdict", "location",
+		"dept", "cip", "cintip", "sip", "ua", "referer", "contenttype", "clienttranstime", "servertranstime", "devicehostname",
+		"deviceowner", "rulelabel", "ruletype", "epochtime",
+	}
+
+	zpaTemplate = `{"LogTimestamp":{{json (.Timestamp.Format "Mon Jan 02 15:04:05 2006")}},"Customer":{{json .Customer}},"SessionID":{{json .SessionID}},"ConnectionID":{{json .ConnectionID}},` +
+		`"InternalReason":{{json .InternalReason}},"ConnectionStatus":{{json .ConnectionStatus}},"IPProtocol":{{.IPProtocol}},"DoubleEncryption":0,"Username":{{json .Login}},` +
+		`"ServicePort":{{.ServerPort}},"ClientPublicIP":{{json .ClientIP}},"ClientPrivateIP":{{json .ClientInternalIP}},"ClientLatitude":{{.Latitude}},"ClientLongitude":{{.Longitude}},` +
+		`"ClientCountryCode":{{json .CountryCode}},"ClientZEN":{{json .ZEN}},"Policy":{{json .Policy}},"Connector":{{json .Connector}},"ConnectorZEN":{{json .ConnectorZEN}},` +
+		`"ConnectorIP":{{json .ConnectorIP}},"ConnectorPort":{{.ConnectorPort}},"Host":{{json .AppHost}},"Application":{{json .Application}},"AppGroup":{{json .AppGroup}},` +
+		`"Server":"0","ServerIP":{{json .AppServerIP}},"ServerPort":{{.ServerPort}},"PolicyProcessingTime":{{.PolicyTime}},"ServerSetupTime":{{.SetupTime}},` +
+		`"TimestampConnectionStart":{{json (.Start.UTC.Format "2006-01-02T15:04:05.000Z")}},"TimestampConnectionEnd":{{json (.Timestamp.UTC.Format "2006-01-02T15:04:05.000Z")}},` +
+		`"ZENTotalBytesRxClient":{{.BytesRx}},"ZENTotalBytesTxClient":{{.BytesTx}},"Idp":{{json .Idp}},"ClientToClient":"0"}`
+
+	// funcs are the functions of the templates that quote the values
+	// of the CSV and JSON outputs.
+	funcs = template.FuncMap{
+		"csv":  csvQuote,
+		"json": jsonQuote,
+	}
+
+	// sites are the sites of the web requests.  The sites with a
+	// threat, or of a category the policy blocks, are blocked by
+	// their rule.
+	sites = [...]site{
+		{"www.google.com", "Google Search", "General Browsing", "Information Technology", "Web Search", "Business Use", "", "", ""},
+		{"outlook.office365.com", "Microsoft Outlook", "Webmail", "Internet Communication", "Web-based Email", "Business Use", "", "", ""},
+		{"teams.microsoft.com", "Microsoft Teams", "Enterprise Collaboration", "Internet Communication", "Online Chat", "Business Use", "", "", ""},
+		{"github.com", "GitHub", "Enterprise Collaboration", "Information Technology", "Computer and Internet Info", "Business Use", "", "", ""},
+		{"login.salesforce.com", "Salesforce", "Sales and Marketing", "Business and Economy", "Corporate Marketing", "Business Use", "", "", ""},
+		{"www.youtube.com", "YouTube", "Streaming Media", "Entertainment/Recreation", "Streaming Media", "Bandwidth Loss", "", "", ""},
+		{"www.linkedin.com", "LinkedIn", "Social Networking", "Social Networking", "Social Networking", "Productivity Loss", "", "", ""},
+		{"www.cnn.com", "General Browsing", "General Browsing", "News and Media", "News and Media", "General Surfing", "", "", ""},
+		{"secure-account-verify.top", "General Browsing", "General Browsing", "Security", "Phishing", "Security Risk", "Phishing", "HTML.Phishing.Gen", "Block_Security_Risk"},
+		{"cdn.update-service.xyz", "General Browsing", "General Browsing", "Security", "Botnet Callback", "Security Risk", "Botnets", "Win32.Backdoor.CobaltStrike", "Block_Security_Risk"},
+		{"www.luckyspin.bet", "General Browsing", "General Browsing", "Gambling", "Gambling", "Legal Liability", "", "", "Block_Gambling"},
+	}
+	locations = [...]struct {
+		name, zen, country  string
+		latitude, longitude float64
+	}{
+		{"HQ-NewYork", "US-NY-8179", "US", 40.7128, -74.006},
+		{"Branch-London", "GB-LON-3", "GB", 51.5072, -0.1276},
+		{"Branch-Frankfurt", "DE-FRA-4", "DE", 50.1109, 8.6821},
+		{"Road Warrior", "US-SJC-7", "US", 37.3382, -121.8863},
+	}
+	departments  = [...]string{"Engineering", "Engineering", "Sales", "Sales", "Marketing", "Finance", "HR", "IT"}
+	paths        = [...]string{"/", "/index.html", "/search", "/api/v1/events", "/login", "/mail/inbox", "/static/app.js", "/images/logo.png"}
+	contentTypes = map[string]string{"/api/v1/events": "application/json", "/static/app.js": "application/javascript", "/images/logo.png": "image/png"}
+	methods      = [...]string{"GET", "GET", "GET", "GET", "GET", "POST", "POST", "PUT"}
+
+	// apps are the private applications of ZPA, of the domain.  The
+	// applications of an app group with departments are only for the
+	// users of the departments.
+	apps = [...]app{
+		{"jira", "Jira", "Engineering Apps", 443, 6, nil},
+		{"git", "GitLab", "Engineering Apps", 22, 6, nil},
+		{"fileserver01", "File Shares", "Corporate Apps", 445, 6, nil},
+		{"intranet", "Intranet", "Corporate Apps", 443, 6, nil},
+		{"erp", "SAP ERP", "Finance Apps", 443, 6, []string{"Finance", "IT"}},
+		{"jump01", "RDP Jump Hosts", "Admin Access", 3389, 6, []string{"IT"}},
+		{"dns01", "Internal DNS", "Infrastructure", 53, 17, nil},
+	}
+	connectors = [...]string{"dc1-connector-01", "dc1-connector-02"}
+)
+
+type site struct {
+	host, app, appClass, superCategory, category, class, malware, threat string
+	// rule is the rule that blocks the requests, empty if they are
+	// allowed.
+	rule string
+}
+
+type app struct {
+	name, application, group string
+	port, protocol           int
+	departments              []string
+}
+
+// user is a user of the customer, with their device and addresses.
+type user struct {
+	login, department, device string
+	location                  int
+	internal, public          net.IP
+	userAgent, sessionID      string
+}
+
+// Zscaler holds the random fields for a ZIA or ZPA log.
+type Zscaler struct {
+	Timestamp        time.Time
+	Start            time.Time
+	Login            string
+	Department       string
+	Location         string
+	DeviceHostname   string
+	DeviceOwner      string
+	ClientIP         net.IP
+	ClientInternalIP net.IP
+	ServerIP         net.IP
+	Host             string
+	RequestSize      int
+	ResponseSize     int
+
+	Protocol         string
+	URL              string
+	Method           string
+	StatusCode       int
+	Action           string
+	Reason           string
+	AppName          string
+	AppClass         string
+	URLClass         string
+	URLSuperCategory string
+	URLCategory      string
+	MalwareCategory  string
+	ThreatName       string
+	RiskScore        int
+	UserAgent        string
+	Referer          string
+	ContentType      string
+	ClientTime       int
+	ServerTime       int
+	RuleLabel        string
+	RuleType         string
+
+	Customer         string
+	SessionID        string
+	ConnectionID     string
+	ConnectionStatus string
+	InternalReason   string
+	IPProtocol       int
+	ServerPort       int
+	CountryCode      string
+	ZEN              string
+	Latitude         float64
+	Longitude        float64
+	Policy           string
+	Connector        string
+	ConnectorZEN     string
+	ConnectorIP      net.IP
+	ConnectorPort    int
+	AppHost          string
+	AppServerIP      net.IP
+	Application      string
+	AppGroup         string
+	BytesRx          int
+	BytesTx          int
+	PolicyTime       int
+	SetupTime        int
+	Idp              string
+
+	product     string
+	domain      string
+	rand        *rand.Rand
+	clock       *generator.Clock
+	pins        *generator.Pins
+	users       []user
+	publicIPs   []net.IP
+	servers     []net.IP
+	connectorIP []net.IP
+	zia         *template.Template
+	zpa         *template.Template
+}
+
+func init() {
+	generator.Register(Name, New)
+}
+
+// New is the Factory for Zscaler objects.
+func New(cfg *ucfg.Config) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	r, err := generator.NewRand(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := generator.NewClock(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	z := &Zscaler{
+		Customer: c.Customer,
+		Idp:      "Okta",
+		product:  c.Product,
+		domain:   c.Domain,
+		rand:     r,
+		clock:    clock,
+	}
+	z.pins, err = generator.NewPins(cfg, r, z)
+	if err != nil {
+		return nil, err
+	}
+
+	// The offices have a public address of their own, the road
+	// warriors are at home.
+	for range locations {
+		z.publicIPs = append(z.publicIPs, random.IPv4(r))
+	}
+	for i := 0; i < c.Users; i++ {
+		u := user{
+			login:      random.Username(r) + "@" + c.Domain,
+			department: departments[r.IntN(len(departments))],
+			device:     random.WorkstationName(r),
+			location:   r.IntN(len(locations)),
+			internal:   net.IPv4(10, byte(10+r.IntN(4)), byte(r.IntN(256)), byte(1+r.IntN(254))),
+			userAgent:  random.UserAgent(r),
+			sessionID:  id(r, 20),
+		}
+		u.public = z.publicIPs[u.location]
+		if locations[u.location].name == "Road Warrior" {
+			u.public = random.IPv4(r)
+		}
+		z.users = append(z.users, u)
+	}
+	for i := range apps {
+		z.servers = append(z.servers, net.IPv4(10, 20, byte(1+i), byte(10+r.IntN(200))))
+	}
+	for i := range connectors {
+		z.connectorIP = append(z.connectorIP, net.IPv4(10, 20, 0, byte(11+i)))
+	}
+
+	names := c.Fields
+	if len(names) == 0 {
+		names = defaultFields
+	}
+	z.zia, err = template.New(ProductZIA).Funcs(generator.FunctionMap).Funcs(funcs).Parse(format(names, c.FeedFormat))
+	if err != nil {
+		return nil, err
+	}
+	z.zpa, err = template.New(ProductZPA).Funcs(generator.FunctionMap).Funcs(funcs).Parse(zpaTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return z, nil
+}
+
+// format returns the template of a ZIA log of the feed output type with
+// the fields names, which must be names of fields.
+func format(names []string, feedFormat string) string {
+	values := make([]string, len(names))
+	for i, name := range names {
+		switch feedFormat {
+		case FeedFormatCSV:
+			values[i] = "{{csv (" + fields[name] + ")}}"
+		case FeedFormatJSON:
+			values[i] = `"` + name + `":{{json (` + fields[name] + `)}}`
+		default:
+			values[i] = "{{" + fields[name] + "}}"
+		}
+	}
+	switch feedFormat {
+	case FeedFormatCSV:
+		return strings.Join(values, ",")
+	case FeedFormatJSON:
+		return `{"sourcetype":"zscalernss-web","event":{` + strings.Join(values, ",") + `}}`
+	default:
+		return strings.Join(values, "\t")
+	}
+}
+
+// Next produces the next ZIA or ZPA log.
+//
+// Example:
+//
+// Mon Jan 02 15:04:05 2006	jdoe@example.com	HTTPS	www.google.com/search	www.google.com	Allowed	Allowed	Google Search	General Browsing	GET	200	812	20331	Business Use	Information Technology	Web Search	None	None	4	None	None	HQ-NewYork	Engineering	198.51.100.7	10.11.4.23	142.250.72.4	Mozilla/5.0 ...	None	text/html	41	27	WS-1234	jdoe@example.com	Default_Allow	URL Filtering	1136214245
+func (z *Zscaler) Next() ([]byte, error) {
+	var buf bytes.Buffer
+
+	product := z.product
+	if product == "" {
+		product = products[z.rand.IntN(len(products))]
+	}
+	z.randomize()
+
+	t := z.zia
+	if product == ProductZPA {
+		t = z.zpa
+	}
+	if err := t.Execute(&buf, z); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// randomize sets the fields of a web request and of a private
+// application connection of a user, the logs of both products are of
+// the same user.
+func (z *Zscaler) randomize() {
+	u := z.users[z.rand.IntN(len(z.users))]
+	loc := locations[u.location]
+	z.Timestamp = z.clock.Now()
+	z.Login = u.login
+	z.Department = u.department
+	z.Location = loc.name
+	z.DeviceHostname = u.device
+	z.DeviceOwner = u.login
+	z.ClientIP = u.public
+	z.ClientInternalIP = u.internal
+	z.UserAgent = u.userAgent
+	z.CountryCode = loc.country
+	z.ZEN = loc.zen
+	z.Latitude = loc.latitude
+	z.Longitude = loc.longitude
+
+	z.randomizeWeb()
+	z.randomizePrivate(u)
+
+	z.pins.Apply(z)
+}
+
+// randomizeWeb sets the fields of the ZIA web request.
+func (z *Zscaler) randomizeWeb() {
+	s := sites[z.rand.IntN(len(sites))]
+	path := paths[z.rand.IntN(len(paths))]
+	z.Host = s.host
+	z.URL = s.host + path
+	z.Protocol = "HTTPS"
+	if z.rand.IntN(10) == 0 {
+		z.Protocol = "HTTP"
+	}
+	z.Method = methods[z.rand.IntN(len(methods))]
+	z.AppName, z.AppClass = s.app, s.appClass
+	z.URLSuperCategory, z.URLCategory, z.URLClass = s.superCategory, s.category, s.class
+	z.MalwareCategory, z.ThreatName = "None", "None"
+	if s.threat != "" {
+		z.MalwareCategory, z.ThreatName = s.malware, s.threat
+	}
+	z.ServerIP = random.IPv4(z.rand)
+	z.ContentType = "text/html"
+	if t, ok := contentTypes[path]; ok {
+		z.ContentType = t
+	}
+	z.Referer = "None"
+	if z.rand.IntN(3) == 0 {
+		z.Referer = "https://www.google.com/"
+	}
+	z.RequestSize = 300 + z.rand.IntN(2000)
+	z.ClientTime = 1 + z.rand.IntN(200)
+
+	if s.rule != "" {
+		z.Action, z.Reason = "Blocked", "Not allowed to browse this category"
+		if s.threat != "" {
+			z.Reason = "Malicious Content"
+		}
+		z.RuleLabel, z.RuleType = s.rule, "URL Filtering"
+		z.StatusCode, z.ResponseSize, z.ServerTime = 403, 600+z.rand.IntN(400), 0
+		z.RiskScore = 0
+		if s.threat != "" {
+			z.RiskScore = 70 + z.rand.IntN(31)
+		}
+		return
+	}
+	z.Action, z.Reason = "Allowed", "Allowed"
+	z.RuleLabel, z.RuleType = "Default_Allow", "URL Filtering"
+	z.StatusCode = random.HTTPStatus(z.rand)
+	z.ResponseSize = 200 + z.rand.IntN(100000)
+	z.ServerTime = 1 + z.rand.IntN(500)
+	z.RiskScore = z.rand.IntN(21)
+}
+
+// randomizePrivate sets the fields of the ZPA connection of u to a
+// private application.
+func (z *Zscaler) randomizePrivate(u user) {
+	i := z.rand.IntN(len(apps))
+	a := apps[i]
+	c := z.rand.IntN(len(connectors))
+	z.Application, z.AppGroup = a.application, a.group
+	z.AppHost = a.name + "." + z.domain
+	z.AppServerIP = z.servers[i]
+	z.ServerPort, z.IPProtocol = a.port, a.protocol
+	z.SessionID = u.sessionID
+	z.ConnectionID = u.sessionID + "," + id(z.rand, 20)
+	z.Connector, z.ConnectorIP = connectors[c], z.connectorIP[c]
+	z.ConnectorZEN = locations[0].zen
+	z.ConnectorPort = 1024 + z.rand.IntN(64512)
+	z.PolicyTime = 50 + z.rand.IntN(400)
+	z.Policy = "Allow " + a.group
+	z.ConnectionStatus, z.InternalReason = "close", ""
+	if z.rand.IntN(5) == 0 {
+		z.ConnectionStatus = "active"
+	}
+
+	duration := time.Duration(1+z.rand.IntN(600)) * time.Second
+	z.BytesRx = 500 + z.rand.IntN(50000)
+	z.BytesTx = 1000 + z.rand.IntN(500000)
+	z.SetupTime = 1000 + z.rand.IntN(20000)
+	if !allowed(a, u.department) {
+		z.Policy = "Deny " + a.group
+		z.ConnectionStatus, z.InternalReason = "close", "BRK_MT_SETUP_FAIL_REJECTED_BY_POLICY"
+		z.BytesRx, z.BytesTx, z.SetupTime = 0, 0, 0
+		duration = 0
+	}
+	z.Start = z.Timestamp.Add(-duration)
+}
+
+// allowed returns whether the users of department may access a.
+func allowed(a app, department string) bool {
+	if a.departments == nil {
+		return true
+	}
+	for _, d := range a.departments {
+		if d == department {
+			return true
+		}
+	}
+	return false
+}
+
+// id returns a random identifier of n letters and digits, like the IDs
+// of the sessions and connections of ZPA.
+func id(r *rand.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = idChars[r.IntN(len(idChars))]
+	}
+	return string(b)
+}
+
+// csvQuote returns the value v as a quoted CSV field.
+func csvQuote(v interface{}) string {
+	return `"` + strings.ReplaceAll(fmt.Sprint(v), `"`, `""`) + `"`
+}
+
+// jsonQuote returns the value v as a JSON string.
+func jsonQuote(v interface{}) (string, error) {
+	b, err := json.Marshal(fmt.Sprint(v))
+	return string(b), err
+}
+
+// Templates returns the templates of the generator, for generator.Lint.
+func (z *Zscaler) Templates() (interface{}, []*template.Template) {
+	return z, []*template.Template{z.zia, z.zpa}
+}
@@ -0,0 +1,115 @@
+package zscaler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func newZscaler(t *testing.T, c map[string]interface{}) generator.Generator {
+	t.Helper()
+	c["type"], c["seed"] = Name, 1
+	cfg, err := ucfg.NewFrom(c)
+	assert.Nil(t, err)
+	g, err := New(cfg)
+	assert.Nil(t, err)
+	return g
+}
+
+func TestNextTSV(t *testing.T) {
+	g := newZscaler(t, map[string]interface{}{"product": ProductZIA})
+	for i := 0; i < 100; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		values := strings.Split(string(got), "\t")
+		if !assert.Len(t, values, len(defaultFields), string(got)) {
+			continue
+		}
+		m := make(map[string]string)
+		for j, name := range defaultFields {
+			m[name] = values[j]
+		}
+		assert.Regexp(t, `^[A-Z][a-z]{2} [A-Z][a-z]{2} \d{2} \d{2}:\d{2}:\d{2} \d{4}$`, m["time"])
+		assert.Regexp(t, `@example\.com$`, m["login"])
+		assert.True(t, strings.HasPrefix(m["url"], m["host"]), string(got))
+		assert.Equal(t, m["login"], m["deviceowner"])
+		switch m["action"] {
+		case "Allowed":
+			assert.Equal(t, "Default_Allow", m["rulelabel"])
+			assert.Equal(t, "None", m["threatname"])
+		case "Blocked":
+			assert.Equal(t, "403", m["respcode"])
+			assert.Regexp(t, `^Block_`, m["rulelabel"])
+			if m["urlclass"] == "Security Risk" {
+				assert.NotEqual(t, "None", m["threatname"], string(got))
+			}
+		default:
+			t.Errorf("unexpected action %q", m["action"])
+		}
+	}
+}
+
+func TestNextCSV(t *testing.T) {
+	g := newZscaler(t, map[string]interface{}{"product": ProductZIA, "feed_format": FeedFormatCSV, "fields": []string{"login", "action", "ua", "respcode"}})
+	for i := 0; i < 20; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		records, err := csv.NewReader(strings.NewReader(string(got))).ReadAll()
+		assert.Nil(t, err, string(got))
+		if assert.Len(t, records, 1) && assert.Len(t, records[0], 4) {
+			assert.Regexp(t, `@example\.com$`, records[0][0])
+			assert.Regexp(t, `^\d{3}$`, records[0][3])
+		}
+	}
+}
+
+func TestNextJSON(t *testing.T) {
+	g := newZscaler(t, map[string]interface{}{"product": ProductZIA, "feed_format": FeedFormatJSON})
+	for i := 0; i < 20; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		var doc struct {
+			SourceType string            `json:"sourcetype"`
+			Event      map[string]string `json:"event"`
+		}
+		if assert.Nil(t, json.Unmarshal(got, &doc), string(got)) {
+			assert.Equal(t, "zscalernss-web", doc.SourceType)
+			assert.Len(t, doc.Event, len(defaultFields))
+		}
+	}
+}
+
+func TestNextZPA(t *testing.T) {
+	g := newZscaler(t, map[string]interface{}{"product": ProductZPA, "domain": "corp.example"})
+	rejected := 0
+	for i := 0; i < 200; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		var doc map[string]interface{}
+		if !assert.Nil(t, json.Unmarshal(got, &doc), string(got)) {
+			continue
+		}
+		assert.Equal(t, "Example Corp", doc["Customer"])
+		assert.Regexp(t, `\.corp\.example$`, doc["Host"])
+		assert.True(t, strings.HasPrefix(doc["ConnectionID"].(string), doc["SessionID"].(string)+","))
+		if doc["InternalReason"] != "" {
+			rejected++
+			assert.Regexp(t, `^Deny (Finance Apps|Admin Access)$`, doc["Policy"])
+			assert.Equal(t, float64(0), doc["ZENTotalBytesTxClient"])
+		}
+	}
+	assert.Greater(t, rejected, 0)
+}
+
+func TestLint(t *testing.T) {
+	for _, c := range []map[string]interface{}{{}, {"product": ProductZIA, "feed_format": FeedFormatJSON}} {
+		issues, err := generator.Lint(newZscaler(t, c), 100)
+		assert.Nil(t, err)
+		assert.Empty(t, issues)
+	}
+}
@@ -3,17 +3,64 @@
 package include
 
 import (
+	_ "github.com/leehinman/spigot/pkg/generator/akamai/datastream"
+	_ "github.com/leehinman/spigot/pkg/generator/aruba/controller"
 	_ "github.com/leehinman/spigot/pkg/generator/aws/firewall"
 	_ "github.com/leehinman/spigot/pkg/generator/aws/vpcflow"
+	_ "github.com/leehinman/spigot/pkg/generator/azure/activitylogs"
+	_ "github.com/leehinman/spigot/pkg/generator/azure/signinlogs"
 	_ "github.com/leehinman/spigot/pkg/generator/cef"
+	_ "github.com/leehinman/spigot/pkg/generator/checkpoint/firewall"
 	_ "github.com/leehinman/spigot/pkg/generator/cisco/asa"
+	_ "github.com/leehinman/spigot/pkg/generator/cisco/ftd"
+	_ "github.com/leehinman/spigot/pkg/generator/cisco/meraki"
 	_ "github.com/leehinman/spigot/pkg/generator/citrix/cef"
 	_ "github.com/leehinman/spigot/pkg/generator/clf"
+	_ "github.com/leehinman/spigot/pkg/generator/csv/spec"
+	_ "github.com/leehinman/spigot/pkg/generator/exec"
+	_ "github.com/leehinman/spigot/pkg/generator/f5/bigip"
 	_ "github.com/leehinman/spigot/pkg/generator/fortinet/firewall"
+	_ "github.com/leehinman/spigot/pkg/generator/heroku/logplex"
+	_ "github.com/leehinman/spigot/pkg/generator/ibmi/audit"
+	_ "github.com/leehinman/spigot/pkg/generator/json/schema"
+	_ "github.com/leehinman/spigot/pkg/generator/kv/spec"
+	_ "github.com/leehinman/spigot/pkg/generator/linux/auditd"
+	_ "github.com/leehinman/spigot/pkg/generator/macos/unifiedlog"
+	_ "github.com/leehinman/spigot/pkg/generator/mikrotik/routeros"
+	_ "github.com/leehinman/spigot/pkg/generator/mssql/audit"
+	_ "github.com/leehinman/spigot/pkg/generator/netapp/ontap"
+	_ "github.com/leehinman/spigot/pkg/generator/okta/systemlog"
+	_ "github.com/leehinman/spigot/pkg/generator/oracle/audit"
+	_ "github.com/leehinman/spigot/pkg/generator/pacs/badge"
+	_ "github.com/leehinman/spigot/pkg/generator/paloalto/panos"
+	_ "github.com/leehinman/spigot/pkg/generator/print/audit"
+	_ "github.com/leehinman/spigot/pkg/generator/saas/webhook"
+	_ "github.com/leehinman/spigot/pkg/generator/syslog/generic"
+	_ "github.com/leehinman/spigot/pkg/generator/system/metrics"
+	_ "github.com/leehinman/spigot/pkg/generator/ubiquiti/unifi"
+	_ "github.com/leehinman/spigot/pkg/generator/wasm"
+	_ "github.com/leehinman/spigot/pkg/generator/webserver/access"
 	_ "github.com/leehinman/spigot/pkg/generator/winlog"
+	_ "github.com/leehinman/spigot/pkg/generator/zeek"
+	_ "github.com/leehinman/spigot/pkg/generator/zscaler"
+	_ "github.com/leehinman/spigot/pkg/output/azureblob"
+	_ "github.com/leehinman/spigot/pkg/output/clickhouse"
+	_ "github.com/leehinman/spigot/pkg/output/elasticsearch"
+	_ "github.com/leehinman/spigot/pkg/output/etw"
 	_ "github.com/leehinman/spigot/pkg/output/file"
+	_ "github.com/leehinman/spigot/pkg/output/gcs"
+	_ "github.com/leehinman/spigot/pkg/output/honeycomb"
+	_ "github.com/leehinman/spigot/pkg/output/http"
+	_ "github.com/leehinman/spigot/pkg/output/influx"
+	_ "github.com/leehinman/spigot/pkg/output/kusto"
+	_ "github.com/leehinman/spigot/pkg/output/openobserve"
+	_ "github.com/leehinman/spigot/pkg/output/parquet"
+	_ "github.com/leehinman/spigot/pkg/output/postgres"
 	_ "github.com/leehinman/spigot/pkg/output/rally"
 	_ "github.com/leehinman/spigot/pkg/output/s3"
+	_ "github.com/leehinman/spigot/pkg/output/sftp"
 	_ "github.com/leehinman/spigot/pkg/output/shipper"
 	_ "github.com/leehinman/spigot/pkg/output/simulate"
+	_ "github.com/leehinman/spigot/pkg/output/socket"
+	_ "github.com/leehinman/spigot/pkg/output/statsd"
 )
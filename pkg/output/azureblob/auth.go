@@ -0,0 +1,67 @@
+package azureblob
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// signSharedKey sets the Authorization header of req to the Shared
+// Key signature of the account with key, of the request headers and
+// the canonicalized resource.
+func signSharedKey(req *http.Request, account string, key []byte) {
+	length := ""
+	if req.ContentLength > 0 {
+		length = strconv.FormatInt(req.ContentLength, 10)
+	}
+	h := req.Header
+	parts := []string{
+		req.Method,
+		h.Get("Content-Encoding"),
+		h.Get("Content-Language"),
+		length,
+		h.Get("Content-MD5"),
+		h.Get("Content-Type"),
+		"", // Date, x-ms-date is used
+		h.Get("If-Modified-Since"),
+		h.Get("If-Match"),
+		h.Get("If-None-Match"),
+		h.Get("If-Unmodified-Since"),
+		h.Get("Range"),
+	}
+
+	var msHeaders []string
+	for name := range h {
+		if n := strings.ToLower(name); strings.HasPrefix(n, "x-ms-") {
+			msHeaders = append(msHeaders, n)
+		}
+	}
+	sort.Strings(msHeaders)
+	var b strings.Builder
+	b.WriteString(strings.Join(parts, "\n"))
+	b.WriteByte('\n')
+	for _, n := range msHeaders {
+		b.WriteString(n + ":" + strings.TrimSpace(h.Get(n)) + "\n")
+	}
+
+	b.WriteString("/" + account + req.URL.EscapedPath())
+	query := req.URL.Query()
+	names := make([]string, 0, len(query))
+	for n := range query {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		values := query[n]
+		sort.Strings(values)
+		b.WriteString("\n" + strings.ToLower(n) + ":" + strings.Join(values, ","))
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(b.String()))
+	req.Header.Set("Authorization", "SharedKey "+account+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+}
@@ -0,0 +1,274 @@
+// Package azureblob implements the output of logs to an Azure Blob
+// Storage container, the equivalent of the s3 output.
+//
+// For configuration, "type", "account", "container" and "prefix" are
+// required, and one of "sas_token", a shared access signature with
+// create and write permissions, or "account_key", the base64 key of
+// the storage account, for Shared Key authorization.
+//
+//	output:
+//	  type: azureblob
+//	  account: "spigotlogs"
+//	  container: "insights-logs"
+//	  prefix: "firewall/y=%{+yyyy}/m=%{+MM}/d=%{+dd}/h=%{+HH}/fw"  ;; firewall/y=2024/m=03/d=02/h=23/fw_0123456789_001.gz
+//	  suffix: ".json"
+//	  sas_token: "sv=2022-11-02&ss=b&srt=o&sp=cw&se=2025-01-01T00:00:00Z&sig=..."
+//	  max_size: 1048576
+//	  max_age: 5m
+//
+// The events, separated by "delimiter", by default "\n", are buffered
+// into a block blob, which is uploaded with each new interval and on
+// close.  "max_size" uploads the blob once the events in it are of at
+// least that many bytes before compression, and "max_age" once its
+// first event is that old.  Both are unlimited by default.
+//
+// "prefix" may contain date formats such as %{+yyyy/MM/dd}, of the time
+// of the first event of the blob in UTC, for date partitioned names.
+// "suffix" is appended to the name, before the ".gz" of "compression"
+// gzip, the default; with "none" the blobs are not compressed.
+//
+// "url" is the blob service endpoint, by default
+// https://<account>.blob.core.windows.net, or for example
+// http://127.0.0.1:10000/devstoreaccount1 for Azurite.  "timeout" is
+// the timeout of each request, by default 30s.
+//
+// "notify" publishes the Event Grid notification of each new blob, a
+// Microsoft.Storage.BlobCreated event in the Event Grid schema, to the
+// custom topic "endpoint" with the access "key", like an event
+// subscription of the storage account.  "topic" is the resource ID of
+// the events, by default that of a storage account of "account".
+//
+//	notify:
+//	  endpoint: "https://blobs.westeurope-1.eventgrid.azure.net/api/events"
+//	  key: "..."
+//
+// "delivery" simulates the write patterns that break pollers listing
+// the container, with the "late", "late_by", "overwrite" and "empty"
+// options of the s3 output.  Late blobs are notified when they are
+// uploaded.
+//
+//	delivery:
+//	  late: 10
+//	  late_by: 15m
+package azureblob
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "azureblob"
+
+// apiVersion is the version of the Blob service REST API.
+const apiVersion = "2021-08-06"
+
+// Output holds the blob being buffered.
+type Output struct {
+	url       string
+	account   string
+	key       []byte
+	sas       string
+	client    *http.Client
+	container string
+	notify    notifyConfig
+	delivery  *output.Delivery
+	delimiter string
+	prefix    output.DatePattern
+	suffix    string
+	compress  bool
+	maxSize   int
+	maxAge    time.Duration
+	name      string
+	created   time.Time
+	size      int
+	buf       *bytes.Buffer
+	w         io.Writer
+	gw        *gzip.Writer
+	now       func() time.Time
+}
+
+func init() {
+	output.Register(Name, New)
+}
+
+// New is the factory for creating a new azureblob Output.
+func New(cfg *ucfg.Config) (output.Output, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	base := c.URL
+	if base == "" {
+		base = "https://" + c.Account + ".blob.core.windows.net"
+	}
+	// Validate checked the prefix and the key.
+	prefix, _ := output.ParseDatePattern("prefix", c.Prefix)
+	key, _ := base64.StdEncoding.DecodeString(c.AccountKey)
+
+	o := &Output{
+		url:       strings.TrimSuffix(base, "/") + "/" + url.PathEscape(c.Container),
+		account:   c.Account,
+		key:       key,
+		sas:       strings.TrimPrefix(c.SASToken, "?"),
+		client:    &http.Client{Timeout: c.Timeout},
+		container: c.Container,
+		notify:    c.Notify,
+		delimiter: c.Delimiter,
+		prefix:    prefix,
+		suffix:    c.Suffix,
+		compress:  c.Compression == CompressionGzip,
+		maxSize:   c.MaxSize,
+		maxAge:    c.MaxAge,
+		buf:       &bytes.Buffer{},
+		now:       time.Now,
+	}
+	if o.notify.Topic == "" {
+		o.notify.Topic = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/spigot/providers/Microsoft.Storage/storageAccounts/" + c.Account
+	}
+	o.delivery = output.NewDelivery(c.Delivery, o.put, o.blobName, func() time.Time { return o.now() })
+	return o, nil
+}
+
+// Write writes the event to the buffered blob, and uploads the blob
+// when it reaches max_size or max_age.
+func (o *Output) Write(b []byte) (int, error) {
+	now := o.now()
+	if o.name != "" && o.maxAge > 0 && now.Sub(o.created) >= o.maxAge {
+		if err := o.flush(); err != nil {
+			return 0, err
+		}
+	}
+	if o.name == "" {
+		o.start(now)
+	}
+	j, err := o.w.Write(b)
+	if err != nil {
+		return j, err
+	}
+	k, err := o.w.Write([]byte(o.delimiter))
+	if err != nil {
+		return j + k, err
+	}
+	o.size += j + k
+	if o.maxSize > 0 && o.size >= o.maxSize {
+		if err := o.flush(); err != nil {
+			return j + k, err
+		}
+	}
+	return j + k, nil
+}
+
+// start starts a new blob at time t.
+func (o *Output) start(t time.Time) {
+	o.name = o.blobName(t)
+	o.created = t
+	o.size = 0
+	o.buf.Reset()
+	o.w = o.buf
+	if o.compress {
+		o.gw = gzip.NewWriter(o.buf)
+		o.w = o.gw
+	}
+}
+
+// blobName returns the name of a blob started at time t.
+func (o *Output) blobName(t time.Time) string {
+	name := fmt.Sprintf("%s_%19d_%03d%s", o.prefix.Format(t), t.UnixNano(), rand.IntN(1000), o.suffix)
+	if o.compress {
+		name += ".gz"
+	}
+	return name
+}
+
+// flush delivers the blob, if there are events in it, and the late
+// blobs that are due.
+func (o *Output) flush() error {
+	if o.name == "" {
+		return o.delivery.Poll()
+	}
+	if o.compress {
+		if err := o.gw.Close(); err != nil {
+			return err
+		}
+	}
+	name := o.name
+	o.name = ""
+	return o.delivery.Deliver(name, o.buf.Bytes())
+}
+
+// put uploads body to the blob name, and publishes its event.
+func (o *Output) put(name string, body []byte) error {
+	u, header, err := o.upload(name, body)
+	if err != nil || o.notify.Endpoint == "" {
+		return err
+	}
+	return o.publish(o.event(u, len(body), header))
+}
+
+// upload creates the block blob name with a Put Blob request, and
+// returns the URL of the blob and the headers of the response.
+func (o *Output) upload(name string, body []byte) (string, http.Header, error) {
+	segments := strings.Split(name, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	u := o.url + "/" + strings.Join(segments, "/")
+	q := u
+	if o.sas != "" {
+		q += "?" + o.sas
+	}
+	req, err := http.NewRequest(http.MethodPut, q, bytes.NewReader(body))
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	if o.compress {
+		req.Header.Set("Content-Type", "application/gzip")
+	}
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-date", o.now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", apiVersion)
+	if len(o.key) > 0 {
+		signSharedKey(req, o.account, o.key)
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusCreated {
+		req.URL.RawQuery = "" // do not print the signature
+		return "", nil, fmt.Errorf("%s %s returned %s: %s", req.Method, req.URL, resp.Status, bytes.TrimSpace(msg))
+	}
+	return u, resp.Header, nil
+}
+
+// Close uploads the buffered events and the late blobs, and closes any
+// idle connections.
+func (o *Output) Close() error {
+	err := o.flush()
+	if err == nil {
+		err = o.delivery.Flush()
+	}
+	o.client.CloseIdleConnections()
+	return err
+}
+
+// NewInterval uploads the buffered events, so each interval is a blob
+// of its own.
+func (o *Output) NewInterval() error {
+	return o.flush()
+}
@@ -0,0 +1,138 @@
+package azureblob
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+type blob struct {
+	path  string
+	query string
+	auth  string
+	body  []byte
+}
+
+func newTestOutput(t *testing.T, c map[string]interface{}) (*Output, *[]blob) {
+	var blobs []blob
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "BlockBlob", r.Header.Get("x-ms-blob-type"))
+		assert.Equal(t, apiVersion, r.Header.Get("x-ms-version"))
+		b, err := io.ReadAll(r.Body)
+		assert.Nil(t, err)
+		blobs = append(blobs, blob{path: r.URL.Path, query: r.URL.RawQuery, auth: r.Header.Get("Authorization"), body: b})
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(srv.Close)
+	c["url"] = srv.URL + "/devstoreaccount1"
+	cfg, err := ucfg.NewFrom(c)
+	assert.Nil(t, err)
+	out, err := New(cfg)
+	assert.Nil(t, err)
+	return out.(*Output), &blobs
+}
+
+func TestRotation(t *testing.T) {
+	o, blobs := newTestOutput(t, map[string]interface{}{"type": Name, "account": "devstoreaccount1", "container": "logs", "prefix": "fw/y=%{+yyyy}/m=%{+MM}/d=%{+dd}/fw", "suffix": ".log", "compression": "none", "max_size": 10, "max_age": "1m", "sas_token": "?sv=2022-11-02&sp=cw&sig=abc"})
+	now := time.Date(2024, 3, 2, 23, 59, 30, 0, time.UTC)
+	o.now = func() time.Time { return now }
+
+	for _, e := range []string{"one", "two"} {
+		_, err := o.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	assert.Len(t, *blobs, 0)
+	now = now.Add(time.Minute)
+	for _, e := range []string{"three", "four"} {
+		_, err := o.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, o.Close())
+	assert.Nil(t, o.NewInterval())
+
+	assert.Len(t, *blobs, 2)
+	assert.Regexp(t, `^/devstoreaccount1/logs/fw/y=2024/m=03/d=02/fw_\d{19}_\d{3}\.log$`, (*blobs)[0].path)
+	assert.Equal(t, "sv=2022-11-02&sp=cw&sig=abc", (*blobs)[0].query)
+	assert.Equal(t, "", (*blobs)[0].auth)
+	assert.Equal(t, "one\ntwo\n", string((*blobs)[0].body))
+	assert.Regexp(t, `^/devstoreaccount1/logs/fw/y=2024/m=03/d=03/fw_\d{19}_\d{3}\.log$`, (*blobs)[1].path)
+	assert.Equal(t, "three\nfour\n", string((*blobs)[1].body))
+}
+
+func TestSharedKey(t *testing.T) {
+	o, blobs := newTestOutput(t, map[string]interface{}{"type": Name, "account": "devstoreaccount1", "container": "logs", "prefix": "test", "account_key": azuriteKey})
+
+	_, err := o.Write([]byte("one"))
+	assert.Nil(t, err)
+	assert.Nil(t, o.Close())
+
+	assert.Len(t, *blobs, 1)
+	assert.Regexp(t, `^/devstoreaccount1/logs/test_\d{19}_\d{3}\.gz$`, (*blobs)[0].path)
+	assert.True(t, strings.HasPrefix((*blobs)[0].auth, "SharedKey devstoreaccount1:"))
+	zr, err := gzip.NewReader(bytes.NewReader((*blobs)[0].body))
+	assert.Nil(t, err)
+	b, err := io.ReadAll(zr)
+	assert.Nil(t, err)
+	assert.Equal(t, "one\n", string(b))
+}
+
+func TestSignSharedKey(t *testing.T) {
+	key, err := base64.StdEncoding.DecodeString(azuriteKey)
+	assert.Nil(t, err)
+	req, err := http.NewRequest(http.MethodPut, "http://127.0.0.1:10000/devstoreaccount1/logs/fw/test.log", strings.NewReader("one\n"))
+	assert.Nil(t, err)
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-date", "Sat, 02 Mar 2024 23:59:30 GMT")
+	req.Header.Set("x-ms-version", apiVersion)
+
+	signSharedKey(req, "devstoreaccount1", key)
+	assert.Equal(t, "SharedKey devstoreaccount1:elMWoGJhghWatlsBvsCzjHNA9e1PL0pCTrBosL8CvRU=", req.Header.Get("Authorization"))
+}
+
+func TestNotify(t *testing.T) {
+	var events []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/api/events" {
+			assert.Equal(t, "secret", r.Header.Get("aeg-sas-key"))
+			var batch []map[string]interface{}
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(&batch))
+			events = append(events, batch...)
+			return
+		}
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.Header().Set("ETag", `"0x8DC3B0F0E0E0E0E"`)
+		w.Header().Set("x-ms-request-id", "a1b2c3")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "account": "devstoreaccount1", "container": "logs", "prefix": "fw/test", "compression": "none", "sas_token": "sig=abc", "url": srv.URL + "/devstoreaccount1", "notify": map[string]interface{}{"endpoint": srv.URL + "/api/events", "key": "secret"}})
+	assert.Nil(t, err)
+	o, err := New(cfg)
+	assert.Nil(t, err)
+	_, err = o.Write([]byte("one"))
+	assert.Nil(t, err)
+	assert.Nil(t, o.Close())
+
+	assert.Len(t, events, 1)
+	assert.Equal(t, "Microsoft.Storage.BlobCreated", events[0]["eventType"])
+	assert.Equal(t, "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/spigot/providers/Microsoft.Storage/storageAccounts/devstoreaccount1", events[0]["topic"])
+	assert.Regexp(t, `^/blobServices/default/containers/logs/blobs/fw/test_\d{19}_\d{3}$`, events[0]["subject"])
+	data := events[0]["data"].(map[string]interface{})
+	assert.Equal(t, "PutBlob", data["api"])
+	assert.Equal(t, "a1b2c3", data["requestId"])
+	assert.Equal(t, `"0x8DC3B0F0E0E0E0E"`, data["eTag"])
+	assert.Equal(t, float64(4), data["contentLength"])
+	assert.Regexp(t, `^`+srv.URL+`/devstoreaccount1/logs/fw/test_\d{19}_\d{3}$`, data["url"])
+}
@@ -0,0 +1,94 @@
+package azureblob
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+type config struct {
+	Type        string                `config:"type" validate:"required"`
+	Account     string                `config:"account" validate:"required"`
+	Container   string                `config:"container" validate:"required"`
+	Prefix      string                `config:"prefix" validate:"required"`
+	Suffix      string                `config:"suffix"`
+	Delimiter   string                `config:"delimiter"`
+	Compression string                `config:"compression"`
+	MaxSize     int                   `config:"max_size"`
+	MaxAge      time.Duration         `config:"max_age"`
+	SASToken    string                `config:"sas_token"`
+	AccountKey  string                `config:"account_key"`
+	URL         string                `config:"url"`
+	Timeout     time.Duration         `config:"timeout"`
+	Notify      notifyConfig          `config:"notify"`
+	Delivery    output.DeliveryConfig `config:"delivery"`
+}
+
+// notifyConfig is the Event Grid topic of the notifications.
+type notifyConfig struct {
+	Endpoint string `config:"endpoint"`
+	Key      string `config:"key"`
+	Topic    string `config:"topic"`
+}
+
+// Compressions of the blobs.
+const (
+	CompressionGzip = "gzip"
+	CompressionNone = "none"
+)
+
+func defaultConfig() config {
+	return config{
+		Type:        Name,
+		Delimiter:   "\n",
+		Compression: CompressionGzip,
+		Timeout:     30 * time.Second,
+		Delivery:    output.DefaultDeliveryConfig(),
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if _, err := output.ParseDatePattern("prefix", c.Prefix); err != nil {
+		return err
+	}
+	if c.Compression != CompressionGzip && c.Compression != CompressionNone {
+		return fmt.Errorf("'%s' is not a valid value for 'compression' expected '%s' or '%s'", c.Compression, CompressionGzip, CompressionNone)
+	}
+	if c.MaxSize < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'max_size' expected 0 or more", c.MaxSize)
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'max_age' expected 0 or more", c.MaxAge)
+	}
+	if (c.SASToken == "") == (c.AccountKey == "") {
+		return fmt.Errorf("one of 'sas_token' or 'account_key' is required")
+	}
+	if c.AccountKey != "" {
+		if _, err := base64.StdEncoding.DecodeString(c.AccountKey); err != nil {
+			return fmt.Errorf("'account_key' is not valid expected a base64 key")
+		}
+	}
+	if c.URL != "" {
+		if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("'%s' is not a valid value for 'url' expected an http or https URL", c.URL)
+		}
+	}
+	if c.Notify.Endpoint != "" {
+		if u, err := url.Parse(c.Notify.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("'%s' is not a valid value for 'notify.endpoint' expected an http or https URL", c.Notify.Endpoint)
+		}
+		if c.Notify.Key == "" {
+			return fmt.Errorf("'notify.key' is required with 'notify.endpoint'")
+		}
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'timeout' expected a positive duration", c.Timeout)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package azureblob
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+// azuriteKey is the well known account key of the Azurite emulator.
+const azuriteKey = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name, "account": "logs", "container": "test", "prefix": "test", "sas_token": "sv=2022-11-02&sig=abc"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Options": {
+			c:           map[string]interface{}{"type": Name, "account": "devstoreaccount1", "container": "test", "prefix": "y=%{+yyyy}/m=%{+MM}/test", "suffix": ".json", "compression": "none", "max_size": 1048576, "max_age": "5m", "account_key": azuriteKey, "url": "http://127.0.0.1:10000/devstoreaccount1", "timeout": "5s"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Notify": {
+			c:           map[string]interface{}{"type": Name, "account": "logs", "container": "test", "prefix": "test", "sas_token": "sig=abc", "notify": map[string]interface{}{"endpoint": "https://blobs.westeurope-1.eventgrid.azure.net/api/events", "key": "secret"}},
+			hasError:    false,
+			errorString: "",
+		},
+		"No Notify Key": {
+			c:           map[string]interface{}{"type": Name, "account": "logs", "container": "test", "prefix": "test", "sas_token": "sig=abc", "notify": map[string]interface{}{"endpoint": "https://blobs.westeurope-1.eventgrid.azure.net/api/events"}},
+			hasError:    true,
+			errorString: "'notify.key' is required with 'notify.endpoint' accessing config",
+		},
+		"Invalid Delivery": {
+			c:           map[string]interface{}{"type": Name, "account": "logs", "container": "test", "prefix": "test", "sas_token": "sig=abc", "delivery": map[string]interface{}{"empty": 200}},
+			hasError:    true,
+			errorString: "'200' is not a valid value for 'empty' expected a percentage from 0 to 100 accessing 'delivery'",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "account": "logs", "container": "test", "prefix": "test", "sas_token": "sig=abc"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'azureblob' accessing config",
+		},
+		"Invalid Prefix": {
+			c:           map[string]interface{}{"type": Name, "account": "logs", "container": "test", "prefix": "y=%{+yyyy", "sas_token": "sig=abc"},
+			hasError:    true,
+			errorString: "'y=%{+yyyy' is not a valid value for 'prefix' expected a '}' after '%{+' accessing config",
+		},
+		"Invalid Compression": {
+			c:           map[string]interface{}{"type": Name, "account": "logs", "container": "test", "prefix": "test", "sas_token": "sig=abc", "compression": "zstd"},
+			hasError:    true,
+			errorString: "'zstd' is not a valid value for 'compression' expected 'gzip' or 'none' accessing config",
+		},
+		"No Credentials": {
+			c:           map[string]interface{}{"type": Name, "account": "logs", "container": "test", "prefix": "test"},
+			hasError:    true,
+			errorString: "one of 'sas_token' or 'account_key' is required accessing config",
+		},
+		"Both Credentials": {
+			c:           map[string]interface{}{"type": Name, "account": "logs", "container": "test", "prefix": "test", "sas_token": "sig=abc", "account_key": azuriteKey},
+			hasError:    true,
+			errorString: "one of 'sas_token' or 'account_key' is required accessing config",
+		},
+		"Invalid Account Key": {
+			c:           map[string]interface{}{"type": Name, "account": "logs", "container": "test", "prefix": "test", "account_key": "not base64!"},
+			hasError:    true,
+			errorString: "'account_key' is not valid expected a base64 key accessing config",
+		},
+		"Invalid URL": {
+			c:           map[string]interface{}{"type": Name, "account": "logs", "container": "test", "prefix": "test", "sas_token": "sig=abc", "url": "127.0.0.1:10000"},
+			hasError:    true,
+			errorString: "'127.0.0.1:10000' is not a valid value for 'url' expected an http or https URL accessing config",
+		},
+		"No Container": {
+			c:           map[string]interface{}{"type": Name, "account": "logs", "prefix": "test", "sas_token": "sig=abc"},
+			hasError:    true,
+			errorString: "string value is not set accessing 'container'",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
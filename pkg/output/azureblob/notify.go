@@ -0,0 +1,76 @@
+package azureblob
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// event returns the BlobCreated event of the blob at u of size bytes,
+// with the headers of the Put Blob response.
+func (o *Output) event(u string, size int, header http.Header) map[string]interface{} {
+	name := strings.TrimPrefix(u, o.url+"/")
+	requestID := header.Get("x-ms-request-id")
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	etag := header.Get("ETag")
+	if etag == "" {
+		etag = fmt.Sprintf("0x%X", o.now().UnixNano())
+	}
+	contentType := "text/plain"
+	if o.compress {
+		contentType = "application/gzip"
+	}
+	return map[string]interface{}{
+		"topic":     o.notify.Topic,
+		"subject":   "/blobServices/default/containers/" + o.container + "/blobs/" + name,
+		"eventType": "Microsoft.Storage.BlobCreated",
+		"id":        uuid.NewString(),
+		"eventTime": o.now().UTC().Format(time.RFC3339Nano),
+		"data": map[string]interface{}{
+			"api":                "PutBlob",
+			"clientRequestId":    uuid.NewString(),
+			"requestId":          requestID,
+			"eTag":               etag,
+			"contentType":        contentType,
+			"contentLength":      size,
+			"blobType":           "BlockBlob",
+			"url":                u,
+			"sequencer":          fmt.Sprintf("%032X", o.now().UnixNano()),
+			"storageDiagnostics": map[string]string{"batchId": uuid.NewString()},
+		},
+		"dataVersion":     "",
+		"metadataVersion": "1",
+	}
+}
+
+// publish posts the event to the Event Grid topic.
+func (o *Output) publish(event map[string]interface{}) error {
+	b, err := json.Marshal([]interface{}{event})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, o.notify.Endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("aeg-sas-key", o.notify.Key)
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s returned %s: %s", req.Method, req.URL, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package output
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// LocalAddr returns the local address for the "local_address" option
+// of a network output, an IP address with an optional port, for
+// example "10.1.2.3" or "10.1.2.3:5514".  network is the network of
+// the output, "tcp" or "udp".  An empty address returns nil, so the
+// operating system picks the local address.
+func LocalAddr(network, address string) (net.Addr, error) {
+	if address == "" {
+		return nil, nil
+	}
+	hostport := address
+	if ip := strings.Trim(address, "[]"); net.ParseIP(ip) != nil {
+		hostport = net.JoinHostPort(ip, "0")
+	}
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil || net.ParseIP(host) == nil {
+		return nil, fmt.Errorf("'%s' is not a valid value for 'local_address' expected an IP address with an optional port", address)
+	}
+
+	switch {
+	case strings.HasPrefix(network, "udp"):
+		return net.ResolveUDPAddr(network, hostport)
+	default:
+		return net.ResolveTCPAddr("tcp", hostport)
+	}
+}
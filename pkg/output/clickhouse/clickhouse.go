@@ -0,0 +1,344 @@
+// Package clickhouse implements the output of structured events to a
+// ClickHouse table, with batched inserts over the native protocol.
+//
+// Events must be JSON objects, such as those of generators with a
+// json format.  The columns of the table are the fields of the events
+// with their names, top level keys or dotted paths of nested objects,
+// e.g. "source.ip".  Fields that are not set are NULL in Nullable
+// columns and the default of the type in the others, 0, an empty
+// string or the least value of an Enum, not the DEFAULT of the column.
+//
+// Supported column types are String, FixedString, the Int and UInt
+// types, Float32, Float64, Bool, Enum8, Enum16, Date, Date32,
+// DateTime, DateTime64, UUID, IPv4 and IPv6, and Nullable, Array and
+// LowCardinality of them.  Times are RFC 3339 times or numbers of
+// seconds since the epoch.  Objects and lists are JSON in String
+// columns.
+//
+// The events are inserted when batch_size events are buffered, with
+// each new interval and on close.
+//
+//	output:
+//	  type: clickhouse
+//	  address: "localhost:9000"
+//	  database: default
+//	  username: default
+//	  password: secret
+//	  table: logs
+//	  batch_size: 10000
+//
+// table is required, with an optional database, and is quoted, so it
+// is case sensitive.  address is localhost:9000, database and username
+// are default by default.  The events are not compressed.
+package clickhouse
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// Name is the name of the output in the configuration file and registry.
+const Name = "clickhouse"
+
+// dialTimeout is the timeout of connecting to the server.
+const dialTimeout = 30 * time.Second
+
+// identEscaper escapes the backslashes and backticks of a quoted
+// identifier.
+var identEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+
+// Output buffers the events until they are inserted.
+type Output struct {
+	c      config
+	conn   net.Conn
+	r      *reader
+	events []map[string]interface{}
+}
+
+func init() {
+	output.Register(Name, New)
+}
+
+// New is the Factory for creating a new clickhouse output.  It
+// connects to the server.
+func New(cfg *ucfg.Config) (output.Output, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	o := &Output{c: c}
+	if err := o.connect(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Write buffers the event, and inserts the buffered events when there
+// are batch_size of them.
+func (o *Output) Write(b []byte) (int, error) {
+	event, err := output.Event(Name, b)
+	if err != nil {
+		return 0, err
+	}
+	o.events = append(o.events, event)
+	if len(o.events) >= o.c.BatchSize {
+		if err := o.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// connect connects to the server and exchanges the hello packets.
+func (o *Output) connect() error {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", o.c.Address)
+	if err != nil {
+		return err
+	}
+
+	var b buffer
+	b.uvarint(clientHello)
+	b.str("spigot")
+	b.uvarint(1)
+	b.uvarint(0)
+	b.uvarint(revision)
+	b.str(o.c.Database)
+	b.str(o.c.Username)
+	b.str(o.c.Password)
+	if _, err := conn.Write(b.Bytes()); err != nil {
+		conn.Close()
+		return err
+	}
+
+	r := &reader{r: bufio.NewReader(conn)}
+	switch packet := r.uvarint(); {
+	case r.err != nil:
+		err = r.err
+	case packet == serverException:
+		err = r.exception()
+	case packet != serverHello:
+		err = fmt.Errorf("unexpected clickhouse packet %d, expected hello", packet)
+	default:
+		r.str() // name
+		r.uvarint()
+		r.uvarint()
+		if r.uvarint() < revision {
+			err = fmt.Errorf("clickhouse server is older than protocol revision %d", revision)
+			break
+		}
+		r.str() // timezone
+		r.str() // display name
+		r.uvarint()
+		err = r.err
+	}
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	o.conn, o.r = conn, r
+	return nil
+}
+
+// flush inserts the buffered events.  The connection is closed after
+// an error, the next insert connects again.
+func (o *Output) flush() error {
+	events := o.events
+	o.events = nil
+	if len(events) == 0 {
+		return nil
+	}
+	if o.conn == nil {
+		if err := o.connect(); err != nil {
+			return err
+		}
+	}
+	if err := o.insert(events); err != nil {
+		o.conn.Close()
+		o.conn = nil
+		return err
+	}
+	return nil
+}
+
+// insert sends the INSERT query, reads the columns of the table from
+// the block the server answers with and sends the events as a block of
+// these columns.
+func (o *Output) insert(events []map[string]interface{}) error {
+	var b buffer
+	b.uvarint(clientQuery)
+	b.str("")
+	o.clientInfo(&b)
+	// settings, as strings with flags
+	b.str("low_cardinality_allow_in_native_format")
+	b.uvarint(0)
+	b.str("0")
+	b.str("")
+	b.uvarint(stageComplete)
+	b.uvarint(0) // no compression
+	b.str("INSERT INTO " + quoteTable(o.c.Table) + " VALUES")
+	// no external tables
+	emptyBlock(&b)
+	if _, err := o.conn.Write(b.Bytes()); err != nil {
+		return err
+	}
+
+	var columns []column
+	for columns == nil {
+		packet, err := o.packet()
+		if err != nil {
+			return err
+		}
+		switch packet {
+		case serverData:
+			if columns, err = o.r.header(); err != nil {
+				return err
+			}
+		case serverTableColumns:
+			o.r.str()
+			o.r.str()
+		default:
+			return fmt.Errorf("unexpected clickhouse packet %d, expected data", packet)
+		}
+	}
+
+	b.Reset()
+	b.uvarint(clientData)
+	b.str("")
+	b.blockInfo()
+	b.uvarint(uint64(len(columns)))
+	b.uvarint(uint64(len(events)))
+	values := make([]interface{}, len(events))
+	for _, c := range columns {
+		enc, err := parseType(c.typ)
+		if err != nil {
+			return err
+		}
+		for i, e := range events {
+			values[i], _ = output.Field(e, c.name)
+		}
+		b.str(c.name)
+		b.str(c.typ)
+		if err := enc.encode(&b, values); err != nil {
+			return fmt.Errorf("clickhouse column '%s': %w", c.name, err)
+		}
+	}
+	emptyBlock(&b)
+	if _, err := o.conn.Write(b.Bytes()); err != nil {
+		return err
+	}
+
+	for {
+		packet, err := o.packet()
+		if err != nil {
+			return err
+		}
+		switch packet {
+		case serverEndOfStream:
+			return nil
+		case serverTableColumns:
+			o.r.str()
+			o.r.str()
+		default:
+			return fmt.Errorf("unexpected clickhouse packet %d, expected end of stream", packet)
+		}
+	}
+}
+
+// packet reads the type of the next packet of the server, skipping
+// progress and profile packets.  Exceptions are returned as errors.
+func (o *Output) packet() (uint64, error) {
+	r := o.r
+	for {
+		packet := r.uvarint()
+		switch packet {
+		case serverException:
+			return 0, r.exception()
+		case serverProgress:
+			r.uvarint() // rows
+			r.uvarint() // bytes
+			r.uvarint() // total rows
+			r.uvarint() // written rows
+			r.uvarint() // written bytes
+		case serverProfileInfo:
+			r.uvarint() // rows
+			r.uvarint() // blocks
+			r.uvarint() // bytes
+			r.u8()      // applied limit
+			r.uvarint() // rows before limit
+			r.u8()      // calculated rows before limit
+		default:
+			return packet, r.err
+		}
+		if r.err != nil {
+			return 0, r.err
+		}
+	}
+}
+
+// clientInfo writes the ClientInfo of the query.
+func (o *Output) clientInfo(b *buffer) {
+	b.u8(queryInitial)
+	b.str("") // initial user
+	b.str("") // initial query id
+	b.str("0.0.0.0:0")
+	b.u8(interfaceTCP)
+	b.str("") // os user
+	b.str("") // client hostname
+	b.str("spigot")
+	b.uvarint(1)
+	b.uvarint(0)
+	b.uvarint(revision)
+	b.str("")    // quota key
+	b.uvarint(0) // version patch
+}
+
+// quote returns the name as a quoted identifier.
+func quote(name string) string {
+	return "`" + identEscaper.Replace(name) + "`"
+}
+
+// quoteTable returns the table, with an optional database, as quoted
+// identifiers.
+func quoteTable(table string) string {
+	parts := strings.SplitN(table, ".", 2)
+	for i, p := range parts {
+		parts[i] = quote(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+// emptyBlock writes a data packet of an empty block, the end of the
+// data.
+func emptyBlock(b *buffer) {
+	b.uvarint(clientData)
+	b.str("")
+	b.blockInfo()
+	b.uvarint(0)
+	b.uvarint(0)
+}
+
+// Close inserts the buffered events and closes the connection.
+func (o *Output) Close() error {
+	err := o.flush()
+	if o.conn != nil {
+		o.conn.Close()
+		o.conn = nil
+	}
+	return err
+}
+
+// NewInterval inserts the buffered events, so each interval is a batch
+// of its own.
+func (o *Output) NewInterval() error {
+	return o.flush()
+}
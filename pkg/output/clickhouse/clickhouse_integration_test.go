@@ -0,0 +1,111 @@
+//go:build integration
+
+package clickhouse
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+// The integration tests run against a ClickHouse server, with its
+// native protocol at CLICKHOUSE_ADDRESS and its HTTP interface at
+// CLICKHOUSE_URL, by default those of a local server:
+//
+//	docker run -d -p 9000:9000 -p 8123:8123 -e CLICKHOUSE_PASSWORD=secret clickhouse/clickhouse-server
+//	CLICKHOUSE_PASSWORD=secret go test -tags integration ./pkg/output/clickhouse
+
+func getenv(key, value string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return value
+}
+
+// query runs q with the HTTP interface of the server and returns its
+// result.
+func query(t *testing.T, q string) string {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, getenv("CLICKHOUSE_URL", "http://localhost:8123"), strings.NewReader(q))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-ClickHouse-User", getenv("CLICKHOUSE_USER", "default"))
+	req.Header.Set("X-ClickHouse-Key", os.Getenv("CLICKHOUSE_PASSWORD"))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("clickhouse query %q: %s: %s", q, resp.Status, body)
+	}
+	return string(body)
+}
+
+func TestServer(t *testing.T) {
+	// The name of the table has to be quoted.
+	table := fmt.Sprintf("default.spigot-%d", time.Now().UnixNano())
+	quoted := quoteTable(table)
+	query(t, "CREATE TABLE "+quoted+" ("+
+		"message String, "+
+		"user Nullable(String), "+
+		"`source.ip` IPv4, "+
+		"`source.port` UInt16, "+
+		"bytes Int64, "+
+		"ratio Float64, "+
+		"ok Bool, "+
+		"`@timestamp` DateTime64(3, 'UTC'), "+
+		"created DateTime('UTC'), "+
+		"tags Array(String), "+
+		"host LowCardinality(String), "+
+		"level Enum8('info' = 1, 'error' = 2), "+
+		"id UUID, "+
+		"client Nullable(IPv6)"+
+		") ENGINE = Memory")
+	t.Cleanup(func() { query(t, "DROP TABLE IF EXISTS "+quoted) })
+
+	c, err := ucfg.NewFrom(map[string]interface{}{
+		"type":       Name,
+		"address":    getenv("CLICKHOUSE_ADDRESS", "localhost:9000"),
+		"username":   getenv("CLICKHOUSE_USER", "default"),
+		"password":   os.Getenv("CLICKHOUSE_PASSWORD"),
+		"table":      table,
+		"batch_size": 2,
+	})
+	assert.Nil(t, err)
+	out, err := New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []string{
+		`{"@timestamp":"2024-03-04T12:00:00.123Z","message":"accepted","user":"alice","source":{"ip":"10.0.0.1","port":443},"bytes":-5,"ratio":0.5,"ok":true,"created":1709553600,"tags":["a","b"],"host":"web-1","level":"info","id":"61f0c404-5cb3-11e7-907b-a6006ad3dba0","client":"2001:db8::1"}`,
+		`{"@timestamp":"2024-03-04T12:00:01Z","message":"denied","source.ip":"10.0.0.2","ok":false,"level":2}`,
+		`{"message":"three","tags":"c"}`,
+	} {
+		_, err := out.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, out.Close())
+
+	got := query(t, "SELECT message, ifNull(user, 'NULL'), toString(`source.ip`), `source.port`, bytes, ratio, toUInt8(ok), "+
+		"toString(`@timestamp`), toUnixTimestamp(created), arrayStringConcat(tags, ','), host, toString(level), toString(id), "+
+		"ifNull(toString(client), 'NULL') FROM "+quoted+" ORDER BY message FORMAT TabSeparated")
+	assert.Equal(t, strings.Join([]string{
+		"accepted\talice\t10.0.0.1\t443\t-5\t0.5\t1\t2024-03-04 12:00:00.123\t1709553600\ta,b\tweb-1\tinfo\t61f0c404-5cb3-11e7-907b-a6006ad3dba0\t2001:db8::1",
+		"denied\tNULL\t10.0.0.2\t0\t0\t0\t0\t2024-03-04 12:00:01.000\t0\t\t\terror\t00000000-0000-0000-0000-000000000000\tNULL",
+		"three\tNULL\t0.0.0.0\t0\t0\t0\t0\t1970-01-01 00:00:00.000\t0\tc\t\tinfo\t00000000-0000-0000-0000-000000000000\tNULL",
+		"",
+	}, "\n"), got)
+}
@@ -0,0 +1,341 @@
+package clickhouse
+
+import (
+	"bufio"
+	"encoding/binary"
+	"math"
+	"net"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+var tableColumns = []column{
+	{"message", "String"},
+	{"user", "Nullable(String)"},
+	{"source.ip", "IPv4"},
+	{"source.port", "UInt16"},
+	{"bytes", "Int64"},
+	{"ratio", "Float64"},
+	{"ok", "Bool"},
+	{"@timestamp", "DateTime64(3, 'UTC')"},
+	{"created", "DateTime"},
+	{"tags", "Array(String)"},
+	{"host", "LowCardinality(String)"},
+	{"level", "Enum8('info' = 1, 'error' = 2)"},
+}
+
+// insert is an INSERT the fake server received.
+type insert struct {
+	query    string
+	settings map[string]string
+	columns  map[string][]interface{}
+}
+
+// server is a fake ClickHouse server, for the inserts of one
+// connection into the table of tableColumns.
+func server(t *testing.T, inserts chan<- insert) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := &reader{r: bufio.NewReader(conn)}
+
+		r.uvarint() // hello
+		r.str()
+		r.uvarint()
+		r.uvarint()
+		r.uvarint()
+		r.str()
+		user, password := r.str(), r.str()
+		var b buffer
+		if user != "default" || password != "secret" {
+			exception(&b, 516, "DB::Exception: default: Authentication failed")
+			_, _ = conn.Write(b.Bytes())
+			return
+		}
+		b.uvarint(serverHello)
+		b.str("ClickHouse")
+		b.uvarint(23)
+		b.uvarint(8)
+		b.uvarint(54460)
+		b.str("UTC")
+		b.str("fake")
+		b.uvarint(1)
+		_, _ = conn.Write(b.Bytes())
+
+		for {
+			in := insert{settings: map[string]string{}, columns: map[string][]interface{}{}}
+			if r.uvarint() != clientQuery || r.err != nil {
+				return
+			}
+			r.str()     // query id
+			r.u8()      // query kind
+			r.str()     // initial user
+			r.str()     // initial query id
+			r.str()     // initial address
+			r.u8()      // interface
+			r.str()     // os user
+			r.str()     // hostname
+			r.str()     // client name
+			r.uvarint() // major
+			r.uvarint() // minor
+			r.uvarint() // revision
+			r.str()     // quota key
+			r.uvarint() // patch
+			for name := r.str(); name != ""; name = r.str() {
+				r.uvarint()
+				in.settings[name] = r.str()
+			}
+			r.uvarint() // stage
+			r.uvarint() // compression
+			in.query = r.str()
+			block(r)
+
+			b.Reset()
+			if in.query != "INSERT INTO `logs` VALUES" {
+				exception(&b, 60, "DB::Exception: Table default.missing does not exist")
+				_, _ = conn.Write(b.Bytes())
+				continue
+			}
+			b.uvarint(serverTableColumns)
+			b.str("")
+			b.str("columns format version: 1\n")
+			b.uvarint(serverData)
+			b.str("")
+			b.blockInfo()
+			b.uvarint(uint64(len(tableColumns)))
+			b.uvarint(0)
+			for _, c := range tableColumns {
+				b.str(c.name)
+				b.str(c.typ)
+			}
+			_, _ = conn.Write(b.Bytes())
+
+			cols, rows := block(r)
+			for i := 0; i < cols; i++ {
+				name, typ := r.str(), r.str()
+				in.columns[name] = values(r, typ, rows)
+			}
+			block(r)
+
+			b.Reset()
+			b.uvarint(serverProgress)
+			for i := 0; i < 5; i++ {
+				b.uvarint(uint64(rows))
+			}
+			b.uvarint(serverEndOfStream)
+			_, _ = conn.Write(b.Bytes())
+			inserts <- in
+		}
+	}()
+	return l.Addr().String()
+}
+
+func exception(b *buffer, code int32, message string) {
+	b.uvarint(serverException)
+	b.u32(uint32(code))
+	b.str("DB::Exception")
+	b.str(message)
+	b.str("")
+	b.u8(0)
+}
+
+// block reads the header of a data packet of the client and returns
+// its number of columns and rows.
+func block(r *reader) (int, int) {
+	r.uvarint() // data
+	r.str()
+	for f := r.uvarint(); f != 0 && r.err == nil; f = r.uvarint() {
+		if f == 1 {
+			r.u8()
+		} else {
+			r.u32()
+		}
+	}
+	return int(r.uvarint()), int(r.uvarint())
+}
+
+func u64(r *reader) uint64 {
+	var b [8]byte
+	r.read(b[:])
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// values decodes the values of a column of the types of tableColumns.
+func values(r *reader, typ string, rows int) []interface{} {
+	v := make([]interface{}, rows)
+	switch typ {
+	case "String", "LowCardinality(String)":
+		for i := range v {
+			v[i] = r.str()
+		}
+	case "Nullable(String)":
+		nulls := make([]byte, rows)
+		r.read(nulls)
+		for i := range v {
+			if s := r.str(); nulls[i] == 0 {
+				v[i] = s
+			}
+		}
+	case "IPv4":
+		for i := range v {
+			ip := make(net.IP, 4)
+			binary.BigEndian.PutUint32(ip, r.u32())
+			v[i] = ip.String()
+		}
+	case "UInt16":
+		for i := range v {
+			var b [2]byte
+			r.read(b[:])
+			v[i] = binary.LittleEndian.Uint16(b[:])
+		}
+	case "Int64", "DateTime64(3, 'UTC')":
+		for i := range v {
+			v[i] = int64(u64(r))
+		}
+	case "Float64":
+		for i := range v {
+			v[i] = math.Float64frombits(u64(r))
+		}
+	case "Bool", "Enum8('info' = 1, 'error' = 2)":
+		for i := range v {
+			v[i] = r.u8()
+		}
+	case "DateTime":
+		for i := range v {
+			v[i] = r.u32()
+		}
+	case "Array(String)":
+		offsets := make([]uint64, rows)
+		for i := range offsets {
+			offsets[i] = u64(r)
+		}
+		var start uint64
+		for i := range v {
+			a := []string{}
+			for ; start < offsets[i]; start++ {
+				a = append(a, r.str())
+			}
+			v[i] = a
+		}
+	}
+	return v
+}
+
+func TestWrite(t *testing.T) {
+	inserts := make(chan insert, 2)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "address": server(t, inserts), "table": "logs", "password": "secret", "batch_size": 2})
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+
+	for _, e := range []string{
+		`{"@timestamp":"2024-03-04T12:00:00.123Z","message":"accepted","user":"alice","source":{"ip":"10.0.0.1","port":443},"bytes":-5,"ratio":0.5,"ok":true,"created":1709553600,"tags":["a","b"],"host":"web-1","level":"info"}`,
+		`{"@timestamp":"2024-03-04T12:00:01Z","message":"denied","source.ip":"10.0.0.2","ok":false,"level":2}`,
+		`{"message":"three","tags":"c"}`,
+	} {
+		_, err := out.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	in := <-inserts
+	assert.Equal(t, "INSERT INTO `logs` VALUES", in.query)
+	assert.Equal(t, map[string]string{"low_cardinality_allow_in_native_format": "0"}, in.settings)
+	assert.Equal(t, map[string][]interface{}{
+		"message":     {"accepted", "denied"},
+		"user":        {"alice", nil},
+		"source.ip":   {"10.0.0.1", "10.0.0.2"},
+		"source.port": {uint16(443), uint16(0)},
+		"bytes":       {int64(-5), int64(0)},
+		"ratio":       {0.5, 0.0},
+		"ok":          {uint8(1), uint8(0)},
+		"@timestamp":  {int64(1709553600123), int64(1709553601000)},
+		"created":     {uint32(1709553600), uint32(0)},
+		"tags":        {[]string{"a", "b"}, []string{}},
+		"host":        {"web-1", ""},
+		"level":       {uint8(1), uint8(2)},
+	}, in.columns)
+
+	assert.Nil(t, out.Close())
+	in = <-inserts
+	assert.Equal(t, []interface{}{"three"}, in.columns["message"])
+	assert.Equal(t, []interface{}{[]string{"c"}}, in.columns["tags"])
+
+	_, err = out.Write([]byte("not json"))
+	assert.EqualError(t, err, "clickhouse output expected a JSON object, use a json format of the generator")
+}
+
+func TestException(t *testing.T) {
+	inserts := make(chan insert, 1)
+	addr := server(t, inserts)
+
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "address": addr, "table": "logs"})
+	assert.Nil(t, err)
+	_, err = New(c)
+	assert.EqualError(t, err, "clickhouse exception: DB::Exception (516): DB::Exception: default: Authentication failed")
+
+	addr = server(t, inserts)
+	c, err = ucfg.NewFrom(map[string]interface{}{"type": Name, "address": addr, "table": "missing", "password": "secret"})
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+	_, err = out.Write([]byte(`{"message":"hello"}`))
+	assert.Nil(t, err)
+	assert.EqualError(t, out.NewInterval(), "clickhouse exception: DB::Exception (60): DB::Exception: Table default.missing does not exist")
+}
+
+func TestParseType(t *testing.T) {
+	for _, typ := range []string{"String", "FixedString(16)", "UInt8", "Int32", "Float32", "Date", "Date32", "DateTime('Europe/Amsterdam')", "DateTime64(6)", "UUID", "IPv6", "Nullable(Enum16('a' = 1000))", "Array(LowCardinality(Nullable(String)))"} {
+		_, err := parseType(typ)
+		assert.Nil(t, err, typ)
+	}
+	_, err := parseType("Map(String, UInt64)")
+	assert.EqualError(t, err, "unsupported clickhouse type 'Map(String, UInt64)'")
+}
+
+func TestEncode(t *testing.T) {
+	tests := map[string]struct {
+		typ    string
+		values []interface{}
+		want   []byte
+	}{
+		"FixedString": {typ: "FixedString(3)", values: []interface{}{"ab", "abcd"}, want: []byte("ab\x00abc")},
+		"UUID": {
+			typ:    "UUID",
+			values: []interface{}{"61f0c404-5cb3-11e7-907b-a6006ad3dba0"},
+			want:   []byte{0xe7, 0x11, 0xb3, 0x5c, 0x04, 0xc4, 0xf0, 0x61, 0xa0, 0xdb, 0xd3, 0x6a, 0x00, 0xa6, 0x7b, 0x90},
+		},
+		"Date":          {typ: "Date", values: []interface{}{"1970-01-03T10:00:00Z"}, want: []byte{2, 0}},
+		"Nullable Int8": {typ: "Nullable(Int8)", values: []interface{}{nil, "-1"}, want: []byte{1, 0, 0, 0xff}},
+		"Enum8 Default": {typ: "Enum8('b' = 2, 'a' = -1)", values: []interface{}{nil, "b"}, want: []byte{0xff, 2}},
+	}
+	for name, tc := range tests {
+		enc, err := parseType(tc.typ)
+		assert.Nil(t, err, name)
+		var b buffer
+		assert.Nil(t, enc.encode(&b, tc.values), name)
+		assert.Equal(t, tc.want, b.Bytes(), name)
+	}
+
+	enc, _ := parseType("IPv4")
+	assert.EqualError(t, enc.encode(&buffer{}, []interface{}{"localhost"}), "'localhost' is not a valid IP address")
+}
+
+func TestQuoteTable(t *testing.T) {
+	tests := map[string]string{
+		"logs":               "`logs`",
+		"spigot.logs":        "`spigot`.`logs`",
+		"spigot.web.access":  "`spigot`.`web.access`",
+		"logs; DROP TABLE x": "`logs; DROP TABLE x`",
+		"a`b\\c":             "`a\\`b\\\\c`",
+	}
+	for table, want := range tests {
+		assert.Equal(t, want, quoteTable(table), table)
+	}
+}
@@ -0,0 +1,489 @@
+package clickhouse
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// column is a column of the table.
+type column struct {
+	name string
+	typ  string
+}
+
+// encoding writes the values of the events in a column of a block.
+// Values that are not set are nil, and are the default of the type.
+type encoding interface {
+	encode(b *buffer, values []interface{}) error
+}
+
+var enumValue = regexp.MustCompile(`'((?:[^'\\]|\\.)*)'\s*=\s*(-?\d+)`)
+
+// parseType returns the encoding of the type of a column.
+func parseType(typ string) (encoding, error) {
+	inner := func(prefix string) (string, bool) {
+		if strings.HasPrefix(typ, prefix+"(") && strings.HasSuffix(typ, ")") {
+			return typ[len(prefix)+1 : len(typ)-1], true
+		}
+		return "", false
+	}
+	if t, ok := inner("Nullable"); ok {
+		enc, err := parseType(t)
+		return nullable{enc}, err
+	}
+	if t, ok := inner("Array"); ok {
+		enc, err := parseType(t)
+		return array{enc}, err
+	}
+	// The output sets low_cardinality_allow_in_native_format to 0,
+	// so the server takes the values of the inner type.
+	if t, ok := inner("LowCardinality"); ok {
+		return parseType(t)
+	}
+	if t, ok := inner("FixedString"); ok {
+		n, err := strconv.Atoi(t)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported clickhouse type '%s'", typ)
+		}
+		return fixedString(n), nil
+	}
+	if t, ok := inner("DateTime64"); ok {
+		p, err := strconv.Atoi(strings.TrimSpace(strings.SplitN(t, ",", 2)[0]))
+		if err != nil {
+			return nil, fmt.Errorf("unsupported clickhouse type '%s'", typ)
+		}
+		return dateTime64(p), nil
+	}
+	if strings.HasPrefix(typ, "DateTime(") {
+		return dateTime{}, nil
+	}
+	for _, size := range []int{8, 16} {
+		if t, ok := inner(fmt.Sprintf("Enum%d", size)); ok {
+			e := enum{size: size / 8, values: make(map[string]int64)}
+			for i, m := range enumValue.FindAllStringSubmatch(t, -1) {
+				n, _ := strconv.ParseInt(m[2], 10, 64)
+				e.values[strings.ReplaceAll(m[1], `\'`, `'`)] = n
+				if i == 0 || n < e.def {
+					e.def = n
+				}
+			}
+			return e, nil
+		}
+	}
+
+	switch typ {
+	case "String":
+		return str{}, nil
+	case "Bool":
+		return integer{size: 1}, nil
+	case "UInt8", "UInt16", "UInt32", "UInt64":
+		bits, _ := strconv.Atoi(typ[4:])
+		return integer{size: bits / 8}, nil
+	case "Int8", "Int16", "Int32", "Int64":
+		bits, _ := strconv.Atoi(typ[3:])
+		return integer{size: bits / 8, signed: true}, nil
+	case "Float32":
+		return float{size: 4}, nil
+	case "Float64":
+		return float{size: 8}, nil
+	case "Date":
+		return date{}, nil
+	case "Date32":
+		return date{wide: true}, nil
+	case "DateTime":
+		return dateTime{}, nil
+	case "UUID":
+		return uuidType{}, nil
+	case "IPv4":
+		return ipv4{}, nil
+	case "IPv6":
+		return ipv6{}, nil
+	}
+	return nil, fmt.Errorf("unsupported clickhouse type '%s'", typ)
+}
+
+type str struct{}
+
+func (str) encode(b *buffer, values []interface{}) error {
+	for _, v := range values {
+		s, err := stringOf(v)
+		if err != nil {
+			return err
+		}
+		b.str(s)
+	}
+	return nil
+}
+
+type fixedString int
+
+func (n fixedString) encode(b *buffer, values []interface{}) error {
+	for _, v := range values {
+		s, err := stringOf(v)
+		if err != nil {
+			return err
+		}
+		if len(s) > int(n) {
+			s = s[:n]
+		}
+		b.WriteString(s)
+		b.Write(make([]byte, int(n)-len(s)))
+	}
+	return nil
+}
+
+type integer struct {
+	size   int
+	signed bool
+}
+
+func (i integer) encode(b *buffer, values []interface{}) error {
+	for _, v := range values {
+		var u uint64
+		if i.signed {
+			n, err := intOf(v)
+			if err != nil {
+				return err
+			}
+			u = uint64(n)
+		} else {
+			n, err := uintOf(v)
+			if err != nil {
+				return err
+			}
+			u = n
+		}
+		switch i.size {
+		case 1:
+			b.u8(uint8(u))
+		case 2:
+			b.u16(uint16(u))
+		case 4:
+			b.u32(uint32(u))
+		default:
+			b.u64(u)
+		}
+	}
+	return nil
+}
+
+type float struct {
+	size int
+}
+
+func (f float) encode(b *buffer, values []interface{}) error {
+	for _, v := range values {
+		n, err := floatOf(v)
+		if err != nil {
+			return err
+		}
+		if f.size == 4 {
+			b.f32(float32(n))
+			continue
+		}
+		b.f64(n)
+	}
+	return nil
+}
+
+// enum is an Enum8 or Enum16.  Fields that are not set are the least
+// value, the default of the server, as 0 need not be a value.
+type enum struct {
+	size   int
+	values map[string]int64
+	def    int64
+}
+
+func (e enum) encode(b *buffer, values []interface{}) error {
+	for _, v := range values {
+		n := e.def
+		if s, ok := v.(string); ok {
+			if n, ok = e.values[s]; !ok {
+				return fmt.Errorf("'%s' is not a value of the clickhouse enum", s)
+			}
+		} else if v != nil {
+			var err error
+			if n, err = intOf(v); err != nil {
+				return err
+			}
+		}
+		if e.size == 1 {
+			b.u8(uint8(n))
+			continue
+		}
+		b.u16(uint16(n))
+	}
+	return nil
+}
+
+// date is the days since 1970-01-01, a UInt16 or an Int32 for Date32.
+type date struct {
+	wide bool
+}
+
+func (d date) encode(b *buffer, values []interface{}) error {
+	for _, v := range values {
+		t, err := timeOf(v)
+		if err != nil {
+			return err
+		}
+		days := t.Unix() / 86400
+		if t.Unix() < 0 && t.Unix()%86400 != 0 {
+			days--
+		}
+		if d.wide {
+			b.u32(uint32(int32(days)))
+			continue
+		}
+		b.u16(uint16(days))
+	}
+	return nil
+}
+
+type dateTime struct{}
+
+func (dateTime) encode(b *buffer, values []interface{}) error {
+	for _, v := range values {
+		t, err := timeOf(v)
+		if err != nil {
+			return err
+		}
+		b.u32(uint32(t.Unix()))
+	}
+	return nil
+}
+
+// dateTime64 is the ticks of 10^-precision seconds since the epoch.
+type dateTime64 int
+
+func (p dateTime64) encode(b *buffer, values []interface{}) error {
+	scale := int64(math.Pow10(9 - int(p)))
+	for _, v := range values {
+		t, err := timeOf(v)
+		if err != nil {
+			return err
+		}
+		b.u64(uint64(t.UnixNano() / scale))
+	}
+	return nil
+}
+
+// uuidType is the two halves of the UUID, each a little endian UInt64.
+type uuidType struct{}
+
+func (uuidType) encode(b *buffer, values []interface{}) error {
+	for _, v := range values {
+		var id uuid.UUID
+		if v != nil {
+			s, err := stringOf(v)
+			if err != nil {
+				return err
+			}
+			if id, err = uuid.Parse(s); err != nil {
+				return fmt.Errorf("'%s' is not a valid UUID", s)
+			}
+		}
+		b.u64(binary.BigEndian.Uint64(id[:8]))
+		b.u64(binary.BigEndian.Uint64(id[8:]))
+	}
+	return nil
+}
+
+type ipv4 struct{}
+
+func (ipv4) encode(b *buffer, values []interface{}) error {
+	for _, v := range values {
+		ip, err := ipOf(v)
+		if err != nil {
+			return err
+		}
+		if ip == nil {
+			b.u32(0)
+			continue
+		}
+		if ip = ip.To4(); ip == nil {
+			return fmt.Errorf("'%v' is not a valid IPv4 address", v)
+		}
+		b.u32(binary.BigEndian.Uint32(ip))
+	}
+	return nil
+}
+
+type ipv6 struct{}
+
+func (ipv6) encode(b *buffer, values []interface{}) error {
+	for _, v := range values {
+		ip, err := ipOf(v)
+		if err != nil {
+			return err
+		}
+		if ip == nil {
+			ip = net.IPv6zero
+		}
+		b.Write(ip.To16())
+	}
+	return nil
+}
+
+// nullable is the null map of the values, followed by the values with
+// the default of the type for nulls.
+type nullable struct {
+	encoding
+}
+
+func (n nullable) encode(b *buffer, values []interface{}) error {
+	for _, v := range values {
+		if v == nil {
+			b.u8(1)
+			continue
+		}
+		b.u8(0)
+	}
+	return n.encoding.encode(b, values)
+}
+
+// array is the offsets of the ends of the arrays, followed by the
+// values of all the arrays.  A value that is not a list is an array of
+// one.
+type array struct {
+	encoding
+}
+
+func (a array) encode(b *buffer, values []interface{}) error {
+	var all []interface{}
+	for _, v := range values {
+		switch v := v.(type) {
+		case nil:
+		case []interface{}:
+			all = append(all, v...)
+		default:
+			all = append(all, v)
+		}
+		b.u64(uint64(len(all)))
+	}
+	return a.encoding.encode(b, all)
+}
+
+// stringOf returns the value of a String column, with objects and
+// lists as JSON.
+func stringOf(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func intOf(v interface{}) (int64, error) {
+	switch v := v.(type) {
+	case nil:
+		return 0, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		f, err := v.Float64()
+		return int64(f), err
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("'%s' is not a valid integer", v)
+		}
+		return int64(f), nil
+	}
+	return 0, fmt.Errorf("'%v' is not a valid integer", v)
+}
+
+func uintOf(v interface{}) (uint64, error) {
+	var s string
+	switch v := v.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		n, err := intOf(v)
+		return uint64(n), err
+	}
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return n, nil
+	}
+	n, err := intOf(v)
+	return uint64(n), err
+}
+
+func floatOf(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case json.Number:
+		return v.Float64()
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("'%s' is not a valid number", v)
+		}
+		return f, nil
+	}
+	n, err := intOf(v)
+	return float64(n), err
+}
+
+// timeOf returns the time of an RFC 3339 time, or of a number of
+// seconds since the epoch.  Times that are not set are the epoch.
+func timeOf(v interface{}) (time.Time, error) {
+	switch v := v.(type) {
+	case nil:
+		return time.Unix(0, 0), nil
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return t, fmt.Errorf("'%s' is not a valid RFC 3339 time", v)
+		}
+		return t, nil
+	}
+	f, err := floatOf(v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)), nil
+}
+
+// ipOf returns the IP address, nil if it is not set.
+func ipOf(v interface{}) (net.IP, error) {
+	if v == nil {
+		return nil, nil
+	}
+	s, err := stringOf(v)
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("'%s' is not a valid IP address", s)
+	}
+	return ip, nil
+}
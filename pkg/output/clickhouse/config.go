@@ -0,0 +1,39 @@
+package clickhouse
+
+import (
+	"fmt"
+	"net"
+)
+
+type config struct {
+	Type      string `config:"type" validate:"required"`
+	Address   string `config:"address"`
+	Database  string `config:"database"`
+	Username  string `config:"username"`
+	Password  string `config:"password"`
+	Table     string `config:"table" validate:"required"`
+	BatchSize int    `config:"batch_size"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:      Name,
+		Address:   "localhost:9000",
+		Database:  "default",
+		Username:  "default",
+		BatchSize: 10000,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return fmt.Errorf("'%s' is not a valid value for 'address' expected a host and port", c.Address)
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'batch_size' expected a value greater than 0", c.BatchSize)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package clickhouse
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid": {
+			c:           map[string]interface{}{"type": Name, "table": "logs"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid All": {
+			c:           map[string]interface{}{"type": Name, "address": "clickhouse:9000", "database": "spigot", "username": "spigot", "password": "secret", "table": "logs", "batch_size": 500},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "table": "logs"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'clickhouse' accessing config",
+		},
+		"No Table": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    true,
+			errorString: "string value is not set accessing 'table'",
+		},
+		"Invalid Address": {
+			c:           map[string]interface{}{"type": Name, "table": "logs", "address": "clickhouse"},
+			hasError:    true,
+			errorString: "'clickhouse' is not a valid value for 'address' expected a host and port accessing config",
+		},
+		"Invalid Batch Size": {
+			c:           map[string]interface{}{"type": Name, "table": "logs", "batch_size": 0},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'batch_size' expected a value greater than 0 accessing config",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		// New connects to the server, so only unpack the config.
+		config := defaultConfig()
+		err = c.Unpack(&config)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package clickhouse
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// revision is the revision of the native protocol the output speaks.
+// The fields of the packets that were added in later revisions are not
+// sent, the server leaves them out of its packets too.
+const revision = 54429
+
+// Packets of the client.
+const (
+	clientHello = 0
+	clientQuery = 1
+	clientData  = 2
+)
+
+// Packets of the server.
+const (
+	serverHello        = 0
+	serverData         = 1
+	serverException    = 2
+	serverProgress     = 3
+	serverEndOfStream  = 5
+	serverProfileInfo  = 6
+	serverTableColumns = 11
+)
+
+const (
+	stageComplete = 2
+	queryInitial  = 1
+	interfaceTCP  = 1
+)
+
+// buffer encodes the values of the packets the client sends.
+type buffer struct {
+	bytes.Buffer
+}
+
+func (b *buffer) uvarint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	b.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
+
+func (b *buffer) str(s string) {
+	b.uvarint(uint64(len(s)))
+	b.WriteString(s)
+}
+
+func (b *buffer) u8(v uint8) {
+	b.WriteByte(v)
+}
+
+func (b *buffer) u16(v uint16) {
+	b.Write(binary.LittleEndian.AppendUint16(nil, v))
+}
+
+func (b *buffer) u32(v uint32) {
+	b.Write(binary.LittleEndian.AppendUint32(nil, v))
+}
+
+func (b *buffer) u64(v uint64) {
+	b.Write(binary.LittleEndian.AppendUint64(nil, v))
+}
+
+func (b *buffer) f32(v float32) {
+	b.u32(math.Float32bits(v))
+}
+
+func (b *buffer) f64(v float64) {
+	b.u64(math.Float64bits(v))
+}
+
+// blockInfo writes the BlockInfo of a block: not overflows, no bucket.
+func (b *buffer) blockInfo() {
+	b.uvarint(1)
+	b.u8(0)
+	b.uvarint(2)
+	b.u32(math.MaxUint32)
+	b.uvarint(0)
+}
+
+// reader decodes the values of the packets the server sends.  After
+// the first error the values are zero, the error is returned by err.
+type reader struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (r *reader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	var v uint64
+	v, r.err = binary.ReadUvarint(r.r)
+	return v
+}
+
+func (r *reader) str() string {
+	n := r.uvarint()
+	if r.err != nil {
+		return ""
+	}
+	if n > 1<<30 {
+		r.err = fmt.Errorf("clickhouse string of %d bytes is too long", n)
+		return ""
+	}
+	b := make([]byte, n)
+	r.read(b)
+	return string(b)
+}
+
+func (r *reader) read(b []byte) {
+	if r.err != nil {
+		return
+	}
+	_, r.err = io.ReadFull(r.r, b)
+}
+
+func (r *reader) u8() uint8 {
+	var b [1]byte
+	r.read(b[:])
+	return b[0]
+}
+
+func (r *reader) u32() uint32 {
+	var b [4]byte
+	r.read(b[:])
+	return binary.LittleEndian.Uint32(b[:])
+}
+
+// exception reads an exception of the server, with its nested
+// exceptions.
+func (r *reader) exception() error {
+	var msgs []string
+	for {
+		code := int32(r.u32())
+		name := r.str()
+		message := r.str()
+		r.str() // stack trace
+		nested := r.u8()
+		if r.err != nil {
+			return r.err
+		}
+		msgs = append(msgs, fmt.Sprintf("%s (%d): %s", name, code, message))
+		if nested == 0 {
+			break
+		}
+	}
+	return fmt.Errorf("clickhouse exception: %s", strings.Join(msgs, ": "))
+}
+
+// header reads the header of a block without rows, the names and types
+// of its columns.
+func (r *reader) header() ([]column, error) {
+	r.str() // table name
+	for {
+		switch field := r.uvarint(); field {
+		case 0:
+		case 1:
+			r.u8()
+			continue
+		case 2:
+			r.u32()
+			continue
+		default:
+			return nil, fmt.Errorf("unexpected clickhouse block info field %d", field)
+		}
+		break
+	}
+	n, rows := r.uvarint(), r.uvarint()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if rows != 0 {
+		return nil, fmt.Errorf("unexpected clickhouse block of %d rows", rows)
+	}
+	columns := make([]column, n)
+	for i := range columns {
+		columns[i].name = r.str()
+		columns[i].typ = r.str()
+	}
+	return columns, r.err
+}
@@ -0,0 +1,97 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DatePattern is a text of literal parts and date formats, such as the
+// name of an index or the key of an object.
+type DatePattern []datePart
+
+type datePart struct {
+	literal string
+	layout  string
+}
+
+// dateTokens are the Joda-Time pattern letters of the date formats,
+// longest first, and their Go layouts.
+var dateTokens = []struct {
+	token  string
+	layout string
+}{
+	{"yyyy", "2006"},
+	{"yy", "06"},
+	{"MM", "01"},
+	{"dd", "02"},
+	{"HH", "15"},
+	{"mm", "04"},
+	{"ss", "05"},
+}
+
+// ParseDatePattern parses s, the value of option, with date formats
+// such as %{+yyyy.MM.dd}.
+func ParseDatePattern(option, s string) (DatePattern, error) {
+	var p DatePattern
+	orig := s
+	for s != "" {
+		start := strings.Index(s, "%{+")
+		if start < 0 {
+			p = append(p, datePart{literal: s})
+			break
+		}
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			return nil, fmt.Errorf("'%s' is not a valid value for '%s' expected a '}' after '%%{+'", orig, option)
+		}
+		if start > 0 {
+			p = append(p, datePart{literal: s[:start]})
+		}
+		layout, err := dateLayout(option, s[start+3:start+end])
+		if err != nil {
+			return nil, err
+		}
+		p = append(p, datePart{layout: layout})
+		s = s[start+end+1:]
+	}
+	return p, nil
+}
+
+// dateLayout returns the Go layout of a Joda-Time date format.  Letters
+// and digits other than the supported patterns are not allowed, other
+// characters are literal.
+func dateLayout(option, format string) (string, error) {
+	var b strings.Builder
+	orig := format
+next:
+	for format != "" {
+		for _, t := range dateTokens {
+			if strings.HasPrefix(format, t.token) {
+				b.WriteString(t.layout)
+				format = format[len(t.token):]
+				continue next
+			}
+		}
+		if c := format[0]; (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			return "", fmt.Errorf("'%s' is not a valid value for '%s' expected a date format of yyyy, yy, MM, dd, HH, mm and ss", orig, option)
+		}
+		b.WriteByte(format[0])
+		format = format[1:]
+	}
+	return b.String(), nil
+}
+
+// Format returns the text of the pattern at time t, in UTC.
+func (p DatePattern) Format(t time.Time) string {
+	var b strings.Builder
+	t = t.UTC()
+	for _, part := range p {
+		if part.layout != "" {
+			b.WriteString(t.Format(part.layout))
+			continue
+		}
+		b.WriteString(part.literal)
+	}
+	return b.String()
+}
@@ -0,0 +1,128 @@
+package output
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// DeliveryConfig is the configuration of the write patterns of the
+// object store outputs, the edge cases that break list based pollers.
+// Late is the percentage of objects delivered LateBy after they are
+// complete, so after newer objects, Overwrite the percentage of
+// objects written again to their key after the next object, and Empty
+// the percentage of objects preceded by a zero-length object.
+type DeliveryConfig struct {
+	Late      float64       `config:"late"`
+	LateBy    time.Duration `config:"late_by"`
+	Overwrite float64       `config:"overwrite"`
+	Empty     float64       `config:"empty"`
+}
+
+// DefaultDeliveryConfig returns a DeliveryConfig that delivers every
+// object once, in order.
+func DefaultDeliveryConfig() DeliveryConfig {
+	return DeliveryConfig{
+		LateBy: 5 * time.Minute,
+	}
+}
+
+// Validate checks that the shares are percentages and that LateBy is
+// positive.
+func (c *DeliveryConfig) Validate() error {
+	for _, p := range []struct {
+		name  string
+		value float64
+	}{{"late", c.Late}, {"overwrite", c.Overwrite}, {"empty", c.Empty}} {
+		if p.value < 0 || p.value > 100 {
+			return fmt.Errorf("'%v' is not a valid value for '%s' expected a percentage from 0 to 100", p.value, p.name)
+		}
+	}
+	if c.LateBy <= 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'late_by' expected a positive duration", c.LateBy)
+	}
+	return nil
+}
+
+// Delivery puts the objects of an output with the write patterns of a
+// DeliveryConfig.
+type Delivery struct {
+	c     DeliveryConfig
+	put   func(name string, body []byte) error
+	name  func(t time.Time) string
+	now   func() time.Time
+	held  []heldObject
+	again *heldObject
+}
+
+type heldObject struct {
+	name string
+	body []byte
+	due  time.Time
+}
+
+// NewDelivery returns the Delivery of c, that puts the objects with
+// put.  name returns the name of a new object at a time, for the
+// zero-length objects, and now the current time.
+func NewDelivery(c DeliveryConfig, put func(name string, body []byte) error, name func(t time.Time) string, now func() time.Time) *Delivery {
+	return &Delivery{c: c, put: put, name: name, now: now}
+}
+
+// Deliver puts the object name with body, or holds it back to deliver
+// it late.  Earlier objects that are due are put first.  body is not
+// used after Deliver returns.
+func (d *Delivery) Deliver(name string, body []byte) error {
+	now := d.now()
+	if err := d.Poll(); err != nil {
+		return err
+	}
+	if chance(d.c.Empty) {
+		if err := d.put(d.name(now), nil); err != nil {
+			return err
+		}
+	}
+	if chance(d.c.Late) {
+		d.held = append(d.held, heldObject{name: name, body: append([]byte(nil), body...), due: now.Add(d.c.LateBy)})
+		return nil
+	}
+	if err := d.put(name, body); err != nil {
+		return err
+	}
+	if chance(d.c.Overwrite) {
+		d.again = &heldObject{name: name, body: append([]byte(nil), body...)}
+	}
+	return nil
+}
+
+// Poll puts the object to overwrite and the late objects that are due.
+func (d *Delivery) Poll() error {
+	if a := d.again; a != nil {
+		d.again = nil
+		if err := d.put(a.name, a.body); err != nil {
+			return err
+		}
+	}
+	now := d.now()
+	for len(d.held) > 0 && !now.Before(d.held[0].due) {
+		h := d.held[0]
+		d.held = d.held[1:]
+		if err := d.put(h.name, h.body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush puts the object to overwrite and all the late objects, on
+// close.
+func (d *Delivery) Flush() error {
+	for i := range d.held {
+		d.held[i].due = time.Time{}
+	}
+	return d.Poll()
+}
+
+// chance reports whether an event of percent happens.
+func chance(percent float64) bool {
+	return percent > 0 && rand.Float64()*100 < percent
+}
@@ -0,0 +1,75 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type config struct {
+	Type       string            `config:"type" validate:"required"`
+	URL        string            `config:"url"`
+	Index      string            `config:"index"`
+	Pipeline   string            `config:"pipeline"`
+	Username   string            `config:"username"`
+	Password   string            `config:"password"`
+	APIKey     string            `config:"api_key"`
+	Headers    map[string]string `config:"headers"`
+	BatchSize  int               `config:"batch_size"`
+	TimeField  string            `config:"time_field"`
+	MaxRetries int               `config:"max_retries"`
+	Backoff    backoffConfig     `config:"backoff"`
+	Timeout    time.Duration     `config:"timeout"`
+}
+
+type backoffConfig struct {
+	Init time.Duration `config:"init"`
+	Max  time.Duration `config:"max"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:       Name,
+		URL:        "http://localhost:9200",
+		Index:      "spigot-%{+yyyy.MM.dd}",
+		BatchSize:  1000,
+		TimeField:  "@timestamp",
+		MaxRetries: 3,
+		Backoff: backoffConfig{
+			Init: time.Second,
+			Max:  time.Minute,
+		},
+		Timeout: 90 * time.Second,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("'%s' is not a valid value for 'url' expected an http or https URL", c.URL)
+	}
+	if _, err := parseIndex(c.Index); err != nil {
+		return err
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'batch_size' expected a value greater than 0", c.BatchSize)
+	}
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'max_retries' expected a value of 0 or more", c.MaxRetries)
+	}
+	if c.Backoff.Init <= 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'backoff.init' expected a positive duration", c.Backoff.Init)
+	}
+	if c.Backoff.Max < c.Backoff.Init {
+		return fmt.Errorf("'%s' is not a valid value for 'backoff.max' expected a duration of at least 'backoff.init'", c.Backoff.Max)
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'timeout' expected a positive duration", c.Timeout)
+	}
+	if c.APIKey != "" && (c.Username != "" || c.Password != "") {
+		return fmt.Errorf("only one of api_key and username and password can be set")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid All": {
+			c:           map[string]interface{}{"type": Name, "url": "https://es.lab:9200", "index": "logs-%{+yyyy.MM.dd}", "pipeline": "spigot", "api_key": "id:key", "batch_size": 500, "time_field": "event.created", "max_retries": 0, "backoff.init": "500ms", "backoff.max": "10s", "timeout": "30s"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'elasticsearch' accessing config",
+		},
+		"Invalid URL": {
+			c:           map[string]interface{}{"type": Name, "url": "localhost:9200"},
+			hasError:    true,
+			errorString: "'localhost:9200' is not a valid value for 'url' expected an http or https URL accessing config",
+		},
+		"Invalid Index": {
+			c:           map[string]interface{}{"type": Name, "index": "logs-%{+yyyy.MM.dd"},
+			hasError:    true,
+			errorString: "'logs-%{+yyyy.MM.dd' is not a valid value for 'index' expected a '}' after '%{+' accessing config",
+		},
+		"Invalid Batch Size": {
+			c:           map[string]interface{}{"type": Name, "batch_size": 0},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'batch_size' expected a value greater than 0 accessing config",
+		},
+		"Invalid Max Retries": {
+			c:           map[string]interface{}{"type": Name, "max_retries": -1},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'max_retries' expected a value of 0 or more accessing config",
+		},
+		"Invalid Backoff Max": {
+			c:           map[string]interface{}{"type": Name, "backoff.init": "10s", "backoff.max": "1s"},
+			hasError:    true,
+			errorString: "'1s' is not a valid value for 'backoff.max' expected a duration of at least 'backoff.init' accessing config",
+		},
+		"Both Auth": {
+			c:           map[string]interface{}{"type": Name, "api_key": "id:key", "username": "elastic"},
+			hasError:    true,
+			errorString: "only one of api_key and username and password can be set accessing config",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c, ucfg.PathSep("."))
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// Package elasticsearch implements the output of structured events to
+// Elasticsearch, with batched _bulk requests.
+//
+// Events must be JSON objects, such as those of generators with a
+// json format.  Each event is a create action, so index may be a data
+// stream.  index is spigot-%{+yyyy.MM.dd} by default, the %{+format}
+// parts are the date of the event in UTC, from the RFC 3339 time of
+// time_field, "@timestamp" by default, or the current time.  The
+// formats are Joda-Time patterns of yyyy, yy, MM, dd, HH, mm and ss.
+// Elasticsearch date math, such as "<spigot-{now/d}>", is resolved by
+// Elasticsearch.  pipeline is optional and is the ingest pipeline of
+// the requests.
+//
+// The events are sent when batch_size events are buffered, with each
+// new interval and on close.  When Elasticsearch answers 429 Too Many
+// Requests, for the request or for some of the events, the request or
+// these events are sent again after a backoff, from backoff.init
+// doubling up to backoff.max, at most max_retries times.  Other
+// errors of events are returned, the events are not sent again.
+//
+//	output:
+//	  type: elasticsearch
+//	  url: "https://localhost:9200"
+//	  index: "logs-spigot-%{+yyyy.MM.dd}"
+//	  api_key: "VnVhQ2ZHY0JDZGJrUW0tZTVhT3g6dWkybHAyYXhUTm1zeWFrdzl0dk5udw=="
+//	  batch_size: 1000
+//	  max_retries: 3
+//	  backoff:
+//	    init: 1s
+//	    max: 60s
+//
+// The requests are authorized with username and password, or with
+// api_key, the base64 encoded "id:api_key" or the id and key joined
+// with a colon.  headers is optional and is added to every request.
+// timeout is the timeout of each request, by default 90s.
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// Name is the name of the output in the configuration file and registry.
+const Name = "elasticsearch"
+
+// Output buffers the events until they are sent.
+type Output struct {
+	c      config
+	url    string
+	index  output.DatePattern
+	client *http.Client
+	docs   []document
+	sleep  func(time.Duration)
+}
+
+// document is an event with the action of its index.
+type document struct {
+	action []byte
+	source []byte
+}
+
+func init() {
+	output.Register(Name, New)
+}
+
+// New is the Factory for creating a new elasticsearch output.
+func New(cfg *ucfg.Config) (output.Output, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	// Validate checked the index.
+	idx, _ := parseIndex(c.Index)
+	u := strings.TrimSuffix(c.URL, "/") + "/_bulk"
+	if c.Pipeline != "" {
+		u += "?" + url.Values{"pipeline": {c.Pipeline}}.Encode()
+	}
+	return &Output{
+		c:      c,
+		url:    u,
+		index:  idx,
+		client: &http.Client{Timeout: c.Timeout},
+		sleep:  time.Sleep,
+	}, nil
+}
+
+// Write buffers the event, and sends the buffered events when there
+// are batch_size of them.
+func (o *Output) Write(b []byte) (int, error) {
+	event, err := output.Event(Name, b)
+	if err != nil {
+		return 0, err
+	}
+	t := time.Now()
+	if v, ok := output.Field(event, o.c.TimeField); ok {
+		if s, ok := v.(string); ok {
+			if ts, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				t = ts
+			}
+		}
+	}
+	action, _ := json.Marshal(map[string]interface{}{"create": map[string]string{"_index": o.index.Format(t)}})
+	o.docs = append(o.docs, document{action: action, source: bytes.TrimSpace(b)})
+	if len(o.docs) >= o.c.BatchSize {
+		if err := o.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// flush sends the buffered events, and sends the events that were
+// rejected with 429 again after a backoff.
+func (o *Output) flush() error {
+	docs := o.docs
+	o.docs = nil
+	backoff := o.c.Backoff.Init
+	for retry := 0; len(docs) > 0; retry++ {
+		if retry > 0 {
+			if retry > o.c.MaxRetries {
+				return fmt.Errorf("elasticsearch rejected %d events with 429 Too Many Requests after %d retries", len(docs), o.c.MaxRetries)
+			}
+			o.sleep(backoff)
+			if backoff *= 2; backoff > o.c.Backoff.Max {
+				backoff = o.c.Backoff.Max
+			}
+		}
+		var err error
+		if docs, err = o.bulk(docs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bulkResponse is the response of a _bulk request.
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// bulk sends the documents in a _bulk request and returns the
+// documents to send again, all of them if the request was rejected
+// with 429 or the ones that were.
+func (o *Output) bulk(docs []document) ([]document, error) {
+	var body bytes.Buffer
+	for _, d := range docs {
+		body.Write(d.action)
+		body.WriteByte('\n')
+		body.Write(d.source)
+		body.WriteByte('\n')
+	}
+	req, err := http.NewRequest(http.MethodPost, o.url, &body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range o.c.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	switch {
+	case o.c.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+apiKey(o.c.APIKey))
+	case o.c.Username != "":
+		req.SetBasicAuth(o.c.Username, o.c.Password)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return docs, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("%s %s returned %s", req.Method, o.url, resp.Status)
+	}
+	var r bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("elasticsearch bulk response is not valid: %w", err)
+	}
+	if !r.Errors {
+		return nil, nil
+	}
+
+	var retry []document
+	var failed int
+	var first string
+	for i, item := range r.Items {
+		for _, result := range item {
+			switch {
+			case result.Status == http.StatusTooManyRequests && i < len(docs):
+				retry = append(retry, docs[i])
+			case result.Status < 200 || result.Status > 299:
+				if failed == 0 {
+					first = fmt.Sprintf("%s: %s", result.Error.Type, result.Error.Reason)
+				}
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		return nil, fmt.Errorf("elasticsearch failed to index %d events, the first with %s", failed, first)
+	}
+	return retry, nil
+}
+
+// apiKey returns the base64 encoded API key, of an encoded key or an
+// id and key joined with a colon.
+func apiKey(key string) string {
+	if strings.Contains(key, ":") {
+		return base64.StdEncoding.EncodeToString([]byte(key))
+	}
+	return key
+}
+
+// Close sends the buffered events and closes any idle connections.
+func (o *Output) Close() error {
+	err := o.flush()
+	o.client.CloseIdleConnections()
+	return err
+}
+
+// NewInterval sends the buffered events, so each interval is a batch
+// of its own.
+func (o *Output) NewInterval() error {
+	return o.flush()
+}
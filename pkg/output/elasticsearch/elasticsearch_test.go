@@ -0,0 +1,152 @@
+package elasticsearch
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	var bodies []string
+	var r *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(b))
+		r = req
+		_, _ = w.Write([]byte(`{"took":1,"errors":false,"items":[{"create":{"status":201}},{"create":{"status":201}}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "url": srv.URL, "index": "logs-%{+yyyy.MM.dd}", "pipeline": "spigot", "batch_size": 2, "username": "elastic", "password": "changeme", "headers": map[string]interface{}{"X-Opaque-Id": "spigot"}})
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+	for _, e := range []string{
+		`{"@timestamp":"2024-03-01T23:59:59.999-01:00","message":"one"}`,
+		`{"@timestamp":"2024-03-04T12:00:00Z","message":"two"}` + "\n",
+		`{"message":"three"}`,
+	} {
+		_, err := out.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	assert.Len(t, bodies, 1)
+	assert.Equal(t, "/_bulk", r.URL.Path)
+	assert.Equal(t, "pipeline=spigot", r.URL.RawQuery)
+	assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+	assert.Equal(t, "spigot", r.Header.Get("X-Opaque-Id"))
+	user, password, ok := r.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "elastic", user)
+	assert.Equal(t, "changeme", password)
+	assert.Equal(t, `{"create":{"_index":"logs-2024.03.02"}}
+{"@timestamp":"2024-03-01T23:59:59.999-01:00","message":"one"}
+{"create":{"_index":"logs-2024.03.04"}}
+{"@timestamp":"2024-03-04T12:00:00Z","message":"two"}
+`, bodies[0])
+
+	assert.Nil(t, out.Close())
+	assert.Len(t, bodies, 2)
+	assert.Equal(t, `{"create":{"_index":"logs-`+time.Now().UTC().Format("2006.01.02")+`"}}
+{"message":"three"}
+`, bodies[1])
+
+	_, err = out.Write([]byte("not json"))
+	assert.EqualError(t, err, "elasticsearch output expected a JSON object, use a json format of the generator")
+}
+
+func TestBackoff(t *testing.T) {
+	var bodies []string
+	responses := []struct {
+		status int
+		body   string
+	}{
+		{http.StatusTooManyRequests, `{"error":"too many requests"}`},
+		{http.StatusOK, `{"errors":true,"items":[{"create":{"status":201}},{"create":{"status":429,"error":{"type":"es_rejected_execution_exception","reason":"rejected"}}}]}`},
+		{http.StatusOK, `{"errors":false,"items":[{"create":{"status":201}}]}`},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		assert.Equal(t, "ApiKey aWQ6a2V5", r.Header.Get("Authorization"))
+		resp := responses[0]
+		responses = responses[1:]
+		w.WriteHeader(resp.status)
+		_, _ = w.Write([]byte(resp.body))
+	}))
+	defer srv.Close()
+
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "url": srv.URL, "index": "logs", "api_key": "id:key", "backoff.init": "1s", "backoff.max": "3s", "max_retries": 2}, ucfg.PathSep("."))
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+	var sleeps []time.Duration
+	out.(*Output).sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+	for _, e := range []string{`{"message":"one"}`, `{"message":"two"}`} {
+		_, err := out.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, out.NewInterval())
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, sleeps)
+	assert.Len(t, bodies, 3)
+	assert.Equal(t, bodies[0], bodies[1])
+	assert.Equal(t, "{\"create\":{\"_index\":\"logs\"}}\n{\"message\":\"two\"}\n", bodies[2])
+
+	for i := 0; i < 3; i++ {
+		responses = append(responses, struct {
+			status int
+			body   string
+		}{http.StatusTooManyRequests, ""})
+	}
+	_, err = out.Write([]byte(`{"message":"three"}`))
+	assert.Nil(t, err)
+	assert.EqualError(t, out.Close(), "elasticsearch rejected 1 events with 429 Too Many Requests after 2 retries")
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, time.Second, 2 * time.Second}, sleeps)
+}
+
+func TestErrors(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"errors":true,"items":[{"create":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse field [bytes]"}}},{"create":{"status":201}}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "url": srv.URL + "/"})
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+	_, err = out.Write([]byte(`{"bytes":"many"}`))
+	assert.Nil(t, err)
+	assert.EqualError(t, out.NewInterval(), "elasticsearch failed to index 1 events, the first with mapper_parsing_exception: failed to parse field [bytes]")
+
+	status = http.StatusUnauthorized
+	_, err = out.Write([]byte(`{"bytes":1}`))
+	assert.Nil(t, err)
+	assert.EqualError(t, out.NewInterval(), "POST "+srv.URL+"/_bulk returned 401 Unauthorized")
+}
+
+func TestIndex(t *testing.T) {
+	ts := time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := map[string]string{
+		"spigot":                       "spigot",
+		"spigot-%{+yyyy.MM.dd}":        "spigot-2024.03.04",
+		"%{+yy}/%{+HH:mm:ss}-x":        "24/05:06:07-x",
+		"<spigot-{now/d}>":             "<spigot-{now/d}>",
+		"a-%{+yyyy}-b-%{+MM}-c-%{+dd}": "a-2024-b-03-c-04",
+	}
+	for s, want := range tests {
+		idx, err := parseIndex(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, want, idx.Format(ts), s)
+	}
+	_, err := parseIndex("spigot-%{+yyyy.MM.dd")
+	assert.EqualError(t, err, "'spigot-%{+yyyy.MM.dd' is not a valid value for 'index' expected a '}' after '%{+'")
+	_, err = parseIndex("spigot-%{+YYYY.ww}")
+	assert.EqualError(t, err, "'YYYY.ww' is not a valid value for 'index' expected a date format of yyyy, yy, MM, dd, HH, mm and ss")
+}
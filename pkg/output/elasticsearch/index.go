@@ -0,0 +1,16 @@
+package elasticsearch
+
+import (
+	"fmt"
+
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// parseIndex parses the name of the index, with date formats such as
+// %{+yyyy.MM.dd}.
+func parseIndex(s string) (output.DatePattern, error) {
+	if s == "" {
+		return nil, fmt.Errorf("'' is not a valid value for 'index' expected an index name")
+	}
+	return output.ParseDatePattern("index", s)
+}
@@ -0,0 +1,68 @@
+package etw
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Type      string `config:"type" validate:"required"`
+	Provider  string `config:"provider"`
+	GUID      string `config:"guid"`
+	EventName string `config:"event_name"`
+	EventID   int    `config:"event_id"`
+	Level     string `config:"level"`
+	Keyword   uint64 `config:"keyword"`
+}
+
+// levels are the names of the ETW levels, in order from level 1.
+var levels = [...]string{"critical", "error", "warning", "information", "verbose"}
+
+func defaultConfig() config {
+	return config{
+		Type:      Name,
+		Provider:  "Spigot",
+		EventName: "Event",
+		Level:     "information",
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if c.Provider == "" {
+		return fmt.Errorf("'provider' can not be empty")
+	}
+	if strings.ContainsRune(c.Provider, 0) {
+		return fmt.Errorf("'%s' is not a valid value for 'provider' expected a name without NUL", c.Provider)
+	}
+	if c.GUID != "" {
+		if _, err := parseGUID(c.GUID); err != nil {
+			return fmt.Errorf("'%s' is not a valid value for 'guid' expected a GUID like '{xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx}'", c.GUID)
+		}
+	}
+	if c.EventName == "" {
+		return fmt.Errorf("'event_name' can not be empty")
+	}
+	if strings.ContainsRune(c.EventName, 0) {
+		return fmt.Errorf("'%s' is not a valid value for 'event_name' expected a name without NUL", c.EventName)
+	}
+	if c.EventID < 0 || c.EventID > 65535 {
+		return fmt.Errorf("'%d' is not a valid value for 'event_id' expected a value from 0 to 65535", c.EventID)
+	}
+	if level(c.Level) == 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'level' expected '%s'", c.Level, strings.Join(levels[:], ", "))
+	}
+	return nil
+}
+
+// level returns the ETW level of name, or 0 if it is not one.
+func level(name string) uint8 {
+	for i, l := range levels {
+		if strings.EqualFold(name, l) {
+			return uint8(i + 1)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+package etw
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Options": {
+			c:           map[string]interface{}{"type": Name, "provider": "Spigot-Test", "guid": "{de934bea-3d2f-5646-73dd-31f7ac8432ea}", "event_name": "Syslog", "event_id": 100, "level": "Warning", "keyword": 0x10},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'etw' accessing config",
+		},
+		"Empty Provider": {
+			c:           map[string]interface{}{"type": Name, "provider": ""},
+			hasError:    true,
+			errorString: "'provider' can not be empty accessing config",
+		},
+		"Invalid GUID": {
+			c:           map[string]interface{}{"type": Name, "guid": "de934bea-3d2f-5646-73dd"},
+			hasError:    true,
+			errorString: "'de934bea-3d2f-5646-73dd' is not a valid value for 'guid' expected a GUID like '{xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx}' accessing config",
+		},
+		"Empty Event Name": {
+			c:           map[string]interface{}{"type": Name, "event_name": ""},
+			hasError:    true,
+			errorString: "'event_name' can not be empty accessing config",
+		},
+		"Invalid Event ID": {
+			c:           map[string]interface{}{"type": Name, "event_id": 65536},
+			hasError:    true,
+			errorString: "'65536' is not a valid value for 'event_id' expected a value from 0 to 65535 accessing config",
+		},
+		"Invalid Level": {
+			c:           map[string]interface{}{"type": Name, "level": "debug"},
+			hasError:    true,
+			errorString: "'debug' is not a valid value for 'level' expected 'critical, error, warning, information, verbose' accessing config",
+		},
+	}
+	for name, tc := range tests {
+		cfg, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		c := defaultConfig()
+		err = cfg.Unpack(&c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
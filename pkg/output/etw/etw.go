@@ -0,0 +1,208 @@
+// Package etw implements the output of events as Event Tracing for
+// Windows (ETW) events of a provider of spigot, to test ETW based
+// collectors, such as the etw input of Filebeat, without files.
+//
+// The events are TraceLogging events, which describe themselves, so a
+// consumer decodes them without a manifest.  Every event is named
+// event_name and has the field "message", the event of the generator
+// as a UTF-8 string.  The provider is registered with the GUID guid,
+// or without it with the GUID that EventSource and TraceLogging derive
+// from the name of the provider, so "*Spigot" selects it in tools like
+// PerfView or tracelog and logman needs
+// {de934bea-3d2f-5646-73dd-31f7ac8432ea}.  The events are only written
+// while a session has the provider enabled for level and keyword.
+//
+// The output is only available on Windows.
+//
+//	output:
+//	  type: etw
+//	  provider: "Spigot"
+//	  event_name: "Event"
+//	  event_id: 0
+//	  level: information
+//	  keyword: 0
+package etw
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// Name is the name of the output in the configuration file and registry.
+const Name = "etw"
+
+// channelTraceLogging is the channel that marks the events of a
+// descriptor as TraceLogging events.
+const channelTraceLogging = 11
+
+// The TraceLogging types of the message field, a NUL terminated UTF-8
+// string.
+const (
+	inTypeANSIString = 2
+	outTypeUTF8      = 35
+	inTypeOutType    = 0x80
+)
+
+// Output writes the events as the events of an ETW provider.
+type Output struct {
+	provider   provider
+	descriptor descriptor
+	metadata   []byte
+	event      []byte
+}
+
+// guid is a GUID, in the layout of the GUIDs of Windows.
+type guid struct {
+	Data1 uint32
+	Data2 uint16
+	Data3 uint16
+	Data4 [8]byte
+}
+
+// descriptor is an EVENT_DESCRIPTOR, the identity of an event.
+type descriptor struct {
+	ID      uint16
+	Version uint8
+	Channel uint8
+	Level   uint8
+	Opcode  uint8
+	Task    uint16
+	Keyword uint64
+}
+
+// provider is a registered ETW provider.  write writes an event of
+// descriptor, with the provider traits metadata, the event metadata
+// event and the data of the fields.
+type provider interface {
+	write(d *descriptor, metadata, event, data []byte) error
+	close() error
+}
+
+func init() {
+	output.Register(Name, New)
+}
+
+// New is the Factory for creating a new ETW output.
+func New(cfg *ucfg.Config) (output.Output, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	id := providerGUID(c.Provider)
+	if c.GUID != "" {
+		id, _ = parseGUID(c.GUID)
+	}
+	p, err := newProvider(id)
+	if err != nil {
+		return nil, err
+	}
+	return &Output{
+		provider: p,
+		descriptor: descriptor{
+			ID:      uint16(c.EventID),
+			Channel: channelTraceLogging,
+			Level:   level(c.Level),
+			Keyword: c.Keyword,
+		},
+		metadata: providerMetadata(c.Provider),
+		event:    eventMetadata(c.EventName),
+	}, nil
+}
+
+// Write writes p, without its trailing line break, as the message of
+// an event.
+func (o *Output) Write(p []byte) (int, error) {
+	data := make([]byte, 0, len(p)+1)
+	data = append(data, strings.TrimSuffix(string(p), "\n")...)
+	data = append(data, 0)
+	if err := o.provider.write(&o.descriptor, o.metadata, o.event, data); err != nil {
+		return 0, fmt.Errorf("writing ETW event of %d bytes: %w", len(p), err)
+	}
+	return len(p), nil
+}
+
+// Close unregisters the provider.
+func (o *Output) Close() error {
+	return o.provider.close()
+}
+
+// NewInterval is a no-op, every event is written on its own.
+func (o *Output) NewInterval() error {
+	return nil
+}
+
+// providerGUID returns the GUID EventSource derives from the name of
+// a provider: the SHA-1 hash of the name in upper case, in big endian
+// UTF-16, in the namespace of EventSource, as a version 5 GUID.
+func providerGUID(name string) guid {
+	namespace := []byte{0x48, 0x2c, 0x2d, 0xb2, 0xc3, 0x90, 0x47, 0xc8, 0x87, 0xf8, 0x1a, 0x15, 0xbf, 0xc1, 0x30, 0xfb}
+	h := sha1.New()
+	h.Write(namespace)
+	for _, u := range utf16.Encode([]rune(strings.ToUpper(name))) {
+		h.Write([]byte{byte(u >> 8), byte(u)})
+	}
+	sum := h.Sum(nil)
+	sum[7] = sum[7]&0x0f | 0x50
+
+	var g guid
+	g.Data1 = binary.LittleEndian.Uint32(sum[0:4])
+	g.Data2 = binary.LittleEndian.Uint16(sum[4:6])
+	g.Data3 = binary.LittleEndian.Uint16(sum[6:8])
+	copy(g.Data4[:], sum[8:16])
+	return g
+}
+
+// parseGUID parses s, a GUID with or without braces.
+func parseGUID(s string) (guid, error) {
+	var g guid
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	parts := strings.Split(s, "-")
+	if len(parts) != 5 || len(parts[0]) != 8 || len(parts[1]) != 4 || len(parts[2]) != 4 || len(parts[3]) != 4 || len(parts[4]) != 12 {
+		return g, errors.New("invalid GUID")
+	}
+	b, err := hex.DecodeString(strings.Join(parts, ""))
+	if err != nil {
+		return g, err
+	}
+	g.Data1 = binary.BigEndian.Uint32(b[0:4])
+	g.Data2 = binary.BigEndian.Uint16(b[4:6])
+	g.Data3 = binary.BigEndian.Uint16(b[6:8])
+	copy(g.Data4[:], b[8:16])
+	return g, nil
+}
+
+// String returns g in the registry format of Windows.
+func (g guid) String() string {
+	return fmt.Sprintf("{%08x-%04x-%04x-%x-%x}", g.Data1, g.Data2, g.Data3, g.Data4[:2], g.Data4[2:])
+}
+
+// providerMetadata returns the TraceLogging provider traits of a
+// provider name: its size and the NUL terminated name.
+func providerMetadata(name string) []byte {
+	b := make([]byte, 2, 2+len(name)+1)
+	b = append(b, name...)
+	b = append(b, 0)
+	binary.LittleEndian.PutUint16(b, uint16(len(b)))
+	return b
+}
+
+// eventMetadata returns the TraceLogging metadata of the events named
+// name: its size, no tags, the NUL terminated name of the event and
+// the message field, a UTF-8 string.
+func eventMetadata(name string) []byte {
+	b := make([]byte, 3, 3+len(name)+1+len("message")+1+2)
+	b = append(b, name...)
+	b = append(b, 0)
+	b = append(b, "message"...)
+	b = append(b, 0, inTypeANSIString|inTypeOutType, outTypeUTF8)
+	binary.LittleEndian.PutUint16(b, uint16(len(b)))
+	return b
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package etw
+
+import "errors"
+
+// newProvider returns an error, ETW is only available on Windows.
+func newProvider(id guid) (provider, error) {
+	return nil, errors.New("the etw output is only supported on Windows")
+}
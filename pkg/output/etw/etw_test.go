@@ -0,0 +1,80 @@
+package etw
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestProviderGUID checks the GUIDs of EventSource providers of .NET,
+// which are derived from their names.
+func TestProviderGUID(t *testing.T) {
+	tests := map[string]string{
+		"Microsoft-Extensions-Logging": "{3ac73b97-af73-50e9-0822-5da4367920d0}",
+		"System.Runtime":               "{49592c0f-5a05-516d-aa4b-a64e02026c89}",
+		"system.runtime":               "{49592c0f-5a05-516d-aa4b-a64e02026c89}",
+		"Spigot":                       "{de934bea-3d2f-5646-73dd-31f7ac8432ea}",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, providerGUID(name).String(), name)
+	}
+}
+
+func TestParseGUID(t *testing.T) {
+	for _, s := range []string{"{3ac73b97-af73-50e9-0822-5da4367920d0}", "3AC73B97-AF73-50E9-0822-5DA4367920D0"} {
+		g, err := parseGUID(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, providerGUID("Microsoft-Extensions-Logging"), g, s)
+	}
+	for _, s := range []string{"", "{3ac73b97af7350e908225da4367920d0}", "3ac73b97-af73-50e9-0822-5da4367920dz"} {
+		_, err := parseGUID(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	assert.Equal(t, []byte("\x09\x00Spigot\x00"), providerMetadata("Spigot"))
+	assert.Equal(t, []byte("\x13\x00\x00Event\x00message\x00\x82\x23"), eventMetadata("Event"))
+}
+
+// fakeProvider records the events written.
+type fakeProvider struct {
+	d      []descriptor
+	data   []string
+	err    error
+	closed bool
+}
+
+func (f *fakeProvider) write(d *descriptor, metadata, event, data []byte) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.d = append(f.d, *d)
+	f.data = append(f.data, string(data))
+	return nil
+}
+
+func (f *fakeProvider) close() error {
+	f.closed = true
+	return nil
+}
+
+func TestWrite(t *testing.T) {
+	f := &fakeProvider{}
+	o := &Output{provider: f, descriptor: descriptor{ID: 7, Channel: channelTraceLogging, Level: 4}}
+	n, err := o.Write([]byte("first event\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, 12, n)
+	_, err = o.Write([]byte("second\nevent"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"first event\x00", "second\nevent\x00"}, f.data)
+	assert.Equal(t, descriptor{ID: 7, Channel: channelTraceLogging, Level: 4}, f.d[0])
+
+	f.err = errors.New("arithmetic result exceeded 32 bits")
+	_, err = o.Write([]byte("too large"))
+	assert.EqualError(t, err, "writing ETW event of 9 bytes: arithmetic result exceeded 32 bits")
+
+	assert.Nil(t, o.Close())
+	assert.True(t, f.closed)
+}
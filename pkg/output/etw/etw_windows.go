@@ -0,0 +1,85 @@
+//go:build windows
+
+package etw
+
+import (
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	advapi32               = windows.NewLazySystemDLL("advapi32.dll")
+	procEventRegister      = advapi32.NewProc("EventRegister")
+	procEventUnregister    = advapi32.NewProc("EventUnregister")
+	procEventWriteTransfer = advapi32.NewProc("EventWriteTransfer")
+)
+
+// The types of the data descriptors of a TraceLogging event.
+const (
+	dataDescriptorUserData         = 0
+	dataDescriptorEventMetadata    = 1
+	dataDescriptorProviderMetadata = 2
+)
+
+// dataDescriptor is an EVENT_DATA_DESCRIPTOR, a part of the data of an
+// event.
+type dataDescriptor struct {
+	Ptr       uint64
+	Size      uint32
+	Type      uint8
+	Reserved1 uint8
+	Reserved2 uint16
+}
+
+// etwProvider is a provider registered with EventRegister.
+type etwProvider struct {
+	handle uint64
+}
+
+// newProvider registers the provider id.
+func newProvider(id guid) (provider, error) {
+	p := &etwProvider{}
+	g := windows.GUID(id)
+	r, _, _ := procEventRegister.Call(uintptr(unsafe.Pointer(&g)), 0, 0, uintptr(unsafe.Pointer(&p.handle)))
+	if r != 0 {
+		return nil, syscall.Errno(r)
+	}
+	return p, nil
+}
+
+func (p *etwProvider) write(d *descriptor, metadata, event, data []byte) error {
+	descriptors := [...]dataDescriptor{
+		{Ptr: uint64(uintptr(unsafe.Pointer(&metadata[0]))), Size: uint32(len(metadata)), Type: dataDescriptorProviderMetadata},
+		{Ptr: uint64(uintptr(unsafe.Pointer(&event[0]))), Size: uint32(len(event)), Type: dataDescriptorEventMetadata},
+		{Ptr: uint64(uintptr(unsafe.Pointer(&data[0]))), Size: uint32(len(data)), Type: dataDescriptorUserData},
+	}
+	args := append(handleArgs(p.handle), uintptr(unsafe.Pointer(d)), 0, 0, uintptr(len(descriptors)), uintptr(unsafe.Pointer(&descriptors[0])))
+	r, _, _ := procEventWriteTransfer.Call(args...)
+	runtime.KeepAlive(metadata)
+	runtime.KeepAlive(event)
+	runtime.KeepAlive(data)
+	if r != 0 {
+		return syscall.Errno(r)
+	}
+	return nil
+}
+
+func (p *etwProvider) close() error {
+	r, _, _ := procEventUnregister.Call(handleArgs(p.handle)...)
+	if r != 0 {
+		return syscall.Errno(r)
+	}
+	return nil
+}
+
+// handleArgs returns the arguments of the REGHANDLE h, a 64 bit value
+// that is passed as two arguments on 32 bit Windows.
+func handleArgs(h uint64) []uintptr {
+	if unsafe.Sizeof(uintptr(0)) == 8 {
+		return []uintptr{uintptr(h)}
+	}
+	return []uintptr{uintptr(h), uintptr(h >> 32)}
+}
@@ -0,0 +1,42 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Event returns the structured event p, a JSON object, for outputs
+// that write the fields of events instead of their text, such as
+// database outputs.  Numbers are json.Number, so integers keep their
+// precision.  name is the name of the output, for the error of events
+// that are not JSON objects.
+func Event(name string, p []byte) (map[string]interface{}, error) {
+	var event map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(&event); err != nil || event == nil {
+		return nil, fmt.Errorf("%s output expected a JSON object, use a json format of the generator", name)
+	}
+	return event, nil
+}
+
+// Field returns the value of the field name of the event, a top level
+// key or a dotted path of nested objects, and whether it is set.
+func Field(event map[string]interface{}, name string) (interface{}, bool) {
+	if v, ok := event[name]; ok {
+		return v, v != nil
+	}
+	var cur interface{} = event
+	for _, key := range strings.Split(name, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return cur, cur != nil
+}
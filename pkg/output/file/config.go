@@ -1,19 +1,37 @@
 package file
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leehinman/spigot/pkg/output"
+)
 
 type config struct {
-	Type      string `config:"type" validate:"required"`
-	Filename  string `config:"filename"`
-	Directory string `config:"directory"`
-	Pattern   string `config:"pattern"`
-	Delimiter string `config:"delimiter" validate:"required"`
+	Type         string        `config:"type" validate:"required"`
+	Filename     string        `config:"filename"`
+	Directory    string        `config:"directory"`
+	Pattern      string        `config:"pattern"`
+	Delimiter    string        `config:"delimiter" validate:"required"`
+	MaxSize      int           `config:"max_size"`
+	MaxAge       time.Duration `config:"max_age"`
+	Keep         int           `config:"keep"`
+	Compress     bool          `config:"compress"`
+	Rename       string        `config:"rename"`
+	CopyTruncate bool          `config:"copytruncate"`
+	Path         string        `config:"path"`
+	Missing      string        `config:"missing"`
+	MaxOpenFiles int           `config:"max_open_files"`
 }
 
 func defaultConfig() config {
 	return config{
-		Type:      "file",
-		Delimiter: "\n",
+		Type:         "file",
+		Delimiter:    "\n",
+		Rename:       "%{filename}.%{n}",
+		Missing:      "unknown",
+		MaxOpenFiles: 100,
 	}
 }
 
@@ -21,6 +39,9 @@ func (c *config) Validate() error {
 	if c.Type != Name {
 		return fmt.Errorf("%s is not a valid type for %s", c.Type, Name)
 	}
+	if c.Path != "" {
+		return c.validatePath()
+	}
 	if c.Filename != "" && (c.Directory != "" || c.Pattern != "") {
 		return fmt.Errorf("if filename is set, directory and pattern must not be")
 	}
@@ -30,5 +51,44 @@ func (c *config) Validate() error {
 	if c.Filename == "" && c.Directory == "" && c.Pattern == "" {
 		return fmt.Errorf("you must specify filename or directory and pattern")
 	}
+	if c.MaxSize < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'max_size' expected 0 or more", c.MaxSize)
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'max_age' expected 0 or more", c.MaxAge)
+	}
+	if (c.MaxSize > 0 || c.MaxAge > 0) && c.Filename == "" {
+		return fmt.Errorf("max_size and max_age can only be used with filename")
+	}
+	if c.Keep < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'keep' expected 0 or more", c.Keep)
+	}
+	if c.Rename == "" && (c.MaxSize > 0 || c.MaxAge > 0) {
+		return fmt.Errorf("'' is not a valid value for 'rename' expected a file name")
+	}
+	if _, err := output.ParseDatePattern("rename", c.Rename); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validatePath validates the options of a path, which writes to a tree
+// of files instead of filename or directory and pattern.
+func (c *config) validatePath() error {
+	if c.Filename != "" || c.Directory != "" || c.Pattern != "" {
+		return fmt.Errorf("if path is set, filename, directory and pattern must not be")
+	}
+	if c.MaxSize > 0 || c.MaxAge > 0 {
+		return fmt.Errorf("max_size and max_age can only be used with filename")
+	}
+	if _, err := parsePath(c.Path); err != nil {
+		return err
+	}
+	if c.Missing == "" || strings.ContainsAny(c.Missing, `/\`) || c.Missing == "." || c.Missing == ".." {
+		return fmt.Errorf("'%s' is not a valid value for 'missing' expected a file or directory name", c.Missing)
+	}
+	if c.MaxOpenFiles <= 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'max_open_files' expected a value greater than 0", c.MaxOpenFiles)
+	}
 	return nil
 }
@@ -2,6 +2,7 @@ package file
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -52,6 +53,61 @@ func TestConfigs(t *testing.T) {
 			hasError:    true,
 			errorString: "if filename is set, directory and pattern must not be",
 		},
+		"Valid Rotation": {
+			c:           config{Type: Name, Filename: "output.log", Delimiter: "\n", MaxSize: 1024, MaxAge: time.Hour, Keep: 3, Compress: true, Rename: "%{filename}-%{+yyyyMMdd}", CopyTruncate: true},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Max Size": {
+			c:           config{Type: Name, Filename: "output.log", MaxSize: -1},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'max_size' expected 0 or more",
+		},
+		"Rotation without filename": {
+			c:           config{Type: Name, Directory: "/var/tmp", Pattern: "output_*", MaxAge: time.Hour, Rename: "%{filename}.%{n}"},
+			hasError:    true,
+			errorString: "max_size and max_age can only be used with filename",
+		},
+		"Invalid Keep": {
+			c:           config{Type: Name, Filename: "output.log", Keep: -1},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'keep' expected 0 or more",
+		},
+		"No Rename": {
+			c:           config{Type: Name, Filename: "output.log", MaxSize: 1024},
+			hasError:    true,
+			errorString: "'' is not a valid value for 'rename' expected a file name",
+		},
+		"Invalid Rename": {
+			c:           config{Type: Name, Filename: "output.log", MaxSize: 1024, Rename: "%{filename}-%{+yyyyMMdd"},
+			hasError:    true,
+			errorString: "'%{filename}-%{+yyyyMMdd' is not a valid value for 'rename' expected a '}' after '%{+'",
+		},
+		"Valid Path": {
+			c:           config{Type: Name, Path: "/var/log/remote/%{host.name}/%{+yyyy-MM-dd}/%{process.name}.log", Delimiter: "\n", Missing: "unknown", MaxOpenFiles: 10},
+			hasError:    false,
+			errorString: "",
+		},
+		"Path and filename set": {
+			c:           config{Type: Name, Path: "/var/log/remote/%{host.name}.log", Filename: "output.log", Missing: "unknown", MaxOpenFiles: 10},
+			hasError:    true,
+			errorString: "if path is set, filename, directory and pattern must not be",
+		},
+		"Invalid Path": {
+			c:           config{Type: Name, Path: "/var/log/remote/%{host.name/app.log", Missing: "unknown", MaxOpenFiles: 10},
+			hasError:    true,
+			errorString: "'/var/log/remote/%{host.name/app.log' is not a valid value for 'path' expected a '}' after '%{'",
+		},
+		"Invalid Missing": {
+			c:           config{Type: Name, Path: "/var/log/remote/%{host.name}.log", Missing: "../x", MaxOpenFiles: 10},
+			hasError:    true,
+			errorString: "'../x' is not a valid value for 'missing' expected a file or directory name",
+		},
+		"Invalid Max Open Files": {
+			c:           config{Type: Name, Path: "/var/log/remote/%{host.name}.log", Missing: "unknown"},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'max_open_files' expected a value greater than 0",
+		},
 		"Only type set": {
 			c:           config{Type: Name},
 			hasError:    true,
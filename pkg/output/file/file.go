@@ -18,11 +18,57 @@
 //	  delimiter: "\r\n"
 //
 // directory and pattern are used in os.CreateTemp call
+//
+// A filename is rotated once it is of max_size bytes, or max_age old,
+// to test the handling of rotations by log shippers.  By default the
+// file is renamed to "<filename>.1", moving earlier rotations up by one,
+// and a new file is created.  rename is the name of the rotated files,
+// of the %{filename}, the number %{n} of the rotation and date formats
+// such as %{+yyyyMMdd-HHmmss} of the time of the rotation in UTC.
+// Without %{n} the files are not numbered but dated.  keep is the number
+// of rotated files kept, all of them by default.  With compress the
+// rotated files are compressed with gzip and end in ".gz".  With
+// copytruncate the file is copied and then truncated instead of renamed,
+// and written in append mode.
+//
+//	output:
+//	  type: file
+//	  filename: "/var/log/spigot/app.log"
+//	  max_size: 10485760
+//	  max_age: 1h
+//	  rename: "%{filename}-%{+yyyyMMdd-HHmmss}"
+//	  keep: 5
+//	  compress: true
+//	  copytruncate: false
+//
+// With path the structured events, JSON objects such as those of
+// generators with a json format, are written to a tree of files like
+// the dynafiles of rsyslog, to emulate a central log server with a
+// directory per host.  path is the name of the file of each event, of
+// the values of its fields %{name}, a top level key or a dotted path,
+// and of date formats such as %{+yyyy-MM-dd} of the current time in
+// UTC.  A value that is not set is missing, "unknown" by default, and
+// path separators in values are replaced with "_".  The files and
+// their directories are created as needed and appended to, with at most
+// max_open_files, 100 by default, open at any time.
+//
+//	output:
+//	  type: file
+//	  path: "/var/log/remote/%{host.name}/%{process.name}.log"
+//	  max_open_files: 500
+//
+// Multi-line log entries are written as they are, followed by the
+// delimiter.  Binary log entries are written prefixed with their
+// length instead, see output.WriteLength.
 package file
 
 import (
+	"container/list"
+	"fmt"
 	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/elastic/go-ucfg"
 	"github.com/leehinman/spigot/pkg/output"
@@ -39,6 +85,8 @@ type Output struct {
 	pWriteCloser io.WriteCloser
 	directory    string
 	pattern      string
+	rotation     *rotation
+	tree         *tree
 }
 
 func init() {
@@ -61,8 +109,24 @@ func New(cfg *ucfg.Config) (output.Output, error) {
 			return nil, err
 		}
 	}
+	if c.Path != "" {
+		// Validate checked the path.
+		path, _ := parsePath(c.Path)
+		return &Output{
+			delimiter: c.Delimiter,
+			tree: &tree{
+				path:      path,
+				missing:   c.Missing,
+				delimiter: c.Delimiter,
+				maxOpen:   c.MaxOpenFiles,
+				files:     make(map[string]*list.Element),
+				lru:       list.New(),
+				now:       time.Now,
+			},
+		}, nil
+	}
 	if c.Filename != "" {
-		pOsFile, err = os.Create(c.Filename)
+		pOsFile, err = create(c.Filename, c.CopyTruncate)
 		if err != nil {
 			return nil, err
 		}
@@ -73,22 +137,73 @@ func New(cfg *ucfg.Config) (output.Output, error) {
 		directory:    c.Directory,
 		pattern:      c.Pattern,
 	}
+	if c.MaxSize > 0 || c.MaxAge > 0 {
+		// Validate checked the rename pattern.
+		rename, _ := output.ParseDatePattern("rename", c.Rename)
+		out.rotation = &rotation{
+			filename:     c.Filename,
+			maxSize:      c.MaxSize,
+			maxAge:       c.MaxAge,
+			keep:         c.Keep,
+			compress:     c.Compress,
+			rename:       rename,
+			numbered:     strings.Contains(c.Rename, "%{n}"),
+			copytruncate: c.CopyTruncate,
+			opened:       time.Now(),
+			now:          time.Now,
+		}
+	}
 	return &out, nil
 }
 
 // Write writes the log entry to the file handle that is opened with
 // new and appends the delimiter.
 func (o *Output) Write(b []byte) (n int, err error) {
-	j, err := o.pWriteCloser.Write(b)
-	if err != nil {
-		return j, err
+	if o.tree != nil {
+		return o.tree.write(b)
+	}
+	return o.write(func(w io.Writer) (int, error) {
+		j, err := w.Write(b)
+		if err != nil {
+			return j, err
+		}
+		k, err := w.Write([]byte(o.delimiter))
+		return j + k, err
+	})
+}
+
+// WriteBinary writes the binary log entry prefixed with its length,
+// without the delimiter.
+func (o *Output) WriteBinary(b []byte) (n int, err error) {
+	if o.tree != nil {
+		return 0, fmt.Errorf("binary log entries can not be written to the files of path")
 	}
-	k, err := o.pWriteCloser.Write([]byte(o.delimiter))
-	return j + k, err
+	return o.write(func(w io.Writer) (int, error) {
+		return output.WriteLength(w, b)
+	})
 }
 
-// Close closes the io.WriteCloser.  Writes after this will fail.
+// write writes with write to the file, rotating it first if it is due.
+func (o *Output) write(write func(io.Writer) (int, error)) (int, error) {
+	r := o.rotation
+	if r == nil {
+		return write(o.pWriteCloser)
+	}
+	if now := r.now(); r.due(now) {
+		if err := o.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+	n, err := write(o.pWriteCloser)
+	r.size += n
+	return n, err
+}
+
+// Close closes the io.WriteCloser, or the files of path.  Writes after this will fail.
 func (o *Output) Close() error {
+	if o.tree != nil {
+		return o.tree.close()
+	}
 	return o.pWriteCloser.Close()
 }
 
@@ -2,9 +2,14 @@ package file
 
 import (
 	"bytes"
+	"compress/gzip"
 	"io"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/elastic/go-ucfg"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -58,3 +63,133 @@ func TestWrite(t *testing.T) {
 		assert.Equal(t, []byte(tc.want), buf.Bytes(), name)
 	}
 }
+
+func TestWriteBinary(t *testing.T) {
+	var buf bytes.Buffer
+	f := &Output{
+		delimiter:    "\n",
+		pWriteCloser: &myWriteCloser{&buf},
+	}
+	for _, b := range []string{"\x00\n\xff", ""} {
+		_, err := f.WriteBinary([]byte(b))
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, []byte("\x00\x00\x00\x03\x00\n\xff\x00\x00\x00\x00"), buf.Bytes())
+}
+
+func newRotatingOutput(t *testing.T, c map[string]interface{}) (*Output, *time.Time) {
+	cfg, err := ucfg.NewFrom(c)
+	assert.Nil(t, err)
+	out, err := New(cfg)
+	assert.Nil(t, err)
+	o := out.(*Output)
+	now := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	o.rotation.opened = now
+	o.rotation.now = func() time.Time { return now }
+	return o, &now
+}
+
+func readFile(t *testing.T, name string) string {
+	b, err := os.ReadFile(name)
+	assert.Nil(t, err, name)
+	if filepath.Ext(name) == ".gz" {
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		assert.Nil(t, err, name)
+		b, err = io.ReadAll(zr)
+		assert.Nil(t, err, name)
+	}
+	return string(b)
+}
+
+func TestRotateNumbered(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	o, _ := newRotatingOutput(t, map[string]interface{}{"type": Name, "filename": filename, "max_size": 8, "keep": 2, "compress": true})
+
+	for _, line := range []string{"one", "two", "three", "four", "five", "six", "seven"} {
+		_, err := o.Write([]byte(line))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, o.Close())
+
+	assert.Equal(t, "seven\n", readFile(t, filename))
+	assert.Equal(t, "five\nsix\n", readFile(t, filename+".1.gz"))
+	assert.Equal(t, "three\nfour\n", readFile(t, filename+".2.gz"))
+	assert.NoFileExists(t, filename+".3.gz")
+	assert.NoFileExists(t, filename+".1")
+}
+
+func TestRotateDated(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	o, now := newRotatingOutput(t, map[string]interface{}{"type": Name, "filename": filename, "max_age": "1h", "rename": "%{filename}-%{+yyyyMMddHH}", "keep": 2})
+
+	for _, line := range []string{"one", "two", "three", "four"} {
+		_, err := o.Write([]byte(line))
+		assert.Nil(t, err)
+		*now = now.Add(time.Hour)
+	}
+	assert.Nil(t, o.Close())
+
+	assert.Equal(t, "four\n", readFile(t, filename))
+	assert.NoFileExists(t, filename+"-2024030211")
+	assert.Equal(t, "two\n", readFile(t, filename+"-2024030212"))
+	assert.Equal(t, "three\n", readFile(t, filename+"-2024030213"))
+}
+
+func TestCopyTruncate(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	o, _ := newRotatingOutput(t, map[string]interface{}{"type": Name, "filename": filename, "max_size": 4, "copytruncate": true})
+	before, err := os.Stat(filename)
+	assert.Nil(t, err)
+
+	for _, line := range []string{"one", "two", "three"} {
+		_, err := o.Write([]byte(line))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, o.Close())
+
+	after, err := os.Stat(filename)
+	assert.Nil(t, err)
+	assert.True(t, os.SameFile(before, after))
+	assert.Equal(t, "three\n", readFile(t, filename))
+	assert.Equal(t, "two\n", readFile(t, filename+".1"))
+	assert.Equal(t, "one\n", readFile(t, filename+".2"))
+}
+
+func TestPath(t *testing.T) {
+	dir := t.TempDir()
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "path": dir + "/%{host.name}/%{+yyyy-MM-dd}/%{app}.log", "max_open_files": 2})
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+	o := out.(*Output)
+	o.tree.now = func() time.Time { return time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC) }
+
+	for _, e := range []string{
+		`{"host":{"name":"web-1"},"app":"nginx","message":"one"}`,
+		`{"host":{"name":"web-2"},"app":"nginx","message":"two"}`,
+		`{"host":{"name":"db-1"},"app":"postgres","message":"three"}`,
+		`{"host":{"name":"web-1"},"app":"nginx","message":"four"}`,
+		`{"host":{"name":"../etc"},"message":"five"}`,
+	} {
+		_, err := out.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	assert.Len(t, o.tree.files, 2)
+	assert.Nil(t, out.Close())
+
+	assert.Equal(t, `{"host":{"name":"web-1"},"app":"nginx","message":"one"}
+{"host":{"name":"web-1"},"app":"nginx","message":"four"}
+`, readFile(t, filepath.Join(dir, "web-1/2024-03-02/nginx.log")))
+	assert.Equal(t, `{"host":{"name":"web-2"},"app":"nginx","message":"two"}
+`, readFile(t, filepath.Join(dir, "web-2/2024-03-02/nginx.log")))
+	assert.Equal(t, `{"host":{"name":"db-1"},"app":"postgres","message":"three"}
+`, readFile(t, filepath.Join(dir, "db-1/2024-03-02/postgres.log")))
+	assert.Equal(t, `{"host":{"name":"../etc"},"message":"five"}
+`, readFile(t, filepath.Join(dir, ".._etc/2024-03-02/unknown.log")))
+
+	_, err = out.Write([]byte("not json"))
+	assert.EqualError(t, err, "file output expected a JSON object, use a json format of the generator")
+	_, err = o.WriteBinary([]byte{0x00})
+	assert.EqualError(t, err, "binary log entries can not be written to the files of path")
+}
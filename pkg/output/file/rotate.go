@@ -0,0 +1,174 @@
+package file
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// rotation rotates the file of filename like logrotate does, when it is
+// of max_size bytes or max_age old.
+type rotation struct {
+	filename     string
+	maxSize      int
+	maxAge       time.Duration
+	keep         int
+	compress     bool
+	rename       output.DatePattern
+	numbered     bool
+	copytruncate bool
+	size         int
+	opened       time.Time
+	rotated      []string
+	now          func() time.Time
+}
+
+// due returns whether the file is due to be rotated, before writing to
+// it at now.
+func (r *rotation) due(now time.Time) bool {
+	return (r.maxSize > 0 && r.size >= r.maxSize) || (r.maxAge > 0 && now.Sub(r.opened) >= r.maxAge)
+}
+
+// name returns the name of the rotated file n, or of the file rotated
+// at t for patterns without %{n}.
+func (r *rotation) name(t time.Time, n int) string {
+	name := strings.ReplaceAll(r.rename.Format(t), "%{filename}", r.filename)
+	name = strings.ReplaceAll(name, "%{n}", strconv.Itoa(n))
+	if r.compress {
+		name += ".gz"
+	}
+	return name
+}
+
+// next returns the name of the file rotated at t, moving the numbered
+// files up by one and removing those beyond keep.
+func (r *rotation) next(t time.Time) (string, error) {
+	if !r.numbered {
+		name := r.name(t, 0)
+		for i := 1; exists(name); i++ {
+			name = r.name(t, 0) + "." + strconv.Itoa(i)
+		}
+		return name, nil
+	}
+	n := 1
+	for exists(r.name(t, n)) {
+		n++
+	}
+	if r.keep > 0 && n > r.keep {
+		n = r.keep
+		if err := os.Remove(r.name(t, n)); err != nil {
+			return "", err
+		}
+	}
+	for i := n; i > 1; i-- {
+		if err := os.Rename(r.name(t, i-1), r.name(t, i)); err != nil {
+			return "", err
+		}
+	}
+	return r.name(t, 1), nil
+}
+
+// rotate rotates the file of o, renamed or with copytruncate copied
+// and truncated.
+func (o *Output) rotate(now time.Time) error {
+	r := o.rotation
+	dest, err := r.next(now)
+	if err != nil {
+		return err
+	}
+	if r.copytruncate {
+		// the file is open in append mode, so the writes after the
+		// truncation are at its start
+		if err := copyFile(r.filename, dest, r.compress); err != nil {
+			return err
+		}
+		if err := os.Truncate(r.filename, 0); err != nil {
+			return err
+		}
+	} else {
+		if err := o.pWriteCloser.Close(); err != nil {
+			return err
+		}
+		// compressed files are renamed first, so the file is always
+		// a new one after the rotation
+		renamed := strings.TrimSuffix(dest, ".gz")
+		if err := os.Rename(r.filename, renamed); err != nil {
+			return err
+		}
+		f, err := create(r.filename, false)
+		if err != nil {
+			return err
+		}
+		o.pWriteCloser = f
+		if r.compress {
+			if err := copyFile(renamed, dest, true); err != nil {
+				return err
+			}
+			if err := os.Remove(renamed); err != nil {
+				return err
+			}
+		}
+	}
+	r.size = 0
+	r.opened = now
+	if !r.numbered && r.keep > 0 {
+		r.rotated = append(r.rotated, dest)
+		for len(r.rotated) > r.keep {
+			if err := os.Remove(r.rotated[0]); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			r.rotated = r.rotated[1:]
+		}
+	}
+	return nil
+}
+
+// copyFile copies the file src to dest, compressed with gzip if
+// compress is set.
+func copyFile(src, dest string, compress bool) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	var w io.WriteCloser = out
+	if compress {
+		w = gzip.NewWriter(out)
+	}
+	if _, err := io.Copy(w, in); err != nil {
+		out.Close()
+		return fmt.Errorf("copying %s to %s: %w", src, dest, err)
+	}
+	if compress {
+		if err := w.Close(); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	return out.Close()
+}
+
+// create creates the file name, truncated, in append mode if
+// appendMode is set.
+func create(name string, appendMode bool) (*os.File, error) {
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appendMode {
+		flag |= os.O_APPEND
+	}
+	return os.OpenFile(name, flag, 0o666)
+}
+
+func exists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
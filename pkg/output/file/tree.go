@@ -0,0 +1,166 @@
+package file
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// pathEscaper replaces the path separators in the values of fields, so
+// an event can not write outside of its directory.
+var pathEscaper = strings.NewReplacer("/", "_", `\`, "_")
+
+// tree writes the events to the files of their path, like the dynafile
+// templates of rsyslog, with at most maxOpen files open.
+type tree struct {
+	path      []pathPart
+	missing   string
+	delimiter string
+	maxOpen   int
+	files     map[string]*list.Element
+	lru       *list.List
+	now       func() time.Time
+}
+
+// pathPart is a literal part of a path, a field of the events or a date
+// format.
+type pathPart struct {
+	literal string
+	field   string
+	date    output.DatePattern
+}
+
+// openFile is a file of the tree, in the lru list.
+type openFile struct {
+	name string
+	f    *os.File
+}
+
+// parsePath parses the path of the events, with fields %{name} and date
+// formats such as %{+yyyy-MM-dd}.
+func parsePath(s string) ([]pathPart, error) {
+	var parts []pathPart
+	orig := s
+	for s != "" {
+		start := strings.Index(s, "%{")
+		if start < 0 {
+			parts = append(parts, pathPart{literal: s})
+			break
+		}
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			return nil, fmt.Errorf("'%s' is not a valid value for 'path' expected a '}' after '%%{'", orig)
+		}
+		if start > 0 {
+			parts = append(parts, pathPart{literal: s[:start]})
+		}
+		name := s[start+2 : start+end]
+		switch {
+		case strings.HasPrefix(name, "+"):
+			date, err := output.ParseDatePattern("path", s[start:start+end+1])
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, pathPart{date: date})
+		case name == "":
+			return nil, fmt.Errorf("'%s' is not a valid value for 'path' expected a field name in '%%{}'", orig)
+		default:
+			parts = append(parts, pathPart{field: name})
+		}
+		s = s[start+end+1:]
+	}
+	return parts, nil
+}
+
+// name returns the name of the file of the event.
+func (t *tree) name(event map[string]interface{}, now time.Time) string {
+	var b strings.Builder
+	for _, p := range t.path {
+		switch {
+		case p.date != nil:
+			b.WriteString(p.date.Format(now))
+		case p.field != "":
+			b.WriteString(t.value(event, p.field))
+		default:
+			b.WriteString(p.literal)
+		}
+	}
+	return filepath.Clean(b.String())
+}
+
+// value returns the value of the field of the event in a path, or
+// missing if it is not set.
+func (t *tree) value(event map[string]interface{}, field string) string {
+	v, ok := output.Field(event, field)
+	if !ok {
+		return t.missing
+	}
+	s := pathEscaper.Replace(fmt.Sprint(v))
+	if s == "" || s == "." || s == ".." {
+		return t.missing
+	}
+	return s
+}
+
+// write writes the event to its file followed by the delimiter.
+func (t *tree) write(b []byte) (int, error) {
+	event, err := output.Event(Name, b)
+	if err != nil {
+		return 0, err
+	}
+	f, err := t.open(t.name(event, t.now()))
+	if err != nil {
+		return 0, err
+	}
+	j, err := f.Write(b)
+	if err != nil {
+		return j, err
+	}
+	k, err := f.Write([]byte(t.delimiter))
+	return j + k, err
+}
+
+// open returns the open file name, opening it in append mode and its
+// directory if needed, and closing the least recently used file if
+// there are maxOpen files open.
+func (t *tree) open(name string) (*os.File, error) {
+	if e, ok := t.files[name]; ok {
+		t.lru.MoveToFront(e)
+		return e.Value.(*openFile).f, nil
+	}
+	if t.lru.Len() >= t.maxOpen {
+		e := t.lru.Back()
+		of := t.lru.Remove(e).(*openFile)
+		delete(t.files, of.name)
+		if err := of.f.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o666)
+	if err != nil {
+		return nil, err
+	}
+	t.files[name] = t.lru.PushFront(&openFile{name: name, f: f})
+	return f, nil
+}
+
+// close closes the open files.
+func (t *tree) close() error {
+	var err error
+	for e := t.lru.Front(); e != nil; e = e.Next() {
+		if cerr := e.Value.(*openFile).f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	t.files = make(map[string]*list.Element)
+	t.lru.Init()
+	return err
+}
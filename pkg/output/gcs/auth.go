@@ -0,0 +1,147 @@
+package gcs
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// The OAuth 2.0 scopes of the uploads and of the notifications.
+const (
+	storageScope = "https://www.googleapis.com/auth/devstorage.read_write"
+	pubsubScope  = "https://www.googleapis.com/auth/pubsub"
+)
+
+// refreshBefore is how long before it expires a token is refreshed.
+const refreshBefore = 5 * time.Minute
+
+// tokenLifetime is the lifetime of the assertions of the service
+// account, the longest Google accepts.
+const tokenLifetime = time.Hour
+
+// serviceAccount is the JSON key file of a service account.
+type serviceAccount struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// tokenSource returns the bearer token of the requests, the token of
+// the config or a token of the service account, with the JWT bearer
+// grant of RFC 7523.
+type tokenSource struct {
+	client  *http.Client
+	token   string
+	account serviceAccount
+	scope   string
+	key     *rsa.PrivateKey
+	expires time.Time
+}
+
+func newTokenSource(client *http.Client, c config) (*tokenSource, error) {
+	if c.CredentialsFile == "" {
+		return &tokenSource{token: c.Token}, nil
+	}
+	b, err := os.ReadFile(c.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	s := &tokenSource{client: client, scope: storageScope}
+	if c.Notify.Topic != "" {
+		s.scope += " " + pubsubScope
+	}
+	if err := json.Unmarshal(b, &s.account); err != nil {
+		return nil, fmt.Errorf("credentials file %s: %w", c.CredentialsFile, err)
+	}
+	if s.account.Type != "service_account" || s.account.ClientEmail == "" || s.account.TokenURI == "" {
+		return nil, fmt.Errorf("credentials file %s is not the key of a service account", c.CredentialsFile)
+	}
+	block, _ := pem.Decode([]byte(s.account.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("credentials file %s has no private key", c.CredentialsFile)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("credentials file %s: %w", c.CredentialsFile, err)
+	}
+	var ok bool
+	if s.key, ok = key.(*rsa.PrivateKey); !ok {
+		return nil, fmt.Errorf("credentials file %s has no RSA private key", c.CredentialsFile)
+	}
+	return s, nil
+}
+
+// get returns the token, and requests a new token of the service
+// account when it is about to expire.  It returns an empty token
+// without credentials.
+func (s *tokenSource) get() (string, error) {
+	if s.key == nil || time.Now().Add(refreshBefore).Before(s.expires) {
+		return s.token, nil
+	}
+	assertion, err := s.assertion(time.Now())
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	resp, err := s.client.PostForm(s.account.TokenURI, form)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("google token response is not valid: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return "", fmt.Errorf("POST %s returned %s: %s", s.account.TokenURI, resp.Status, token.ErrorDescription)
+	}
+	s.token = token.AccessToken
+	s.expires = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return s.token, nil
+}
+
+// assertion returns the JWT of the service account issued at t, signed
+// with RS256.
+func (s *tokenSource) assertion(t time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": s.account.PrivateKeyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   s.account.ClientEmail,
+		"scope": s.scope,
+		"aud":   s.account.TokenURI,
+		"iat":   t.Unix(),
+		"exp":   t.Add(tokenLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + enc.EncodeToString(sig), nil
+}
@@ -0,0 +1,90 @@
+package gcs
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+type config struct {
+	Type            string                `config:"type" validate:"required"`
+	Bucket          string                `config:"bucket" validate:"required"`
+	Prefix          string                `config:"prefix" validate:"required"`
+	Suffix          string                `config:"suffix"`
+	Delimiter       string                `config:"delimiter"`
+	Compression     string                `config:"compression"`
+	MaxSize         int                   `config:"max_size"`
+	MaxAge          time.Duration         `config:"max_age"`
+	CredentialsFile string                `config:"credentials_file"`
+	Token           string                `config:"token"`
+	URL             string                `config:"url"`
+	Timeout         time.Duration         `config:"timeout"`
+	Notify          notifyConfig          `config:"notify"`
+	Delivery        output.DeliveryConfig `config:"delivery"`
+}
+
+// notifyConfig is the Pub/Sub topic of the notifications.
+type notifyConfig struct {
+	Topic string `config:"topic"`
+	URL   string `config:"url"`
+}
+
+// topicPattern matches the names of Pub/Sub topics.
+var topicPattern = regexp.MustCompile(`^projects/[^/]+/topics/[^/]+$`)
+
+// Compressions of the objects.
+const (
+	CompressionGzip = "gzip"
+	CompressionNone = "none"
+)
+
+func defaultConfig() config {
+	return config{
+		Type:        Name,
+		Delimiter:   "\n",
+		Compression: CompressionGzip,
+		URL:         "https://storage.googleapis.com",
+		Timeout:     30 * time.Second,
+		Notify: notifyConfig{
+			URL: "https://pubsub.googleapis.com",
+		},
+		Delivery: output.DefaultDeliveryConfig(),
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if _, err := output.ParseDatePattern("prefix", c.Prefix); err != nil {
+		return err
+	}
+	if c.Compression != CompressionGzip && c.Compression != CompressionNone {
+		return fmt.Errorf("'%s' is not a valid value for 'compression' expected '%s' or '%s'", c.Compression, CompressionGzip, CompressionNone)
+	}
+	if c.MaxSize < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'max_size' expected 0 or more", c.MaxSize)
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'max_age' expected 0 or more", c.MaxAge)
+	}
+	if c.CredentialsFile != "" && c.Token != "" {
+		return fmt.Errorf("'credentials_file' and 'token' can not be used together")
+	}
+	if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("'%s' is not a valid value for 'url' expected an http or https URL", c.URL)
+	}
+	if t := c.Notify.Topic; t != "" && !topicPattern.MatchString(t) {
+		return fmt.Errorf("'%s' is not a valid value for 'notify.topic' expected projects/<project>/topics/<topic>", t)
+	}
+	if u, err := url.Parse(c.Notify.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("'%s' is not a valid value for 'notify.url' expected an http or https URL", c.Notify.URL)
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'timeout' expected a positive duration", c.Timeout)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package gcs
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "prefix": "test"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Options": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "prefix": "logs/%{+yyyy/MM/dd}/test", "suffix": ".json", "compression": "none", "max_size": 1048576, "max_age": "5m", "token": "ya29.a0", "url": "http://localhost:4443", "timeout": "5s"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Delivery": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "prefix": "test", "delivery": map[string]interface{}{"overwrite": -1}},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'overwrite' expected a percentage from 0 to 100 accessing 'delivery'",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "bucket": "test", "prefix": "test"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'gcs' accessing config",
+		},
+		"Invalid Prefix": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "prefix": "logs/%{+yyyy/MM/dd"},
+			hasError:    true,
+			errorString: "'logs/%{+yyyy/MM/dd' is not a valid value for 'prefix' expected a '}' after '%{+' accessing config",
+		},
+		"Invalid Compression": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "prefix": "test", "compression": "zstd"},
+			hasError:    true,
+			errorString: "'zstd' is not a valid value for 'compression' expected 'gzip' or 'none' accessing config",
+		},
+		"Invalid Max Age": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "prefix": "test", "max_age": "-1s"},
+			hasError:    true,
+			errorString: "'-1s' is not a valid value for 'max_age' expected 0 or more accessing config",
+		},
+		"Credentials And Token": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "prefix": "test", "credentials_file": "key.json", "token": "ya29.a0"},
+			hasError:    true,
+			errorString: "'credentials_file' and 'token' can not be used together accessing config",
+		},
+		"Invalid URL": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "prefix": "test", "url": "localhost:4443"},
+			hasError:    true,
+			errorString: "'localhost:4443' is not a valid value for 'url' expected an http or https URL accessing config",
+		},
+		"Valid Notify": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "prefix": "test", "notify": map[string]interface{}{"topic": "projects/test/topics/logs"}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Notify Topic": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "prefix": "test", "notify": map[string]interface{}{"topic": "logs"}},
+			hasError:    true,
+			errorString: "'logs' is not a valid value for 'notify.topic' expected projects/<project>/topics/<topic> accessing config",
+		},
+		"No Bucket": {
+			c:           map[string]interface{}{"type": Name, "prefix": "test"},
+			hasError:    true,
+			errorString: "string value is not set accessing 'bucket'",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// Package gcs implements the output of logs to a Google Cloud Storage
+// bucket, the equivalent of the s3 output.
+//
+// For configuration, "type", "bucket" and "prefix" are required.
+//
+//	output:
+//	  type: gcs
+//	  bucket: "logs"
+//	  prefix: "firewall/%{+yyyy/MM/dd}/fw"  ;; firewall/2024/03/02/fw_0123456789_001.gz
+//	  suffix: ".log"
+//	  credentials_file: "/etc/spigot/service-account.json"
+//	  max_size: 1048576
+//	  max_age: 5m
+//
+// The events, separated by "delimiter", by default "\n", are buffered
+// into an object, which is uploaded with each new interval and on
+// close.  "max_size" uploads the object once the events in it are of
+// at least that many bytes before compression, and "max_age" once its
+// first event is that old.  Both are unlimited by default.
+//
+// "prefix" may contain date formats such as %{+yyyy/MM/dd}, of the time
+// of the first event of the object in UTC, for date partitioned names.
+// "suffix" is appended to the name, before the ".gz" of "compression"
+// gzip, the default; with "none" the objects are not compressed.
+//
+// "credentials_file" is the JSON key file of a service account with
+// rights to create objects in the bucket, or "token" is an OAuth 2.0
+// access token.  Without either the requests are not authenticated,
+// for emulators such as fake-gcs-server with a "url" of their own.
+// url is https://storage.googleapis.com by default, and "timeout" is
+// the timeout of each request, by default 30s.
+//
+// "notify" publishes the Pub/Sub notification of each new object, an
+// OBJECT_FINALIZE message with the object resource in the JSON_API_V1
+// payload format, to "topic", like a bucket with a notification
+// configuration.  The url of Pub/Sub is https://pubsub.googleapis.com
+// by default, or that of an emulator.
+//
+//	notify:
+//	  topic: "projects/my-project/topics/logs"
+//
+// "delivery" simulates the write patterns that break pollers listing
+// the bucket, with the "late", "late_by", "overwrite" and "empty"
+// options of the s3 output.  Late objects are notified when they are
+// uploaded.
+//
+//	delivery:
+//	  late: 10
+//	  late_by: 15m
+package gcs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "gcs"
+
+// Output holds the object being buffered.
+type Output struct {
+	url       string
+	client    *http.Client
+	tokens    *tokenSource
+	bucket    string
+	topicURL  string
+	delivery  *output.Delivery
+	delimiter string
+	prefix    output.DatePattern
+	suffix    string
+	compress  bool
+	maxSize   int
+	maxAge    time.Duration
+	name      string
+	created   time.Time
+	size      int
+	buf       *bytes.Buffer
+	w         io.Writer
+	gw        *gzip.Writer
+	now       func() time.Time
+}
+
+func init() {
+	output.Register(Name, New)
+}
+
+// New is the factory for creating a new gcs Output.
+func New(cfg *ucfg.Config) (output.Output, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: c.Timeout}
+	tokens, err := newTokenSource(client, c)
+	if err != nil {
+		return nil, err
+	}
+	// Validate checked the prefix.
+	prefix, _ := output.ParseDatePattern("prefix", c.Prefix)
+
+	o := &Output{
+		url:       strings.TrimSuffix(c.URL, "/") + "/upload/storage/v1/b/" + url.PathEscape(c.Bucket) + "/o",
+		client:    client,
+		tokens:    tokens,
+		bucket:    c.Bucket,
+		delimiter: c.Delimiter,
+		prefix:    prefix,
+		suffix:    c.Suffix,
+		compress:  c.Compression == CompressionGzip,
+		maxSize:   c.MaxSize,
+		maxAge:    c.MaxAge,
+		buf:       &bytes.Buffer{},
+		now:       time.Now,
+	}
+	if c.Notify.Topic != "" {
+		o.topicURL = strings.TrimSuffix(c.Notify.URL, "/") + "/v1/" + c.Notify.Topic + ":publish"
+	}
+	o.delivery = output.NewDelivery(c.Delivery, o.put, o.objectName, func() time.Time { return o.now() })
+	return o, nil
+}
+
+// Write writes the event to the buffered object, and uploads the
+// object when it reaches max_size or max_age.
+func (o *Output) Write(b []byte) (int, error) {
+	now := o.now()
+	if o.name != "" && o.maxAge > 0 && now.Sub(o.created) >= o.maxAge {
+		if err := o.flush(); err != nil {
+			return 0, err
+		}
+	}
+	if o.name == "" {
+		o.start(now)
+	}
+	j, err := o.w.Write(b)
+	if err != nil {
+		return j, err
+	}
+	k, err := o.w.Write([]byte(o.delimiter))
+	if err != nil {
+		return j + k, err
+	}
+	o.size += j + k
+	if o.maxSize > 0 && o.size >= o.maxSize {
+		if err := o.flush(); err != nil {
+			return j + k, err
+		}
+	}
+	return j + k, nil
+}
+
+// start starts a new object at time t.
+func (o *Output) start(t time.Time) {
+	o.name = o.objectName(t)
+	o.created = t
+	o.size = 0
+	o.buf.Reset()
+	o.w = o.buf
+	if o.compress {
+		o.gw = gzip.NewWriter(o.buf)
+		o.w = o.gw
+	}
+}
+
+// objectName returns the name of an object started at time t.
+func (o *Output) objectName(t time.Time) string {
+	name := fmt.Sprintf("%s_%19d_%03d%s", o.prefix.Format(t), t.UnixNano(), rand.IntN(1000), o.suffix)
+	if o.compress {
+		name += ".gz"
+	}
+	return name
+}
+
+// flush delivers the object, if there are events in it, and the late
+// objects that are due.
+func (o *Output) flush() error {
+	if o.name == "" {
+		return o.delivery.Poll()
+	}
+	if o.compress {
+		if err := o.gw.Close(); err != nil {
+			return err
+		}
+	}
+	name := o.name
+	o.name = ""
+	return o.delivery.Deliver(name, o.buf.Bytes())
+}
+
+// put uploads body to the object name, and publishes its notification.
+func (o *Output) put(name string, body []byte) error {
+	object, err := o.upload(name, body)
+	if err != nil || o.topicURL == "" {
+		return err
+	}
+	return o.notify(name, len(body), object)
+}
+
+// upload creates the object name with a simple media upload, and
+// returns the object resource of the response.
+func (o *Output) upload(name string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, o.url+"?uploadType=media&name="+url.QueryEscape(name), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	if o.compress {
+		req.Header.Set("Content-Type", "application/gzip")
+	}
+	return o.do(req)
+}
+
+// do sends req, with the bearer token of the output, and returns the
+// body of the response.
+func (o *Output) do(req *http.Request) ([]byte, error) {
+	token, err := o.tokens.get()
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s returned %s: %s", req.Method, req.URL, resp.Status, bytes.TrimSpace(msg))
+	}
+	return msg, nil
+}
+
+// Close uploads the buffered events and the late objects, and closes
+// any idle connections.
+func (o *Output) Close() error {
+	err := o.flush()
+	if err == nil {
+		err = o.delivery.Flush()
+	}
+	o.client.CloseIdleConnections()
+	return err
+}
+
+// NewInterval uploads the buffered events, so each interval is an
+// object of its own.
+func (o *Output) NewInterval() error {
+	return o.flush()
+}
@@ -0,0 +1,176 @@
+package gcs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+type object struct {
+	name        string
+	contentType string
+	auth        string
+	body        []byte
+}
+
+// newServer returns a server of the upload and token endpoints, and
+// the objects uploaded to the bucket "logs".
+func newServer(t *testing.T, key *rsa.PrivateKey) (*httptest.Server, *[]object) {
+	var objects []object
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			assert.Nil(t, r.ParseForm())
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.PostForm.Get("grant_type"))
+			parts := strings.Split(r.PostForm.Get("assertion"), ".")
+			assert.Len(t, parts, 3)
+			sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+			assert.Nil(t, err)
+			sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			assert.Nil(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, sum[:], sig))
+			claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+			assert.Nil(t, err)
+			assert.Contains(t, string(claims), `"iss":"spigot@project.iam.gserviceaccount.com"`)
+			_, _ = w.Write([]byte(`{"access_token":"ya29.test","expires_in":3599,"token_type":"Bearer"}`))
+		case "/upload/storage/v1/b/logs/o":
+			assert.Equal(t, "media", r.URL.Query().Get("uploadType"))
+			b, err := io.ReadAll(r.Body)
+			assert.Nil(t, err)
+			objects = append(objects, object{name: r.URL.Query().Get("name"), contentType: r.Header.Get("Content-Type"), auth: r.Header.Get("Authorization"), body: b})
+			_, _ = w.Write([]byte(`{"kind":"storage#object"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &objects
+}
+
+func TestRotation(t *testing.T) {
+	srv, objects := newServer(t, nil)
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "bucket": "logs", "prefix": "firewall/%{+yyyy/MM/dd}/fw", "suffix": ".log", "compression": "none", "max_size": 10, "max_age": "1m", "token": "ya29.static", "url": srv.URL})
+	assert.Nil(t, err)
+	out, err := New(cfg)
+	assert.Nil(t, err)
+	o := out.(*Output)
+	now := time.Date(2024, 3, 2, 23, 59, 30, 0, time.UTC)
+	o.now = func() time.Time { return now }
+
+	for _, e := range []string{"one", "two"} {
+		_, err := o.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	assert.Len(t, *objects, 0)
+	now = now.Add(time.Minute)
+	for _, e := range []string{"three", "four"} {
+		_, err := o.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, o.Close())
+	assert.Nil(t, o.NewInterval())
+
+	assert.Len(t, *objects, 2)
+	assert.Regexp(t, `^firewall/2024/03/02/fw_\d{19}_\d{3}\.log$`, (*objects)[0].name)
+	assert.Equal(t, "one\ntwo\n", string((*objects)[0].body))
+	assert.Equal(t, "text/plain", (*objects)[0].contentType)
+	assert.Equal(t, "Bearer ya29.static", (*objects)[0].auth)
+	assert.Regexp(t, `^firewall/2024/03/03/fw_\d{19}_\d{3}\.log$`, (*objects)[1].name)
+	assert.Equal(t, "three\nfour\n", string((*objects)[1].body))
+}
+
+func TestServiceAccount(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	srv, objects := newServer(t, key)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.Nil(t, err)
+	account, err := json.Marshal(serviceAccount{
+		Type:         "service_account",
+		ClientEmail:  "spigot@project.iam.gserviceaccount.com",
+		PrivateKeyID: "1",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:     srv.URL + "/token",
+	})
+	assert.Nil(t, err)
+	file := filepath.Join(t.TempDir(), "service-account.json")
+	assert.Nil(t, os.WriteFile(file, account, 0o600))
+
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "bucket": "logs", "prefix": "test", "credentials_file": file, "url": srv.URL})
+	assert.Nil(t, err)
+	o, err := New(cfg)
+	assert.Nil(t, err)
+	_, err = o.Write([]byte("one"))
+	assert.Nil(t, err)
+	assert.Nil(t, o.Close())
+
+	assert.Len(t, *objects, 1)
+	assert.Regexp(t, `^test_\d{19}_\d{3}\.gz$`, (*objects)[0].name)
+	assert.Equal(t, "application/gzip", (*objects)[0].contentType)
+	assert.Equal(t, "Bearer ya29.test", (*objects)[0].auth)
+	zr, err := gzip.NewReader(bytes.NewReader((*objects)[0].body))
+	assert.Nil(t, err)
+	b, err := io.ReadAll(zr)
+	assert.Nil(t, err)
+	assert.Equal(t, "one\n", string(b))
+}
+
+func TestNotify(t *testing.T) {
+	var published []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/upload/storage/v1/b/logs/o":
+			_, _ = io.Copy(io.Discard, r.Body)
+			_, _ = w.Write([]byte(`{"kind":"storage#object","name":"` + r.URL.Query().Get("name") + `","bucket":"logs","generation":"1709423970000000","size":"8","timeCreated":"2024-03-02T23:59:30Z"}`))
+		case "/v1/projects/test/topics/logs:publish":
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			var msg struct {
+				Messages []map[string]interface{} `json:"messages"`
+			}
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(&msg))
+			published = append(published, msg.Messages...)
+			_, _ = w.Write([]byte(`{"messageIds":["1"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "bucket": "logs", "prefix": "test", "compression": "none", "url": srv.URL, "notify": map[string]interface{}{"topic": "projects/test/topics/logs", "url": srv.URL}})
+	assert.Nil(t, err)
+	o, err := New(cfg)
+	assert.Nil(t, err)
+	_, err = o.Write([]byte("one"))
+	assert.Nil(t, err)
+	assert.Nil(t, o.Close())
+
+	assert.Len(t, published, 1)
+	attrs := published[0]["attributes"].(map[string]interface{})
+	assert.Equal(t, "OBJECT_FINALIZE", attrs["eventType"])
+	assert.Equal(t, "JSON_API_V1", attrs["payloadFormat"])
+	assert.Equal(t, "logs", attrs["bucketId"])
+	assert.Regexp(t, `^test_\d{19}_\d{3}$`, attrs["objectId"])
+	assert.Equal(t, "1709423970000000", attrs["objectGeneration"])
+	data, err := base64.StdEncoding.DecodeString(published[0]["data"].(string))
+	assert.Nil(t, err)
+	var resource map[string]interface{}
+	assert.Nil(t, json.Unmarshal(data, &resource))
+	assert.Equal(t, attrs["objectId"], resource["name"])
+	assert.Equal(t, "storage#object", resource["kind"])
+}
@@ -0,0 +1,60 @@
+package gcs
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// notify publishes the notification of the object name of size bytes
+// to the topic.  object is the object resource of the upload, a
+// minimal resource is made up if it has none, as emulators do.
+func (o *Output) notify(name string, size int, object []byte) error {
+	var resource map[string]interface{}
+	if err := json.Unmarshal(object, &resource); err != nil || resource["name"] == nil {
+		resource = map[string]interface{}{
+			"kind":        "storage#object",
+			"name":        name,
+			"bucket":      o.bucket,
+			"generation":  strconv.FormatInt(o.now().UnixMicro(), 10),
+			"size":        strconv.Itoa(size),
+			"timeCreated": o.now().UTC().Format(time.RFC3339Nano),
+		}
+		object, _ = json.Marshal(resource)
+	}
+	generation, _ := resource["generation"].(string)
+	eventTime, _ := resource["timeCreated"].(string)
+	if eventTime == "" {
+		eventTime = o.now().UTC().Format(time.RFC3339Nano)
+	}
+	msg := map[string]interface{}{
+		"messages": []interface{}{
+			map[string]interface{}{
+				"data": base64.StdEncoding.EncodeToString(object),
+				"attributes": map[string]string{
+					"notificationConfig": "projects/_/buckets/" + o.bucket + "/notificationConfigs/spigot",
+					"eventType":          "OBJECT_FINALIZE",
+					"payloadFormat":      "JSON_API_V1",
+					"bucketId":           o.bucket,
+					"objectId":           name,
+					"objectGeneration":   generation,
+					"eventTime":          eventTime,
+				},
+			},
+		},
+	}
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, o.topicURL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	_, err = o.do(req)
+	return err
+}
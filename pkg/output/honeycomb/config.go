@@ -0,0 +1,48 @@
+package honeycomb
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type config struct {
+	Type       string        `config:"type" validate:"required"`
+	URL        string        `config:"url"`
+	Dataset    string        `config:"dataset" validate:"required"`
+	APIKey     string        `config:"api_key" validate:"required"`
+	TimeField  string        `config:"time_field"`
+	SampleRate int           `config:"sample_rate"`
+	BatchSize  int           `config:"batch_size"`
+	Timeout    time.Duration `config:"timeout"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:       Name,
+		URL:        "https://api.honeycomb.io",
+		TimeField:  "@timestamp",
+		SampleRate: 1,
+		BatchSize:  100,
+		Timeout:    10 * time.Second,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("'%s' is not a valid value for 'url' expected an http or https URL", c.URL)
+	}
+	if c.SampleRate <= 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'sample_rate' expected a value greater than 0", c.SampleRate)
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'batch_size' expected a value greater than 0", c.BatchSize)
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'timeout' expected a positive duration", c.Timeout)
+	}
+	return nil
+}
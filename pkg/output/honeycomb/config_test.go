@@ -0,0 +1,74 @@
+package honeycomb
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name, "dataset": "spigot", "api_key": "abc"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Options": {
+			c:           map[string]interface{}{"type": Name, "url": "https://api.eu1.honeycomb.io", "dataset": "spigot", "api_key": "abc", "time_field": "event.created", "sample_rate": 20, "batch_size": 50, "timeout": "30s"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "dataset": "spigot", "api_key": "abc"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'honeycomb' accessing config",
+		},
+		"Invalid URL": {
+			c:           map[string]interface{}{"type": Name, "url": "api.honeycomb.io", "dataset": "spigot", "api_key": "abc"},
+			hasError:    true,
+			errorString: "'api.honeycomb.io' is not a valid value for 'url' expected an http or https URL accessing config",
+		},
+		"No Dataset": {
+			c:           map[string]interface{}{"type": Name, "api_key": "abc"},
+			hasError:    true,
+			errorString: "string value is not set accessing 'dataset'",
+		},
+		"No API Key": {
+			c:           map[string]interface{}{"type": Name, "dataset": "spigot"},
+			hasError:    true,
+			errorString: "string value is not set accessing 'api_key'",
+		},
+		"Invalid Sample Rate": {
+			c:           map[string]interface{}{"type": Name, "dataset": "spigot", "api_key": "abc", "sample_rate": 0},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'sample_rate' expected a value greater than 0 accessing config",
+		},
+		"Invalid Batch Size": {
+			c:           map[string]interface{}{"type": Name, "dataset": "spigot", "api_key": "abc", "batch_size": 0},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'batch_size' expected a value greater than 0 accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, err.Error(), tc.errorString, name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Package honeycomb implements the output of structured events to the
+// batch events API of Honeycomb.
+//
+// Events must be JSON objects, such as those of generators with a
+// json format.  Each event is the data of a Honeycomb event of the
+// dataset, at the RFC 3339 time of time_field, "@timestamp" by default,
+// or the current time.  sample_rate is the sample rate of the events,
+// 1 by default, for a stream that emulates one sampled by its source.
+//
+// The events are sent when batch_size events are buffered, 100 by
+// default, with each new interval and on close.  The requests are
+// compressed with gzip.  Events that Honeycomb does not accept are
+// returned as errors.
+//
+//	output:
+//	  type: honeycomb
+//	  url: "https://api.honeycomb.io"
+//	  dataset: spigot
+//	  api_key: "hcaik_01hq..."
+//	  batch_size: 100
+//
+// timeout is the timeout of each request, by default 10s.
+package honeycomb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// Name is the name of the output in the configuration file and registry.
+const Name = "honeycomb"
+
+// Output buffers the events until they are sent.
+type Output struct {
+	c      config
+	url    string
+	client *http.Client
+	events []event
+}
+
+// event is an event of the batch API.
+type event struct {
+	Time       string          `json:"time"`
+	SampleRate int             `json:"samplerate"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func init() {
+	output.Register(Name, New)
+}
+
+// New is the Factory for creating a new honeycomb output.
+func New(cfg *ucfg.Config) (output.Output, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	return &Output{
+		c:      c,
+		url:    strings.TrimSuffix(c.URL, "/") + "/1/batch/" + url.PathEscape(c.Dataset),
+		client: &http.Client{Timeout: c.Timeout},
+	}, nil
+}
+
+// Write buffers the event, and sends the buffered events when there
+// are batch_size of them.
+func (o *Output) Write(b []byte) (int, error) {
+	e, err := output.Event(Name, b)
+	if err != nil {
+		return 0, err
+	}
+	t := time.Now()
+	if v, ok := output.Field(e, o.c.TimeField); ok {
+		if s, ok := v.(string); ok {
+			if ts, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				t = ts
+			}
+		}
+	}
+	o.events = append(o.events, event{
+		Time:       t.UTC().Format(time.RFC3339Nano),
+		SampleRate: o.c.SampleRate,
+		Data:       json.RawMessage(bytes.TrimSpace(b)),
+	})
+	if len(o.events) >= o.c.BatchSize {
+		if err := o.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// flush sends the buffered events.
+func (o *Output) flush() error {
+	if len(o.events) == 0 {
+		return nil
+	}
+	events := o.events
+	o.events = nil
+
+	var body bytes.Buffer
+	zw := gzip.NewWriter(&body)
+	if err := json.NewEncoder(zw).Encode(events); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, o.url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("X-Honeycomb-Team", o.c.APIKey)
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(msg, &e) == nil && e.Error != "" {
+			return fmt.Errorf("%s %s returned %s: %s", req.Method, req.URL, resp.Status, e.Error)
+		}
+		return fmt.Errorf("%s %s returned %s", req.Method, req.URL, resp.Status)
+	}
+
+	// the response has the status of each event
+	var statuses []struct {
+		Status int    `json:"status"`
+		Error  string `json:"error"`
+	}
+	if err := json.Unmarshal(msg, &statuses); err != nil {
+		return fmt.Errorf("honeycomb returned an invalid response: %w", err)
+	}
+	failed, first := 0, ""
+	for _, s := range statuses {
+		if s.Status == http.StatusAccepted {
+			continue
+		}
+		if failed == 0 {
+			first = fmt.Sprintf("%d %s", s.Status, s.Error)
+		}
+		failed++
+	}
+	if failed > 0 {
+		return fmt.Errorf("honeycomb failed to accept %d events, the first with %s", failed, first)
+	}
+	return nil
+}
+
+// Close sends the buffered events and closes any idle connections.
+func (o *Output) Close() error {
+	err := o.flush()
+	o.client.CloseIdleConnections()
+	return err
+}
+
+// NewInterval sends the buffered events, so each interval is a batch of
+// its own.
+func (o *Output) NewInterval() error {
+	return o.flush()
+}
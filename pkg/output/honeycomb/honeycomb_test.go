@@ -0,0 +1,66 @@
+package honeycomb
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/1/batch/web%20logs", r.URL.EscapedPath())
+		assert.Equal(t, "hcaik_test", r.Header.Get("X-Honeycomb-Team"))
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		zr, err := gzip.NewReader(r.Body)
+		assert.Nil(t, err)
+		b, _ := io.ReadAll(zr)
+		bodies = append(bodies, string(b))
+		if len(bodies) == 1 {
+			_, _ = io.WriteString(w, `[{"status":202},{"status":202}]`)
+			return
+		}
+		_, _ = io.WriteString(w, `[{"status":400,"error":"request body is malformed"}]`)
+	}))
+	defer srv.Close()
+
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "url": srv.URL, "dataset": "web logs", "api_key": "hcaik_test", "sample_rate": 10, "batch_size": 2})
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+
+	_, err = out.Write([]byte(`{"@timestamp":"2024-03-02T10:00:00.5+01:00","message":"one"}`))
+	assert.Nil(t, err)
+	_, err = out.Write([]byte(`{"@timestamp":"2024-03-02T10:00:01Z","message":"two"}` + "\n"))
+	assert.Nil(t, err)
+	assert.Len(t, bodies, 1)
+	assert.Equal(t, `[{"time":"2024-03-02T09:00:00.5Z","samplerate":10,"data":{"@timestamp":"2024-03-02T10:00:00.5+01:00","message":"one"}},{"time":"2024-03-02T10:00:01Z","samplerate":10,"data":{"@timestamp":"2024-03-02T10:00:01Z","message":"two"}}]`+"\n", bodies[0])
+
+	_, err = out.Write([]byte(`{"@timestamp":"2024-03-02T10:00:02Z","message":"three"}`))
+	assert.Nil(t, err)
+	assert.EqualError(t, out.Close(), "honeycomb failed to accept 1 events, the first with 400 request body is malformed")
+
+	_, err = out.Write([]byte(`not json`))
+	assert.EqualError(t, err, "honeycomb output expected a JSON object, use a json format of the generator")
+}
+
+func TestUnauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"error":"unknown API key - check your credentials"}`)
+	}))
+	defer srv.Close()
+
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "url": srv.URL, "dataset": "spigot", "api_key": "wrong"})
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+	_, err = out.Write([]byte(`{"message":"one"}`))
+	assert.Nil(t, err)
+	assert.EqualError(t, out.NewInterval(), "POST "+srv.URL+"/1/batch/spigot returned 401 Unauthorized: unknown API key - check your credentials")
+}
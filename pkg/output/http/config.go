@@ -0,0 +1,101 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/leehinman/spigot/pkg/output"
+	"github.com/leehinman/spigot/pkg/output/proxy"
+)
+
+type config struct {
+	Type         string            `config:"type" validate:"required"`
+	URL          string            `config:"url" validate:"required"`
+	Method       string            `config:"method"`
+	Headers      map[string]string `config:"headers"`
+	Envelope     bool              `config:"envelope"`
+	Framing      string            `config:"framing"`
+	DrainToken   string            `config:"drain_token"`
+	Timeout      time.Duration     `config:"timeout"`
+	Proxy        proxy.Config      `config:"proxy"`
+	LocalAddress string            `config:"local_address"`
+	Pcap         string            `config:"pcap"`
+	Template     string            `config:"template"`
+	BatchSize    int               `config:"batch_size"`
+	Compression  string            `config:"compression"`
+	Username     string            `config:"username"`
+	Password     string            `config:"password"`
+	BearerToken  string            `config:"bearer_token"`
+	MaxRetries   int               `config:"max_retries"`
+	Backoff      backoffConfig     `config:"backoff"`
+}
+
+type backoffConfig struct {
+	Init time.Duration `config:"init"`
+	Max  time.Duration `config:"max"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:      Name,
+		Method:    "POST",
+		Timeout:   10 * time.Second,
+		BatchSize: 1,
+		Backoff: backoffConfig{
+			Init: time.Second,
+			Max:  time.Minute,
+		},
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a valid value for 'url': %w", c.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("'%s' is not a valid value for 'url' expected an http or https URL", c.URL)
+	}
+	if c.Framing != "" && c.Framing != FramingLogplex {
+		return fmt.Errorf("'%s' is not a valid value for 'framing' expected '%s'", c.Framing, FramingLogplex)
+	}
+	if c.Framing == FramingLogplex && c.Envelope {
+		return fmt.Errorf("'envelope' can not be used with '%s' framing", FramingLogplex)
+	}
+	if _, err := output.LocalAddr("tcp", c.LocalAddress); err != nil {
+		return err
+	}
+	if c.Template != "" && !strings.Contains(c.Template, TemplateEvent) {
+		return fmt.Errorf("'%s' is not a valid value for 'template' expected '%s' in the template", c.Template, TemplateEvent)
+	}
+	if c.Template != "" && c.Envelope {
+		return fmt.Errorf("'template' can not be used with 'envelope'")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'batch_size' expected a value greater than 0", c.BatchSize)
+	}
+	if c.BatchSize > 1 && c.Envelope {
+		return fmt.Errorf("'batch_size' can not be used with 'envelope'")
+	}
+	if c.Compression != "" && c.Compression != CompressionGzip {
+		return fmt.Errorf("'%s' is not a valid value for 'compression' expected '%s'", c.Compression, CompressionGzip)
+	}
+	if c.BearerToken != "" && (c.Username != "" || c.Password != "") {
+		return fmt.Errorf("only one of bearer_token and username and password can be set")
+	}
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'max_retries' expected a value of 0 or more", c.MaxRetries)
+	}
+	if c.Backoff.Init <= 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'backoff.init' expected a positive duration", c.Backoff.Init)
+	}
+	if c.Backoff.Max < c.Backoff.Init {
+		return fmt.Errorf("'%s' is not a valid value for 'backoff.max' expected a duration of at least 'backoff.init'", c.Backoff.Max)
+	}
+	return nil
+}
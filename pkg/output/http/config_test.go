@@ -0,0 +1,124 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid": {
+			c:           map[string]interface{}{"type": Name, "url": "https://collector.example.com/webhook"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid with Headers": {
+			c:           map[string]interface{}{"type": Name, "url": "http://localhost:8080/", "headers": map[string]interface{}{"Authorization": "Bearer abc"}, "envelope": true},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid with Logplex Framing": {
+			c:           map[string]interface{}{"type": Name, "url": "https://localhost/logs", "framing": "logplex", "drain_token": "d.abc"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Framing": {
+			c:           map[string]interface{}{"type": Name, "url": "https://localhost/logs", "framing": "ndjson"},
+			hasError:    true,
+			errorString: "'ndjson' is not a valid value for 'framing' expected 'logplex' accessing config",
+		},
+		"Logplex Framing with Envelope": {
+			c:           map[string]interface{}{"type": Name, "url": "https://localhost/logs", "framing": "logplex", "envelope": true},
+			hasError:    true,
+			errorString: "'envelope' can not be used with 'logplex' framing accessing config",
+		},
+		"Valid with Local Address": {
+			c:           map[string]interface{}{"type": Name, "url": "https://localhost/logs", "local_address": "127.0.0.1"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Local Address": {
+			c:           map[string]interface{}{"type": Name, "url": "https://localhost/logs", "local_address": "localhost:80"},
+			hasError:    true,
+			errorString: "'localhost:80' is not a valid value for 'local_address' expected an IP address with an optional port accessing config",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "url": "http://localhost:8080/"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'http' accessing config",
+		},
+		"Invalid Scheme": {
+			c:           map[string]interface{}{"type": Name, "url": "ftp://localhost/"},
+			hasError:    true,
+			errorString: "'ftp://localhost/' is not a valid value for 'url' expected an http or https URL accessing config",
+		},
+		"Valid with Batch": {
+			c:           map[string]interface{}{"type": Name, "url": "https://localhost/hec", "template": `{"event": %{event}}`, "batch_size": 100, "compression": "gzip", "username": "spigot", "password": "s3cr3t", "max_retries": 5, "backoff": map[string]interface{}{"init": "500ms", "max": "30s"}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Template": {
+			c:           map[string]interface{}{"type": Name, "url": "https://localhost/hec", "template": `{"event": %{message}}`},
+			hasError:    true,
+			errorString: "'{\"event\": %{message}}' is not a valid value for 'template' expected '%{event}' in the template accessing config",
+		},
+		"Template with Envelope": {
+			c:           map[string]interface{}{"type": Name, "url": "https://localhost/hec", "template": `{"event": %{event}}`, "envelope": true},
+			hasError:    true,
+			errorString: "'template' can not be used with 'envelope' accessing config",
+		},
+		"Invalid Batch Size": {
+			c:           map[string]interface{}{"type": Name, "url": "https://localhost/hec", "batch_size": 0},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'batch_size' expected a value greater than 0 accessing config",
+		},
+		"Batch with Envelope": {
+			c:           map[string]interface{}{"type": Name, "url": "https://localhost/hec", "batch_size": 10, "envelope": true},
+			hasError:    true,
+			errorString: "'batch_size' can not be used with 'envelope' accessing config",
+		},
+		"Invalid Compression": {
+			c:           map[string]interface{}{"type": Name, "url": "https://localhost/hec", "compression": "zstd"},
+			hasError:    true,
+			errorString: "'zstd' is not a valid value for 'compression' expected 'gzip' accessing config",
+		},
+		"Bearer Token with Username": {
+			c:           map[string]interface{}{"type": Name, "url": "https://localhost/hec", "bearer_token": "abc", "username": "spigot"},
+			hasError:    true,
+			errorString: "only one of bearer_token and username and password can be set accessing config",
+		},
+		"Invalid Max Retries": {
+			c:           map[string]interface{}{"type": Name, "url": "https://localhost/hec", "max_retries": -1},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'max_retries' expected a value of 0 or more accessing config",
+		},
+		"Invalid Backoff Max": {
+			c:           map[string]interface{}{"type": Name, "url": "https://localhost/hec", "backoff": map[string]interface{}{"init": "10s", "max": "1s"}},
+			hasError:    true,
+			errorString: "'1s' is not a valid value for 'backoff.max' expected a duration of at least 'backoff.init' accessing config",
+		},
+		"No URL": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    true,
+			errorString: "string value is not set accessing 'url'",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+// Package http implements the output of logs to an HTTP endpoint.
+//
+// Each log entry is sent as the body of one request to url.  method
+// is optional and defaults to POST.  headers is optional and is added
+// to every request.  timeout is optional and defaults to 10s.
+//
+// When envelope is true each log entry must be a JSON object with
+// "headers" and "body", as produced by the saas:webhook generator.
+// The body is sent as the request body and the headers are added to
+// the request, after the configured headers.
+//
+//	output:
+//	  type: http
+//	  url: "https://collector.example.com/webhook"
+//	  headers:
+//	    Authorization: "Bearer 0123456789"
+//	  envelope: true
+//
+// When framing is "logplex" each log entry is sent the way Heroku
+// delivers messages to an HTTPS log drain: the body is the octet
+// counted frame "<length> <message>" and the request has the
+// Content-Type, Logplex-Msg-Count and Logplex-Frame-Id headers of a
+// drain.  drain_token is optional and is sent as Logplex-Drain-Token.
+//
+//	output:
+//	  type: http
+//	  url: "https://collector.example.com/logplex"
+//	  framing: logplex
+//	  drain_token: "d.01234567-89ab-cdef-0123-456789abcdef"
+//
+// proxy is optional and sends the requests through a SOCKS5 or HTTP
+// proxy, see package proxy.
+//
+//	output:
+//	  type: http
+//	  url: "https://collector.example.com/webhook"
+//	  proxy:
+//	    url: "http://egress.lab:3128"
+//	    username: "spigot"
+//	    password: "s3cr3t"
+//
+// local_address is optional and is the IP address, with an optional
+// port, the connections are made from.
+//
+// pcap is optional and records the requests in a pcap file, see
+// package pcap.  HTTPS requests are recorded encrypted.
+//
+// template is optional and is the body of each log entry, with
+// %{event} replaced by the log entry, before framing.  batch_size is
+// optional and defaults to 1.  Above 1 the log entries are sent in
+// batches of batch_size, each log entry on a line of its own, as
+// NDJSON, or as the frames of one logplex request.  The batches are
+// also sent with each new interval and on close.  Binary log entries
+// are not batched.
+//
+//	output:
+//	  type: http
+//	  url: "https://splunk.lab:8088/services/collector/event"
+//	  headers:
+//	    Authorization: "Splunk 01234567-89ab-cdef-0123-456789abcdef"
+//	  template: '{"sourcetype": "spigot", "event": %{event}}'
+//	  batch_size: 100
+//	  compression: gzip
+//	  max_retries: 5
+//	  backoff:
+//	    init: 1s
+//	    max: 60s
+//
+// compression is optional, with gzip the bodies are compressed and
+// sent with Content-Encoding gzip.  username and password are sent as
+// basic auth, bearer_token as a bearer token.  max_retries is optional
+// and defaults to 0, requests that fail to connect or are answered
+// with 429 or 5xx are sent again after a backoff, from backoff.init
+// doubling up to backoff.max, at most max_retries times.
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/google/uuid"
+	"github.com/leehinman/spigot/pkg/output"
+	"github.com/leehinman/spigot/pkg/output/pcap"
+)
+
+// Name is the name of the output in the configuration file and registry
+const Name = "http"
+
+// FramingLogplex frames each log entry as a Heroku HTTPS drain message.
+const FramingLogplex = "logplex"
+
+// TemplateEvent is replaced by the log entry in the template.
+const TemplateEvent = "%{event}"
+
+// CompressionGzip compresses the bodies with gzip.
+const CompressionGzip = "gzip"
+
+// Output holds the client and request settings.
+type Output struct {
+	client     *http.Client
+	url        string
+	method     string
+	headers    map[string]string
+	envelope   bool
+	framing    string
+	drainToken string
+	pcap       *pcap.Writer
+	template   string
+	batchSize  int
+	batch      [][]byte
+	compress   bool
+	username   string
+	password   string
+	bearer     string
+	maxRetries int
+	backoff    backoffConfig
+	sleep      func(time.Duration)
+}
+
+type envelope struct {
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+func init() {
+	output.Register(Name, New)
+}
+
+// New is the Factory for creating a new http output.
+func New(cfg *ucfg.Config) (output.Output, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.Proxy.Enabled() {
+		transport.Proxy = c.Proxy.HTTPProxy()
+	}
+	if c.LocalAddress != "" || c.Pcap != "" {
+		local, _ := output.LocalAddr("tcp", c.LocalAddress)
+		transport.DialContext = (&net.Dialer{
+			LocalAddr: local,
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+	var w *pcap.Writer
+	if c.Pcap != "" {
+		var err error
+		if w, err = pcap.Create(c.Pcap); err != nil {
+			return nil, err
+		}
+		dial := transport.DialContext
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dial(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return w.Conn(conn), nil
+		}
+	}
+
+	return &Output{
+		client:     &http.Client{Timeout: c.Timeout, Transport: transport},
+		url:        c.URL,
+		method:     c.Method,
+		headers:    c.Headers,
+		envelope:   c.Envelope,
+		framing:    c.Framing,
+		drainToken: c.DrainToken,
+		pcap:       w,
+		template:   c.Template,
+		batchSize:  c.BatchSize,
+		compress:   c.Compression == CompressionGzip,
+		username:   c.Username,
+		password:   c.Password,
+		bearer:     c.BearerToken,
+		maxRetries: c.MaxRetries,
+		backoff:    c.Backoff,
+		sleep:      time.Sleep,
+	}, nil
+}
+
+// Write sends the log entry as the body of a request, or adds it to
+// the batch.  A response status other than 2xx is returned as an
+// error.
+func (o *Output) Write(b []byte) (n int, err error) {
+	body := b
+	var headers map[string]string
+	if o.envelope {
+		var e envelope
+		if err := json.Unmarshal(b, &e); err != nil {
+			return 0, fmt.Errorf("log entry is not a valid envelope: %w", err)
+		}
+		body = []byte(e.Body)
+		headers = e.Headers
+	}
+	if o.template != "" {
+		body = []byte(strings.ReplaceAll(o.template, TemplateEvent, string(b)))
+	}
+	if o.framing == FramingLogplex {
+		body = []byte(fmt.Sprintf("%d %s", len(body), body))
+		headers = o.logplexHeaders(1)
+	}
+
+	if o.batchSize > 1 {
+		o.batch = append(o.batch, body)
+		if len(o.batch) >= o.batchSize {
+			if err := o.flush(); err != nil {
+				return 0, err
+			}
+		}
+		return len(b), nil
+	}
+	return o.send(body, headers, len(b))
+}
+
+// logplexHeaders returns the headers of a drain request of n messages.
+func (o *Output) logplexHeaders(n int) map[string]string {
+	headers := map[string]string{
+		"Content-Type":      "application/logplex-1",
+		"Logplex-Msg-Count": strconv.Itoa(n),
+		"Logplex-Frame-Id":  uuid.New().String(),
+	}
+	if o.drainToken != "" {
+		headers["Logplex-Drain-Token"] = o.drainToken
+	}
+	return headers
+}
+
+// flush sends the batch as the body of a request, the frames of the
+// logplex messages or the log entries as NDJSON.
+func (o *Output) flush() error {
+	batch := o.batch
+	o.batch = nil
+	if len(batch) == 0 {
+		return nil
+	}
+	if o.framing == FramingLogplex {
+		_, err := o.send(bytes.Join(batch, nil), o.logplexHeaders(len(batch)), 0)
+		return err
+	}
+	var body bytes.Buffer
+	for _, b := range batch {
+		body.Write(bytes.TrimRight(b, "\n"))
+		body.WriteByte('\n')
+	}
+	var headers map[string]string
+	if !o.hasHeader("Content-Type") {
+		headers = map[string]string{"Content-Type": "application/x-ndjson"}
+	}
+	_, err := o.send(body.Bytes(), headers, 0)
+	return err
+}
+
+func (o *Output) hasHeader(name string) bool {
+	for k := range o.headers {
+		if http.CanonicalHeaderKey(k) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteBinary sends the binary log entry as the body of a request,
+// with Content-Type application/octet-stream unless the headers have
+// one.  envelope and framing do not apply to binary entries.
+func (o *Output) WriteBinary(b []byte) (int, error) {
+	if o.hasHeader("Content-Type") {
+		return o.send(b, nil, len(b))
+	}
+	return o.send(b, map[string]string{"Content-Type": "application/octet-stream"}, len(b))
+}
+
+// send sends a request with body and headers, after the configured
+// headers, and returns n if the response status is 2xx.  Failed
+// requests are sent again up to max_retries times.
+func (o *Output) send(body []byte, headers map[string]string, n int) (int, error) {
+	if o.compress {
+		var z bytes.Buffer
+		zw := gzip.NewWriter(&z)
+		_, _ = zw.Write(body)
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+		body = z.Bytes()
+	}
+
+	backoff := o.backoff.Init
+	for retry := 0; ; retry++ {
+		status, err := o.do(body, headers)
+		if err == nil && status >= 200 && status <= 299 {
+			return n, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("%s %s returned %d %s", o.method, o.url, status, http.StatusText(status))
+		}
+		if retry >= o.maxRetries || (status != 0 && status != http.StatusTooManyRequests && status < 500) {
+			return 0, err
+		}
+		o.sleep(backoff)
+		if backoff *= 2; backoff > o.backoff.Max {
+			backoff = o.backoff.Max
+		}
+	}
+}
+
+// do sends one request and returns the status of the response, 0 if
+// there is none.
+func (o *Output) do(body []byte, headers map[string]string) (int, error) {
+	req, err := http.NewRequest(o.method, o.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range o.headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if o.compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	switch {
+	case o.bearer != "":
+		req.Header.Set("Authorization", "Bearer "+o.bearer)
+	case o.username != "" || o.password != "":
+		req.SetBasicAuth(o.username, o.password)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// Close sends the batch, and closes any idle connections and the pcap
+// file.
+func (o *Output) Close() error {
+	err := o.flush()
+	o.client.CloseIdleConnections()
+	if o.pcap != nil {
+		if cerr := o.pcap.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// NewInterval sends the batch.
+func (o *Output) NewInterval() error {
+	return o.flush()
+}
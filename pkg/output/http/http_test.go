@@ -0,0 +1,203 @@
+package http
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	tests := map[string]struct {
+		envelope bool
+		binary   bool
+		framing  string
+		input    string
+		status   int
+		body     string
+		headers  map[string]string
+		hasError bool
+	}{
+		"Raw": {
+			input:   "a",
+			status:  http.StatusOK,
+			body:    "a",
+			headers: map[string]string{"Authorization": "Bearer abc"},
+		},
+		"Envelope": {
+			envelope: true,
+			input:    `{"headers":{"Content-Type":"application/json","Stripe-Signature":"t=1,v1=ab"},"body":"{\"id\":\"evt_1\"}"}`,
+			status:   http.StatusNoContent,
+			body:     `{"id":"evt_1"}`,
+			headers:  map[string]string{"Authorization": "Bearer abc", "Content-Type": "application/json", "Stripe-Signature": "t=1,v1=ab"},
+		},
+		"Logplex": {
+			framing: FramingLogplex,
+			input:   "<158>1 1970-01-02T03:04:05.123456+00:00 host heroku router - at=info",
+			status:  http.StatusOK,
+			body:    "68 <158>1 1970-01-02T03:04:05.123456+00:00 host heroku router - at=info",
+			headers: map[string]string{"Authorization": "Bearer abc", "Content-Type": "application/logplex-1", "Logplex-Msg-Count": "1", "Logplex-Drain-Token": "d.abc"},
+		},
+		"Binary": {
+			binary:  true,
+			framing: FramingLogplex,
+			input:   "\x00\x05\xff\n",
+			status:  http.StatusOK,
+			body:    "\x00\x05\xff\n",
+			headers: map[string]string{"Authorization": "Bearer abc", "Content-Type": "application/octet-stream", "Logplex-Msg-Count": ""},
+		},
+		"Bad Envelope": {
+			envelope: true,
+			input:    "a",
+			hasError: true,
+		},
+		"Error Status": {
+			input:    "a",
+			status:   http.StatusUnauthorized,
+			body:     "a",
+			hasError: true,
+		},
+	}
+	for name, tc := range tests {
+		var gotBody string
+		var gotHeaders http.Header
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			gotBody = string(b)
+			gotHeaders = r.Header
+			w.WriteHeader(tc.status)
+		}))
+
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "url": srv.URL, "envelope": tc.envelope, "framing": tc.framing, "drain_token": "d.abc", "headers": map[string]interface{}{"Authorization": "Bearer abc"}})
+		assert.Nil(t, err, name)
+		o, err := New(c)
+		assert.Nil(t, err, name)
+
+		if tc.binary {
+			_, err = o.(*Output).WriteBinary([]byte(tc.input))
+		} else {
+			_, err = o.Write([]byte(tc.input))
+		}
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+		} else {
+			assert.Nil(t, err, name)
+			assert.Equal(t, tc.body, gotBody, name)
+			for k, v := range tc.headers {
+				assert.Equal(t, v, gotHeaders.Get(k), name)
+			}
+		}
+		assert.Nil(t, o.Close(), name)
+		srv.Close()
+	}
+}
+
+func TestProxy(t *testing.T) {
+	var gotURL, gotAuth string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.String()
+		gotAuth = r.Header.Get("Proxy-Authorization")
+	}))
+	defer proxy.Close()
+
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "url": "http://collector.example.com/webhook", "proxy": map[string]interface{}{"url": proxy.URL, "username": "spigot", "password": "s3cr3t"}})
+	assert.Nil(t, err)
+	o, err := New(c)
+	assert.Nil(t, err)
+	_, err = o.Write([]byte("a"))
+	assert.Nil(t, err)
+	assert.Equal(t, "http://collector.example.com/webhook", gotURL)
+	assert.Equal(t, "Basic c3BpZ290OnMzY3IzdA==", gotAuth)
+	assert.Nil(t, o.Close())
+}
+
+func TestBatch(t *testing.T) {
+	tests := map[string]struct {
+		c       map[string]interface{}
+		bodies  []string
+		headers map[string]string
+	}{
+		"NDJSON": {
+			c:       map[string]interface{}{"batch_size": 2},
+			bodies:  []string{"{\"a\":1}\n{\"a\":2}\n", "{\"a\":3}\n"},
+			headers: map[string]string{"Content-Type": "application/x-ndjson"},
+		},
+		"Template": {
+			c:       map[string]interface{}{"batch_size": 2, "template": `{"sourcetype":"spigot","event":%{event}}`, "headers": map[string]interface{}{"Content-Type": "application/json"}},
+			bodies:  []string{"{\"sourcetype\":\"spigot\",\"event\":{\"a\":1}}\n{\"sourcetype\":\"spigot\",\"event\":{\"a\":2}}\n", "{\"sourcetype\":\"spigot\",\"event\":{\"a\":3}}\n"},
+			headers: map[string]string{"Content-Type": "application/json"},
+		},
+		"Logplex": {
+			c:       map[string]interface{}{"batch_size": 2, "framing": FramingLogplex},
+			bodies:  []string{"7 {\"a\":1}7 {\"a\":2}", "7 {\"a\":3}"},
+			headers: map[string]string{"Content-Type": "application/logplex-1", "Logplex-Msg-Count": "1"},
+		},
+	}
+	for name, tc := range tests {
+		var bodies []string
+		var gotHeaders http.Header
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+			gotHeaders = r.Header
+		}))
+
+		tc.c["type"] = Name
+		tc.c["url"] = srv.URL
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		o, err := New(c)
+		assert.Nil(t, err, name)
+		for _, e := range []string{`{"a":1}`, `{"a":2}`, `{"a":3}`} {
+			_, err = o.Write([]byte(e))
+			assert.Nil(t, err, name)
+		}
+		assert.Len(t, bodies, 1, name)
+		assert.Nil(t, o.Close(), name)
+		assert.Equal(t, tc.bodies, bodies, name)
+		for k, v := range tc.headers {
+			assert.Equal(t, v, gotHeaders.Get(k), name)
+		}
+		srv.Close()
+	}
+}
+
+func TestRetry(t *testing.T) {
+	statuses := []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK, http.StatusBadRequest}
+	var bodies []string
+	var auth, encoding string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		zr, err := gzip.NewReader(r.Body)
+		assert.Nil(t, err)
+		b, _ := io.ReadAll(zr)
+		bodies = append(bodies, string(b))
+		auth, encoding = r.Header.Get("Authorization"), r.Header.Get("Content-Encoding")
+		w.WriteHeader(statuses[0])
+		statuses = statuses[1:]
+	}))
+	defer srv.Close()
+
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "url": srv.URL, "compression": CompressionGzip, "bearer_token": "abc", "max_retries": 3, "backoff.init": "2s", "backoff.max": "3s"}, ucfg.PathSep("."))
+	assert.Nil(t, err)
+	o, err := New(c)
+	assert.Nil(t, err)
+	var sleeps []time.Duration
+	o.(*Output).sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+	_, err = o.Write([]byte("a"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "a", "a"}, bodies)
+	assert.Equal(t, []time.Duration{2 * time.Second, 3 * time.Second}, sleeps)
+	assert.Equal(t, "Bearer abc", auth)
+	assert.Equal(t, "gzip", encoding)
+
+	_, err = o.Write([]byte("b"))
+	assert.EqualError(t, err, "POST "+srv.URL+" returned 400 Bad Request")
+	assert.Len(t, bodies, 4)
+	assert.Nil(t, o.Close())
+}
@@ -0,0 +1,69 @@
+package influx
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type config struct {
+	Type      string        `config:"type" validate:"required"`
+	URL       string        `config:"url"`
+	Org       string        `config:"org"`
+	Bucket    string        `config:"bucket"`
+	Token     string        `config:"token"`
+	Database  string        `config:"database"`
+	Username  string        `config:"username"`
+	Password  string        `config:"password"`
+	Precision string        `config:"precision"`
+	BatchSize int           `config:"batch_size"`
+	Timeout   time.Duration `config:"timeout"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:      Name,
+		URL:       "http://localhost:8086",
+		Precision: "ns",
+		BatchSize: 5000,
+		Timeout:   10 * time.Second,
+	}
+}
+
+var precisions = [...]string{"ns", "us", "ms", "s"}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("'%s' is not a valid value for 'url' expected an http or https URL", c.URL)
+	}
+	if c.Bucket == "" && c.Database == "" {
+		return fmt.Errorf("you must specify bucket or database")
+	}
+	if c.Bucket != "" && c.Database != "" {
+		return fmt.Errorf("only one of bucket and database can be set")
+	}
+	if c.Bucket != "" && (c.Username != "" || c.Password != "") {
+		return fmt.Errorf("'username' and 'password' can only be used with 'database'")
+	}
+	if c.Database != "" && (c.Org != "" || c.Token != "") {
+		return fmt.Errorf("'org' and 'token' can only be used with 'bucket'")
+	}
+	ok := false
+	for _, p := range precisions {
+		ok = ok || p == c.Precision
+	}
+	if !ok {
+		return fmt.Errorf("'%s' is not a valid value for 'precision' expected '%s'", c.Precision, strings.Join(precisions[:], ", "))
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'batch_size' expected a value greater than 0", c.BatchSize)
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'timeout' expected a positive duration", c.Timeout)
+	}
+	return nil
+}
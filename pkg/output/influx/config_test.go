@@ -0,0 +1,79 @@
+package influx
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Bucket": {
+			c:           map[string]interface{}{"type": Name, "bucket": "metrics", "org": "spigot", "token": "abc"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Database": {
+			c:           map[string]interface{}{"type": Name, "url": "https://influx.lab:8086", "database": "telegraf", "username": "spigot", "password": "s3cr3t", "precision": "s", "batch_size": 100},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "bucket": "metrics"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'influx' accessing config",
+		},
+		"Invalid URL": {
+			c:           map[string]interface{}{"type": Name, "bucket": "metrics", "url": "localhost:8086"},
+			hasError:    true,
+			errorString: "'localhost:8086' is not a valid value for 'url' expected an http or https URL accessing config",
+		},
+		"No Bucket": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    true,
+			errorString: "you must specify bucket or database accessing config",
+		},
+		"Bucket And Database": {
+			c:           map[string]interface{}{"type": Name, "bucket": "metrics", "database": "telegraf"},
+			hasError:    true,
+			errorString: "only one of bucket and database can be set accessing config",
+		},
+		"Bucket With Username": {
+			c:           map[string]interface{}{"type": Name, "bucket": "metrics", "username": "spigot"},
+			hasError:    true,
+			errorString: "'username' and 'password' can only be used with 'database' accessing config",
+		},
+		"Database With Token": {
+			c:           map[string]interface{}{"type": Name, "database": "telegraf", "token": "abc"},
+			hasError:    true,
+			errorString: "'org' and 'token' can only be used with 'bucket' accessing config",
+		},
+		"Invalid Precision": {
+			c:           map[string]interface{}{"type": Name, "bucket": "metrics", "precision": "n"},
+			hasError:    true,
+			errorString: "'n' is not a valid value for 'precision' expected 'ns, us, ms, s' accessing config",
+		},
+		"Invalid Batch Size": {
+			c:           map[string]interface{}{"type": Name, "bucket": "metrics", "batch_size": 0},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'batch_size' expected a value greater than 0 accessing config",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Package influx implements the output of points of the InfluxDB line
+// protocol, such as those of the influx:line generator, to the write
+// API of InfluxDB.
+//
+// With bucket the points are written with the v2 API, to the bucket of
+// org, authorized with token.  With database they are written with the
+// v1 API, authorized with username and password if set, which
+// InfluxDB 2 and 3 also serve.  precision is the precision of the
+// timestamps of the points, ns by default, and must be the precision
+// of the generator.
+//
+// The points are written when batch_size points are buffered, 5000 by
+// default, with each new interval and on close.  The requests are
+// compressed with gzip.
+//
+//	output:
+//	  type: influx
+//	  url: "http://localhost:8086"
+//	  org: spigot
+//	  bucket: metrics
+//	  token: "0123456789abcdef=="
+//	  precision: ns
+//	  batch_size: 5000
+//
+// timeout is the timeout of each request, by default 10s.
+package influx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// Name is the name of the output in the configuration file and registry.
+const Name = "influx"
+
+// v1Precisions are the precisions of the v1 API, which has n and u for
+// ns and us.
+var v1Precisions = map[string]string{"ns": "n", "us": "u", "ms": "ms", "s": "s"}
+
+// Output buffers the points until they are written.
+type Output struct {
+	c      config
+	url    string
+	client *http.Client
+	buf    bytes.Buffer
+	points int
+}
+
+func init() {
+	output.Register(Name, New)
+}
+
+// New is the Factory for creating a new influx output.
+func New(cfg *ucfg.Config) (output.Output, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	base := strings.TrimSuffix(c.URL, "/")
+	var u string
+	if c.Bucket != "" {
+		q := url.Values{"bucket": {c.Bucket}, "precision": {c.Precision}}
+		if c.Org != "" {
+			q.Set("org", c.Org)
+		}
+		u = base + "/api/v2/write?" + q.Encode()
+	} else {
+		u = base + "/write?" + url.Values{"db": {c.Database}, "precision": {v1Precisions[c.Precision]}}.Encode()
+	}
+	return &Output{
+		c:      c,
+		url:    u,
+		client: &http.Client{Timeout: c.Timeout},
+	}, nil
+}
+
+// Write buffers the point, and writes the buffered points when there
+// are batch_size of them.
+func (o *Output) Write(b []byte) (int, error) {
+	line := bytes.TrimSpace(b)
+	if len(line) == 0 {
+		return len(b), nil
+	}
+	o.buf.Write(line)
+	o.buf.WriteByte('\n')
+	o.points++
+	if o.points >= o.c.BatchSize {
+		if err := o.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// flush writes the buffered points.
+func (o *Output) flush() error {
+	if o.points == 0 {
+		return nil
+	}
+	var body bytes.Buffer
+	zw := gzip.NewWriter(&body)
+	_, _ = zw.Write(o.buf.Bytes())
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	o.buf.Reset()
+	o.points = 0
+
+	req, err := http.NewRequest(http.MethodPost, o.url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("Content-Encoding", "gzip")
+	switch {
+	case o.c.Token != "":
+		req.Header.Set("Authorization", "Token "+o.c.Token)
+	case o.c.Username != "" || o.c.Password != "":
+		req.SetBasicAuth(o.c.Username, o.c.Password)
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		u := *req.URL
+		u.RawQuery = ""
+		return fmt.Errorf("%s %s returned %s%s", req.Method, u.String(), resp.Status, errorMessage(msg))
+	}
+	return nil
+}
+
+// errorMessage returns the message of an InfluxDB error response, of
+// the v2 or the v1 API.
+func errorMessage(body []byte) string {
+	var e struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &e); err != nil {
+		return ""
+	}
+	if e.Message != "" {
+		return ": " + e.Message
+	}
+	if e.Error != "" {
+		return ": " + e.Error
+	}
+	return ""
+}
+
+// Close writes the buffered points and closes any idle connections.
+func (o *Output) Close() error {
+	err := o.flush()
+	o.client.CloseIdleConnections()
+	return err
+}
+
+// NewInterval writes the buffered points, so each interval is a batch
+// of its own.
+func (o *Output) NewInterval() error {
+	return o.flush()
+}
@@ -0,0 +1,80 @@
+package influx
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+type write struct {
+	path  string
+	query string
+	auth  string
+	body  string
+}
+
+func newServer(t *testing.T, writes *[]write) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		zr, err := gzip.NewReader(r.Body)
+		assert.Nil(t, err)
+		b, _ := io.ReadAll(zr)
+		*writes = append(*writes, write{path: r.URL.Path, query: r.URL.RawQuery, auth: r.Header.Get("Authorization"), body: string(b)})
+		if r.URL.Query().Get("bucket") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":"not found","message":"bucket \"missing\" not found"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWrite(t *testing.T) {
+	var writes []write
+	srv := newServer(t, &writes)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "url": srv.URL, "org": "spigot", "bucket": "metrics", "token": "abc", "batch_size": 2})
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+	for _, p := range []string{"cpu,host=a v=1i 1", "cpu,host=b v=2i 2\n", "", "cpu,host=c v=3i 3"} {
+		_, err := out.Write([]byte(p))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, out.Close())
+	assert.Equal(t, []write{
+		{path: "/api/v2/write", query: "bucket=metrics&org=spigot&precision=ns", auth: "Token abc", body: "cpu,host=a v=1i 1\ncpu,host=b v=2i 2\n"},
+		{path: "/api/v2/write", query: "bucket=metrics&org=spigot&precision=ns", auth: "Token abc", body: "cpu,host=c v=3i 3\n"},
+	}, writes)
+}
+
+func TestWriteV1(t *testing.T) {
+	var writes []write
+	srv := newServer(t, &writes)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "url": srv.URL + "/", "database": "telegraf", "username": "spigot", "password": "s3cr3t", "precision": "us"})
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+	_, err = out.Write([]byte("cpu v=1 1"))
+	assert.Nil(t, err)
+	assert.Nil(t, out.NewInterval())
+	assert.Nil(t, out.NewInterval())
+	assert.Equal(t, []write{{path: "/write", query: "db=telegraf&precision=u", auth: "Basic c3BpZ290OnMzY3IzdA==", body: "cpu v=1 1\n"}}, writes)
+}
+
+func TestError(t *testing.T) {
+	var writes []write
+	srv := newServer(t, &writes)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "url": srv.URL, "bucket": "missing"})
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+	_, err = out.Write([]byte("cpu v=1 1"))
+	assert.Nil(t, err)
+	assert.EqualError(t, out.Close(), "POST "+srv.URL+`/api/v2/write returned 404 Not Found: bucket "missing" not found`)
+}
@@ -0,0 +1,72 @@
+package kusto
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// refreshBefore is how long before it expires a token is refreshed.
+const refreshBefore = 5 * time.Minute
+
+// tokenSource returns the bearer token of the requests, the token of
+// the config or an Azure AD token of the application, with the client
+// credentials grant.
+type tokenSource struct {
+	client       *http.Client
+	token        string
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scope        string
+	expires      time.Time
+}
+
+func newTokenSource(client *http.Client, c config, resource string) *tokenSource {
+	if c.Token != "" {
+		return &tokenSource{token: c.Token}
+	}
+	return &tokenSource{
+		client:       client,
+		tokenURL:     strings.TrimSuffix(c.AuthorityURL, "/") + "/" + url.PathEscape(c.TenantID) + "/oauth2/v2.0/token",
+		clientID:     c.ClientID,
+		clientSecret: c.ClientSecret,
+		scope:        strings.TrimSuffix(resource, "/") + "/.default",
+	}
+}
+
+// get returns the token, and requests a new token of the application
+// when it is about to expire.
+func (s *tokenSource) get() (string, error) {
+	if s.tokenURL == "" || time.Now().Add(refreshBefore).Before(s.expires) {
+		return s.token, nil
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {s.clientID},
+		"client_secret": {s.clientSecret},
+		"scope":         {s.scope},
+	}
+	resp, err := s.client.PostForm(s.tokenURL, form)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("azure ad token response is not valid: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return "", fmt.Errorf("POST %s returned %s: %s", s.tokenURL, resp.Status, token.ErrorDescription)
+	}
+	s.token = token.AccessToken
+	s.expires = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return s.token, nil
+}
@@ -0,0 +1,71 @@
+package kusto
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type config struct {
+	Type         string        `config:"type" validate:"required"`
+	ClusterURL   string        `config:"cluster_url" validate:"required"`
+	IngestURL    string        `config:"ingest_url"`
+	Database     string        `config:"database" validate:"required"`
+	Table        string        `config:"table" validate:"required"`
+	Mapping      string        `config:"mapping"`
+	Ingestion    string        `config:"ingestion"`
+	BatchSize    int           `config:"batch_size"`
+	Token        string        `config:"token"`
+	TenantID     string        `config:"tenant_id"`
+	ClientID     string        `config:"client_id"`
+	ClientSecret string        `config:"client_secret"`
+	AuthorityURL string        `config:"authority_url"`
+	Timeout      time.Duration `config:"timeout"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:         Name,
+		Ingestion:    IngestionStreaming,
+		BatchSize:    1000,
+		AuthorityURL: "https://login.microsoftonline.com",
+		Timeout:      30 * time.Second,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	for _, opt := range []struct{ name, url string }{
+		{"cluster_url", c.ClusterURL},
+		{"ingest_url", c.IngestURL},
+		{"authority_url", c.AuthorityURL},
+	} {
+		if opt.url == "" {
+			continue
+		}
+		if u, err := url.Parse(opt.url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("'%s' is not a valid value for '%s' expected an http or https URL", opt.url, opt.name)
+		}
+	}
+	switch c.Ingestion {
+	case IngestionStreaming, IngestionQueued:
+	default:
+		return fmt.Errorf("'%s' is not a valid value for 'ingestion' expected '%s, %s'", c.Ingestion, IngestionStreaming, IngestionQueued)
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'batch_size' expected a value greater than 0", c.BatchSize)
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'timeout' expected a positive duration", c.Timeout)
+	}
+	app := c.TenantID != "" || c.ClientID != "" || c.ClientSecret != ""
+	if c.Token != "" && app {
+		return fmt.Errorf("only one of token and tenant_id, client_id and client_secret can be set")
+	}
+	if c.Token == "" && (c.TenantID == "" || c.ClientID == "" || c.ClientSecret == "") {
+		return fmt.Errorf("you must specify token or tenant_id, client_id and client_secret")
+	}
+	return nil
+}
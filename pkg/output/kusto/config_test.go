@@ -0,0 +1,85 @@
+package kusto
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	base := func(opts map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{"type": Name, "cluster_url": "https://mycluster.kusto.windows.net", "database": "security", "table": "SpigotEvents", "token": "abc"}
+		for k, v := range opts {
+			if v == nil {
+				delete(c, k)
+				continue
+			}
+			c[k] = v
+		}
+		return c
+	}
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid": {
+			c:           base(nil),
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Queued Application": {
+			c:           base(map[string]interface{}{"token": nil, "ingestion": "queued", "mapping": "spigot_json", "tenant_id": "tenant", "client_id": "app", "client_secret": "s3cr3t"}),
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           base(map[string]interface{}{"type": "Bob"}),
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'kusto' accessing config",
+		},
+		"No Table": {
+			c:           base(map[string]interface{}{"table": nil}),
+			hasError:    true,
+			errorString: "string value is not set accessing 'table'",
+		},
+		"Invalid Cluster URL": {
+			c:           base(map[string]interface{}{"cluster_url": "mycluster.kusto.windows.net"}),
+			hasError:    true,
+			errorString: "'mycluster.kusto.windows.net' is not a valid value for 'cluster_url' expected an http or https URL accessing config",
+		},
+		"Invalid Ingestion": {
+			c:           base(map[string]interface{}{"ingestion": "direct"}),
+			hasError:    true,
+			errorString: "'direct' is not a valid value for 'ingestion' expected 'streaming, queued' accessing config",
+		},
+		"Invalid Batch Size": {
+			c:           base(map[string]interface{}{"batch_size": 0}),
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'batch_size' expected a value greater than 0 accessing config",
+		},
+		"No Auth": {
+			c:           base(map[string]interface{}{"token": nil, "tenant_id": "tenant"}),
+			hasError:    true,
+			errorString: "you must specify token or tenant_id, client_id and client_secret accessing config",
+		},
+		"Both Auth": {
+			c:           base(map[string]interface{}{"client_secret": "s3cr3t"}),
+			hasError:    true,
+			errorString: "only one of token and tenant_id, client_id and client_secret can be set accessing config",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Package kusto implements the output of structured events to an Azure
+// Data Explorer (Kusto) table, with streaming or queued ingestion.
+//
+// Events must be JSON objects, such as those of generators with a
+// json format, and are ingested in the multijson format.  mapping is
+// optional and is the name of a JSON ingestion mapping of the table,
+// without it the fields of the events are the columns of the same
+// name.
+//
+// With streaming ingestion, the default, each batch of events is
+// posted to the streaming ingestion endpoint of the cluster, which
+// must be enabled on the cluster and the table.  With queued
+// ingestion each batch is uploaded as a blob to the temporary storage
+// of the ingestion endpoint, and queued for ingestion.  ingest_url is
+// the data ingestion URI of the cluster, by default cluster_url with
+// "ingest-" in front of its host.
+//
+// The events are ingested when batch_size events are buffered, with
+// each new interval and on close.
+//
+//	output:
+//	  type: kusto
+//	  cluster_url: "https://mycluster.westeurope.kusto.windows.net"
+//	  database: security
+//	  table: SpigotEvents
+//	  mapping: spigot_json
+//	  ingestion: queued
+//	  batch_size: 1000
+//	  tenant_id: "00000000-0000-0000-0000-000000000000"
+//	  client_id: "11111111-1111-1111-1111-111111111111"
+//	  client_secret: "s3cr3t"
+//
+// The requests are authorized with an Azure AD token of the
+// application of tenant_id, client_id and client_secret, or with the
+// bearer token of token.  authority_url is the Azure AD endpoint, by
+// default https://login.microsoftonline.com.  timeout is the timeout
+// of each request, by default 30s.
+package kusto
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// Name is the name of the output in the configuration file and registry.
+const Name = "kusto"
+
+// Ingestion methods.
+const (
+	IngestionStreaming = "streaming"
+	IngestionQueued    = "queued"
+)
+
+// Output buffers the events until they are ingested.
+type Output struct {
+	c         config
+	ingestURL string
+	client    *http.Client
+	tokens    *tokenSource
+	resources *resources
+	buf       bytes.Buffer
+	events    int
+}
+
+func init() {
+	output.Register(Name, New)
+}
+
+// New is the Factory for creating a new kusto output.
+func New(cfg *ucfg.Config) (output.Output, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	o := &Output{
+		c:         c,
+		ingestURL: strings.TrimSuffix(c.IngestURL, "/"),
+		client:    &http.Client{Timeout: c.Timeout},
+	}
+	if o.ingestURL == "" {
+		u, _ := url.Parse(strings.TrimSuffix(c.ClusterURL, "/"))
+		u.Host = "ingest-" + u.Host
+		o.ingestURL = u.String()
+	}
+	resource := c.ClusterURL
+	if c.Ingestion == IngestionQueued {
+		resource = o.ingestURL
+	}
+	o.tokens = newTokenSource(o.client, c, resource)
+	return o, nil
+}
+
+// Write buffers the event, and ingests the buffered events when there
+// are batch_size of them.
+func (o *Output) Write(b []byte) (int, error) {
+	if _, err := output.Event(Name, b); err != nil {
+		return 0, err
+	}
+	o.buf.Write(bytes.TrimSpace(b))
+	o.buf.WriteByte('\n')
+	o.events++
+	if o.events >= o.c.BatchSize {
+		if err := o.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// flush ingests the buffered events, compressed with gzip.
+func (o *Output) flush() error {
+	if o.events == 0 {
+		return nil
+	}
+	size := o.buf.Len()
+	var data bytes.Buffer
+	zw := gzip.NewWriter(&data)
+	_, _ = zw.Write(o.buf.Bytes())
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	o.buf.Reset()
+	o.events = 0
+
+	if o.c.Ingestion == IngestionQueued {
+		return o.enqueue(data.Bytes(), size)
+	}
+	return o.stream(data.Bytes())
+}
+
+// stream posts the events to the streaming ingestion endpoint.
+func (o *Output) stream(data []byte) error {
+	q := url.Values{"streamFormat": {"multijson"}}
+	if o.c.Mapping != "" {
+		q.Set("mappingName", o.c.Mapping)
+	}
+	u := strings.TrimSuffix(o.c.ClusterURL, "/") + "/v1/rest/ingest/" + url.PathEscape(o.c.Database) + "/" + url.PathEscape(o.c.Table) + "?" + q.Encode()
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	_, err = o.do(req, true)
+	return err
+}
+
+// do sends the request, with the bearer token if authorize is set,
+// and returns the body of the response.  A response status other than
+// 2xx is returned as an error, without the query of the URL, which
+// may be a shared access signature.
+func (o *Output) do(req *http.Request, authorize bool) ([]byte, error) {
+	if authorize {
+		token, err := o.tokens.get()
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("x-ms-app", "spigot")
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		u := *req.URL
+		u.RawQuery = ""
+		return nil, fmt.Errorf("%s %s returned %s%s", req.Method, u.String(), resp.Status, errorMessage(body))
+	}
+	return body, nil
+}
+
+// errorMessage returns the message of a Kusto error response.
+func errorMessage(body []byte) string {
+	var e struct {
+		Error struct {
+			Message string `json:"@message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &e); err != nil || e.Error.Message == "" {
+		return ""
+	}
+	return ": " + e.Error.Message
+}
+
+// Close ingests the buffered events and closes any idle connections.
+func (o *Output) Close() error {
+	err := o.flush()
+	o.client.CloseIdleConnections()
+	return err
+}
+
+// NewInterval ingests the buffered events, so each interval is a batch
+// of its own.
+func (o *Output) NewInterval() error {
+	return o.flush()
+}
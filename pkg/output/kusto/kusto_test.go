@@ -0,0 +1,194 @@
+package kusto
+
+import (
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+// request is a request the fake cluster received.
+type request struct {
+	method string
+	path   string
+	query  string
+	auth   string
+	body   string
+}
+
+// cluster is a fake cluster, with its ingestion endpoint, storage and
+// Azure AD.
+type cluster struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []request
+	tokens   int
+}
+
+func newCluster(t *testing.T) *cluster {
+	c := &cluster{}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body []byte
+		if r.Header.Get("Content-Encoding") == "gzip" || strings.HasSuffix(r.URL.Path, ".gz") {
+			zr, err := gzip.NewReader(r.Body)
+			assert.Nil(t, err)
+			body, _ = io.ReadAll(zr)
+		} else {
+			body, _ = io.ReadAll(r.Body)
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.requests = append(c.requests, request{method: r.Method, path: r.URL.Path, query: r.URL.RawQuery, auth: r.Header.Get("Authorization"), body: string(body)})
+
+		switch {
+		case r.URL.Path == "/tenant/oauth2/v2.0/token":
+			form, _ := url.ParseQuery(string(body))
+			if form.Get("client_secret") != "s3cr3t" {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"AADSTS7000215: Invalid client secret provided."}`))
+				return
+			}
+			c.tokens++
+			_, _ = w.Write([]byte(`{"token_type":"Bearer","expires_in":3599,"access_token":"aad-token"}`))
+		case r.URL.Path == "/v1/rest/ingest/security/Missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"BadRequest","@message":"Table 'Missing' was not found"}}`))
+		case strings.HasPrefix(r.URL.Path, "/v1/rest/ingest/"):
+		case r.URL.Path == "/v1/rest/mgmt":
+			var cmd struct{ Csl string }
+			_ = json.Unmarshal(body, &cmd)
+			rows := `[["TempStorage","` + c.URL + `/tempstorage?sv=sig"],["SecuredReadyForAggregationQueue","` + c.URL + `/readyforaggregation?sv=sig"],["SuccessfulIngestionsQueue","` + c.URL + `/success?sv=sig"]]`
+			if cmd.Csl == ".get kusto identity token" {
+				rows = `[["auth-context"]]`
+			}
+			_, _ = w.Write([]byte(`{"Tables":[{"TableName":"Table_0","Rows":` + rows + `}]}`))
+		case strings.HasPrefix(r.URL.Path, "/tempstorage/"):
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/readyforaggregation/messages":
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestStreaming(t *testing.T) {
+	c := newCluster(t)
+	cfg, err := ucfg.NewFrom(map[string]interface{}{
+		"type":          Name,
+		"cluster_url":   c.URL,
+		"database":      "security",
+		"table":         "SpigotEvents",
+		"mapping":       "spigot_json",
+		"batch_size":    2,
+		"authority_url": c.URL,
+		"tenant_id":     "tenant",
+		"client_id":     "app",
+		"client_secret": "s3cr3t",
+	})
+	assert.Nil(t, err)
+	out, err := New(cfg)
+	assert.Nil(t, err)
+
+	for _, e := range []string{`{"message":"one"}`, `{"message":"two"}` + "\n", `{"message":"three"}`} {
+		_, err := out.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, out.Close())
+
+	assert.Len(t, c.requests, 3)
+	assert.Equal(t, "/tenant/oauth2/v2.0/token", c.requests[0].path)
+	assert.Contains(t, c.requests[0].body, "scope="+url.QueryEscape(c.URL+"/.default"))
+	assert.Equal(t, request{
+		method: http.MethodPost,
+		path:   "/v1/rest/ingest/security/SpigotEvents",
+		query:  "mappingName=spigot_json&streamFormat=multijson",
+		auth:   "Bearer aad-token",
+		body:   "{\"message\":\"one\"}\n{\"message\":\"two\"}\n",
+	}, c.requests[1])
+	assert.Equal(t, "{\"message\":\"three\"}\n", c.requests[2].body)
+	assert.Equal(t, 1, c.tokens)
+
+	_, err = out.Write([]byte("not json"))
+	assert.EqualError(t, err, "kusto output expected a JSON object, use a json format of the generator")
+}
+
+func TestQueued(t *testing.T) {
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "cluster_url": "https://mycluster.kusto.windows.net/", "database": "security", "table": "SpigotEvents", "ingestion": IngestionQueued, "token": "static"})
+	assert.Nil(t, err)
+	out, err := New(cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://ingest-mycluster.kusto.windows.net", out.(*Output).ingestURL)
+
+	c := newCluster(t)
+	cfg, err = ucfg.NewFrom(map[string]interface{}{"type": Name, "cluster_url": "https://mycluster.kusto.windows.net", "ingest_url": c.URL, "database": "security", "table": "SpigotEvents", "ingestion": IngestionQueued, "token": "static"})
+	assert.Nil(t, err)
+	out, err = New(cfg)
+	assert.Nil(t, err)
+
+	_, err = out.Write([]byte(`{"message":"one"}`))
+	assert.Nil(t, err)
+	assert.Nil(t, out.NewInterval())
+	assert.Nil(t, out.NewInterval())
+
+	assert.Len(t, c.requests, 4)
+	assert.Equal(t, "/v1/rest/mgmt", c.requests[0].path)
+	assert.Equal(t, "Bearer static", c.requests[0].auth)
+	assert.JSONEq(t, `{"db":"NetDefaultDB","csl":".get ingestion resources"}`, c.requests[0].body)
+	assert.JSONEq(t, `{"db":"NetDefaultDB","csl":".get kusto identity token"}`, c.requests[1].body)
+
+	blob := c.requests[2]
+	assert.Equal(t, http.MethodPut, blob.method)
+	assert.Regexp(t, `^/tempstorage/security__SpigotEvents__[0-9a-f-]{36}\.multijson\.gz$`, blob.path)
+	assert.Equal(t, "sv=sig", blob.query)
+	assert.Equal(t, "", blob.auth)
+	assert.Equal(t, "{\"message\":\"one\"}\n", blob.body)
+
+	queued := c.requests[3]
+	assert.Equal(t, "/readyforaggregation/messages", queued.path)
+	var qm struct {
+		MessageText string
+	}
+	assert.Nil(t, xml.Unmarshal([]byte(queued.body), &qm))
+	text, err := base64.StdEncoding.DecodeString(qm.MessageText)
+	assert.Nil(t, err)
+	var msg ingestionMessage
+	assert.Nil(t, json.Unmarshal(text, &msg))
+	assert.Equal(t, c.URL+blob.path+"?sv=sig", msg.BlobPath)
+	assert.Equal(t, strings.TrimSuffix(strings.TrimPrefix(blob.path, "/tempstorage/security__SpigotEvents__"), ".multijson.gz"), msg.ID)
+	assert.Equal(t, 18, msg.RawDataSize)
+	assert.Equal(t, "security", msg.DatabaseName)
+	assert.Equal(t, "SpigotEvents", msg.TableName)
+	assert.Equal(t, map[string]string{"authorizationContext": "auth-context", "format": "multijson"}, msg.AdditionalProperties)
+}
+
+func TestErrors(t *testing.T) {
+	c := newCluster(t)
+	cfg, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "cluster_url": c.URL, "database": "security", "table": "Missing", "token": "static"})
+	assert.Nil(t, err)
+	out, err := New(cfg)
+	assert.Nil(t, err)
+	_, err = out.Write([]byte(`{"message":"one"}`))
+	assert.Nil(t, err)
+	assert.EqualError(t, out.NewInterval(), "POST "+c.URL+"/v1/rest/ingest/security/Missing returned 404 Not Found: Table 'Missing' was not found")
+
+	cfg, err = ucfg.NewFrom(map[string]interface{}{"type": Name, "cluster_url": c.URL, "database": "security", "table": "SpigotEvents", "authority_url": c.URL, "tenant_id": "tenant", "client_id": "app", "client_secret": "wrong"})
+	assert.Nil(t, err)
+	out, err = New(cfg)
+	assert.Nil(t, err)
+	_, err = out.Write([]byte(`{"message":"one"}`))
+	assert.Nil(t, err)
+	assert.EqualError(t, out.Close(), "POST "+c.URL+"/tenant/oauth2/v2.0/token returned 401 Unauthorized: AADSTS7000215: Invalid client secret provided.")
+}
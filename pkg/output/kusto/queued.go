@@ -0,0 +1,180 @@
+package kusto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// resourcesTTL is how long the ingestion resources are used before
+// they are requested again.
+const resourcesTTL = time.Hour
+
+// storageVersion is the version of the Azure Storage REST API of the
+// blob and queue requests.
+const storageVersion = "2019-12-12"
+
+// resources are the temporary storage containers and the queues of the
+// ingestion endpoint, as URLs with a shared access signature, and the
+// authorization context of the queued messages.
+type resources struct {
+	containers  []string
+	queues      []string
+	authContext string
+	expires     time.Time
+	next        int
+}
+
+// ingestionResources returns the ingestion resources, and requests
+// them again when they expire.
+func (o *Output) ingestionResources() (*resources, error) {
+	if o.resources != nil && time.Now().Before(o.resources.expires) {
+		return o.resources, nil
+	}
+	rows, err := o.mgmt(".get ingestion resources")
+	if err != nil {
+		return nil, err
+	}
+	r := &resources{expires: time.Now().Add(resourcesTTL)}
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		typ, _ := row[0].(string)
+		root, _ := row[1].(string)
+		switch typ {
+		case "TempStorage":
+			r.containers = append(r.containers, root)
+		case "SecuredReadyForAggregationQueue":
+			r.queues = append(r.queues, root)
+		}
+	}
+	if len(r.containers) == 0 || len(r.queues) == 0 {
+		return nil, fmt.Errorf("kusto ingestion resources have no temporary storage or queue")
+	}
+
+	rows, err = o.mgmt(".get kusto identity token")
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return nil, fmt.Errorf("kusto identity token is not set")
+	}
+	r.authContext, _ = rows[0][0].(string)
+	o.resources = r
+	return r, nil
+}
+
+// mgmt runs a management command on the ingestion endpoint and
+// returns the rows of its first table.
+func (o *Output) mgmt(csl string) ([][]interface{}, error) {
+	b, _ := json.Marshal(map[string]string{"db": "NetDefaultDB", "csl": csl})
+	req, err := http.NewRequest(http.MethodPost, o.ingestURL+"/v1/rest/mgmt", bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Accept", "application/json")
+	body, err := o.do(req, true)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Tables []struct {
+			Rows [][]interface{} `json:"Rows"`
+		} `json:"Tables"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("kusto response of '%s' is not valid: %w", csl, err)
+	}
+	if len(resp.Tables) == 0 {
+		return nil, nil
+	}
+	return resp.Tables[0].Rows, nil
+}
+
+// ingestionMessage is the message of a blob in the ingestion queue.
+type ingestionMessage struct {
+	ID                   string            `json:"Id"`
+	BlobPath             string            `json:"BlobPath"`
+	RawDataSize          int               `json:"RawDataSize"`
+	DatabaseName         string            `json:"DatabaseName"`
+	TableName            string            `json:"TableName"`
+	RetainBlobOnSuccess  bool              `json:"RetainBlobOnSuccess"`
+	FlushImmediately     bool              `json:"FlushImmediately"`
+	ReportLevel          int               `json:"ReportLevel"`
+	ReportMethod         int               `json:"ReportMethod"`
+	AdditionalProperties map[string]string `json:"AdditionalProperties"`
+}
+
+// enqueue uploads the events as a blob and queues it for ingestion.
+// The containers and queues are used in turn.
+func (o *Output) enqueue(data []byte, size int) error {
+	r, err := o.ingestionResources()
+	if err != nil {
+		return err
+	}
+	container, queue := r.containers[r.next%len(r.containers)], r.queues[r.next%len(r.queues)]
+	r.next++
+
+	id := uuid.New().String()
+	blob, err := withPath(container, fmt.Sprintf("%s__%s__%s.multijson.gz", o.c.Database, o.c.Table, id))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, blob, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-version", storageVersion)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if _, err := o.do(req, false); err != nil {
+		return err
+	}
+
+	props := map[string]string{"authorizationContext": r.authContext, "format": "multijson"}
+	if o.c.Mapping != "" {
+		props["ingestionMappingReference"] = o.c.Mapping
+		props["ingestionMappingType"] = "Json"
+	}
+	msg, _ := json.Marshal(ingestionMessage{
+		ID:                   id,
+		BlobPath:             blob,
+		RawDataSize:          size,
+		DatabaseName:         o.c.Database,
+		TableName:            o.c.Table,
+		AdditionalProperties: props,
+	})
+	messages, err := withPath(queue, "messages")
+	if err != nil {
+		return err
+	}
+	body := "<QueueMessage><MessageText>" + base64.StdEncoding.EncodeToString(msg) + "</MessageText></QueueMessage>"
+	req, err = http.NewRequest(http.MethodPost, messages, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-ms-version", storageVersion)
+	req.Header.Set("Content-Type", "application/xml")
+	_, err = o.do(req, false)
+	return err
+}
+
+// withPath returns the URL of the resource name below the storage
+// resource root, with the query of root.
+func withPath(root, name string) (string, error) {
+	u, err := url.Parse(root)
+	if err != nil {
+		return "", fmt.Errorf("kusto ingestion resource is not a valid URL: %w", err)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + name
+	return u.String(), nil
+}
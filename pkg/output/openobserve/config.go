@@ -0,0 +1,47 @@
+package openobserve
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type config struct {
+	Type         string        `config:"type" validate:"required"`
+	URL          string        `config:"url"`
+	Organization string        `config:"organization"`
+	Stream       string        `config:"stream" validate:"required"`
+	Username     string        `config:"username" validate:"required"`
+	Password     string        `config:"password" validate:"required"`
+	BatchSize    int           `config:"batch_size"`
+	Timeout      time.Duration `config:"timeout"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:         Name,
+		URL:          "http://localhost:5080",
+		Organization: "default",
+		BatchSize:    1000,
+		Timeout:      10 * time.Second,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("'%s' is not a valid value for 'url' expected an http or https URL", c.URL)
+	}
+	if c.Organization == "" {
+		return fmt.Errorf("'organization' can not be empty")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'batch_size' expected a value greater than 0", c.BatchSize)
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'timeout' expected a positive duration", c.Timeout)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package openobserve
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name, "stream": "spigot", "username": "root@example.com", "password": "pass"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Options": {
+			c:           map[string]interface{}{"type": Name, "url": "https://api.openobserve.ai", "organization": "lab", "stream": "spigot", "username": "root@example.com", "password": "pass", "batch_size": 500, "timeout": "30s"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "stream": "spigot", "username": "root@example.com", "password": "pass"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'openobserve' accessing config",
+		},
+		"Invalid URL": {
+			c:           map[string]interface{}{"type": Name, "url": "localhost:5080", "stream": "spigot", "username": "root@example.com", "password": "pass"},
+			hasError:    true,
+			errorString: "'localhost:5080' is not a valid value for 'url' expected an http or https URL accessing config",
+		},
+		"Empty Organization": {
+			c:           map[string]interface{}{"type": Name, "organization": "", "stream": "spigot", "username": "root@example.com", "password": "pass"},
+			hasError:    true,
+			errorString: "'organization' can not be empty accessing config",
+		},
+		"No Stream": {
+			c:           map[string]interface{}{"type": Name, "username": "root@example.com", "password": "pass"},
+			hasError:    true,
+			errorString: "string value is not set accessing 'stream'",
+		},
+		"No Password": {
+			c:           map[string]interface{}{"type": Name, "stream": "spigot", "username": "root@example.com"},
+			hasError:    true,
+			errorString: "string value is not set accessing 'password'",
+		},
+		"Invalid Batch Size": {
+			c:           map[string]interface{}{"type": Name, "stream": "spigot", "username": "root@example.com", "password": "pass", "batch_size": -1},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'batch_size' expected a value greater than 0 accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, err.Error(), tc.errorString, name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Package openobserve implements the output of structured events to
+// the JSON ingestion API of OpenObserve.
+//
+// Events must be JSON objects, such as those of generators with a
+// json format.  They are ingested into the stream of the organization,
+// "default" by default, authorized with username and password.
+// OpenObserve takes the time of the events from their _timestamp or
+// @timestamp, or the time of ingestion.
+//
+// The events are sent when batch_size events are buffered, 1000 by
+// default, with each new interval and on close.  The requests are
+// compressed with gzip.  Events that OpenObserve fails to ingest are
+// returned as errors.
+//
+//	output:
+//	  type: openobserve
+//	  url: "http://localhost:5080"
+//	  organization: default
+//	  stream: spigot
+//	  username: "root@example.com"
+//	  password: "Complexpass#123"
+//
+// timeout is the timeout of each request, by default 10s.
+package openobserve
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// Name is the name of the output in the configuration file and registry.
+const Name = "openobserve"
+
+// Output buffers the events until they are sent.
+type Output struct {
+	c      config
+	url    string
+	client *http.Client
+	buf    bytes.Buffer
+	events int
+}
+
+func init() {
+	output.Register(Name, New)
+}
+
+// New is the Factory for creating a new openobserve output.
+func New(cfg *ucfg.Config) (output.Output, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	return &Output{
+		c:      c,
+		url:    strings.TrimSuffix(c.URL, "/") + "/api/" + url.PathEscape(c.Organization) + "/" + url.PathEscape(c.Stream) + "/_json",
+		client: &http.Client{Timeout: c.Timeout},
+	}, nil
+}
+
+// Write buffers the event, and sends the buffered events when there
+// are batch_size of them.
+func (o *Output) Write(b []byte) (int, error) {
+	if _, err := output.Event(Name, b); err != nil {
+		return 0, err
+	}
+	if o.events == 0 {
+		o.buf.WriteByte('[')
+	} else {
+		o.buf.WriteByte(',')
+	}
+	o.buf.Write(bytes.TrimSpace(b))
+	o.events++
+	if o.events >= o.c.BatchSize {
+		if err := o.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// flush sends the buffered events, a JSON array.
+func (o *Output) flush() error {
+	if o.events == 0 {
+		return nil
+	}
+	o.buf.WriteByte(']')
+	var body bytes.Buffer
+	zw := gzip.NewWriter(&body)
+	_, _ = zw.Write(o.buf.Bytes())
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	o.buf.Reset()
+	o.events = 0
+
+	req, err := http.NewRequest(http.MethodPost, o.url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	req.SetBasicAuth(o.c.Username, o.c.Password)
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(resp.Body)
+
+	var r struct {
+		Message string `json:"message"`
+		Status  []struct {
+			Failed int    `json:"failed"`
+			Error  string `json:"error"`
+		} `json:"status"`
+	}
+	_ = json.Unmarshal(msg, &r)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if r.Message != "" {
+			return fmt.Errorf("%s %s returned %s: %s", req.Method, req.URL, resp.Status, r.Message)
+		}
+		return fmt.Errorf("%s %s returned %s", req.Method, req.URL, resp.Status)
+	}
+	for _, s := range r.Status {
+		if s.Failed > 0 {
+			return fmt.Errorf("openobserve failed to ingest %d events: %s", s.Failed, s.Error)
+		}
+	}
+	return nil
+}
+
+// Close sends the buffered events and closes any idle connections.
+func (o *Output) Close() error {
+	err := o.flush()
+	o.client.CloseIdleConnections()
+	return err
+}
+
+// NewInterval sends the buffered events, so each interval is a batch of
+// its own.
+func (o *Output) NewInterval() error {
+	return o.flush()
+}
@@ -0,0 +1,67 @@
+package openobserve
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/lab/web/_json", r.URL.Path)
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "root@example.com", user)
+		assert.Equal(t, "Complexpass#123", pass)
+		zr, err := gzip.NewReader(r.Body)
+		assert.Nil(t, err)
+		b, _ := io.ReadAll(zr)
+		bodies = append(bodies, string(b))
+		if len(bodies) == 1 {
+			_, _ = io.WriteString(w, `{"code":200,"status":[{"name":"web","successful":2,"failed":0}]}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"code":200,"status":[{"name":"web","successful":0,"failed":1,"error":"too old data, only last 5 hours data can be ingested"}]}`)
+	}))
+	defer srv.Close()
+
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "url": srv.URL, "organization": "lab", "stream": "web", "username": "root@example.com", "password": "Complexpass#123", "batch_size": 2})
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+
+	_, err = out.Write([]byte(`{"message":"one"}`))
+	assert.Nil(t, err)
+	_, err = out.Write([]byte(`{"message":"two"}` + "\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{`[{"message":"one"},{"message":"two"}]`}, bodies)
+
+	_, err = out.Write([]byte(`{"@timestamp":"2001-01-01T00:00:00Z","message":"three"}`))
+	assert.Nil(t, err)
+	assert.EqualError(t, out.Close(), "openobserve failed to ingest 1 events: too old data, only last 5 hours data can be ingested")
+
+	_, err = out.Write([]byte(`"three"`))
+	assert.EqualError(t, err, "openobserve output expected a JSON object, use a json format of the generator")
+}
+
+func TestUnauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"code":401,"message":"Unauthorized Access"}`)
+	}))
+	defer srv.Close()
+
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "url": srv.URL, "stream": "spigot", "username": "root@example.com", "password": "wrong"})
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+	_, err = out.Write([]byte(`{"message":"one"}`))
+	assert.Nil(t, err)
+	assert.EqualError(t, out.NewInterval(), "POST "+srv.URL+"/api/default/spigot/_json returned 401 Unauthorized: Unauthorized Access")
+}
@@ -4,6 +4,9 @@
 package output
 
 import (
+	"encoding/binary"
+	"io"
+
 	"github.com/elastic/go-ucfg"
 )
 
@@ -14,6 +17,28 @@ type Output interface {
 	NewInterval() error
 }
 
+// BinaryOutput is implemented by outputs that can write binary events,
+// such as NetFlow packets, which may contain any byte and must not be
+// framed as lines of text.  Stream outputs prefix each event with its
+// length, datagram outputs send it as one datagram and JSON outputs
+// encode it as base64.
+type BinaryOutput interface {
+	WriteBinary(p []byte) (n int, err error)
+}
+
+// WriteLength writes p to w prefixed with its length, as a 4 byte big
+// endian integer.
+func WriteLength(w io.Writer, p []byte) (int, error) {
+	var prefix [4]byte
+	binary.BigEndian.PutUint32(prefix[:], uint32(len(p)))
+	j, err := w.Write(prefix[:])
+	if err != nil {
+		return j, err
+	}
+	k, err := w.Write(p)
+	return j + k, err
+}
+
 type config struct {
 	Type string `config:"type" validate:"required"`
 }
@@ -0,0 +1,68 @@
+package parquet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Partitions of the files.
+const (
+	PartitionNone   = "none"
+	PartitionDaily  = "daily"
+	PartitionHourly = "hourly"
+)
+
+// Compressions of the files.
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+)
+
+var (
+	partitions   = [...]string{PartitionNone, PartitionDaily, PartitionHourly}
+	compressions = [...]string{CompressionNone, CompressionGzip}
+)
+
+type config struct {
+	Type        string `config:"type" validate:"required"`
+	Directory   string `config:"directory" validate:"required"`
+	Partition   string `config:"partition"`
+	TimeField   string `config:"time_field"`
+	RowsPerFile int    `config:"rows_per_file"`
+	Compression string `config:"compression"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:        Name,
+		Partition:   PartitionDaily,
+		TimeField:   "@timestamp",
+		RowsPerFile: 10000,
+		Compression: CompressionGzip,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if !contains(partitions[:], c.Partition) {
+		return fmt.Errorf("'%s' is not a valid value for 'partition' expected '%s'", c.Partition, strings.Join(partitions[:], ", "))
+	}
+	if c.RowsPerFile <= 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'rows_per_file' expected a value greater than 0", c.RowsPerFile)
+	}
+	if !contains(compressions[:], c.Compression) {
+		return fmt.Errorf("'%s' is not a valid value for 'compression' expected '%s'", c.Compression, strings.Join(compressions[:], ", "))
+	}
+	return nil
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package parquet
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid": {
+			c:           map[string]interface{}{"type": Name, "directory": "/var/tmp/lake"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Hourly": {
+			c:           map[string]interface{}{"type": Name, "directory": "/var/tmp/lake", "partition": "hourly", "time_field": "event.created", "rows_per_file": 100, "compression": "none"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "directory": "/var/tmp/lake"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'parquet' accessing config",
+		},
+		"No Directory": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    true,
+			errorString: "string value is not set accessing 'directory'",
+		},
+		"Invalid Partition": {
+			c:           map[string]interface{}{"type": Name, "directory": "/var/tmp/lake", "partition": "weekly"},
+			hasError:    true,
+			errorString: "'weekly' is not a valid value for 'partition' expected 'none, daily, hourly' accessing config",
+		},
+		"Invalid Rows Per File": {
+			c:           map[string]interface{}{"type": Name, "directory": "/var/tmp/lake", "rows_per_file": 0},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'rows_per_file' expected a value greater than 0 accessing config",
+		},
+		"Invalid Compression": {
+			c:           map[string]interface{}{"type": Name, "directory": "/var/tmp/lake", "compression": "snappy"},
+			hasError:    true,
+			errorString: "'snappy' is not a valid value for 'compression' expected 'none, gzip' accessing config",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
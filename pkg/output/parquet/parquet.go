@@ -0,0 +1,147 @@
+// Package parquet implements the output of structured events to
+// Parquet files, for testing the ingestion of data lakes such as
+// Athena or Spark.
+//
+// Events must be JSON objects, such as those of generators with a
+// json format.  The schema of a file is derived from its events:
+// every field is optional, objects are groups, integers are INT64,
+// other numbers DOUBLE, booleans BOOLEAN and everything else,
+// including lists and fields with values of mixed types, UTF8
+// strings.  The time field is a TIMESTAMP_MICROS when all its values
+// are RFC 3339 times.
+//
+// Files are written to Hive style partitions of the directory by the
+// time field of the events, or the current time when it is not set,
+// e.g. "dt=2024-03-04" for daily or "dt=2024-03-04/hour=12" for hourly
+// partitions.  A file is written when a partition has rows_per_file
+// events, with each new interval and on close.
+//
+//	output:
+//	  type: parquet
+//	  directory: "/var/tmp/lake"
+//	  partition: hourly
+//	  time_field: "@timestamp"
+//	  rows_per_file: 10000
+//	  compression: gzip
+//
+// directory is required.  partition is none, daily (the default) or
+// hourly.  time_field is a dotted path, "@timestamp" by default.
+// compression is none or gzip (the default).
+package parquet
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// Name is the name of the output in the configuration file and registry.
+const Name = "parquet"
+
+// Output buffers the events of each partition until they are written
+// to a file.
+type Output struct {
+	c      config
+	events map[string][]map[string]interface{}
+	now    func() time.Time
+}
+
+func init() {
+	output.Register(Name, New)
+}
+
+// New is the Factory for creating a new parquet output.
+func New(cfg *ucfg.Config) (output.Output, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	return &Output{
+		c:      c,
+		events: make(map[string][]map[string]interface{}),
+		now:    time.Now,
+	}, nil
+}
+
+// Write adds the event to its partition, and writes the partition to
+// a file when it has rows_per_file events.
+func (o *Output) Write(b []byte) (int, error) {
+	event, err := output.Event(Name, b)
+	if err != nil {
+		return 0, err
+	}
+
+	p := partition(o.time(event), o.c.Partition)
+	o.events[p] = append(o.events[p], event)
+	if len(o.events[p]) >= o.c.RowsPerFile {
+		if err := o.flush(p); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// time returns the time of the event, its time field or the current
+// time.
+func (o *Output) time(event map[string]interface{}) time.Time {
+	if v, ok := output.Field(event, o.c.TimeField); ok {
+		if s, ok := v.(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				return t
+			}
+		}
+	}
+	return o.now()
+}
+
+// flush writes the events of the partition p to a new file.
+func (o *Output) flush(p string) error {
+	events := o.events[p]
+	delete(o.events, p)
+	if len(events) == 0 {
+		return nil
+	}
+	dir := filepath.Join(o.c.Directory, filepath.FromSlash(p))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, "part-*.parquet")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(f, events, o.c.TimeField, o.c.Compression == CompressionGzip); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// flushAll writes the events of all the partitions.
+func (o *Output) flushAll() error {
+	partitions := make([]string, 0, len(o.events))
+	for p := range o.events {
+		partitions = append(partitions, p)
+	}
+	sort.Strings(partitions)
+	for _, p := range partitions {
+		if err := o.flush(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close writes the buffered events.
+func (o *Output) Close() error {
+	return o.flushAll()
+}
+
+// NewInterval writes the buffered events, so each interval has its
+// own files.
+func (o *Output) NewInterval() error {
+	return o.flushAll()
+}
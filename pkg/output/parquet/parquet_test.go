@@ -0,0 +1,234 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+// decoder reads the structs written by thrift, as maps of the field
+// ids to their values.
+type decoder struct {
+	r *bytes.Reader
+}
+
+func (d *decoder) varint() uint64 {
+	v, _ := binary.ReadUvarint(d.r)
+	return v
+}
+
+func (d *decoder) zigzag() int64 {
+	v := d.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (d *decoder) value(typ byte) interface{} {
+	switch typ {
+	case thriftI32, thriftI64:
+		return d.zigzag()
+	case thriftBinary:
+		b := make([]byte, d.varint())
+		_, _ = io.ReadFull(d.r, b)
+		return string(b)
+	case thriftList:
+		h, _ := d.r.ReadByte()
+		n := uint64(h >> 4)
+		if n == 15 {
+			n = d.varint()
+		}
+		l := make([]interface{}, n)
+		for i := range l {
+			l[i] = d.value(h & 0x0f)
+		}
+		return l
+	case thriftStruct:
+		return d.read()
+	}
+	panic("unexpected type")
+}
+
+func (d *decoder) read() map[int16]interface{} {
+	s := make(map[int16]interface{})
+	var id int16
+	for {
+		h, _ := d.r.ReadByte()
+		if h == 0 {
+			return s
+		}
+		if delta := int16(h >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(d.zigzag())
+		}
+		s[id] = d.value(h & 0x0f)
+	}
+}
+
+// footer returns the FileMetaData of the file.
+func footer(t *testing.T, file []byte) map[int16]interface{} {
+	assert.Equal(t, magic, string(file[:4]))
+	assert.Equal(t, magic, string(file[len(file)-4:]))
+	n := binary.LittleEndian.Uint32(file[len(file)-8:])
+	start := len(file) - 8 - int(n)
+	return (&decoder{bytes.NewReader(file[start : len(file)-8])}).read()
+}
+
+// values returns the values of the column chunk, nil for null values.
+func values(t *testing.T, file []byte, chunk map[int16]interface{}) []interface{} {
+	meta := chunk[3].(map[int16]interface{})
+	r := bytes.NewReader(file[meta[9].(int64):])
+	header := (&decoder{r}).read()
+	body := make([]byte, header[3].(int64))
+	_, _ = io.ReadFull(r, body)
+	if meta[4].(int64) == codecGzip {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		assert.Nil(t, err)
+		body, err = io.ReadAll(zr)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, header[2].(int64), int64(len(body)))
+
+	rows := int(header[5].(map[int16]interface{})[1].(int64))
+	maxDef := len(meta[3].([]interface{}))
+	n := binary.LittleEndian.Uint32(body)
+	levels := bytes.NewReader(body[4 : 4+n])
+	var defs []int
+	for len(defs) < rows {
+		run, _ := binary.ReadUvarint(levels)
+		def, _ := levels.ReadByte()
+		for i := 0; i < int(run>>1); i++ {
+			defs = append(defs, int(def))
+		}
+	}
+
+	data := body[4+n:]
+	var out []interface{}
+	var bit int
+	for _, def := range defs {
+		if def < maxDef {
+			out = append(out, nil)
+			continue
+		}
+		switch meta[1].(int64) {
+		case typeBoolean:
+			out = append(out, data[bit/8]&(1<<(bit%8)) != 0)
+			bit++
+		case typeInt64:
+			out = append(out, int64(binary.LittleEndian.Uint64(data)))
+			data = data[8:]
+		case typeDouble:
+			out = append(out, math.Float64frombits(binary.LittleEndian.Uint64(data)))
+			data = data[8:]
+		default:
+			l := binary.LittleEndian.Uint32(data)
+			out = append(out, string(data[4:4+l]))
+			data = data[4+l:]
+		}
+	}
+	return out
+}
+
+func TestWriteFile(t *testing.T) {
+	events := []string{
+		`{"@timestamp":"2024-03-04T12:00:00Z","bytes":10,"ratio":0.5,"ok":true,"source":{"ip":"10.0.0.1","port":80},"tags":["a","b"],"mixed":1}`,
+		`{"@timestamp":"2024-03-04T12:00:01.5Z","bytes":20,"ratio":2,"ok":false,"source":{"ip":"10.0.0.2"},"mixed":{"x":1}}`,
+		`{"@timestamp":"2024-03-04T12:00:02Z","ok":true,"message":"hello"}`,
+	}
+	for _, compress := range []bool{false, true} {
+		var rows []map[string]interface{}
+		for _, e := range events {
+			var row map[string]interface{}
+			d := json.NewDecoder(strings.NewReader(e))
+			d.UseNumber()
+			assert.Nil(t, d.Decode(&row))
+			rows = append(rows, row)
+		}
+		var buf bytes.Buffer
+		assert.Nil(t, writeFile(&buf, rows, "@timestamp", compress))
+		file := buf.Bytes()
+
+		meta := footer(t, file)
+		assert.Equal(t, int64(3), meta[3])
+		var names []string
+		for _, e := range meta[2].([]interface{}) {
+			names = append(names, e.(map[int16]interface{})[4].(string))
+		}
+		assert.Equal(t, []string{"schema", "@timestamp", "bytes", "message", "mixed", "ok", "ratio", "source", "ip", "port", "tags"}, names)
+		ts := meta[2].([]interface{})[1].(map[int16]interface{})
+		assert.Equal(t, int64(typeInt64), ts[1])
+		assert.Equal(t, int64(convertedTimestampMicros), ts[6])
+
+		got := map[string][]interface{}{}
+		for _, c := range meta[4].([]interface{})[0].(map[int16]interface{})[1].([]interface{}) {
+			chunk := c.(map[int16]interface{})
+			var path string
+			for _, p := range chunk[3].(map[int16]interface{})[3].([]interface{}) {
+				path = join(path, p.(string))
+			}
+			got[path] = values(t, file, chunk)
+		}
+		start := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC).UnixMicro()
+		assert.Equal(t, map[string][]interface{}{
+			"@timestamp":  {start, start + 1500000, start + 2000000},
+			"bytes":       {int64(10), int64(20), nil},
+			"message":     {nil, nil, "hello"},
+			"mixed":       {"1", `{"x":1}`, nil},
+			"ok":          {true, false, true},
+			"ratio":       {0.5, 2.0, nil},
+			"source.ip":   {"10.0.0.1", "10.0.0.2", nil},
+			"source.port": {int64(80), nil, nil},
+			"tags":        {`["a","b"]`, nil, nil},
+		}, got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "directory": dir, "partition": "hourly", "rows_per_file": 2})
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+	out.(*Output).now = func() time.Time { return time.Date(2024, 3, 5, 1, 0, 0, 0, time.UTC) }
+
+	for _, e := range []string{
+		`{"@timestamp":"2024-03-04T12:00:00Z","n":1}`,
+		`{"@timestamp":"2024-03-04T12:30:00+00:00","n":2}`,
+		`{"@timestamp":"2024-03-04T13:00:00Z","n":3}`,
+		`{"n":4}`,
+	} {
+		_, err := out.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*", "*.parquet"))
+	assert.Len(t, files, 1)
+
+	assert.Nil(t, out.Close())
+	for p, rows := range map[string]int64{"dt=2024-03-04/hour=12": 2, "dt=2024-03-04/hour=13": 1, "dt=2024-03-05/hour=01": 1} {
+		files, _ := filepath.Glob(filepath.Join(dir, filepath.FromSlash(p), "part-*.parquet"))
+		assert.Len(t, files, 1, p)
+		file, err := os.ReadFile(files[0])
+		assert.Nil(t, err)
+		assert.Equal(t, rows, footer(t, file)[3], p)
+	}
+
+	_, err = out.Write([]byte("<13>Mar  4 12:00:00 host app: hello"))
+	assert.EqualError(t, err, "parquet output expected a JSON object, use a json format of the generator")
+}
+
+func TestPartition(t *testing.T) {
+	at := time.Date(2024, 3, 4, 9, 30, 0, 0, time.FixedZone("", 3600))
+	assert.Equal(t, "", partition(at, PartitionNone))
+	assert.Equal(t, "dt=2024-03-04", partition(at, PartitionDaily))
+	assert.Equal(t, "dt=2024-03-04/hour=08", partition(at, PartitionHourly))
+}
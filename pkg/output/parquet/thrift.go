@@ -0,0 +1,97 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Types of the Thrift compact protocol.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thrift writes the structs of the Parquet metadata with the Thrift
+// compact protocol.  Fields are written in increasing order of their
+// ids, as the field headers hold the delta to the previous id.
+type thrift struct {
+	buf  bytes.Buffer
+	last []int16
+}
+
+func (t *thrift) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	t.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (t *thrift) zigzag(v int64) {
+	t.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (t *thrift) field(id int16, typ byte) {
+	last := t.last[len(t.last)-1]
+	if delta := id - last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.zigzag(int64(id))
+	}
+	t.last[len(t.last)-1] = id
+}
+
+// begin starts a struct, the top level one or the value of a field.
+func (t *thrift) begin() {
+	t.last = append(t.last, 0)
+}
+
+// end ends the struct begin started.
+func (t *thrift) end() {
+	t.buf.WriteByte(0)
+	t.last = t.last[:len(t.last)-1]
+}
+
+func (t *thrift) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.zigzag(int64(v))
+}
+
+func (t *thrift) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.zigzag(v)
+}
+
+func (t *thrift) str(id int16, v string) {
+	t.field(id, thriftBinary)
+	t.varint(uint64(len(v)))
+	t.buf.WriteString(v)
+}
+
+// list writes the header of a list of n elements of typ, which are
+// written next without field headers.
+func (t *thrift) list(id int16, typ byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.buf.WriteByte(byte(n)<<4 | typ)
+		return
+	}
+	t.buf.WriteByte(0xf0 | typ)
+	t.varint(uint64(n))
+}
+
+func (t *thrift) i32s(id int16, v []int32) {
+	t.list(id, thriftI32, len(v))
+	for _, e := range v {
+		t.zigzag(int64(e))
+	}
+}
+
+func (t *thrift) strs(id int16, v []string) {
+	t.list(id, thriftBinary, len(v))
+	for _, e := range v {
+		t.varint(uint64(len(e)))
+		t.buf.WriteString(e)
+	}
+}
@@ -0,0 +1,459 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Physical and converted types, encodings and codecs of the Parquet
+// format.
+const (
+	typeBoolean   = 0
+	typeInt64     = 2
+	typeDouble    = 5
+	typeByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMicros = 10
+
+	repetitionOptional = 1
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecUncompressed = 0
+	codecGzip         = 2
+
+	pageData = 0
+)
+
+const magic = "PAR1"
+
+// kinds of the values of a column, from the values of the events.
+type kind int
+
+const (
+	kindNull kind = iota
+	kindBool
+	kindInt
+	kindDouble
+	kindTime
+	kindString
+)
+
+// merge returns the kind of a column with values of k and o.  Integers
+// and doubles are doubles, other mixed values are strings.
+func (k kind) merge(o kind) kind {
+	switch {
+	case k == o || o == kindNull:
+		return k
+	case k == kindNull:
+		return o
+	case (k == kindInt && o == kindDouble) || (k == kindDouble && o == kindInt):
+		return kindDouble
+	}
+	return kindString
+}
+
+// node is a field of the schema of the events, a group for objects or
+// a column for the other values.  A field that is an object in some
+// events and a value in others is a string column, with the objects
+// as JSON.
+type node struct {
+	name     string
+	group    bool
+	value    bool
+	kind     kind
+	children map[string]*node
+}
+
+func newNode(name string) *node {
+	return &node{name: name, children: make(map[string]*node)}
+}
+
+// add adds the value v of the field at path to the schema.
+func (n *node) add(v interface{}, path, timeField string) {
+	switch v := v.(type) {
+	case nil:
+	case map[string]interface{}:
+		n.group = true
+		for k, e := range v {
+			c, ok := n.children[k]
+			if !ok {
+				c = newNode(k)
+				n.children[k] = c
+			}
+			c.add(e, join(path, k), timeField)
+		}
+	default:
+		n.value = true
+		n.kind = n.kind.merge(kindOf(v, path == timeField))
+	}
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// isColumn reports whether n is a column.  Fields that are never set
+// are string columns.
+func (n *node) isColumn() bool {
+	return n.value || len(n.children) == 0
+}
+
+// sorted returns the children of n in the order of their names.
+func (n *node) sorted() []*node {
+	children := make([]*node, 0, len(n.children))
+	for _, c := range n.children {
+		children = append(children, c)
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].name < children[j].name })
+	return children
+}
+
+func kindOf(v interface{}, isTime bool) kind {
+	switch v := v.(type) {
+	case bool:
+		return kindBool
+	case json.Number:
+		if _, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return kindInt
+		}
+		return kindDouble
+	case string:
+		if _, err := time.Parse(time.RFC3339Nano, v); isTime && err == nil {
+			return kindTime
+		}
+	}
+	return kindString
+}
+
+// column is a column of the file, a leaf of the schema.
+type column struct {
+	path []string
+	node *node
+}
+
+// columns returns the columns below n, depth first in the order of the
+// names.
+func columns(n *node, path []string) []column {
+	var cols []column
+	for _, c := range n.sorted() {
+		p := append(append([]string(nil), path...), c.name)
+		if c.isColumn() {
+			cols = append(cols, column{path: p, node: c})
+			continue
+		}
+		cols = append(cols, columns(c, p)...)
+	}
+	return cols
+}
+
+// lookup returns the value of the column path in the event, and its
+// definition level, the number of the fields of path that are set.
+func lookup(event map[string]interface{}, path []string) (interface{}, int) {
+	var cur interface{} = event
+	for i, name := range path {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, i
+		}
+		if cur, ok = m[name]; !ok || cur == nil {
+			return nil, i
+		}
+	}
+	return cur, len(path)
+}
+
+// writeFile writes the events to w as a Parquet file with a row group
+// of a data page per column.  The fields of the events are optional
+// fields of the schema, objects are groups.  The time field is a
+// timestamp if all its values are RFC 3339 times.
+func writeFile(w io.Writer, events []map[string]interface{}, timeField string, compress bool) error {
+	root := newNode("schema")
+	for _, e := range events {
+		root.add(e, "", timeField)
+	}
+	cols := columns(root, nil)
+
+	codec := int32(codecUncompressed)
+	if compress {
+		codec = codecGzip
+	}
+
+	var file bytes.Buffer
+	file.WriteString(magic)
+	meta := make([]columnMeta, len(cols))
+	for i, c := range cols {
+		page, n, err := encodeColumn(c, events, compress)
+		if err != nil {
+			return err
+		}
+		meta[i] = columnMeta{column: c, offset: int64(file.Len()), compressed: int64(len(page)), uncompressed: int64(n)}
+		file.Write(page)
+	}
+
+	footer := fileMetaData(root, meta, int64(len(events)), codec)
+	file.Write(footer)
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	file.Write(length[:])
+	file.WriteString(magic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+type columnMeta struct {
+	column
+	offset       int64
+	compressed   int64
+	uncompressed int64
+}
+
+// encodeColumn returns the data page of the column c, with its header,
+// and its size without compression.
+func encodeColumn(c column, events []map[string]interface{}, compress bool) ([]byte, int, error) {
+	maxDef := len(c.path)
+	defs := make([]int, len(events))
+	var values bytes.Buffer
+	var booleans []bool
+	for i, e := range events {
+		v, def := lookup(e, c.path)
+		defs[i] = def
+		if def < maxDef {
+			continue
+		}
+		switch c.node.physical() {
+		case typeBoolean:
+			booleans = append(booleans, v.(bool))
+		case typeInt64:
+			n, err := int64Of(v, c.node.kind)
+			if err != nil {
+				return nil, 0, err
+			}
+			_ = binary.Write(&values, binary.LittleEndian, n)
+		case typeDouble:
+			f, err := v.(json.Number).Float64()
+			if err != nil {
+				return nil, 0, err
+			}
+			_ = binary.Write(&values, binary.LittleEndian, math.Float64bits(f))
+		default:
+			s, err := stringOf(v)
+			if err != nil {
+				return nil, 0, err
+			}
+			_ = binary.Write(&values, binary.LittleEndian, uint32(len(s)))
+			values.WriteString(s)
+		}
+	}
+	if booleans != nil {
+		values.Write(packBooleans(booleans))
+	}
+
+	levels := rle(defs, bits.Len(uint(maxDef)))
+	var data bytes.Buffer
+	_ = binary.Write(&data, binary.LittleEndian, uint32(len(levels)))
+	data.Write(levels)
+	data.Write(values.Bytes())
+
+	body := data.Bytes()
+	if compress {
+		var z bytes.Buffer
+		zw := gzip.NewWriter(&z)
+		if _, err := zw.Write(body); err != nil {
+			return nil, 0, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, 0, err
+		}
+		body = z.Bytes()
+	}
+
+	t := &thrift{}
+	t.begin()
+	t.i32(1, pageData)
+	t.i32(2, int32(data.Len()))
+	t.i32(3, int32(len(body)))
+	t.field(5, thriftStruct)
+	t.begin()
+	t.i32(1, int32(len(events)))
+	t.i32(2, encodingPlain)
+	t.i32(3, encodingRLE)
+	t.i32(4, encodingRLE)
+	t.end()
+	t.end()
+
+	header := t.buf.Len()
+	t.buf.Write(body)
+	return t.buf.Bytes(), header + data.Len(), nil
+}
+
+// physical returns the physical type of the column n.
+func (n *node) physical() int32 {
+	if n.group {
+		return typeByteArray
+	}
+	switch n.kind {
+	case kindBool:
+		return typeBoolean
+	case kindInt, kindTime:
+		return typeInt64
+	case kindDouble:
+		return typeDouble
+	}
+	return typeByteArray
+}
+
+func int64Of(v interface{}, k kind) (int64, error) {
+	if k == kindTime {
+		t, err := time.Parse(time.RFC3339Nano, v.(string))
+		return t.UnixMicro(), err
+	}
+	return strconv.ParseInt(string(v.(json.Number)), 10, 64)
+}
+
+// stringOf returns the value of a string column, with objects and
+// lists as JSON.
+func stringOf(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// packBooleans returns the booleans bit-packed, the first in the least
+// significant bit.
+func packBooleans(v []bool) []byte {
+	b := make([]byte, (len(v)+7)/8)
+	for i, e := range v {
+		if e {
+			b[i/8] |= 1 << (i % 8)
+		}
+	}
+	return b
+}
+
+// rle returns the levels with the RLE encoding of the RLE/bit-packing
+// hybrid, a run per repeated level.
+func rle(levels []int, bitWidth int) []byte {
+	var b bytes.Buffer
+	var buf [binary.MaxVarintLen64]byte
+	width := (bitWidth + 7) / 8
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		b.Write(buf[:binary.PutUvarint(buf[:], uint64(j-i)<<1)])
+		for k := 0; k < width; k++ {
+			b.WriteByte(byte(levels[i] >> (8 * k)))
+		}
+		i = j
+	}
+	return b.Bytes()
+}
+
+// fileMetaData returns the footer of the file.
+func fileMetaData(root *node, meta []columnMeta, rows int64, codec int32) []byte {
+	t := &thrift{}
+	t.begin()
+	t.i32(1, 1)
+
+	var elements []*node
+	var collect func(n *node)
+	collect = func(n *node) {
+		for _, c := range n.sorted() {
+			elements = append(elements, c)
+			if !c.isColumn() {
+				collect(c)
+			}
+		}
+	}
+	collect(root)
+	t.list(2, thriftStruct, len(elements)+1)
+	t.begin()
+	t.str(4, root.name)
+	t.i32(5, int32(len(root.children)))
+	t.end()
+	for _, n := range elements {
+		t.begin()
+		if n.isColumn() {
+			t.i32(1, n.physical())
+		}
+		t.i32(3, repetitionOptional)
+		t.str(4, n.name)
+		if !n.isColumn() {
+			t.i32(5, int32(len(n.children)))
+		} else if n.physical() == typeByteArray {
+			t.i32(6, convertedUTF8)
+		} else if n.kind == kindTime {
+			t.i32(6, convertedTimestampMicros)
+		}
+		t.end()
+	}
+
+	t.i64(3, rows)
+
+	var total int64
+	for _, m := range meta {
+		total += m.uncompressed
+	}
+	t.list(4, thriftStruct, 1)
+	t.begin()
+	t.list(1, thriftStruct, len(meta))
+	for _, m := range meta {
+		t.begin()
+		t.i64(2, m.offset)
+		t.field(3, thriftStruct)
+		t.begin()
+		t.i32(1, m.node.physical())
+		t.i32s(2, []int32{encodingPlain, encodingRLE})
+		t.strs(3, m.path)
+		t.i32(4, codec)
+		t.i64(5, rows)
+		t.i64(6, m.uncompressed)
+		t.i64(7, m.compressed)
+		t.i64(9, m.offset)
+		t.end()
+		t.end()
+	}
+	t.i64(2, total)
+	t.i64(3, rows)
+	t.end()
+
+	t.str(6, "spigot")
+	t.end()
+	return t.buf.Bytes()
+}
+
+// partition returns the Hive style directory of the partition of t.
+func partition(t time.Time, by string) string {
+	t = t.UTC()
+	switch by {
+	case PartitionDaily:
+		return "dt=" + t.Format("2006-01-02")
+	case PartitionHourly:
+		return strings.Join([]string{"dt=" + t.Format("2006-01-02"), "hour=" + t.Format("15")}, "/")
+	}
+	return ""
+}
@@ -0,0 +1,265 @@
+// Package pcap records what network outputs send in a pcap file.
+//
+// Outputs with capture support have a "pcap" option, the path of the
+// pcap file.  Every connection the output makes is recorded, as the
+// IPv4 or IPv6 packets of its UDP datagrams or TCP segments, so what
+// spigot sent can be compared with what a collector received:
+//
+//	output:
+//	  type: syslog
+//	  network: tcp
+//	  host: collector.example.com
+//	  port: 514
+//	  pcap: "/var/tmp/spigot-syslog.pcap"
+//
+// Only the data the output sends is recorded, with the local and
+// remote addresses of the connection.  The packets are rebuilt from
+// the writes, not captured from the network interface, so TCP
+// connections have a synthetic handshake and acknowledgements are not
+// recorded.  TLS connections are recorded encrypted.
+package pcap
+
+import (
+	"encoding/binary"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// linkTypeRaw is the link type of packets that start with the
+	// IPv4 or IPv6 header.
+	linkTypeRaw = 101
+	snapLen     = 65535
+	mss         = 1460
+
+	protoTCP = 6
+	protoUDP = 17
+
+	flagFIN = 0x01
+	flagSYN = 0x02
+	flagPSH = 0x08
+	flagACK = 0x10
+)
+
+// Writer writes packets to a pcap file.  It is safe for concurrent
+// use.
+type Writer struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// Create creates the pcap file at path, truncating it if it exists.
+func Create(path string) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	hdr := make([]byte, 24)
+	binary.LittleEndian.PutUint32(hdr[0:], 0xa1b2c3d4)
+	binary.LittleEndian.PutUint16(hdr[4:], 2)
+	binary.LittleEndian.PutUint16(hdr[6:], 4)
+	binary.LittleEndian.PutUint32(hdr[16:], snapLen)
+	binary.LittleEndian.PutUint32(hdr[20:], linkTypeRaw)
+	if _, err := f.Write(hdr); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Writer{f: f}, nil
+}
+
+// Close closes the pcap file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.f.Close()
+}
+
+// write writes one packet record.
+func (w *Writer) write(pkt []byte) error {
+	now := time.Now()
+	rec := make([]byte, 16+len(pkt))
+	binary.LittleEndian.PutUint32(rec[0:], uint32(now.Unix()))
+	binary.LittleEndian.PutUint32(rec[4:], uint32(now.Nanosecond()/1000))
+	binary.LittleEndian.PutUint32(rec[8:], uint32(len(pkt)))
+	binary.LittleEndian.PutUint32(rec[12:], uint32(len(pkt)))
+	copy(rec[16:], pkt)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := w.f.Write(rec)
+	return err
+}
+
+// Conn returns conn, recording everything written to it.  Only TCP
+// and UDP connections are recorded, any other conn is returned as is.
+func (w *Writer) Conn(conn net.Conn) net.Conn {
+	c := &recorder{Conn: conn, w: w}
+	switch local := conn.LocalAddr().(type) {
+	case *net.TCPAddr:
+		remote, ok := conn.RemoteAddr().(*net.TCPAddr)
+		if !ok {
+			return conn
+		}
+		c.tcp = true
+		c.src, c.srcPort = local.IP, local.Port
+		c.dst, c.dstPort = remote.IP, remote.Port
+		c.handshake()
+	case *net.UDPAddr:
+		remote, ok := conn.RemoteAddr().(*net.UDPAddr)
+		if !ok {
+			return conn
+		}
+		c.src, c.srcPort = local.IP, local.Port
+		c.dst, c.dstPort = remote.IP, remote.Port
+	default:
+		return conn
+	}
+	return c
+}
+
+// recorder is a connection that records its writes.
+type recorder struct {
+	net.Conn
+	w       *Writer
+	tcp     bool
+	src     net.IP
+	dst     net.IP
+	srcPort int
+	dstPort int
+	seq     uint32
+	ack     uint32
+	once    sync.Once
+}
+
+func (c *recorder) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.record(b[:n])
+	}
+	return n, err
+}
+
+func (c *recorder) Close() error {
+	if c.tcp {
+		c.once.Do(func() {
+			_ = c.w.write(c.segment(c.src, c.dst, c.srcPort, c.dstPort, c.seq, c.ack, flagFIN|flagACK, nil))
+		})
+	}
+	return c.Conn.Close()
+}
+
+// handshake records a TCP three way handshake, so the stream of the
+// connection can be followed.
+func (c *recorder) handshake() {
+	c.seq, c.ack = 1000, 5000
+	_ = c.w.write(c.segment(c.src, c.dst, c.srcPort, c.dstPort, c.seq-1, 0, flagSYN, nil))
+	_ = c.w.write(c.segment(c.dst, c.src, c.dstPort, c.srcPort, c.ack-1, c.seq, flagSYN|flagACK, nil))
+	_ = c.w.write(c.segment(c.src, c.dst, c.srcPort, c.dstPort, c.seq, c.ack, flagACK, nil))
+}
+
+func (c *recorder) record(b []byte) {
+	if !c.tcp {
+		_ = c.w.write(packet(c.src, c.dst, protoUDP, udp(c.src, c.dst, c.srcPort, c.dstPort, b)))
+		return
+	}
+	for len(b) > 0 {
+		n := len(b)
+		if n > mss {
+			n = mss
+		}
+		_ = c.w.write(c.segment(c.src, c.dst, c.srcPort, c.dstPort, c.seq, c.ack, flagPSH|flagACK, b[:n]))
+		c.seq += uint32(n)
+		b = b[n:]
+	}
+}
+
+func (c *recorder) segment(src, dst net.IP, srcPort, dstPort int, seq, ack uint32, flags byte, payload []byte) []byte {
+	return packet(src, dst, protoTCP, tcp(src, dst, srcPort, dstPort, seq, ack, flags, payload))
+}
+
+// udp returns a UDP datagram with its checksum.
+func udp(src, dst net.IP, srcPort, dstPort int, payload []byte) []byte {
+	b := make([]byte, 8+len(payload))
+	binary.BigEndian.PutUint16(b[0:], uint16(srcPort))
+	binary.BigEndian.PutUint16(b[2:], uint16(dstPort))
+	binary.BigEndian.PutUint16(b[4:], uint16(len(b)))
+	copy(b[8:], payload)
+	c := checksum(b, pseudo(src, dst, protoUDP, len(b)))
+	if c == 0 {
+		c = 0xffff
+	}
+	binary.BigEndian.PutUint16(b[6:], c)
+	return b
+}
+
+// tcp returns a TCP segment, without options, with its checksum.
+func tcp(src, dst net.IP, srcPort, dstPort int, seq, ack uint32, flags byte, payload []byte) []byte {
+	b := make([]byte, 20+len(payload))
+	binary.BigEndian.PutUint16(b[0:], uint16(srcPort))
+	binary.BigEndian.PutUint16(b[2:], uint16(dstPort))
+	binary.BigEndian.PutUint32(b[4:], seq)
+	binary.BigEndian.PutUint32(b[8:], ack)
+	b[12] = 5 << 4
+	b[13] = flags
+	binary.BigEndian.PutUint16(b[14:], 65535)
+	copy(b[20:], payload)
+	binary.BigEndian.PutUint16(b[16:], checksum(b, pseudo(src, dst, protoTCP, len(b))))
+	return b
+}
+
+// packet returns the IPv4 or IPv6 packet of the transport segment.
+func packet(src, dst net.IP, proto byte, segment []byte) []byte {
+	if src4, dst4 := src.To4(), dst.To4(); src4 != nil && dst4 != nil {
+		pkt := make([]byte, 20+len(segment))
+		pkt[0] = 0x45
+		binary.BigEndian.PutUint16(pkt[2:], uint16(len(pkt)))
+		pkt[6] = 0x40 // don't fragment
+		pkt[8] = 64
+		pkt[9] = proto
+		copy(pkt[12:16], src4)
+		copy(pkt[16:20], dst4)
+		binary.BigEndian.PutUint16(pkt[10:], checksum(pkt[:20], 0))
+		copy(pkt[20:], segment)
+		return pkt
+	}
+	pkt := make([]byte, 40+len(segment))
+	pkt[0] = 0x60
+	binary.BigEndian.PutUint16(pkt[4:], uint16(len(segment)))
+	pkt[6] = proto
+	pkt[7] = 64
+	copy(pkt[8:24], src.To16())
+	copy(pkt[24:40], dst.To16())
+	copy(pkt[40:], segment)
+	return pkt
+}
+
+// pseudo returns the sum of the pseudo header of a TCP or UDP
+// checksum.
+func pseudo(src, dst net.IP, proto byte, length int) uint32 {
+	if src4, dst4 := src.To4(), dst.To4(); src4 != nil && dst4 != nil {
+		return sum(dst4, sum(src4, 0)) + uint32(proto) + uint32(length)
+	}
+	return sum(dst.To16(), sum(src.To16(), 0)) + uint32(proto) + uint32(length)
+}
+
+// sum adds b as big endian 16 bit words to s.
+func sum(b []byte, s uint32) uint32 {
+	for i := 0; i+1 < len(b); i += 2 {
+		s += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if len(b)%2 == 1 {
+		s += uint32(b[len(b)-1]) << 8
+	}
+	return s
+}
+
+// checksum returns the internet checksum of b, starting with the sum s.
+func checksum(b []byte, s uint32) uint16 {
+	s = sum(b, s)
+	for s > 0xffff {
+		s = s>>16 + s&0xffff
+	}
+	return ^uint16(s)
+}
@@ -0,0 +1,114 @@
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readPcap returns the packets of the pcap file at path.
+func readPcap(t *testing.T, path string) [][]byte {
+	b, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.True(t, len(b) >= 24)
+	assert.Equal(t, uint32(0xa1b2c3d4), binary.LittleEndian.Uint32(b[0:]))
+	assert.Equal(t, uint32(linkTypeRaw), binary.LittleEndian.Uint32(b[20:]))
+
+	var pkts [][]byte
+	for b = b[24:]; len(b) >= 16; {
+		n := binary.LittleEndian.Uint32(b[8:])
+		pkts = append(pkts, b[16:16+n])
+		b = b[16+n:]
+	}
+	assert.Len(t, b, 0)
+	return pkts
+}
+
+func TestTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer l.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		b, _ := io.ReadAll(conn)
+		received <- b
+	}()
+
+	path := filepath.Join(t.TempDir(), "tcp.pcap")
+	w, err := Create(path)
+	assert.Nil(t, err)
+	conn, err := net.Dial("tcp", l.Addr().String())
+	assert.Nil(t, err)
+	conn = w.Conn(conn)
+	sent := "hello\n" + strings.Repeat("x", 2000)
+	_, err = io.WriteString(conn, sent[:6])
+	assert.Nil(t, err)
+	_, err = io.WriteString(conn, sent[6:])
+	assert.Nil(t, err)
+	assert.Nil(t, conn.Close())
+	assert.Nil(t, w.Close())
+	assert.Equal(t, sent, string(<-received))
+
+	pkts := readPcap(t, path)
+	assert.Len(t, pkts, 3+3+1)
+	flags := []byte{flagSYN, flagSYN | flagACK, flagACK, flagPSH | flagACK, flagPSH | flagACK, flagPSH | flagACK, flagFIN | flagACK}
+	var stream bytes.Buffer
+	for i, pkt := range pkts {
+		assert.Equal(t, byte(0x45), pkt[0])
+		assert.Equal(t, uint16(0), checksum(pkt[:20], 0), "ip checksum %d", i)
+		seg := pkt[20:]
+		assert.Equal(t, uint16(0), checksum(seg, pseudo(pkt[12:16], pkt[16:20], protoTCP, len(seg))), "tcp checksum %d", i)
+		assert.Equal(t, flags[i], seg[13], "flags %d", i)
+		stream.Write(seg[20:])
+	}
+	assert.Equal(t, sent, stream.String())
+	assert.Equal(t, uint32(1000+6), binary.BigEndian.Uint32(pkts[4][20+4:]), "sequence number")
+}
+
+func TestUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer pc.Close()
+
+	path := filepath.Join(t.TempDir(), "udp.pcap")
+	w, err := Create(path)
+	assert.Nil(t, err)
+	conn, err := net.Dial("udp", pc.LocalAddr().String())
+	assert.Nil(t, err)
+	conn = w.Conn(conn)
+	_, err = io.WriteString(conn, "<13>hello")
+	assert.Nil(t, err)
+	assert.Nil(t, conn.Close())
+	assert.Nil(t, w.Close())
+
+	pkts := readPcap(t, path)
+	assert.Len(t, pkts, 1)
+	pkt := pkts[0]
+	assert.Equal(t, byte(protoUDP), pkt[9])
+	seg := pkt[20:]
+	assert.Equal(t, uint16(pc.LocalAddr().(*net.UDPAddr).Port), binary.BigEndian.Uint16(seg[2:]))
+	assert.Equal(t, uint16(0), checksum(seg, pseudo(pkt[12:16], pkt[16:20], protoUDP, len(seg))))
+	assert.Equal(t, "<13>hello", string(seg[8:]))
+}
+
+func TestIPv6(t *testing.T) {
+	src, dst := net.ParseIP("2001:db8::1"), net.ParseIP("2001:db8::2")
+	pkt := packet(src, dst, protoUDP, udp(src, dst, 40000, 514, []byte("hello")))
+	assert.Len(t, pkt, 40+8+5)
+	assert.Equal(t, byte(0x60), pkt[0])
+	assert.Equal(t, uint16(8+5), binary.BigEndian.Uint16(pkt[4:]))
+	assert.Equal(t, src, net.IP(pkt[8:24]))
+	assert.Equal(t, dst, net.IP(pkt[24:40]))
+	assert.Equal(t, uint16(0), checksum(pkt[40:], pseudo(src, dst, protoUDP, 8+5)))
+}
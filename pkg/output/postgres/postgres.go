@@ -0,0 +1,420 @@
+// Package postgres implements the output of structured events to a
+// PostgreSQL or TimescaleDB table, with batched COPY FROM STDIN.
+//
+// Events must be JSON objects, such as those of generators with a
+// json format.  The columns of the table are the fields of the events
+// with their names, top level keys or dotted paths of nested objects,
+// e.g. "source.ip".  columns is optional and lists the columns that are
+// copied, by default all the columns of the table.  List them to leave
+// out columns with a DEFAULT, such as serial ids, or generated
+// columns.  Fields that are not set are NULL.  Objects and lists are
+// JSON, for json and jsonb columns, and the other values are their
+// text, so times must be times Postgres parses, such as RFC 3339.
+//
+// The events are copied when batch_size events are buffered, with
+// each new interval and on close.
+//
+//	output:
+//	  type: postgres
+//	  address: "localhost:5432"
+//	  database: postgres
+//	  username: postgres
+//	  password: secret
+//	  table: public.logs
+//	  columns: ["@timestamp", "source.ip", "message"]
+//	  batch_size: 10000
+//
+// table is required, and is quoted, so it is case sensitive, with an
+// optional schema.  address is localhost:5432, database and username
+// are postgres by default.  The password is sent as the server asks,
+// in clear text, as an MD5 hash or with SCRAM-SHA-256.  The
+// connection is not encrypted.
+package postgres
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// Name is the name of the output in the configuration file and registry.
+const Name = "postgres"
+
+// dialTimeout is the timeout of connecting to the server.
+const dialTimeout = 30 * time.Second
+
+// Output buffers the events until they are copied.
+type Output struct {
+	c       config
+	conn    net.Conn
+	r       *bufio.Reader
+	columns []string
+	events  []map[string]interface{}
+}
+
+func init() {
+	output.Register(Name, New)
+}
+
+// New is the Factory for creating a new postgres output.  It connects
+// to the server, and looks up the columns of the table if columns is
+// not set.
+func New(cfg *ucfg.Config) (output.Output, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	o := &Output{c: c, columns: c.Columns}
+	if err := o.connect(); err != nil {
+		return nil, err
+	}
+	if o.columns == nil {
+		columns, err := o.tableColumns()
+		if err != nil {
+			o.conn.Close()
+			return nil, err
+		}
+		o.columns = columns
+	}
+	return o, nil
+}
+
+// Write buffers the event, and copies the buffered events when there
+// are batch_size of them.
+func (o *Output) Write(b []byte) (int, error) {
+	event, err := output.Event(Name, b)
+	if err != nil {
+		return 0, err
+	}
+	o.events = append(o.events, event)
+	if len(o.events) >= o.c.BatchSize {
+		if err := o.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// connect connects to the server and authenticates.
+func (o *Output) connect() error {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", o.c.Address)
+	if err != nil {
+		return err
+	}
+	o.conn, o.r = conn, bufio.NewReader(conn)
+
+	m := newMessage(0)
+	m.i32(protocolVersion)
+	m.str("user")
+	m.str(o.c.Username)
+	m.str("database")
+	m.str(o.c.Database)
+	m.str("application_name")
+	m.str("spigot")
+	m.WriteByte(0)
+	if err = o.send(m); err == nil {
+		err = o.authenticate()
+	}
+	if err != nil {
+		conn.Close()
+		o.conn = nil
+		return err
+	}
+	return nil
+}
+
+// authenticate answers the authentication requests of the server,
+// until it is ready for queries.
+func (o *Output) authenticate() error {
+	var s *scram
+	for {
+		typ, b, err := readMessage(o.r)
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case msgErrorResponse:
+			return serverError(b)
+		case msgReadyForQuery:
+			return nil
+		case msgAuthentication:
+		default:
+			// parameter status, backend key data and notices
+			continue
+		}
+
+		m := newMessage(msgPassword)
+		switch req := b.i32(); req {
+		case authOK:
+			continue
+		case authCleartext:
+			m.str(o.c.Password)
+		case authMD5:
+			m.str(md5Password(o.c.Username, o.c.Password, b.next(4)))
+		case authSASL:
+			var mechanisms []string
+			for mech := b.str(); mech != ""; mech = b.str() {
+				mechanisms = append(mechanisms, mech)
+			}
+			if !contains(mechanisms, "SCRAM-SHA-256") {
+				return fmt.Errorf("postgres server expected an unsupported authentication mechanism '%s'", strings.Join(mechanisms, ", "))
+			}
+			nonce := make([]byte, 18)
+			if _, err := rand.Read(nonce); err != nil {
+				return err
+			}
+			s = newSCRAM("", o.c.Password, base64.StdEncoding.EncodeToString(nonce))
+			m.str("SCRAM-SHA-256")
+			m.i32(int32(len(s.first())))
+			m.WriteString(s.first())
+		case authSASLContinue:
+			if s == nil {
+				return fmt.Errorf("unexpected postgres SASL message")
+			}
+			final, err := s.final(string(b.b))
+			if err != nil {
+				return err
+			}
+			m.WriteString(final)
+		case authSASLFinal:
+			if s == nil {
+				return fmt.Errorf("unexpected postgres SASL message")
+			}
+			if err := s.verify(string(b.b)); err != nil {
+				return err
+			}
+			continue
+		default:
+			return fmt.Errorf("unsupported postgres authentication request %d", req)
+		}
+		if err := o.send(m); err != nil {
+			return err
+		}
+	}
+}
+
+// md5Password is the password of the MD5 authentication,
+// "md5" + md5(md5(password + user) + salt).
+func md5Password(user, password string, salt []byte) string {
+	inner := md5.Sum([]byte(password + user))
+	outer := md5.Sum(append([]byte(hex.EncodeToString(inner[:])), salt...))
+	return "md5" + hex.EncodeToString(outer[:])
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// tableColumns returns the columns of the table, in their order.
+func (o *Output) tableColumns() ([]string, error) {
+	m := newMessage(msgQuery)
+	m.str("SELECT attname FROM pg_attribute WHERE attrelid = " + literal(quoteTable(o.c.Table)) +
+		"::regclass AND attnum > 0 AND NOT attisdropped ORDER BY attnum")
+	if err := o.send(m); err != nil {
+		return nil, err
+	}
+	var columns []string
+	var qerr error
+	for {
+		typ, b, err := readMessage(o.r)
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case msgDataRow:
+			if b.i16() == 1 {
+				columns = append(columns, string(b.next(int(b.i32()))))
+			}
+		case msgErrorResponse:
+			qerr = serverError(b)
+		case msgReadyForQuery:
+			if qerr == nil && len(columns) == 0 {
+				qerr = fmt.Errorf("postgres table '%s' has no columns", o.c.Table)
+			}
+			return columns, qerr
+		}
+	}
+}
+
+// flush copies the buffered events.  The connection is closed after
+// an error, the next copy connects again.
+func (o *Output) flush() error {
+	events := o.events
+	o.events = nil
+	if len(events) == 0 {
+		return nil
+	}
+	if o.conn == nil {
+		if err := o.connect(); err != nil {
+			return err
+		}
+	}
+	if err := o.copy(events); err != nil {
+		o.conn.Close()
+		o.conn = nil
+		return err
+	}
+	return nil
+}
+
+// copy sends the events as the rows of a COPY FROM STDIN in the text
+// format, and waits for the server to be ready for the next query.
+func (o *Output) copy(events []map[string]interface{}) error {
+	quoted := make([]string, len(o.columns))
+	for i, c := range o.columns {
+		quoted[i] = quote(c)
+	}
+	m := newMessage(msgQuery)
+	m.str("COPY " + quoteTable(o.c.Table) + " (" + strings.Join(quoted, ", ") + ") FROM STDIN")
+	if err := o.send(m); err != nil {
+		return err
+	}
+	if err := o.copyIn(); err != nil {
+		return err
+	}
+
+	var data []byte
+	var row strings.Builder
+	for _, e := range events {
+		row.Reset()
+		for i, c := range o.columns {
+			if i > 0 {
+				row.WriteByte('\t')
+			}
+			v, _ := output.Field(e, c)
+			s, err := text(v)
+			if err != nil {
+				return fmt.Errorf("postgres column '%s': %w", c, err)
+			}
+			row.WriteString(s)
+		}
+		row.WriteByte('\n')
+		m := newMessage(msgCopyData)
+		m.WriteString(row.String())
+		data = append(data, m.bytes()...)
+	}
+	data = append(data, newMessage(msgCopyDone).bytes()...)
+	if _, err := o.conn.Write(data); err != nil {
+		return err
+	}
+
+	var qerr error
+	for {
+		typ, b, err := readMessage(o.r)
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case msgErrorResponse:
+			if qerr == nil {
+				qerr = serverError(b)
+			}
+		case msgReadyForQuery:
+			return qerr
+		}
+	}
+}
+
+// copyIn waits for the server to start the copy.  An error of the
+// query is followed by ReadyForQuery.
+func (o *Output) copyIn() error {
+	var qerr error
+	for {
+		typ, b, err := readMessage(o.r)
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case msgCopyInResponse:
+			return nil
+		case msgErrorResponse:
+			qerr = serverError(b)
+		case msgReadyForQuery:
+			if qerr == nil {
+				qerr = fmt.Errorf("postgres server did not start the copy")
+			}
+			return qerr
+		}
+	}
+}
+
+func (o *Output) send(m *message) error {
+	_, err := o.conn.Write(m.bytes())
+	return err
+}
+
+var escape = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// text returns the value in the text format of COPY, with objects and
+// lists as JSON.
+func text(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return `\N`, nil
+	case string:
+		return escape.Replace(v), nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	b, err := json.Marshal(v)
+	return escape.Replace(string(b)), err
+}
+
+// quote returns the name as a quoted identifier.
+func quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteTable returns the table, with an optional schema, as quoted
+// identifiers.
+func quoteTable(table string) string {
+	parts := strings.SplitN(table, ".", 2)
+	for i, p := range parts {
+		parts[i] = quote(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+// literal returns s as a string literal.
+func literal(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Close copies the buffered events and closes the connection.
+func (o *Output) Close() error {
+	err := o.flush()
+	if o.conn != nil {
+		_ = o.send(newMessage(msgTerminate))
+		o.conn.Close()
+		o.conn = nil
+	}
+	return err
+}
+
+// NewInterval copies the buffered events, so each interval is a batch
+// of its own.
+func (o *Output) NewInterval() error {
+	return o.flush()
+}
@@ -0,0 +1,224 @@
+package postgres
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCopy is a COPY the fake server received.
+type fakeCopy struct {
+	query string
+	rows  []string
+}
+
+// server is a fake Postgres server, for the copies of one connection
+// into the table logs.  It asks for auth, an authentication request.
+func server(t *testing.T, auth int32, copies chan<- fakeCopy) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		send := func(typ byte, payload ...interface{}) {
+			m := newMessage(typ)
+			for _, p := range payload {
+				switch p := p.(type) {
+				case int32:
+					m.i32(p)
+				case int16:
+					m.Write(binary.BigEndian.AppendUint16(nil, uint16(p)))
+				case string:
+					m.str(p)
+				case []byte:
+					m.Write(p)
+				}
+			}
+			_, _ = conn.Write(m.bytes())
+		}
+		fail := func(code, message string) {
+			send(msgErrorResponse, []byte("S"), "ERROR", []byte("C"), code, []byte("M"), message, []byte{0})
+			send(msgReadyForQuery, []byte("I"))
+		}
+
+		var h [4]byte
+		if _, err := io.ReadFull(r, h[:]); err != nil {
+			return
+		}
+		startup := make([]byte, binary.BigEndian.Uint32(h[:])-4)
+		if _, err := io.ReadFull(r, startup); err != nil {
+			return
+		}
+		params := strings.Split(string(startup[4:]), "\x00")
+		user := params[1]
+
+		salt := []byte{1, 2, 3, 4}
+		send(msgAuthentication, auth, salt)
+		_, b, err := readMessage(r)
+		if err != nil {
+			return
+		}
+		password := b.str()
+		if (auth == authCleartext && password != "secret") || (auth == authMD5 && password != md5Password(user, "secret", salt)) {
+			send(msgErrorResponse, []byte("S"), "FATAL", []byte("C"), "28P01", []byte("M"), `password authentication failed for user "`+user+`"`, []byte{0})
+			return
+		}
+		send(msgAuthentication, int32(authOK))
+		send('S', "server_version", "16.2")
+		send('K', int32(1), int32(2))
+		send(msgReadyForQuery, []byte("I"))
+
+		for {
+			typ, b, err := readMessage(r)
+			if err != nil || typ != msgQuery {
+				return
+			}
+			query := b.str()
+			switch {
+			case strings.HasPrefix(query, "SELECT attname"):
+				if !strings.Contains(query, `'"public"."logs"'::regclass`) {
+					fail("42P01", `relation "missing" does not exist`)
+					continue
+				}
+				send(msgRowDescription, int16(1), "attname", int32(0), int16(0), int32(19), int16(64), int32(-1), int16(0))
+				for _, c := range []string{"@timestamp", "message", "source.ip", "event"} {
+					send(msgDataRow, int16(1), int32(len(c)), []byte(c))
+				}
+				send(msgCommandComplete, "SELECT 4")
+				send(msgReadyForQuery, []byte("I"))
+			case strings.HasPrefix(query, `COPY "public"."logs"`):
+				send(msgCopyInResponse, []byte{0}, int16(0))
+				c := fakeCopy{query: query}
+				for {
+					typ, b, err := readMessage(r)
+					if err != nil {
+						return
+					}
+					if typ == msgCopyDone {
+						break
+					}
+					c.rows = append(c.rows, string(b.b))
+				}
+				send(msgCommandComplete, "COPY")
+				send(msgReadyForQuery, []byte("I"))
+				copies <- c
+			default:
+				fail("42P01", `relation "missing" does not exist`)
+			}
+		}
+	}()
+	return l.Addr().String()
+}
+
+// msgRowDescription is only sent by the fake server.
+const msgRowDescription = 'T'
+
+func TestWrite(t *testing.T) {
+	for _, auth := range []int32{authCleartext, authMD5} {
+		copies := make(chan fakeCopy, 2)
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "address": server(t, auth, copies), "table": "public.logs", "password": "secret", "batch_size": 2})
+		assert.Nil(t, err)
+		out, err := New(c)
+		assert.Nil(t, err)
+
+		for _, e := range []string{
+			`{"@timestamp":"2024-03-04T12:00:00.123Z","message":"tab\there\nand \\ more","source":{"ip":"10.0.0.1"},"event":{"code":4624}}`,
+			`{"@timestamp":"2024-03-04T12:00:01Z","message":null,"source.ip":"10.0.0.2","event":[1,2]}`,
+			`{"message":"three"}`,
+		} {
+			_, err := out.Write([]byte(e))
+			assert.Nil(t, err)
+		}
+		in := <-copies
+		assert.Equal(t, `COPY "public"."logs" ("@timestamp", "message", "source.ip", "event") FROM STDIN`, in.query)
+		assert.Equal(t, []string{
+			"2024-03-04T12:00:00.123Z\ttab\\there\\nand \\\\ more\t10.0.0.1\t{\"code\":4624}\n",
+			"2024-03-04T12:00:01Z\t\\N\t10.0.0.2\t[1,2]\n",
+		}, in.rows)
+
+		assert.Nil(t, out.Close())
+		in = <-copies
+		assert.Equal(t, []string{"\\N\tthree\t\\N\t\\N\n"}, in.rows)
+	}
+}
+
+func TestWriteColumns(t *testing.T) {
+	copies := make(chan fakeCopy, 1)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "address": server(t, authCleartext, copies), "table": "public.logs", "password": "secret", "columns": []string{"message", "ok"}})
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+	_, err = out.Write([]byte(`{"message":"hello","ok":true}`))
+	assert.Nil(t, err)
+	assert.Nil(t, out.NewInterval())
+	in := <-copies
+	assert.Equal(t, `COPY "public"."logs" ("message", "ok") FROM STDIN`, in.query)
+	assert.Equal(t, []string{"hello\ttrue\n"}, in.rows)
+
+	_, err = out.Write([]byte("not json"))
+	assert.EqualError(t, err, "postgres output expected a JSON object, use a json format of the generator")
+}
+
+func TestServerError(t *testing.T) {
+	copies := make(chan fakeCopy, 1)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "address": server(t, authMD5, copies), "table": "public.logs", "password": "wrong"})
+	assert.Nil(t, err)
+	_, err = New(c)
+	assert.EqualError(t, err, `postgres FATAL 28P01: password authentication failed for user "postgres"`)
+
+	c, err = ucfg.NewFrom(map[string]interface{}{"type": Name, "address": server(t, authMD5, copies), "table": "missing", "password": "secret"})
+	assert.Nil(t, err)
+	_, err = New(c)
+	assert.EqualError(t, err, `postgres ERROR 42P01: relation "missing" does not exist`)
+
+	c, err = ucfg.NewFrom(map[string]interface{}{"type": Name, "address": server(t, authMD5, copies), "table": "missing", "password": "secret", "columns": []string{"message"}})
+	assert.Nil(t, err)
+	out, err := New(c)
+	assert.Nil(t, err)
+	_, err = out.Write([]byte(`{"message":"hello"}`))
+	assert.Nil(t, err)
+	assert.EqualError(t, out.NewInterval(), `postgres ERROR 42P01: relation "missing" does not exist`)
+}
+
+// TestSCRAM is the example exchange of RFC 7677.
+func TestSCRAM(t *testing.T) {
+	s := newSCRAM("user", "pencil", "rOprNGfwEbeRWgbNEkqO")
+	assert.Equal(t, "n,,n=user,r=rOprNGfwEbeRWgbNEkqO", s.first())
+	final, err := s.final("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	assert.Nil(t, err)
+	assert.Equal(t, "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=", final)
+	assert.Nil(t, s.verify("v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4="))
+	assert.EqualError(t, s.verify("v=AAAA"), "postgres SCRAM authentication failed: invalid server signature")
+
+	_, err = newSCRAM("", "pencil", "abc").final("r=xyz,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096")
+	assert.EqualError(t, err, "postgres SCRAM authentication failed: invalid server nonce")
+}
+
+func TestText(t *testing.T) {
+	tests := map[string]struct {
+		v    interface{}
+		want string
+	}{
+		"Null":   {v: nil, want: `\N`},
+		"String": {v: "a\tb\r\n\\", want: `a\tb\r\n\\`},
+		"Bool":   {v: false, want: "false"},
+		"Object": {v: map[string]interface{}{"k": "a\tb"}, want: `{"k":"a\\tb"}`},
+	}
+	for name, tc := range tests {
+		got, err := text(tc.v)
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.want, got, name)
+	}
+	assert.Equal(t, `"Logs"."a""b"`, quoteTable(`Logs.a"b`))
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// protocolVersion is version 3.0 of the frontend/backend protocol.
+const protocolVersion = 3 << 16
+
+// Messages of the frontend.
+const (
+	msgPassword  = 'p'
+	msgQuery     = 'Q'
+	msgCopyData  = 'd'
+	msgCopyDone  = 'c'
+	msgTerminate = 'X'
+)
+
+// Messages of the backend.
+const (
+	msgAuthentication  = 'R'
+	msgCommandComplete = 'C'
+	msgCopyInResponse  = 'G'
+	msgDataRow         = 'D'
+	msgErrorResponse   = 'E'
+	msgReadyForQuery   = 'Z'
+)
+
+// Authentication requests of the backend.
+const (
+	authOK           = 0
+	authCleartext    = 3
+	authMD5          = 5
+	authSASL         = 10
+	authSASLContinue = 11
+	authSASLFinal    = 12
+)
+
+// maxMessage is the largest message of the backend that is read.
+const maxMessage = 1 << 30
+
+// message encodes a message of the frontend.  The startup message has
+// no type.
+type message struct {
+	bytes.Buffer
+	start int
+}
+
+func newMessage(typ byte) *message {
+	m := &message{}
+	if typ != 0 {
+		m.WriteByte(typ)
+		m.start = 1
+	}
+	m.Write([]byte{0, 0, 0, 0})
+	return m
+}
+
+func (m *message) i32(v int32) {
+	m.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+}
+
+func (m *message) str(s string) {
+	m.WriteString(s)
+	m.WriteByte(0)
+}
+
+// bytes returns the message with its length.
+func (m *message) bytes() []byte {
+	b := m.Bytes()
+	binary.BigEndian.PutUint32(b[m.start:], uint32(len(b)-m.start))
+	return b
+}
+
+// readMessage reads the type and body of the next message of the
+// backend.
+func readMessage(r *bufio.Reader) (byte, *body, error) {
+	var h [5]byte
+	if _, err := io.ReadFull(r, h[:]); err != nil {
+		return 0, nil, err
+	}
+	n := int64(binary.BigEndian.Uint32(h[1:])) - 4
+	if n < 0 || n > maxMessage {
+		return 0, nil, fmt.Errorf("postgres message of %d bytes is invalid", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return 0, nil, err
+	}
+	return h[0], &body{b: b}, nil
+}
+
+// body decodes the body of a message of the backend.  Values past the
+// end of the body are zero.
+type body struct {
+	b []byte
+}
+
+func (b *body) next(n int) []byte {
+	if n < 0 || n > len(b.b) {
+		b.b = nil
+		return nil
+	}
+	v := b.b[:n]
+	b.b = b.b[n:]
+	return v
+}
+
+func (b *body) i16() int16 {
+	v := b.next(2)
+	if v == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(v))
+}
+
+func (b *body) i32() int32 {
+	v := b.next(4)
+	if v == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(v))
+}
+
+func (b *body) str() string {
+	i := bytes.IndexByte(b.b, 0)
+	if i < 0 {
+		b.b = nil
+		return ""
+	}
+	s := string(b.b[:i])
+	b.b = b.b[i+1:]
+	return s
+}
+
+// serverError returns the error of an ErrorResponse.
+func serverError(b *body) error {
+	fields := make(map[byte]string)
+	for len(b.b) > 0 && b.b[0] != 0 {
+		typ := b.next(1)[0]
+		fields[typ] = b.str()
+	}
+	return fmt.Errorf("postgres %s %s: %s", fields['S'], fields['C'], fields['M'])
+}
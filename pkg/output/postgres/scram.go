@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// scram is the client of a SCRAM-SHA-256 exchange, RFC 5802 and RFC
+// 7677, without channel binding.
+type scram struct {
+	password        string
+	nonce           string
+	clientFirstBare string
+	serverSignature []byte
+}
+
+// newSCRAM returns the client of an exchange.  The server ignores the
+// user of the messages, it is the user of the startup message.
+func newSCRAM(user, password, nonce string) *scram {
+	user = strings.NewReplacer("=", "=3D", ",", "=2C").Replace(user)
+	return &scram{
+		password:        password,
+		nonce:           nonce,
+		clientFirstBare: "n=" + user + ",r=" + nonce,
+	}
+}
+
+// first returns the client-first-message.
+func (s *scram) first() string {
+	return "n,," + s.clientFirstBare
+}
+
+// final returns the client-final-message of the server-first-message.
+func (s *scram) final(serverFirst string) (string, error) {
+	attrs := scramAttributes(serverFirst)
+	nonce := attrs["r"]
+	if !strings.HasPrefix(nonce, s.nonce) || len(nonce) == len(s.nonce) {
+		return "", fmt.Errorf("postgres SCRAM authentication failed: invalid server nonce")
+	}
+	salt, err := base64.StdEncoding.DecodeString(attrs["s"])
+	if err != nil {
+		return "", fmt.Errorf("postgres SCRAM authentication failed: invalid salt")
+	}
+	iterations, err := strconv.Atoi(attrs["i"])
+	if err != nil || iterations <= 0 {
+		return "", fmt.Errorf("postgres SCRAM authentication failed: invalid iteration count")
+	}
+
+	salted := pbkdf2([]byte(s.password), salt, iterations)
+	clientKey := hmacSHA256(salted, "Client Key")
+	storedKey := sha256.Sum256(clientKey)
+	withoutProof := "c=biws,r=" + nonce
+	authMessage := s.clientFirstBare + "," + serverFirst + "," + withoutProof
+	proof := hmacSHA256(storedKey[:], authMessage)
+	for i := range proof {
+		proof[i] ^= clientKey[i]
+	}
+	s.serverSignature = hmacSHA256(hmacSHA256(salted, "Server Key"), authMessage)
+	return withoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+// verify checks the signature of the server-final-message.
+func (s *scram) verify(serverFinal string) error {
+	attrs := scramAttributes(serverFinal)
+	if e, ok := attrs["e"]; ok {
+		return fmt.Errorf("postgres SCRAM authentication failed: %s", e)
+	}
+	v, err := base64.StdEncoding.DecodeString(attrs["v"])
+	if err != nil || !hmac.Equal(v, s.serverSignature) {
+		return fmt.Errorf("postgres SCRAM authentication failed: invalid server signature")
+	}
+	return nil
+}
+
+func scramAttributes(msg string) map[string]string {
+	attrs := make(map[string]string)
+	for _, a := range strings.Split(msg, ",") {
+		if k, v, ok := strings.Cut(a, "="); ok {
+			attrs[k] = v
+		}
+	}
+	return attrs
+}
+
+func hmacSHA256(key []byte, msg string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(msg))
+	return h.Sum(nil)
+}
+
+// pbkdf2 is Hi of RFC 5802, PBKDF2 with HMAC-SHA-256 and a key of one
+// block.
+func pbkdf2(password, salt []byte, iterations int) []byte {
+	h := hmac.New(sha256.New, password)
+	h.Write(salt)
+	h.Write(binary.BigEndian.AppendUint32(nil, 1))
+	u := h.Sum(nil)
+	key := append([]byte(nil), u...)
+	for i := 1; i < iterations; i++ {
+		h.Reset()
+		h.Write(u)
+		u = h.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
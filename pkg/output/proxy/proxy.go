@@ -0,0 +1,227 @@
+// Package proxy connects network outputs through a SOCKS5 or HTTP
+// proxy.
+//
+// Outputs with proxy support have a "proxy" option:
+//
+//	url:      The URL of the proxy, with the scheme socks5, socks5h,
+//	          http or https, for example "socks5://proxy:1080".
+//	username: (optional) The user name for the proxy.
+//	password: (optional) The password for the proxy.
+//
+// The user name and password can also be part of the URL.  HTTP
+// proxies are used with CONNECT, so any TCP connection, not only HTTP
+// requests, can go through them.  UDP is not supported.
+//
+//	output:
+//	  type: syslog
+//	  network: tcp
+//	  host: collector.example.com
+//	  port: 514
+//	  proxy:
+//	    url: "http://egress.lab:3128"
+//	    username: "spigot"
+//	    password: "secret:vault:secret/data/egress#password"
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	xproxy "golang.org/x/net/proxy"
+)
+
+// Config is the proxy configuration of an output.
+type Config struct {
+	URL      string `config:"url"`
+	Username string `config:"username"`
+	Password string `config:"password"`
+}
+
+// ContextDialer dials connections with a context.
+type ContextDialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// Validate checks that the URL is empty or a URL of a supported proxy.
+func (c *Config) Validate() error {
+	if c.URL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a valid value for 'url': %w", c.URL, err)
+	}
+	switch u.Scheme {
+	case "socks5", "socks5h", "http", "https":
+	default:
+		return fmt.Errorf("'%s' is not a valid value for 'url' expected a socks5, socks5h, http or https URL", c.URL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("'%s' is not a valid value for 'url' expected a host", c.URL)
+	}
+	return nil
+}
+
+// Enabled returns true if a proxy is configured.
+func (c Config) Enabled() bool {
+	return c.URL != ""
+}
+
+// ProxyURL returns the URL of the proxy, with the username and
+// password as user info.  It returns nil if no proxy is configured.
+func (c Config) ProxyURL() *url.URL {
+	if !c.Enabled() {
+		return nil
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil
+	}
+	if c.Username != "" {
+		u.User = url.UserPassword(c.Username, c.Password)
+	}
+	return u
+}
+
+// HTTPProxy returns the Proxy function for an http.Transport, which
+// sends all requests through the proxy.
+func (c Config) HTTPProxy() func(*http.Request) (*url.URL, error) {
+	if !c.Enabled() {
+		return nil
+	}
+	return http.ProxyURL(c.ProxyURL())
+}
+
+// Dialer returns a dialer that connects through the proxy with
+// forward, or forward itself if no proxy is configured.
+func (c Config) Dialer(forward *net.Dialer) (ContextDialer, error) {
+	if !c.Enabled() {
+		return forward, nil
+	}
+
+	u := c.ProxyURL()
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		var auth *xproxy.Auth
+		if u.User != nil {
+			password, _ := u.User.Password()
+			auth = &xproxy.Auth{User: u.User.Username(), Password: password}
+		}
+		d, err := xproxy.SOCKS5("tcp", u.Host, auth, forward)
+		if err != nil {
+			return nil, err
+		}
+		return tcpOnly{d.(ContextDialer)}, nil
+	default:
+		return &connectDialer{proxy: u, forward: forward}, nil
+	}
+}
+
+// tcpOnly rejects networks other than TCP, which are not supported by
+// the SOCKS5 dialer.
+type tcpOnly struct {
+	ContextDialer
+}
+
+func (t tcpOnly) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if err := checkNetwork(network); err != nil {
+		return nil, err
+	}
+	return t.ContextDialer.DialContext(ctx, network, address)
+}
+
+// connectDialer dials through an HTTP proxy with CONNECT.
+type connectDialer struct {
+	proxy   *url.URL
+	forward *net.Dialer
+}
+
+func (d *connectDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if err := checkNetwork(network); err != nil {
+		return nil, err
+	}
+
+	host := d.proxy.Host
+	if d.proxy.Port() == "" {
+		port := "80"
+		if d.proxy.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(d.proxy.Hostname(), port)
+	}
+	conn, err := d.forward.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if d.proxy.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: d.proxy.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if d.proxy.User != nil {
+		password, _ := d.proxy.User.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(d.proxy.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s returned %s for CONNECT %s", d.proxy.Host, resp.Status, address)
+	}
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn reads the data the proxy sent after its response.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (b *bufferedConn) Read(p []byte) (int, error) {
+	return b.r.Read(p)
+}
+
+func checkNetwork(network string) error {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		return nil
+	default:
+		return fmt.Errorf("'%s' network can not be used with a proxy", network)
+	}
+}
@@ -0,0 +1,110 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Empty": {
+			c: map[string]interface{}{},
+		},
+		"SOCKS5": {
+			c: map[string]interface{}{"url": "socks5://proxy:1080", "username": "u", "password": "p"},
+		},
+		"HTTP": {
+			c: map[string]interface{}{"url": "http://u:p@proxy:3128"},
+		},
+		"Invalid Scheme": {
+			c:           map[string]interface{}{"url": "ftp://proxy:21"},
+			hasError:    true,
+			errorString: "'ftp://proxy:21' is not a valid value for 'url' expected a socks5, socks5h, http or https URL accessing config",
+		},
+		"No Host": {
+			c:           map[string]interface{}{"url": "socks5://"},
+			hasError:    true,
+			errorString: "'socks5://' is not a valid value for 'url' expected a host accessing config",
+		},
+	}
+	for name, tc := range tests {
+		cfg, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		c := Config{}
+		err = cfg.Unpack(&c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		} else {
+			assert.Nil(t, err, name)
+		}
+	}
+}
+
+// connectProxy serves one CONNECT request and copies the tunnel from
+// the client to out.
+func connectProxy(t *testing.T, auth string, out chan<- string) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		br := bufio.NewReader(conn)
+		req, err := http.ReadRequest(br)
+		if err != nil {
+			return
+		}
+		if req.Method != http.MethodConnect || req.Header.Get("Proxy-Authorization") != auth {
+			_, _ = io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+			return
+		}
+		_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		line, _ := br.ReadString('\n')
+		out <- req.Host + " " + line
+	}()
+	return l
+}
+
+func TestConnectDialer(t *testing.T) {
+	out := make(chan string, 1)
+	l := connectProxy(t, "Basic c3BpZ290OnMzY3IzdA==", out)
+	defer l.Close()
+
+	c := Config{URL: "http://" + l.Addr().String(), Username: "spigot", Password: "s3cr3t"}
+	d, err := c.Dialer(&net.Dialer{})
+	assert.Nil(t, err)
+	conn, err := d.DialContext(context.Background(), "tcp", "collector.example.com:514")
+	assert.Nil(t, err)
+	_, err = io.WriteString(conn, "hello\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "collector.example.com:514 hello\n", <-out)
+	conn.Close()
+
+	_, err = d.DialContext(context.Background(), "udp", "collector.example.com:514")
+	assert.EqualError(t, err, "'udp' network can not be used with a proxy")
+}
+
+func TestConnectDialerAuth(t *testing.T) {
+	l := connectProxy(t, "Basic c3BpZ290OnMzY3IzdA==", nil)
+	defer l.Close()
+
+	c := Config{URL: "http://" + l.Addr().String()}
+	d, err := c.Dialer(&net.Dialer{})
+	assert.Nil(t, err)
+	_, err = d.DialContext(context.Background(), "tcp", "collector.example.com:514")
+	assert.EqualError(t, err, "proxy "+l.Addr().String()+" returned 407 Proxy Authentication Required for CONNECT collector.example.com:514")
+}
@@ -14,9 +14,14 @@
 //	  pattern: "rally_*"
 //
 // directory and pattern are used in os.CreateTemp call
+//
+// Each log entry is the "message" string of a JSON object, so the line
+// breaks of multi-line entries are escaped.  Binary log entries are
+// encoded as base64.
 package rally
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"io"
 	"os"
@@ -92,6 +97,12 @@ func (r *Output) Write(b []byte) (int, error) {
 	return n + k, err
 }
 
+// WriteBinary writes the binary log entry like Write, with the
+// message encoded as base64.
+func (r *Output) WriteBinary(b []byte) (int, error) {
+	return r.Write([]byte(base64.StdEncoding.EncodeToString(b)))
+}
+
 // Close closes the io.WriteCloser.  Writes after this will fail.
 func (r *Output) Close() error {
 	return r.pWriteCloser.Close()
@@ -29,6 +29,10 @@ func TestWrite(t *testing.T) {
 			input: []string{"a", "b"},
 			want:  "{\"message\":\"a\"}\n{\"message\":\"b\"}\n",
 		},
+		"MultiLine": {
+			input: []string{"panic: oops\n\tmain.go:10"},
+			want:  "{\"message\":\"panic: oops\\n\\tmain.go:10\"}\n",
+		},
 	}
 	for name, tc := range tests {
 		var buf bytes.Buffer
@@ -44,3 +48,13 @@ func TestWrite(t *testing.T) {
 		assert.Equal(t, []byte(tc.want), buf.Bytes(), name)
 	}
 }
+
+func TestWriteBinary(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Output{
+		pWriteCloser: &myWriteCloser{&buf},
+	}
+	_, err := r.WriteBinary([]byte{0x00, 0x0a, 0xff})
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"message\":\"AAr/\"}\n", buf.String())
+}
@@ -1,19 +1,45 @@
 package s3
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leehinman/spigot/pkg/output"
+)
 
 type config struct {
-	Type      string `config:"type" validate:"required"`
-	Bucket    string `config:"bucket" validate:"required"`
-	Region    string `config:"region" validate:"required"`
-	Delimiter string `config:"delimiter"`
-	Prefix    string `config:"prefix" validate:"required"`
+	Type        string                `config:"type" validate:"required"`
+	Bucket      string                `config:"bucket" validate:"required"`
+	Region      string                `config:"region" validate:"required"`
+	Delimiter   string                `config:"delimiter"`
+	Prefix      string                `config:"prefix" validate:"required"`
+	Suffix      string                `config:"suffix"`
+	Compression string                `config:"compression"`
+	MaxSize     int                   `config:"max_size"`
+	MaxAge      time.Duration         `config:"max_age"`
+	Notify      notifyConfig          `config:"notify"`
+	Delivery    output.DeliveryConfig `config:"delivery"`
+}
+
+// notifyConfig is the destination of the event notifications.
+type notifyConfig struct {
+	SQSQueueURL string `config:"sqs_queue_url"`
+	SNSTopicARN string `config:"sns_topic_arn"`
 }
 
+// Compressions of the objects.
+const (
+	CompressionGzip = "gzip"
+	CompressionNone = "none"
+)
+
 func defaultConfig() config {
 	return config{
-		Type:      Name,
-		Delimiter: "\n",
+		Type:        Name,
+		Delimiter:   "\n",
+		Compression: CompressionGzip,
+		Delivery:    output.DefaultDeliveryConfig(),
 	}
 }
 
@@ -21,5 +47,23 @@ func (c *config) Validate() error {
 	if c.Type != Name {
 		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
 	}
+	if _, err := output.ParseDatePattern("prefix", c.Prefix); err != nil {
+		return err
+	}
+	if c.Compression != CompressionGzip && c.Compression != CompressionNone {
+		return fmt.Errorf("'%s' is not a valid value for 'compression' expected '%s' or '%s'", c.Compression, CompressionGzip, CompressionNone)
+	}
+	if c.MaxSize < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'max_size' expected 0 or more", c.MaxSize)
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'max_age' expected 0 or more", c.MaxAge)
+	}
+	if c.Notify.SQSQueueURL != "" && c.Notify.SNSTopicARN != "" {
+		return fmt.Errorf("'notify.sqs_queue_url' and 'notify.sns_topic_arn' can not be used together")
+	}
+	if arn := c.Notify.SNSTopicARN; arn != "" && !strings.HasPrefix(arn, "arn:") {
+		return fmt.Errorf("'%s' is not a valid value for 'notify.sns_topic_arn' expected an ARN", arn)
+	}
 	return nil
 }
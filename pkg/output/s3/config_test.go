@@ -23,6 +23,56 @@ func TestConfigs(t *testing.T) {
 			hasError:    true,
 			errorString: "'Bob' is not a valid value for 'type' expected 's3' accessing config",
 		},
+		"Valid Options": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "region": "us-west", "prefix": "AWSLogs/%{+yyyy/MM/dd}/test", "suffix": ".json", "compression": "none", "max_size": 1048576, "max_age": "5m"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Prefix": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "region": "us-west", "prefix": "AWSLogs/%{+yyyy/MM/dd"},
+			hasError:    true,
+			errorString: "'AWSLogs/%{+yyyy/MM/dd' is not a valid value for 'prefix' expected a '}' after '%{+' accessing config",
+		},
+		"Invalid Compression": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "region": "us-west", "prefix": "test", "compression": "zstd"},
+			hasError:    true,
+			errorString: "'zstd' is not a valid value for 'compression' expected 'gzip' or 'none' accessing config",
+		},
+		"Invalid Max Size": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "region": "us-west", "prefix": "test", "max_size": -1},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'max_size' expected 0 or more accessing config",
+		},
+		"Invalid Max Age": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "region": "us-west", "prefix": "test", "max_age": "-1s"},
+			hasError:    true,
+			errorString: "'-1s' is not a valid value for 'max_age' expected 0 or more accessing config",
+		},
+		"Valid Notify": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "region": "us-west", "prefix": "test", "notify": map[string]interface{}{"sns_topic_arn": "arn:aws:sns:us-west-2:123456789012:logs"}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Delivery": {
+			c:           map[string]interface{}{"type": Name, "bucket": "logs", "region": "us-east-1", "prefix": "test", "delivery": map[string]interface{}{"late": 10, "late_by": "15m", "overwrite": 2, "empty": 1}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Delivery": {
+			c:           map[string]interface{}{"type": Name, "bucket": "logs", "region": "us-east-1", "prefix": "test", "delivery": map[string]interface{}{"late": 101}},
+			hasError:    true,
+			errorString: "'101' is not a valid value for 'late' expected a percentage from 0 to 100 accessing 'delivery'",
+		},
+		"Invalid Notify": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "region": "us-west", "prefix": "test", "notify": map[string]interface{}{"sqs_queue_url": "https://sqs.us-west-2.amazonaws.com/123456789012/logs", "sns_topic_arn": "arn:aws:sns:us-west-2:123456789012:logs"}},
+			hasError:    true,
+			errorString: "'notify.sqs_queue_url' and 'notify.sns_topic_arn' can not be used together accessing config",
+		},
+		"Invalid SNS Topic": {
+			c:           map[string]interface{}{"type": Name, "bucket": "test", "region": "us-west", "prefix": "test", "notify": map[string]interface{}{"sns_topic_arn": "logs"}},
+			hasError:    true,
+			errorString: "'logs' is not a valid value for 'notify.sns_topic_arn' expected an ARN accessing config",
+		},
 		"No Type": {
 			c:           map[string]interface{}{"type": ""},
 			hasError:    true,
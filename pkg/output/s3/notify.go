@@ -0,0 +1,82 @@
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/uuid"
+)
+
+// newNotify returns the function sending the notifications to the
+// destination of c, or nil without one.
+func newNotify(c notifyConfig) func(body string) error {
+	switch {
+	case c.SQSQueueURL != "":
+		return func(body string) error {
+			_, err := sqsClient.SendMessage(context.TODO(), &sqs.SendMessageInput{
+				QueueUrl:    aws.String(c.SQSQueueURL),
+				MessageBody: aws.String(body),
+			})
+			return err
+		}
+	case c.SNSTopicARN != "":
+		return func(body string) error {
+			_, err := snsClient.Publish(context.TODO(), &sns.PublishInput{
+				TopicArn: aws.String(c.SNSTopicARN),
+				Subject:  aws.String("Amazon S3 Notification"),
+				Message:  aws.String(body),
+			})
+			return err
+		}
+	}
+	return nil
+}
+
+// notification returns the S3 event notification of the object key of
+// size bytes with etag, put at t.
+func (s *S3Output) notification(key string, size int, etag string, t time.Time) string {
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = url.QueryEscape(seg)
+	}
+	requestID := strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", "")[:16])
+	record := map[string]interface{}{
+		"eventVersion": "2.1",
+		"eventSource":  "aws:s3",
+		"awsRegion":    s.region,
+		"eventTime":    t.UTC().Format("2006-01-02T15:04:05.000Z"),
+		"eventName":    "ObjectCreated:Put",
+		"userIdentity": map[string]string{"principalId": "AWS:SPIGOT"},
+		"requestParameters": map[string]string{
+			"sourceIPAddress": "127.0.0.1",
+		},
+		"responseElements": map[string]string{
+			"x-amz-request-id": requestID,
+			"x-amz-id-2":       uuid.NewString(),
+		},
+		"s3": map[string]interface{}{
+			"s3SchemaVersion": "1.0",
+			"configurationId": "spigot",
+			"bucket": map[string]interface{}{
+				"name":          s.bucket,
+				"ownerIdentity": map[string]string{"principalId": "SPIGOT"},
+				"arn":           "arn:aws:s3:::" + s.bucket,
+			},
+			"object": map[string]interface{}{
+				"key":       strings.Join(segments, "/"),
+				"size":      size,
+				"eTag":      etag,
+				"sequencer": fmt.Sprintf("%016X", t.UnixNano()),
+			},
+		},
+	}
+	b, _ := json.Marshal(map[string]interface{}{"Records": []interface{}{record}})
+	return string(b)
+}
@@ -2,15 +2,58 @@
 //
 // For configuration, "type", "bucket", "region", and "prefix" are all required.
 //
-// "delimiter" is optional and controls the character written between log entries, by default "/n"
+// "delimiter" is optional and controls the character written between log entries, by default "\n"
 //
 //	output:
-//	  type: aws:s3
+//	  type: s3
 //	  bucket: "bucket_name"
 //	  region: "us-west"
-//	  delimiter: "/n"
+//	  delimiter: "\n"
 //	  prefix: "my_name"  ;; my_name_0123456789_001.gz
 //
+// The events are buffered into an object, which is uploaded with each
+// new interval and on close.  "max_size" uploads the object once the
+// events in it are of at least that many bytes before compression, and
+// "max_age" once its first event is that old, so a long run delivers many
+// objects like the services that deliver their logs to S3.  Both are
+// unlimited by default.
+//
+// "prefix" may contain date formats such as %{+yyyy/MM/dd}, of the time
+// of the first event of the object in UTC, for date partitioned keys.
+// "suffix" is appended to the key, before the ".gz" of "compression"
+// gzip, the default; with "none" the objects are not compressed.
+//
+//	output:
+//	  type: s3
+//	  bucket: "cloudtrail-logs"
+//	  region: "us-east-1"
+//	  prefix: "AWSLogs/123456789012/CloudTrail/us-east-1/%{+yyyy/MM/dd}/123456789012_CloudTrail_us-east-1"
+//	  suffix: ".json"
+//	  max_size: 1048576
+//	  max_age: 5m
+//
+// "notify" sends the S3 event notification of each new object, an
+// ObjectCreated:Put record, to the SQS queue "sqs_queue_url" or the
+// SNS topic "sns_topic_arn", like a bucket with event notifications,
+// so collectors that read the notifications see each object once.
+//
+//	notify:
+//	  sqs_queue_url: "https://sqs.us-east-1.amazonaws.com/123456789012/cloudtrail"
+//
+// "delivery" simulates the write patterns that break pollers listing
+// the bucket.  "late" is the percentage of objects uploaded "late_by",
+// by default 5m, after they are complete, so after newer objects,
+// "overwrite" the percentage of objects uploaded again to their key
+// after the next object, and "empty" the percentage of objects
+// preceded by a zero-length object.  Late objects are notified when
+// they are uploaded.
+//
+//	delivery:
+//	  late: 10
+//	  late_by: 15m
+//	  overwrite: 2
+//	  empty: 1
+//
 // Assumptions:
 //
 // - Either aws credentials file or environment variables (AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY) are set.
@@ -22,14 +65,18 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/md5"
 	"fmt"
-	"math/rand"
+	"io"
+	"math/rand/v2"
 	"sync"
 	"time"
 
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/elastic/go-ucfg"
 	"github.com/leehinman/spigot/pkg/output"
@@ -39,18 +86,32 @@ import (
 const Name = "s3"
 
 var (
-	doOnce   sync.Once
-	uploader *manager.Uploader
+	doOnce    sync.Once
+	uploader  *manager.Uploader
+	sqsClient *sqs.Client
+	snsClient *sns.Client
 )
 
 // S3Output holds config for writing to S3.
 type S3Output struct {
 	delimiter string
 	bucket    string
+	prefix    output.DatePattern
+	suffix    string
+	compress  bool
+	maxSize   int
+	maxAge    time.Duration
 	key       string
-	prefix    string
+	created   time.Time
+	size      int
 	buf       *bytes.Buffer
+	w         io.Writer
 	gw        *gzip.Writer
+	now       func() time.Time
+	upload    func(bucket, key string, body io.Reader) error
+	notify    func(body string) error
+	region    string
+	delivery  *output.Delivery
 }
 
 func init() {
@@ -70,48 +131,129 @@ func New(cfg *ucfg.Config) (s output.Output, err error) {
 			panic(err)
 		}
 		uploader = manager.NewUploader(s3.NewFromConfig(cfg))
+		sqsClient = sqs.NewFromConfig(cfg)
+		snsClient = sns.NewFromConfig(cfg)
 	})
-	var buf bytes.Buffer
-	gw := gzip.NewWriter(&buf)
-	key := fmt.Sprintf("%s_%19d_%03d.gz", c.Prefix, time.Now().UnixNano(), rand.Intn(1000))
+	// Validate checked the prefix.
+	prefix, _ := output.ParseDatePattern("prefix", c.Prefix)
 
-	s = &S3Output{
+	o := &S3Output{
 		delimiter: c.Delimiter,
 		bucket:    c.Bucket,
-		key:       key,
-		prefix:    c.Prefix,
-		buf:       &buf,
-		gw:        gw,
+		prefix:    prefix,
+		suffix:    c.Suffix,
+		compress:  c.Compression == CompressionGzip,
+		maxSize:   c.MaxSize,
+		maxAge:    c.MaxAge,
+		buf:       &bytes.Buffer{},
+		now:       time.Now,
+		upload:    upload,
+		notify:    newNotify(c.Notify),
+		region:    c.Region,
 	}
-	return s, nil
+	o.delivery = output.NewDelivery(c.Delivery, o.put, o.objectKey, func() time.Time { return o.now() })
+	return o, nil
 }
 
-// Write writes log entry to internal buffer
+// upload puts the object key into the bucket.
+func upload(bucket, key string, body io.Reader) error {
+	_, err := uploader.Upload(context.TODO(), &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   body,
+	})
+	return err
+}
+
+// Write writes log entry to internal buffer, and uploads the object
+// when it reaches max_size or max_age.
 func (s *S3Output) Write(b []byte) (n int, err error) {
-	j, err := s.gw.Write(b)
+	now := s.now()
+	if s.key != "" && s.maxAge > 0 && now.Sub(s.created) >= s.maxAge {
+		if err := s.flush(); err != nil {
+			return 0, err
+		}
+	}
+	if s.key == "" {
+		s.start(now)
+	}
+	j, err := s.w.Write(b)
 	if err != nil {
 		return j, err
 	}
-	k, err := s.gw.Write([]byte(s.delimiter))
-	return j + k, err
+	k, err := s.w.Write([]byte(s.delimiter))
+	if err != nil {
+		return j + k, err
+	}
+	s.size += j + k
+	if s.maxSize > 0 && s.size >= s.maxSize {
+		if err := s.flush(); err != nil {
+			return j + k, err
+		}
+	}
+	return j + k, nil
 }
 
-// Close closes internal buffer and uploads data to S3
-func (s *S3Output) Close() error {
-	s.gw.Close()
+// start starts a new object at time t.
+func (s *S3Output) start(t time.Time) {
+	s.key = s.objectKey(t)
+	s.created = t
+	s.size = 0
+	s.buf.Reset()
+	s.w = s.buf
+	if s.compress {
+		s.gw = gzip.NewWriter(s.buf)
+		s.w = s.gw
+	}
+}
 
-	_, err := uploader.Upload(context.TODO(), &s3.PutObjectInput{
-		Bucket: aws.String(s.bucket),
-		Key:    aws.String(s.key),
-		Body:   s.buf,
-	})
-	return err
+// objectKey returns the key of an object started at time t.
+func (s *S3Output) objectKey(t time.Time) string {
+	key := fmt.Sprintf("%s_%19d_%03d%s", s.prefix.Format(t), t.UnixNano(), rand.IntN(1000), s.suffix)
+	if s.compress {
+		key += ".gz"
+	}
+	return key
 }
 
+// flush delivers the object, if there are events in it, and the late
+// objects that are due.
+func (s *S3Output) flush() error {
+	if s.key == "" {
+		return s.delivery.Poll()
+	}
+	if s.compress {
+		if err := s.gw.Close(); err != nil {
+			return err
+		}
+	}
+	key := s.key
+	s.key = ""
+	return s.delivery.Deliver(key, s.buf.Bytes())
+}
+
+// put uploads body to the object key, and sends its notification.
+func (s *S3Output) put(key string, body []byte) error {
+	size, etag := len(body), fmt.Sprintf("%x", md5.Sum(body))
+	if err := s.upload(s.bucket, key, bytes.NewReader(body)); err != nil {
+		return err
+	}
+	if s.notify == nil {
+		return nil
+	}
+	return s.notify(s.notification(key, size, etag, s.now()))
+}
+
+// Close uploads the buffered events and the late objects to S3
+func (s *S3Output) Close() error {
+	if err := s.flush(); err != nil {
+		return err
+	}
+	return s.delivery.Flush()
+}
+
+// NewInterval uploads the buffered events, so each interval is an object
+// of its own.
 func (s *S3Output) NewInterval() error {
-	s.Close()
-	s.buf.Reset()
-	s.gw = gzip.NewWriter(s.buf)
-	s.key = fmt.Sprintf("%s_%19d_%3d.gz", s.prefix, time.Now().UnixNano(), rand.Intn(1000))
-	return nil
+	return s.flush()
 }
@@ -0,0 +1,171 @@
+package s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+type object struct {
+	bucket string
+	key    string
+	body   []byte
+}
+
+func newTestOutput(t *testing.T, c map[string]interface{}) (*S3Output, *[]object) {
+	cfg, err := ucfg.NewFrom(c)
+	assert.Nil(t, err)
+	out, err := New(cfg)
+	assert.Nil(t, err)
+	s := out.(*S3Output)
+	var objects []object
+	s.upload = func(bucket, key string, body io.Reader) error {
+		b, err := io.ReadAll(body)
+		objects = append(objects, object{bucket: bucket, key: key, body: b})
+		return err
+	}
+	return s, &objects
+}
+
+func TestRotation(t *testing.T) {
+	s, objects := newTestOutput(t, map[string]interface{}{"type": Name, "bucket": "logs", "region": "us-east-1", "prefix": "AWSLogs/%{+yyyy/MM/dd}/elb", "suffix": ".log", "compression": "none", "max_size": 10, "max_age": "1m"})
+	now := time.Date(2024, 3, 2, 23, 59, 30, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	for _, e := range []string{"one", "two"} {
+		_, err := s.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	assert.Len(t, *objects, 0)
+	now = now.Add(time.Minute)
+	for _, e := range []string{"three", "four"} {
+		_, err := s.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, s.Close())
+	assert.Nil(t, s.NewInterval())
+
+	assert.Len(t, *objects, 2)
+	assert.Equal(t, "logs", (*objects)[0].bucket)
+	assert.Regexp(t, `^AWSLogs/2024/03/02/elb_\d{19}_\d{3}\.log$`, (*objects)[0].key)
+	assert.Equal(t, "one\ntwo\n", string((*objects)[0].body))
+	assert.Regexp(t, `^AWSLogs/2024/03/03/elb_\d{19}_\d{3}\.log$`, (*objects)[1].key)
+	assert.Equal(t, "three\nfour\n", string((*objects)[1].body))
+}
+
+func TestGzip(t *testing.T) {
+	s, objects := newTestOutput(t, map[string]interface{}{"type": Name, "bucket": "logs", "region": "us-east-1", "prefix": "test"})
+
+	_, err := s.Write([]byte("one"))
+	assert.Nil(t, err)
+	assert.Nil(t, s.NewInterval())
+	_, err = s.Write([]byte("two"))
+	assert.Nil(t, err)
+	assert.Nil(t, s.Close())
+
+	assert.Len(t, *objects, 2)
+	for i, want := range []string{"one\n", "two\n"} {
+		assert.Regexp(t, `^test_\d{19}_\d{3}\.gz$`, (*objects)[i].key)
+		zr, err := gzip.NewReader(bytes.NewReader((*objects)[i].body))
+		assert.Nil(t, err)
+		b, err := io.ReadAll(zr)
+		assert.Nil(t, err)
+		assert.Equal(t, want, string(b))
+	}
+}
+
+func TestNotify(t *testing.T) {
+	s, objects := newTestOutput(t, map[string]interface{}{"type": Name, "bucket": "logs", "region": "us-east-1", "prefix": "AWSLogs/%{+yyyy/MM/dd}/elb log", "compression": "none", "notify": map[string]interface{}{"sqs_queue_url": "https://sqs.us-east-1.amazonaws.com/123456789012/logs"}})
+	now := time.Date(2024, 3, 2, 23, 59, 30, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	var notifications []string
+	s.notify = func(body string) error {
+		notifications = append(notifications, body)
+		return nil
+	}
+
+	_, err := s.Write([]byte("one"))
+	assert.Nil(t, err)
+	assert.Nil(t, s.Close())
+
+	assert.Len(t, *objects, 1)
+	assert.Len(t, notifications, 1)
+	var n struct {
+		Records []struct {
+			EventSource string `json:"eventSource"`
+			EventName   string `json:"eventName"`
+			EventTime   string `json:"eventTime"`
+			AWSRegion   string `json:"awsRegion"`
+			S3          struct {
+				Bucket struct {
+					Name string `json:"name"`
+					ARN  string `json:"arn"`
+				} `json:"bucket"`
+				Object struct {
+					Key  string `json:"key"`
+					Size int    `json:"size"`
+					ETag string `json:"eTag"`
+				} `json:"object"`
+			} `json:"s3"`
+		} `json:"Records"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(notifications[0]), &n))
+	assert.Len(t, n.Records, 1)
+	r := n.Records[0]
+	assert.Equal(t, "aws:s3", r.EventSource)
+	assert.Equal(t, "ObjectCreated:Put", r.EventName)
+	assert.Equal(t, "2024-03-02T23:59:30.000Z", r.EventTime)
+	assert.Equal(t, "us-east-1", r.AWSRegion)
+	assert.Equal(t, "logs", r.S3.Bucket.Name)
+	assert.Equal(t, "arn:aws:s3:::logs", r.S3.Bucket.ARN)
+	assert.Equal(t, strings.ReplaceAll((*objects)[0].key, " ", "+"), r.S3.Object.Key)
+	assert.Equal(t, 4, r.S3.Object.Size)
+	assert.Equal(t, fmt.Sprintf("%x", md5.Sum([]byte("one\n"))), r.S3.Object.ETag)
+}
+
+func TestDelivery(t *testing.T) {
+	s, objects := newTestOutput(t, map[string]interface{}{"type": Name, "bucket": "logs", "region": "us-east-1", "prefix": "test", "compression": "none", "delivery": map[string]interface{}{"late": 100, "late_by": "1m"}})
+	now := time.Date(2024, 3, 2, 23, 59, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	for _, e := range []string{"one", "two"} {
+		_, err := s.Write([]byte(e))
+		assert.Nil(t, err)
+		assert.Nil(t, s.NewInterval())
+		now = now.Add(40 * time.Second)
+	}
+	assert.Len(t, *objects, 0)
+	assert.Nil(t, s.NewInterval())
+	assert.Len(t, *objects, 1)
+	assert.Equal(t, "one\n", string((*objects)[0].body))
+	assert.Nil(t, s.Close())
+	assert.Len(t, *objects, 2)
+	assert.Equal(t, "two\n", string((*objects)[1].body))
+
+	s, objects = newTestOutput(t, map[string]interface{}{"type": Name, "bucket": "logs", "region": "us-east-1", "prefix": "test", "compression": "none", "delivery": map[string]interface{}{"overwrite": 100, "empty": 100}})
+	for _, e := range []string{"one", "two"} {
+		_, err := s.Write([]byte(e))
+		assert.Nil(t, err)
+		assert.Nil(t, s.NewInterval())
+	}
+	assert.Nil(t, s.Close())
+
+	assert.Len(t, *objects, 6)
+	var bodies []string
+	for _, o := range *objects {
+		assert.Regexp(t, `^test_\d{19}_\d{3}$`, o.key)
+		bodies = append(bodies, string(o.body))
+	}
+	assert.Equal(t, []string{"", "one\n", "one\n", "", "two\n", "two\n"}, bodies)
+	assert.Equal(t, (*objects)[1].key, (*objects)[2].key)
+	assert.NotEqual(t, (*objects)[0].key, (*objects)[1].key)
+}
@@ -0,0 +1,72 @@
+package sftp
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+type config struct {
+	Type                  string                `config:"type" validate:"required"`
+	Address               string                `config:"address" validate:"required"`
+	Username              string                `config:"username" validate:"required"`
+	Password              string                `config:"password"`
+	PrivateKey            string                `config:"private_key"`
+	PrivateKeyPassphrase  string                `config:"private_key_passphrase"`
+	KnownHosts            string                `config:"known_hosts"`
+	InsecureIgnoreHostKey bool                  `config:"insecure_ignore_host_key"`
+	Path                  string                `config:"path" validate:"required"`
+	Suffix                string                `config:"suffix"`
+	TempSuffix            string                `config:"temp_suffix"`
+	Delimiter             string                `config:"delimiter"`
+	Compression           string                `config:"compression"`
+	MaxSize               int                   `config:"max_size"`
+	MaxAge                time.Duration         `config:"max_age"`
+	Delivery              output.DeliveryConfig `config:"delivery"`
+}
+
+// Compressions of the files.
+const (
+	CompressionGzip = "gzip"
+	CompressionNone = "none"
+)
+
+func defaultConfig() config {
+	return config{
+		Type:        Name,
+		TempSuffix:  ".part",
+		Delimiter:   "\n",
+		Compression: CompressionGzip,
+		Delivery:    output.DefaultDeliveryConfig(),
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return fmt.Errorf("'%s' is not a valid value for 'address' expected host:port", c.Address)
+	}
+	if c.Password == "" && c.PrivateKey == "" {
+		return fmt.Errorf("'password' or 'private_key' is required")
+	}
+	if c.KnownHosts == "" && !c.InsecureIgnoreHostKey {
+		return fmt.Errorf("'known_hosts' is required without 'insecure_ignore_host_key'")
+	}
+	if _, err := output.ParseDatePattern("path", c.Path); err != nil {
+		return err
+	}
+	if c.Compression != CompressionGzip && c.Compression != CompressionNone {
+		return fmt.Errorf("'%s' is not a valid value for 'compression' expected '%s' or '%s'", c.Compression, CompressionGzip, CompressionNone)
+	}
+	if c.MaxSize < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'max_size' expected 0 or more", c.MaxSize)
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'max_age' expected 0 or more", c.MaxAge)
+	}
+	return nil
+}
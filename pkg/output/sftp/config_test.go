@@ -0,0 +1,100 @@
+package sftp
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	dir := t.TempDir()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.Nil(t, err)
+	keyFile := filepath.Join(dir, "id_ed25519")
+	assert.Nil(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	knownHosts := filepath.Join(dir, "known_hosts")
+	assert.Nil(t, os.WriteFile(knownHosts, nil, 0o600))
+
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name, "address": "localhost:22", "username": "spigot", "password": "secret", "insecure_ignore_host_key": true, "path": "/upload/test"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Options": {
+			c:           map[string]interface{}{"type": Name, "address": "localhost:22", "username": "spigot", "private_key": keyFile, "known_hosts": knownHosts, "path": "/upload/%{+yyyy/MM/dd}/test", "suffix": ".log", "temp_suffix": "", "compression": "none", "max_size": 1048576, "max_age": "5m"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "address": "localhost:22", "username": "spigot", "password": "secret", "insecure_ignore_host_key": true, "path": "test"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'sftp' accessing config",
+		},
+		"Invalid Address": {
+			c:           map[string]interface{}{"type": Name, "address": "localhost", "username": "spigot", "password": "secret", "insecure_ignore_host_key": true, "path": "test"},
+			hasError:    true,
+			errorString: "'localhost' is not a valid value for 'address' expected host:port accessing config",
+		},
+		"No Credentials": {
+			c:           map[string]interface{}{"type": Name, "address": "localhost:22", "username": "spigot", "insecure_ignore_host_key": true, "path": "test"},
+			hasError:    true,
+			errorString: "'password' or 'private_key' is required accessing config",
+		},
+		"No Known Hosts": {
+			c:           map[string]interface{}{"type": Name, "address": "localhost:22", "username": "spigot", "password": "secret", "path": "test"},
+			hasError:    true,
+			errorString: "'known_hosts' is required without 'insecure_ignore_host_key' accessing config",
+		},
+		"Invalid Path": {
+			c:           map[string]interface{}{"type": Name, "address": "localhost:22", "username": "spigot", "password": "secret", "insecure_ignore_host_key": true, "path": "/upload/%{+yyyy"},
+			hasError:    true,
+			errorString: "'/upload/%{+yyyy' is not a valid value for 'path' expected a '}' after '%{+' accessing config",
+		},
+		"Invalid Compression": {
+			c:           map[string]interface{}{"type": Name, "address": "localhost:22", "username": "spigot", "password": "secret", "insecure_ignore_host_key": true, "path": "test", "compression": "zstd"},
+			hasError:    true,
+			errorString: "'zstd' is not a valid value for 'compression' expected 'gzip' or 'none' accessing config",
+		},
+		"Invalid Max Size": {
+			c:           map[string]interface{}{"type": Name, "address": "localhost:22", "username": "spigot", "password": "secret", "insecure_ignore_host_key": true, "path": "test", "max_size": -1},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'max_size' expected 0 or more accessing config",
+		},
+		"Invalid Late By": {
+			c:           map[string]interface{}{"type": Name, "address": "localhost:22", "username": "spigot", "password": "secret", "insecure_ignore_host_key": true, "path": "test", "delivery": map[string]interface{}{"late": 5, "late_by": "0s"}},
+			hasError:    true,
+			errorString: "'0s' is not a valid value for 'late_by' expected a positive duration accessing 'delivery'",
+		},
+		"Invalid Private Key": {
+			c:           map[string]interface{}{"type": Name, "address": "localhost:22", "username": "spigot", "private_key": knownHosts, "insecure_ignore_host_key": true, "path": "test"},
+			hasError:    true,
+			errorString: "private key " + knownHosts + ": ssh: no key found",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package sftp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+)
+
+// The packet types of SFTP version 3, draft-ietf-secsh-filexfer-02.
+const (
+	fxpInit     = 1
+	fxpVersion  = 2
+	fxpOpen     = 3
+	fxpClose    = 4
+	fxpWrite    = 6
+	fxpRemove   = 13
+	fxpMkdir    = 14
+	fxpStat     = 17
+	fxpRename   = 18
+	fxpStatus   = 101
+	fxpHandle   = 102
+	fxpAttrs    = 105
+	fxpExtended = 200
+)
+
+// The open flags of fxpOpen.
+const (
+	fxfWrite = 0x02
+	fxfCreat = 0x08
+	fxfTrunc = 0x10
+)
+
+// The status codes of fxpStatus.
+const (
+	fxOK         = 0
+	fxNoSuchFile = 2
+	fxFailure    = 4
+)
+
+// posixRename is the OpenSSH extension of a rename that replaces the
+// target, which fxpRename of version 3 does not.
+const posixRename = "posix-rename@openssh.com"
+
+// sftpVersion is the protocol version of the client.
+const sftpVersion = 3
+
+// maxData is the most data of a write request, which all servers
+// accept.
+const maxData = 32768
+
+// maxPacket is the largest response packet that is read.
+const maxPacket = 256 * 1024
+
+// statusError is a status response with a code other than fxOK.
+type statusError struct {
+	code uint32
+	msg  string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("sftp status %d: %s", e.code, e.msg)
+}
+
+// client is an SFTP client on the streams of the sftp subsystem of an
+// SSH session.  Requests are sent one at a time.
+type client struct {
+	w          io.Writer
+	r          *bufio.Reader
+	id         uint32
+	extensions map[string]string
+}
+
+// newClient returns a client of the server on r and w, after the
+// version exchange.
+func newClient(r io.Reader, w io.Writer) (*client, error) {
+	c := &client{w: w, r: bufio.NewReader(r)}
+	if err := c.writePacket(fxpInit, appendUint32(nil, sftpVersion)); err != nil {
+		return nil, err
+	}
+	typ, data, err := c.readPacket()
+	if err != nil {
+		return nil, err
+	}
+	if typ != fxpVersion || len(data) < 4 {
+		return nil, fmt.Errorf("sftp server sent packet %d, expected its version", typ)
+	}
+	if v := binary.BigEndian.Uint32(data); v < sftpVersion {
+		return nil, fmt.Errorf("sftp server version %d is not supported, expected %d", v, sftpVersion)
+	}
+	c.extensions = map[string]string{}
+	for data = data[4:]; len(data) > 0; {
+		name, ok := readString(data)
+		if !ok {
+			break
+		}
+		data = data[4+len(name):]
+		v, ok := readString(data)
+		if !ok {
+			break
+		}
+		data = data[4+len(v):]
+		c.extensions[name] = v
+	}
+	return c, nil
+}
+
+// writePacket writes a packet of typ with data.
+func (c *client) writePacket(typ byte, data []byte) error {
+	b := make([]byte, 0, 5+len(data))
+	b = appendUint32(b, uint32(1+len(data)))
+	b = append(b, typ)
+	b = append(b, data...)
+	_, err := c.w.Write(b)
+	return err
+}
+
+// readPacket reads a packet, and returns its type and data.
+func (c *client) readPacket() (byte, []byte, error) {
+	var h [5]byte
+	if _, err := io.ReadFull(c.r, h[:]); err != nil {
+		return 0, nil, err
+	}
+	n := binary.BigEndian.Uint32(h[:4])
+	if n < 1 || n > maxPacket {
+		return 0, nil, fmt.Errorf("sftp packet of %d bytes is not valid", n)
+	}
+	data := make([]byte, n-1)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return 0, nil, err
+	}
+	return h[4], data, nil
+}
+
+// request sends a request of typ with data, and returns the type and
+// data of the response, without its request id.
+func (c *client) request(typ byte, data []byte) (byte, []byte, error) {
+	c.id++
+	if err := c.writePacket(typ, append(appendUint32(nil, c.id), data...)); err != nil {
+		return 0, nil, err
+	}
+	rtyp, rdata, err := c.readPacket()
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(rdata) < 4 || binary.BigEndian.Uint32(rdata) != c.id {
+		return 0, nil, fmt.Errorf("sftp response does not match request %d", c.id)
+	}
+	return rtyp, rdata[4:], nil
+}
+
+// do sends a request of typ with data, and returns the error of its
+// status response.
+func (c *client) do(typ byte, data []byte) error {
+	rtyp, rdata, err := c.request(typ, data)
+	if err != nil {
+		return err
+	}
+	return status(rtyp, rdata)
+}
+
+// status returns the error of a response that must be a status.
+func status(typ byte, data []byte) error {
+	if typ != fxpStatus {
+		return fmt.Errorf("sftp server sent packet %d, expected a status", typ)
+	}
+	if len(data) < 4 {
+		return fmt.Errorf("sftp status is too short")
+	}
+	code := binary.BigEndian.Uint32(data)
+	if code == fxOK {
+		return nil
+	}
+	msg, _ := readString(data[4:])
+	return &statusError{code: code, msg: msg}
+}
+
+// create opens the file p for writing, truncating it, and returns its
+// handle.
+func (c *client) create(p string) (string, error) {
+	data := appendString(nil, p)
+	data = appendUint32(data, fxfWrite|fxfCreat|fxfTrunc)
+	data = appendUint32(data, 0) // no attributes
+	typ, rdata, err := c.request(fxpOpen, data)
+	if err != nil {
+		return "", err
+	}
+	if typ != fxpHandle {
+		return "", status(typ, rdata)
+	}
+	h, ok := readString(rdata)
+	if !ok {
+		return "", fmt.Errorf("sftp handle is too short")
+	}
+	return h, nil
+}
+
+// write writes b to the file of handle h at offset off.
+func (c *client) write(h string, off uint64, b []byte) error {
+	data := appendString(nil, h)
+	data = binary.BigEndian.AppendUint64(data, off)
+	data = appendString(data, string(b))
+	return c.do(fxpWrite, data)
+}
+
+// close closes the handle h.
+func (c *client) close(h string) error {
+	return c.do(fxpClose, appendString(nil, h))
+}
+
+// rename renames the file from to to, replacing to if it exists.  The
+// extension posixRename does so in one request; without it to is
+// removed first, as servers such as OpenSSH fail fxpRename onto an
+// existing file.
+func (c *client) rename(from, to string) error {
+	if _, ok := c.extensions[posixRename]; ok {
+		return c.do(fxpExtended, appendString(appendString(appendString(nil, posixRename), from), to))
+	}
+	var se *statusError
+	if err := c.do(fxpRemove, appendString(nil, to)); err != nil && (!errors.As(err, &se) || se.code != fxNoSuchFile) {
+		return err
+	}
+	return c.do(fxpRename, appendString(appendString(nil, from), to))
+}
+
+// mkdirAll creates the directory dir and the parents it does not have.
+func (c *client) mkdirAll(dir string) error {
+	dir = path.Clean(dir)
+	if dir == "." || dir == "/" {
+		return nil
+	}
+	typ, data, err := c.request(fxpStat, appendString(nil, dir))
+	if err != nil {
+		return err
+	}
+	if typ == fxpAttrs {
+		return nil
+	}
+	var se *statusError
+	if err := status(typ, data); err == nil || !errors.As(err, &se) || se.code != fxNoSuchFile {
+		return err
+	}
+	if parent := path.Dir(dir); parent != dir {
+		if err := c.mkdirAll(parent); err != nil {
+			return err
+		}
+	}
+	return c.do(fxpMkdir, appendUint32(appendString(nil, dir), 0))
+}
+
+// upload writes the file p with the contents of body.
+func (c *client) upload(p string, body []byte) error {
+	h, err := c.create(p)
+	if err != nil {
+		return err
+	}
+	for off := 0; off < len(body); off += maxData {
+		end := off + maxData
+		if end > len(body) {
+			end = len(body)
+		}
+		if err := c.write(h, uint64(off), body[off:end]); err != nil {
+			_ = c.close(h)
+			return err
+		}
+	}
+	return c.close(h)
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return binary.BigEndian.AppendUint32(b, v)
+}
+
+func appendString(b []byte, s string) []byte {
+	return append(appendUint32(b, uint32(len(s))), s...)
+}
+
+// readString returns the string at the start of b.
+func readString(b []byte) (string, bool) {
+	if len(b) < 4 {
+		return "", false
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint64(len(b)-4) < uint64(n) {
+		return "", false
+	}
+	return string(b[4 : 4+n]), true
+}
@@ -0,0 +1,317 @@
+// Package sftp implements the output of log files uploaded to an SFTP
+// server, like the drops several integrations collect their logs from.
+//
+// "address", "username" and "path" are required, and one of
+// "password" or "private_key", the path of a private key file in
+// OpenSSH or PEM format, with an optional "private_key_passphrase".
+// The host key of the server is checked against the "known_hosts"
+// file, unless "insecure_ignore_host_key" is true.
+//
+//	output:
+//	  type: sftp
+//	  address: "sftp.example.com:22"
+//	  username: spigot
+//	  private_key: "/home/spigot/.ssh/id_ed25519"
+//	  known_hosts: "/home/spigot/.ssh/known_hosts"
+//	  path: "/upload/%{+yyyy/MM/dd}/firewall"  ;; firewall_0123456789_001.log.gz
+//	  suffix: ".log"
+//	  max_size: 10485760
+//	  max_age: 5m
+//
+// The events, separated by "delimiter", by default "\n", are buffered
+// into a file, which is uploaded with each new interval and on close.
+// "max_size" uploads the file once the events in it are of at least
+// that many bytes before compression, and "max_age" once its first
+// event is that old.  Both are unlimited by default.
+//
+// "path" may contain date formats such as %{+yyyy/MM/dd}, of the time
+// of the first event of the file in UTC.  The directories of the path
+// are created when they do not exist.  "suffix" is appended to the
+// name, before the ".gz" of "compression" gzip, the default; with
+// "none" the files are not compressed.
+//
+// A file is written with "temp_suffix", by default ".part", and
+// renamed when it is complete, replacing a file of the same name, so
+// collectors never pick up a partial file.  With an empty temp_suffix the file is written in place.
+//
+// "delivery" simulates the write patterns that break pollers listing
+// the directories, with the "late", "late_by", "overwrite" and "empty"
+// options of the s3 output.
+//
+//	delivery:
+//	  late: 10
+//	  late_by: 15m
+package sftp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "sftp"
+
+// dialTimeout is the timeout of connecting to the server.
+const dialTimeout = 30 * time.Second
+
+// Output holds the file being buffered and the connection to the
+// server.
+type Output struct {
+	address    string
+	ssh        *ssh.ClientConfig
+	delimiter  string
+	path       output.DatePattern
+	suffix     string
+	tempSuffix string
+	compress   bool
+	maxSize    int
+	maxAge     time.Duration
+	name       string
+	created    time.Time
+	size       int
+	buf        *bytes.Buffer
+	w          io.Writer
+	gw         *gzip.Writer
+	now        func() time.Time
+	upload     func(name string, body []byte) error
+	delivery   *output.Delivery
+
+	conn    *ssh.Client
+	session *ssh.Session
+	client  *client
+}
+
+func init() {
+	output.Register(Name, New)
+}
+
+// New is the factory for creating a new sftp Output.
+func New(cfg *ucfg.Config) (output.Output, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	sc, err := sshConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	// Validate checked the path.
+	p, _ := output.ParseDatePattern("path", c.Path)
+
+	o := &Output{
+		address:    c.Address,
+		ssh:        sc,
+		delimiter:  c.Delimiter,
+		path:       p,
+		suffix:     c.Suffix,
+		tempSuffix: c.TempSuffix,
+		compress:   c.Compression == CompressionGzip,
+		maxSize:    c.MaxSize,
+		maxAge:     c.MaxAge,
+		buf:        &bytes.Buffer{},
+		now:        time.Now,
+	}
+	o.upload = o.put
+	o.delivery = output.NewDelivery(c.Delivery, func(name string, body []byte) error { return o.upload(name, body) }, o.fileName, func() time.Time { return o.now() })
+	return o, nil
+}
+
+// sshConfig returns the client config of the authentication and host
+// key checking of c.
+func sshConfig(c config) (*ssh.ClientConfig, error) {
+	sc := &ssh.ClientConfig{
+		User:    c.Username,
+		Timeout: dialTimeout,
+	}
+	if c.PrivateKey != "" {
+		key, err := os.ReadFile(c.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		var signer ssh.Signer
+		if c.PrivateKeyPassphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(c.PrivateKeyPassphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("private key %s: %w", c.PrivateKey, err)
+		}
+		sc.Auth = append(sc.Auth, ssh.PublicKeys(signer))
+	}
+	if c.Password != "" {
+		sc.Auth = append(sc.Auth, ssh.Password(c.Password))
+	}
+	if c.InsecureIgnoreHostKey {
+		sc.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+		return sc, nil
+	}
+	cb, err := knownhosts.New(c.KnownHosts)
+	if err != nil {
+		return nil, err
+	}
+	sc.HostKeyCallback = cb
+	return sc, nil
+}
+
+// Write writes the event to the buffered file, and uploads the file
+// when it reaches max_size or max_age.
+func (o *Output) Write(b []byte) (int, error) {
+	now := o.now()
+	if o.name != "" && o.maxAge > 0 && now.Sub(o.created) >= o.maxAge {
+		if err := o.flush(); err != nil {
+			return 0, err
+		}
+	}
+	if o.name == "" {
+		o.start(now)
+	}
+	j, err := o.w.Write(b)
+	if err != nil {
+		return j, err
+	}
+	k, err := o.w.Write([]byte(o.delimiter))
+	if err != nil {
+		return j + k, err
+	}
+	o.size += j + k
+	if o.maxSize > 0 && o.size >= o.maxSize {
+		if err := o.flush(); err != nil {
+			return j + k, err
+		}
+	}
+	return j + k, nil
+}
+
+// start starts a new file at time t.
+func (o *Output) start(t time.Time) {
+	o.name = o.fileName(t)
+	o.created = t
+	o.size = 0
+	o.buf.Reset()
+	o.w = o.buf
+	if o.compress {
+		o.gw = gzip.NewWriter(o.buf)
+		o.w = o.gw
+	}
+}
+
+// fileName returns the name of a file started at time t.
+func (o *Output) fileName(t time.Time) string {
+	name := fmt.Sprintf("%s_%19d_%03d%s", o.path.Format(t), t.UnixNano(), rand.IntN(1000), o.suffix)
+	if o.compress {
+		name += ".gz"
+	}
+	return name
+}
+
+// flush delivers the file, if there are events in it, and the late
+// files that are due.
+func (o *Output) flush() error {
+	if o.name == "" {
+		return o.delivery.Poll()
+	}
+	if o.compress {
+		if err := o.gw.Close(); err != nil {
+			return err
+		}
+	}
+	name := o.name
+	o.name = ""
+	return o.delivery.Deliver(name, o.buf.Bytes())
+}
+
+// put uploads body to the file name on the server, connecting first
+// if there is no connection.  The connection is closed on errors, so
+// the next upload connects again.
+func (o *Output) put(name string, body []byte) error {
+	if o.client == nil {
+		if err := o.connect(); err != nil {
+			return err
+		}
+	}
+	err := o.client.mkdirAll(path.Dir(name))
+	if err == nil {
+		err = o.client.upload(name+o.tempSuffix, body)
+	}
+	if err == nil && o.tempSuffix != "" {
+		err = o.client.rename(name+o.tempSuffix, name)
+	}
+	if err != nil {
+		o.disconnect()
+		return fmt.Errorf("sftp upload of %s: %w", name, err)
+	}
+	return nil
+}
+
+// connect connects to the server and starts the sftp subsystem.
+func (o *Output) connect() error {
+	conn, err := ssh.Dial("tcp", o.address, o.ssh)
+	if err != nil {
+		return err
+	}
+	session, err := conn.NewSession()
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	w, err := session.StdinPipe()
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	c, err := newClient(r, w)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	o.conn, o.session, o.client = conn, session, c
+	return nil
+}
+
+// disconnect closes the connection to the server.
+func (o *Output) disconnect() {
+	if o.conn == nil {
+		return
+	}
+	_ = o.session.Close()
+	_ = o.conn.Close()
+	o.conn, o.session, o.client = nil, nil, nil
+}
+
+// Close uploads the buffered events and the late files, and closes the
+// connection.
+func (o *Output) Close() error {
+	err := o.flush()
+	if err == nil {
+		err = o.delivery.Flush()
+	}
+	o.disconnect()
+	return err
+}
+
+// NewInterval uploads the buffered events, so each interval is a file
+// of its own.
+func (o *Output) NewInterval() error {
+	return o.flush()
+}
@@ -0,0 +1,240 @@
+package sftp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+type file struct {
+	name string
+	body []byte
+}
+
+func newTestOutput(t *testing.T, c map[string]interface{}) (*Output, *[]file) {
+	c["address"] = "localhost:22"
+	c["username"] = "spigot"
+	c["password"] = "secret"
+	c["insecure_ignore_host_key"] = true
+	cfg, err := ucfg.NewFrom(c)
+	assert.Nil(t, err)
+	out, err := New(cfg)
+	assert.Nil(t, err)
+	o := out.(*Output)
+	var files []file
+	o.upload = func(name string, body []byte) error {
+		files = append(files, file{name: name, body: append([]byte(nil), body...)})
+		return nil
+	}
+	return o, &files
+}
+
+func TestRotation(t *testing.T) {
+	o, files := newTestOutput(t, map[string]interface{}{"type": Name, "path": "/upload/%{+yyyy/MM/dd}/fw", "suffix": ".log", "compression": "none", "max_size": 10, "max_age": "1m"})
+	now := time.Date(2024, 3, 2, 23, 59, 30, 0, time.UTC)
+	o.now = func() time.Time { return now }
+
+	for _, e := range []string{"one", "two"} {
+		_, err := o.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	assert.Len(t, *files, 0)
+	now = now.Add(time.Minute)
+	for _, e := range []string{"three", "four"} {
+		_, err := o.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, o.Close())
+	assert.Nil(t, o.NewInterval())
+
+	assert.Len(t, *files, 2)
+	assert.Regexp(t, `^/upload/2024/03/02/fw_\d{19}_\d{3}\.log$`, (*files)[0].name)
+	assert.Equal(t, "one\ntwo\n", string((*files)[0].body))
+	assert.Regexp(t, `^/upload/2024/03/03/fw_\d{19}_\d{3}\.log$`, (*files)[1].name)
+	assert.Equal(t, "three\nfour\n", string((*files)[1].body))
+}
+
+func TestGzip(t *testing.T) {
+	o, files := newTestOutput(t, map[string]interface{}{"type": Name, "path": "drop/test"})
+
+	_, err := o.Write([]byte("one"))
+	assert.Nil(t, err)
+	assert.Nil(t, o.Close())
+
+	assert.Len(t, *files, 1)
+	assert.Regexp(t, `^drop/test_\d{19}_\d{3}\.gz$`, (*files)[0].name)
+	zr, err := gzip.NewReader(bytes.NewReader((*files)[0].body))
+	assert.Nil(t, err)
+	b, err := io.ReadAll(zr)
+	assert.Nil(t, err)
+	assert.Equal(t, "one\n", string(b))
+}
+
+// server is an in-memory SFTP server of the requests of the client.
+type server struct {
+	r       io.Reader
+	w       io.Writer
+	dirs    map[string]bool
+	files   map[string][]byte
+	handles map[string]string
+	ext     bool
+}
+
+// newTestServer starts a server, which has posixRename when ext is
+// true, and returns it and a client of it.
+func newTestServer(t *testing.T, ext bool) (*server, *client) {
+	cr, sw := io.Pipe()
+	sr, cw := io.Pipe()
+	s := &server{r: sr, w: sw, dirs: map[string]bool{"/upload": true}, files: map[string][]byte{}, handles: map[string]string{}, ext: ext}
+	go s.serve()
+	t.Cleanup(func() { cw.Close() })
+
+	c, err := newClient(cr, cw)
+	assert.Nil(t, err)
+	return s, c
+}
+
+func (s *server) serve() {
+	for {
+		var h [5]byte
+		if _, err := io.ReadFull(s.r, h[:]); err != nil {
+			return
+		}
+		data := make([]byte, binary.BigEndian.Uint32(h[:4])-1)
+		if _, err := io.ReadFull(s.r, data); err != nil {
+			return
+		}
+		if h[4] == fxpInit {
+			v := appendUint32(nil, sftpVersion)
+			if s.ext {
+				v = appendString(appendString(v, posixRename), "1")
+			}
+			s.reply(fxpVersion, v)
+			continue
+		}
+		id, data := append([]byte(nil), data[:4]...), data[4:]
+		str := func() string {
+			v, _ := readString(data)
+			data = data[4+len(v):]
+			return v
+		}
+		code := uint32(fxOK)
+		switch h[4] {
+		case fxpStat:
+			if p := str(); s.dirs[p] {
+				s.reply(fxpAttrs, append(id, 0, 0, 0, 0))
+				continue
+			}
+			code = fxNoSuchFile
+		case fxpMkdir:
+			s.dirs[str()] = true
+		case fxpOpen:
+			p := str()
+			hd := fmt.Sprintf("h%d", len(s.handles))
+			s.handles[hd] = p
+			s.files[p] = nil
+			s.reply(fxpHandle, appendString(id, hd))
+			continue
+		case fxpWrite:
+			p := s.handles[str()]
+			off := binary.BigEndian.Uint64(data)
+			data = data[8:]
+			b := str()
+			s.files[p] = append(s.files[p][:off], b...)
+		case fxpClose:
+			delete(s.handles, str())
+		case fxpRemove:
+			p := str()
+			if _, ok := s.files[p]; !ok {
+				code = fxNoSuchFile
+			}
+			delete(s.files, p)
+		case fxpRename:
+			// Like OpenSSH, a rename does not replace a file.
+			from, to := str(), str()
+			if _, ok := s.files[to]; ok {
+				code = fxFailure
+				break
+			}
+			s.files[to] = s.files[from]
+			delete(s.files, from)
+		case fxpExtended:
+			if !s.ext || str() != posixRename {
+				code = fxFailure
+				break
+			}
+			from, to := str(), str()
+			s.files[to] = s.files[from]
+			delete(s.files, from)
+		}
+		s.reply(fxpStatus, appendString(appendUint32(id, code), ""))
+	}
+}
+
+func (s *server) reply(typ byte, data []byte) {
+	b := appendUint32(nil, uint32(1+len(data)))
+	_, _ = s.w.Write(append(append(b, typ), data...))
+}
+
+func TestClient(t *testing.T) {
+	for _, ext := range []bool{false, true} {
+		s, c := newTestServer(t, ext)
+		body := bytes.Repeat([]byte("0123456789"), 10000)
+		assert.Nil(t, c.mkdirAll("/upload/2024/03/02"))
+		assert.Nil(t, c.upload("/upload/2024/03/02/fw.log.part", body))
+		assert.Nil(t, c.rename("/upload/2024/03/02/fw.log.part", "/upload/2024/03/02/fw.log"))
+
+		assert.Equal(t, map[string]bool{"/upload": true, "/upload/2024": true, "/upload/2024/03": true, "/upload/2024/03/02": true}, s.dirs)
+		assert.Equal(t, map[string][]byte{"/upload/2024/03/02/fw.log": body}, s.files)
+		assert.Empty(t, s.handles)
+
+		assert.Nil(t, c.upload("/upload/2024/03/02/fw.log.part", []byte("again")))
+		assert.Nil(t, c.rename("/upload/2024/03/02/fw.log.part", "/upload/2024/03/02/fw.log"), "ext=%v", ext)
+		assert.Equal(t, map[string][]byte{"/upload/2024/03/02/fw.log": []byte("again")}, s.files)
+	}
+}
+
+func TestDelivery(t *testing.T) {
+	o, files := newTestOutput(t, map[string]interface{}{"type": Name, "path": "drop/test", "delivery": map[string]interface{}{"late": 100, "late_by": "1m", "empty": 100}})
+	now := time.Date(2024, 3, 2, 23, 59, 0, 0, time.UTC)
+	o.now = func() time.Time { return now }
+
+	_, err := o.Write([]byte("one"))
+	assert.Nil(t, err)
+	assert.Nil(t, o.NewInterval())
+	assert.Len(t, *files, 1)
+	assert.Regexp(t, `^drop/test_\d{19}_\d{3}\.gz$`, (*files)[0].name)
+	assert.Empty(t, (*files)[0].body)
+	now = now.Add(time.Minute)
+	assert.Nil(t, o.NewInterval())
+	assert.Len(t, *files, 2)
+	assert.NotEqual(t, (*files)[0].name, (*files)[1].name)
+	assert.NotEmpty(t, (*files)[1].body)
+}
+
+func TestOverwrite(t *testing.T) {
+	for _, ext := range []bool{false, true} {
+		o, _ := newTestOutput(t, map[string]interface{}{"type": Name, "path": "/upload/fw", "compression": "none", "delivery": map[string]interface{}{"overwrite": 100}})
+		s, c := newTestServer(t, ext)
+		o.client = c
+		o.upload = o.put
+
+		_, err := o.Write([]byte("one"))
+		assert.Nil(t, err)
+		assert.Nil(t, o.NewInterval())
+		assert.Len(t, s.files, 1)
+		assert.Nil(t, o.NewInterval(), "ext=%v", ext)
+		assert.Len(t, s.files, 1)
+		for name, body := range s.files {
+			assert.Regexp(t, `^/upload/fw_\d{19}_\d{3}$`, name)
+			assert.Equal(t, "one\n", string(body))
+		}
+	}
+}
@@ -17,9 +17,12 @@
 //	  pattern: "simulate_*"
 //
 // directory and pattern are used in os.CreateTemp call
+//
+// Binary log entries are encoded as base64 in the message.
 package simulate
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"io"
 	"os"
@@ -95,6 +98,12 @@ func (r *Output) Write(b []byte) (int, error) {
 	return 0, nil
 }
 
+// WriteBinary writes the binary log entry like Write, with the
+// message encoded as base64.
+func (r *Output) WriteBinary(b []byte) (int, error) {
+	return r.Write([]byte(base64.StdEncoding.EncodeToString(b)))
+}
+
 // Close Marshals the internal slice of events and writes the JSON to
 // the io.WriteCloser.  Adds a newline at end of JSON data.
 // Writes after this will fail.
@@ -47,3 +47,15 @@ func TestWrite(t *testing.T) {
 		assert.Equal(t, []byte(tc.want), buf.Bytes(), name)
 	}
 }
+
+func TestWriteBinary(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Output{
+		events:       []event{},
+		pWriteCloser: &myWriteCloser{&buf},
+	}
+	_, err := r.WriteBinary([]byte{0x00, 0x0a, 0xff})
+	assert.Nil(t, err)
+	r.Close()
+	assert.Equal(t, "{\"events\":[{\"message\":\"AAr/\"}]}\n", buf.String())
+}
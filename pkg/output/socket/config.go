@@ -0,0 +1,43 @@
+package socket
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+var networks = [...]string{"tcp", "tcp4", "tcp6", "udp", "udp4", "udp6"}
+
+type config struct {
+	Type         string `config:"type" validate:"required"`
+	Network      string `config:"network" validate:"required"`
+	Host         string `config:"host" validate:"required"`
+	Port         string `config:"port" validate:"required"`
+	Delimiter    string `config:"delimiter"`
+	LocalAddress string `config:"local_address"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:      Name,
+		Delimiter: "\n",
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	valid := false
+	for _, n := range networks {
+		valid = valid || c.Network == n
+	}
+	if !valid {
+		return fmt.Errorf("'%s' is not a valid value for 'network' expected '%s'", c.Network, strings.Join(networks[:], ", "))
+	}
+	if _, err := output.LocalAddr(c.Network, c.LocalAddress); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package socket
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid": {
+			c:        map[string]interface{}{"type": Name, "network": "udp", "host": "localhost", "port": "2055"},
+			hasError: false,
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "network": "udp", "host": "localhost", "port": "2055"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'socket' accessing config",
+		},
+		"Invalid Network": {
+			c:           map[string]interface{}{"type": Name, "network": "unix", "host": "localhost", "port": "2055"},
+			hasError:    true,
+			errorString: "'unix' is not a valid value for 'network' expected 'tcp, tcp4, tcp6, udp, udp4, udp6' accessing config",
+		},
+		"Invalid Local Address": {
+			c:           map[string]interface{}{"type": Name, "network": "tcp", "host": "localhost", "port": "2055", "local_address": "localhost"},
+			hasError:    true,
+			errorString: "'localhost' is not a valid value for 'local_address' expected an IP address with an optional port accessing config",
+		},
+		"No Host": {
+			c:           map[string]interface{}{"type": Name, "network": "tcp", "port": "2055"},
+			hasError:    true,
+			errorString: "string value is not set accessing 'host'",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		cfg := defaultConfig()
+		err = c.Unpack(&cfg)
+		if tc.hasError {
+			if assert.Error(t, err, name) {
+				assert.Equal(t, tc.errorString, err.Error(), name)
+			}
+		} else {
+			assert.Nil(t, err, name)
+		}
+	}
+}
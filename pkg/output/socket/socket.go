@@ -0,0 +1,141 @@
+// Package socket implements the output of logs over a plain TCP
+// connection or as UDP datagrams.
+//
+// "network", "host" and "port" are required.  "network" is tcp, tcp4,
+// tcp6, udp, udp4 or udp6.  Over TCP every log entry is followed by
+// "delimiter", which defaults to "\n".  Over UDP every log entry is a
+// datagram of its own, without the delimiter.
+//
+// Binary log entries, such as NetFlow packets, are sent prefixed with
+// their length over TCP, see output.WriteLength, and as they are over
+// UDP.
+//
+// "local_address" is optional and is the IP address, with an optional
+// port, the log entries are sent from.
+//
+//	output:
+//	  type: socket
+//	  network: udp
+//	  host: collector.example.com
+//	  port: 2055
+package socket
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "socket"
+
+// dialTimeout is the timeout of connecting to the server.
+const dialTimeout = 30 * time.Second
+
+// Output holds the connection to the server.
+type Output struct {
+	network   string
+	addr      string
+	delimiter []byte
+	stream    bool
+	dialer    *net.Dialer
+	conn      net.Conn
+}
+
+func init() {
+	output.Register(Name, New)
+}
+
+// New is the Factory for creating a new socket output.
+func New(cfg *ucfg.Config) (output.Output, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	local, err := output.LocalAddr(c.Network, c.LocalAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Output{
+		network:   c.Network,
+		addr:      net.JoinHostPort(c.Host, c.Port),
+		delimiter: []byte(c.Delimiter),
+		stream:    strings.HasPrefix(c.Network, "tcp"),
+		dialer:    &net.Dialer{LocalAddr: local},
+	}
+	if err := o.connect(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Write sends the log entry, followed by the delimiter over TCP.
+func (o *Output) Write(b []byte) (int, error) {
+	return o.send(func(conn net.Conn) (int, error) {
+		if !o.stream {
+			return conn.Write(b)
+		}
+		// one write, so the entry and its delimiter are not split
+		// over two segments
+		return conn.Write(append(b[:len(b):len(b)], o.delimiter...))
+	})
+}
+
+// WriteBinary sends the binary log entry, prefixed with its length
+// over TCP.
+func (o *Output) WriteBinary(b []byte) (int, error) {
+	return o.send(func(conn net.Conn) (int, error) {
+		if !o.stream {
+			return conn.Write(b)
+		}
+		return output.WriteLength(conn, b)
+	})
+}
+
+// send writes with write over the connection.  If the connection
+// fails it reconnects and writes again, once.
+func (o *Output) send(write func(net.Conn) (int, error)) (int, error) {
+	if o.conn != nil {
+		if n, err := write(o.conn); err == nil {
+			return n, nil
+		}
+	}
+	if err := o.connect(); err != nil {
+		return 0, err
+	}
+	return write(o.conn)
+}
+
+func (o *Output) connect() error {
+	if o.conn != nil {
+		o.conn.Close()
+		o.conn = nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	conn, err := o.dialer.DialContext(ctx, o.network, o.addr)
+	if err != nil {
+		return err
+	}
+	o.conn = conn
+	return nil
+}
+
+// Close closes the connection.
+func (o *Output) Close() error {
+	if o.conn == nil {
+		return nil
+	}
+	err := o.conn.Close()
+	o.conn = nil
+	return err
+}
+
+func (o *Output) NewInterval() error {
+	return nil
+}
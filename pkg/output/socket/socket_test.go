@@ -0,0 +1,63 @@
+package socket
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer l.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		b, _ := io.ReadAll(conn)
+		received <- b
+	}()
+
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "network": "tcp", "host": host, "port": port, "delimiter": "\r\n"})
+	assert.Nil(t, err)
+	o, err := New(c)
+	assert.Nil(t, err)
+	_, err = o.Write([]byte("hello"))
+	assert.Nil(t, err)
+	_, err = o.(*Output).WriteBinary([]byte{0x00, 0x0a, 0xff})
+	assert.Nil(t, err)
+	assert.Nil(t, o.Close())
+	assert.Equal(t, []byte("hello\r\n\x00\x00\x00\x03\x00\x0a\xff"), <-received)
+}
+
+func TestUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer pc.Close()
+
+	host, port, _ := net.SplitHostPort(pc.LocalAddr().String())
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "network": "udp", "host": host, "port": port})
+	assert.Nil(t, err)
+	o, err := New(c)
+	assert.Nil(t, err)
+	defer o.Close()
+
+	buf := make([]byte, 1024)
+	_, err = o.Write([]byte("hello"))
+	assert.Nil(t, err)
+	n, _, err := pc.ReadFrom(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(buf[:n]))
+
+	_, err = o.(*Output).WriteBinary([]byte{0x00, 0x0a, 0xff})
+	assert.Nil(t, err)
+	n, _, err = pc.ReadFrom(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00, 0x0a, 0xff}, buf[:n])
+}
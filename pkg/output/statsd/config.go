@@ -0,0 +1,111 @@
+package statsd
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+type config struct {
+	Type          string         `config:"type" validate:"required"`
+	Address       string         `config:"address"`
+	Protocol      string         `config:"protocol"`
+	Prefix        string         `config:"prefix"`
+	Tags          []string       `config:"tags"`
+	Metrics       []metricConfig `config:"metrics" validate:"required"`
+	MaxPacketSize int            `config:"max_packet_size"`
+}
+
+// metricConfig is a metric of the events, the value of field or 1 for
+// counters without field, tagged with the values of the fields of tags.
+type metricConfig struct {
+	Name       string   `config:"name" validate:"required"`
+	Type       string   `config:"type" validate:"required"`
+	Field      string   `config:"field"`
+	Tags       []string `config:"tags"`
+	SampleRate float64  `config:"sample_rate"`
+}
+
+// Protocols of the output.
+const (
+	ProtocolStatsd    = "statsd"
+	ProtocolDogStatsd = "dogstatsd"
+)
+
+// Types of the metrics.
+const (
+	TypeCounter      = "counter"
+	TypeGauge        = "gauge"
+	TypeTimer        = "timer"
+	TypeHistogram    = "histogram"
+	TypeDistribution = "distribution"
+	TypeSet          = "set"
+)
+
+var types = [...]string{TypeCounter, TypeGauge, TypeTimer, TypeHistogram, TypeDistribution, TypeSet}
+
+// reserved are the characters that can not be in the names of metrics.
+const reserved = ":|@#,"
+
+func defaultConfig() config {
+	return config{
+		Type:          Name,
+		Address:       "localhost:8125",
+		Protocol:      ProtocolDogStatsd,
+		MaxPacketSize: 1432,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return fmt.Errorf("'%s' is not a valid value for 'address' expected host:port", c.Address)
+	}
+	if c.Protocol != ProtocolStatsd && c.Protocol != ProtocolDogStatsd {
+		return fmt.Errorf("'%s' is not a valid value for 'protocol' expected '%s' or '%s'", c.Protocol, ProtocolStatsd, ProtocolDogStatsd)
+	}
+	if strings.ContainsAny(c.Prefix, reserved) {
+		return fmt.Errorf("'%s' is not a valid value for 'prefix' expected no '%s'", c.Prefix, reserved)
+	}
+	tagged := len(c.Tags) > 0
+	for _, t := range c.Tags {
+		if t == "" || strings.ContainsAny(t, "|#,") {
+			return fmt.Errorf("'%s' is not a valid value for 'tags' expected a tag without '|', '#' or ','", t)
+		}
+	}
+	if len(c.Metrics) == 0 {
+		return fmt.Errorf("you must specify at least one of 'metrics'")
+	}
+	for _, m := range c.Metrics {
+		tagged = tagged || len(m.Tags) > 0
+	}
+	if tagged && c.Protocol != ProtocolDogStatsd {
+		return fmt.Errorf("'tags' can only be used with protocol '%s'", ProtocolDogStatsd)
+	}
+	if c.MaxPacketSize <= 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'max_packet_size' expected a value greater than 0", c.MaxPacketSize)
+	}
+	return nil
+}
+
+func (c *metricConfig) Validate() error {
+	if strings.ContainsAny(c.Name, reserved) {
+		return fmt.Errorf("'%s' is not a valid value for 'name' expected no '%s'", c.Name, reserved)
+	}
+	valid := false
+	for _, t := range types {
+		valid = valid || c.Type == t
+	}
+	if !valid {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, strings.Join(types[:], ", "))
+	}
+	if c.Field == "" && c.Type != TypeCounter {
+		return fmt.Errorf("'field' is required for metrics of type '%s'", c.Type)
+	}
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return fmt.Errorf("'%g' is not a valid value for 'sample_rate' expected a value between 0 and 1", c.SampleRate)
+	}
+	return nil
+}
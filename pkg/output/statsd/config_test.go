@@ -0,0 +1,95 @@
+package statsd
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	counter := []map[string]interface{}{{"name": "events", "type": "counter"}}
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name, "metrics": counter},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Options": {
+			c:           map[string]interface{}{"type": Name, "address": "127.0.0.1:8125", "prefix": "spigot.", "tags": []string{"env:test"}, "max_packet_size": 8932, "metrics": []map[string]interface{}{{"name": "latency", "type": "distribution", "field": "event.duration", "tags": []string{"host.name"}, "sample_rate": 0.1}}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob", "metrics": counter},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'statsd' accessing config",
+		},
+		"Invalid Address": {
+			c:           map[string]interface{}{"type": Name, "address": "localhost", "metrics": counter},
+			hasError:    true,
+			errorString: "'localhost' is not a valid value for 'address' expected host:port accessing config",
+		},
+		"Invalid Protocol": {
+			c:           map[string]interface{}{"type": Name, "protocol": "graphite", "metrics": counter},
+			hasError:    true,
+			errorString: "'graphite' is not a valid value for 'protocol' expected 'statsd' or 'dogstatsd' accessing config",
+		},
+		"Tags Without DogStatsD": {
+			c:           map[string]interface{}{"type": Name, "protocol": "statsd", "tags": []string{"env:test"}, "metrics": counter},
+			hasError:    true,
+			errorString: "'tags' can only be used with protocol 'dogstatsd' accessing config",
+		},
+		"No Metrics": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    true,
+			errorString: "missing required field accessing 'metrics'",
+		},
+		"Invalid Metric Type": {
+			c:           map[string]interface{}{"type": Name, "metrics": []map[string]interface{}{{"name": "events", "type": "meter"}}},
+			hasError:    true,
+			errorString: "'meter' is not a valid value for 'type' expected 'counter, gauge, timer, histogram, distribution, set' accessing 'metrics.0'",
+		},
+		"Invalid Metric Name": {
+			c:           map[string]interface{}{"type": Name, "metrics": []map[string]interface{}{{"name": "events:total", "type": "counter"}}},
+			hasError:    true,
+			errorString: "'events:total' is not a valid value for 'name' expected no ':|@#,' accessing 'metrics.0'",
+		},
+		"No Field": {
+			c:           map[string]interface{}{"type": Name, "metrics": []map[string]interface{}{{"name": "load", "type": "gauge"}}},
+			hasError:    true,
+			errorString: "'field' is required for metrics of type 'gauge' accessing 'metrics.0'",
+		},
+		"Invalid Sample Rate": {
+			c:           map[string]interface{}{"type": Name, "metrics": []map[string]interface{}{{"name": "events", "type": "counter", "sample_rate": 1.5}}},
+			hasError:    true,
+			errorString: "'1.5' is not a valid value for 'sample_rate' expected a value between 0 and 1 accessing 'metrics.0'",
+		},
+		"Invalid Max Packet Size": {
+			c:           map[string]interface{}{"type": Name, "max_packet_size": 0, "metrics": counter},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'max_packet_size' expected a value greater than 0 accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, err.Error(), tc.errorString, name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Package statsd implements the output of metrics of structured
+// events, such as those of the json formats of the generators, to a
+// statsd or DogStatsD server over UDP, to compare metrics extracted by
+// a pipeline from the events with metrics emitted directly.
+//
+// Every event is one of each of the metrics: a counter, gauge, timer,
+// histogram, distribution or set of the value of its field.  Counters
+// without field count the events.  Events without the field, or with a
+// value that is not a number for metrics other than sets, leave the
+// metric out.  prefix is prepended to the names of the metrics.
+//
+// With protocol dogstatsd, the default, the metrics are tagged with
+// tags, such as "env:test", and with "<field>:<value>" of each of the
+// fields of the tags of the metric that are set in the event.  With
+// protocol statsd there are no tags.  A sample_rate below 1 sends the
+// metric for that fraction of the events only, with the rate for the
+// server to scale it.
+//
+// The metrics are sent in datagrams of at most max_packet_size bytes,
+// 1432 by default, with each new interval and on close.
+//
+//	output:
+//	  type: statsd
+//	  address: "localhost:8125"
+//	  prefix: "spigot."
+//	  tags: ["env:test"]
+//	  metrics:
+//	    - {name: requests, type: counter, tags: [http.request.method, http.response.status_code]}
+//	    - {name: bytes, type: histogram, field: http.response.body.bytes}
+//	    - {name: duration, type: timer, field: event.duration, sample_rate: 0.1}
+//	    - {name: clients, type: set, field: source.ip}
+package statsd
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// Name is the name of the output in the configuration file and registry.
+const Name = "statsd"
+
+// suffixes are the statsd types of the metrics.
+var suffixes = map[string]string{
+	TypeCounter:      "c",
+	TypeGauge:        "g",
+	TypeTimer:        "ms",
+	TypeHistogram:    "h",
+	TypeDistribution: "d",
+	TypeSet:          "s",
+}
+
+// tagEscaper replaces the characters that can not be in tags.
+var tagEscaper = strings.NewReplacer("|", "_", "#", "_", ",", "_", "\n", "_")
+
+// Output buffers the metrics of the events until they are sent.
+type Output struct {
+	conn    net.Conn
+	tags    []string
+	metrics []metric
+	maxSize int
+	buf     bytes.Buffer
+	random  func() float64
+}
+
+type metric struct {
+	name   string
+	suffix string
+	field  string
+	tags   []string
+	rate   float64
+}
+
+func init() {
+	output.Register(Name, New)
+}
+
+// New is the Factory for creating a new statsd output.
+func New(cfg *ucfg.Config) (output.Output, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	conn, err := net.Dial("udp", c.Address)
+	if err != nil {
+		return nil, err
+	}
+	o := &Output{
+		conn:    conn,
+		tags:    c.Tags,
+		maxSize: c.MaxPacketSize,
+		random:  rand.Float64,
+	}
+	for _, m := range c.Metrics {
+		rate := m.SampleRate
+		if rate == 0 {
+			rate = 1
+		}
+		o.metrics = append(o.metrics, metric{
+			name:   c.Prefix + m.Name,
+			suffix: suffixes[m.Type],
+			field:  m.Field,
+			tags:   m.Tags,
+			rate:   rate,
+		})
+	}
+	return o, nil
+}
+
+// Write buffers the metrics of the event, and sends the buffered
+// metrics when a datagram is full.
+func (o *Output) Write(p []byte) (int, error) {
+	event, err := output.Event(Name, p)
+	if err != nil {
+		return 0, err
+	}
+	for _, m := range o.metrics {
+		line, ok := o.line(m, event)
+		if !ok {
+			continue
+		}
+		if o.buf.Len() > 0 && o.buf.Len()+1+len(line) > o.maxSize {
+			if err := o.flush(); err != nil {
+				return 0, err
+			}
+		}
+		if o.buf.Len() > 0 {
+			o.buf.WriteByte('\n')
+		}
+		o.buf.WriteString(line)
+	}
+	return len(p), nil
+}
+
+// line returns the metric m of the event, and whether it is sent.
+//
+// Example:
+//
+// spigot.requests:1|c|#env:test,http.request.method:GET
+func (o *Output) line(m metric, event map[string]interface{}) (string, bool) {
+	value := "1"
+	if m.field != "" {
+		v, ok := output.Field(event, m.field)
+		if !ok {
+			return "", false
+		}
+		if value, ok = metricValue(m.suffix, v); !ok {
+			return "", false
+		}
+	}
+	if m.rate < 1 && o.random() >= m.rate {
+		return "", false
+	}
+
+	var b strings.Builder
+	b.WriteString(m.name)
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(m.suffix)
+	if m.rate < 1 {
+		b.WriteString("|@")
+		b.WriteString(strconv.FormatFloat(m.rate, 'g', -1, 64))
+	}
+	sep := "|#"
+	for _, t := range o.tags {
+		b.WriteString(sep)
+		sep = ","
+		b.WriteString(t)
+	}
+	for _, f := range m.tags {
+		v, ok := output.Field(event, f)
+		if !ok {
+			continue
+		}
+		s, ok := scalar(v)
+		if !ok {
+			continue
+		}
+		b.WriteString(sep)
+		sep = ","
+		b.WriteString(tagEscaper.Replace(f + ":" + s))
+	}
+	return b.String(), true
+}
+
+// metricValue returns the value v of a field as the value of a metric
+// of type suffix, and whether it is one.  Sets have any value, the
+// other metrics numbers.
+func metricValue(suffix string, v interface{}) (string, bool) {
+	s, ok := scalar(v)
+	if !ok {
+		return "", false
+	}
+	if suffix == suffixes[TypeSet] {
+		return tagEscaper.Replace(strings.ReplaceAll(s, ":", "_")), s != ""
+	}
+	if _, isBool := v.(bool); isBool {
+		return "", false
+	}
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// scalar returns the text of a string, number or boolean v, and whether
+// it is one.
+func scalar(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// flush sends the buffered metrics as one datagram.
+func (o *Output) flush() error {
+	if o.buf.Len() == 0 {
+		return nil
+	}
+	_, err := o.conn.Write(o.buf.Bytes())
+	o.buf.Reset()
+	return err
+}
+
+// Close sends the buffered metrics and closes the connection.
+func (o *Output) Close() error {
+	err := o.flush()
+	if cerr := o.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// NewInterval sends the buffered metrics.
+func (o *Output) NewInterval() error {
+	return o.flush()
+}
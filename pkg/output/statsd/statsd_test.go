@@ -0,0 +1,85 @@
+package statsd
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOutput(t *testing.T, c map[string]interface{}) (*Output, net.PacketConn) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	c["address"] = pc.LocalAddr().String()
+	cfg, err := ucfg.NewFrom(c)
+	assert.Nil(t, err)
+	o, err := New(cfg)
+	assert.Nil(t, err)
+	return o.(*Output), pc
+}
+
+func read(t *testing.T, pc net.PacketConn) string {
+	buf := make([]byte, 2048)
+	assert.Nil(t, pc.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := pc.ReadFrom(buf)
+	assert.Nil(t, err)
+	return string(buf[:n])
+}
+
+func TestWrite(t *testing.T) {
+	o, pc := newTestOutput(t, map[string]interface{}{
+		"type":   Name,
+		"prefix": "spigot.",
+		"tags":   []string{"env:test"},
+		"metrics": []map[string]interface{}{
+			{"name": "requests", "type": "counter", "tags": []string{"http.request.method", "http.response.status_code"}},
+			{"name": "bytes", "type": "histogram", "field": "http.response.body.bytes"},
+			{"name": "duration", "type": "timer", "field": "event.duration", "sample_rate": 0.5},
+			{"name": "clients", "type": "set", "field": "source.ip"},
+		},
+	})
+	defer pc.Close()
+	random := []float64{0.2, 0.7}
+	o.random = func() float64 {
+		r := random[0]
+		random = random[1:]
+		return r
+	}
+
+	_, err := o.Write([]byte(`{"http":{"request":{"method":"GET"},"response":{"status_code":200,"body":{"bytes":1024}}},"event":{"duration":12.5},"source":{"ip":"10.0.0.1"}}`))
+	assert.Nil(t, err)
+	_, err = o.Write([]byte(`{"http":{"request":{"method":"P|OST"},"response":{"body":{"bytes":"n/a"}}},"event":{"duration":3}}`))
+	assert.Nil(t, err)
+	assert.Nil(t, o.NewInterval())
+	assert.Equal(t, `spigot.requests:1|c|#env:test,http.request.method:GET,http.response.status_code:200
+spigot.bytes:1024|h|#env:test
+spigot.duration:12.5|ms|@0.5|#env:test
+spigot.clients:10.0.0.1|s|#env:test
+spigot.requests:1|c|#env:test,http.request.method:P_OST`, read(t, pc))
+
+	_, err = o.Write([]byte(`["not", "an", "object"]`))
+	assert.EqualError(t, err, "statsd output expected a JSON object, use a json format of the generator")
+	assert.Nil(t, o.Close())
+}
+
+func TestMaxPacketSize(t *testing.T) {
+	o, pc := newTestOutput(t, map[string]interface{}{
+		"type":            Name,
+		"protocol":        "statsd",
+		"max_packet_size": 30,
+		"metrics": []map[string]interface{}{
+			{"name": "load", "type": "gauge", "field": "load"},
+		},
+	})
+	defer pc.Close()
+
+	for _, e := range []string{`{"load":0.25}`, `{"load":0.5}`, `{"load":1.75}`} {
+		_, err := o.Write([]byte(e))
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, "load:0.25|g\nload:0.5|g", read(t, pc))
+	assert.Nil(t, o.Close())
+	assert.Equal(t, "load:1.75|g", read(t, pc))
+}
@@ -1,21 +1,64 @@
 //go:build !windows
 package syslog
 
-import "fmt"
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/leehinman/spigot/pkg/output"
+	"github.com/leehinman/spigot/pkg/output/proxy"
+)
 
 type config struct {
-	Type     string `config:"type" validate:"required"`
-	Facility string `config:"facility"`
-	Severity string `config:"severity"`
-	Tag      string `config:"tag"`
-	Network  string `config:"network" validate:"required"`
-	Host     string `config:"host" validate:"required"`
-	Port     string `config:"port" validate:"required"`
+	Type         string       `config:"type" validate:"required"`
+	Facility     string       `config:"facility"`
+	Severity     string       `config:"severity"`
+	Tag          string       `config:"tag"`
+	Network      string       `config:"network" validate:"required"`
+	Host         string       `config:"host" validate:"required"`
+	Port         string       `config:"port" validate:"required"`
+	Proxy        proxy.Config `config:"proxy"`
+	LocalAddress string       `config:"local_address"`
+	SpoofSources []string     `config:"spoof_sources"`
+	Devices      devices      `config:"devices"`
+	Pcap         string       `config:"pcap"`
+	Multiline    string       `config:"multiline"`
+	Framing      string       `config:"framing"`
+	TLS          tlsConfig    `config:"tls"`
+	Churn        churn        `config:"churn"`
+}
+
+// devices is the pool of simulated devices that send the events.
+type devices struct {
+	Count     int      `config:"count"`
+	Hostname  string   `config:"hostname"`
+	Addresses []string `config:"addresses"`
+}
+
+// churn drops the connections to the server, after events events or
+// when they are older than interval, to exercise the reconnects of the
+// server.  partial is the percentage of the drops in the middle of a
+// message and abort the percentage of the drops without a clean close.
+type churn struct {
+	Events   int           `config:"events"`
+	Interval time.Duration `config:"interval"`
+	Partial  int           `config:"partial"`
+	Abort    int           `config:"abort"`
+}
+
+func (c churn) enabled() bool {
+	return c.Events > 0 || c.Interval > 0
 }
 
 func defaultConfig() config {
 	return config{
-		Type: Name,
+		Type:      Name,
+		Multiline: MultilineEscape,
+		Devices: devices{
+			Hostname: "device%d",
+		},
 	}
 }
 
@@ -23,5 +66,103 @@ func (c *config) Validate() error {
 	if c.Type != Name {
 		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
 	}
+	if _, ok := lookup(facilities[:], c.Facility); c.Facility != "" && !ok {
+		return fmt.Errorf("'%s' is not a valid value for 'facility' expected '%s'", c.Facility, names(facilities[:]))
+	}
+	if _, ok := lookup(severities[:], c.Severity); c.Severity != "" && !ok {
+		return fmt.Errorf("'%s' is not a valid value for 'severity' expected '%s'", c.Severity, names(severities[:]))
+	}
+	if c.Proxy.Enabled() && !strings.HasPrefix(c.Network, "tcp") {
+		return fmt.Errorf("'%s' network can not be used with a proxy", c.Network)
+	}
+	if _, err := output.LocalAddr(c.Network, c.LocalAddress); err != nil {
+		return err
+	}
+	if len(c.SpoofSources) > 0 {
+		if c.Network != "udp" && c.Network != "udp4" {
+			return fmt.Errorf("'spoof_sources' can not be used with '%s' network expected 'udp'", c.Network)
+		}
+		if c.LocalAddress != "" {
+			return fmt.Errorf("'spoof_sources' can not be used with 'local_address'")
+		}
+		if _, err := c.sources(); err != nil {
+			return err
+		}
+		if c.Pcap != "" {
+			return fmt.Errorf("'pcap' can not be used with 'spoof_sources'")
+		}
+	}
+	if c.Multiline != MultilineEscape && c.Multiline != MultilineSplit && c.Multiline != MultilineRaw {
+		return fmt.Errorf("'%s' is not a valid value for 'multiline' expected '%s'", c.Multiline, strings.Join([]string{MultilineEscape, MultilineSplit, MultilineRaw}, ", "))
+	}
+	if c.Framing != "" && c.Framing != FramingNonTransparent && c.Framing != FramingOctetCounting {
+		return fmt.Errorf("'%s' is not a valid value for 'framing' expected '%s' or '%s'", c.Framing, FramingNonTransparent, FramingOctetCounting)
+	}
+	if c.Framing == FramingOctetCounting && !strings.HasPrefix(c.Network, "tcp") {
+		return fmt.Errorf("'framing' %s can not be used with '%s' network expected 'tcp'", FramingOctetCounting, c.Network)
+	}
+	if c.TLS.Enabled && !strings.HasPrefix(c.Network, "tcp") {
+		return fmt.Errorf("'tls' can not be used with '%s' network expected 'tcp'", c.Network)
+	}
+	if err := c.Churn.validate(c.Network); err != nil {
+		return err
+	}
+	if c.Devices.Count < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'devices.count' expected 0 or more", c.Devices.Count)
+	}
+	if c.Devices.Count > 0 || len(c.Devices.Addresses) > 0 {
+		if len(c.SpoofSources) > 0 {
+			return fmt.Errorf("'devices' can not be used with 'spoof_sources'")
+		}
+		if h := fmt.Sprintf(c.Devices.Hostname, 1); strings.Contains(h, "%!") || h == fmt.Sprintf(c.Devices.Hostname, 2) {
+			return fmt.Errorf("'%s' is not a valid value for 'devices.hostname' expected one %%d for the device number", c.Devices.Hostname)
+		}
+	}
+	if len(c.Devices.Addresses) > 0 && c.LocalAddress != "" {
+		return fmt.Errorf("'devices.addresses' can not be used with 'local_address'")
+	}
+	for _, v := range c.Devices.Addresses {
+		if net.ParseIP(v) == nil {
+			return fmt.Errorf("'%s' is not a valid value for 'devices.addresses' expected an IP address", v)
+		}
+	}
+	return nil
+}
+
+// sources returns the networks of spoof_sources.
+func (c *config) sources() ([]*net.IPNet, error) {
+	var sources []*net.IPNet
+	for _, v := range c.SpoofSources {
+		_, n, err := net.ParseCIDR(v)
+		if err != nil || n.IP.To4() == nil {
+			return nil, fmt.Errorf("'%s' is not a valid value for 'spoof_sources' expected an IPv4 CIDR", v)
+		}
+		sources = append(sources, n)
+	}
+	return sources, nil
+}
+
+func (c churn) validate(network string) error {
+	if c.Events < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'churn.events' expected 0 or more", c.Events)
+	}
+	if c.Interval < 0 {
+		return fmt.Errorf("'%v' is not a valid value for 'churn.interval' expected 0 or more", c.Interval)
+	}
+	if c.Partial < 0 || c.Partial > 100 {
+		return fmt.Errorf("'%v' is not a valid value for 'churn.partial' expected a percentage from 0 to 100", c.Partial)
+	}
+	if c.Abort < 0 || c.Abort > 100 {
+		return fmt.Errorf("'%v' is not a valid value for 'churn.abort' expected a percentage from 0 to 100", c.Abort)
+	}
+	if !c.enabled() {
+		if c.Partial > 0 || c.Abort > 0 {
+			return fmt.Errorf("'churn.partial' and 'churn.abort' can only be used with 'churn.events' or 'churn.interval'")
+		}
+		return nil
+	}
+	if !strings.HasPrefix(network, "tcp") {
+		return fmt.Errorf("'churn' can not be used with '%s' network expected 'tcp'", network)
+	}
 	return nil
 }
@@ -19,6 +19,121 @@ func TestConfigs(t *testing.T) {
 			hasError:    true,
 			errorString: "'Bob' is not a valid value for 'type' expected 'syslog' accessing config",
 		},
+		"Invalid Facility": {
+			c:           map[string]interface{}{"type": Name, "network": "tcp", "host": "localhost", "port": "1234", "facility": "LOG_LOCAL8"},
+			hasError:    true,
+			errorString: "'LOG_LOCAL8' is not a valid value for 'facility' expected 'LOG_KERN, LOG_USER, LOG_MAIL, LOG_DAEMON, LOG_AUTH, LOG_SYSLOG, LOG_LPR, LOG_NEWS, LOG_UUCP, LOG_CRON, LOG_AUTHPRIV, LOG_FTP, LOG_LOCAL0, LOG_LOCAL1, LOG_LOCAL2, LOG_LOCAL3, LOG_LOCAL4, LOG_LOCAL5, LOG_LOCAL6, LOG_LOCAL7' accessing config",
+		},
+		"Invalid Severity": {
+			c:           map[string]interface{}{"type": Name, "network": "tcp", "host": "localhost", "port": "1234", "severity": "LOG_ERROR"},
+			hasError:    true,
+			errorString: "'LOG_ERROR' is not a valid value for 'severity' expected 'LOG_EMERG, LOG_ALERT, LOG_CRIT, LOG_ERR, LOG_WARNING, LOG_NOTICE, LOG_INFO, LOG_DEBUG' accessing config",
+		},
+		"Proxy with UDP": {
+			c:           map[string]interface{}{"type": Name, "network": "udp", "host": "localhost", "port": "1234", "proxy": map[string]interface{}{"url": "socks5://proxy:1080"}},
+			hasError:    true,
+			errorString: "'udp' network can not be used with a proxy accessing config",
+		},
+		"Invalid Local Address": {
+			c:           map[string]interface{}{"type": Name, "network": "tcp", "host": "localhost", "port": "1234", "local_address": "eth0"},
+			hasError:    true,
+			errorString: "'eth0' is not a valid value for 'local_address' expected an IP address with an optional port accessing config",
+		},
+		"Spoof with TCP": {
+			c:           map[string]interface{}{"type": Name, "network": "tcp", "host": "localhost", "port": "1234", "spoof_sources": []string{"10.0.0.0/8"}},
+			hasError:    true,
+			errorString: "'spoof_sources' can not be used with 'tcp' network expected 'udp' accessing config",
+		},
+		"Invalid Spoof Source": {
+			c:           map[string]interface{}{"type": Name, "network": "udp", "host": "localhost", "port": "1234", "spoof_sources": []string{"2001:db8::/32"}},
+			hasError:    true,
+			errorString: "'2001:db8::/32' is not a valid value for 'spoof_sources' expected an IPv4 CIDR accessing config",
+		},
+		"Devices": {
+			c:           map[string]interface{}{"type": Name, "network": "udp", "host": "localhost", "port": "1234", "devices": map[string]interface{}{"count": 10, "hostname": "fw-%03d"}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Device Hostname": {
+			c:           map[string]interface{}{"type": Name, "network": "udp", "host": "localhost", "port": "1234", "devices": map[string]interface{}{"count": 10, "hostname": "fw"}},
+			hasError:    true,
+			errorString: "'fw' is not a valid value for 'devices.hostname' expected one %d for the device number accessing config",
+		},
+		"Invalid Device Address": {
+			c:           map[string]interface{}{"type": Name, "network": "udp", "host": "localhost", "port": "1234", "devices": map[string]interface{}{"addresses": []string{"10.9.0.11/32"}}},
+			hasError:    true,
+			errorString: "'10.9.0.11/32' is not a valid value for 'devices.addresses' expected an IP address accessing config",
+		},
+		"Devices with Spoof Sources": {
+			c:           map[string]interface{}{"type": Name, "network": "udp", "host": "localhost", "port": "1234", "spoof_sources": []string{"10.0.0.0/8"}, "devices": map[string]interface{}{"count": 10}},
+			hasError:    true,
+			errorString: "'devices' can not be used with 'spoof_sources' accessing config",
+		},
+		"Pcap with Spoof Sources": {
+			c:           map[string]interface{}{"type": Name, "network": "udp", "host": "localhost", "port": "1234", "spoof_sources": []string{"10.0.0.0/8"}, "pcap": "/tmp/spigot.pcap"},
+			hasError:    true,
+			errorString: "'pcap' can not be used with 'spoof_sources' accessing config",
+		},
+		"Invalid Multiline": {
+			c:           map[string]interface{}{"type": Name, "network": "tcp", "host": "localhost", "port": "1234", "multiline": "join"},
+			hasError:    true,
+			errorString: "'join' is not a valid value for 'multiline' expected 'escape, split, raw' accessing config",
+		},
+		"Invalid Framing": {
+			c:           map[string]interface{}{"type": Name, "network": "tcp", "host": "localhost", "port": "1234", "framing": "lf"},
+			hasError:    true,
+			errorString: "'lf' is not a valid value for 'framing' expected 'non-transparent' or 'octet-counting' accessing config",
+		},
+		"Octet Counting over UDP": {
+			c:           map[string]interface{}{"type": Name, "network": "udp", "host": "localhost", "port": "1234", "framing": "octet-counting"},
+			hasError:    true,
+			errorString: "'framing' octet-counting can not be used with 'udp' network expected 'tcp' accessing config",
+		},
+		"TLS over UDP": {
+			c:           map[string]interface{}{"type": Name, "network": "udp", "host": "localhost", "port": "1234", "tls": map[string]interface{}{"enabled": true}},
+			hasError:    true,
+			errorString: "'tls' can not be used with 'udp' network expected 'tcp' accessing config",
+		},
+		"TLS Certificate without Key": {
+			c:           map[string]interface{}{"type": Name, "network": "tcp", "host": "localhost", "port": "1234", "tls": map[string]interface{}{"enabled": true, "certificate": "client.pem"}},
+			hasError:    true,
+			errorString: "'certificate' and 'key' must be used together accessing 'tls'",
+		},
+		"TLS Fingerprints with CA": {
+			c:           map[string]interface{}{"type": Name, "network": "tcp", "host": "localhost", "port": "1234", "tls": map[string]interface{}{"enabled": true, "ca": "ca.pem", "fingerprints": []string{"sha-1:00:01:02:03:04:05:06:07:08:09:0A:0B:0C:0D:0E:0F:10:11:12:13"}}},
+			hasError:    true,
+			errorString: "'fingerprints' can not be used with 'ca' accessing 'tls'",
+		},
+		"TLS Invalid Fingerprint": {
+			c:           map[string]interface{}{"type": Name, "network": "tcp", "host": "localhost", "port": "1234", "tls": map[string]interface{}{"enabled": true, "fingerprints": []string{"md5:00:01:02:03:04:05:06:07:08:09:0A:0B:0C:0D:0E:0F"}}},
+			hasError:    true,
+			errorString: "'md5:00:01:02:03:04:05:06:07:08:09:0A:0B:0C:0D:0E:0F' is not a valid value for 'fingerprints' expected a fingerprint like 'sha-256:E1:2D:...' accessing 'tls'",
+		},
+		"TLS Missing CA": {
+			c:           map[string]interface{}{"type": Name, "network": "tcp", "host": "localhost", "port": "1234", "tls": map[string]interface{}{"enabled": true, "ca": "/nonexistent/ca.pem"}},
+			hasError:    true,
+			errorString: "open /nonexistent/ca.pem: no such file or directory",
+		},
+		"Invalid Churn Events": {
+			c:           map[string]interface{}{"type": Name, "network": "tcp", "host": "localhost", "port": "1234", "churn": map[string]interface{}{"events": -1}},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'churn.events' expected 0 or more accessing config",
+		},
+		"Invalid Churn Partial": {
+			c:           map[string]interface{}{"type": Name, "network": "tcp", "host": "localhost", "port": "1234", "churn": map[string]interface{}{"events": 10, "partial": 101}},
+			hasError:    true,
+			errorString: "'101' is not a valid value for 'churn.partial' expected a percentage from 0 to 100 accessing config",
+		},
+		"Churn Abort without Drops": {
+			c:           map[string]interface{}{"type": Name, "network": "tcp", "host": "localhost", "port": "1234", "churn": map[string]interface{}{"abort": 10}},
+			hasError:    true,
+			errorString: "'churn.partial' and 'churn.abort' can only be used with 'churn.events' or 'churn.interval' accessing config",
+		},
+		"Churn over UDP": {
+			c:           map[string]interface{}{"type": Name, "network": "udp", "host": "localhost", "port": "1234", "churn": map[string]interface{}{"interval": "1m"}},
+			hasError:    true,
+			errorString: "'churn' can not be used with 'udp' network expected 'tcp' accessing config",
+		},
 		"No Type": {
 			c:           map[string]interface{}{"type": "", "network": "tcp", "host": "localhost", "port": "1234"},
 			hasError:    true,
@@ -0,0 +1,125 @@
+//go:build linux
+
+package syslog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"syscall"
+)
+
+// maxPayload is the largest message of a UDP datagram over IPv4.
+const maxPayload = 65535 - 20 - 8
+
+// spoofConn sends UDP datagrams with source addresses from the
+// sources networks, over a raw socket.
+type spoofConn struct {
+	fd      int
+	dst     *net.UDPAddr
+	sources []*net.IPNet
+	id      uint16
+}
+
+// newSpoofConn opens a raw socket to send to addr, which requires the
+// CAP_NET_RAW capability.
+func newSpoofConn(sources []*net.IPNet, addr string) (io.WriteCloser, error) {
+	dst, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return nil, err
+	}
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_RAW, syscall.IPPROTO_RAW)
+	if err != nil {
+		if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EACCES) {
+			return nil, fmt.Errorf("'spoof_sources' needs the CAP_NET_RAW capability: %w", err)
+		}
+		return nil, err
+	}
+	return &spoofConn{fd: fd, dst: dst, sources: sources, id: uint16(rand.IntN(65536))}, nil
+}
+
+// Write sends b as one datagram from a random source address and port.
+func (s *spoofConn) Write(b []byte) (int, error) {
+	if len(b) > maxPayload {
+		return 0, fmt.Errorf("message of %d bytes is larger than a UDP datagram", len(b))
+	}
+	src := randomIP(s.sources[rand.IntN(len(s.sources))])
+	s.id++
+	pkt := packet(src, s.dst.IP.To4(), uint16(rand.IntN(65536-1024)+1024), uint16(s.dst.Port), s.id, b)
+
+	sa := &syscall.SockaddrInet4{}
+	copy(sa.Addr[:], s.dst.IP.To4())
+	if err := syscall.Sendto(s.fd, pkt, 0, sa); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Close closes the raw socket.
+func (s *spoofConn) Close() error {
+	return syscall.Close(s.fd)
+}
+
+// randomIP returns a random address of n.
+func randomIP(n *net.IPNet) net.IP {
+	ip := make(net.IP, 4)
+	r := rand.Uint32()
+	base := binary.BigEndian.Uint32(n.IP.To4())
+	mask := binary.BigEndian.Uint32(n.Mask)
+	binary.BigEndian.PutUint32(ip, base&mask|r&^mask)
+	return ip
+}
+
+// packet returns the IPv4 packet of a UDP datagram with payload.
+func packet(src, dst net.IP, srcPort, dstPort, id uint16, payload []byte) []byte {
+	udpLen := 8 + len(payload)
+	pkt := make([]byte, 20+udpLen)
+
+	// IPv4 header, without options
+	pkt[0] = 0x45
+	binary.BigEndian.PutUint16(pkt[2:], uint16(len(pkt)))
+	binary.BigEndian.PutUint16(pkt[4:], id)
+	pkt[8] = 64
+	pkt[9] = syscall.IPPROTO_UDP
+	copy(pkt[12:16], src.To4())
+	copy(pkt[16:20], dst.To4())
+	binary.BigEndian.PutUint16(pkt[10:], checksum(pkt[:20], 0))
+
+	// UDP header, with the checksum over the pseudo header
+	udp := pkt[20:]
+	binary.BigEndian.PutUint16(udp[0:], srcPort)
+	binary.BigEndian.PutUint16(udp[2:], dstPort)
+	binary.BigEndian.PutUint16(udp[4:], uint16(udpLen))
+	copy(udp[8:], payload)
+	pseudo := sum(pkt[12:20], 0) + syscall.IPPROTO_UDP + uint32(udpLen)
+	c := checksum(udp, pseudo)
+	if c == 0 {
+		c = 0xffff
+	}
+	binary.BigEndian.PutUint16(udp[6:], c)
+
+	return pkt
+}
+
+// sum adds b as big endian 16 bit words to s.
+func sum(b []byte, s uint32) uint32 {
+	for i := 0; i+1 < len(b); i += 2 {
+		s += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if len(b)%2 == 1 {
+		s += uint32(b[len(b)-1]) << 8
+	}
+	return s
+}
+
+// checksum returns the internet checksum of b, starting with the sum s.
+func checksum(b []byte, s uint32) uint16 {
+	s = sum(b, s)
+	for s > 0xffff {
+		s = s>>16 + s&0xffff
+	}
+	return ^uint16(s)
+}
@@ -0,0 +1,41 @@
+//go:build linux
+
+package syslog
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPacket(t *testing.T) {
+	src, dst := net.ParseIP("10.20.30.40"), net.ParseIP("192.168.1.1")
+	pkt := packet(src, dst, 40000, 514, 7, []byte("<13>hello"))
+
+	assert.Len(t, pkt, 20+8+9)
+	assert.Equal(t, uint16(len(pkt)), binary.BigEndian.Uint16(pkt[2:]))
+	assert.Equal(t, uint16(0), checksum(pkt[:20], 0), "ip checksum")
+	assert.Equal(t, src.To4(), net.IP(pkt[12:16]))
+	assert.Equal(t, dst.To4(), net.IP(pkt[16:20]))
+
+	udp := pkt[20:]
+	assert.Equal(t, uint16(40000), binary.BigEndian.Uint16(udp[0:]))
+	assert.Equal(t, uint16(514), binary.BigEndian.Uint16(udp[2:]))
+	pseudo := sum(pkt[12:20], 0) + 17 + uint32(len(udp))
+	assert.Equal(t, uint16(0), checksum(udp, pseudo), "udp checksum")
+	assert.Equal(t, "<13>hello", string(udp[8:]))
+}
+
+func TestRandomIP(t *testing.T) {
+	_, n, err := net.ParseCIDR("10.20.0.0/16")
+	assert.Nil(t, err)
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		ip := randomIP(n)
+		assert.True(t, n.Contains(ip), ip.String())
+		seen[ip.String()] = true
+	}
+	assert.True(t, len(seen) > 1)
+}
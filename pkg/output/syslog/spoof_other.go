@@ -0,0 +1,15 @@
+//go:build !linux && !windows
+
+package syslog
+
+import (
+	"errors"
+	"io"
+	"net"
+)
+
+// newSpoofConn returns an error, spoofing source addresses needs the
+// raw sockets of Linux.
+func newSpoofConn(sources []*net.IPNet, addr string) (io.WriteCloser, error) {
+	return nil, errors.New("'spoof_sources' is only supported on Linux")
+}
@@ -3,8 +3,10 @@
 // Configuration:
 // "type", "network", "host", and "port" are all required.
 //
-// "facility" is optional and defaults to LOG.KERN
-// "severity" is optional and defaults to LOG.EMERG
+// "facility" is optional and defaults to LOG_KERN, one of LOG_KERN to
+// LOG_FTP and LOG_LOCAL0 to LOG_LOCAL7
+// "severity" is optional and defaults to LOG_EMERG, one of LOG_EMERG to
+// LOG_DEBUG
 // "tag" is optional tag to add to message
 //
 //	output:
@@ -12,25 +14,180 @@
 //	  network: tcp
 //	  host: localhost
 //	  port: 1234
+//
+// "proxy" is optional and connects through a SOCKS5 or HTTP proxy,
+// with "network" tcp.  See package proxy.
+//
+//	output:
+//	  type: syslog
+//	  network: tcp
+//	  host: collector.example.com
+//	  port: 514
+//	  proxy:
+//	    url: "socks5://egress.lab:1080"
+//
+// "local_address" is optional and is the IP address, with an optional
+// port, the events are sent from.
+//
+// "spoof_sources" is optional and only supported with "network" udp on
+// Linux.  Every event is sent from a random address of one of the
+// IPv4 CIDRs, over a raw socket, which needs the CAP_NET_RAW
+// capability.
+//
+//	output:
+//	  type: syslog
+//	  network: udp
+//	  host: collector.example.com
+//	  port: 514
+//	  spoof_sources: ["10.20.0.0/16", "172.16.5.0/24"]
+//
+// "devices" is optional and simulates a pool of devices.  Every event
+// is sent by a random device, with the hostname of the device in the
+// header and over a connection of its own, so each device has its own
+// source port.  "hostname" is the format of the hostnames, with %d for
+// the device number, and defaults to "device%d".  "addresses" are
+// optional IP aliases of this host, which the devices use in turn as
+// their source address.  "count" defaults to the number of addresses.
+//
+//	output:
+//	  type: syslog
+//	  network: udp
+//	  host: collector.example.com
+//	  port: 514
+//	  devices:
+//	    count: 50
+//	    hostname: "edge-fw-%02d"
+//	    addresses: ["10.9.0.11", "10.9.0.12"]
+//
+// "pcap" is optional and records what is sent in a pcap file, see
+// package pcap.  It can not be used with "spoof_sources".
+//
+// "multiline" is optional and is what is done with the line breaks of
+// multi-line events, which would end the message early over TCP.
+// "escape", the default, replaces them with "#012" and "#015", as
+// rsyslog escapes control characters.  "split" sends every line as a
+// message of its own.  "raw" sends them as they are, which is only
+// safe over UDP or with octet-counting framing.
+//
+// "framing" is optional and is the framing of the messages over TCP,
+// RFC 6587.  "non-transparent" ends every message with a line break,
+// "octet-counting" prefixes it with its length and a space.  It
+// defaults to octet-counting with TLS and non-transparent otherwise.
+//
+// "tls" is optional and sends syslog over TLS, RFC 5425, with
+// "network" tcp, usually to port 6514.  The connections use TLS 1.2 or
+// later and the messages are in the format of RFC 5424.  "ca" is a PEM
+// file of the CAs that sign the certificate of the server, the CAs of
+// the host by default, and "server_name" is the name it is verified
+// for, "host" by default.  "fingerprints" accepts a certificate of the
+// server by its fingerprint instead, such as a self-signed one, in the
+// format of RFC 5425, the hash function sha-1, sha-256, sha-384 or
+// sha-512 and the hash in colon separated hex octets.  "certificate"
+// and "key" are PEM files of the client certificate, for mutual
+// authentication.
+//
+//	output:
+//	  type: syslog
+//	  network: tcp
+//	  host: collector.example.com
+//	  port: 6514
+//	  tls:
+//	    enabled: true
+//	    ca: "/etc/spigot/ca.pem"
+//	    certificate: "/etc/spigot/client.pem"
+//	    key: "/etc/spigot/client-key.pem"
+//
+// "churn" is optional and drops the connections to the server while
+// the events are sent, with "network" tcp, because the reconnects of
+// the server are what breaks.  A connection is dropped after "events"
+// events or when it is older than "interval", and the next event is
+// sent over a new one.  "partial" is the percentage of the drops that
+// first send the beginning of the next message, which the server gets
+// truncated before it gets it in full over the new connection.
+// "abort" is the percentage of the drops that reset the TCP connection
+// instead of closing it, without the close_notify of TLS.
+//
+//	output:
+//	  type: syslog
+//	  network: tcp
+//	  host: collector.example.com
+//	  port: 6514
+//	  tls:
+//	    enabled: true
+//	    fingerprints: ["sha-256:E1:2D:53:2B:7C:6B:8A:29:A2:76:C8:64:36:0B:08:4B:7A:F1:9E:9D:AF:DA:6B:E9:62:A1:44:94:20:4A:1B:2C"]
+//	  churn:
+//	    events: 1000
+//	    interval: 1m
+//	    partial: 25
+//	    abort: 10
 
 //go:build !windows
 package syslog
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
 	"io"
 	"log/syslog"
+	"math/rand/v2"
 	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/elastic/go-ucfg"
 	"github.com/leehinman/spigot/pkg/output"
+	"github.com/leehinman/spigot/pkg/output/pcap"
+	"github.com/leehinman/spigot/pkg/output/proxy"
 )
 
 // Name is the name used in the configuration file and the registry.
 const Name = "syslog"
 
-// Output hosts the WriteCloser
+const (
+	MultilineEscape = "escape"
+	MultilineSplit  = "split"
+	MultilineRaw    = "raw"
+)
+
+const (
+	FramingNonTransparent = "non-transparent"
+	FramingOctetCounting  = "octet-counting"
+)
+
+// rfc5424Time is the format of the timestamps of RFC 5424, with
+// microseconds.
+const rfc5424Time = "2006-01-02T15:04:05.000000Z07:00"
+
+// dialTimeout is the timeout of connecting to the syslog server.
+const dialTimeout = 30 * time.Second
+
+// Output hosts the connection to the syslog server
 type Output struct {
-	pWC io.WriteCloser
+	network   string
+	addr      string
+	priority  syslog.Priority
+	tag       string
+	sources   []*net.IPNet
+	devices   []*device
+	pcap      *pcap.Writer
+	multiline string
+	framing   string
+	tls       *tls.Config
+	churn     churn
+}
+
+// device is a sender of events, with its own hostname and connection.
+type device struct {
+	hostname string
+	dialer   proxy.ContextDialer
+	conn     io.WriteCloser
+	raw      net.Conn
+	tcp      *net.TCPConn
+	sent     int
+	since    time.Time
 }
 
 func init() {
@@ -43,96 +200,324 @@ func New(cfg *ucfg.Config) (s output.Output, err error) {
 	if err := cfg.Unpack(&c); err != nil {
 		return nil, err
 	}
-	priority := getPriority(c.Facility, c.Severity)
-	sysLog, err := syslog.Dial(c.Network, net.JoinHostPort(c.Host, c.Port), priority, c.Tag)
+	sources, err := c.sources()
+	if err != nil {
+		return nil, err
+	}
+	tag := c.Tag
+	if tag == "" {
+		tag = os.Args[0]
+	}
+	devices, err := newDevices(c)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Output{
+		network:   c.Network,
+		addr:      net.JoinHostPort(c.Host, c.Port),
+		priority:  getPriority(c.Facility, c.Severity),
+		tag:       tag,
+		sources:   sources,
+		devices:   devices,
+		multiline: c.Multiline,
+		framing:   c.Framing,
+		churn:     c.Churn,
+	}
+	if c.TLS.Enabled {
+		if o.tls, err = c.TLS.clientConfig(c.Host); err != nil {
+			return nil, err
+		}
+		o.tag = appName(tag)
+	}
+	if o.framing == "" {
+		o.framing = FramingNonTransparent
+		if c.TLS.Enabled {
+			o.framing = FramingOctetCounting
+		}
+	}
+	if c.Pcap != "" {
+		if o.pcap, err = pcap.Create(c.Pcap); err != nil {
+			return nil, err
+		}
+	}
+	if err := o.connect(devices[0]); err != nil {
+		o.Close()
+		return nil, err
+	}
+	return o, nil
+}
+
+// appName returns tag as the APP-NAME of RFC 5424, at most 48
+// printable characters without spaces.
+func appName(tag string) string {
+	b := []byte(tag)
+	for i, c := range b {
+		if c <= ' ' || c > '~' {
+			b[i] = '_'
+		}
+	}
+	if len(b) > 48 {
+		b = b[:48]
+	}
+	return string(b)
+}
+
+// newDevices returns the devices of the output.  Without a devices
+// pool all events are sent by one device with the hostname of this
+// host.
+func newDevices(c config) ([]*device, error) {
+	count := c.Devices.Count
+	if count == 0 {
+		count = len(c.Devices.Addresses)
+	}
+	if count == 0 {
+		hostname, _ := os.Hostname()
+		d, err := newDevice(c, hostname, c.LocalAddress)
+		if err != nil {
+			return nil, err
+		}
+		return []*device{d}, nil
+	}
+
+	devices := make([]*device, count)
+	for i := range devices {
+		address := c.LocalAddress
+		if len(c.Devices.Addresses) > 0 {
+			address = c.Devices.Addresses[i%len(c.Devices.Addresses)]
+		}
+		d, err := newDevice(c, fmt.Sprintf(c.Devices.Hostname, i+1), address)
+		if err != nil {
+			return nil, err
+		}
+		devices[i] = d
+	}
+	return devices, nil
+}
+
+// newDevice returns a device that sends from the local address.
+func newDevice(c config, hostname, address string) (*device, error) {
+	local, err := output.LocalAddr(c.Network, address)
 	if err != nil {
 		return nil, err
 	}
-	s = &Output{
-		pWC: sysLog,
+	dialer, err := c.Proxy.Dialer(&net.Dialer{LocalAddr: local})
+	if err != nil {
+		return nil, err
 	}
-	return s, nil
+	return &device{hostname: hostname, dialer: dialer}, nil
 }
 
-// Write sends the log message to the syslog server
+// Write sends the log message to the syslog server, from a random
+// device of the pool.  If the connection fails it reconnects and sends
+// the message again, once.  With churn a connection that is due is
+// dropped first and the message is sent over a new one.
 func (s *Output) Write(b []byte) (n int, err error) {
-	return s.pWC.Write(b)
+	d := s.devices[0]
+	if len(s.devices) > 1 {
+		d = s.devices[rand.IntN(len(s.devices))]
+	}
+	messages := s.messages(d, b)
+	if d.conn != nil && s.due(d) {
+		s.drop(d, messages[0])
+	}
+	if d.conn != nil {
+		if err = s.send(d, messages); err == nil {
+			return len(b), nil
+		}
+	}
+	if err := s.connect(d); err != nil {
+		return 0, err
+	}
+	if err := s.send(d, messages); err != nil {
+		return 0, err
+	}
+	return len(b), nil
 }
 
-// Close closes the connection to the syslog server
+// Close closes the connections to the syslog server
 func (s *Output) Close() error {
-	return s.pWC.Close()
+	var err error
+	for _, d := range s.devices {
+		if d.conn == nil {
+			continue
+		}
+		if cerr := d.conn.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		d.conn = nil
+	}
+	if s.pcap != nil {
+		if cerr := s.pcap.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		s.pcap = nil
+	}
+	return err
 }
 
-func getPriority(facility string, severity string) syslog.Priority {
-	var f, s syslog.Priority
-
-	switch severity {
-	case "LOG_EMERG":
-		s = syslog.LOG_EMERG
-	case "LOG_ALERT":
-		s = syslog.LOG_ALERT
-	case "LOG_CRIT":
-		s = syslog.LOG_CRIT
-	case "LOG_ERR":
-		s = syslog.LOG_ERR
-	case "LOG_WARNING":
-		s = syslog.LOG_WARNING
-	case "LOG_NOTICE":
-		s = syslog.LOG_NOTICE
-	case "LOG_INFO":
-		s = syslog.LOG_INFO
-	case "LOG_DEBUG":
-		s = syslog.LOG_DEBUG
-	default:
-		s = syslog.LOG_EMERG
-	}
-
-	switch facility {
-	case "LOG_KERN":
-		f = syslog.LOG_KERN
-	case "LOG_USER":
-		f = syslog.LOG_USER
-	case "LOG_MAIL":
-		f = syslog.LOG_MAIL
-	case "LOG_DAEMON":
-		f = syslog.LOG_DAEMON
-	case "LOG_AUTH":
-		f = syslog.LOG_AUTH
-	case "LOG_SYSLOG":
-		f = syslog.LOG_SYSLOG
-	case "LOG_LPR":
-		f = syslog.LOG_LPR
-	case "LOG_NEWS":
-		f = syslog.LOG_NEWS
-	case "LOG_UUCP":
-		f = syslog.LOG_UUCP
-	case "LOG_CRON":
-		f = syslog.LOG_CRON
-	case "LOG_AUTHPRIV":
-		f = syslog.LOG_AUTHPRIV
-	case "LOG_FTP":
-		f = syslog.LOG_FTP
-	case "LOG_LOCAL0":
-		f = syslog.LOG_LOCAL0
-	case "LOG_LOCAL1":
-		f = syslog.LOG_LOCAL1
-	case "LOG_LOCAL2":
-		f = syslog.LOG_LOCAL2
-	case "LOG_LOCAL3":
-		f = syslog.LOG_LOCAL3
-	case "LOG_LOCAL4":
-		f = syslog.LOG_LOCAL4
-	case "LOG_LOCAL5":
-		f = syslog.LOG_LOCAL5
-	case "LOG_LOCAL6":
-		f = syslog.LOG_LOCAL6
-	case "LOG_LOCAL7":
-		f = syslog.LOG_LOCAL7
-	default:
-		f = syslog.LOG_KERN
+func (s *Output) connect(d *device) error {
+	if d.conn != nil {
+		d.conn.Close()
+		d.conn = nil
+	}
+	if len(s.sources) > 0 {
+		conn, err := newSpoofConn(s.sources, s.addr)
+		if err != nil {
+			return err
+		}
+		d.conn = conn
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	conn, err := d.dialer.DialContext(ctx, s.network, s.addr)
+	if err != nil {
+		return err
 	}
+	d.tcp, _ = conn.(*net.TCPConn)
+	if s.pcap != nil {
+		conn = s.pcap.Conn(conn)
+	}
+	d.raw, d.conn = conn, conn
+	if s.tls != nil {
+		tlsConn := tls.Client(conn, s.tls)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			d.conn = nil
+			return err
+		}
+		d.conn = tlsConn
+	}
+	d.sent, d.since = 0, time.Now()
+	return nil
+}
+
+// due returns true if the connection of d is due to be dropped by
+// churn.
+func (s *Output) due(d *device) bool {
+	return (s.churn.Events > 0 && d.sent >= s.churn.Events) ||
+		(s.churn.Interval > 0 && time.Since(d.since) >= s.churn.Interval)
+}
 
+// drop closes the connection of d.  A partial drop first sends the
+// beginning of msg, which is sent again in full over the next
+// connection, so the server gets a truncated message.  An aborted
+// drop closes the TCP connection with a reset, without the close_notify
+// of TLS.
+func (s *Output) drop(d *device, msg []byte) {
+	if len(msg) > 1 && rand.IntN(100) < s.churn.Partial {
+		_, _ = d.conn.Write(msg[:1+rand.IntN(len(msg)-1)])
+	}
+	if rand.IntN(100) < s.churn.Abort {
+		if d.tcp != nil {
+			_ = d.tcp.SetLinger(0)
+		}
+		_ = d.raw.Close()
+	} else {
+		_ = d.conn.Close()
+	}
+	d.conn = nil
+}
+
+// escaper escapes line breaks the way rsyslog escapes control
+// characters.
+var escaper = strings.NewReplacer("\n", "#012", "\r", "#015")
+
+// messages returns the messages of b from d, framed.  Without TLS they
+// are in the format of log/syslog:
+// "<priority>timestamp hostname tag[pid]: message", with TLS in the
+// format of RFC 5424, as RFC 5425 requires:
+// "<priority>1 timestamp hostname tag pid - - message".
+// Non-transparent framing ends them with a line break, octet-counting
+// prefixes them with their length and a space.  The line breaks in b
+// are escaped, split or kept as configured with multiline.
+func (s *Output) messages(d *device, b []byte) [][]byte {
+	msg := strings.TrimSuffix(string(b), "\n")
+	lines := []string{msg}
+	switch s.multiline {
+	case MultilineEscape:
+		lines[0] = escaper.Replace(msg)
+	case MultilineSplit:
+		lines = strings.Split(strings.ReplaceAll(msg, "\r\n", "\n"), "\n")
+	}
+	now := time.Now()
+	messages := make([][]byte, len(lines))
+	for i, line := range lines {
+		var m string
+		if s.tls != nil {
+			m = fmt.Sprintf("<%d>1 %s %s %s %d - - %s", s.priority, now.Format(rfc5424Time), d.hostname, s.tag, os.Getpid(), line)
+		} else {
+			m = fmt.Sprintf("<%d>%s %s %s[%d]: %s", s.priority, now.Format(time.RFC3339), d.hostname, s.tag, os.Getpid(), line)
+		}
+		if s.framing == FramingOctetCounting {
+			messages[i] = []byte(strconv.Itoa(len(m)) + " " + m)
+		} else {
+			messages[i] = []byte(m + "\n")
+		}
+	}
+	return messages
+}
+
+// send sends the messages over the connection of d.
+func (s *Output) send(d *device, messages [][]byte) error {
+	for _, m := range messages {
+		if _, err := d.conn.Write(m); err != nil {
+			return err
+		}
+	}
+	d.sent++
+	return nil
+}
+
+var (
+	// severities and facilities are the keywords of the severity and
+	// facility options, in the order of their codes.
+	severities = [...]keyword{
+		{"LOG_EMERG", syslog.LOG_EMERG}, {"LOG_ALERT", syslog.LOG_ALERT}, {"LOG_CRIT", syslog.LOG_CRIT}, {"LOG_ERR", syslog.LOG_ERR},
+		{"LOG_WARNING", syslog.LOG_WARNING}, {"LOG_NOTICE", syslog.LOG_NOTICE}, {"LOG_INFO", syslog.LOG_INFO}, {"LOG_DEBUG", syslog.LOG_DEBUG},
+	}
+	facilities = [...]keyword{
+		{"LOG_KERN", syslog.LOG_KERN}, {"LOG_USER", syslog.LOG_USER}, {"LOG_MAIL", syslog.LOG_MAIL}, {"LOG_DAEMON", syslog.LOG_DAEMON},
+		{"LOG_AUTH", syslog.LOG_AUTH}, {"LOG_SYSLOG", syslog.LOG_SYSLOG}, {"LOG_LPR", syslog.LOG_LPR}, {"LOG_NEWS", syslog.LOG_NEWS},
+		{"LOG_UUCP", syslog.LOG_UUCP}, {"LOG_CRON", syslog.LOG_CRON}, {"LOG_AUTHPRIV", syslog.LOG_AUTHPRIV}, {"LOG_FTP", syslog.LOG_FTP},
+		{"LOG_LOCAL0", syslog.LOG_LOCAL0}, {"LOG_LOCAL1", syslog.LOG_LOCAL1}, {"LOG_LOCAL2", syslog.LOG_LOCAL2}, {"LOG_LOCAL3", syslog.LOG_LOCAL3},
+		{"LOG_LOCAL4", syslog.LOG_LOCAL4}, {"LOG_LOCAL5", syslog.LOG_LOCAL5}, {"LOG_LOCAL6", syslog.LOG_LOCAL6}, {"LOG_LOCAL7", syslog.LOG_LOCAL7},
+	}
+)
+
+// keyword is a keyword of a facility or a severity and its code.
+type keyword struct {
+	name     string
+	priority syslog.Priority
+}
+
+// lookup returns the code of the keyword name, and false if name is
+// not one of keywords.
+func lookup(keywords []keyword, name string) (syslog.Priority, bool) {
+	for _, k := range keywords {
+		if k.name == name {
+			return k.priority, true
+		}
+	}
+	return 0, false
+}
+
+// names returns the names of keywords, for the error of an unknown
+// keyword.
+func names(keywords []keyword) string {
+	var s []string
+	for _, k := range keywords {
+		s = append(s, k.name)
+	}
+	return strings.Join(s, ", ")
+}
+
+// getPriority returns the priority of the facility and the severity,
+// Validate checked them, the empty ones are LOG_KERN and LOG_EMERG.
+func getPriority(facility string, severity string) syslog.Priority {
+	f, _ := lookup(facilities[:], facility)
+	s, _ := lookup(severities[:], severity)
 	return s | f
 }
 
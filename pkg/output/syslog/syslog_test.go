@@ -0,0 +1,182 @@
+//go:build !windows
+package syslog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer l.Close()
+	lines := make(chan string, 2)
+	remotes := make(chan string, 2)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			remotes <- conn.RemoteAddr().(*net.TCPAddr).IP.String()
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			lines <- line
+			conn.Close()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "network": "tcp", "host": host, "port": port, "facility": "LOG_LOCAL0", "severity": "LOG_INFO", "tag": "spigot", "local_address": "127.0.0.1"})
+	assert.Nil(t, err)
+	o, err := New(c)
+	assert.Nil(t, err)
+
+	want := regexp.MustCompile(`^<134>\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\S* \S+ spigot\[\d+\]: hello\n$`)
+	_, err = o.Write([]byte("hello"))
+	assert.Nil(t, err)
+	assert.Regexp(t, want, <-lines)
+	assert.Equal(t, "127.0.0.1", <-remotes)
+
+	// the server closed the connection, the next writes reconnect
+	for i := 0; i < 3; i++ {
+		if _, err = o.Write([]byte("hello\n")); err != nil {
+			break
+		}
+	}
+	assert.Nil(t, err)
+	assert.Regexp(t, want, <-lines)
+	assert.Nil(t, o.Close())
+}
+
+func TestDevices(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer pc.Close()
+
+	host, port, _ := net.SplitHostPort(pc.LocalAddr().String())
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "network": "udp", "host": host, "port": port, "tag": "spigot", "devices": map[string]interface{}{"count": 3, "hostname": "fw%02d", "addresses": []string{"127.0.0.1"}}})
+	assert.Nil(t, err)
+	o, err := New(c)
+	assert.Nil(t, err)
+	defer o.Close()
+
+	header := regexp.MustCompile(`^<\d+>\S+ (\S+) spigot\[\d+\]: hello\n$`)
+	ports := make(map[string]string)
+	buf := make([]byte, 1024)
+	for i := 0; i < 60; i++ {
+		_, err = o.Write([]byte("hello"))
+		assert.Nil(t, err)
+		n, addr, err := pc.ReadFrom(buf)
+		assert.Nil(t, err)
+		m := header.FindStringSubmatch(string(buf[:n]))
+		assert.NotNil(t, m, string(buf[:n]))
+		hostname, from := m[1], addr.String()
+		if p, ok := ports[hostname]; ok {
+			assert.Equal(t, p, from, hostname)
+		}
+		ports[hostname] = from
+	}
+	assert.Len(t, ports, 3)
+	seen := make(map[string]bool)
+	for hostname, from := range ports {
+		assert.Contains(t, []string{"fw01", "fw02", "fw03"}, hostname)
+		assert.False(t, seen[from], "%s shares %s", hostname, from)
+		seen[from] = true
+	}
+}
+
+func TestMultiline(t *testing.T) {
+	// the headers are replaced with "|"
+	tests := map[string]struct {
+		multiline string
+		expected  string
+	}{
+		"Escape": {multiline: MultilineEscape, expected: "|type=SYSCALL a=1#012type=CWD cwd=\"/\"#015#012type=PATH item=0\n"},
+		"Split":  {multiline: MultilineSplit, expected: "|type=SYSCALL a=1\n|type=CWD cwd=\"/\"\n|type=PATH item=0\n"},
+		"Raw":    {multiline: MultilineRaw, expected: "|type=SYSCALL a=1\ntype=CWD cwd=\"/\"\r\ntype=PATH item=0\n"},
+	}
+	header := regexp.MustCompile(`<\d+>\S+ \S+ spigot\[\d+\]: `)
+	for name, tc := range tests {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.Nil(t, err, name)
+		received := make(chan string, 1)
+		go func() {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			b, _ := io.ReadAll(conn)
+			received <- string(b)
+		}()
+
+		host, port, _ := net.SplitHostPort(l.Addr().String())
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "network": "tcp", "host": host, "port": port, "tag": "spigot", "multiline": tc.multiline})
+		assert.Nil(t, err, name)
+		o, err := New(c)
+		assert.Nil(t, err, name)
+		_, err = o.Write([]byte("type=SYSCALL a=1\ntype=CWD cwd=\"/\"\r\ntype=PATH item=0\n"))
+		assert.Nil(t, err, name)
+		assert.Nil(t, o.Close(), name)
+		assert.Equal(t, tc.expected, header.ReplaceAllString(<-received, "|"), name)
+		l.Close()
+	}
+}
+
+func TestChurn(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer l.Close()
+	received := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			b, _ := io.ReadAll(conn)
+			received <- string(b)
+			conn.Close()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "network": "tcp", "host": host, "port": port, "tag": "spigot", "framing": FramingOctetCounting, "churn": map[string]interface{}{"events": 2, "partial": 100}})
+	assert.Nil(t, err)
+	o, err := New(c)
+	assert.Nil(t, err)
+	for i := 1; i <= 5; i++ {
+		_, err = o.Write([]byte(fmt.Sprintf("message %d", i)))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, o.Close())
+
+	// every connection has two messages and the beginning of the next,
+	// which is sent again over the next connection
+	frame := regexp.MustCompile(`(\d+) (<\d+>\S+ \S+ spigot\[\d+\]: message (\d))`)
+	for i, first := range []int{1, 3, 5} {
+		data := <-received
+		var got []string
+		for _, m := range frame.FindAllStringSubmatch(data, -1) {
+			assert.Equal(t, strconv.Itoa(len(m[2])), m[1], data)
+			got = append(got, m[3])
+			data = strings.TrimPrefix(data, m[0])
+		}
+		if i < 2 {
+			assert.Equal(t, []string{strconv.Itoa(first), strconv.Itoa(first + 1)}, got)
+			assert.NotEmpty(t, data, "partial message")
+			assert.False(t, frame.MatchString(data), data)
+		} else {
+			assert.Equal(t, []string{"5"}, got)
+			assert.Empty(t, data)
+		}
+	}
+}
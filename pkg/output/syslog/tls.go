@@ -0,0 +1,124 @@
+//go:build !windows
+
+package syslog
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"strings"
+)
+
+// tlsConfig is the TLS of syslog over TLS, RFC 5425.
+type tlsConfig struct {
+	Enabled            bool     `config:"enabled"`
+	CA                 string   `config:"ca"`
+	Certificate        string   `config:"certificate"`
+	Key                string   `config:"key"`
+	ServerName         string   `config:"server_name"`
+	Fingerprints       []string `config:"fingerprints"`
+	InsecureSkipVerify bool     `config:"insecure_skip_verify"`
+}
+
+// fingerprintHashes are the hash functions of the fingerprints, by
+// their names in the IANA "Hash Function Textual Names" registry.
+var fingerprintHashes = map[string]func() hash.Hash{
+	"sha-1":   sha1.New,
+	"sha-256": sha256.New,
+	"sha-384": sha512.New384,
+	"sha-512": sha512.New,
+}
+
+func (c *tlsConfig) Validate() error {
+	if (c.Certificate == "") != (c.Key == "") {
+		return fmt.Errorf("'certificate' and 'key' must be used together")
+	}
+	if len(c.Fingerprints) > 0 && c.CA != "" {
+		return fmt.Errorf("'fingerprints' can not be used with 'ca'")
+	}
+	for _, v := range c.Fingerprints {
+		if _, _, err := parseFingerprint(v); err != nil {
+			return fmt.Errorf("'%s' is not a valid value for 'fingerprints' expected a fingerprint like 'sha-256:E1:2D:...'", v)
+		}
+	}
+	return nil
+}
+
+// clientConfig returns the TLS configuration of the connections to
+// host.  With fingerprints the certificate of the server is accepted
+// when it matches one of them, as RFC 5425 allows for self-signed
+// certificates, instead of when it is signed by a trusted CA.
+func (c *tlsConfig) clientConfig(host string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         host,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.ServerName != "" {
+		cfg.ServerName = c.ServerName
+	}
+	if c.CA != "" {
+		b, err := os.ReadFile(c.CA)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("'%s' is not a valid value for 'ca' expected a PEM file of certificates", c.CA)
+		}
+	}
+	if c.Certificate != "" {
+		cert, err := tls.LoadX509KeyPair(c.Certificate, c.Key)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if len(c.Fingerprints) > 0 {
+		fingerprints := c.Fingerprints
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyPeerCertificate = func(certs [][]byte, _ [][]*x509.Certificate) error {
+			if len(certs) == 0 {
+				return errors.New("the server sent no certificate")
+			}
+			for _, v := range fingerprints {
+				name, want, _ := parseFingerprint(v)
+				h := fingerprintHashes[name]()
+				h.Write(certs[0])
+				if bytes.Equal(h.Sum(nil), want) {
+					return nil
+				}
+			}
+			return errors.New("the certificate of the server matches none of the 'fingerprints'")
+		}
+	}
+	return cfg, nil
+}
+
+// parseFingerprint parses a certificate fingerprint of RFC 5425, the
+// name of the hash function, a colon and the hash as colon separated
+// hex octets, and returns the name and the hash.
+func parseFingerprint(v string) (string, []byte, error) {
+	name, octets, ok := strings.Cut(v, ":")
+	name = strings.ToLower(name)
+	newHash, known := fingerprintHashes[name]
+	if !ok || !known {
+		return "", nil, errors.New("unknown hash function")
+	}
+	sum, err := hex.DecodeString(strings.ReplaceAll(octets, ":", ""))
+	if err != nil {
+		return "", nil, err
+	}
+	if len(sum) != newHash().Size() || len(octets) != 3*len(sum)-1 {
+		return "", nil, errors.New("invalid length")
+	}
+	return name, sum, nil
+}
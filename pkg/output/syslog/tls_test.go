@@ -0,0 +1,193 @@
+//go:build !windows
+
+package syslog
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCertificate returns a certificate for 127.0.0.1 signed by parent,
+// or self-signed without parent, and writes it and its key to PEM files
+// in dir.
+func newCertificate(t *testing.T, dir, name string, parent *tls.Certificate) (tls.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	signer, signerKey := tmpl, interface{}(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	assert.Nil(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	certFile, keyFile := filepath.Join(dir, name+".pem"), filepath.Join(dir, name+"-key.pem")
+	assert.Nil(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.Nil(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, certFile, keyFile
+}
+
+// readFrame reads an octet-counting framed message.
+func readFrame(r *bufio.Reader) (string, error) {
+	length, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return string(b), err
+}
+
+func TestTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caFile, _ := newCertificate(t, dir, "ca", nil)
+	server, _, _ := newCertificate(t, dir, "server", &ca)
+	_, clientFile, clientKeyFile := newCertificate(t, dir, "client", &ca)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{server},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	})
+	assert.Nil(t, err)
+	defer l.Close()
+	frames := make(chan string, 10)
+	clients := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				tlsConn := conn.(*tls.Conn)
+				if tlsConn.Handshake() != nil {
+					return
+				}
+				clients <- tlsConn.ConnectionState().PeerCertificates[0].Subject.CommonName
+				r := bufio.NewReader(conn)
+				for {
+					frame, err := readFrame(r)
+					if err != nil {
+						return
+					}
+					frames <- frame
+				}
+			}()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	sum := sha256.Sum256(server.Certificate[0])
+	var octets []string
+	for _, b := range sum {
+		octets = append(octets, fmt.Sprintf("%02X", b))
+	}
+	tests := map[string]map[string]interface{}{
+		"CA":           {"enabled": true, "ca": caFile, "certificate": clientFile, "key": clientKeyFile},
+		"Fingerprints": {"enabled": true, "fingerprints": []string{"SHA-256:" + strings.Join(octets, ":")}, "certificate": clientFile, "key": clientKeyFile},
+	}
+	want := regexp.MustCompile(`^<134>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}\S* \S+ spigot \d+ - - hello\nworld$`)
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "network": "tcp", "host": host, "port": port, "facility": "LOG_LOCAL0", "severity": "LOG_INFO", "tag": "spigot", "multiline": "raw", "tls": tc})
+		assert.Nil(t, err, name)
+		o, err := New(c)
+		if !assert.Nil(t, err, name) {
+			continue
+		}
+		_, err = o.Write([]byte("hello\nworld\n"))
+		assert.Nil(t, err, name)
+		assert.Equal(t, "client", <-clients, name)
+		assert.Regexp(t, want, <-frames, name)
+		assert.Nil(t, o.Close(), name)
+	}
+
+	// the client does not trust the server without the CA or with
+	// another fingerprint
+	for name, tc := range map[string]map[string]interface{}{
+		"Other Fingerprint": {"enabled": true, "fingerprints": []string{"sha-256:" + strings.Repeat("00:", 31) + "00"}, "certificate": clientFile, "key": clientKeyFile},
+		"Untrusted":         {"enabled": true, "certificate": clientFile, "key": clientKeyFile},
+	} {
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "network": "tcp", "host": host, "port": port, "tls": tc})
+		assert.Nil(t, err, name)
+		_, err = New(c)
+		assert.NotNil(t, err, name)
+	}
+
+	// the server rejects the client without a client certificate, with
+	// TLS 1.3 after the handshake of the client
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "network": "tcp", "host": host, "port": port, "tls": map[string]interface{}{"enabled": true, "ca": caFile}})
+	assert.Nil(t, err)
+	if o, err := New(c); err == nil {
+		_, _ = o.Write([]byte("hello"))
+		o.Close()
+	}
+	select {
+	case client := <-clients:
+		t.Errorf("client %q without certificate was accepted", client)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestParseFingerprint(t *testing.T) {
+	name, sum, err := parseFingerprint("SHA-1:E1:2D:53:2B:7C:6B:8A:29:A2:76:C8:64:36:0B:08:4B:7A:F1:9E:9D")
+	assert.Nil(t, err)
+	assert.Equal(t, "sha-1", name)
+	assert.Len(t, sum, 20)
+	assert.Equal(t, byte(0xe1), sum[0])
+
+	for _, v := range []string{
+		"",
+		"sha-1",
+		"md5:E1:2D:53:2B:7C:6B:8A:29:A2:76:C8:64:36:0B:08:4B",
+		"sha-1:E1:2D:53:2B:7C:6B:8A:29:A2:76:C8:64:36:0B:08:4B:7A:F1:9E",
+		"sha-1:E12D532B7C6B8A29A276C864360B084B7AF19E9D",
+		"sha-1:E1:2D:53:2B:7C:6B:8A:29:A2:76:C8:64:36:0B:08:4B:7A:F1:9E:ZZ",
+	} {
+		_, _, err := parseFingerprint(v)
+		assert.NotNil(t, err, v)
+	}
+}
@@ -0,0 +1,57 @@
+// Package profile bundles ready-to-run configuration files, the
+// profiles, in the spigot binary.  "spigot init <profile>" writes one
+// as a starting point, so a first run needs no configuration of its
+// own.
+//
+// A profile is a YAML file of the profiles directory, named after the
+// profile.  Its first line is a comment with the description of the
+// profile, which is shown in the list of the profiles.
+package profile
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed profiles/*.yml
+var profiles embed.FS
+
+// Profile is a bundled configuration file.
+type Profile struct {
+	Name        string
+	Description string
+}
+
+// List returns the profiles, sorted by name.
+func List() []Profile {
+	// The pattern of go:embed matches the files.
+	files, _ := fs.Glob(profiles, "profiles/*.yml")
+	var list []Profile
+	for _, f := range files {
+		b, _ := profiles.ReadFile(f)
+		line, _, _ := strings.Cut(string(b), "\n")
+		list = append(list, Profile{
+			Name:        strings.TrimSuffix(path.Base(f), ".yml"),
+			Description: strings.TrimSpace(strings.TrimPrefix(line, "#")),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Config returns the configuration file of the profile name.
+func Config(name string) ([]byte, error) {
+	b, err := profiles.ReadFile("profiles/" + name + ".yml")
+	if err != nil || strings.Contains(name, "/") {
+		var names []string
+		for _, p := range List() {
+			names = append(names, p.Name)
+		}
+		return nil, fmt.Errorf("'%s' is not a valid value for 'profile' expected '%s'", name, strings.Join(names, ", "))
+	}
+	return b, nil
+}
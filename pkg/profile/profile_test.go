@@ -0,0 +1,56 @@
+package profile
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/elastic/go-ucfg/yaml"
+	"github.com/leehinman/spigot/pkg/runner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList(t *testing.T) {
+	var names []string
+	for _, p := range List() {
+		names = append(names, p.Name)
+		assert.NotEmpty(t, p.Description, p.Name)
+	}
+	assert.Equal(t, []string{"elastic-integrations-smoke", "parser-regression", "siem-soak-10k-eps"}, names)
+}
+
+// TestConfig checks that the profiles are configurations spigot runs,
+// with generators whose templates have no errors.
+func TestConfig(t *testing.T) {
+	for _, p := range List() {
+		b, err := Config(p.Name)
+		if !assert.Nil(t, err, p.Name) {
+			continue
+		}
+		cfg, err := yaml.NewConfig(b, ucfg.PathSep("."))
+		if !assert.Nil(t, err, p.Name) {
+			continue
+		}
+		c := struct {
+			Runners []*ucfg.Config `config:"runners" validate:"required"`
+		}{}
+		if !assert.Nil(t, cfg.Unpack(&c), p.Name) {
+			continue
+		}
+		names, err := runner.Names(c.Runners)
+		assert.Nil(t, err, p.Name)
+		for i, r := range c.Runners {
+			issues, err := runner.Validate(r, 10)
+			assert.Nil(t, err, "%s: %s", p.Name, names[i])
+			for _, issue := range issues {
+				assert.True(t, issue.Warning, "%s: %s: %s", p.Name, names[i], issue)
+			}
+		}
+	}
+}
+
+func TestConfigInvalid(t *testing.T) {
+	_, err := Config("soak")
+	assert.EqualError(t, err, "'soak' is not a valid value for 'profile' expected 'elastic-integrations-smoke, parser-regression, siem-soak-10k-eps'")
+	_, err = Config("../profile")
+	assert.Error(t, err)
+}
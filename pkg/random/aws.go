@@ -1,6 +1,6 @@
 package random
 
-import "math/rand"
+import "math/rand/v2"
 
 var (
 	availabilityZones = [...]string{
@@ -165,23 +165,23 @@ var (
 )
 
 // AWSAvailabilityZone will return a random AWS Availability Zone.
-func AWSAvailabilityZone() string {
-	return availabilityZones[rand.Intn(len(availabilityZones))]
+func AWSAvailabilityZone(r *rand.Rand) string {
+	return availabilityZones[r.IntN(len(availabilityZones))]
 }
 
 // AWSAvailabilityZoneInRegion will return a random AWS Availability
 // Zone in the provided region. If the region cannot be found, an empty
 // string will be returned.
-func AWSAvailabilityZoneInRegion(region string) string {
+func AWSAvailabilityZoneInRegion(r *rand.Rand, region string) string {
 	regionAZs, ok := regionAZMap[region]
 	if !ok {
 		return ""
 	}
 
-	return regionAZs[rand.Intn(len(regionAZs))]
+	return regionAZs[r.IntN(len(regionAZs))]
 }
 
 // AWSRegion returns a random AWS region.
-func AWSRegion() string {
-	return regions[rand.Intn(len(regions))]
+func AWSRegion(r *rand.Rand) string {
+	return regions[r.IntN(len(regions))]
 }
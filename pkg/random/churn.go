@@ -0,0 +1,78 @@
+package random
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// ChurnConfig is the configuration of the churn of a pool of hosts or
+// users over a run, for asset inventories and baselines that drift.
+// Arrive is the number of new hosts or joiners per hour, Retire the
+// number of hosts retired or leavers per hour, and Reassign the number
+// of addresses per hour that move to another host, or of users that
+// move to another group.  The churn is disabled by default.
+type ChurnConfig struct {
+	Arrive   float64 `config:"arrive"`
+	Retire   float64 `config:"retire"`
+	Reassign float64 `config:"reassign"`
+}
+
+// Validate checks that the rates are not negative.
+func (c *ChurnConfig) Validate() error {
+	for _, v := range []struct {
+		name string
+		rate float64
+	}{{"arrive", c.Arrive}, {"retire", c.Retire}, {"reassign", c.Reassign}} {
+		if v.rate < 0 {
+			return fmt.Errorf("'%v' is not a valid value for '%s' expected a value of 0 or more", v.rate, v.name)
+		}
+	}
+	return nil
+}
+
+// Churn times the changes of a pool of a ChurnConfig.  The changes of
+// each kind are a Poisson process at their rate, in the time of the
+// events, so a run over a day of timestamps has a day of churn.
+type Churn struct {
+	rand  *rand.Rand
+	rates [3]float64
+	next  [3]time.Time
+}
+
+// NewChurn returns the Churn for c using r, or nil when the churn is
+// disabled.
+func NewChurn(r *rand.Rand, c ChurnConfig) *Churn {
+	if c.Arrive == 0 && c.Retire == 0 && c.Reassign == 0 {
+		return nil
+	}
+	return &Churn{rand: r, rates: [3]float64{c.Arrive, c.Retire, c.Reassign}}
+}
+
+// Changes returns the number of arrivals, retirements and
+// reassignments up to t since the previous call.  The first call
+// starts the churn at t.  A nil Churn has no changes.
+func (c *Churn) Changes(t time.Time) (arrive, retire, reassign int) {
+	if c == nil {
+		return 0, 0, 0
+	}
+	var n [3]int
+	for i, rate := range c.rates {
+		if rate == 0 {
+			continue
+		}
+		if c.next[i].IsZero() {
+			c.next[i] = c.after(t, rate)
+		}
+		for !c.next[i].After(t) {
+			n[i]++
+			c.next[i] = c.after(c.next[i], rate)
+		}
+	}
+	return n[0], n[1], n[2]
+}
+
+// after returns the time of the change after t at rate per hour.
+func (c *Churn) after(t time.Time, rate float64) time.Time {
+	return t.Add(time.Duration(c.rand.ExpFloat64() / rate * float64(time.Hour)))
+}
@@ -0,0 +1,56 @@
+package random
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net"
+)
+
+// IPv4FromCIDR returns a random IPv4 address of the network n, such as
+// 10.0.0.0/8.  n must be an IPv4 network.
+func IPv4FromCIDR(r *rand.Rand, n *net.IPNet) net.IP {
+	return IPFromCIDR(r, &net.IPNet{IP: n.IP.To4(), Mask: n.Mask[len(n.Mask)-net.IPv4len:]})
+}
+
+// IPFromCIDR returns a random address of the IPv4 or IPv6 network n.
+func IPFromCIDR(r *rand.Rand, n *net.IPNet) net.IP {
+	ip := make(net.IP, len(n.IP))
+	Read(r, ip)
+	for i := range ip {
+		ip[i] = n.IP[i]&n.Mask[i] | ip[i]&^n.Mask[i]
+	}
+	return ip
+}
+
+// Pool picks addresses from networks, so generators have realistic
+// internal and external ranges instead of the whole address space.
+// Each network of the pool is as likely, whatever its size.
+type Pool struct {
+	nets []*net.IPNet
+}
+
+// NewPool returns the Pool of the cidrs of the option name, or nil
+// without cidrs.
+func NewPool(name string, cidrs []string) (*Pool, error) {
+	if len(cidrs) == 0 {
+		return nil, nil
+	}
+	p := &Pool{}
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid value for '%s' expected a CIDR such as 10.0.0.0/8", cidr, name)
+		}
+		p.nets = append(p.nets, n)
+	}
+	return p, nil
+}
+
+// IP returns a random address of a network of the pool.  A nil Pool
+// returns IP(r, ipv6) instead.
+func (p *Pool) IP(r *rand.Rand, ipv6 float64) net.IP {
+	if p == nil {
+		return IP(r, ipv6)
+	}
+	return IPFromCIDR(r, p.nets[r.IntN(len(p.nets))])
+}
@@ -0,0 +1,136 @@
+package random
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	firstNames = [...]string{"Alice", "Bob", "Carol", "David", "Emma", "Farid", "Grace", "Hiro", "Ines", "Jan", "Kofi", "Lena", "Mila", "Noah", "Olga", "Priya"}
+	lastNames  = [...]string{"Anderson", "Brown", "Chen", "Dubois", "Evans", "Fischer", "Garcia", "Huang", "Ivanova", "Jansen", "Kim", "Lopez", "Muller", "Nguyen", "Okafor", "Patel"}
+	domains    = [...]string{"example.com", "example.net", "example.org", "corp.example.com", "test.example.net"}
+	hostParts  = [...]string{"web", "db", "api", "mail", "app", "cache", "files", "vpn"}
+	countries  = [...]string{"US", "CA", "MX", "BR", "GB", "FR", "DE", "NL", "ES", "IT", "SE", "PL", "IN", "CN", "JP", "KR", "AU", "ZA", "NG", "EG"}
+	words      = [...]string{
+		"alpha", "bravo", "cache", "delta", "error", "file", "gateway", "host", "index", "job",
+		"kernel", "login", "message", "node", "order", "packet", "queue", "request", "session", "token",
+		"update", "volume", "worker", "export", "yield", "zone",
+	}
+
+	fakers = map[string]func(r *rand.Rand) interface{}{
+		"ipv4":         func(r *rand.Rand) interface{} { return IPv4(r).String() },
+		"ipv6":         func(r *rand.Rand) interface{} { return IPv6(r).String() },
+		"mac":          func(r *rand.Rand) interface{} { return MAC(r).String() },
+		"port":         func(r *rand.Rand) interface{} { return Port(r) },
+		"hostname":     func(r *rand.Rand) interface{} { return Hostname(r) },
+		"domain":       func(r *rand.Rand) interface{} { return domains[r.IntN(len(domains))] },
+		"url":          func(r *rand.Rand) interface{} { return URL(r) },
+		"email":        func(r *rand.Rand) interface{} { return Email(r) },
+		"username":     func(r *rand.Rand) interface{} { return Username(r) },
+		"first_name":   func(r *rand.Rand) interface{} { return firstNames[r.IntN(len(firstNames))] },
+		"last_name":    func(r *rand.Rand) interface{} { return lastNames[r.IntN(len(lastNames))] },
+		"name":         func(r *rand.Rand) interface{} { return Name(r) },
+		"uuid":         func(r *rand.Rand) interface{} { return UUID(r) },
+		"hex":          func(r *rand.Rand) interface{} { return Hex(r, 16) },
+		"user_agent":   func(r *rand.Rand) interface{} { return UserAgent(r) },
+		"http_method":  func(r *rand.Rand) interface{} { return HTTPMethod(r) },
+		"http_status":  func(r *rand.Rand) interface{} { return HTTPStatus(r) },
+		"http_version": func(r *rand.Rand) interface{} { return HTTPVersion(r) },
+		"aws_region":   func(r *rand.Rand) interface{} { return AWSRegion(r) },
+		"country_code": func(r *rand.Rand) interface{} { return countries[r.IntN(len(countries))] },
+		"word":         func(r *rand.Rand) interface{} { return Word(r) },
+		"sentence":     func(r *rand.Rand) interface{} { return Sentence(r) },
+	}
+)
+
+// Faker returns the function that generates the random values of the
+// faker name, such as "ipv4" or "email", for generators whose fields
+// are defined in their configuration.  The values are strings, except
+// for "port" and "http_status", which are ints.
+func Faker(name string) (func(r *rand.Rand) interface{}, bool) {
+	f, ok := fakers[name]
+	return f, ok
+}
+
+// FakerNames returns the sorted names of the fakers.
+func FakerNames() []string {
+	names := make([]string, 0, len(fakers))
+	for name := range fakers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MAC returns a random, locally administered, unicast MAC address.
+func MAC(r *rand.Rand) net.HardwareAddr {
+	mac := make(net.HardwareAddr, 6)
+	Read(r, mac)
+	mac[0] = mac[0]&^0x01 | 0x02
+	return mac
+}
+
+// Hostname returns a random fully qualified host name, such as
+// web03.example.com.
+func Hostname(r *rand.Rand) string {
+	return fmt.Sprintf("%s%02d.%s", hostParts[r.IntN(len(hostParts))], r.IntN(20)+1, domains[r.IntN(len(domains))])
+}
+
+// URL returns a random https URL of a host name.
+func URL(r *rand.Rand) string {
+	return fmt.Sprintf("https://%s/%s/%s", Hostname(r), Word(r), Word(r))
+}
+
+// Name returns a random first and last name.
+func Name(r *rand.Rand) string {
+	return firstNames[r.IntN(len(firstNames))] + " " + lastNames[r.IntN(len(lastNames))]
+}
+
+// Username returns a random user name, the first letter of a first
+// name and a last name, such as "jnguyen".
+func Username(r *rand.Rand) string {
+	return strings.ToLower(firstNames[r.IntN(len(firstNames))][:1] + lastNames[r.IntN(len(lastNames))])
+}
+
+// Email returns a random email address of a user name.
+func Email(r *rand.Rand) string {
+	return Username(r) + "@" + domains[r.IntN(len(domains))]
+}
+
+// UUID returns a random version 4 UUID.
+func UUID(r *rand.Rand) string {
+	id, err := uuid.NewRandomFromReader(Reader(r))
+	if err != nil {
+		// a *rand.Rand never fails to read.
+		panic(err)
+	}
+	return id.String()
+}
+
+// Hex returns n random bytes, hex encoded.
+func Hex(r *rand.Rand, n int) string {
+	b := make([]byte, n)
+	Read(r, b)
+	return hex.EncodeToString(b)
+}
+
+// Word returns a random word.
+func Word(r *rand.Rand) string {
+	return words[r.IntN(len(words))]
+}
+
+// Sentence returns a random sentence of 4 to 10 words.
+func Sentence(r *rand.Rand) string {
+	s := make([]string, r.IntN(7)+4)
+	for i := range s {
+		s[i] = Word(r)
+	}
+	s[0] = strings.ToUpper(s[0][:1]) + s[0][1:]
+	return strings.Join(s, " ") + "."
+}
@@ -0,0 +1,169 @@
+package random
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types of a FieldConfig.
+const (
+	FieldString    = "string"
+	FieldInteger   = "integer"
+	FieldNumber    = "number"
+	FieldBoolean   = "boolean"
+	FieldTimestamp = "timestamp"
+)
+
+var fieldTypes = [...]string{FieldString, FieldInteger, FieldNumber, FieldBoolean, FieldTimestamp}
+
+// FieldConfig is the configuration of a Field, for generators whose
+// fields are defined in their configuration.  A field has the values
+// of its faker, one of its values or a random value of its type.  Min
+// and Max are the range of integers and numbers, 0 to 1000 by
+// default, or the length of strings of random letters.  Strings
+// without a length are words.  Layout is the Go time layout of
+// timestamps, which are the current time, RFC 3339 by default.  Empty
+// is the percentage of values that are empty.
+type FieldConfig struct {
+	Name   string   `config:"name" validate:"required"`
+	Type   string   `config:"type"`
+	Faker  string   `config:"faker"`
+	Values []string `config:"values"`
+	Layout string   `config:"layout"`
+	Min    *float64 `config:"min"`
+	Max    *float64 `config:"max"`
+	Empty  float64  `config:"empty"`
+}
+
+// Validate checks the type, faker, range and empty percentage of the
+// field.
+func (c *FieldConfig) Validate() error {
+	if c.Type != "" && !containsString(fieldTypes[:], c.Type) {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, strings.Join(fieldTypes[:], ", "))
+	}
+	if _, ok := fakers[c.Faker]; c.Faker != "" && !ok {
+		return fmt.Errorf("'%s' is not a valid value for 'faker' expected '%s'", c.Faker, strings.Join(FakerNames(), ", "))
+	}
+	min, max := c.bounds()
+	if max < min {
+		return fmt.Errorf("'%v' is not a valid value for 'max' expected a value of at least %v", max, min)
+	}
+	if c.kind() == FieldString && c.Min != nil && min < 0 {
+		return fmt.Errorf("'%v' is not a valid value for 'min' expected a length of 0 or more", min)
+	}
+	if c.Empty < 0 || c.Empty > 100 {
+		return fmt.Errorf("'%v' is not a valid value for 'empty' expected a percentage from 0 to 100", c.Empty)
+	}
+	return nil
+}
+
+// bounds returns the range of the field, with the defaults for the
+// bounds that are not set.
+func (c *FieldConfig) bounds() (min, max float64) {
+	min, max = 0, 1000
+	if c.kind() == FieldString {
+		min, max = 1, 10
+	}
+	switch {
+	case c.Min != nil && c.Max != nil:
+		min, max = *c.Min, *c.Max
+	case c.Min != nil:
+		min = *c.Min
+		if min > max {
+			max += min
+		}
+	case c.Max != nil:
+		max = *c.Max
+		min = math.Min(min, max)
+	}
+	return min, max
+}
+
+// kind returns the type of the field, which is a string by default.
+func (c *FieldConfig) kind() string {
+	if c.Type == "" {
+		return FieldString
+	}
+	return c.Type
+}
+
+// Field generates the values of a FieldConfig.
+type Field struct {
+	rand     *rand.Rand
+	c        FieldConfig
+	fake     func(r *rand.Rand) interface{}
+	min, max float64
+}
+
+// NewField returns the Field of c using r.  c must be valid.
+func NewField(r *rand.Rand, c FieldConfig) *Field {
+	f := &Field{rand: r, c: c}
+	f.fake = fakers[c.Faker]
+	f.min, f.max = c.bounds()
+	f.c.Type = c.kind()
+	if f.c.Layout == "" {
+		f.c.Layout = time.RFC3339
+	}
+	return f
+}
+
+// Name returns the name of the field.
+func (f *Field) Name() string {
+	return f.c.Name
+}
+
+// Type returns the type of the field.
+func (f *Field) Type() string {
+	return f.c.Type
+}
+
+// Value returns a random value of the field, as text.  now is the time
+// of timestamps.
+func (f *Field) Value(now time.Time) string {
+	if f.c.Empty > 0 && f.rand.Float64()*100 < f.c.Empty {
+		return ""
+	}
+	switch {
+	case f.fake != nil:
+		return fmt.Sprint(f.fake(f.rand))
+	case len(f.c.Values) > 0:
+		return f.c.Values[f.rand.IntN(len(f.c.Values))]
+	}
+	switch f.c.Type {
+	case FieldInteger:
+		lo, hi := int64(math.Ceil(f.min)), int64(math.Floor(f.max))
+		if hi < lo {
+			return strconv.FormatInt(lo, 10)
+		}
+		return strconv.FormatInt(lo+f.rand.Int64N(hi-lo+1), 10)
+	case FieldNumber:
+		v := math.Round((f.min+f.rand.Float64()*(f.max-f.min))*100) / 100
+		return strconv.FormatFloat(math.Max(f.min, math.Min(f.max, v)), 'f', -1, 64)
+	case FieldBoolean:
+		return strconv.FormatBool(f.rand.IntN(2) == 1)
+	case FieldTimestamp:
+		return now.Format(f.c.Layout)
+	}
+	if f.c.Min == nil && f.c.Max == nil {
+		return Word(f.rand)
+	}
+	lo, hi := int(f.min), int(f.max)
+	b := make([]byte, lo+f.rand.IntN(hi-lo+1))
+	for i := range b {
+		b[i] = byte('a' + f.rand.IntN(26))
+	}
+	return string(b)
+}
+
+func containsString(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
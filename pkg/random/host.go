@@ -0,0 +1,111 @@
+package random
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"strings"
+)
+
+const nameChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+var (
+	// ouis are the organizationally unique identifiers of the MAC
+	// addresses of vendors.
+	ouis = map[string][][3]byte{
+		"apple":       {{0xf0, 0x18, 0x98}, {0xa4, 0x83, 0xe7}, {0x3c, 0x22, 0xfb}},
+		"cisco":       {{0x00, 0x00, 0x0c}, {0x00, 0x01, 0x42}},
+		"dell":        {{0x00, 0x14, 0x22}, {0xf8, 0xbc, 0x12}, {0x18, 0x66, 0xda}},
+		"hp":          {{0x3c, 0xd9, 0x2b}},
+		"hyperv":      {{0x00, 0x15, 0x5d}},
+		"intel":       {{0x3c, 0xa9, 0xf4}, {0xa4, 0x4e, 0x31}},
+		"meraki":      {{0x00, 0x18, 0x0a}, {0x88, 0x15, 0x44}, {0xe0, 0x55, 0x3d}, {0x0c, 0x8d, 0xdb}},
+		"raspberrypi": {{0xb8, 0x27, 0xeb}, {0xdc, 0xa6, 0x32}, {0xe4, 0x5f, 0x01}},
+		"vmware":      {{0x00, 0x50, 0x56}, {0x00, 0x0c, 0x29}},
+	}
+
+	serverRoles = [...]string{"web", "app", "db", "dc", "file", "mail", "print", "proxy", "sql", "vpn"}
+	sites       = [...]string{"nyc", "sfo", "chi", "lon", "ams", "fra", "sin", "syd"}
+)
+
+// MACVendors returns the sorted names of the vendors of VendorMAC.
+func MACVendors() []string {
+	names := make([]string, 0, len(ouis))
+	for name := range ouis {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// VendorMAC returns a random MAC address with an OUI of vendor, such
+// as "dell" or "meraki", or false when vendor is not of MACVendors.
+func VendorMAC(r *rand.Rand, vendor string) (net.HardwareAddr, bool) {
+	pool, ok := ouis[vendor]
+	if !ok {
+		return nil, false
+	}
+	oui := pool[r.IntN(len(pool))]
+	mac := make(net.HardwareAddr, 6)
+	copy(mac, oui[:])
+	Read(r, mac[3:])
+	return mac, true
+}
+
+// MACPool picks MAC addresses of the OUIs of vendors, so the network
+// cards of a generator are of the vendors of a real fleet.
+type MACPool struct {
+	vendors []string
+}
+
+// NewMACPool returns the MACPool of vendors, or nil when there are no
+// vendors.
+func NewMACPool(name string, vendors []string) (*MACPool, error) {
+	if len(vendors) == 0 {
+		return nil, nil
+	}
+	for _, v := range vendors {
+		if _, ok := ouis[v]; !ok {
+			return nil, fmt.Errorf("'%s' is not a valid value for '%s' expected '%s'", v, name, strings.Join(MACVendors(), ", "))
+		}
+	}
+	return &MACPool{vendors: vendors}, nil
+}
+
+// MAC returns a random MAC address of a vendor of the pool.  A nil
+// pool returns a random locally administered address, as MAC does.
+func (p *MACPool) MAC(r *rand.Rand) net.HardwareAddr {
+	if p == nil {
+		return MAC(r)
+	}
+	mac, _ := VendorMAC(r, p.vendors[r.IntN(len(p.vendors))])
+	return mac
+}
+
+// WorkstationName returns a random name of the kind Windows gives new
+// computers, such as DESKTOP-4F7Q2ZK or LAPTOP-9KD2M3TB.
+func WorkstationName(r *rand.Rand) string {
+	prefix, n := "DESKTOP-", 7
+	if r.IntN(3) == 0 {
+		prefix, n = "LAPTOP-", 8
+	}
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = nameChars[r.IntN(len(nameChars))]
+	}
+	return prefix + string(b)
+}
+
+// ServerName returns a random server name of a site, role and number,
+// such as ams-sql-02.
+func ServerName(r *rand.Rand) string {
+	return fmt.Sprintf("%s-%s-%02d", sites[r.IntN(len(sites))], serverRoles[r.IntN(len(serverRoles))], r.IntN(20)+1)
+}
+
+// CloudHostname returns the host name a cloud provider gives an
+// instance with the private address ip, such as ip-10-0-1-23.  ip must
+// be an IPv4 address.
+func CloudHostname(ip net.IP) string {
+	return "ip-" + strings.ReplaceAll(ip.To4().String(), ".", "-")
+}
@@ -0,0 +1,100 @@
+package random
+
+import (
+	"math/rand/v2"
+	"net"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVendorMAC(t *testing.T) {
+	tests := map[string][]string{
+		"apple":       {"f0:18:98", "a4:83:e7", "3c:22:fb"},
+		"cisco":       {"00:00:0c", "00:01:42"},
+		"dell":        {"00:14:22", "f8:bc:12", "18:66:da"},
+		"hp":          {"3c:d9:2b"},
+		"hyperv":      {"00:15:5d"},
+		"intel":       {"3c:a9:f4", "a4:4e:31"},
+		"meraki":      {"00:18:0a", "88:15:44", "e0:55:3d", "0c:8d:db"},
+		"raspberrypi": {"b8:27:eb", "dc:a6:32", "e4:5f:01"},
+		"vmware":      {"00:50:56", "00:0c:29"},
+	}
+	assert.Len(t, MACVendors(), len(tests))
+	for vendor, prefixes := range tests {
+		seed := int64(1)
+		r := NewRand(&seed)
+		seen := make(map[string]bool)
+		for i := 0; i < 100; i++ {
+			mac, ok := VendorMAC(r, vendor)
+			assert.True(t, ok, vendor)
+			assert.Len(t, mac, 6, vendor)
+			prefix := mac.String()[:8]
+			assert.Contains(t, prefixes, prefix, vendor)
+			seen[prefix] = true
+		}
+		assert.Len(t, seen, len(prefixes), vendor)
+	}
+
+	seed := int64(1)
+	mac, ok := VendorMAC(NewRand(&seed), "nokia")
+	assert.False(t, ok)
+	assert.Nil(t, mac)
+}
+
+func TestMACPool(t *testing.T) {
+	tests := map[string]struct {
+		vendors []string
+		prefix  *regexp.Regexp
+		err     string
+	}{
+		"none":    {prefix: regexp.MustCompile(`^.[26ae]:`)},
+		"vendors": {vendors: []string{"hyperv", "vmware"}, prefix: regexp.MustCompile(`^(00:15:5d|00:50:56|00:0c:29):`)},
+		"invalid": {vendors: []string{"dell", "nokia"}, err: "'nokia' is not a valid value for 'macs' expected 'apple, cisco, dell, hp, hyperv, intel, meraki, raspberrypi, vmware'"},
+	}
+	for name, tc := range tests {
+		p, err := NewMACPool("macs", tc.vendors)
+		if tc.err != "" {
+			assert.EqualError(t, err, tc.err, name)
+			continue
+		}
+		assert.NoError(t, err, name)
+		seed := int64(1)
+		r := NewRand(&seed)
+		for i := 0; i < 100; i++ {
+			assert.Regexp(t, tc.prefix, p.MAC(r).String(), name)
+		}
+	}
+}
+
+func TestHostnames(t *testing.T) {
+	tests := map[string]struct {
+		name func(r *rand.Rand) string
+		want *regexp.Regexp
+	}{
+		"workstation": {name: WorkstationName, want: regexp.MustCompile(`^(DESKTOP-[0-9A-Z]{7}|LAPTOP-[0-9A-Z]{8})$`)},
+		"server":      {name: ServerName, want: regexp.MustCompile(`^(nyc|sfo|chi|lon|ams|fra|sin|syd)-(web|app|db|dc|file|mail|print|proxy|sql|vpn)-(0[1-9]|1[0-9]|20)$`)},
+	}
+	for name, tc := range tests {
+		seed := int64(1)
+		r := NewRand(&seed)
+		for i := 0; i < 100; i++ {
+			assert.Regexp(t, tc.want, tc.name(r), name)
+		}
+	}
+}
+
+func TestCloudHostname(t *testing.T) {
+	tests := map[string]struct {
+		ip   net.IP
+		want string
+	}{
+		"private":     {ip: net.ParseIP("10.0.1.23"), want: "ip-10-0-1-23"},
+		"4-in-6":      {ip: net.ParseIP("::ffff:172.31.255.4"), want: "ip-172-31-255-4"},
+		"four octets": {ip: net.IPv4(192, 168, 0, 1).To4(), want: "ip-192-168-0-1"},
+	}
+	for name, tc := range tests {
+		assert.Equal(t, tc.want, CloudHostname(tc.ip), name)
+	}
+}
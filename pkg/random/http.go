@@ -1,7 +1,7 @@
 package random
 
 import (
-	"math/rand"
+	"math/rand/v2"
 	"net/http"
 )
 
@@ -62,21 +62,21 @@ var (
 )
 
 // HTTPMethod returns a random HTTP method.
-func HTTPMethod() string {
-	return httpMethods[rand.Intn(len(httpMethods))]
+func HTTPMethod(r *rand.Rand) string {
+	return httpMethods[r.IntN(len(httpMethods))]
 }
 
 // HTTPStatus returns a random HTTP status code.
-func HTTPStatus() int {
-	return httpStatuses[rand.Intn(len(httpStatuses))]
+func HTTPStatus(r *rand.Rand) int {
+	return httpStatuses[r.IntN(len(httpStatuses))]
 }
 
 // HTTPVersion returns a random HTTP version.
-func HTTPVersion() string {
-	return httpVersions[rand.Intn(len(httpVersions))]
+func HTTPVersion(r *rand.Rand) string {
+	return httpVersions[r.IntN(len(httpVersions))]
 }
 
 // UserAgent returns a random user agent string.
-func UserAgent() string {
-	return userAgents[rand.Intn(len(userAgents))]
+func UserAgent(r *rand.Rand) string {
+	return userAgents[r.IntN(len(userAgents))]
 }
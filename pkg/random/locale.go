@@ -0,0 +1,134 @@
+package random
+
+import (
+	"math/rand/v2"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+var (
+	accentedFirstNames = [...]string{"José", "Zoë", "Søren", "Łukasz", "François", "Björn", "İlkay", "Jiří", "Ségolène", "Ñusta", "Þóra", "Dương"}
+	accentedLastNames  = [...]string{"Müller", "Gonçalves", "Øyen", "Wróbel", "Lefèvre", "Šimůnek", "Yılmaz", "Ólafsdóttir", "Nguyễn", "Peña", "Ström", "Dvořák"}
+	// cjkNames and rtlNames are written without spaces between the
+	// family and the given name, as they are in user names.
+	cjkNames = [...]string{"山田太郎", "佐藤花子", "王伟", "李娜", "김민준", "이서연", "陳志明", "鈴木一郎"}
+	rtlNames = [...]string{"محمدالعلي", "فاطمةحسن", "عليرضا", "דודכהן", "נועהלוי", "יוסףמזרחי"}
+	// idnLabels are labels of internationalized domain names.
+	idnLabels    = [...]string{"münchen", "zürich", "bücher", "東京", "例え", "пример", "مثال", "서울", "עברית", "ελλάδα"}
+	unicodeWords = [...]string{"café", "naïve", "straße", "ログイン", "错误", "서버", "خطأ", "שגיאה", "ошибка", "σφάλμα", "ñandú"}
+	accents      = map[rune]rune{'a': 'á', 'e': 'é', 'i': 'í', 'o': 'ö', 'u': 'ü', 'n': 'ñ', 'c': 'ç', 'A': 'Å', 'E': 'É', 'O': 'Ø', 'U': 'Ü'}
+)
+
+// Localize returns a non-ASCII variant of s of the same shape, to test
+// the UTF-8 handling of a pipeline end to end.  URLs, email addresses
+// and host names get internationalized labels, values with spaces are
+// names in accented, CJK or right-to-left scripts, or sentences with a
+// word of them, and other values are accented or are a CJK or
+// right-to-left name.
+func Localize(r *rand.Rand, s string) string {
+	switch {
+	case strings.Contains(s, "://"):
+		u, err := url.Parse(s)
+		if err != nil || u.Hostname() == "" {
+			return localizeToken(r, s)
+		}
+		// The URL as it is written, not percent-encoded, with the
+		// word as the first segment of the path.
+		i := strings.Index(s, u.Host)
+		host := strings.Replace(u.Host, u.Hostname(), localizeHost(r, u.Hostname()), 1)
+		word := unicodeWords[r.IntN(len(unicodeWords))]
+		return s[:i] + host + "/" + word + s[i+len(u.Host):]
+	case strings.Count(s, "@") == 1 && !strings.Contains(s, " "):
+		at := strings.Index(s, "@")
+		return localizeToken(r, s[:at]) + "@" + localizeHost(r, s[at+1:])
+	case isHostname(s):
+		return localizeHost(r, s)
+	case strings.Contains(s, " "):
+		words := strings.Fields(s)
+		if isName(words) {
+			return internationalName(r)
+		}
+		words[r.IntN(len(words))] = unicodeWords[r.IntN(len(unicodeWords))]
+		return strings.Join(words, " ")
+	default:
+		return localizeToken(r, s)
+	}
+}
+
+// localizeHost returns the host name h with its first label, or the
+// host name of an IP address, replaced by an internationalized label.
+func localizeHost(r *rand.Rand, h string) string {
+	label := idnLabels[r.IntN(len(idnLabels))]
+	if i := strings.Index(h, "."); i > 0 && isHostname(h) {
+		return label + h[i:]
+	}
+	return label + ".example.com"
+}
+
+// localizeToken returns the token s accented or, one in two times, a
+// CJK or right-to-left name.
+func localizeToken(r *rand.Rand, s string) string {
+	switch r.IntN(4) {
+	case 0:
+		return cjkNames[r.IntN(len(cjkNames))]
+	case 1:
+		return rtlNames[r.IntN(len(rtlNames))]
+	}
+	accented := []rune(s)
+	changed := false
+	for i, c := range accented {
+		if a, ok := accents[c]; ok && (!changed || r.IntN(2) == 0) {
+			accented[i], changed = a, true
+		}
+	}
+	if !changed {
+		return s + unicodeWords[r.IntN(len(unicodeWords))]
+	}
+	return string(accented)
+}
+
+func internationalName(r *rand.Rand) string {
+	switch r.IntN(4) {
+	case 0:
+		return cjkNames[r.IntN(len(cjkNames))]
+	case 1:
+		return rtlNames[r.IntN(len(rtlNames))]
+	}
+	return accentedFirstNames[r.IntN(len(accentedFirstNames))] + " " + accentedLastNames[r.IntN(len(accentedLastNames))]
+}
+
+// isHostname reports whether s looks like a domain name, labels of
+// letters, digits and hyphens separated by dots, with a letter in the
+// last label.
+func isHostname(s string) bool {
+	labels := strings.Split(s, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, l := range labels {
+		if l == "" {
+			return false
+		}
+		for _, c := range l {
+			if c > unicode.MaxASCII || !(c == '-' || unicode.IsLetter(c) || unicode.IsDigit(c)) {
+				return false
+			}
+		}
+	}
+	return strings.IndexFunc(labels[len(labels)-1], unicode.IsLetter) >= 0
+}
+
+// isName reports whether the words are a name, two or three
+// capitalized words.
+func isName(words []string) bool {
+	if len(words) < 2 || len(words) > 3 {
+		return false
+	}
+	for _, w := range words {
+		if !unicode.IsUpper([]rune(w)[0]) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,28 +1,54 @@
-// Package random provides functions for generating random objects using math/rand
+// Package random provides functions for generating random objects using math/rand/v2
+//
+// All functions take the *rand.Rand to use, so that generators with a
+// seed produce the same objects on every run.
 package random
 
 import (
-	"math/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"math/rand/v2"
 	"net"
+	"sync"
 	"time"
 )
 
 // IPv4 returns a random net.IP from the IPv4 address space.  No
 // effort is made to prevent non-routable addresses.
-func IPv4() net.IP {
-	u32 := rand.Uint32()
+func IPv4(r *rand.Rand) net.IP {
+	u32 := r.Uint32()
 	return net.IPv4(byte(u32&0xff), byte((u32>>8)&0xff), byte((u32>>16)&0xff), byte((u32>>24)&0xff))
 }
 
-// Port returns a random integer from 0 to 65535.
-func Port() int {
-	return rand.Intn(65536)
+// IPv6 returns a random net.IP from the IPv6 global unicast address
+// space, 2000::/3.  No effort is made to prevent reserved addresses
+// such as documentation prefixes.
+func IPv6(r *rand.Rand) net.IP {
+	ip := make(net.IP, net.IPv6len)
+	Read(r, ip)
+	ip[0] = 0x20 | ip[0]&0x1f
+	return ip
+}
+
+// IP returns a random IPv6 address for the percentage ipv6, from 0 to
+// 100, of the calls, and a random IPv4 address otherwise, for dual
+// stack networks.
+func IP(r *rand.Rand, ipv6 float64) net.IP {
+	if ipv6 > 0 && r.Float64()*100 < ipv6 {
+		return IPv6(r)
+	}
+	return IPv4(r)
 }
 
-func Randomtime() string {
-	// Seed the random number generator
-	rand.Seed(time.Now().UnixNano())
+// Port returns a random integer from 0 to 65535.
+func Port(r *rand.Rand) int {
+	return r.IntN(65536)
+}
 
+// Randomtime returns a random time of day in the last 20 minutes,
+// formatted as HH:MM:SS.
+func Randomtime(r *rand.Rand) string {
 	// Get the current time
 	now := time.Now()
 
@@ -33,10 +59,88 @@ func Randomtime() string {
 	lowerBound := now.Add(-duration)
 
 	// Generate a random timestamp between now and lowerBound
-	randomTimestamp := lowerBound.Add(time.Duration(rand.Int63n(now.UnixNano()-lowerBound.UnixNano())) * time.Nanosecond)
+	randomTimestamp := lowerBound.Add(time.Duration(r.Int64N(now.UnixNano()-lowerBound.UnixNano())) * time.Nanosecond)
 
 	// Format the random timestamp as HH:MM:SS
 	formattedTime := randomTimestamp.Format("15:04:05")
 
 	return formattedTime
 }
+
+// NewRand returns a *rand.Rand of a ChaCha8 stream of its own, seeded
+// with seed.  When seed is nil the stream is the next one derived from
+// the seed of the run, see Seed, so the generators of concurrent
+// workers neither share a source nor its lock and a run with the same
+// seed writes the same events.
+func NewRand(seed *int64) *rand.Rand {
+	if seed != nil {
+		return rand.New(rand.NewChaCha8(key(*seed, 0)))
+	}
+	run.Lock()
+	defer run.Unlock()
+	run.streams++
+	return rand.New(rand.NewChaCha8(key(run.seed, run.streams)))
+}
+
+// NewWrapperRand returns a *rand.Rand of the stream of a wrapper of a
+// generator seeded with seed, so the wrapper does not draw the numbers
+// of the generator.  stream tells the wrappers apart.  When seed is nil
+// it is NewRand(nil).
+func NewWrapperRand(seed *int64, stream uint64) *rand.Rand {
+	if seed != nil {
+		return rand.New(rand.NewChaCha8(key(*seed, wrapperStreams|stream)))
+	}
+	return NewRand(nil)
+}
+
+// wrapperStreams is the first of the streams of the wrappers of a
+// seed, far from those of the run.
+const wrapperStreams = 1 << 63
+
+// Seed sets the seed of the run the streams of NewRand without a seed
+// are derived from and starts them over.  It is 1 until it is set.
+func Seed(seed int64) {
+	run.Lock()
+	defer run.Unlock()
+	run.seed, run.streams = seed, 0
+}
+
+var run = struct {
+	sync.Mutex
+	seed    int64
+	streams uint64
+}{seed: 1}
+
+// key returns the ChaCha8 seed of the stream of a seed.  Stream 0 is
+// the one of a seed of a generator, those from wrapperStreams the ones
+// of its wrappers and the others those of the run.
+func key(seed int64, stream uint64) [32]byte {
+	var b [16]byte
+	binary.LittleEndian.PutUint64(b[:8], uint64(seed))
+	binary.LittleEndian.PutUint64(b[8:], stream)
+	return sha256.Sum256(b[:])
+}
+
+// Read fills b with random bytes of r, like the Read of math/rand.
+func Read(r *rand.Rand, b []byte) {
+	for len(b) > 0 {
+		var u [8]byte
+		binary.LittleEndian.PutUint64(u[:], r.Uint64())
+		b = b[copy(b, u[:]):]
+	}
+}
+
+// Reader returns an io.Reader of the random bytes of r, for the
+// functions of other packages that read their randomness.
+func Reader(r *rand.Rand) io.Reader {
+	return reader{r}
+}
+
+type reader struct {
+	r *rand.Rand
+}
+
+func (r reader) Read(b []byte) (int, error) {
+	Read(r.r, b)
+	return len(b), nil
+}
@@ -0,0 +1,133 @@
+package random
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net"
+	"time"
+)
+
+// topFlowShare is the fraction of flows that are between top talker
+// pairs.  The bytes of these flows are scaled up so the pairs account
+// for the configured share of bytes.
+const topFlowShare = 0.1
+
+// TopTalkersConfig is the configuration of a TopTalkers, for
+// generators of flow records.  Pairs is the number of host pairs
+// that are top talkers, 0 disables top talkers.  Share is the
+// fraction of bytes the pairs account for within each Window of the
+// flow timestamps.
+type TopTalkersConfig struct {
+	Pairs  int           `config:"pairs"`
+	Share  float64       `config:"share"`
+	Window time.Duration `config:"window"`
+}
+
+// DefaultTopTalkersConfig returns a disabled TopTalkersConfig with the
+// default share and window.
+func DefaultTopTalkersConfig() TopTalkersConfig {
+	return TopTalkersConfig{
+		Share:  0.8,
+		Window: time.Minute,
+	}
+}
+
+// Validate checks that Pairs is not negative, that Share is a
+// majority of the bytes and that Window is positive.
+func (c *TopTalkersConfig) Validate() error {
+	if c.Pairs < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'pairs' expected a value of 0 or more", c.Pairs)
+	}
+	if c.Share < 0.5 || c.Share >= 1 {
+		return fmt.Errorf("'%g' is not a valid value for 'share' expected a value from 0.5 to below 1", c.Share)
+	}
+	if c.Window <= 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'window' expected a positive duration", c.Window)
+	}
+	return nil
+}
+
+// TopTalkers picks the addresses of flows so that a small set of host
+// pairs accounts for most of the bytes, like the top-N of real
+// traffic.  Pairs are ranked, the first pair is picked most often.
+// The bytes are counted per window, so the pairs have their share in
+// every window, also in quiet ones with few flows.
+type TopTalkers struct {
+	rand    *rand.Rand
+	pairs   [][2]net.IP
+	weights []float64
+	total   float64
+	share   float64
+	scale   int
+
+	window time.Duration
+	start  time.Time
+	top    float64
+	other  float64
+}
+
+// NewTopTalkers returns the TopTalkers for c using r, or nil when top
+// talkers are disabled.
+func NewTopTalkers(r *rand.Rand, c TopTalkersConfig) *TopTalkers {
+	if c.Pairs == 0 {
+		return nil
+	}
+
+	t := &TopTalkers{
+		rand:   r,
+		share:  c.Share,
+		window: c.Window,
+		scale:  int(math.Round(c.Share * (1 - topFlowShare) / (topFlowShare * (1 - c.Share)))),
+	}
+	for i := 0; i < c.Pairs; i++ {
+		t.pairs = append(t.pairs, [2]net.IP{IPv4(r), IPv4(r)})
+		w := 1 / float64(i+1)
+		t.weights = append(t.weights, w)
+		t.total += w
+	}
+
+	return t
+}
+
+// Pair returns the source and destination address of the next flow,
+// of bytes bytes at now, and the factor to multiply its packets and
+// bytes by.  Flows that are not between top talkers have random
+// addresses and a factor of 1.  A flow that would bring the share of
+// the pairs in the window of now below Share is made a top talker
+// flow, with a factor that restores the share.
+func (t *TopTalkers) Pair(now time.Time, bytes int) (src, dst net.IP, scale int) {
+	if start := now.Truncate(t.window); !start.Equal(t.start) {
+		t.start, t.top, t.other = start, 0, 0
+	}
+
+	b := float64(bytes)
+	top := t.rand.Float64() < topFlowShare
+	if !top && t.top < t.share*(t.top+t.other+b) {
+		top = true
+	}
+	if !top {
+		t.other += b
+		return IPv4(t.rand), IPv4(t.rand), 1
+	}
+
+	scale = t.scale
+	if b > 0 {
+		// The least factor for top+b*scale >= share*(top+other+b*scale).
+		if need := int(math.Ceil((t.share*(t.top+t.other) - t.top) / (b * (1 - t.share)))); need > scale {
+			scale = need
+		}
+	}
+	t.top += b * float64(scale)
+
+	i := 0
+	r := t.rand.Float64() * t.total
+	for ; i < len(t.weights)-1; i++ {
+		if r < t.weights[i] {
+			break
+		}
+		r -= t.weights[i]
+	}
+
+	return t.pairs[i][0], t.pairs[i][1], scale
+}
@@ -0,0 +1,59 @@
+package random
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopTalkersWindows(t *testing.T) {
+	seed := int64(1)
+	r := NewRand(&seed)
+	c := DefaultTopTalkersConfig()
+	c.Pairs = 3
+	talkers := NewTopTalkers(r, c)
+	pairs := make(map[[2]string]bool)
+	for _, p := range talkers.pairs {
+		pairs[[2]string{p[0].String(), p[1].String()}] = true
+	}
+
+	// Busy windows with a flow every second and quiet ones with a
+	// handful of flows.
+	start := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	for w, flows := range []int{600, 3, 1, 600, 5, 2} {
+		var top, total float64
+		for i := 0; i < flows; i++ {
+			now := start.Add(time.Duration(w)*c.Window + time.Duration(i)*c.Window/time.Duration(flows))
+			bytes := r.IntN(100000) + 1
+			src, dst, scale := talkers.Pair(now, bytes)
+			b := float64(bytes * scale)
+			if pairs[[2]string{src.String(), dst.String()}] {
+				top += b
+			}
+			total += b
+			assert.GreaterOrEqual(t, top/total, c.Share, "window %d flow %d", w, i)
+		}
+	}
+}
+
+func TestTopTalkersConfig(t *testing.T) {
+	tests := map[string]struct {
+		c   TopTalkersConfig
+		err string
+	}{
+		"default":     {c: DefaultTopTalkersConfig()},
+		"pairs":       {c: TopTalkersConfig{Pairs: -1, Share: 0.8, Window: time.Minute}, err: "'-1' is not a valid value for 'pairs' expected a value of 0 or more"},
+		"share":       {c: TopTalkersConfig{Pairs: 5, Share: 1, Window: time.Minute}, err: "'1' is not a valid value for 'share' expected a value from 0.5 to below 1"},
+		"window":      {c: TopTalkersConfig{Pairs: 5, Share: 0.8}, err: "'0s' is not a valid value for 'window' expected a positive duration"},
+		"short share": {c: TopTalkersConfig{Pairs: 5, Share: 0.4, Window: time.Minute}, err: "'0.4' is not a valid value for 'share' expected a value from 0.5 to below 1"},
+	}
+	for name, tc := range tests {
+		err := tc.c.Validate()
+		if tc.err == "" {
+			assert.NoError(t, err, name)
+			continue
+		}
+		assert.EqualError(t, err, tc.err, name)
+	}
+}
@@ -0,0 +1,125 @@
+package random
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+)
+
+// Kinds of user agents.
+const (
+	UserAgentBrowser = "browser"
+	UserAgentMobile  = "mobile"
+	UserAgentBot     = "bot"
+	UserAgentCLI     = "cli"
+)
+
+var (
+	userAgentKinds = [...]string{UserAgentBrowser, UserAgentMobile, UserAgentBot, UserAgentCLI}
+
+	userAgentsByKind = map[string][]string{
+		UserAgentBrowser: {
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0.0.0 Safari/537.36",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0.0.0 Safari/537.36 Edg/122.0.2365.66",
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:123.0) Gecko/20100101 Firefox/123.0",
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0.0.0 Safari/537.36",
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.3.1 Safari/605.1.15",
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 14.3; rv:123.0) Gecko/20100101 Firefox/123.0",
+			"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0.0.0 Safari/537.36",
+			"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:123.0) Gecko/20100101 Firefox/123.0",
+		},
+		UserAgentMobile: {
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_3_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.3.1 Mobile/15E148 Safari/604.1",
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_3 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/122.0.6261.89 Mobile/15E148 Safari/604.1",
+			"Mozilla/5.0 (iPad; CPU OS 17_3 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.3 Mobile/15E148 Safari/604.1",
+			"Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0.6261.105 Mobile Safari/537.36",
+			"Mozilla/5.0 (Linux; Android 14; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/23.0 Chrome/115.0.0.0 Mobile Safari/537.36",
+			"Mozilla/5.0 (Android 14; Mobile; rv:123.0) Gecko/123.0 Firefox/123.0",
+		},
+		UserAgentBot: {
+			"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			"Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)",
+			"Mozilla/5.0 (compatible; YandexBot/3.0; +http://yandex.com/bots)",
+			"Mozilla/5.0 (compatible; AhrefsBot/7.0; +http://ahrefs.com/robot/)",
+			"Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; GPTBot/1.0; +https://openai.com/gptbot)",
+			"facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)",
+		},
+		UserAgentCLI: {
+			"curl/8.4.0",
+			"curl/7.81.0",
+			"Wget/1.21.2",
+			"python-requests/2.31.0",
+			"Go-http-client/1.1",
+			"PostmanRuntime/7.36.3",
+			"okhttp/4.12.0",
+		},
+	}
+)
+
+// DefaultUserAgentWeights returns the weights of the kinds of user
+// agents of the traffic of a typical web site.
+func DefaultUserAgentWeights() map[string]float64 {
+	return map[string]float64{UserAgentBrowser: 55, UserAgentMobile: 35, UserAgentBot: 7, UserAgentCLI: 3}
+}
+
+// ValidUserAgentWeights checks the weights of option, which are keyed
+// by the kinds of user agents.  nil weights are the default weights.
+func ValidUserAgentWeights(option string, weights map[string]float64) error {
+	if weights == nil {
+		return nil
+	}
+	total := 0.0
+	for kind, w := range weights {
+		if _, ok := userAgentsByKind[kind]; !ok {
+			return fmt.Errorf("'%s' is not a valid value for '%s' expected '%s'", kind, option, strings.Join(userAgentKinds[:], ", "))
+		}
+		if w < 0 {
+			return fmt.Errorf("'%v' is not a valid value for '%s.%s' expected a weight of 0 or more", w, option, kind)
+		}
+		total += w
+	}
+	if total == 0 {
+		return fmt.Errorf("'%s' expected a weight greater than 0", option)
+	}
+	return nil
+}
+
+// UserAgents is a weighted distribution of the kinds of user agents,
+// browsers, mobile browsers and apps, bots and command line clients.
+type UserAgents struct {
+	kinds  []string
+	totals []float64
+}
+
+// NewUserAgents returns the UserAgents of the weights by kind, checked
+// by ValidUserAgentWeights, or of the default weights when weights is
+// nil.  The kinds are sorted, so a seeded generator draws the same
+// user agents.
+func NewUserAgents(weights map[string]float64) *UserAgents {
+	if weights == nil {
+		weights = DefaultUserAgentWeights()
+	}
+	u := &UserAgents{}
+	for kind := range weights {
+		u.kinds = append(u.kinds, kind)
+	}
+	sort.Strings(u.kinds)
+	total := 0.0
+	for _, kind := range u.kinds {
+		total += weights[kind]
+		u.totals = append(u.totals, total)
+	}
+	return u
+}
+
+// UserAgent returns a random user agent of a kind drawn by weight.
+func (u *UserAgents) UserAgent(r *rand.Rand) string {
+	v := r.Float64() * u.totals[len(u.totals)-1]
+	i := sort.SearchFloat64s(u.totals, v)
+	for i < len(u.totals)-1 && u.totals[i] <= v {
+		i++
+	}
+	agents := userAgentsByKind[u.kinds[i]]
+	return agents[r.IntN(len(agents))]
+}
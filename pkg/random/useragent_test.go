@@ -0,0 +1,75 @@
+package random
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserAgents(t *testing.T) {
+	kinds := make(map[string]string)
+	for kind, agents := range userAgentsByKind {
+		for _, agent := range agents {
+			kinds[agent] = kind
+		}
+	}
+
+	tests := map[string]map[string]float64{
+		"default":  nil,
+		"bots":     {UserAgentBrowser: 1, UserAgentBot: 3},
+		"cli only": {UserAgentCLI: 1},
+	}
+	for name, weights := range tests {
+		t.Run(name, func(t *testing.T) {
+			want := weights
+			if want == nil {
+				want = DefaultUserAgentWeights()
+			}
+			total := 0.0
+			for _, w := range want {
+				total += w
+			}
+
+			seed := int64(1)
+			r := NewRand(&seed)
+			u := NewUserAgents(weights)
+			n := 10000
+			seen := make(map[string]int)
+			for i := 0; i < n; i++ {
+				kind, ok := kinds[u.UserAgent(r)]
+				assert.True(t, ok)
+				seen[kind]++
+			}
+			for kind, count := range seen {
+				assert.Contains(t, want, kind)
+				assert.InDelta(t, want[kind]/total, float64(count)/float64(n), 0.02, kind)
+			}
+			for kind, w := range want {
+				if w > 0 {
+					assert.NotZero(t, seen[kind], kind)
+				}
+			}
+		})
+	}
+}
+
+func TestValidUserAgentWeights(t *testing.T) {
+	tests := map[string]struct {
+		weights map[string]float64
+		err     string
+	}{
+		"nil":          {},
+		"valid":        {weights: map[string]float64{UserAgentBrowser: 1, UserAgentMobile: 0}},
+		"invalid kind": {weights: map[string]float64{"tv": 1}, err: "'tv' is not a valid value for 'user_agents' expected 'browser, mobile, bot, cli'"},
+		"negative":     {weights: map[string]float64{UserAgentBot: -1}, err: "'-1' is not a valid value for 'user_agents.bot' expected a weight of 0 or more"},
+		"all zero":     {weights: map[string]float64{UserAgentBot: 0}, err: "'user_agents' expected a weight greater than 0"},
+	}
+	for name, tc := range tests {
+		err := ValidUserAgentWeights("user_agents", tc.weights)
+		if tc.err == "" {
+			assert.NoError(t, err, name)
+			continue
+		}
+		assert.EqualError(t, err, tc.err, name)
+	}
+}
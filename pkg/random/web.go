@@ -0,0 +1,119 @@
+package random
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/url"
+	"strings"
+)
+
+var (
+	domainWords = [...]string{
+		"silver", "pine", "valley", "brick", "stone", "ridge", "oak", "wood", "grove", "blue",
+		"water", "haven", "copper", "hollow", "windy", "river", "crystal", "bay", "iron", "cove",
+		"sunset", "bluff", "maple", "golden", "peak", "meadow", "briar", "highland", "green", "field",
+		"lake", "rose", "vista", "willow", "brook", "cedar", "crest", "elm", "spring", "harbor",
+	}
+	tlds      = [...]string{"com", "com", "com", "net", "org", "io", "co", "info", "de", "co.uk"}
+	urlPaths  = [...]string{"", "index.html", "login", "search", "products", "cart", "checkout", "account/settings", "api/v1/items", "images/logo.png", "static/app.js", "blog/2024/release-notes", "help/faq", "download/setup.exe"}
+	urlParams = [...]string{"q", "page", "sort", "id", "lang", "ref", "session", "utm_source", "utm_medium", "category"}
+	urlValues = [...]string{"shoes", "2", "price", "desc", "en", "newsletter", "email", "1042", "true", "summer sale"}
+)
+
+// Wordlist is the words the domains, URLs and email addresses are drawn
+// from.  Domains are used as they are, otherwise a domain is two or
+// three Labels with a TLD, such as "silverpinevalley.com".  Paths are
+// the paths of the URLs, without the leading slash, and Params the
+// names of their query parameters.  Users are the local parts of the
+// email addresses, otherwise they are user names.  Scheme is the
+// scheme of the URLs, by default https.  Empty lists use the built in
+// words, so the zero Wordlist, or a nil *Wordlist, has plausible
+// values.
+type Wordlist struct {
+	Domains []string `config:"domains"`
+	Labels  []string `config:"labels"`
+	TLDs    []string `config:"tlds"`
+	Paths   []string `config:"paths"`
+	Params  []string `config:"params"`
+	Users   []string `config:"users"`
+	Scheme  string   `config:"scheme"`
+}
+
+// Domain returns a random domain name.
+func (w *Wordlist) Domain(r *rand.Rand) string {
+	if w != nil && len(w.Domains) > 0 {
+		return w.Domains[r.IntN(len(w.Domains))]
+	}
+	n := r.IntN(2) + 2
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		if w != nil && len(w.Labels) > 0 {
+			b.WriteString(w.Labels[r.IntN(len(w.Labels))])
+		} else {
+			b.WriteString(domainWords[r.IntN(len(domainWords))])
+		}
+	}
+	b.WriteByte('.')
+	if w != nil && len(w.TLDs) > 0 {
+		b.WriteString(w.TLDs[r.IntN(len(w.TLDs))])
+	} else {
+		b.WriteString(tlds[r.IntN(len(tlds))])
+	}
+	return b.String()
+}
+
+// Query returns a random query string of one to three parameters,
+// escaped, without the leading question mark.
+func (w *Wordlist) Query(r *rand.Rand) string {
+	v := url.Values{}
+	for i := r.IntN(3) + 1; i > 0; i-- {
+		name := urlParams[r.IntN(len(urlParams))]
+		if w != nil && len(w.Params) > 0 {
+			name = w.Params[r.IntN(len(w.Params))]
+		}
+		v.Set(name, urlValues[r.IntN(len(urlValues))])
+	}
+	return v.Encode()
+}
+
+// URL returns a random URL of a host of a domain, with a query string
+// half of the time.
+func (w *Wordlist) URL(r *rand.Rand) string {
+	scheme := "https"
+	if w != nil && w.Scheme != "" {
+		scheme = w.Scheme
+	}
+	host := w.Domain(r)
+	if w == nil || len(w.Domains) == 0 {
+		host = "www." + host
+	}
+	path := urlPaths[r.IntN(len(urlPaths))]
+	if w != nil && len(w.Paths) > 0 {
+		path = w.Paths[r.IntN(len(w.Paths))]
+	}
+	u := fmt.Sprintf("%s://%s/%s", scheme, host, path)
+	if r.IntN(2) == 0 {
+		u += "?" + w.Query(r)
+	}
+	return u
+}
+
+// Email returns a random email address at a domain.
+func (w *Wordlist) Email(r *rand.Rand) string {
+	if w != nil && len(w.Users) > 0 {
+		return w.Users[r.IntN(len(w.Users))] + "@" + w.Domain(r)
+	}
+	return Username(r) + "@" + w.Domain(r)
+}
+
+// Domain returns a random domain name of the built in words, such as
+// "silverpinevalley.com".
+func Domain(r *rand.Rand) string {
+	return (*Wordlist)(nil).Domain(r)
+}
+
+// Query returns a random query string of the built in words, such as
+// "page=2&q=shoes".
+func Query(r *rand.Rand) string {
+	return (*Wordlist)(nil).Query(r)
+}
@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/output"
+	"github.com/stretchr/testify/assert"
+)
+
+// textOutput is an output without WriteBinary.
+type textOutput struct{}
+
+func (textOutput) Write(p []byte) (int, error) { return len(p), nil }
+func (textOutput) Close() error                { return nil }
+func (textOutput) NewInterval() error          { return nil }
+
+// binaryGenerator generates the same binary event.
+type binaryGenerator struct{}
+
+func (binaryGenerator) Next() ([]byte, error) { return []byte{0x00, 0x0a, 0xff}, nil }
+func (binaryGenerator) Binary() bool          { return true }
+
+func init() {
+	generator.Register("test:binary", func(*ucfg.Config) (generator.Generator, error) {
+		return binaryGenerator{}, nil
+	})
+	output.Register("test:text", func(*ucfg.Config) (output.Output, error) {
+		return textOutput{}, nil
+	})
+}
+
+func TestBinary(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := ucfg.NewFrom(map[string]interface{}{
+		"generator": map[string]interface{}{"type": "test:binary"},
+		"output":    map[string]interface{}{"type": "file", "filename": filepath.Join(dir, "binary.log")},
+		"records":   2,
+	})
+	assert.Nil(t, err)
+	r, err := New(cfg)
+	assert.Nil(t, err)
+	assert.Nil(t, r.Execute())
+	b, err := os.ReadFile(filepath.Join(dir, "binary.log"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("\x00\x00\x00\x03\x00\x0a\xff\x00\x00\x00\x03\x00\x0a\xff"), b)
+
+	cfg, err = ucfg.NewFrom(map[string]interface{}{
+		"generator": map[string]interface{}{"type": "test:binary"},
+		"output":    map[string]interface{}{"type": "test:text"},
+	})
+	assert.Nil(t, err)
+	_, err = New(cfg)
+	if assert.Error(t, err) {
+		assert.Equal(t, "'test:text' output can not write the binary events of 'test:binary'", err.Error())
+	}
+}
@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/generator"
+)
+
+// day is the period Estimate projects the volume of a runner for.
+const day = 24 * time.Hour
+
+// Projection is the volume of events a runner writes in a day, as
+// estimated from sample events of its generator.  The bytes are the
+// bytes of the events, without the framing the output adds.
+type Projection struct {
+	Generator    string
+	Interval     time.Duration
+	Records      int
+	AverageBytes float64
+	EventsPerDay float64
+	BytesPerDay  float64
+}
+
+// Estimate returns the Projection of the runner config cfg, with the
+// average size of samples generated events.  A runner without an
+// interval writes its records once, which is its volume for the day.
+// A runner with an interval writes at most events_per_second, for the
+// day or a shorter duration.  No runner writes more than max_events.
+// The output is not created.
+func Estimate(cfg *ucfg.Config, samples int) (Projection, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return Projection{}, err
+	}
+	gc := typeConfig{}
+	if err := c.Generator.Unpack(&gc); err != nil {
+		return Projection{}, err
+	}
+	g, err := generator.New(c.Generator)
+	if err != nil {
+		return Projection{}, err
+	}
+
+	p := Projection{Generator: gc.Type, Interval: c.Interval, Records: c.Records}
+	total := 0
+	for i := 0; i < samples; i++ {
+		b, err := g.Next()
+		if err != nil {
+			return Projection{}, err
+		}
+		total += len(b)
+	}
+	if samples > 0 {
+		p.AverageBytes = float64(total) / float64(samples)
+	}
+
+	span := day
+	if c.Duration > 0 && c.Duration < span {
+		span = c.Duration
+	}
+	p.EventsPerDay = float64(c.Records)
+	if c.Interval > 0 {
+		p.EventsPerDay *= float64(span) / float64(c.Interval)
+		if limit := c.EventsPerSecond * span.Seconds(); limit > 0 && limit < p.EventsPerDay {
+			p.EventsPerDay = limit
+		}
+	}
+	if limit := float64(c.MaxEvents); limit > 0 && limit < p.EventsPerDay {
+		p.EventsPerDay = limit
+	}
+	p.BytesPerDay = p.EventsPerDay * p.AverageBytes
+	return p, nil
+}
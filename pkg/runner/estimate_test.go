@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimate(t *testing.T) {
+	tests := map[string]struct {
+		interval        interface{}
+		eventsPerSecond float64
+		maxEvents       int
+		duration        string
+		eventsPerDay    float64
+	}{
+		"Interval":         {interval: "10s", eventsPerDay: 250 * 8640},
+		"Once":             {interval: nil, eventsPerDay: 250},
+		"Limited":          {interval: "10s", eventsPerSecond: 10, eventsPerDay: 10 * 86400},
+		"MaxEvents":        {interval: "10s", maxEvents: 1000, eventsPerDay: 1000},
+		"MaxEventsOnce":    {interval: nil, maxEvents: 100, eventsPerDay: 100},
+		"MaxEventsAbove":   {interval: "10s", maxEvents: 10000000, eventsPerDay: 250 * 8640},
+		"Duration":         {interval: "10s", duration: "1h", eventsPerDay: 250 * 360},
+		"DurationLimited":  {interval: "10s", eventsPerSecond: 10, duration: "1h", eventsPerDay: 10 * 3600},
+		"DurationAboveDay": {interval: "10s", duration: "48h", eventsPerDay: 250 * 8640},
+		"DurationOnce":     {interval: nil, duration: "1h", eventsPerDay: 250},
+		"Both":             {interval: "10s", maxEvents: 50000, duration: "1h", eventsPerDay: 50000},
+	}
+	for name, tc := range tests {
+		c := map[string]interface{}{
+			"generator": map[string]interface{}{"type": "clf", "seed": 1},
+			"output":    map[string]interface{}{"type": "file", "directory": "/nonexistent", "pattern": "spigot_*.log"},
+			"records":   250,
+		}
+		if tc.interval != nil {
+			c["interval"] = tc.interval
+		}
+		if tc.eventsPerSecond > 0 {
+			c["events_per_second"] = tc.eventsPerSecond
+		}
+		if tc.maxEvents > 0 {
+			c["max_events"] = tc.maxEvents
+		}
+		if tc.duration != "" {
+			c["duration"] = tc.duration
+		}
+		cfg, err := ucfg.NewFrom(c)
+		assert.Nil(t, err, name)
+
+		p, err := Estimate(cfg, 100)
+		assert.Nil(t, err, name)
+		assert.Equal(t, "clf", p.Generator, name)
+		assert.Equal(t, 250, p.Records, name)
+		assert.InDelta(t, 85, p.AverageBytes, 10, name)
+		assert.Equal(t, tc.eventsPerDay, p.EventsPerDay, name)
+		assert.Equal(t, p.EventsPerDay*p.AverageBytes, p.BytesPerDay, name)
+	}
+
+	cfg, err := ucfg.NewFrom(map[string]interface{}{
+		"generator": map[string]interface{}{"type": "clf"},
+		"output":    map[string]interface{}{"type": "file"},
+		"interval":  "1m",
+	})
+	assert.Nil(t, err)
+	p, err := Estimate(cfg, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Minute, p.Interval)
+	assert.Equal(t, float64(1024*24*60), p.EventsPerDay)
+}
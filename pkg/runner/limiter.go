@@ -0,0 +1,100 @@
+package runner
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// maxWait is the longest a limiter sleeps at once, so the rate of a
+// ramp up is followed as it grows.
+const maxWait = 100 * time.Millisecond
+
+// limiter is a token bucket of events.  The bucket holds up to burst
+// tokens and fills at rate tokens per second, growing linearly from 0
+// over rampUp.  Every event takes one token.
+type limiter struct {
+	rate   float64
+	burst  float64
+	rampUp time.Duration
+
+	start  time.Time
+	last   time.Time
+	tokens float64
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+// newLimiter returns the limiter of eventsPerSecond, or nil for no
+// limit.  A burst of 0 is a tenth of a second of events, at least 1.
+func newLimiter(eventsPerSecond float64, burst int, rampUp time.Duration) *limiter {
+	if eventsPerSecond <= 0 {
+		return nil
+	}
+	b := float64(burst)
+	if burst == 0 {
+		b = math.Max(1, math.Ceil(eventsPerSecond/10))
+	}
+	return &limiter{
+		rate:   eventsPerSecond,
+		burst:  b,
+		rampUp: rampUp,
+		now:    time.Now,
+		sleep:  sleepContext,
+	}
+}
+
+// rateAt returns the rate at t, during the ramp up a fraction of rate.
+func (l *limiter) rateAt(t time.Time) float64 {
+	if l.rampUp <= 0 {
+		return l.rate
+	}
+	if elapsed := t.Sub(l.start); elapsed < l.rampUp {
+		return l.rate * float64(elapsed) / float64(l.rampUp)
+	}
+	return l.rate
+}
+
+// wait waits until there is a token for an event, or ctx is canceled.
+// The bucket starts full, or empty with a ramp up.
+func (l *limiter) wait(ctx context.Context) error {
+	now := l.now()
+	if l.start.IsZero() {
+		l.start, l.last = now, now
+		if l.rampUp <= 0 {
+			l.tokens = l.burst
+		}
+	}
+	for {
+		rate := l.rateAt(now)
+		l.tokens = math.Min(l.burst, l.tokens+rate*now.Sub(l.last).Seconds())
+		l.last = now
+		if l.tokens >= 1 {
+			l.tokens--
+			return nil
+		}
+		d := maxWait
+		if rate > 0 {
+			if need := time.Duration((1 - l.tokens) / rate * float64(time.Second)); need < d {
+				d = need
+			}
+		}
+		if err := l.sleep(ctx, d); err != nil {
+			return err
+		}
+		now = l.now()
+	}
+}
+
+// sleepContext sleeps for d, or until ctx is canceled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
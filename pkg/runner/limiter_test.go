@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock returns a limiter of eventsPerSecond on a clock that only
+// moves when the limiter sleeps.
+func fakeClock(eventsPerSecond float64, burst int, rampUp time.Duration) (*limiter, *time.Time) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	l := newLimiter(eventsPerSecond, burst, rampUp)
+	l.now = func() time.Time { return now }
+	l.sleep = func(ctx context.Context, d time.Duration) error {
+		now = now.Add(d)
+		return ctx.Err()
+	}
+	return l, &now
+}
+
+func TestLimiter(t *testing.T) {
+	assert.Nil(t, newLimiter(0, 0, 0))
+	assert.Equal(t, float64(10), newLimiter(100, 0, 0).burst)
+	assert.Equal(t, float64(1), newLimiter(5, 0, 0).burst)
+
+	tests := map[string]struct {
+		rate    float64
+		burst   int
+		rampUp  time.Duration
+		events  int
+		elapsed time.Duration
+	}{
+		"Burst":   {rate: 10, burst: 5, events: 5, elapsed: 0},
+		"Rate":    {rate: 10, burst: 5, events: 25, elapsed: 2 * time.Second},
+		"Ramp Up": {rate: 10, burst: 1, rampUp: 2 * time.Second, events: 11, elapsed: 2 * time.Second},
+	}
+	for name, tc := range tests {
+		l, now := fakeClock(tc.rate, tc.burst, tc.rampUp)
+		start := *now
+		for i := 0; i < tc.events; i++ {
+			assert.NoError(t, l.wait(context.Background()), name)
+		}
+		assert.InDelta(t, tc.elapsed.Seconds(), now.Sub(start).Seconds(), 0.2, name)
+	}
+}
+
+func TestLimiterCanceled(t *testing.T) {
+	l, _ := fakeClock(1, 1, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.NoError(t, l.wait(ctx))
+	cancel()
+	assert.ErrorIs(t, l.wait(ctx), context.Canceled)
+}
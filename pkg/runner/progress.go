@@ -0,0 +1,120 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Progress is how far a runner is.  Total is the number of records the
+// runner writes, 0 if only its duration is known.
+type Progress struct {
+	Events   int64
+	Bytes    int64
+	Total    int64
+	Elapsed  time.Duration
+	Duration time.Duration
+}
+
+// Finite reports whether the runner stops by itself, after one batch
+// without an interval, max_events records or its duration.
+func (r *Runner) Finite() bool {
+	return r.config.Interval == 0 || r.config.MaxEvents > 0 || r.config.Duration > 0
+}
+
+// Progress returns the Progress of the runner.  It is safe to call
+// while the runner executes.
+func (r *Runner) Progress() Progress {
+	p := Progress{
+		Events:   atomic.LoadInt64(&r.nEvents),
+		Bytes:    atomic.LoadInt64(&r.nBytes),
+		Total:    r.config.MaxEvents,
+		Duration: r.config.Duration,
+	}
+	if r.config.Interval == 0 && (p.Total == 0 || int64(r.config.Records) < p.Total) {
+		p.Total = int64(r.config.Records)
+	}
+	if started := atomic.LoadInt64(&r.started); started != 0 {
+		p.Elapsed = time.Since(time.Unix(0, started))
+	}
+	return p
+}
+
+// Rate returns the events per second.
+func (p Progress) Rate() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Events) / p.Elapsed.Seconds()
+}
+
+// ETA returns the time until the runner is done, from the rate of the
+// events so far and the duration, whichever comes first.  It returns
+// -1 if the time is not known yet.
+func (p Progress) ETA() time.Duration {
+	eta, known := time.Duration(0), false
+	if p.Total > 0 && p.Events > 0 {
+		eta, known = time.Duration(float64(p.Elapsed)*float64(p.Total-p.Events)/float64(p.Events)), true
+	}
+	if d := p.Duration - p.Elapsed; p.Duration > 0 && (!known || d < eta) {
+		eta, known = d, true
+	}
+	if !known {
+		return -1
+	}
+	if eta < 0 {
+		return 0
+	}
+	return eta
+}
+
+// String returns the progress as a line for an operator, for example
+// "1200/5000 events (24.0%), 1.2 MB, 400.0 events/s, ETA 9s".
+func (p Progress) String() string {
+	var b strings.Builder
+	if p.Total > 0 {
+		fmt.Fprintf(&b, "%d/%d events (%.1f%%)", p.Events, p.Total, 100*float64(p.Events)/float64(p.Total))
+	} else {
+		fmt.Fprintf(&b, "%d events", p.Events)
+	}
+	fmt.Fprintf(&b, ", %s, %.1f events/s", formatBytes(p.Bytes), p.Rate())
+	if eta := p.ETA(); eta >= 0 {
+		fmt.Fprintf(&b, ", ETA %s", eta.Round(time.Second))
+	} else {
+		b.WriteString(", ETA unknown")
+	}
+	return b.String()
+}
+
+// ReportProgress writes the Progress of the runner, after name, to w
+// every interval until ctx is canceled, and a last time when it is.
+func (r *Runner) ReportProgress(ctx context.Context, w io.Writer, name string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Fprintf(w, "%s: %s\n", name, r.Progress())
+			return
+		case <-ticker.C:
+			fmt.Fprintf(w, "%s: %s\n", name, r.Progress())
+		}
+	}
+}
+
+// formatBytes returns n with a decimal unit.
+func formatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,132 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress(t *testing.T) {
+	tests := map[string]struct {
+		p   Progress
+		eta time.Duration
+		s   string
+	}{
+		"Records": {
+			p:   Progress{Events: 1200, Bytes: 1234567, Total: 5000, Elapsed: 3 * time.Second},
+			eta: 9500 * time.Millisecond,
+			s:   "1200/5000 events (24.0%), 1.2 MB, 400.0 events/s, ETA 10s",
+		},
+		"Duration": {
+			p:   Progress{Events: 50, Bytes: 999, Elapsed: time.Minute, Duration: time.Hour},
+			eta: 59 * time.Minute,
+			s:   "50 events, 999 B, 0.8 events/s, ETA 59m0s",
+		},
+		"Duration First": {
+			p:   Progress{Events: 10, Total: 100, Elapsed: time.Minute, Duration: 2 * time.Minute},
+			eta: time.Minute,
+			s:   "10/100 events (10.0%), 0 B, 0.2 events/s, ETA 1m0s",
+		},
+		"Not Started": {
+			p:   Progress{Total: 100},
+			eta: -1,
+			s:   "0/100 events (0.0%), 0 B, 0.0 events/s, ETA unknown",
+		},
+	}
+	for name, tc := range tests {
+		assert.Equal(t, tc.eta, tc.p.ETA(), name)
+		assert.Equal(t, tc.s, tc.p.String(), name)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	tests := map[string]struct {
+		c      map[string]interface{}
+		finite bool
+		events int64
+	}{
+		"Once":       {c: map[string]interface{}{"records": 5}, finite: true, events: 5},
+		"Max Events": {c: map[string]interface{}{"records": 5, "interval": "1ms", "max_events": 12}, finite: true, events: 12},
+		"Duration":   {c: map[string]interface{}{"records": 1, "interval": "10ms", "duration": "100ms"}, finite: true},
+		"Forever":    {c: map[string]interface{}{"records": 1, "interval": "1s"}, finite: false},
+	}
+	for name, tc := range tests {
+		tc.c["generator"] = map[string]interface{}{"type": "clf"}
+		tc.c["output"] = map[string]interface{}{"type": "file", "directory": t.TempDir(), "pattern": "spigot_*.log"}
+		cfg, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		r, err := New(cfg)
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.finite, r.Finite(), name)
+		if !tc.finite {
+			continue
+		}
+
+		assert.Nil(t, r.Execute(), name)
+		p := r.Progress()
+		if tc.events > 0 {
+			assert.Equal(t, tc.events, p.Events, name)
+			assert.Equal(t, tc.events, p.Total, name)
+		} else {
+			assert.Greater(t, p.Events, int64(5), name)
+			assert.GreaterOrEqual(t, p.Elapsed, 100*time.Millisecond, name)
+		}
+		assert.Equal(t, time.Duration(0), p.ETA(), name)
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := ucfg.NewFrom(map[string]interface{}{
+		"generator": map[string]interface{}{
+			"type": "clf",
+			"timestamp": map[string]interface{}{
+				"start":    "2024-03-01T00:00:00Z",
+				"end":      "2024-03-01T00:10:00Z",
+				"interval": "1m",
+			},
+		},
+		"output":   map[string]interface{}{"type": "file", "filename": filepath.Join(dir, "spigot.log")},
+		"records":  4,
+		"interval": "1ms",
+	})
+	assert.Nil(t, err)
+	r, err := New(cfg)
+	assert.Nil(t, err)
+	assert.True(t, r.Finite())
+
+	assert.Nil(t, r.Execute())
+	assert.Equal(t, int64(11), r.Progress().Events)
+	b, err := os.ReadFile(filepath.Join(dir, "spigot.log"))
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	assert.Len(t, lines, 11)
+	assert.Contains(t, lines[0], "[01/Mar/2024:00:00:00 +0000]")
+	assert.Contains(t, lines[10], "[01/Mar/2024:00:10:00 +0000]")
+}
+
+func TestLimitsConfig(t *testing.T) {
+	for _, tc := range []struct {
+		c           map[string]interface{}
+		errorString string
+	}{
+		{map[string]interface{}{"max_events": -1}, "'-1' is not a valid value for 'max_events' expected 0 or more accessing config"},
+		{map[string]interface{}{"duration": "-1s"}, "'-1s' is not a valid value for 'duration' expected 0 or more accessing config"},
+		{map[string]interface{}{"events_per_second": -1}, "'-1' is not a valid value for 'events_per_second' expected 0 or more accessing config"},
+		{map[string]interface{}{"events_per_second": 10, "burst": -1}, "'-1' is not a valid value for 'burst' expected 0 or more accessing config"},
+	} {
+		tc.c["generator"] = map[string]interface{}{"type": "clf"}
+		tc.c["output"] = map[string]interface{}{"type": "file"}
+		cfg, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err)
+		_, err = New(cfg)
+		if assert.Error(t, err) {
+			assert.Equal(t, tc.errorString, err.Error())
+		}
+	}
+}
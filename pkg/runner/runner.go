@@ -5,6 +5,10 @@
 //	"generator" and "output" are required, and are the configs of the
 //	specific types.
 //
+//	"name" is optional, and names the runner, a pipeline of a
+//	generator and an output, in messages and telemetry.  The names of
+//	the runners of a config must be unique, see Names.
+//
 //	"records" is optional, default is 1024.  This is the number of log
 //	records to write per interval.
 //
@@ -12,6 +16,12 @@
 //	given then the runner is executed once.  If an interval is given
 //	then at each interval the runner is executed.
 //
+//	"max_events" is optional, the total number of records to write.
+//	The runner stops after writing them, in the last interval.
+//
+//	"duration" is optional and is a go duration.  The runner stops
+//	when it has run for the duration.
+//
 //	Example:
 //
 //	  generator:
@@ -26,29 +36,107 @@
 //
 //	This would write 2 vpcflow log entries to a file in the
 //	/var/tmp/spigot_asa_<random>.log file every 5 seconds.
+//
+//	The events of generators of binary events, such as NetFlow, are
+//	written with the WriteBinary of the output, see
+//	output.BinaryOutput.  Outputs without it can not be used with
+//	them.
+//
+//	"events_per_second" is optional and limits the rate of the
+//	records with a token bucket, which holds "burst" records, by
+//	default a tenth of a second of them.  "ramp_up" is optional and
+//	is a go duration, over which the rate grows from 0 to
+//	events_per_second.  Without events_per_second the records are
+//	written as fast as the output takes them.
+//
+//	  events_per_second: 500
+//	  burst: 50
+//	  ramp_up: 1m
+//
+//	"labels" is optional, a map of strings.  The labels are added to
+//	the spans and metrics of the runner, see package telemetry.
+//
+//	A "seed" in the generator config makes the generator produce the
+//	same records on every run.  See SetSeed for seeding all runners.
+//
+//	A "timestamp" in the generator config backfills a time range, the
+//	runner stops after the event at its end.  Its scenario backfills
+//	the range around a DST change, new year or a leap second instead.
+//	See generator.Clock.
+//
+//	A "corrupt" in the generator config corrupts a percentage of the
+//	records, to load test the handling of records a parser fails on.
+//	See generator.New.
+//
+//	A "stress" in the generator config adds very long fields, many
+//	keys or deep nesting to JSON records.  See generator.New.
+//
+//	A "payload" in the generator config adds text of a tuned entropy
+//	to the records, to size storage and network compression.  See
+//	generator.New.
+//
+//	String values of the output config can be references to secrets,
+//	"secret:<provider>:<name>", which are fetched when the runner is
+//	created.  See package secrets.
 package runner
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/elastic/go-ucfg"
 	"github.com/leehinman/spigot/pkg/generator"
 	_ "github.com/leehinman/spigot/pkg/include"
 	"github.com/leehinman/spigot/pkg/output"
+	"github.com/leehinman/spigot/pkg/secrets"
+	"github.com/leehinman/spigot/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Runner holds the config, output and generator.
 type Runner struct {
+	// The counters of Progress, updated with sync/atomic.  They come
+	// first to be 64-bit aligned on 32-bit platforms.
+	started int64
+	nEvents int64
+	nBytes  int64
+
 	config    config
 	generator generator.Generator
 	output    output.Output
+	// write is the Write of the output, or its WriteBinary for a
+	// generator of binary events.
+	write func([]byte) (int, error)
+
+	limiter *limiter
+
+	attrs []attribute.KeyValue
+	instruments
 }
 
 type config struct {
-	Generator *ucfg.Config  `config:"generator" validate:"required"`
-	Output    *ucfg.Config  `config:"output" validate:"required"`
-	Interval  time.Duration `config:"interval"`
-	Records   int           `config:"records"`
+	Name      string            `config:"name"`
+	Generator *ucfg.Config      `config:"generator" validate:"required"`
+	Output    *ucfg.Config      `config:"output" validate:"required"`
+	Interval  time.Duration     `config:"interval"`
+	Records   int               `config:"records"`
+	MaxEvents int64             `config:"max_events"`
+	Duration  time.Duration     `config:"duration"`
+	Labels    map[string]string `config:"labels"`
+
+	EventsPerSecond float64       `config:"events_per_second"`
+	Burst           int           `config:"burst"`
+	RampUp          time.Duration `config:"ramp_up"`
+}
+
+type typeConfig struct {
+	Type string `config:"type"`
 }
 
 func defaultConfig() config {
@@ -58,6 +146,28 @@ func defaultConfig() config {
 	return c
 }
 
+func (c *config) Validate() error {
+	if c.MaxEvents < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'max_events' expected 0 or more", c.MaxEvents)
+	}
+	if c.Duration < 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'duration' expected 0 or more", c.Duration)
+	}
+	if c.EventsPerSecond < 0 {
+		return fmt.Errorf("'%g' is not a valid value for 'events_per_second' expected 0 or more", c.EventsPerSecond)
+	}
+	if c.Burst < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'burst' expected 0 or more", c.Burst)
+	}
+	if c.RampUp < 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'ramp_up' expected 0 or more", c.RampUp)
+	}
+	if c.EventsPerSecond == 0 && (c.Burst > 0 || c.RampUp > 0) {
+		return fmt.Errorf("'burst' and 'ramp_up' can only be used with 'events_per_second'")
+	}
+	return nil
+}
+
 // New is Factory for creating a new runner
 func New(cfg *ucfg.Config) (Runner, error) {
 	r := Runner{}
@@ -68,7 +178,11 @@ func New(cfg *ucfg.Config) (Runner, error) {
 	}
 
 	r.config = c
+	r.limiter = newLimiter(c.EventsPerSecond, c.Burst, c.RampUp)
 
+	if err := secrets.Resolve(context.Background(), c.Output); err != nil {
+		return r, err
+	}
 	o, err := output.New(c.Output)
 	if err != nil {
 		return r, err
@@ -82,33 +196,245 @@ func New(cfg *ucfg.Config) (Runner, error) {
 	}
 	r.generator = g
 
+	// A generator backfilling a time range is done at its end.
+	clock, err := generator.NewClock(c.Generator)
+	if err != nil {
+		return r, err
+	}
+	if n := clock.Events(); n > 0 && (r.config.MaxEvents == 0 || n < r.config.MaxEvents) {
+		r.config.MaxEvents = n
+	}
+
+	gc, oc := typeConfig{}, typeConfig{}
+	if err := c.Generator.Unpack(&gc); err != nil {
+		return r, err
+	}
+	if err := c.Output.Unpack(&oc); err != nil {
+		return r, err
+	}
+
+	r.write = o.Write
+	if generator.IsBinary(g) {
+		b, ok := o.(output.BinaryOutput)
+		if !ok {
+			return r, fmt.Errorf("'%s' output can not write the binary events of '%s'", oc.Type, gc.Type)
+		}
+		r.write = b.WriteBinary
+	}
+	r.attrs = append(telemetry.Attributes(c.Labels),
+		attribute.String("spigot.generator", gc.Type),
+		attribute.String("spigot.output", oc.Type),
+	)
+	if c.Name != "" {
+		r.attrs = append(r.attrs, attribute.String("spigot.runner", c.Name))
+	}
+
+	r.instruments, err = newInstruments()
+	if err != nil {
+		return r, err
+	}
+
 	return r, nil
 }
 
+// Lint returns the issues generator.Lint finds in the templates of
+// the generator, without generating events.
+func (r *Runner) Lint() ([]generator.Issue, error) {
+	return generator.Lint(r.generator, 0)
+}
+
+// Validate checks the runner config cfg and returns the issues
+// generator.Lint finds in the templates of its generator, with samples
+// generated events.  The output is not created.
+func Validate(cfg *ucfg.Config, samples int) ([]generator.Issue, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	g, err := generator.New(c.Generator)
+	if err != nil {
+		return nil, err
+	}
+	return generator.Lint(g, samples)
+}
+
+// Names returns the names of the runner configs cfgs, their "name",
+// or "runner <n>" for the n-th runner without one.  It returns an
+// error if two runners have the same name.
+func Names(cfgs []*ucfg.Config) ([]string, error) {
+	names := make([]string, len(cfgs))
+	seen := make(map[string]int, len(cfgs))
+	for i, cfg := range cfgs {
+		c := struct {
+			Name string `config:"name"`
+		}{}
+		if err := cfg.Unpack(&c); err != nil {
+			return nil, err
+		}
+		names[i] = c.Name
+		if names[i] == "" {
+			names[i] = fmt.Sprintf("runner %d", i)
+		}
+		if j, ok := seen[names[i]]; ok {
+			return nil, fmt.Errorf("runners %d and %d have the same name '%s'", j, i, names[i])
+		}
+		seen[names[i]] = i
+	}
+	return names, nil
+}
+
+// SetSeed sets the "seed" of the generator of each runner config in
+// cfgs that does not have a seed of its own.  The generator of the
+// n-th runner is seeded with seed+n, so that two runners with the same
+// generator do not write the same records.
+func SetSeed(cfgs []*ucfg.Config, seed int64) error {
+	for i, cfg := range cfgs {
+		ok, err := cfg.Has("generator.seed", -1, ucfg.PathSep("."))
+		if err != nil {
+			return err
+		}
+		if ok {
+			continue
+		}
+		if err := cfg.SetInt("generator.seed", -1, seed+int64(i), ucfg.PathSep(".")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetLabels adds labels to the "labels" of each runner config in cfgs.
+// Labels a runner already has are kept.
+func SetLabels(cfgs []*ucfg.Config, labels map[string]string) error {
+	for _, cfg := range cfgs {
+		ok, err := cfg.Has("labels", -1)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if err := cfg.SetChild("labels", -1, ucfg.New()); err != nil {
+				return err
+			}
+		}
+		l, err := cfg.Child("labels", -1)
+		if err != nil {
+			return err
+		}
+		for k, v := range labels {
+			ok, err := l.Has(k, -1)
+			if err != nil {
+				return err
+			}
+			if ok {
+				continue
+			}
+			if err := l.SetString(k, -1, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // Execute runs the runner
 func (r *Runner) Execute() error {
+	return r.ExecuteContext(context.Background())
+}
+
+// ExecuteContext runs the runner until it is done or ctx is canceled.
+// When ctx is canceled the output is closed and the error of ctx is
+// returned.  The runner is done after one batch without an interval,
+// after max_events records or when it has run for the duration.
+func (r *Runner) ExecuteContext(ctx context.Context) error {
+	atomic.StoreInt64(&r.started, time.Now().UnixNano())
+
+	parent := ctx
+	if r.config.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.config.Duration)
+		defer cancel()
+	}
+
 	var ticker *time.Ticker = nil
 	if r.config.Interval > 0 {
 		ticker = time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
 	}
 
-	for ; true; <-ticker.C {
-		for i := 0; i < r.config.Records; i++ {
-			b, err := r.generator.Next()
-			if err != nil {
-				return err
-			}
-			_, err = r.output.Write(b)
-			if err != nil {
-				return err
+	for {
+		if err := r.batch(ctx); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && parent.Err() == nil {
+				// the batch closed the output
+				return nil
 			}
+			return err
 		}
-		if r.config.Interval == 0 {
+		if r.config.Interval == 0 || r.maxed() {
 			break
 		}
 		if err := r.output.NewInterval(); err != nil {
 			return err
 		}
+		select {
+		case <-ctx.Done():
+			_ = r.output.Close()
+			if parent.Err() == nil {
+				return nil
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
 	}
 	return r.output.Close()
 }
+
+// maxed reports whether the runner wrote max_events records.
+func (r *Runner) maxed() bool {
+	return r.config.MaxEvents > 0 && atomic.LoadInt64(&r.nEvents) >= r.config.MaxEvents
+}
+
+// batch writes one batch of records, in a span.
+func (r *Runner) batch(ctx context.Context) error {
+	ctx, span := r.tracer.Start(ctx, "batch", trace.WithAttributes(r.attrs...))
+	defer span.End()
+
+	opt := metric.WithAttributes(r.attrs...)
+	records := 0
+	for ; records < r.config.Records && !r.maxed(); records++ {
+		if err := ctx.Err(); err != nil {
+			_ = r.output.Close()
+			return err
+		}
+		if r.limiter != nil {
+			if err := r.limiter.wait(ctx); err != nil {
+				_ = r.output.Close()
+				return err
+			}
+		}
+		b, err := r.generator.Next()
+		if err != nil {
+			return r.fail(ctx, span, err)
+		}
+		start := time.Now()
+		_, err = r.write(b)
+		r.writeDuration.Record(ctx, time.Since(start).Seconds(), opt)
+		if err != nil {
+			return r.fail(ctx, span, err)
+		}
+		r.records.Add(ctx, 1, opt)
+		r.bytes.Add(ctx, int64(len(b)), opt)
+		atomic.AddInt64(&r.nEvents, 1)
+		atomic.AddInt64(&r.nBytes, int64(len(b)))
+	}
+	span.SetAttributes(attribute.Int("spigot.records", records))
+
+	return nil
+}
+
+// fail records err on span and the error counter and returns it.
+func (r *Runner) fail(ctx context.Context, span trace.Span, err error) error {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	r.errors.Add(ctx, 1, metric.WithAttributes(r.attrs...))
+	return err
+}
@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNames(t *testing.T) {
+	tests := map[string]struct {
+		names []interface{}
+		want  []string
+		err   bool
+	}{
+		"Unnamed":   {names: []interface{}{nil, nil}, want: []string{"runner 0", "runner 1"}},
+		"Named":     {names: []interface{}{"firewall", nil, "netscaler"}, want: []string{"firewall", "runner 1", "netscaler"}},
+		"Duplicate": {names: []interface{}{"firewall", "firewall"}, err: true},
+	}
+	for name, tc := range tests {
+		var cfgs []*ucfg.Config
+		for _, n := range tc.names {
+			c := map[string]interface{}{"generator": map[string]interface{}{"type": "clf"}}
+			if n != nil {
+				c["name"] = n
+			}
+			cfg, err := ucfg.NewFrom(c)
+			assert.Nil(t, err, name)
+			cfgs = append(cfgs, cfg)
+		}
+		names, err := Names(cfgs)
+		if tc.err {
+			assert.Error(t, err, name)
+			continue
+		}
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.want, names, name)
+	}
+}
@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer and meter of the
+// runners.
+const instrumentationName = "github.com/leehinman/spigot/pkg/runner"
+
+// instruments holds the tracer and metric instruments of a runner.
+// They come from the global providers, which do nothing unless
+// telemetry is started.
+type instruments struct {
+	tracer        trace.Tracer
+	records       metric.Int64Counter
+	bytes         metric.Int64Counter
+	errors        metric.Int64Counter
+	writeDuration metric.Float64Histogram
+}
+
+func newInstruments() (instruments, error) {
+	var err error
+	i := instruments{tracer: otel.Tracer(instrumentationName)}
+	meter := otel.Meter(instrumentationName)
+
+	i.records, err = meter.Int64Counter("spigot.records",
+		metric.WithDescription("Number of records written to the output."),
+		metric.WithUnit("{record}"))
+	if err != nil {
+		return i, err
+	}
+	i.bytes, err = meter.Int64Counter("spigot.bytes",
+		metric.WithDescription("Number of bytes written to the output."),
+		metric.WithUnit("By"))
+	if err != nil {
+		return i, err
+	}
+	i.errors, err = meter.Int64Counter("spigot.errors",
+		metric.WithDescription("Number of generator and output errors."),
+		metric.WithUnit("{error}"))
+	if err != nil {
+		return i, err
+	}
+	i.writeDuration, err = meter.Float64Histogram("spigot.output.write.duration",
+		metric.WithDescription("Latency of writing a record to the output."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return i, err
+	}
+
+	return i, nil
+}
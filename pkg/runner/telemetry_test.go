@@ -0,0 +1,89 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTelemetry(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	cfg, err := ucfg.NewFrom(map[string]interface{}{
+		"generator": map[string]interface{}{"type": "clf"},
+		"output": map[string]interface{}{
+			"type":      "file",
+			"directory": t.TempDir(),
+			"pattern":   "spigot_*.log",
+			"delimiter": "\n",
+		},
+		"records": 5,
+		"labels":  map[string]interface{}{"test": "soak"},
+	})
+	assert.Nil(t, err)
+	r, err := New(cfg)
+	assert.Nil(t, err)
+	assert.Nil(t, r.Execute())
+
+	attrs := attribute.NewSet(
+		attribute.String("spigot.generator", "clf"),
+		attribute.String("spigot.output", "file"),
+		attribute.String("test", "soak"),
+	)
+
+	ended := spans.Ended()
+	if assert.Len(t, ended, 1) {
+		assert.Equal(t, "batch", ended[0].Name())
+		for _, kv := range attrs.ToSlice() {
+			assert.Contains(t, ended[0].Attributes(), kv)
+		}
+		assert.Contains(t, ended[0].Attributes(), attribute.Int("spigot.records", 5))
+	}
+
+	var rm metricdata.ResourceMetrics
+	assert.Nil(t, reader.Collect(context.Background(), &rm))
+	got := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			got[m.Name] = m.Data
+		}
+	}
+	if records, ok := got["spigot.records"].(metricdata.Sum[int64]); assert.True(t, ok) {
+		assert.Equal(t, int64(5), records.DataPoints[0].Value)
+		assert.Equal(t, attrs, records.DataPoints[0].Attributes)
+	}
+	if latency, ok := got["spigot.output.write.duration"].(metricdata.Histogram[float64]); assert.True(t, ok) {
+		assert.Equal(t, uint64(5), latency.DataPoints[0].Count)
+	}
+	assert.NotContains(t, got, "spigot.errors")
+}
+
+func TestSetLabels(t *testing.T) {
+	cfgs := []*ucfg.Config{
+		ucfg.MustNewFrom(map[string]interface{}{}),
+		ucfg.MustNewFrom(map[string]interface{}{"labels": map[string]interface{}{"team": "b"}}),
+	}
+	assert.Nil(t, SetLabels(cfgs, map[string]string{"team": "a", "test.id": "42"}))
+
+	for i, want := range []map[string]string{
+		{"team": "a", "test.id": "42"},
+		{"team": "b", "test.id": "42"},
+	} {
+		c := struct {
+			Labels map[string]string `config:"labels"`
+		}{}
+		assert.Nil(t, cfgs[i].Unpack(&c))
+		assert.Equal(t, want, c.Labels)
+	}
+}
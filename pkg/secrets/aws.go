@@ -0,0 +1,46 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+func init() {
+	_ = Register("aws", &awsSecretsManager{})
+}
+
+// awsSecretsManager reads secrets from AWS Secrets Manager.
+type awsSecretsManager struct {
+	once   sync.Once
+	client *secretsmanager.Client
+	err    error
+}
+
+// Get returns the string value of the secret name.
+func (a *awsSecretsManager) Get(ctx context.Context, name string) (string, error) {
+	a.once.Do(func() {
+		cfg, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			a.err = err
+			return
+		}
+		a.client = secretsmanager.NewFromConfig(cfg)
+	})
+	if a.err != nil {
+		return "", a.err
+	}
+
+	out, err := a.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+	if err != nil {
+		return "", err
+	}
+	if out.SecretString == nil {
+		return "", fmt.Errorf("secret '%s' is not a string", name)
+	}
+	return *out.SecretString, nil
+}
@@ -0,0 +1,80 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+const gcpMetadataToken = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+func init() {
+	_ = Register("gcp", &gcpSecretManager{
+		client:   &http.Client{Timeout: 30 * time.Second},
+		endpoint: "https://secretmanager.googleapis.com",
+	})
+}
+
+// gcpSecretManager reads secrets from GCP Secret Manager with its REST
+// API.
+type gcpSecretManager struct {
+	client   *http.Client
+	endpoint string
+}
+
+// Get returns the payload of the secret version name.
+func (g *gcpSecretManager) Get(ctx context.Context, name string) (string, error) {
+	token, err := g.token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var body struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := g.get(ctx, g.endpoint+"/v1/"+name+":access", map[string]string{"Authorization": "Bearer " + token}, &body); err != nil {
+		return "", fmt.Errorf("%s for secret '%s'", err, name)
+	}
+	b, err := base64.StdEncoding.DecodeString(body.Payload.Data)
+	return string(b), err
+}
+
+// token returns the OAuth access token from GOOGLE_OAUTH_ACCESS_TOKEN
+// or the metadata server.
+func (g *gcpSecretManager) token(ctx context.Context) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+	var body struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := g.get(ctx, gcpMetadataToken, map[string]string{"Metadata-Flavor": "Google"}, &body); err != nil {
+		return "", fmt.Errorf("%s for the access token", err)
+	}
+	return body.AccessToken, nil
+}
+
+func (g *gcpSecretManager) get(ctx context.Context, url string, headers map[string]string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gcp returned %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,138 @@
+// Package secrets resolves references to secrets in the configuration
+// of outputs, so credentials like API keys and tokens do not have to
+// be written in plain text in the configuration file.
+//
+// A reference is a string value of the form
+//
+//	secret:<provider>:<name>[#<key>]
+//
+// It is replaced by the secret, fetched from the provider when the
+// runner is created.  With a key the secret is a JSON object and the
+// value of key is used.  Every reference is fetched only once.
+//
+// Providers:
+//
+//	vault: A HashiCorp Vault KV secret, name is the API path, for
+//	       example "secret/data/spigot", and key is required.  The
+//	       address and token are read from VAULT_ADDR and VAULT_TOKEN,
+//	       and the namespace from VAULT_NAMESPACE.
+//	aws:   An AWS Secrets Manager secret, name is the name or ARN of
+//	       the secret.  Credentials and region are read from the
+//	       environment or the shared AWS configuration.
+//	gcp:   A GCP Secret Manager secret version, name is the resource
+//	       name, for example
+//	       "projects/acme/secrets/hec-token/versions/latest".  The
+//	       access token is read from GOOGLE_OAUTH_ACCESS_TOKEN, or from
+//	       the metadata server on GCP.
+//
+// Example:
+//
+//	output:
+//	  type: http
+//	  url: "https://hec.example.com:8088/services/collector/raw"
+//	  headers:
+//	    Authorization: "secret:vault:secret/data/spigot#hec_token"
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/elastic/go-ucfg"
+)
+
+// Prefix is the prefix of references to secrets.
+const Prefix = "secret:"
+
+// Provider fetches secrets by name.
+type Provider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+var (
+	registry = make(map[string]Provider)
+
+	mu    sync.Mutex
+	cache = make(map[string]string)
+)
+
+// Register associates a provider name with the provider.
+func Register(name string, p Provider) error {
+	if _, exists := registry[name]; exists {
+		return fmt.Errorf("Error registering secret provider '%s': already registered", name)
+	}
+	registry[name] = p
+	return nil
+}
+
+// Resolve replaces all references to secrets in the string values of
+// cfg with the secrets.
+func Resolve(ctx context.Context, cfg *ucfg.Config) error {
+	opts := []ucfg.Option{ucfg.PathSep(".")}
+	for _, k := range cfg.FlattenedKeys(opts...) {
+		v, err := cfg.String(k, -1, opts...)
+		if err != nil || !strings.HasPrefix(v, Prefix) {
+			continue
+		}
+		s, err := Lookup(ctx, v)
+		if err != nil {
+			return fmt.Errorf("%s accessing '%s'", err, k)
+		}
+		if err := cfg.SetString(k, -1, s, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lookup returns the secret of the reference ref.
+func Lookup(ctx context.Context, ref string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if s, ok := cache[ref]; ok {
+		return s, nil
+	}
+
+	provider, name, ok := strings.Cut(strings.TrimPrefix(ref, Prefix), ":")
+	if !ok || name == "" {
+		return "", fmt.Errorf("'%s' is not a valid secret reference expected '%s<provider>:<name>'", ref, Prefix)
+	}
+	p, exists := registry[provider]
+	if !exists {
+		return "", fmt.Errorf("Secret provider %s not registered", provider)
+	}
+
+	name, key, _ := strings.Cut(name, "#")
+	s, err := p.Get(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if key != "" {
+		if s, err = field(s, key); err != nil {
+			return "", fmt.Errorf("%s in secret '%s'", err, name)
+		}
+	}
+
+	cache[ref] = s
+	return s, nil
+}
+
+// field returns the value of key of the JSON object s.
+func field(s, key string) (string, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		return "", fmt.Errorf("key '%s' can not be used with a value that is not a JSON object", key)
+	}
+	v, ok := m[key]
+	if !ok {
+		return "", fmt.Errorf("key '%s' not found", key)
+	}
+	if str, ok := v.(string); ok {
+		return str, nil
+	}
+	return fmt.Sprint(v), nil
+}
@@ -0,0 +1,91 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+type staticProvider struct {
+	secrets map[string]string
+	calls   int
+}
+
+func (s *staticProvider) Get(ctx context.Context, name string) (string, error) {
+	s.calls++
+	v, ok := s.secrets[name]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return v, nil
+}
+
+func TestResolve(t *testing.T) {
+	p := &staticProvider{secrets: map[string]string{
+		"token": "s3cr3t",
+		"hec":   `{"token": "hec-token", "port": 8088}`,
+	}}
+	assert.Nil(t, Register("static", p))
+
+	tests := map[string]struct {
+		config   map[string]interface{}
+		expected map[string]interface{}
+		hasErr   bool
+		errStr   string
+	}{
+		"plain": {
+			config:   map[string]interface{}{"type": "http", "url": "http://localhost"},
+			expected: map[string]interface{}{"type": "http", "url": "http://localhost"},
+		},
+		"secret": {
+			config:   map[string]interface{}{"type": "http", "drain_token": "secret:static:token"},
+			expected: map[string]interface{}{"type": "http", "drain_token": "s3cr3t"},
+		},
+		"nested key": {
+			config:   map[string]interface{}{"headers": map[string]interface{}{"Authorization": "secret:static:hec#token"}, "port": "secret:static:hec#port"},
+			expected: map[string]interface{}{"headers": map[string]interface{}{"Authorization": "hec-token"}, "port": "8088"},
+		},
+		"missing key": {
+			config: map[string]interface{}{"token": "secret:static:hec#password"},
+			hasErr: true,
+			errStr: "key 'password' not found in secret 'hec' accessing 'token'",
+		},
+		"not an object": {
+			config: map[string]interface{}{"token": "secret:static:token#password"},
+			hasErr: true,
+			errStr: "key 'password' can not be used with a value that is not a JSON object in secret 'token' accessing 'token'",
+		},
+		"unknown provider": {
+			config: map[string]interface{}{"token": "secret:keyring:token"},
+			hasErr: true,
+			errStr: "Secret provider keyring not registered accessing 'token'",
+		},
+		"invalid reference": {
+			config: map[string]interface{}{"token": "secret:static"},
+			hasErr: true,
+			errStr: "'secret:static' is not a valid secret reference expected 'secret:<provider>:<name>' accessing 'token'",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := ucfg.MustNewFrom(tc.config, ucfg.PathSep("."))
+			err := Resolve(context.Background(), cfg)
+			if tc.hasErr {
+				assert.EqualError(t, err, tc.errStr)
+				return
+			}
+			assert.Nil(t, err)
+			got := map[string]interface{}{}
+			assert.Nil(t, cfg.Unpack(&got))
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+
+	calls := p.calls
+	_, err := Lookup(context.Background(), "secret:static:token")
+	assert.Nil(t, err)
+	assert.Equal(t, calls, p.calls, "secrets are fetched once")
+}
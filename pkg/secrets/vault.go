@@ -0,0 +1,63 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+func init() {
+	_ = Register("vault", &vault{client: &http.Client{Timeout: 30 * time.Second}})
+}
+
+// vault reads secrets from the KV secrets engine of HashiCorp Vault.
+type vault struct {
+	client *http.Client
+}
+
+// Get returns the data of the KV secret at path name as a JSON object.
+// Both version 1 and version 2 of the KV engine are supported.
+func (v *vault) Get(ctx context.Context, name string) (string, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is required for secret '%s'", name)
+	}
+	url := strings.TrimSuffix(addr, "/") + "/v1/" + strings.TrimPrefix(name, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s for secret '%s'", resp.Status, name)
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	// KV version 2 nests the secret in data.data
+	if data, ok := body.Data["data"]; ok {
+		if _, ok := body.Data["metadata"]; ok {
+			return string(data), nil
+		}
+	}
+	b, err := json.Marshal(body.Data)
+	return string(b), err
+}
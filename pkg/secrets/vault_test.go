@@ -0,0 +1,51 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/spigot":
+			_, _ = w.Write([]byte(`{"data": {"data": {"api_key": "kv2-key"}, "metadata": {"version": 3}}}`))
+		case "/v1/kv/spigot":
+			_, _ = w.Write([]byte(`{"data": {"api_key": "kv1-key"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+
+	tests := map[string]struct {
+		ref      string
+		expected string
+		hasErr   bool
+	}{
+		"kv2":       {ref: "secret:vault:secret/data/spigot#api_key", expected: "kv2-key"},
+		"kv1":       {ref: "secret:vault:kv/spigot#api_key", expected: "kv1-key"},
+		"not found": {ref: "secret:vault:kv/missing#api_key", hasErr: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Lookup(context.Background(), tc.ref)
+			if tc.hasErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
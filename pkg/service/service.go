@@ -0,0 +1,364 @@
+// Package service runs spigot as a long running service.  Scenarios
+// are submitted over a REST API, queued and executed by a fixed
+// number of workers.  With one worker scenarios run one after the
+// other, with more they run in parallel.
+//
+// A scenario is a name, an optional seed, optional labels and a list
+// of runners, in the same format as the configuration file, as YAML or
+// JSON.  The labels are added to the labels of every runner.
+//
+//	name: "team-a load test"
+//	seed: 42
+//	labels:
+//	  team: "a"
+//	runners:
+//	  - generator:
+//	      type: "cisco:asa"
+//	    output:
+//	      type: file
+//	      directory: "/var/tmp"
+//	      pattern: "spigot_asa_*.log"
+//	      delimiter: "\n"
+//	    records: 250
+//
+// Each scenario has its own generators and outputs, created when the
+// scenario starts, and a scenario that fails or is canceled does not
+// affect the others.  All runners of a scenario are canceled when one
+// of them fails.
+//
+// The API is:
+//
+//	POST   /scenarios       Submit a scenario, returns 202 and the scenario.
+//	GET    /scenarios       List the scenarios.
+//	GET    /scenarios/<id>  Get a scenario.
+//	DELETE /scenarios/<id>  Cancel a queued or running scenario.
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/elastic/go-ucfg/yaml"
+	"github.com/leehinman/spigot/pkg/runner"
+)
+
+// States of a scenario.
+const (
+	StateQueued   = "queued"
+	StateRunning  = "running"
+	StateDone     = "done"
+	StateFailed   = "failed"
+	StateCanceled = "canceled"
+)
+
+// maxScenarioSize is the largest scenario body that is accepted.
+const maxScenarioSize = 1 << 20
+
+var errQueueFull = errors.New("scenario queue is full")
+
+type scenarioConfig struct {
+	Name    string            `config:"name"`
+	Seed    *int64            `config:"seed"`
+	Labels  map[string]string `config:"labels"`
+	Runners []*ucfg.Config    `config:"runners" validate:"required"`
+}
+
+// Scenario is the status of a submitted scenario.
+type Scenario struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name,omitempty"`
+	State     string     `json:"state"`
+	Error     string     `json:"error,omitempty"`
+	Submitted time.Time  `json:"submitted"`
+	Started   *time.Time `json:"started,omitempty"`
+	Finished  *time.Time `json:"finished,omitempty"`
+
+	runners []*ucfg.Config
+	cancel  context.CancelFunc
+}
+
+// Service holds the scenario queue and the workers executing it.
+type Service struct {
+	mu        sync.Mutex
+	nextID    int
+	scenarios map[int]*Scenario
+	queue     chan *Scenario
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// New returns a Service with workers workers and room for queueSize
+// queued scenarios.  The workers are started by Start.
+func New(workers, queueSize int) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Service{
+		nextID:    1,
+		scenarios: make(map[int]*Scenario),
+		queue:     make(chan *Scenario, queueSize),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	s.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go s.worker()
+	}
+	return s
+}
+
+// Close cancels all scenarios and waits for the workers to exit.
+func (s *Service) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Submit parses and queues a scenario.
+func (s *Service) Submit(body []byte) (Scenario, error) {
+	cfg, err := yaml.NewConfig(body, ucfg.PathSep("."))
+	if err != nil {
+		return Scenario{}, err
+	}
+	c := scenarioConfig{}
+	if err := cfg.Unpack(&c); err != nil {
+		return Scenario{}, err
+	}
+	if c.Seed != nil {
+		if err := runner.SetSeed(c.Runners, *c.Seed); err != nil {
+			return Scenario{}, err
+		}
+	}
+	if err := runner.SetLabels(c.Runners, c.Labels); err != nil {
+		return Scenario{}, err
+	}
+	if _, err := runner.Names(c.Runners); err != nil {
+		return Scenario{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sc := &Scenario{
+		ID:        s.nextID,
+		Name:      c.Name,
+		State:     StateQueued,
+		Submitted: time.Now().UTC(),
+		runners:   c.Runners,
+	}
+	select {
+	case s.queue <- sc:
+	default:
+		return Scenario{}, errQueueFull
+	}
+	s.nextID++
+	s.scenarios[sc.ID] = sc
+
+	return *sc, nil
+}
+
+// Get returns the scenario with id.
+func (s *Service) Get(id int) (Scenario, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sc, ok := s.scenarios[id]
+	if !ok {
+		return Scenario{}, false
+	}
+	return *sc, true
+}
+
+// List returns all scenarios ordered by id.
+func (s *Service) List() []Scenario {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := make([]Scenario, 0, len(s.scenarios))
+	for _, sc := range s.scenarios {
+		l = append(l, *sc)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].ID < l[j].ID })
+	return l
+}
+
+// Cancel cancels the scenario with id.  A queued scenario is skipped
+// by the workers, a running scenario is stopped.
+func (s *Service) Cancel(id int) (Scenario, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sc, ok := s.scenarios[id]
+	if !ok {
+		return Scenario{}, false
+	}
+	switch sc.State {
+	case StateQueued:
+		s.finish(sc, StateCanceled, nil)
+	case StateRunning:
+		sc.cancel()
+	}
+	return *sc, true
+}
+
+func (s *Service) worker() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case sc := <-s.queue:
+			s.run(sc)
+		}
+	}
+}
+
+func (s *Service) run(sc *Scenario) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	s.mu.Lock()
+	if sc.State != StateQueued {
+		s.mu.Unlock()
+		return
+	}
+	now := time.Now().UTC()
+	sc.State = StateRunning
+	sc.Started = &now
+	sc.cancel = cancel
+	s.mu.Unlock()
+
+	var runners []runner.Runner
+	for _, rCfg := range sc.runners {
+		r, err := runner.New(rCfg)
+		if err != nil {
+			s.mu.Lock()
+			s.finish(sc, StateFailed, err)
+			s.mu.Unlock()
+			return
+		}
+		runners = append(runners, r)
+	}
+
+	errs := make(chan error, len(runners))
+	for i := range runners {
+		r := &runners[i]
+		go func() {
+			err := r.ExecuteContext(ctx)
+			if err != nil {
+				cancel()
+			}
+			errs <- err
+		}()
+	}
+
+	// The runners canceled because another failed return
+	// context.Canceled, keep the error of the one that failed.
+	var first error
+	for range runners {
+		err := <-errs
+		if err != nil && (first == nil || errors.Is(first, context.Canceled)) {
+			first = err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case first == nil:
+		s.finish(sc, StateDone, nil)
+	case errors.Is(first, context.Canceled):
+		s.finish(sc, StateCanceled, nil)
+	default:
+		s.finish(sc, StateFailed, first)
+	}
+}
+
+// finish sets the final state of sc, s.mu must be held.
+func (s *Service) finish(sc *Scenario, state string, err error) {
+	now := time.Now().UTC()
+	sc.State = state
+	sc.Finished = &now
+	sc.runners = nil
+	if err != nil {
+		sc.Error = err.Error()
+	}
+}
+
+// ServeHTTP implements the REST API.
+func (s *Service) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	if path == "/scenarios" {
+		switch req.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, s.List())
+		case http.MethodPost:
+			s.handleSubmit(w, req)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		}
+		return
+	}
+
+	if !strings.HasPrefix(path, "/scenarios/") {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%s not found", req.URL.Path))
+		return
+	}
+	idStr := strings.TrimPrefix(path, "/scenarios/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("scenario %s not found", idStr))
+		return
+	}
+
+	var sc Scenario
+	var ok bool
+	switch req.Method {
+	case http.MethodGet:
+		sc, ok = s.Get(id)
+	case http.MethodDelete:
+		sc, ok = s.Cancel(id)
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("scenario %d not found", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, sc)
+}
+
+func (s *Service) handleSubmit(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxScenarioSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	sc, err := s.Submit(body)
+	if err == errQueueFull {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, sc)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
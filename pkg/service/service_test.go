@@ -0,0 +1,150 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scenario(dir string, interval string) string {
+	return fmt.Sprintf(`
+name: test
+runners:
+  - generator:
+      type: "aws:vpcflow"
+    output:
+      type: file
+      directory: %q
+      pattern: "spigot_*.log"
+      delimiter: "\n"
+    records: 2
+    interval: %q
+`, dir, interval)
+}
+
+func TestAPI(t *testing.T) {
+	s := New(1, 4)
+	defer s.Close()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/scenarios", "application/yaml", strings.NewReader(scenario(t.TempDir(), "0s")))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	var sc Scenario
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&sc))
+	resp.Body.Close()
+	assert.Equal(t, 1, sc.ID)
+	assert.Equal(t, "test", sc.Name)
+
+	assert.Equal(t, StateDone, waitFor(t, s, sc.ID, StateDone).State)
+
+	resp, err = http.Get(srv.URL + "/scenarios/1")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&sc))
+	resp.Body.Close()
+	assert.Equal(t, StateDone, sc.State)
+	assert.NotNil(t, sc.Started)
+	assert.NotNil(t, sc.Finished)
+
+	resp, err = http.Get(srv.URL + "/scenarios")
+	assert.Nil(t, err)
+	var l []Scenario
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&l))
+	resp.Body.Close()
+	assert.Len(t, l, 1)
+
+	tests := map[string]struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		"Invalid Scenario": {method: http.MethodPost, path: "/scenarios", body: "name: [", status: http.StatusBadRequest},
+		"No Runners":       {method: http.MethodPost, path: "/scenarios", body: "name: empty", status: http.StatusBadRequest},
+		"Unknown Scenario": {method: http.MethodGet, path: "/scenarios/42", status: http.StatusNotFound},
+		"Invalid ID":       {method: http.MethodDelete, path: "/scenarios/abc", status: http.StatusNotFound},
+		"Unknown Path":     {method: http.MethodGet, path: "/runners", status: http.StatusNotFound},
+		"Invalid Method":   {method: http.MethodPut, path: "/scenarios", status: http.StatusMethodNotAllowed},
+	}
+	for name, tc := range tests {
+		req, err := http.NewRequest(tc.method, srv.URL+tc.path, strings.NewReader(tc.body))
+		assert.Nil(t, err, name)
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err, name)
+		resp.Body.Close()
+		assert.Equal(t, tc.status, resp.StatusCode, name)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	s := New(1, 1)
+	defer s.Close()
+	dir := t.TempDir()
+
+	// The first scenario runs until it is canceled, so the second
+	// waits in the queue and the third does not fit.
+	first, err := s.Submit([]byte(scenario(dir, "1h")))
+	assert.Nil(t, err)
+	waitFor(t, s, first.ID, StateRunning)
+	second, err := s.Submit([]byte(scenario(dir, "0s")))
+	assert.Nil(t, err)
+	_, err = s.Submit([]byte(scenario(dir, "0s")))
+	assert.Equal(t, errQueueFull, err)
+
+	sc, _ := s.Get(second.ID)
+	assert.Equal(t, StateQueued, sc.State)
+
+	_, ok := s.Cancel(first.ID)
+	assert.True(t, ok)
+	assert.Equal(t, StateCanceled, waitFor(t, s, first.ID, StateCanceled).State)
+	assert.Equal(t, StateDone, waitFor(t, s, second.ID, StateDone).State)
+}
+
+func TestParallel(t *testing.T) {
+	s := New(2, 2)
+	defer s.Close()
+	dir := t.TempDir()
+
+	first, err := s.Submit([]byte(scenario(dir, "1h")))
+	assert.Nil(t, err)
+	second, err := s.Submit([]byte(scenario(dir, "1h")))
+	assert.Nil(t, err)
+	waitFor(t, s, first.ID, StateRunning)
+	waitFor(t, s, second.ID, StateRunning)
+
+	s.Cancel(first.ID)
+	waitFor(t, s, first.ID, StateCanceled)
+	sc, _ := s.Get(second.ID)
+	assert.Equal(t, StateRunning, sc.State)
+}
+
+func TestFailed(t *testing.T) {
+	s := New(1, 1)
+	defer s.Close()
+
+	sc, err := s.Submit([]byte("runners:\n  - generator:\n      type: bob\n    output:\n      type: file\n      pattern: x\n      directory: " + t.TempDir()))
+	assert.Nil(t, err)
+	sc = waitFor(t, s, sc.ID, StateFailed)
+	assert.Equal(t, "Input bob not registered", sc.Error)
+}
+
+func waitFor(t *testing.T, s *Service, id int, state string) Scenario {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		sc, ok := s.Get(id)
+		assert.True(t, ok)
+		if sc.State == state || time.Now().After(deadline) {
+			return sc
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
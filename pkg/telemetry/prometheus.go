@@ -0,0 +1,181 @@
+package telemetry
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// Prometheus serves the metrics of the runners in the Prometheus text
+// exposition format, for example on /metrics.  Its Reader must be added
+// to the meter provider with Start or StartMetrics.
+//
+// The names of the metrics are the OpenTelemetry names with the dots
+// replaced by underscores, the unit appended and counters ending in
+// _total, such as spigot_records_total and spigot_bytes_total.  The
+// attributes of the runners, such as spigot_runner, spigot_generator
+// and the labels, are the labels of the series, so the rate of each
+// runner is for example
+//
+//	rate(spigot_records_total[1m])
+type Prometheus struct {
+	reader sdkmetric.Reader
+}
+
+// NewPrometheus returns a Prometheus handler with a reader of its own.
+func NewPrometheus() *Prometheus {
+	return &Prometheus{reader: sdkmetric.NewManualReader()}
+}
+
+// Reader returns the reader to add to the meter provider.
+func (p *Prometheus) Reader() sdkmetric.Reader {
+	return p.reader
+}
+
+// ServeHTTP collects the metrics and writes them to w.
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var rm metricdata.ResourceMetrics
+	if err := p.reader.Collect(r.Context(), &rm); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writePrometheus(w, rm)
+}
+
+// writePrometheus writes the metrics of rm in the text format to w,
+// after a target_info of the attributes of the resource.  Metrics of
+// types that spigot does not record are skipped.
+func writePrometheus(w io.Writer, rm metricdata.ResourceMetrics) {
+	if rm.Resource != nil && rm.Resource.Len() > 0 {
+		writeHeader(w, "target_info", "Target metadata", "gauge")
+		fmt.Fprintf(w, "target_info%s 1\n", promLabels(*rm.Resource.Set(), "", ""))
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			name := promName(m.Name, m.Unit)
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				writeSum(w, name, m.Description, data.IsMonotonic, data.DataPoints)
+			case metricdata.Sum[float64]:
+				writeSum(w, name, m.Description, data.IsMonotonic, data.DataPoints)
+			case metricdata.Gauge[int64]:
+				writePoints(w, name, m.Description, "gauge", data.DataPoints)
+			case metricdata.Gauge[float64]:
+				writePoints(w, name, m.Description, "gauge", data.DataPoints)
+			case metricdata.Histogram[int64]:
+				writeHistogram(w, name, m.Description, data.DataPoints)
+			case metricdata.Histogram[float64]:
+				writeHistogram(w, name, m.Description, data.DataPoints)
+			}
+		}
+	}
+}
+
+func writeSum[N int64 | float64](w io.Writer, name, help string, monotonic bool, points []metricdata.DataPoint[N]) {
+	if !monotonic {
+		writePoints(w, name, help, "gauge", points)
+		return
+	}
+	writePoints(w, name+"_total", help, "counter", points)
+}
+
+func writePoints[N int64 | float64](w io.Writer, name, help, typ string, points []metricdata.DataPoint[N]) {
+	writeHeader(w, name, help, typ)
+	for _, p := range points {
+		fmt.Fprintf(w, "%s%s %s\n", name, promLabels(p.Attributes, "", ""), promValue(float64(p.Value)))
+	}
+}
+
+func writeHistogram[N int64 | float64](w io.Writer, name, help string, points []metricdata.HistogramDataPoint[N]) {
+	writeHeader(w, name, help, "histogram")
+	for _, p := range points {
+		var n uint64
+		for i, bound := range p.Bounds {
+			n += p.BucketCounts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name, promLabels(p.Attributes, "le", promValue(bound)), n)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, promLabels(p.Attributes, "le", "+Inf"), p.Count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, promLabels(p.Attributes, "", ""), promValue(float64(p.Sum)))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, promLabels(p.Attributes, "", ""), p.Count)
+	}
+}
+
+func writeHeader(w io.Writer, name, help, typ string) {
+	if help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, helpEscaper.Replace(help))
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+}
+
+// helpEscaper escapes help texts.
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// promUnits are the suffixes of the OpenTelemetry units.  Annotations
+// such as {record} have none.
+var promUnits = map[string]string{
+	"By": "bytes",
+	"s":  "seconds",
+	"ms": "milliseconds",
+	"1":  "ratio",
+}
+
+// promName returns the Prometheus name of the metric name with unit.
+func promName(name, unit string) string {
+	n := promSanitize(name)
+	if suffix, ok := promUnits[unit]; ok && !strings.HasSuffix(n, "_"+suffix) {
+		n += "_" + suffix
+	}
+	return n
+}
+
+// promSanitize replaces the characters that are not valid in names
+// with underscores.
+func promSanitize(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if !(c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')) {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// promLabels returns the label set of attrs, with the extra label k
+// if it is not empty.
+func promLabels(attrs attribute.Set, k, v string) string {
+	var labels []string
+	iter := attrs.Iter()
+	for iter.Next() {
+		kv := iter.Attribute()
+		labels = append(labels, promSanitize(string(kv.Key))+`="`+labelEscaper.Replace(kv.Value.Emit())+`"`)
+	}
+	if k != "" {
+		labels = append(labels, k+`="`+v+`"`)
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(labels, ",") + "}"
+}
+
+// labelEscaper escapes label values.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// promValue formats v as a sample value.
+func promValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
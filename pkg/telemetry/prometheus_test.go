@@ -0,0 +1,51 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestPrometheus(t *testing.T) {
+	p := NewPrometheus()
+	res, err := newResource(map[string]string{"test": "soak"})
+	assert.NoError(t, err)
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(p.Reader()), sdkmetric.WithResource(res))
+	defer func() { _ = mp.Shutdown(context.Background()) }()
+	meter := mp.Meter("test")
+
+	records, err := meter.Int64Counter("spigot.records", metric.WithDescription("Number of records."), metric.WithUnit("{record}"))
+	assert.NoError(t, err)
+	bytes, err := meter.Int64Counter("spigot.bytes", metric.WithUnit("By"))
+	assert.NoError(t, err)
+	latency, err := meter.Float64Histogram("spigot.output.write.duration", metric.WithUnit("s"))
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	opt := metric.WithAttributes(attribute.String("spigot.runner", "fire\"wall"), attribute.String("test", "soak"))
+	records.Add(ctx, 3, opt)
+	bytes.Add(ctx, 1024, opt)
+	latency.Record(ctx, 0.003, opt)
+	latency.Record(ctx, 7, opt)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	assert.Regexp(t, `(?m)^target_info\{service_name="spigot",.*test="soak"\} 1$`, body)
+	labels := `spigot_runner="fire\"wall",test="soak"`
+	assert.Contains(t, body, "# HELP spigot_records_total Number of records.\n# TYPE spigot_records_total counter\nspigot_records_total{"+labels+"} 3\n")
+	assert.Contains(t, body, "# TYPE spigot_bytes_total counter\nspigot_bytes_total{"+labels+"} 1024\n")
+	assert.Contains(t, body, "# TYPE spigot_output_write_duration_seconds histogram\n")
+	assert.Contains(t, body, "spigot_output_write_duration_seconds_bucket{"+labels+`,le="5"} 1`+"\n")
+	assert.Contains(t, body, "spigot_output_write_duration_seconds_bucket{"+labels+`,le="+Inf"} 2`+"\n")
+	assert.Contains(t, body, "spigot_output_write_duration_seconds_sum{"+labels+"} 7.003\n")
+	assert.Contains(t, body, "spigot_output_write_duration_seconds_count{"+labels+"} 2\n")
+}
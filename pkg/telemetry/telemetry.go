@@ -0,0 +1,162 @@
+// Package telemetry exports spigot's own traces and metrics with
+// OpenTelemetry, so the behavior of spigot during a test can be
+// analyzed next to the system under test.
+//
+// The runners record a span for every batch of records and metrics
+// for the records and bytes written and the latency of the output.
+// Without telemetry configured these are not recorded.  The metrics
+// can also be scraped by Prometheus, see Prometheus.
+//
+// Configuration:
+//
+//	endpoint: The OTLP gRPC endpoint, for example "localhost:4317".  If
+//	          empty the OTEL_EXPORTER_OTLP_ENDPOINT environment variable
+//	          or the OTLP default is used.
+//	insecure: (bool, optional) Connect to the endpoint without TLS.
+//	interval: (duration, optional) How often metrics are exported.
+//	          Default 10s.
+//
+// The top level "labels" of the configuration file are added to the
+// resource of all traces and metrics.
+//
+//	labels:
+//	  test: "ingest-soak-42"
+//	telemetry:
+//	  endpoint: "otel-collector:4317"
+//	  insecure: true
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// ServiceName is the service.name of spigot's traces and metrics.
+const ServiceName = "spigot"
+
+// Config is the configuration of the telemetry exporters.
+type Config struct {
+	Endpoint string        `config:"endpoint"`
+	Insecure bool          `config:"insecure"`
+	Interval time.Duration `config:"interval"`
+}
+
+// DefaultConfig returns the default telemetry configuration.
+func DefaultConfig() Config {
+	return Config{
+		Interval: 10 * time.Second,
+	}
+}
+
+// Validate validates the telemetry configuration.
+func (c *Config) Validate() error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'interval' expected a duration greater than 0", c.Interval)
+	}
+	return nil
+}
+
+// Start installs global tracer and meter providers that export to the
+// endpoint in c, with labels as attributes of the resource.  The
+// readers, such as that of a Prometheus handler, are added to the
+// meter provider.  The returned function flushes and stops the
+// exporters.
+func Start(ctx context.Context, c Config, labels map[string]string, readers ...sdkmetric.Reader) (func(context.Context) error, error) {
+	res, err := newResource(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	var traceOpts []otlptracegrpc.Option
+	var metricOpts []otlpmetricgrpc.Option
+	if c.Endpoint != "" {
+		traceOpts = append(traceOpts, otlptracegrpc.WithEndpoint(c.Endpoint))
+		metricOpts = append(metricOpts, otlpmetricgrpc.WithEndpoint(c.Endpoint))
+	}
+	if c.Insecure {
+		traceOpts = append(traceOpts, otlptracegrpc.WithInsecure())
+		metricOpts = append(metricOpts, otlpmetricgrpc.WithInsecure())
+	}
+
+	traceExporter, err := otlptracegrpc.New(ctx, traceOpts...)
+	if err != nil {
+		return nil, err
+	}
+	metricExporter, err := otlpmetricgrpc.New(ctx, metricOpts...)
+	if err != nil {
+		_ = traceExporter.Shutdown(ctx)
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(res),
+	)
+	opts := []sdkmetric.Option{
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter, sdkmetric.WithInterval(c.Interval))),
+		sdkmetric.WithResource(res),
+	}
+	for _, r := range readers {
+		opts = append(opts, sdkmetric.WithReader(r))
+	}
+	mp := sdkmetric.NewMeterProvider(opts...)
+	otel.SetTracerProvider(tp)
+	otel.SetMeterProvider(mp)
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if mErr := mp.Shutdown(ctx); err == nil {
+			err = mErr
+		}
+		return err
+	}, nil
+}
+
+// StartMetrics installs a global meter provider of only the readers,
+// for metrics without an OTLP endpoint.  The returned function stops
+// the readers.
+func StartMetrics(labels map[string]string, readers ...sdkmetric.Reader) (func(context.Context) error, error) {
+	res, err := newResource(labels)
+	if err != nil {
+		return nil, err
+	}
+	opts := []sdkmetric.Option{sdkmetric.WithResource(res)}
+	for _, r := range readers {
+		opts = append(opts, sdkmetric.WithReader(r))
+	}
+	mp := sdkmetric.NewMeterProvider(opts...)
+	otel.SetMeterProvider(mp)
+	return mp.Shutdown, nil
+}
+
+// newResource returns the resource of spigot with labels.
+func newResource(labels map[string]string) (*resource.Resource, error) {
+	attrs := append(Attributes(labels), semconv.ServiceName(ServiceName))
+	return resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, attrs...))
+}
+
+// Attributes returns labels as attributes, sorted by key.
+func Attributes(labels map[string]string) []attribute.KeyValue {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]attribute.KeyValue, 0, len(labels))
+	for _, k := range keys {
+		attrs = append(attrs, attribute.String(k, labels[k]))
+	}
+	return attrs
+}
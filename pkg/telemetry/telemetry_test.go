@@ -0,0 +1,59 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Defaults": {
+			c:           map[string]interface{}{},
+			hasError:    false,
+			errorString: "",
+		},
+		"Valid Endpoint": {
+			c:           map[string]interface{}{"endpoint": "localhost:4317", "insecure": true, "interval": "1s"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Interval": {
+			c:           map[string]interface{}{"interval": "0s"},
+			hasError:    true,
+			errorString: "'0s' is not a valid value for 'interval' expected a duration greater than 0 accessing config",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c, err := ucfg.NewFrom(tc.c)
+			assert.NoError(t, err)
+
+			cfg := DefaultConfig()
+			err = c.Unpack(&cfg)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, err.Error(), tc.errorString)
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	attrs := Attributes(map[string]string{"test": "soak", "team": "a"})
+	if assert.Len(t, attrs, 2) {
+		assert.Equal(t, "team", string(attrs[0].Key))
+		assert.Equal(t, "test", string(attrs[1].Key))
+		assert.Equal(t, "soak", attrs[1].Value.AsString())
+	}
+}